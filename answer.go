@@ -0,0 +1,382 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/prompts"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+const (
+	// DefaultAnswerContextTokens is the default token budget for the context
+	// passed to the answering model.
+	DefaultAnswerContextTokens = 2000
+
+	// DefaultAnswerSnippetChars is the default number of characters of
+	// episode text included around each cited span.
+	DefaultAnswerSnippetChars = 400
+
+	answerSystemPrompt = `You are a helpful assistant that answers questions using only the facts, entities and sources retrieved from a knowledge graph.`
+)
+
+// AnswerOptions configures Client.Answer.
+type AnswerOptions struct {
+	// SearchConfig controls retrieval. Nil uses the client's default search config.
+	SearchConfig *types.SearchConfig
+	// MaxContextTokens caps the estimated size of the context given to the model.
+	MaxContextTokens int
+	// MaxSnippetChars is the number of characters of episode text quoted per source.
+	MaxSnippetChars int
+	// ExcludeCommunities skips community summaries in the context.
+	ExcludeCommunities bool
+	// ExcludeSources skips episode snippets in the context.
+	ExcludeSources bool
+	// SystemPrompt replaces the default answering instructions.
+	SystemPrompt string
+}
+
+// AnswerResult is a synthesized answer with the graph data that supports it.
+type AnswerResult struct {
+	// Question is the question that was asked.
+	Question string `json:"question"`
+	// Answer is the model's answer.
+	Answer string `json:"answer"`
+	// FactUUIDs are the edges the answer cites.
+	FactUUIDs []string `json:"fact_uuids"`
+	// EpisodeUUIDs are the episodes the answer cites, directly or through a cited fact.
+	EpisodeUUIDs []string `json:"episode_uuids"`
+	// Citations are the provenance spans of the cited facts.
+	Citations []*types.Citation `json:"citations,omitempty"`
+	// Uncited is true when the answer cites no fact or source in the
+	// context, so nothing in the graph is known to support it.
+	Uncited bool `json:"uncited"`
+	// ContextTokens is the estimated size of the context given to the model.
+	ContextTokens int `json:"context_tokens"`
+	// SearchResults holds everything retrieved for the question.
+	SearchResults *types.SearchResults `json:"-"`
+}
+
+// answerContext is the token-budgeted context assembled for a question.
+type answerContext struct {
+	facts       []map[string]interface{}
+	entities    []map[string]interface{}
+	communities []map[string]interface{}
+	sources     []map[string]interface{}
+
+	factIDs   map[string]*types.Edge
+	sourceIDs map[string]string
+	tokens    int
+	budget    int
+}
+
+// fits reserves room for item if it fits in the remaining budget.
+func (a *answerContext) fits(item map[string]interface{}) bool {
+	var text strings.Builder
+	for _, v := range item {
+		fmt.Fprintf(&text, "%v ", v)
+	}
+	tokens := nlp.GetTokenCount(text.String())
+	if a.tokens+tokens > a.budget {
+		return false
+	}
+	a.tokens += tokens
+	return true
+}
+
+// Answer answers a question from the knowledge graph. It runs hybrid search,
+// packs facts, entity summaries, communities and episode snippets into a
+// token-budgeted context, and asks NlpModels.TextGeneration (falling back to
+// the client's NLP processor) to answer with citations.
+func (c *Client) Answer(ctx context.Context, question string, opts *AnswerOptions) (*AnswerResult, error) {
+	if strings.TrimSpace(question) == "" {
+		return nil, fmt.Errorf("question cannot be empty")
+	}
+	if opts == nil {
+		opts = &AnswerOptions{}
+	}
+
+	generator := c.nlpModels.TextGeneration
+	if generator == nil {
+		generator = c.nlProcessor
+	}
+	if generator == nil {
+		return nil, fmt.Errorf("no text generation model configured: set NlpModels.TextGeneration")
+	}
+
+	var groupIDs []string
+	if opts.SearchConfig != nil {
+		groupIDs = opts.SearchConfig.GroupIDs
+	} else if c.config.SearchConfig != nil {
+		groupIDs = c.config.SearchConfig.GroupIDs
+	}
+	groupIDs, err := c.searchGroupIDs(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = c.withUsage(ctx, "", groupIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.Search(ctx, question, opts.SearchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for answer context: %w", err)
+	}
+
+	answerCtx := c.buildAnswerContext(ctx, results, opts)
+
+	systemPrompt := opts.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = answerSystemPrompt
+	}
+	promptContext := map[string]interface{}{
+		"query":            question,
+		"entity_summaries": answerCtx.entities,
+		"facts":            answerCtx.facts,
+		"system_prompt":    systemPrompt,
+		"cite":             true,
+		"logger":           c.logger,
+	}
+	if len(answerCtx.communities) > 0 {
+		promptContext["communities"] = answerCtx.communities
+	}
+	if len(answerCtx.sources) > 0 {
+		promptContext["sources"] = answerCtx.sources
+	}
+
+	messages, err := prompts.NewLibrary().Eval().QAPrompt().Call(promptContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build answer prompt: %w", err)
+	}
+
+	response, err := generator.Chat(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate answer: %w", err)
+	}
+
+	result := &AnswerResult{
+		Question:      question,
+		Answer:        strings.TrimSpace(response.Content),
+		ContextTokens: answerCtx.tokens,
+		SearchResults: results,
+	}
+	answerCtx.resolveCitations(result)
+
+	return result, nil
+}
+
+// buildAnswerContext fills the context in priority order (facts, entity
+// summaries, source snippets, communities) until the token budget is spent.
+func (c *Client) buildAnswerContext(ctx context.Context, results *types.SearchResults, opts *AnswerOptions) *answerContext {
+	budget := opts.MaxContextTokens
+	if budget <= 0 {
+		budget = DefaultAnswerContextTokens
+	}
+	snippetChars := opts.MaxSnippetChars
+	if snippetChars <= 0 {
+		snippetChars = DefaultAnswerSnippetChars
+	}
+
+	a := &answerContext{
+		factIDs:   make(map[string]*types.Edge),
+		sourceIDs: make(map[string]string),
+		budget:    budget,
+	}
+
+	var included []*types.Edge
+	for _, edge := range results.Edges {
+		fact := edge.Fact
+		if fact == "" {
+			fact = edge.Summary
+		}
+		if fact == "" {
+			continue
+		}
+		validAt := ""
+		if edge.ValidAt != nil {
+			validAt = edge.ValidAt.Format(time.RFC3339)
+		} else if !edge.ValidFrom.IsZero() {
+			validAt = edge.ValidFrom.Format(time.RFC3339)
+		}
+		invalidAt := "Present"
+		if edge.InvalidAt != nil {
+			invalidAt = edge.InvalidAt.Format(time.RFC3339)
+		} else if edge.ValidTo != nil {
+			invalidAt = edge.ValidTo.Format(time.RFC3339)
+		}
+		id := "F" + strconv.Itoa(len(a.facts)+1)
+		item := map[string]interface{}{
+			"id":         id,
+			"fact":       fact,
+			"valid_at":   validAt,
+			"invalid_at": invalidAt,
+		}
+		if !a.fits(item) {
+			break
+		}
+		a.facts = append(a.facts, item)
+		a.factIDs[id] = edge
+		included = append(included, edge)
+	}
+
+	var entities []*types.Node
+	for _, node := range results.Nodes {
+		if node.Type != types.EntityNodeType || node.Summary == "" {
+			continue
+		}
+		item := map[string]interface{}{
+			"entity_name": node.Name,
+			"summary":     node.Summary,
+		}
+		if !a.fits(item) {
+			break
+		}
+		a.entities = append(a.entities, item)
+		entities = append(entities, node)
+	}
+
+	if !opts.ExcludeSources {
+		c.addAnswerSources(ctx, a, included, snippetChars)
+	}
+
+	if !opts.ExcludeCommunities {
+		seen := make(map[string]bool)
+		for _, node := range entities {
+			community, err := c.driver.GetExistingCommunity(ctx, node.Uuid)
+			if err != nil || community == nil || seen[community.Uuid] || community.Summary == "" {
+				continue
+			}
+			seen[community.Uuid] = true
+			item := map[string]interface{}{
+				"community_name": community.Name,
+				"summary":        community.Summary,
+			}
+			if !a.fits(item) {
+				break
+			}
+			a.communities = append(a.communities, item)
+		}
+	}
+
+	return a
+}
+
+// addAnswerSources quotes the episode text around the provenance span of
+// each included fact.
+func (c *Client) addAnswerSources(ctx context.Context, a *answerContext, edges []*types.Edge, snippetChars int) {
+	episodes := make(map[string]*types.Node)
+	quoted := make(map[string]bool)
+
+	for _, edge := range edges {
+		for _, p := range types.EdgeProvenance(edge) {
+			key := fmt.Sprintf("%s:%d:%d", p.EpisodeID, p.Start, p.End)
+			if quoted[key] {
+				continue
+			}
+			quoted[key] = true
+
+			episode, ok := episodes[p.EpisodeID]
+			if !ok {
+				var err error
+				episode, err = c.driver.GetNode(ctx, p.EpisodeID, edge.GroupID)
+				if err != nil {
+					c.logger.Debug("Failed to load episode for answer context", "episode_id", p.EpisodeID, "error", err)
+				}
+				episodes[p.EpisodeID] = episode
+			}
+
+			snippet := p.Text
+			if episode != nil {
+				if window := snippetWindow(episode.Content, p.Span(), snippetChars); window != "" {
+					snippet = window
+				}
+			}
+			if snippet == "" {
+				continue
+			}
+
+			id := "S" + strconv.Itoa(len(a.sources)+1)
+			item := map[string]interface{}{
+				"id":      id,
+				"snippet": snippet,
+			}
+			if !a.fits(item) {
+				return
+			}
+			a.sources = append(a.sources, item)
+			a.sourceIDs[id] = p.EpisodeID
+		}
+	}
+}
+
+// snippetWindow returns up to size characters of content centred on span.
+func snippetWindow(content string, span *types.Span, size int) string {
+	runes := []rune(content)
+	if !span.IsValid() || span.End > len(runes) {
+		return ""
+	}
+	pad := (size - (span.End - span.Start)) / 2
+	if pad < 0 {
+		pad = 0
+	}
+	start := span.Start - pad
+	if start < 0 {
+		start = 0
+	}
+	end := span.End + pad
+	if end > len(runes) {
+		end = len(runes)
+	}
+	return strings.TrimSpace(string(runes[start:end]))
+}
+
+// answerCitationPattern matches bracketed citations such as [F1] or [F2, S1].
+var answerCitationPattern = regexp.MustCompile(`\[((?:[FS]\d+)(?:\s*,\s*[FS]\d+)*)\]`)
+
+// resolveCitations maps the ids cited in the answer back to fact and episode
+// UUIDs. Ids that are malformed or not in the context are ignored.
+func (a *answerContext) resolveCitations(result *AnswerResult) {
+	var cited []*types.Edge
+	seenFacts := make(map[string]bool)
+	seenEpisodes := make(map[string]bool)
+
+	addEpisode := func(uuid string) {
+		if uuid != "" && !seenEpisodes[uuid] {
+			seenEpisodes[uuid] = true
+			result.EpisodeUUIDs = append(result.EpisodeUUIDs, uuid)
+		}
+	}
+	addFact := func(edge *types.Edge) {
+		if seenFacts[edge.Uuid] {
+			return
+		}
+		seenFacts[edge.Uuid] = true
+		cited = append(cited, edge)
+		result.FactUUIDs = append(result.FactUUIDs, edge.Uuid)
+		for _, p := range types.EdgeProvenance(edge) {
+			addEpisode(p.EpisodeID)
+		}
+	}
+
+	for _, match := range answerCitationPattern.FindAllStringSubmatch(result.Answer, -1) {
+		for _, id := range strings.Split(match[1], ",") {
+			id = strings.TrimSpace(id)
+			if edge, ok := a.factIDs[id]; ok {
+				addFact(edge)
+			} else if episodeID, ok := a.sourceIDs[id]; ok {
+				addEpisode(episodeID)
+			}
+		}
+	}
+
+	// An answer without valid citations is not attributed to the context:
+	// guessing which facts support it would invent provenance.
+	result.Uncited = len(seenFacts) == 0 && len(seenEpisodes) == 0
+	result.Citations = types.EdgeCitations(cited)
+}
@@ -0,0 +1,140 @@
+package predicato
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// answerFact returns an entity edge of group g supported by a span of episode.
+func answerFact(uuid, fact, episode string) *types.Edge {
+	edge := &types.Edge{BaseEdge: types.BaseEdge{Uuid: uuid, GroupID: "g"}, Type: types.EntityEdgeType, Fact: fact}
+	if episode != "" {
+		types.AddEdgeProvenance(edge, types.Provenance{EpisodeID: episode, Start: 0, End: len(fact), Text: fact})
+	}
+	return edge
+}
+
+// newAnswerClient returns a client of group g over the edges, searching by
+// BM25 only so that no embedder is needed, and answering with reply.
+func newAnswerClient(t *testing.T, reply string, edges ...*types.Edge) (*Client, *scriptedNLP) {
+	t.Helper()
+	d := newMemoryDriver()
+	for _, edge := range edges {
+		d.edges[edge.Uuid] = edge
+	}
+	d.nodes["ep1"] = &types.Node{Uuid: "ep1", GroupID: "g", Type: types.EpisodicNodeType, Content: "Alice works at Acme"}
+	d.nodes["ep2"] = &types.Node{Uuid: "ep2", GroupID: "g", Type: types.EpisodicNodeType, Content: "Bob manages Acme"}
+
+	generator := &scriptedNLP{reply: reply}
+	searchConfig := &types.SearchConfig{
+		Limit:      10,
+		NodeConfig: &types.NodeSearchConfig{SearchMethods: []string{"bm25"}, Reranker: "rrf"},
+		EdgeConfig: &types.EdgeSearchConfig{SearchMethods: []string{"bm25"}, Reranker: "rrf"},
+	}
+	client, err := NewClient(d, generator, nil, &Config{GroupID: "g", TimeZone: time.UTC, SearchConfig: searchConfig}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client, generator
+}
+
+func TestAnswerStopsAtContextBudget(t *testing.T) {
+	long := strings.Repeat("Acme shipped another quarterly report ", 100)
+	client, generator := newAnswerClient(t, "Alice works at Acme [F1].",
+		answerFact("e1", "Alice works at Acme", ""),
+		answerFact("e2", long, ""),
+		answerFact("e3", "Bob manages Acme", ""),
+	)
+
+	result, err := client.Answer(context.Background(), "Who works at Acme?", &AnswerOptions{
+		MaxContextTokens:   50,
+		ExcludeSources:     true,
+		ExcludeCommunities: true,
+	})
+	if err != nil {
+		t.Fatalf("Answer: %v", err)
+	}
+
+	prompt := generator.prompts[0]
+	if !strings.Contains(prompt, "Alice works at Acme") {
+		t.Error("the first fact, which fits the budget, is missing from the prompt")
+	}
+	// Facts are packed in rank order, so nothing after the first fact that
+	// overflows the budget is included, even if it would fit
+	if strings.Contains(prompt, "quarterly report") || strings.Contains(prompt, "Bob manages Acme") {
+		t.Error("facts past the budget were included in the prompt")
+	}
+	if result.ContextTokens == 0 || result.ContextTokens > 50 {
+		t.Errorf("ContextTokens = %d, want 1..50", result.ContextTokens)
+	}
+}
+
+func TestAnswerResolvesCitationMarkers(t *testing.T) {
+	client, _ := newAnswerClient(t,
+		"Bob manages Acme [F2], where Alice works [F1, F9]. See [S2] and [X1], [F0] and [F1].",
+		answerFact("e1", "Alice works at Acme", "ep1"),
+		answerFact("e2", "Bob manages Acme", "ep2"),
+	)
+
+	result, err := client.Answer(context.Background(), "Who is at Acme?", &AnswerOptions{ExcludeCommunities: true})
+	if err != nil {
+		t.Fatalf("Answer: %v", err)
+	}
+
+	// F9 and F0 are out of range, X1 is not a citation id; F1 is cited twice
+	if want := []string{"e2", "e1"}; !slices.Equal(result.FactUUIDs, want) {
+		t.Errorf("FactUUIDs = %v, want %v", result.FactUUIDs, want)
+	}
+	if want := []string{"ep2", "ep1"}; !slices.Equal(result.EpisodeUUIDs, want) {
+		t.Errorf("EpisodeUUIDs = %v, want %v", result.EpisodeUUIDs, want)
+	}
+	if len(result.Citations) != 2 {
+		t.Errorf("got %d citations, want one per cited fact", len(result.Citations))
+	}
+	if result.Uncited {
+		t.Error("an answer with valid citations is marked uncited")
+	}
+
+	// A source citation attributes the answer to its episode but to no fact
+	client, _ = newAnswerClient(t, "Bob manages Acme [S2].",
+		answerFact("e1", "Alice works at Acme", "ep1"),
+		answerFact("e2", "Bob manages Acme", "ep2"),
+	)
+	result, err = client.Answer(context.Background(), "Who manages Acme?", &AnswerOptions{ExcludeCommunities: true})
+	if err != nil {
+		t.Fatalf("Answer: %v", err)
+	}
+	if len(result.FactUUIDs) != 0 || !slices.Equal(result.EpisodeUUIDs, []string{"ep2"}) || result.Uncited {
+		t.Errorf("[S2] resolved to facts %v, episodes %v, uncited %v; want episode ep2 only",
+			result.FactUUIDs, result.EpisodeUUIDs, result.Uncited)
+	}
+}
+
+func TestAnswerWithoutCitationsIsUncited(t *testing.T) {
+	for _, reply := range []string{
+		"Alice and Bob are at Acme.",
+		"Alice and Bob are at Acme [F7] [S9] [Q1].", // only invalid or out-of-range ids
+	} {
+		client, _ := newAnswerClient(t, reply,
+			answerFact("e1", "Alice works at Acme", "ep1"),
+			answerFact("e2", "Bob manages Acme", "ep2"),
+		)
+
+		result, err := client.Answer(context.Background(), "Who is at Acme?", &AnswerOptions{ExcludeCommunities: true})
+		if err != nil {
+			t.Fatalf("Answer: %v", err)
+		}
+		if !result.Uncited {
+			t.Errorf("%q: answer without valid citations is not marked uncited", reply)
+		}
+		if len(result.FactUUIDs) != 0 || len(result.EpisodeUUIDs) != 0 || len(result.Citations) != 0 {
+			t.Errorf("%q: uncited answer attributed to facts %v, episodes %v, %d citations",
+				reply, result.FactUUIDs, result.EpisodeUUIDs, len(result.Citations))
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/firebase/genkit/go/genkit"
@@ -60,6 +61,7 @@ type Config struct {
 
 	// MCP Server Configuration
 	GroupID           string
+	AllowedGroupIDs   []string
 	UseCustomEntities bool
 	DestroyGraph      bool
 	Transport         string
@@ -90,6 +92,7 @@ func NewConfig() *Config {
 		DatabaseUser:      getEnv("NEO4J_USER", ""),
 		DatabasePassword:  getEnv("NEO4J_PASSWORD", ""),
 		GroupID:           getEnv("GROUP_ID", "default"),
+		AllowedGroupIDs:   getEnvList("ALLOWED_GROUP_IDS"),
 		UseCustomEntities: getEnvBool("USE_CUSTOM_ENTITIES", false),
 		DestroyGraph:      getEnvBool("DESTROY_GRAPH", false),
 		Transport:         getEnv("MCP_TRANSPORT", "stdio"),
@@ -136,6 +139,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // NewMCPServer creates a new MCP server instance
 func NewMCPServer(config *Config) (*MCPServer, error) {
 	logger := slog.New(predicatoLogger.NewColorHandler(os.Stderr, &slog.HandlerOptions{
@@ -186,8 +199,9 @@ func NewMCPServer(config *Config) (*MCPServer, error) {
 
 	// Create Predicato client
 	predicatoConfig := &predicato.Config{
-		GroupID:  config.GroupID,
-		TimeZone: time.UTC,
+		GroupID:         config.GroupID,
+		AllowedGroupIDs: config.AllowedGroupIDs,
+		TimeZone:        time.UTC,
 	}
 
 	client, err := predicato.NewClient(graphDriver, nlProcessor, embedderClient, predicatoConfig, logger)
@@ -23,13 +23,14 @@ type AddMemoryRequest struct {
 
 // SearchRequest represents search parameters
 type SearchRequest struct {
-	Query          string   `json:"query"`
-	Limit          int      `json:"limit,omitempty"`
-	GroupIDs       []string `json:"group_ids,omitempty"`
-	MaxNodes       int      `json:"max_nodes,omitempty"`
-	MaxFacts       int      `json:"max_facts,omitempty"`
-	CenterNodeUUID string   `json:"center_node_uuid,omitempty"`
-	Entity         string   `json:"entity,omitempty"` // Single entity type to filter results
+	Query          string             `json:"query"`
+	Limit          int                `json:"limit,omitempty"`
+	GroupIDs       []string           `json:"group_ids,omitempty"`
+	GroupWeights   map[string]float64 `json:"group_weights,omitempty"`
+	MaxNodes       int                `json:"max_nodes,omitempty"`
+	MaxFacts       int                `json:"max_facts,omitempty"`
+	CenterNodeUUID string             `json:"center_node_uuid,omitempty"`
+	Entity         string             `json:"entity,omitempty"` // Single entity type to filter results
 }
 
 // GetEpisodesRequest represents parameters for retrieving episodes
@@ -166,6 +167,8 @@ func (s *MCPServer) SearchMemoryNodesTool(ctx *ai.ToolContext, input *SearchRequ
 			Reranker:      "rrf",
 			MinScore:      0.0,
 		},
+		GroupIDs:     groupIDs,
+		GroupWeights: input.GroupWeights,
 	}
 
 	// Apply entity filtering if specified (similar to Python's entity parameter)
@@ -263,6 +266,8 @@ func (s *MCPServer) SearchMemoryFactsTool(ctx *ai.ToolContext, input *SearchRequ
 			Reranker:      "rrf",
 			MinScore:      0.0,
 		},
+		GroupIDs:     groupIDs,
+		GroupWeights: input.GroupWeights,
 	}
 
 	// Perform search
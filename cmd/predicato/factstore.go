@@ -0,0 +1,175 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/factory"
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/spf13/cobra"
+)
+
+var factstoreCmd = &cobra.Command{
+	Use:   "factstore",
+	Short: "Manage the fact store schema",
+	Long: `Manage the schema of the fact store configured under "factstore".
+
+The fact store records its schema version and the size of its stored
+embeddings. When a new release adds migrations, or the configured embedding
+dimensions change, the fact store refuses to start until it is migrated.`,
+}
+
+var factstoreMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending migrations and resize stored embeddings",
+	Long: `Apply the pending schema migrations of the fact store.
+
+If the configured embedding dimensions differ from the stored vectors, the
+nodes and edges are re-embedded with the configured embedder. With --pad, or
+when no embedder is configured, the stored vectors are zero-padded or
+truncated instead, which is only meaningful for embedding models trained for
+truncation.`,
+	RunE: runFactstoreMigrate,
+}
+
+var factstoreStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the applied migrations and embedding dimensions",
+	RunE:  runFactstoreStatus,
+}
+
+var factstorePad bool
+
+func init() {
+	rootCmd.AddCommand(factstoreCmd)
+	factstoreCmd.AddCommand(factstoreMigrateCmd)
+	factstoreCmd.AddCommand(factstoreStatusCmd)
+
+	factstoreMigrateCmd.Flags().BoolVar(&factstorePad, "pad", false, "Pad or truncate stored embeddings instead of re-embedding them")
+}
+
+// openMigrator opens the configured fact store, which must support migrations.
+// Its embedding dimensions default to those of embedderClient.
+func openMigrator(cfg *config.Config, embedderClient embedder.Client) (factstore.Migrator, func(), error) {
+	dimensions := cfg.Embedding.Dimensions
+	if dimensions == 0 && embedderClient != nil {
+		dimensions = embedderClient.Dimensions()
+	}
+	facts, err := factory.NewFactStore(cfg.FactStore, dimensions)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrator, ok := facts.(factstore.Migrator)
+	if !ok {
+		facts.Close()
+		return nil, nil, fmt.Errorf("factstore type %q does not support migrations", cfg.FactStore.Type)
+	}
+	return migrator, func() { facts.Close() }, nil
+}
+
+func runFactstoreMigrate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	embedderClient, err := factory.NewEmbedder(factory.EmbeddingConfig(cfg))
+	if err != nil {
+		return err
+	}
+	if embedderClient != nil {
+		defer embedderClient.Close()
+	}
+
+	migrator, closeStore, err := openMigrator(cfg, embedderClient)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	opts := &factstore.MigrateOptions{Embedder: embedderClient}
+	if factstorePad {
+		opts.Embedder = nil
+	}
+	result, err := migrator.Migrate(context.Background(), opts)
+	if err != nil {
+		return fmt.Errorf("failed to migrate factstore: %w", err)
+	}
+
+	for _, m := range result.Applied {
+		fmt.Printf("Applied migration %d: %s\n", m.Version, m.Description)
+	}
+	if result.ResizedTo > 0 {
+		how := "padded"
+		if result.Reembedded {
+			how = "re-embedded"
+		}
+		fmt.Printf("Resized %d embeddings from %d to %d dimensions (%s)\n", result.Vectors, result.ResizedFrom, result.ResizedTo, how)
+	}
+	if len(result.Applied) == 0 && result.ResizedTo == 0 {
+		fmt.Println("Factstore is up to date")
+	}
+	return nil
+}
+
+func runFactstoreStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// The embedder is only consulted for its dimensions
+	embedderClient, err := factory.NewEmbedder(factory.EmbeddingConfig(cfg))
+	if err != nil {
+		return err
+	}
+	if embedderClient != nil {
+		defer embedderClient.Close()
+	}
+
+	migrator, closeStore, err := openMigrator(cfg, embedderClient)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	status, err := migrator.SchemaStatus(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get factstore status: %w", err)
+	}
+
+	fmt.Printf("Schema version: %d of %d\n", status.CurrentVersion, status.LatestVersion)
+	fmt.Printf("Embedding dimensions: %d stored, %d configured\n", status.StoredDimensions, status.ConfiguredDimensions)
+	if status.CurrentVersion > 0 {
+		space, err := migrator.GetEmbeddingSpace(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get embedding space: %w", err)
+		}
+		if space != nil && space.Model != "" {
+			fmt.Printf("Embedding model: %s\n", space.Model)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tAPPLIED\tDESCRIPTION")
+	for _, m := range status.Migrations {
+		applied := "pending"
+		if m.AppliedAt != nil {
+			applied = m.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, applied, m.Description)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(status.Pending()) > 0 || status.DimensionsChanged() {
+		fmt.Println("\nRun `predicato factstore migrate` to bring the factstore up to date.")
+	}
+	return nil
+}
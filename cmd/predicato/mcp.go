@@ -7,15 +7,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/soundprediction/predicato"
-	"github.com/soundprediction/predicato/pkg/driver"
-	"github.com/soundprediction/predicato/pkg/embedder"
+	appConfig "github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/factory"
 	predicatoLogger "github.com/soundprediction/predicato/pkg/logger"
 	"github.com/soundprediction/predicato/pkg/nlp"
-	"github.com/soundprediction/predicato/pkg/telemetry"
 	"github.com/soundprediction/predicato/pkg/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -47,6 +47,7 @@ to work with MCP clients like Claude Desktop or other compatible applications.`,
 
 var (
 	mcpGroupID           string
+	mcpAllowedGroupIDs   []string
 	mcpTransport         string
 	mcpHost              string
 	mcpPort              int
@@ -76,6 +77,7 @@ func init() {
 	viper.BindEnv("database.password", "NEO4J_PASSWORD")
 	viper.BindEnv("database.database", "NEO4J_DATABASE")
 	viper.BindEnv("mcp.group_id", "GROUP_ID")
+	viper.BindEnv("mcp.allowed_group_ids", "ALLOWED_GROUP_IDS")
 	viper.BindEnv("mcp.transport", "MCP_TRANSPORT")
 	viper.BindEnv("mcp.host", "MCP_HOST")
 	viper.BindEnv("mcp.port", "MCP_PORT")
@@ -88,6 +90,7 @@ func init() {
 
 	// MCP Server specific flags
 	mcpCmd.Flags().StringVar(&mcpGroupID, "group-id", "default", "Namespace for the graph")
+	mcpCmd.Flags().StringSliceVar(&mcpAllowedGroupIDs, "allowed-group-ids", nil, "Additional groups that searches may include (e.g. a shared org group)")
 	mcpCmd.Flags().StringVar(&mcpTransport, "transport", "stdio", "Transport to use (stdio or sse)")
 	mcpCmd.Flags().StringVar(&mcpHost, "host", "localhost", "Host to bind the MCP server to")
 	mcpCmd.Flags().IntVar(&mcpPort, "port", 3000, "Port to bind the MCP server to")
@@ -119,6 +122,7 @@ func init() {
 
 	// Bind flags to viper for configuration
 	viper.BindPFlag("mcp.group_id", mcpCmd.Flags().Lookup("group-id"))
+	viper.BindPFlag("mcp.allowed_group_ids", mcpCmd.Flags().Lookup("allowed-group-ids"))
 	viper.BindPFlag("mcp.transport", mcpCmd.Flags().Lookup("transport"))
 	viper.BindPFlag("mcp.host", mcpCmd.Flags().Lookup("host"))
 	viper.BindPFlag("mcp.port", mcpCmd.Flags().Lookup("port"))
@@ -171,6 +175,7 @@ type MCPConfig struct {
 
 	// MCP Server Configuration
 	GroupID           string
+	AllowedGroupIDs   []string
 	UseCustomEntities bool
 	DestroyGraph      bool
 	Transport         string
@@ -220,8 +225,10 @@ type AddMemoryRequest struct {
 
 // SearchRequest represents search parameters
 type SearchRequest struct {
-	Query string `json:"query"`
-	Limit int    `json:"limit,omitempty"`
+	Query        string             `json:"query"`
+	Limit        int                `json:"limit,omitempty"`
+	GroupIDs     []string           `json:"group_ids,omitempty"`
+	GroupWeights map[string]float64 `json:"group_weights,omitempty"`
 }
 
 // GetEpisodesRequest represents parameters for retrieving episodes
@@ -266,6 +273,7 @@ func runMCPServer(cmd *cobra.Command, args []string) error {
 	config := &MCPConfig{
 		// MCP Server configuration
 		GroupID:           getViperStringWithFallback("mcp.group_id", mcpGroupID),
+		AllowedGroupIDs:   getViperStringSliceWithFallback("mcp.allowed_group_ids", mcpAllowedGroupIDs),
 		Transport:         getViperStringWithFallback("mcp.transport", mcpTransport),
 		Host:              getViperStringWithFallback("mcp.host", mcpHost),
 		Port:              getViperIntWithFallback("mcp.port", mcpPort),
@@ -358,109 +366,64 @@ func NewMCPServer(config *MCPConfig) (*MCPServer, error) {
 		Level: slog.LevelInfo,
 	}))
 
-	// Create database driver
-	var graphDriver driver.GraphDriver
-	var err error
-
-	switch config.DatabaseDriver {
-	case "ladybug":
-		graphDriver, err = driver.NewLadybugDriver(config.DatabaseURI, 16)
+	// Telemetry using Parquet
+	trackingPath := config.TelemetryParquetPath
+	if trackingPath == "" {
+		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return nil, fmt.Errorf("failed to create ladybug driver: %w", err)
+			return nil, fmt.Errorf("failed to get user home directory: %w", err)
 		}
+		trackingPath = fmt.Sprintf("%s/.predicato/telemetry", homeDir)
+	}
 
-	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", config.DatabaseDriver)
+	cfg := &appConfig.Config{
+		Database: appConfig.DatabaseConfig{
+			Driver:   config.DatabaseDriver,
+			URI:      config.DatabaseURI,
+			Username: config.DatabaseUser,
+			Password: config.DatabasePassword,
+			Database: config.DatabaseName,
+		},
+		NLP:       appConfig.NLPConfig{Models: map[string]appConfig.NLPModelConfig{}},
+		Telemetry: appConfig.TelemetryConfig{ParquetPath: trackingPath},
 	}
 
 	// Create LLM client - only if we have an API key or base URL
-	var nlProcessor nlp.Client
 	if config.OpenAIAPIKey != "" || config.LLMBaseURL != "" {
-		llmConfig := nlp.Config{
-			Model:       config.LLMModel,
-			Temperature: &[]float32{float32(config.LLMTemperature)}[0],
-			BaseURL:     config.LLMBaseURL,
-		}
-		// Use empty string as API key if only base URL is provided (for services that don't require auth)
 		apiKey := config.OpenAIAPIKey
-		if apiKey == "" && config.LLMBaseURL != "" {
+		if apiKey == "" {
 			apiKey = "dummy" // Some OpenAI-compatible services require a non-empty key
 		}
-		baseLLMClient, err := nlp.NewOpenAIClient(apiKey, llmConfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create LLM client: %w", err)
-		}
-		// Wrap with retry client for automatic retry on errors
-		retryClient, err := nlp.NewRetryClient(baseLLMClient, nlp.DefaultRetryConfig())
-		if err != nil {
-			return nil, fmt.Errorf("failed to create retry client: %w", err)
-		}
-
-		// Telemetry using Parquet
-		trackingPath := config.TelemetryParquetPath
-		if trackingPath == "" {
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get user home directory: %w", err)
-			}
-			trackingPath = fmt.Sprintf("%s/.predicato/telemetry", homeDir)
-		}
-
-		// Ensure directory exists
-		if err := os.MkdirAll(trackingPath, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create telemetry directory: %w", err)
-		}
-
-		// Initialize Token Tracker
-		tracker, err := nlp.NewTokenTracker(trackingPath)
-		if err != nil {
-			logger.Warn("Failed to initialize token tracker", "error", err)
-			nlProcessor = retryClient
-		} else {
-			nlProcessor = nlp.NewTokenTrackingClient(retryClient, tracker)
-			logger.Info("Token tracking enabled", "path", trackingPath)
-		}
-
-		// Initialize Error Tracking Logger
-		// We wrap the existing logger's handler with our Parquet handler
-		parquetHandler, err := telemetry.NewParquetHandler(logger.Handler(), trackingPath)
-		if err != nil {
-			logger.Warn("Failed to initialize error tracking", "error", err)
-		} else {
-			// Update the logger to use our new handler
-			logger = slog.New(parquetHandler)
-			logger.Info("Error tracking enabled")
+		cfg.NLP.Models[factory.ModelDefault] = appConfig.NLPModelConfig{
+			Provider:    string(nlp.ProviderOpenAI),
+			Model:       config.LLMModel,
+			APIKey:      apiKey,
+			BaseURL:     config.LLMBaseURL,
+			Temperature: float32(config.LLMTemperature),
 		}
 	} else {
 		logger.Warn("No LLM configuration provided - LLM functionality will be disabled")
 	}
 
 	// Create embedder client - only if we have an API key or base URL
-	var embedderClient embedder.Client
 	if config.EmbeddingAPIKey != "" || config.EmbeddingBaseURL != "" {
-		embedderConfig := embedder.Config{
-			Model:   config.EmbedderModel,
-			BaseURL: config.EmbeddingBaseURL,
+		cfg.Embedding = appConfig.EmbeddingConfig{
+			Provider: string(nlp.ProviderOpenAI),
+			Model:    config.EmbedderModel,
+			APIKey:   config.EmbeddingAPIKey,
+			BaseURL:  config.EmbeddingBaseURL,
 		}
-		// Use empty string as API key if only base URL is provided
-		apiKey := config.EmbeddingAPIKey
-		if apiKey == "" && config.EmbeddingBaseURL != "" {
-			apiKey = "dummy"
-		}
-		embedderClient = embedder.NewOpenAIEmbedder(apiKey, embedderConfig)
 	} else {
 		logger.Warn("No embedder configuration provided - embedding functionality will be disabled")
 	}
 
-	// Create Predicato client
-	predicatoConfig := &predicato.Config{
-		GroupID:  config.GroupID,
-		TimeZone: time.UTC,
-	}
-
-	client, err := predicato.NewClient(graphDriver, nlProcessor, embedderClient, predicatoConfig, logger)
+	client, err := factory.NewClient(cfg, &factory.Options{
+		Logger:          logger,
+		GroupID:         config.GroupID,
+		AllowedGroupIDs: config.AllowedGroupIDs,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Predicato client: %w", err)
+		return nil, err
 	}
 
 	return &MCPServer{
@@ -567,6 +530,16 @@ func (s *MCPServer) RegisterTools() error {
 					"minimum":     1,
 					"maximum":     100,
 				},
+				"group_ids": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Groups to search together (optional, defaults to server group)",
+				},
+				"group_weights": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": map[string]interface{}{"type": "number"},
+					"description":          "Score multiplier per group when merging results (optional)",
+				},
 			},
 			"required": []string{"query"},
 		},
@@ -589,6 +562,16 @@ func (s *MCPServer) RegisterTools() error {
 					"minimum":     1,
 					"maximum":     100,
 				},
+				"group_ids": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Groups to search together (optional, defaults to server group)",
+				},
+				"group_weights": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": map[string]interface{}{"type": "number"},
+					"description":          "Score multiplier per group when merging results (optional)",
+				},
 			},
 			"required": []string{"query"},
 		},
@@ -728,6 +711,8 @@ func (s *MCPServer) SearchMemoryNodesTool(ctx context.Context, input *SearchRequ
 			Reranker:      "rrf",
 			MinScore:      0.0,
 		},
+		GroupIDs:     input.GroupIDs,
+		GroupWeights: input.GroupWeights,
 	}
 
 	// Perform search
@@ -796,6 +781,8 @@ func (s *MCPServer) SearchMemoryFactsTool(ctx context.Context, input *SearchRequ
 			Reranker:      "rrf",
 			MinScore:      0.0,
 		},
+		GroupIDs:     input.GroupIDs,
+		GroupWeights: input.GroupWeights,
 	}
 
 	// Perform search
@@ -1103,6 +1090,22 @@ func getViperBoolWithFallback(key string, fallback bool) bool {
 	return fallback
 }
 
+func getViperStringSliceWithFallback(key string, fallback []string) []string {
+	if !viper.IsSet(key) {
+		return fallback
+	}
+	// Environment variables arrive as a single comma-separated string
+	var values []string
+	for _, item := range viper.GetStringSlice(key) {
+		for _, value := range strings.Split(item, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
 func getStringFlagOrEnv(cmd *cobra.Command, flagName, envName, defaultValue string) string {
 	if cmd.Flags().Changed(flagName) {
 		value, _ := cmd.Flags().GetString(flagName)
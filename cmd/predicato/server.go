@@ -11,12 +11,9 @@ import (
 
 	"github.com/soundprediction/predicato"
 	"github.com/soundprediction/predicato/pkg/config"
-	"github.com/soundprediction/predicato/pkg/driver"
-	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/factory"
 	predicatoLogger "github.com/soundprediction/predicato/pkg/logger"
-	"github.com/soundprediction/predicato/pkg/nlp"
 	"github.com/soundprediction/predicato/pkg/server"
-	"github.com/soundprediction/predicato/pkg/telemetry"
 	"github.com/spf13/cobra"
 )
 
@@ -230,123 +227,22 @@ func validateServerConfig(cfg *config.Config) error {
 }
 
 func initializePredicato(cfg *config.Config) (predicato.Predicato, error) {
-	// Initialize database driver
-	var graphDriver driver.GraphDriver
-	var err error
 	logger := slog.New(predicatoLogger.NewColorHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
-	switch cfg.Database.Driver {
-	case "ladybug":
-		graphDriver, err = driver.NewLadybugDriver(cfg.Database.URI, 16)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create ladybug driver: %w", err)
-		}
-
-	case "falkordb":
-		// FalkorDB support would be implemented here
-		return nil, fmt.Errorf("FalkorDB driver not yet implemented")
-	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Database.Driver)
-	}
-
-	// Initialize NLP client
-	var nlProcessor nlp.Client
-	defaultModel := cfg.NLP.Models["default"]
-	if defaultModel.APIKey != "" {
-		switch defaultModel.Provider {
-		case "openai":
-			nlpConfig := nlp.Config{
-				Model:       defaultModel.Model,
-				Temperature: &defaultModel.Temperature,
-				BaseURL:     defaultModel.BaseURL,
-			}
-			baseNLPClient, err := nlp.NewOpenAIClient(defaultModel.APIKey, nlpConfig)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create NLP client: %w", err)
-			}
-			// Wrap with retry client for automatic retry on errors
-			retryClient, err := nlp.NewRetryClient(baseNLPClient, nlp.DefaultRetryConfig())
-			if err != nil {
-				return nil, fmt.Errorf("failed to create retry client: %w", err)
-			}
-
-			// Telemetry using Parquet
-			trackingPath := cfg.Telemetry.ParquetPath
-			if trackingPath == "" {
-				homeDir, err := os.UserHomeDir()
-				if err != nil {
-					return nil, fmt.Errorf("failed to get user home directory: %w", err)
-				}
-				trackingPath = fmt.Sprintf("%s/.predicato/telemetry", homeDir)
-			}
-
-			// Ensure directory exists
-			if err := os.MkdirAll(trackingPath, 0755); err != nil {
-				return nil, fmt.Errorf("failed to create telemetry directory: %w", err)
-			}
-
-			// Initialize Token Tracker
-			tracker, err := nlp.NewTokenTracker(trackingPath)
-			if err != nil {
-				fmt.Printf("Warning: Failed to initialize token tracker: %v\n", err)
-				nlProcessor = retryClient
-			} else {
-				nlProcessor = nlp.NewTokenTrackingClient(retryClient, tracker)
-				fmt.Printf("Token tracking enabled at: %s\n", trackingPath)
-			}
-
-			// Initialize Error Tracking Logger
-			colorHandler := predicatoLogger.NewColorHandler(os.Stderr, &slog.HandlerOptions{
-				Level: slog.LevelInfo,
-			})
-
-			parquetHandler, err := telemetry.NewParquetHandler(colorHandler, trackingPath)
-			if err != nil {
-				fmt.Printf("Warning: Failed to initialize error tracking: %v\n", err)
-			} else {
-				// Update the global logger to use our new handler
-				logger = slog.New(parquetHandler)
-				fmt.Printf("Error tracking enabled\n")
-			}
-		default:
-			return nil, fmt.Errorf("unsupported NLP provider: %s", defaultModel.Provider)
-		}
-	}
-
-	// Initialize embedder client
-	var embedderClient embedder.Client
-	if cfg.Embedding.APIKey != "" {
-		switch cfg.Embedding.Provider {
-		case "openai":
-			embedderConfig := embedder.Config{
-				Model:   cfg.Embedding.Model,
-				BaseURL: cfg.Embedding.BaseURL,
-			}
-			embedderClient = embedder.NewOpenAIEmbedder(cfg.Embedding.APIKey, embedderConfig)
-		default:
-			return nil, fmt.Errorf("unsupported embedding provider: %s", cfg.Embedding.Provider)
-		}
-	}
-
-	// Create Predicato client configuration
-	predicatoConfig := &predicato.Config{
-		GroupID:  "default", // Default group ID - could be made configurable
-		TimeZone: time.UTC,
-	}
 
-	// Create and return Predicato client
-	client, err := predicato.NewClient(graphDriver, nlProcessor, embedderClient, predicatoConfig, logger)
+	client, err := factory.NewClient(cfg, &factory.Options{Logger: logger})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Predicato client: %w", err)
+		return nil, err
 	}
 
 	fmt.Printf("Predicato initialized successfully with driver: %s\n", cfg.Database.Driver)
-	if nlProcessor != nil {
+	if defaultModel, ok := cfg.NLP.Models[factory.ModelDefault]; ok && client.GetNLProcessor() != nil {
 		fmt.Printf("NLP provider: %s, model: %s\n", defaultModel.Provider, defaultModel.Model)
 	}
-	if embedderClient != nil {
-		fmt.Printf("Embedding provider: %s, model: %s\n", cfg.Embedding.Provider, cfg.Embedding.Model)
+	if client.GetEmbedder() != nil {
+		embeddingConfig := factory.EmbeddingConfig(cfg)
+		fmt.Printf("Embedding provider: %s, model: %s\n", embeddingConfig.Provider, embeddingConfig.Model)
 	}
 
 	return client, nil
@@ -0,0 +1,268 @@
+package predicato
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Report token usage, errors and latency",
+	Long: `Report on the telemetry written under "telemetry.parquet_path": the token
+usage of every NLP request and the error logs.
+
+--since and --until take an RFC 3339 time, a date (midnight UTC) or a
+duration before now such as 36h or 7d. --until is exclusive, so
+--since 2026-03-01 --until 2026-03-02 selects one day.`,
+}
+
+var telemetryTokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Show token usage and estimated cost",
+	Long: `Show requests, tokens and estimated cost grouped by --by, a comma-separated
+list of model, day, group and source.`,
+	RunE: runTelemetryTokens,
+}
+
+var telemetryErrorsCmd = &cobra.Command{
+	Use:   "errors",
+	Short: "Show the most frequent errors",
+	RunE:  runTelemetryErrors,
+}
+
+var telemetryLatencyCmd = &cobra.Command{
+	Use:   "latency",
+	Short: "Show NLP request latency percentiles",
+	Long: `Show the p50, p90, p95 and p99 latency of NLP requests grouped by --by, a
+comma-separated list of model, day, group and source. Requests recorded
+before latency was tracked are skipped.`,
+	RunE: runTelemetryLatency,
+}
+
+var (
+	telemetryDir    string
+	telemetrySince  string
+	telemetryUntil  string
+	telemetryFormat string
+	telemetryBy     []string
+	telemetryTop    int
+)
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryTokensCmd)
+	telemetryCmd.AddCommand(telemetryErrorsCmd)
+	telemetryCmd.AddCommand(telemetryLatencyCmd)
+
+	telemetryCmd.PersistentFlags().StringVar(&telemetryDir, "dir", "", "Telemetry directory (default is telemetry.parquet_path)")
+	telemetryCmd.PersistentFlags().StringVar(&telemetrySince, "since", "", "Only include records at or after this time")
+	telemetryCmd.PersistentFlags().StringVar(&telemetryUntil, "until", "", "Only include records before this time")
+	telemetryCmd.PersistentFlags().StringVar(&telemetryFormat, "format", "table", "Output format (table, csv, json)")
+
+	telemetryTokensCmd.Flags().StringSliceVar(&telemetryBy, "by", []string{nlp.UsageByModel}, "Group by model, day, group and/or source")
+	telemetryLatencyCmd.Flags().StringSliceVar(&telemetryBy, "by", []string{nlp.UsageByModel}, "Group by model, day, group and/or source")
+	telemetryErrorsCmd.Flags().IntVar(&telemetryTop, "top", 10, "Number of errors to show (0 for all)")
+}
+
+// telemetryOptions resolves the telemetry directory and time window flags.
+func telemetryOptions() (string, telemetry.TimeWindow, error) {
+	switch telemetryFormat {
+	case "table", "csv", "json":
+	default:
+		return "", telemetry.TimeWindow{}, fmt.Errorf("unsupported format %q, expected table, csv or json", telemetryFormat)
+	}
+
+	dir := telemetryDir
+	if dir == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return "", telemetry.TimeWindow{}, fmt.Errorf("failed to load config: %w", err)
+		}
+		dir = cfg.Telemetry.ParquetPath
+	}
+	if dir == "" {
+		return "", telemetry.TimeWindow{}, fmt.Errorf("no telemetry directory: set --dir or telemetry.parquet_path")
+	}
+
+	now := time.Now()
+	since, err := parseTelemetryTime(telemetrySince, now)
+	if err != nil {
+		return "", telemetry.TimeWindow{}, fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseTelemetryTime(telemetryUntil, now)
+	if err != nil {
+		return "", telemetry.TimeWindow{}, fmt.Errorf("invalid --until: %w", err)
+	}
+	return dir, telemetry.TimeWindow{Since: since, Until: until}, nil
+}
+
+// parseTelemetryTime parses an RFC 3339 time, a date or a duration before now.
+// An empty value is the zero time, which leaves the window open.
+func parseTelemetryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time, date or duration", value)
+}
+
+func runTelemetryTokens(cmd *cobra.Command, args []string) error {
+	dir, window, err := telemetryOptions()
+	if err != nil {
+		return err
+	}
+	records, err := nlp.ReadTokenUsage(dir, window)
+	if err != nil {
+		return fmt.Errorf("failed to read token usage: %w", err)
+	}
+	summaries, err := nlp.SummarizeTokenUsage(records, telemetryBy...)
+	if err != nil {
+		return err
+	}
+
+	header := append(usageHeader(telemetryBy), "REQUESTS", "PROMPT_TOKENS", "COMPLETION_TOKENS", "TOTAL_TOKENS", "ESTIMATED_COST")
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		rows = append(rows, append(usageValues(s.UsageKey, telemetryBy),
+			strconv.Itoa(s.Requests),
+			strconv.Itoa(s.PromptTokens),
+			strconv.Itoa(s.CompletionTokens),
+			strconv.Itoa(s.TotalTokens),
+			strconv.FormatFloat(s.EstimatedCost, 'f', 4, 64),
+		))
+	}
+	return writeTelemetryReport(os.Stdout, header, rows, summaries)
+}
+
+func runTelemetryErrors(cmd *cobra.Command, args []string) error {
+	dir, window, err := telemetryOptions()
+	if err != nil {
+		return err
+	}
+	records, err := telemetry.ReadLogRecords(dir, window)
+	if err != nil {
+		return fmt.Errorf("failed to read error logs: %w", err)
+	}
+	summaries := telemetry.TopErrors(records, telemetryTop)
+
+	header := []string{"COUNT", "FIRST_SEEN", "LAST_SEEN", "MESSAGE", "SOURCE", "SAMPLE_ATTRIBUTES"}
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		rows = append(rows, []string{
+			strconv.Itoa(s.Count),
+			s.FirstSeen.Format(time.RFC3339),
+			s.LastSeen.Format(time.RFC3339),
+			s.Message,
+			s.Source,
+			s.Attributes,
+		})
+	}
+	return writeTelemetryReport(os.Stdout, header, rows, summaries)
+}
+
+func runTelemetryLatency(cmd *cobra.Command, args []string) error {
+	dir, window, err := telemetryOptions()
+	if err != nil {
+		return err
+	}
+	records, err := nlp.ReadTokenUsage(dir, window)
+	if err != nil {
+		return fmt.Errorf("failed to read token usage: %w", err)
+	}
+	summaries, err := nlp.SummarizeLatency(records, telemetryBy...)
+	if err != nil {
+		return err
+	}
+
+	header := append(usageHeader(telemetryBy), "REQUESTS", "P50_MS", "P90_MS", "P95_MS", "P99_MS", "MAX_MS")
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		row := append(usageValues(s.UsageKey, telemetryBy), strconv.Itoa(s.Requests))
+		for _, ms := range []float64{s.P50Ms, s.P90Ms, s.P95Ms, s.P99Ms, s.MaxMs} {
+			row = append(row, strconv.FormatFloat(ms, 'f', 1, 64))
+		}
+		rows = append(rows, row)
+	}
+	return writeTelemetryReport(os.Stdout, header, rows, summaries)
+}
+
+func usageHeader(by []string) []string {
+	header := make([]string, 0, len(by))
+	for _, dim := range by {
+		header = append(header, strings.ToUpper(dim))
+	}
+	return header
+}
+
+func usageValues(key nlp.UsageKey, by []string) []string {
+	values := make([]string, 0, len(by))
+	for _, dim := range by {
+		values = append(values, key.Value(dim))
+	}
+	return values
+}
+
+// writeTelemetryReport writes rows under header as a table or CSV, or data as
+// JSON, according to --format.
+func writeTelemetryReport(out io.Writer, header []string, rows [][]string, data any) error {
+	switch telemetryFormat {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case "csv":
+		w := csv.NewWriter(out)
+		for i := range header {
+			header[i] = strings.ToLower(header[i])
+		}
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+		return w.Error()
+	default:
+		if len(rows) == 0 {
+			fmt.Fprintln(out, "No records found")
+			return nil
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		// Keep multi-line messages on one row
+		flatten := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = flatten.Replace(cell)
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		return w.Flush()
+	}
+}
@@ -0,0 +1,163 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/soundprediction/predicato/pkg/community"
+	"github.com/soundprediction/predicato/pkg/types"
+	"github.com/soundprediction/predicato/pkg/utils"
+)
+
+// UpdateCommunities rebuilds the communities of a group and persists them.
+// A rebuilt community with the same members as an existing one updates it in
+// place; existing communities no rebuilt community matches are removed once
+// every cluster has been built.
+func (c *Client) UpdateCommunities(ctx context.Context, episodeID string, groupID string) ([]*types.Node, []*types.Edge, error) {
+	ctx, err := c.withUsage(ctx, "", groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.logger.Info("Starting community update",
+		"episode_id", episodeID,
+		"group_id", groupID)
+
+	communityResult, err := c.community.BuildCommunities(ctx, []string{groupID}, c.logger)
+	if communityResult == nil || (err != nil && len(communityResult.CommunityNodes) == 0) {
+		return nil, nil, fmt.Errorf("failed to build communities: %w", err)
+	}
+	complete := err == nil
+	if !complete {
+		c.logger.Warn("Some communities failed to build",
+			"episode_id", episodeID,
+			"error", err)
+	}
+
+	c.persistCommunities(ctx, episodeID, groupID, communityResult, complete)
+
+	c.logger.Info("Community update completed",
+		"episode_id", episodeID,
+		"communities", len(communityResult.CommunityNodes),
+		"community_edges", len(communityResult.CommunityEdges))
+
+	return communityResult.CommunityNodes, communityResult.CommunityEdges, nil
+}
+
+// persistCommunities writes rebuilt communities over the group's existing
+// ones and reports the changes to graph event listeners. Stale communities
+// are only removed when complete is true, so a partial rebuild never drops a
+// community whose cluster failed to build. Failures are logged, as a
+// community update does not fail the ingestion that triggered it.
+func (c *Client) persistCommunities(ctx context.Context, episodeID, groupID string, built *community.BuildCommunitiesResult, complete bool) {
+	if len(built.CommunityNodes) == 0 && !complete {
+		return
+	}
+
+	reconciled, err := c.community.ReconcileCommunities(ctx, groupID, built)
+	if err != nil {
+		c.logger.Warn("Failed to match rebuilt communities to existing ones",
+			"episode_id", episodeID,
+			"error", err)
+		reconciled = &community.ReconcileCommunitiesResult{}
+	}
+
+	events := &graphEventBatch{operation: types.GraphOperationCommunities, episodeUUID: episodeID}
+	defer func() { c.emitGraphEvents(ctx, events.events) }()
+
+	if len(built.CommunityNodes) > 0 || len(built.CommunityEdges) > 0 {
+		if _, err := utils.AddNodesAndEdgesBulk(ctx, c.driver, built.CommunityNodes, built.CommunityEdges, []*types.Node{}, []*types.Edge{}, c.embedder); err != nil {
+			c.logger.Warn("Failed to persist community nodes and edges in bulk",
+				"episode_id", episodeID,
+				"community_count", len(built.CommunityNodes),
+				"community_edge_count", len(built.CommunityEdges),
+				"error", err)
+			return
+		}
+		c.logger.Info("Persisted community nodes and edges",
+			"episode_id", episodeID,
+			"community_count", len(built.CommunityNodes),
+			"community_edge_count", len(built.CommunityEdges))
+		events.upserts(reconciled.Existing, built.CommunityNodes, built.CommunityEdges)
+	}
+
+	if !complete {
+		return
+	}
+	for _, stale := range reconciled.Stale {
+		if err := c.driver.DeleteNode(ctx, stale.Uuid, groupID); err != nil {
+			c.logger.Warn("Failed to remove stale community",
+				"community_uuid", stale.Uuid,
+				"error", err)
+			continue
+		}
+		events.node(types.GraphEventDeleted, stale)
+	}
+}
+
+// UpdateEntityCommunity adds an entity to the community it belongs to and
+// re-summarizes that community. See community.Builder.UpdateCommunity.
+func (c *Client) UpdateEntityCommunity(ctx context.Context, entity *types.Node) (*community.UpdateCommunityResult, error) {
+	ctx, err := c.withUsage(ctx, "", entity.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.community.UpdateCommunity(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	events := &graphEventBatch{operation: types.GraphOperationCommunities}
+	for _, node := range result.CommunityNodes {
+		events.node(types.GraphEventUpdated, node)
+	}
+	for _, edge := range result.CommunityEdges {
+		events.edge(types.GraphEventCreated, edge)
+	}
+	c.emitGraphEvents(ctx, events.events)
+	return result, nil
+}
+
+// RefreshCommunity re-summarizes a community from its current members and
+// deletes it when none remain. See community.Builder.RefreshCommunity.
+func (c *Client) RefreshCommunity(ctx context.Context, communityUUID, groupID string) (*community.RefreshCommunityResult, error) {
+	ctx, err := c.withUsage(ctx, "", groupID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.community.RefreshCommunity(ctx, communityUUID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := &graphEventBatch{operation: types.GraphOperationCommunities}
+	if result.Deleted {
+		events.node(types.GraphEventDeleted, result.Community)
+	} else {
+		events.node(types.GraphEventUpdated, result.Community)
+	}
+	c.emitGraphEvents(ctx, events.events)
+	return result, nil
+}
+
+// RemoveCommunities removes the communities of a group along with their
+// HAS_MEMBER edges.
+func (c *Client) RemoveCommunities(ctx context.Context, groupID string) error {
+	if groupID == "" {
+		groupID = c.config.GroupID
+	}
+	communities, err := c.community.GetGroupCommunities(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to get communities: %w", err)
+	}
+
+	events := &graphEventBatch{operation: types.GraphOperationCommunities}
+	defer func() { c.emitGraphEvents(ctx, events.events) }()
+	for _, node := range communities {
+		if err := c.driver.DeleteNode(ctx, node.Uuid, groupID); err != nil {
+			return fmt.Errorf("failed to delete community %s: %w", node.Uuid, err)
+		}
+		events.node(types.GraphEventDeleted, node)
+	}
+	return nil
+}
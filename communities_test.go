@@ -0,0 +1,82 @@
+package predicato
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/community"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// TestPersistCommunitiesDiffsExistingCommunities tests that rebuilt
+// communities update the existing community with the same members, that
+// unmatched existing communities are removed, and that the events say so
+func TestPersistCommunitiesDiffsExistingCommunities(t *testing.T) {
+	drv := newMemoryDriver()
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, uuid := range []string{"kept", "gone"} {
+		drv.nodes[uuid] = &types.Node{Uuid: uuid, Type: types.CommunityNodeType, GroupID: "g", CreatedAt: created}
+	}
+	drv.respond("MATCH (c:Community {group_id",
+		map[string]interface{}{"community_uuid": "kept", "member_uuid": "e1", "edge_uuid": "r1"},
+		map[string]interface{}{"community_uuid": "kept", "member_uuid": "e2", "edge_uuid": "r2"},
+		map[string]interface{}{"community_uuid": "gone", "member_uuid": "e3", "edge_uuid": "r3"},
+	)
+
+	client, err := NewClient(drv, nil, nil, &Config{GroupID: "g", TimeZone: time.UTC}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	var events []types.GraphEvent
+	client.AddGraphEventListener(GraphEventListenerFunc(func(ctx context.Context, batch []types.GraphEvent) error {
+		events = append(events, batch...)
+		return nil
+	}))
+
+	memberEdge := func(uuid, community, member string) *types.Edge {
+		return &types.Edge{BaseEdge: types.BaseEdge{Uuid: uuid, GroupID: "g", SourceNodeID: community, TargetNodeID: member}}
+	}
+	built := &community.BuildCommunitiesResult{
+		CommunityNodes: []*types.Node{
+			{Uuid: "comm_1", Type: types.CommunityNodeType, GroupID: "g"},
+			{Uuid: "comm_2", Type: types.CommunityNodeType, GroupID: "g"},
+		},
+		CommunityEdges: []*types.Edge{
+			memberEdge("comm_e1", "comm_1", "e2"),
+			memberEdge("comm_e2", "comm_1", "e1"),
+			memberEdge("comm_e3", "comm_2", "e4"),
+		},
+	}
+	client.persistCommunities(context.Background(), "episode-1", "g", built, true)
+
+	if got := built.CommunityNodes[0]; got.Uuid != "kept" || !got.CreatedAt.Equal(created) {
+		t.Errorf("matched community = %s created %v; want kept created %v", got.Uuid, got.CreatedAt, created)
+	}
+	if got := built.CommunityEdges[0]; got.Uuid != "r2" || got.SourceNodeID != "kept" {
+		t.Errorf("matched member edge = %s from %s; want r2 from kept", got.Uuid, got.SourceNodeID)
+	}
+	if _, ok := drv.nodes["gone"]; ok {
+		t.Error("stale community was not removed")
+	}
+
+	want := map[string]types.GraphEventType{
+		"kept":    types.GraphEventUpdated,
+		"r1":      types.GraphEventUpdated,
+		"r2":      types.GraphEventUpdated,
+		"comm_2":  types.GraphEventCreated,
+		"comm_e3": types.GraphEventCreated,
+		"gone":    types.GraphEventDeleted,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for _, event := range events {
+		if event.Type != want[event.UUID] {
+			t.Errorf("event for %s = %s, want %s", event.UUID, event.Type, want[event.UUID])
+		}
+		if event.Operation != types.GraphOperationCommunities || event.EpisodeUUID != "episode-1" {
+			t.Errorf("event for %s has operation %q episode %q", event.UUID, event.Operation, event.EpisodeUUID)
+		}
+	}
+}
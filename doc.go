@@ -94,6 +94,28 @@
 //		TimeZone: time.UTC,
 //	}
 //
+// # Embedding Models
+//
+// The graph and fact store record the embedding model and dimensions of their
+// vectors. Searches and ingestion with a different embedder fail with
+// ErrEmbeddingSpaceMismatch instead of comparing incompatible vectors. To
+// switch models, re-embed the stored vectors with ReembedAll; the new vectors
+// are staged and replace the old ones in one transaction:
+//
+//	result, err := client.ReembedAll(ctx, newEmbedder, 100)
+//
+// # Telemetry
+//
+// AddEpisode and its steps, search branches, graph database queries and NLP
+// and embedder requests are traced with OpenTelemetry. Spans go to the global
+// tracer provider, which is a no-op until the application installs one:
+//
+//	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter)))
+//
+// Latency histograms, token counts, the Ladybug write queue depth and circuit
+// breaker states are recorded as Prometheus metrics in telemetry.Registry and
+// served by telemetry.MetricsHandler.
+//
 // # Error Handling
 //
 // The library provides typed errors for common scenarios:
@@ -101,6 +123,7 @@
 //   - ErrNodeNotFound: Returned when a requested node doesn't exist
 //   - ErrEdgeNotFound: Returned when a requested edge doesn't exist
 //   - ErrInvalidEpisode: Returned when an episode is malformed
+//   - ErrEmbeddingSpaceMismatch: Returned when the embedder differs from the stored vectors
 //
 // # Architecture
 //
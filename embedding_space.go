@@ -0,0 +1,109 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/soundprediction/predicato/pkg/cache"
+	"github.com/soundprediction/predicato/pkg/driver"
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// embeddingSpaceState caches the outcome of the embedding space checks.
+type embeddingSpaceState struct {
+	mu           sync.Mutex
+	graphChecked bool
+	factsChecked bool
+	// stage holds the vectors of an unfinished ReembedAll run
+	stage cache.Cache
+}
+
+// EmbeddingSpace returns the embedding model and dimensions recorded for the
+// graph, or nil if the driver does not record them or nothing was recorded yet.
+func (c *Client) EmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error) {
+	store, ok := c.driver.(driver.EmbeddingSpaceStore)
+	if !ok {
+		return nil, nil
+	}
+	return store.GetEmbeddingSpace(ctx)
+}
+
+// embedderSpace returns the embedding space of emb.
+func embedderSpace(emb embedder.Client) *types.EmbeddingSpace {
+	return &types.EmbeddingSpace{Model: embedder.ModelID(emb), Dimensions: emb.Dimensions()}
+}
+
+// checkEmbeddingSpace verifies that the client's embedder matches the
+// embedding space recorded for the graph. A graph without a recorded space
+// adopts the embedder's. The result is cached until ReembedAll switches the
+// graph to another model.
+func (c *Client) checkEmbeddingSpace(ctx context.Context) error {
+	if c.embedder == nil {
+		return nil
+	}
+	store, ok := c.driver.(driver.EmbeddingSpaceStore)
+	if !ok {
+		return nil
+	}
+
+	c.embeddingSpace.mu.Lock()
+	defer c.embeddingSpace.mu.Unlock()
+	if c.embeddingSpace.graphChecked {
+		return nil
+	}
+
+	stored, err := store.GetEmbeddingSpace(ctx)
+	if err != nil {
+		return err
+	}
+	current := embedderSpace(c.embedder)
+	if stored == nil {
+		// A read-only graph cannot adopt the space; searches still proceed
+		if err := store.SetEmbeddingSpace(ctx, current); err != nil {
+			c.logger.Warn("Failed to record embedding space", "space", current.String(), "error", err)
+		}
+	} else if !stored.Matches(current) {
+		return fmt.Errorf("%w: graph was embedded with %s, embedder is %s; run ReembedAll to switch models",
+			ErrEmbeddingSpaceMismatch, stored, current)
+	}
+	c.embeddingSpace.graphChecked = true
+	return nil
+}
+
+// checkFactsEmbeddingSpace verifies that the client's embedder matches the
+// embedding space recorded for the fact store, recording it if unknown.
+func (c *Client) checkFactsEmbeddingSpace(ctx context.Context) error {
+	if c.embedder == nil {
+		return nil
+	}
+	migrator, ok := c.factStore.(factstore.Migrator)
+	if !ok {
+		return nil
+	}
+
+	c.embeddingSpace.mu.Lock()
+	defer c.embeddingSpace.mu.Unlock()
+	if c.embeddingSpace.factsChecked {
+		return nil
+	}
+
+	stored, err := migrator.GetEmbeddingSpace(ctx)
+	if err != nil {
+		return err
+	}
+	current := embedderSpace(c.embedder)
+	if !stored.Matches(current) {
+		return fmt.Errorf("%w: fact store was embedded with %s, embedder is %s; run ReembedAll to switch models",
+			ErrEmbeddingSpaceMismatch, stored, current)
+	}
+	if stored == nil || stored.Model == "" {
+		if err := migrator.SetEmbeddingSpace(ctx, current); err != nil {
+			return err
+		}
+	}
+	c.embeddingSpace.factsChecked = true
+	return nil
+}
@@ -0,0 +1,379 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/prompts"
+	"github.com/soundprediction/predicato/pkg/types"
+	"github.com/soundprediction/predicato/pkg/utils/maintenance"
+)
+
+// resummarizeEpisodeLimit is the number of remaining episodes a node summary
+// is rebuilt from after an episode mentioning it is removed.
+const resummarizeEpisodeLimit = 5
+
+// RemoveEpisodeOptions controls RemoveEpisodeWithOptions.
+type RemoveEpisodeOptions struct {
+	// DryRun computes and returns the planned changes without writing anything.
+	DryRun bool
+	// SkipResummarize leaves the summaries of surviving nodes and communities
+	// unchanged, avoiding the LLM calls needed to rebuild them.
+	SkipResummarize bool
+}
+
+// RemoveEpisodeResult lists the changes made, or planned in a dry run, by
+// RemoveEpisodeWithOptions.
+type RemoveEpisodeResult struct {
+	EpisodeUUID string `json:"episode_uuid"`
+	DryRun      bool   `json:"dry_run"`
+
+	// DeletedEdges were supported only by the episode, or touch a deleted node.
+	DeletedEdges []string `json:"deleted_edges"`
+	// UpdatedEdges are still supported by other episodes and only had the
+	// episode removed from their Episodes.
+	UpdatedEdges []string `json:"updated_edges"`
+	// DeletedNodes were mentioned by no other episode.
+	DeletedNodes []string `json:"deleted_nodes"`
+	// ResummarizedNodes survive but lost a mention or an edge, so their
+	// summaries are rebuilt from the remaining episodes.
+	ResummarizedNodes []string `json:"resummarized_nodes"`
+	// DeletedSourceNodes were the source of no other episode.
+	DeletedSourceNodes []string `json:"deleted_source_nodes"`
+	// AffectedCommunities had a deleted or re-summarized member. They are
+	// re-summarized, or deleted when no members remain.
+	AffectedCommunities []string `json:"affected_communities"`
+	// DeletedCommunities were left without members. Only filled when the
+	// removal is applied.
+	DeletedCommunities []string `json:"deleted_communities"`
+}
+
+// episodeRemovalPlan is the set of changes needed to remove an episode.
+type episodeRemovalPlan struct {
+	episode      *types.Node
+	deleteEdges  []*types.Edge
+	updateEdges  []*types.Edge
+	deleteNodes  []*types.Node
+	resummarize  []string
+	sourceEdges  []string
+	deleteSource []string
+	communities  []string
+}
+
+// RemoveEpisodeWithOptions removes an episode from the graph. The episode is
+// removed from the Episodes of every edge it supports; edges it alone
+// supported are deleted. Entities mentioned by no other episode are deleted
+// with every edge touching them, whichever episodes support it, and source
+// nodes that no longer source any episode are deleted. The summaries of
+// surviving entities and of the communities of affected entities are
+// rebuilt, and communities left without members are deleted.
+//
+// With DryRun set, the planned changes are returned and nothing is written.
+func (c *Client) RemoveEpisodeWithOptions(ctx context.Context, episodeUUID string, opts *RemoveEpisodeOptions) (*RemoveEpisodeResult, error) {
+	if opts == nil {
+		opts = &RemoveEpisodeOptions{}
+	}
+
+	episode, err := types.GetEpisodicNodeByUUID(ctx, c.driver, episodeUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get episode: %w", err)
+	}
+	ctx, err = c.withUsage(ctx, "", episode.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := c.planEpisodeRemoval(ctx, episode)
+	if err != nil {
+		return nil, err
+	}
+	result := plan.result(opts.DryRun)
+	if opts.DryRun {
+		return result, nil
+	}
+
+	wrapper := &driverWrapper{c.driver}
+	err = c.withWriteTransaction(ctx, func(txCtx context.Context) error {
+		for _, edge := range plan.updateEdges {
+			if _, err := types.RetractEntityEdgeEpisode(txCtx, wrapper, edge, episode.Uuid, time.Now().UTC()); err != nil {
+				return fmt.Errorf("failed to remove episode from edge %s: %w", edge.Uuid, err)
+			}
+		}
+		if err := types.DeleteEdgesByUUIDs(txCtx, wrapper, edgeUUIDs(plan.deleteEdges)); err != nil {
+			return fmt.Errorf("failed to delete edges: %w", err)
+		}
+		if err := types.DeleteNodesByUUIDs(txCtx, c.driver, nodeUUIDs(plan.deleteNodes)); err != nil {
+			return fmt.Errorf("failed to delete nodes: %w", err)
+		}
+		// On Ladybug the links do not end at the episode, so deleting it
+		// leaves them behind
+		if err := types.DeleteEdgesByUUIDs(txCtx, wrapper, plan.sourceEdges); err != nil {
+			return fmt.Errorf("failed to delete source links: %w", err)
+		}
+		for _, uuid := range plan.deleteSource {
+			if err := c.driver.DeleteNode(txCtx, uuid, episode.GroupID); err != nil {
+				return fmt.Errorf("failed to delete source node %s: %w", uuid, err)
+			}
+		}
+		if err := types.DeleteNode(txCtx, c.driver, episode); err != nil {
+			return fmt.Errorf("failed to delete episode: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := &graphEventBatch{operation: types.GraphOperationRemoveEpisode, episodeUUID: episode.Uuid}
+	for _, edge := range plan.updateEdges {
+		events.edge(types.GraphEventUpdated, edge)
+	}
+	for _, edge := range plan.deleteEdges {
+		events.edge(types.GraphEventDeleted, edge)
+	}
+	for _, node := range plan.deleteNodes {
+		events.node(types.GraphEventDeleted, node)
+	}
+	for _, uuid := range plan.deleteSource {
+		events.node(types.GraphEventDeleted, &types.Node{Uuid: uuid, GroupID: episode.GroupID, Type: types.SourceNodeType})
+	}
+	events.node(types.GraphEventDeleted, episode)
+
+	if !opts.SkipResummarize {
+		result.ResummarizedNodes, result.DeletedCommunities = c.resummarizeAfterRemoval(ctx, episode.GroupID, plan.resummarize, plan.communities, events)
+	}
+	c.emitGraphEvents(ctx, events.events)
+
+	return result, nil
+}
+
+// planEpisodeRemoval works out which edges, nodes, sources and communities
+// removing episode affects.
+func (c *Client) planEpisodeRemoval(ctx context.Context, episode *types.Node) (*episodeRemovalPlan, error) {
+	plan := &episodeRemovalPlan{episode: episode}
+	wrapper := &driverWrapper{c.driver}
+
+	// Edges list their supporting episodes; the episode's own entity_edges
+	// may also name edges it created, so both are consulted.
+	supported, err := types.GetEntityEdgesByEpisode(ctx, wrapper, episode.Uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edges supported by episode: %w", err)
+	}
+	listed, err := types.GetEntityEdgesByUUIDs(ctx, wrapper, episode.EntityEdges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity edges: %w", err)
+	}
+	seenEdges := make(map[string]bool)
+	var updateEdges []*types.Edge
+	for _, edge := range append(supported, listed...) {
+		if edge == nil || seenEdges[edge.Uuid] || !containsString(edge.Episodes, episode.Uuid) {
+			continue
+		}
+		seenEdges[edge.Uuid] = true
+		if len(edge.Episodes) == 1 {
+			plan.deleteEdges = append(plan.deleteEdges, edge)
+		} else {
+			updateEdges = append(updateEdges, edge)
+		}
+	}
+
+	mentioned, err := types.GetMentionedNodes(ctx, c.driver, []*types.Node{episode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentioned nodes: %w", err)
+	}
+	counts, err := types.CountOtherEpisodeMentions(ctx, c.driver, nodeUUIDs(mentioned), episode.Uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count episode mentions: %w", err)
+	}
+
+	deleted := make(map[string]bool)
+	for _, node := range mentioned {
+		if counts[node.Uuid] == 0 {
+			plan.deleteNodes = append(plan.deleteNodes, node)
+			deleted[node.Uuid] = true
+		}
+	}
+
+	// An edge cannot outlive its endpoints, so edges that other episodes
+	// support are deleted too when they touch a deleted node
+	for _, node := range plan.deleteNodes {
+		touching, err := types.GetEntityEdgesByNode(ctx, wrapper, node.Uuid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get edges of node %s: %w", node.Uuid, err)
+		}
+		for _, edge := range touching {
+			if !seenEdges[edge.Uuid] {
+				seenEdges[edge.Uuid] = true
+				plan.deleteEdges = append(plan.deleteEdges, edge)
+			}
+		}
+	}
+	for _, edge := range updateEdges {
+		if deleted[edge.SourceNodeID] || deleted[edge.TargetNodeID] {
+			plan.deleteEdges = append(plan.deleteEdges, edge)
+		} else {
+			plan.updateEdges = append(plan.updateEdges, edge)
+		}
+	}
+
+	// Surviving nodes that lost a mention or an edge need new summaries
+	affected := make(map[string]bool)
+	addAffected := func(uuid string) {
+		if uuid != "" && !deleted[uuid] && !affected[uuid] {
+			affected[uuid] = true
+			plan.resummarize = append(plan.resummarize, uuid)
+		}
+	}
+	for _, node := range mentioned {
+		addAffected(node.Uuid)
+	}
+	for _, edge := range plan.deleteEdges {
+		addAffected(edge.SourceNodeID)
+		addAffected(edge.TargetNodeID)
+	}
+
+	links, err := types.GetEpisodeSourceLinks(ctx, wrapper, episode.Uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get episode sources: %w", err)
+	}
+	for _, link := range links {
+		if link.EdgeUUID != "" {
+			plan.sourceEdges = append(plan.sourceEdges, link.EdgeUUID)
+		}
+		if link.OtherEpisodes == 0 && link.SourceNodeUUID != "" {
+			plan.deleteSource = append(plan.deleteSource, link.SourceNodeUUID)
+		}
+	}
+
+	plan.communities, err = types.GetNodeCommunities(ctx, c.driver, append(nodeUUIDs(plan.deleteNodes), plan.resummarize...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected communities: %w", err)
+	}
+
+	return plan, nil
+}
+
+// result describes the plan as a RemoveEpisodeResult.
+func (p *episodeRemovalPlan) result(dryRun bool) *RemoveEpisodeResult {
+	return &RemoveEpisodeResult{
+		EpisodeUUID:         p.episode.Uuid,
+		DryRun:              dryRun,
+		DeletedEdges:        edgeUUIDs(p.deleteEdges),
+		UpdatedEdges:        edgeUUIDs(p.updateEdges),
+		DeletedNodes:        nodeUUIDs(p.deleteNodes),
+		ResummarizedNodes:   append([]string{}, p.resummarize...),
+		DeletedSourceNodes:  append([]string{}, p.deleteSource...),
+		AffectedCommunities: append([]string{}, p.communities...),
+		DeletedCommunities:  []string{},
+	}
+}
+
+// resummarizeAfterRemoval rebuilds the summaries of the nodes and
+// communities affected by applied removals from the episodes that remain,
+// returning the nodes re-summarized and the communities deleted for lack of
+// members. Failures are logged, since the removals have already been
+// committed.
+func (c *Client) resummarizeAfterRemoval(ctx context.Context, groupID string, nodes, communities []string, events *graphEventBatch) ([]string, []string) {
+	nodeOps := maintenance.NewNodeOperations(c.driver, c.nlProcessor, c.embedder, prompts.NewLibrary())
+	nodeOps.AttributeNLP = c.nlpModels.NodeAttribute
+	nodeOps.SetLogger(c.logger)
+
+	resummarized := []string{}
+	for _, uuid := range nodes {
+		node, err := c.resummarizeNode(ctx, nodeOps, uuid, groupID)
+		if err != nil {
+			c.logger.Warn("Failed to re-summarize node after episode removal",
+				"node_uuid", uuid,
+				"error", err)
+			continue
+		}
+		if node != nil {
+			resummarized = append(resummarized, uuid)
+			events.node(types.GraphEventUpdated, node)
+		}
+	}
+
+	deleted := []string{}
+	if c.community == nil {
+		return resummarized, deleted
+	}
+	for _, uuid := range communities {
+		refreshed, err := c.community.RefreshCommunity(ctx, uuid, groupID)
+		if err != nil {
+			c.logger.Warn("Failed to refresh community after episode removal",
+				"community_uuid", uuid,
+				"error", err)
+			continue
+		}
+		if refreshed.Deleted {
+			deleted = append(deleted, uuid)
+			events.node(types.GraphEventDeleted, refreshed.Community)
+		} else {
+			events.node(types.GraphEventUpdated, refreshed.Community)
+		}
+	}
+	return resummarized, deleted
+}
+
+// resummarizeNode rebuilds a node's summary from the most recent episodes that
+// still mention it. It returns nil when no such episode remains.
+func (c *Client) resummarizeNode(ctx context.Context, nodeOps *maintenance.NodeOperations, uuid, groupID string) (*types.Node, error) {
+	node, err := c.driver.GetNode(ctx, uuid, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	// Removed episodes are deleted before their nodes are re-summarized
+	episodes, err := types.GetMentioningEpisodes(ctx, c.driver, uuid, "", resummarizeEpisodeLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentioning episodes: %w", err)
+	}
+	if len(episodes) == 0 {
+		return nil, nil
+	}
+
+	contents := make([]string, len(episodes))
+	for i, episode := range episodes {
+		contents[i] = episode.Content
+	}
+	remaining := &types.Node{
+		Uuid:    episodes[0].Uuid,
+		Type:    types.EpisodicNodeType,
+		GroupID: groupID,
+		Content: strings.Join(contents, "\n\n"),
+	}
+
+	// The old summary may repeat the removed episode, so it is rebuilt from
+	// the remaining episodes alone
+	node.Summary = ""
+	updated, err := nodeOps.ExtractAttributesFromNodes(ctx, []*types.Node{node}, remaining, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize node: %w", err)
+	}
+	if len(updated) == 0 || updated[0].Summary == "" {
+		return nil, fmt.Errorf("no summary was generated")
+	}
+	if err := c.driver.UpsertNode(ctx, updated[0]); err != nil {
+		return nil, fmt.Errorf("failed to save node: %w", err)
+	}
+	return updated[0], nil
+}
+
+// edgeUUIDs returns the UUIDs of edges.
+func edgeUUIDs(edges []*types.Edge) []string {
+	uuids := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		uuids = append(uuids, edge.Uuid)
+	}
+	return uuids
+}
+
+// nodeUUIDs returns the UUIDs of nodes.
+func nodeUUIDs(nodes []*types.Node) []string {
+	uuids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		uuids = append(uuids, node.Uuid)
+	}
+	return uuids
+}
@@ -0,0 +1,153 @@
+package predicato
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRemovalDriver returns a driver holding episode ep-1, which alone mentions
+// Bob and alone supports Alice's edge to Bob, and shares Alice and an edge
+// with ep-2. Source src-1 sources only ep-1.
+func newRemovalDriver() *memoryDriver {
+	d := newMemoryDriver()
+	d.respond("MATCH (e:Episodic {uuid: $uuid})",
+		map[string]interface{}{"uuid": "ep-1", "name": "Meeting", "content": "Alice met Bob", "group_id": "g"})
+	d.respond("WHERE $episode_uuid IN e.episodes",
+		map[string]interface{}{"uuid": "edge-only", "name": "MET", "fact": "Alice met Bob", "group_id": "g",
+			"episodes": []interface{}{"ep-1"}, "source_node_uuid": "alice", "target_node_uuid": "bob", "created_at": time.Now()},
+		map[string]interface{}{"uuid": "edge-shared", "name": "WORKS_AT", "fact": "Alice works at Acme", "group_id": "g",
+			"episodes": []interface{}{"ep-1", "ep-2"}, "source_node_uuid": "alice", "target_node_uuid": "acme", "created_at": time.Now()})
+	d.respond("MATCH (episode:Episodic)-[:MENTIONS]->(n:Entity)",
+		map[string]interface{}{"uuid": "alice", "name": "Alice", "group_id": "g"},
+		map[string]interface{}{"uuid": "bob", "name": "Bob", "group_id": "g"})
+	d.respond("count(e) AS episode_count", map[string]interface{}{"uuid": "alice", "episode_count": int64(1)})
+	d.respond("r.name = 'SOURCED_FROM'", map[string]interface{}{"uuid": "src-1", "edge_uuid": "link-1", "other_episodes": int64(0)})
+	d.respond("n:Entity {uuid: $uuid}",
+		map[string]interface{}{"uuid": "ep-2", "name": "Hiring", "content": "Alice joined Acme", "group_id": "g"})
+	d.nodes["alice"] = nodeWithSummary("alice", "Alice", "Alice met Bob at the secret meeting")
+	return d
+}
+
+// TestRemoveEpisodeDryRun tests that a dry run plans the removal without writing
+func TestRemoveEpisodeDryRun(t *testing.T) {
+	d := newRemovalDriver()
+	client, err := NewClient(d, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	result, err := client.RemoveEpisodeWithOptions(context.Background(), "ep-1", &RemoveEpisodeOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("RemoveEpisodeWithOptions: %v", err)
+	}
+	if !result.DryRun || !equalStrings(result.DeletedEdges, "edge-only") || !equalStrings(result.UpdatedEdges, "edge-shared") {
+		t.Errorf("edges = deleted %v, updated %v; want edge-only deleted and edge-shared updated", result.DeletedEdges, result.UpdatedEdges)
+	}
+	if !equalStrings(result.DeletedNodes, "bob") || !equalStrings(result.ResummarizedNodes, "alice") {
+		t.Errorf("nodes = deleted %v, resummarized %v; want bob deleted and alice resummarized", result.DeletedNodes, result.ResummarizedNodes)
+	}
+	if !equalStrings(result.DeletedSourceNodes, "src-1") {
+		t.Errorf("source nodes = %v, want src-1 deleted", result.DeletedSourceNodes)
+	}
+	for _, fragment := range []string{"DELETE", "SET"} {
+		if queries := d.executedContaining(fragment); len(queries) > 0 {
+			t.Errorf("dry run executed %d %s queries", len(queries), fragment)
+		}
+	}
+	if d.nodes["alice"].Summary != "Alice met Bob at the secret meeting" {
+		t.Errorf("dry run changed the summary to %q", d.nodes["alice"].Summary)
+	}
+}
+
+// TestRemoveEpisodeDeletesEdgesOfDeletedNodes tests that edges other episodes
+// support are deleted with a node no remaining episode mentions
+func TestRemoveEpisodeDeletesEdgesOfDeletedNodes(t *testing.T) {
+	d := newRemovalDriver()
+	d.respond("WHERE n.uuid = $node_uuid OR m.uuid = $node_uuid",
+		map[string]interface{}{"uuid": "edge-only", "name": "MET", "fact": "Alice met Bob", "group_id": "g",
+			"episodes": []interface{}{"ep-1"}, "source_node_uuid": "alice", "target_node_uuid": "bob", "created_at": time.Now()},
+		map[string]interface{}{"uuid": "edge-other", "name": "KNOWS", "fact": "Carol knows Bob", "group_id": "g",
+			"episodes": []interface{}{"ep-3"}, "source_node_uuid": "carol", "target_node_uuid": "bob", "created_at": time.Now()})
+	client, err := NewClient(d, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	result, err := client.RemoveEpisodeWithOptions(context.Background(), "ep-1", &RemoveEpisodeOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("RemoveEpisodeWithOptions: %v", err)
+	}
+	if !equalStrings(result.DeletedNodes, "bob") {
+		t.Errorf("deleted nodes = %v, want bob", result.DeletedNodes)
+	}
+	if !equalStrings(result.DeletedEdges, "edge-only", "edge-other") || !equalStrings(result.UpdatedEdges, "edge-shared") {
+		t.Errorf("edges = deleted %v, updated %v; want edge-only and edge-other deleted, edge-shared updated",
+			result.DeletedEdges, result.UpdatedEdges)
+	}
+	// Carol lost an edge, so her summary is rebuilt too
+	if !equalStrings(result.ResummarizedNodes, "alice", "carol") {
+		t.Errorf("resummarized nodes = %v, want alice and carol", result.ResummarizedNodes)
+	}
+}
+
+// TestRemoveEpisode tests applying a removal and rebuilding the summaries of
+// surviving nodes from the remaining episodes alone
+func TestRemoveEpisode(t *testing.T) {
+	d := newRemovalDriver()
+	d.nodes["src-1"] = nodeWithSummary("src-1", "notes.txt", "Content source: notes.txt")
+	nlProcessor := &scriptedNLP{reply: "node_id\tsummary\n0\tAlice works at Acme\n"}
+	client, err := NewClient(d, nlProcessor, &lengthEmbedder{model: "test"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	result, err := client.RemoveEpisodeWithOptions(context.Background(), "ep-1", nil)
+	if err != nil {
+		t.Fatalf("RemoveEpisodeWithOptions: %v", err)
+	}
+	if result.DryRun || !equalStrings(result.ResummarizedNodes, "alice") {
+		t.Errorf("result = %+v, want alice resummarized", result)
+	}
+
+	// The shared edge loses the episode; the rest is deleted
+	if len(d.executedContaining("SET e.episodes")) == 0 {
+		t.Error("the episode was not retracted from the shared edge")
+	}
+	for _, fragment := range []string{"DETACH DELETE n", "WHERE e.uuid IN $uuids"} {
+		if len(d.executedContaining(fragment)) == 0 {
+			t.Errorf("no query containing %q was executed", fragment)
+		}
+	}
+	if _, ok := d.nodes["src-1"]; ok {
+		t.Error("the source node of the removed episode was kept")
+	}
+
+	if len(nlProcessor.prompts) != 1 {
+		t.Fatalf("got %d prompts, want 1", len(nlProcessor.prompts))
+	}
+	prompt := nlProcessor.prompts[0]
+	if strings.Contains(prompt, "secret meeting") || strings.Contains(prompt, "Alice met Bob") {
+		t.Errorf("prompt repeats the removed episode: %s", prompt)
+	}
+	if !strings.Contains(prompt, "Alice joined Acme") {
+		t.Errorf("prompt lacks the remaining episode: %s", prompt)
+	}
+	if got := d.nodes["alice"].Summary; got != "Alice works at Acme" {
+		t.Errorf("summary = %q, want the rebuilt one", got)
+	}
+}
+
+// equalStrings reports whether values holds exactly want, in order.
+func equalStrings(values []string, want ...string) bool {
+	if len(values) != len(want) {
+		return false
+	}
+	for i := range values {
+		if values[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package predicato
+
+import (
+	"context"
+	"sync"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// GraphEventListener receives the changes a Client makes to the graph.
+//
+// Events are delivered after the change has been written, in write order,
+// on the goroutine that made the change. Listeners that do slow work, such
+// as network calls, should hand events off to another goroutine. An error
+// from a listener is logged and does not undo or fail the write.
+type GraphEventListener interface {
+	OnGraphEvents(ctx context.Context, events []types.GraphEvent) error
+}
+
+// GraphEventListenerFunc adapts an ordinary function to a GraphEventListener.
+type GraphEventListenerFunc func(ctx context.Context, events []types.GraphEvent) error
+
+// OnGraphEvents calls f(ctx, events).
+func (f GraphEventListenerFunc) OnGraphEvents(ctx context.Context, events []types.GraphEvent) error {
+	return f(ctx, events)
+}
+
+// graphEventListeners holds a client's listeners. It is shared by pointer so
+// that shallow copies of the client deliver to the same listeners.
+type graphEventListeners struct {
+	mu        sync.RWMutex
+	listeners []GraphEventListener
+}
+
+// AddGraphEventListener registers listener to receive graph change events.
+func (c *Client) AddGraphEventListener(listener GraphEventListener) {
+	if c.events == nil || listener == nil {
+		return
+	}
+	c.events.mu.Lock()
+	defer c.events.mu.Unlock()
+	c.events.listeners = append(c.events.listeners, listener)
+}
+
+// hasGraphEventListeners reports whether any listener is registered, so
+// callers can skip work that only serves events.
+func (c *Client) hasGraphEventListeners() bool {
+	if c.events == nil {
+		return false
+	}
+	c.events.mu.RLock()
+	defer c.events.mu.RUnlock()
+	return len(c.events.listeners) > 0
+}
+
+// emitGraphEvents delivers events to every registered listener.
+func (c *Client) emitGraphEvents(ctx context.Context, events []types.GraphEvent) {
+	if len(events) == 0 || c.events == nil {
+		return
+	}
+	c.events.mu.RLock()
+	listeners := append([]GraphEventListener(nil), c.events.listeners...)
+	c.events.mu.RUnlock()
+
+	for _, listener := range listeners {
+		if err := listener.OnGraphEvents(ctx, events); err != nil {
+			c.logger.Warn("Graph event listener failed",
+				"listener", listener,
+				"events", len(events),
+				"error", err)
+		}
+	}
+}
+
+// existingGraphObjects returns the UUIDs among nodes and edges that are
+// already in the graph, so events for a write can tell creations from
+// updates. It returns nil when no listener is registered.
+func (c *Client) existingGraphObjects(ctx context.Context, groupID string, nodes []*types.Node, edges []*types.Edge) map[string]bool {
+	if !c.hasGraphEventListeners() {
+		return nil
+	}
+
+	existing := make(map[string]bool)
+	if len(nodes) > 0 {
+		uuids := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			if node != nil {
+				uuids = append(uuids, node.Uuid)
+			}
+		}
+		found, err := c.driver.GetNodes(ctx, uuids, groupID)
+		if err != nil {
+			c.logger.Warn("Failed to look up existing nodes for graph events", "error", err)
+		}
+		for _, node := range found {
+			existing[node.Uuid] = true
+		}
+	}
+	if len(edges) > 0 {
+		uuids := make([]string, 0, len(edges))
+		for _, edge := range edges {
+			if edge != nil {
+				uuids = append(uuids, edge.Uuid)
+			}
+		}
+		found, err := c.driver.GetEdges(ctx, uuids, groupID)
+		if err != nil {
+			c.logger.Warn("Failed to look up existing edges for graph events", "error", err)
+		}
+		for _, edge := range found {
+			existing[edge.Uuid] = true
+		}
+	}
+	return existing
+}
+
+// upsertEventType returns GraphEventUpdated for objects in existing and
+// GraphEventCreated otherwise.
+func upsertEventType(existing map[string]bool, uuid string) types.GraphEventType {
+	if existing[uuid] {
+		return types.GraphEventUpdated
+	}
+	return types.GraphEventCreated
+}
+
+// graphEventBatch accumulates the events of one operation.
+type graphEventBatch struct {
+	operation   string
+	episodeUUID string
+	events      []types.GraphEvent
+}
+
+// node records a change to a node.
+func (b *graphEventBatch) node(eventType types.GraphEventType, node *types.Node) {
+	if b == nil || node == nil {
+		return
+	}
+	event := types.NewNodeEvent(eventType, b.operation, node)
+	event.EpisodeUUID = b.episodeUUID
+	b.events = append(b.events, event)
+}
+
+// edge records a change to an edge.
+func (b *graphEventBatch) edge(eventType types.GraphEventType, edge *types.Edge) {
+	if b == nil || edge == nil {
+		return
+	}
+	event := types.NewEdgeEvent(eventType, b.operation, edge)
+	event.EpisodeUUID = b.episodeUUID
+	b.events = append(b.events, event)
+}
+
+// upserts records nodes and edges as created or updated depending on whether
+// they are in existing.
+func (b *graphEventBatch) upserts(existing map[string]bool, nodes []*types.Node, edges []*types.Edge) {
+	for _, node := range nodes {
+		if node != nil {
+			b.node(upsertEventType(existing, node.Uuid), node)
+		}
+	}
+	for _, edge := range edges {
+		if edge != nil {
+			b.edge(upsertEventType(existing, edge.Uuid), edge)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/driver"
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// memoryDriver is an in-memory graph driver for tests. Methods it does not
+// implement panic through the embedded nil interface. Raw queries return
+// the rows registered with respond for a fragment of the query.
+type memoryDriver struct {
+	driver.GraphDriver
+
+	mu        sync.Mutex
+	nodes     map[string]*types.Node
+	edges     map[string]*types.Edge
+	space     *types.EmbeddingSpace
+	responses []queryResponse
+	failures  []queryFailure
+	executed  []string
+
+	// retryWrites makes write transactions roll back their first attempt
+	// and run again, as a managed transaction does after a transient error.
+	retryWrites bool
+}
+
+// queryResponse holds the rows returned for queries containing fragment.
+type queryResponse struct {
+	fragment string
+	rows     []map[string]interface{}
+}
+
+// queryFailure holds the error returned for queries containing fragment.
+type queryFailure struct {
+	fragment string
+	err      error
+}
+
+func newMemoryDriver() *memoryDriver {
+	return &memoryDriver{nodes: make(map[string]*types.Node), edges: make(map[string]*types.Edge)}
+}
+
+func (m *memoryDriver) Session(database *string) driver.GraphDriverSession {
+	if !m.retryWrites {
+		return nil
+	}
+	return &retryingSession{m: m}
+}
+
+// retryingSession runs every write transaction twice, discarding the writes
+// of the first attempt.
+type retryingSession struct {
+	driver.GraphDriverSession
+	m *memoryDriver
+}
+
+func (s *retryingSession) Enter(ctx context.Context) (driver.GraphDriverSession, error) {
+	return s, nil
+}
+
+func (s *retryingSession) Close() error { return nil }
+
+func (s *retryingSession) ExecuteWrite(ctx context.Context, fn func(context.Context, driver.GraphDriverSession, ...interface{}) (interface{}, error), args ...interface{}) (interface{}, error) {
+	s.m.mu.Lock()
+	nodes, edges := maps.Clone(s.m.nodes), maps.Clone(s.m.edges)
+	s.m.mu.Unlock()
+	if _, err := fn(ctx, s, args...); err != nil {
+		return nil, err
+	}
+
+	s.m.mu.Lock()
+	s.m.nodes, s.m.edges = nodes, edges
+	s.m.mu.Unlock()
+	return fn(ctx, s, args...)
+}
+
+func (m *memoryDriver) Provider() driver.GraphProvider { return driver.GraphProviderNeo4j }
+
+// respond registers the rows returned for queries containing fragment.
+func (m *memoryDriver) respond(fragment string, rows ...map[string]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, queryResponse{fragment: fragment, rows: rows})
+}
+
+// fail makes queries containing fragment return err until cleared with
+// fail(fragment, nil).
+func (m *memoryDriver) fail(fragment string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.failures[:0]
+	for _, failure := range m.failures {
+		if failure.fragment != fragment {
+			kept = append(kept, failure)
+		}
+	}
+	m.failures = kept
+	if err != nil {
+		m.failures = append(m.failures, queryFailure{fragment: fragment, err: err})
+	}
+}
+
+func (m *memoryDriver) ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) (interface{}, interface{}, interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executed = append(m.executed, query)
+	for _, failure := range m.failures {
+		if strings.Contains(query, failure.fragment) {
+			return nil, nil, nil, failure.err
+		}
+	}
+	for _, response := range m.responses {
+		if strings.Contains(query, response.fragment) {
+			return response.rows, nil, nil, nil
+		}
+	}
+	return []map[string]interface{}{}, nil, nil, nil
+}
+
+// executedContaining returns the executed queries containing fragment.
+func (m *memoryDriver) executedContaining(fragment string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var queries []string
+	for _, query := range m.executed {
+		if strings.Contains(query, fragment) {
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+func (m *memoryDriver) GetNode(ctx context.Context, nodeID, groupID string) (*types.Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return nil, fmt.Errorf("node %s not found", nodeID)
+	}
+	copied := *node
+	return &copied, nil
+}
+
+func (m *memoryDriver) GetNodes(ctx context.Context, nodeIDs []string, groupID string) ([]*types.Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var nodes []*types.Node
+	for _, uuid := range nodeIDs {
+		if node, ok := m.nodes[uuid]; ok {
+			copied := *node
+			nodes = append(nodes, &copied)
+		}
+	}
+	return nodes, nil
+}
+
+func (m *memoryDriver) GetEdges(ctx context.Context, edgeIDs []string, groupID string) ([]*types.Edge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var edges []*types.Edge
+	for _, uuid := range edgeIDs {
+		if edge, ok := m.edges[uuid]; ok {
+			copied := *edge
+			edges = append(edges, &copied)
+		}
+	}
+	return edges, nil
+}
+
+func (m *memoryDriver) UpsertNode(ctx context.Context, node *types.Node) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *node
+	m.nodes[node.Uuid] = &copied
+	return nil
+}
+
+func (m *memoryDriver) DeleteNode(ctx context.Context, nodeID, groupID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.nodes, nodeID)
+	return nil
+}
+
+func (m *memoryDriver) UpsertEdge(ctx context.Context, edge *types.Edge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *edge
+	m.edges[edge.Uuid] = &copied
+	return nil
+}
+
+func (m *memoryDriver) GetStats(ctx context.Context, groupID string) (*driver.GraphStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &driver.GraphStats{NodeCount: int64(len(m.nodes)), EdgeCount: int64(len(m.edges))}, nil
+}
+
+func (m *memoryDriver) ScanNodes(ctx context.Context, nodeType types.NodeType, afterUUID string, limit int) ([]*types.Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var nodes []*types.Node
+	for _, node := range m.nodes {
+		if node.Type == nodeType && node.Uuid > afterUUID {
+			copied := *node
+			nodes = append(nodes, &copied)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Uuid < nodes[j].Uuid })
+	if len(nodes) > limit {
+		nodes = nodes[:limit]
+	}
+	return nodes, nil
+}
+
+func (m *memoryDriver) ScanEdges(ctx context.Context, afterUUID string, limit int) ([]*types.Edge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var edges []*types.Edge
+	for _, edge := range m.edges {
+		if edge.Type == types.EntityEdgeType && edge.Uuid > afterUUID {
+			copied := *edge
+			edges = append(edges, &copied)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].Uuid < edges[j].Uuid })
+	if len(edges) > limit {
+		edges = edges[:limit]
+	}
+	return edges, nil
+}
+
+// SearchNodes returns the entity nodes of groupID in UUID order, ignoring
+// the query.
+func (m *memoryDriver) SearchNodes(ctx context.Context, query, groupID string, options *driver.SearchOptions) ([]*types.Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var nodes []*types.Node
+	for _, node := range m.nodes {
+		if node.Type == types.EntityNodeType && node.GroupID == groupID {
+			copied := *node
+			nodes = append(nodes, &copied)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Uuid < nodes[j].Uuid })
+	return nodes, nil
+}
+
+// SearchEdges returns the entity edges of groupID in UUID order, ignoring
+// the query.
+func (m *memoryDriver) SearchEdges(ctx context.Context, query, groupID string, options *driver.SearchOptions) ([]*types.Edge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var edges []*types.Edge
+	for _, edge := range m.edges {
+		if edge.Type == types.EntityEdgeType && edge.GroupID == groupID {
+			copied := *edge
+			edges = append(edges, &copied)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].Uuid < edges[j].Uuid })
+	return edges, nil
+}
+
+func (m *memoryDriver) SetNodeEmbeddings(ctx context.Context, nodes []*types.Node) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, node := range nodes {
+		if stored, ok := m.nodes[node.Uuid]; ok {
+			stored.NameEmbedding, stored.Embedding = node.NameEmbedding, node.Embedding
+		}
+	}
+	return nil
+}
+
+func (m *memoryDriver) SetEdgeEmbeddings(ctx context.Context, edges []*types.Edge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, edge := range edges {
+		if stored, ok := m.edges[edge.Uuid]; ok {
+			stored.FactEmbedding, stored.Embedding = edge.FactEmbedding, edge.Embedding
+		}
+	}
+	return nil
+}
+
+func (m *memoryDriver) RetrieveEpisodes(ctx context.Context, referenceTime time.Time, groupIDs []string, limit int, episodeType *types.EpisodeType) ([]*types.Node, error) {
+	return nil, nil
+}
+
+func (m *memoryDriver) GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.space, nil
+}
+
+func (m *memoryDriver) SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.space = space
+	return nil
+}
+
+// lengthEmbedder embeds every text as [len(text), 1], reporting model as its ID.
+type lengthEmbedder struct {
+	model string
+	calls int
+}
+
+func (e *lengthEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text)), 1}
+	}
+	return vectors, nil
+}
+
+func (e *lengthEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (e *lengthEmbedder) Dimensions() int { return 2 }
+
+func (e *lengthEmbedder) Close() error { return nil }
+
+func (e *lengthEmbedder) ModelID() string { return e.model }
+
+// scriptedNLP answers every chat with reply, recording the prompts.
+type scriptedNLP struct {
+	reply   string
+	prompts []string
+}
+
+func (s *scriptedNLP) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
+	var prompt strings.Builder
+	for _, message := range messages {
+		prompt.WriteString(message.Content)
+	}
+	s.prompts = append(s.prompts, prompt.String())
+	return &types.Response{Content: s.reply}, nil
+}
+
+func (s *scriptedNLP) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
+	return s.Chat(ctx, messages)
+}
+
+func (s *scriptedNLP) GetCapabilities() []nlp.TaskCapability { return nil }
+
+func (s *scriptedNLP) Close() error { return nil }
+
+// nodeWithSummary returns an entity node of group g.
+func nodeWithSummary(uuid, name, summary string) *types.Node {
+	return &types.Node{Uuid: uuid, Name: name, Summary: summary, GroupID: "g", Type: types.EntityNodeType}
+}
@@ -21,11 +21,17 @@ func (c *Client) ClearGraph(ctx context.Context, groupID string) error {
 	}
 
 	// Delete all nodes (this will also delete associated edges in most graph databases)
+	events := &graphEventBatch{operation: types.GraphOperationClearGraph}
+	defer func() { c.emitGraphEvents(ctx, events.events) }()
 	for _, node := range allNodes {
 		if err := c.driver.DeleteNode(ctx, node.Uuid, groupID); err != nil {
 			return fmt.Errorf("failed to delete node %s: %w", node.Uuid, err)
 		}
+		events.node(types.GraphEventDeleted, node)
 	}
+	// The edges went with their nodes without an event each, so consumers
+	// are told the whole group is gone
+	events.events = append(events.events, types.NewGroupClearedEvent(events.operation, groupID))
 
 	return nil
 }
@@ -41,100 +47,50 @@ func (c *Client) getAllNodesForGroup(ctx context.Context, groupID string) ([]*ty
 }
 
 // CreateIndices creates database indices and constraints for optimal performance.
+// Drivers with native vector indexes size them from the embedder's dimensions.
 func (c *Client) CreateIndices(ctx context.Context) error {
+	c.setEmbeddingDimensions()
 	return c.driver.CreateIndices(ctx)
 }
 
-// RemoveEpisode removes an episode and its associated nodes and edges from the knowledge graph.
-// This is an exact translation of the Python Predicato.remove_episode() method.
-func (c *Client) RemoveEpisode(ctx context.Context, episodeUUID string) error {
-	// Find the episode to be deleted
-	// Equivalent to: episode = await EpisodicNode.get_by_uuid(self.driver, episode_uuid)
-	episode, err := types.GetEpisodicNodeByUUID(ctx, c.driver, episodeUUID)
-	if err != nil {
-		return fmt.Errorf("failed to get episode: %w", err)
+// setEmbeddingDimensions passes the embedder's output dimension to drivers
+// that build native vector indexes.
+func (c *Client) setEmbeddingDimensions() {
+	if c.embedder == nil {
+		return
 	}
-
-	// Find edges mentioned by the episode
-	// Equivalent to: edges = await EntityEdge.get_by_uuids(self.driver, episode.entity_edges)
-	wrapper := &driverWrapper{c.driver}
-	edges, err := types.GetEntityEdgesByUUIDs(ctx, wrapper, episode.EntityEdges)
-	if err != nil {
-		return fmt.Errorf("failed to get entity edges: %w", err)
+	if setter, ok := c.driver.(driver.EmbeddingDimensionsSetter); ok {
+		setter.SetEmbeddingDimensions(c.embedder.Dimensions())
 	}
+}
 
-	// We should only delete edges created by the episode
-	// Equivalent to: if edge.episodes and edge.episodes[0] == episode.uuid:
-	var edgesToDelete []*types.Edge
-	for _, edge := range edges {
-		if len(edge.Episodes) > 0 && edge.Episodes[0] == episode.Uuid {
-			edgesToDelete = append(edgesToDelete, edge)
-		}
+// withWriteTransaction runs fn in a single driver write transaction. Driver
+// calls made with the context passed to fn commit or roll back together.
+// Drivers without session support run fn directly. Managed transactions run
+// fn again after a transient failure, so fn must reset any state it
+// accumulates.
+func (c *Client) withWriteTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session := c.driver.Session(nil)
+	if session == nil {
+		return fn(ctx)
 	}
-
-	// Find nodes mentioned by the episode
-	// Equivalent to: nodes = await get_mentioned_nodes(self.driver, [episode])
-	mentionedNodes, err := types.GetMentionedNodes(ctx, c.driver, []*types.Node{episode})
+	session, err := session.Enter(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get mentioned nodes: %w", err)
-	}
-
-	// We should delete all nodes that are only mentioned in the deleted episode
-	var nodesToDelete []*types.Node
-	for _, node := range mentionedNodes {
-		// Equivalent to: query: LiteralString = 'MATCH (e:Episodic)-[:MENTIONS]->(n:Entity {uuid: $uuid}) RETURN count(*) AS episode_count'
-		query := `MATCH (e:Episodic)-[:MENTIONS]->(n:Entity {uuid: $uuid}) RETURN count(*) AS episode_count`
-		records, _, _, err := c.driver.ExecuteQuery(ctx, query, map[string]interface{}{
-			"uuid": node.Uuid,
-		})
-		if err != nil {
-			c.logger.Warn("failed to check episode count for node, skipping deletion",
-				"node_uuid", node.Uuid,
-				"error", err)
-			continue // Skip on error, don't delete
-		}
-
-		// Check if only one episode mentions this node
-		if recordList, ok := records.([]map[string]interface{}); ok {
-			for _, record := range recordList {
-				if count, ok := record["episode_count"].(int64); ok && count == 1 {
-					nodesToDelete = append(nodesToDelete, node)
-				}
-			}
-		}
+		return fmt.Errorf("failed to open session: %w", err)
 	}
+	defer session.Close()
 
-	// Delete edges first
-	// Equivalent to: await Edge.delete_by_uuids(self.driver, [edge.uuid for edge in edges_to_delete])
-	if len(edgesToDelete) > 0 {
-		edgeUUIDs := make([]string, len(edgesToDelete))
-		for i, edge := range edgesToDelete {
-			edgeUUIDs[i] = edge.Uuid
-		}
-		if err := types.DeleteEdgesByUUIDs(ctx, wrapper, edgeUUIDs); err != nil {
-			return fmt.Errorf("failed to delete edges: %w", err)
-		}
-	}
-
-	// Delete nodes
-	// Equivalent to: await Node.delete_by_uuids(self.driver, [node.uuid for node in nodes_to_delete])
-	if len(nodesToDelete) > 0 {
-		nodeUUIDs := make([]string, len(nodesToDelete))
-		for i, node := range nodesToDelete {
-			nodeUUIDs[i] = node.Uuid
-		}
-		if err := types.DeleteNodesByUUIDs(ctx, c.driver, nodeUUIDs); err != nil {
-			return fmt.Errorf("failed to delete nodes: %w", err)
-		}
-	}
-
-	// Finally, delete the episode itself
-	// Equivalent to: await episode.delete(self.driver)
-	if err := types.DeleteNode(ctx, c.driver, episode); err != nil {
-		return fmt.Errorf("failed to delete episode: %w", err)
-	}
+	_, err = session.ExecuteWrite(ctx, func(txCtx context.Context, _ driver.GraphDriverSession, _ ...interface{}) (interface{}, error) {
+		return nil, fn(txCtx)
+	})
+	return err
+}
 
-	return nil
+// RemoveEpisode removes an episode and its associated nodes and edges from the knowledge graph.
+// See RemoveEpisodeWithOptions for what is removed and updated.
+func (c *Client) RemoveEpisode(ctx context.Context, episodeUUID string) error {
+	_, err := c.RemoveEpisodeWithOptions(ctx, episodeUUID, nil)
+	return err
 }
 
 // Close closes the client and all its connections.
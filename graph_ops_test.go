@@ -0,0 +1,48 @@
+package predicato
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// TestClearGraphReportsTheClearedGroup tests that clearing a group reports
+// its deleted nodes and then the group as cleared, so consumers also drop
+// the edges that went with the nodes
+func TestClearGraphReportsTheClearedGroup(t *testing.T) {
+	d := newMemoryDriver()
+	d.nodes["alice"] = nodeWithSummary("alice", "Alice", "")
+	d.nodes["bob"] = nodeWithSummary("bob", "Bob", "")
+	d.edges["met"] = &types.Edge{BaseEdge: types.BaseEdge{Uuid: "met", GroupID: "g", SourceNodeID: "alice", TargetNodeID: "bob"}, Type: types.EntityEdgeType}
+	client, err := NewClient(d, nil, nil, &Config{GroupID: "g", TimeZone: time.UTC}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	var events []types.GraphEvent
+	client.AddGraphEventListener(GraphEventListenerFunc(func(ctx context.Context, batch []types.GraphEvent) error {
+		events = append(events, batch...)
+		return nil
+	}))
+
+	if err := client.ClearGraph(context.Background(), ""); err != nil {
+		t.Fatalf("ClearGraph: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	for i, uuid := range []string{"alice", "bob"} {
+		if got := events[i]; got.Type != types.GraphEventDeleted || got.UUID != uuid {
+			t.Errorf("event %d = %s %s, want %s deleted", i, got.UUID, got.Type, uuid)
+		}
+	}
+	last := events[2]
+	if last.Type != types.GraphEventCleared || last.Kind != types.GraphObjectGroup || last.GroupID != "g" {
+		t.Errorf("last event = %s %s of group %q, want the group g cleared", last.Kind, last.Type, last.GroupID)
+	}
+	if last.Operation != types.GraphOperationClearGraph {
+		t.Errorf("operation = %q, want %q", last.Operation, types.GraphOperationClearGraph)
+	}
+}
@@ -3,6 +3,7 @@ package predicato
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/soundprediction/predicato/pkg/driver"
 	"github.com/soundprediction/predicato/pkg/prompts"
 	"github.com/soundprediction/predicato/pkg/search"
+	"github.com/soundprediction/predicato/pkg/telemetry"
 	"github.com/soundprediction/predicato/pkg/types"
 	"github.com/soundprediction/predicato/pkg/utils"
 	"github.com/soundprediction/predicato/pkg/utils/maintenance"
@@ -225,11 +227,41 @@ func (c *Client) Add(ctx context.Context, episodes []types.Episode, options *Add
 // This implementation uses bulk processing with sophisticated deduplication.
 // Content is automatically chunked if it exceeds MaxCharacters, but the same
 // efficient bulk processing path is used for both single and multi-chunk episodes.
-func (c *Client) AddEpisode(ctx context.Context, episode types.Episode, options *AddEpisodeOptions) (*types.AddEpisodeResults, error) {
+func (c *Client) AddEpisode(ctx context.Context, episode types.Episode, options *AddEpisodeOptions) (result *types.AddEpisodeResults, err error) {
 	if options == nil {
 		options = &AddEpisodeOptions{}
 	}
 
+	groupID := episode.GroupID
+	if groupID == "" {
+		groupID = c.config.GroupID
+	}
+	ctx, span := telemetry.StartSpan(ctx, "predicato.AddEpisode",
+		telemetry.AttrGroupID.String(groupID),
+		telemetry.AttrEpisodeID.String(episode.ID),
+	)
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	ctx, err = c.withUsage(ctx, options.Usage, groupID)
+	if err != nil {
+		return nil, err
+	}
+	// Tag token usage with the group for the telemetry reports
+	ctx = context.WithValue(ctx, types.ContextKeyGroupID, groupID)
+	if err := c.checkEmbeddingSpace(ctx); err != nil {
+		return nil, err
+	}
+
+	if len(options.Preprocessors) > 0 {
+		if err := preprocessEpisode(ctx, &episode, options); err != nil {
+			return nil, err
+		}
+		// The episode is processed once; later stages get options without preprocessors
+		processed := *options
+		processed.Preprocessors = nil
+		options = &processed
+	}
+
 	// Inject ingestion source into context for token tracking
 	ingestionSource := episode.Source
 	if ingestionSource == "" {
@@ -270,6 +302,16 @@ func (c *Client) AddEpisode(ctx context.Context, episode types.Episode, options
 	return c.addEpisodeChunked(ctx, episode, options, maxCharacters)
 }
 
+// preprocessEpisode runs the episode preprocessors configured in options.
+func preprocessEpisode(ctx context.Context, episode *types.Episode, options *AddEpisodeOptions) error {
+	for _, preprocessor := range options.Preprocessors {
+		if err := preprocessor.PreprocessEpisode(ctx, episode); err != nil {
+			return fmt.Errorf("failed to preprocess episode %s: %w", episode.ID, err)
+		}
+	}
+	return nil
+}
+
 // addEpisodeChunked chunks long episode content and uses bulk deduplication
 // processing across all chunks to efficiently handle large episodes.
 func (c *Client) addEpisodeChunked(ctx context.Context, episode types.Episode, options *AddEpisodeOptions, maxCharacters int) (*types.AddEpisodeResults, error) {
@@ -296,6 +338,7 @@ func (c *Client) addEpisodeChunked(ctx context.Context, episode types.Episode, o
 	// STEP 4: Initialize maintenance operations
 	nodeOps := maintenance.NewNodeOperations(c.driver, c.nlProcessor, c.embedder, prompts.NewLibrary())
 	nodeOps.ExtractionNLP = c.nlpModels.NodeExtraction
+	nodeOps.EntityExtractor = c.config.EntityExtractor
 	nodeOps.ReflexionNLP = c.nlpModels.NodeReflexion
 	nodeOps.ResolutionNLP = c.nlpModels.NodeResolution
 	nodeOps.AttributeNLP = c.nlpModels.NodeAttribute
@@ -307,16 +350,22 @@ func (c *Client) addEpisodeChunked(ctx context.Context, episode types.Episode, o
 
 	edgeOps := maintenance.NewEdgeOperations(c.driver, c.nlProcessor, c.embedder, prompts.NewLibrary())
 	edgeOps.ExtractionNLP = c.nlpModels.EdgeExtraction
+	edgeOps.RelationExtractor = c.config.RelationExtractor
 	edgeOps.ResolutionNLP = c.nlpModels.EdgeResolution
 	edgeOps.SkipResolution = options.SkipEdgeResolution
 	edgeOps.UseYAML = options.UseYAML
 	edgeOps.SetLogger(c.logger)
 
 	// STEP 5: Extract entities from all chunks
-	extractedNodesByChunk, err := c.extractEntitiesFromAllChunks(ctx, episode.ID, chunkData.chunkEpisodeNodes, chunkData.previousEpisodes, options, nodeOps)
+	stepCtx, endStep := traceStep(ctx, "extraction", episode.ID)
+	extractedNodesByChunk, err := c.extractEntitiesFromAllChunks(stepCtx, episode.ID, chunkData.chunkEpisodeNodes, chunkData.previousEpisodes, options, nodeOps)
+	endStep(err)
 	if err != nil {
 		return nil, err
 	}
+	// Spans come back relative to each chunk; edges are extracted from the
+	// whole episode, so make them relative to its content.
+	anchorNodeSpans(chunkData.mainEpisodeNode.Content, extractedNodesByChunk, chunkOffsets(chunkData.mainEpisodeNode.Content, chunks))
 
 	// OPTIMIZATION: Filter out chunks with no extracted entities
 	var filteredNodesByChunk [][]*types.Node
@@ -348,25 +397,39 @@ func (c *Client) addEpisodeChunked(ctx context.Context, episode types.Episode, o
 	// Only process entities and relationships if we have chunks with entities
 	if chunksWithEntities > 0 {
 		// STEP 6: Deduplicate entities across chunks (only chunks with entities)
-		dedupeResult, allResolvedNodes, err := c.deduplicateEntitiesAcrossChunks(ctx, episode.ID, filteredNodesByChunk, filteredEpisodeTuples, options, nodeOps)
+		stepCtx, endStep := traceStep(ctx, "dedup", episode.ID)
+		dedupeResult, allResolvedNodes, err := c.deduplicateEntitiesAcrossChunks(stepCtx, episode.ID, filteredNodesByChunk, filteredEpisodeTuples, options, nodeOps)
+		endStep(err)
 		if err != nil {
 			return nil, err
 		}
 
 		// STEP 7: Extract relationships
-		allExtractedEdges, err := c.extractRelationshipsFromChunks(ctx, episode.ID, chunkData.mainEpisodeNode, dedupeResult, chunkData.previousEpisodes, options, edgeOps)
+		stepCtx, endStep = traceStep(ctx, "edge_extraction", episode.ID)
+		allExtractedEdges, err := c.extractRelationshipsFromChunks(stepCtx, episode.ID, chunkData.mainEpisodeNode, dedupeResult, chunkData.previousEpisodes, options, edgeOps)
+		endStep(err)
 		if err != nil {
 			return nil, err
 		}
+		// The spans have served relation extraction; keep them off graph nodes
+		clearNodeSpans(extractedNodesByChunk)
+		clearNodeSpans([][]*types.Node{allResolvedNodes})
+		for _, nodes := range dedupeResult.NodesByEpisode {
+			clearNodeSpans([][]*types.Node{nodes})
+		}
 
 		// STEP 8: Resolve and persist relationships
-		resolvedEdges, invalidatedEdges, err = c.resolveAndPersistRelationships(ctx, episode.ID, allExtractedEdges, chunkData.mainEpisodeNode, allResolvedNodes, options, edgeOps)
+		stepCtx, endStep = traceStep(ctx, "edge_resolution", episode.ID)
+		resolvedEdges, invalidatedEdges, err = c.resolveAndPersistRelationships(stepCtx, episode.ID, allExtractedEdges, chunkData.mainEpisodeNode, allResolvedNodes, options, edgeOps)
+		endStep(err)
 		if err != nil {
 			return nil, err
 		}
 
 		// STEP 9: Extract attributes
-		hydratedNodes, err = c.extractEntityAttributes(ctx, episode.ID, allResolvedNodes, chunkData.mainEpisodeNode, chunkData.previousEpisodes, options, nodeOps)
+		stepCtx, endStep = traceStep(ctx, "attributes", episode.ID)
+		hydratedNodes, err = c.extractEntityAttributes(stepCtx, episode.ID, allResolvedNodes, chunkData.mainEpisodeNode, chunkData.previousEpisodes, options, nodeOps)
+		endStep(err)
 		if err != nil {
 			return nil, err
 		}
@@ -377,18 +440,18 @@ func (c *Client) addEpisodeChunked(ctx context.Context, episode types.Episode, o
 			return nil, err
 		}
 
-		// STEP 11: Perform final graph updates
-		if err := c.performFinalGraphUpdates(ctx, episode.ID, chunkData.mainEpisodeNode, hydratedNodes, resolvedEdges, invalidatedEdges, episodicEdges); err != nil {
-			return nil, err
-		}
 	} else {
 		c.logger.Info("No entities extracted from any chunks, skipping entity and relationship processing",
 			"episode_id", episode.ID)
+	}
 
-		// Still need to persist the episode node with its content
-		if err := c.driver.UpsertNode(ctx, chunkData.mainEpisodeNode); err != nil {
-			return nil, fmt.Errorf("failed to persist episode node: %w", err)
-		}
+	// STEP 11: Perform final graph updates. Nothing above writes to the graph,
+	// so the episode is either fully persisted here or not at all.
+	stepCtx, endStep = traceStep(ctx, "graph_update", episode.ID)
+	err = c.performFinalGraphUpdates(stepCtx, episode, chunkData.mainEpisodeNode, hydratedNodes, resolvedEdges, invalidatedEdges, episodicEdges)
+	endStep(err)
+	if err != nil {
+		return nil, err
 	}
 
 	// STEP 12: Prepare result
@@ -402,7 +465,9 @@ func (c *Client) addEpisodeChunked(ctx context.Context, episode types.Episode, o
 	}
 
 	// STEP 13: Update communities
-	communities, communityEdges, err := c.UpdateCommunities(ctx, episode.ID, episode.GroupID)
+	stepCtx, endStep = traceStep(ctx, "communities", episode.ID)
+	communities, communityEdges, err := c.UpdateCommunities(stepCtx, episode.ID, episode.GroupID)
+	endStep(err)
 	if err != nil {
 		return nil, err
 	}
@@ -414,24 +479,7 @@ func (c *Client) addEpisodeChunked(ctx context.Context, episode types.Episode, o
 		result.CommunityEdges = communityEdges
 	}
 
-	// STEP 14: Persist community nodes and edges using bulk operation
-	if len(communities) > 0 || len(communityEdges) > 0 {
-		_, err = utils.AddNodesAndEdgesBulk(ctx, c.driver, communities, communityEdges, []*types.Node{}, []*types.Edge{}, c.embedder)
-		if err != nil {
-			c.logger.Warn("Failed to persist community nodes and edges in bulk",
-				"episode_id", episode.ID,
-				"community_count", len(communities),
-				"community_edge_count", len(communityEdges),
-				"error", err)
-		} else {
-			c.logger.Info("Persisted community nodes and edges",
-				"episode_id", episode.ID,
-				"community_count", len(communities),
-				"community_edge_count", len(communityEdges))
-		}
-	}
-
-	// STEP 15: Log final results
+	// STEP 14: Log final results
 	c.logger.Info("Chunked episode processing completed with bulk deduplication",
 		"episode_id", episode.ID,
 		"total_chunks", len(chunks),
@@ -519,6 +567,10 @@ func (c *Client) AddToEpisode(ctx context.Context, episodeID string, additionalC
 
 	// Use the client's configured group ID
 	groupID := c.config.GroupID
+	ctx, err := c.withUsage(ctx, options.Usage, groupID)
+	if err != nil {
+		return nil, err
+	}
 
 	// 1. Retrieve and validate the existing episode
 	existingEpisode, err := c.retrieveAndValidateEpisode(ctx, episodeID, groupID)
@@ -567,7 +619,12 @@ type chunkEpisodeData struct {
 }
 
 // prepareAndValidateEpisode chunks the episode content and validates entity types and group ID.
+// A maxCharacters of zero uses the same 2048 character default as AddEpisode.
 func (c *Client) prepareAndValidateEpisode(episode *types.Episode, options *AddEpisodeOptions, maxCharacters int) ([]string, error) {
+	if maxCharacters <= 0 {
+		maxCharacters = 2048
+	}
+
 	// Chunk the content
 	chunks := chunkText(episode.Content, maxCharacters)
 
@@ -675,9 +732,9 @@ func (c *Client) createChunkEpisodeStructures(ctx context.Context, episode types
 		}
 
 		if i == 0 {
-			// Create the actual persisted episode node with first chunk
+			// Build the episode node that the final graph write persists
 			var err error
-			data.mainEpisodeNode, err = c.createEpisodeNode(ctx, chunkEpisode, options)
+			data.mainEpisodeNode, err = c.buildEpisodeNode(ctx, chunkEpisode, options)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create episode node: %w", err)
 			}
@@ -689,28 +746,6 @@ func (c *Client) createChunkEpisodeStructures(ctx context.Context, episode types
 	data.mainEpisodeNode.Content = fullContent
 	data.mainEpisodeNode.UpdatedAt = time.Now()
 
-	// STEP: Create source node and edge if episode has a source
-	if episode.Source != "" {
-		sourceNode, isNew, err := c.getOrCreateSourceNode(ctx, episode.Source, episode.GroupID)
-		if err != nil {
-			c.logger.Warn("Failed to create source node", "source", episode.Source, "error", err)
-		} else if sourceNode != nil {
-			if isNew {
-				c.logger.Info("Created new source node for episode", "source", episode.Source, "episode_id", episode.ID)
-			} else {
-				c.logger.Debug("Using existing source node for episode", "source", episode.Source, "episode_id", episode.ID)
-			}
-
-			// Create edge from source to episode
-			sourceEdge, err := c.createSourceEdge(ctx, sourceNode, data.mainEpisodeNode)
-			if err != nil {
-				c.logger.Warn("Failed to create source edge", "source", episode.Source, "episode_id", episode.ID, "error", err)
-			} else if sourceEdge != nil {
-				c.logger.Debug("Created source edge", "source", episode.Source, "episode_id", episode.ID, "edge_id", sourceEdge.Uuid)
-			}
-		}
-	}
-
 	return data, nil
 }
 
@@ -788,14 +823,9 @@ func (c *Client) deduplicateEntitiesAcrossChunks(ctx context.Context, episodeID
 		}
 	}
 
-	// EARLY WRITE: Persist deduplicated nodes
-	c.logger.Info("Persisting deduplicated nodes early",
-		"episode_id", episodeID,
-		"num_nodes", len(allResolvedNodes))
-
-	validNodes := 0
+	// Drop nodes that would fail the final graph write
+	validNodes := make([]*types.Node, 0, len(allResolvedNodes))
 	for i, node := range allResolvedNodes {
-		// Comprehensive validation before persistence
 		if node == nil {
 			c.logger.Warn("Skipping nil node during persistence",
 				"episode_id", episodeID,
@@ -805,26 +835,16 @@ func (c *Client) deduplicateEntitiesAcrossChunks(ctx context.Context, episodeID
 		if !validateNodeForPersistence(node, episodeID, i, c.logger) {
 			continue
 		}
-
-		if err := c.driver.UpsertNode(ctx, node); err != nil {
-			c.logger.Warn("Failed to persist deduplicated node",
-				"episode_id", episodeID,
-				"node_index", i,
-				"node_id", node.Uuid,
-				"node_name", node.Name,
-				"error", err)
-		} else {
-			validNodes++
-		}
+		validNodes = append(validNodes, node)
 	}
 
-	c.logger.Info("Deduplicated nodes persisted",
+	c.logger.Info("Deduplicated nodes validated",
 		"episode_id", episodeID,
 		"total_nodes", len(allResolvedNodes),
-		"valid_nodes", validNodes,
-		"skipped_nodes", len(allResolvedNodes)-validNodes)
+		"valid_nodes", len(validNodes),
+		"skipped_nodes", len(allResolvedNodes)-len(validNodes))
 
-	return dedupeResult, allResolvedNodes, nil
+	return dedupeResult, validNodes, nil
 }
 
 // validateNodeForPersistence performs comprehensive validation on a node before database persistence
@@ -942,7 +962,8 @@ func (c *Client) extractRelationshipsFromChunks(ctx context.Context, episodeID s
 	return allExtractedEdges, nil
 }
 
-// resolveAndPersistRelationships resolves extracted relationships and persists them to the graph.
+// resolveAndPersistRelationships resolves extracted relationships. The resolved
+// edges are persisted by performFinalGraphUpdates.
 func (c *Client) resolveAndPersistRelationships(ctx context.Context, episodeID string, allExtractedEdges []*types.Edge, mainEpisodeNode *types.Node, allResolvedNodes []*types.Node, options *AddEpisodeOptions, edgeOps *maintenance.EdgeOperations) ([]*types.Edge, []*types.Edge, error) {
 	c.logger.Info("Starting bulk relationship resolution",
 		"episode_id", episodeID,
@@ -965,25 +986,6 @@ func (c *Client) resolveAndPersistRelationships(ctx context.Context, episodeID s
 		"resolved_relationships", len(resolvedEdges),
 		"invalidated_relationships", len(invalidatedEdges))
 
-	// EARLY WRITE: Persist resolved edges
-	c.logger.Info("Persisting resolved edges early",
-		"episode_id", episodeID,
-		"num_edges", len(resolvedEdges)+len(invalidatedEdges))
-
-	allResolvedEdges := append(resolvedEdges, invalidatedEdges...)
-	for _, edge := range allResolvedEdges {
-		if err := c.driver.UpsertEdge(ctx, edge); err != nil {
-			c.logger.Warn("Failed to persist resolved edge",
-				"episode_id", episodeID,
-				"edge_id", edge.Uuid,
-				"error", err)
-		}
-	}
-
-	c.logger.Info("Resolved edges persisted",
-		"episode_id", episodeID,
-		"num_edges", len(allResolvedEdges))
-
 	return resolvedEdges, invalidatedEdges, nil
 }
 
@@ -1015,27 +1017,62 @@ func (c *Client) buildEpisodicEdgesForEntities(ctx context.Context, hydratedNode
 	return episodicEdges, nil
 }
 
-// performFinalGraphUpdates performs the final bulk update of nodes and edges to the graph.
-func (c *Client) performFinalGraphUpdates(ctx context.Context, episodeID string, mainEpisodeNode *types.Node, hydratedNodes []*types.Node, resolvedEdges []*types.Edge, invalidatedEdges []*types.Edge, episodicEdges []*types.Edge) error {
+// performFinalGraphUpdates writes the episode, its source link, entities and
+// edges to the graph in a single transaction, so a failure leaves none of them behind.
+func (c *Client) performFinalGraphUpdates(ctx context.Context, episode types.Episode, mainEpisodeNode *types.Node, hydratedNodes []*types.Node, resolvedEdges []*types.Edge, invalidatedEdges []*types.Edge, episodicEdges []*types.Edge) error {
 	allEdges := append(resolvedEdges, invalidatedEdges...)
 
 	c.logger.Info("Starting final updates",
-		"episode_id", episodeID,
+		"episode_id", episode.ID,
 		"episodic_nodes", 1,
 		"entity_nodes_to_update", len(hydratedNodes),
 		"entity_edges_to_update", len(allEdges),
 		"episodic_edges_to_add", len(episodicEdges))
 
-	_, err := utils.AddNodesAndEdgesBulk(ctx, c.driver,
-		[]*types.Node{mainEpisodeNode},
-		episodicEdges,
-		hydratedNodes,
-		allEdges,
-		c.embedder)
+	existing := c.existingGraphObjects(ctx, episode.GroupID,
+		append([]*types.Node{mainEpisodeNode}, hydratedNodes...), resolvedEdges)
+	batch := &graphEventBatch{operation: types.GraphOperationIngestion, episodeUUID: mainEpisodeNode.Uuid}
+
+	// Embed before the transaction: it holds the database, and on Ladybug the
+	// write queue, until it commits. A retried transaction writes the same
+	// embeddings, which depend only on the nodes and edges.
+	if err := utils.EmbedNodesAndEdges(ctx, hydratedNodes, allEdges, c.embedder); err != nil {
+		return fmt.Errorf("failed to perform final updates: %w", err)
+	}
+
+	err := c.withWriteTransaction(ctx, func(txCtx context.Context) error {
+		// Drop the events of a rolled back attempt
+		batch.events = nil
+		result, err := utils.AddNodesAndEdgesBulk(txCtx, c.driver,
+			[]*types.Node{mainEpisodeNode},
+			episodicEdges,
+			hydratedNodes,
+			allEdges,
+			nil)
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			return errors.Join(result.Errors...)
+		}
+
+		return c.linkEpisodeSource(txCtx, episode, mainEpisodeNode, batch)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to perform final updates: %w", err)
 	}
 
+	if existing != nil {
+		events := &graphEventBatch{operation: batch.operation, episodeUUID: batch.episodeUUID}
+		events.upserts(existing, append([]*types.Node{mainEpisodeNode}, hydratedNodes...), resolvedEdges)
+		for _, edge := range invalidatedEdges {
+			events.edge(types.GraphEventInvalidated, edge)
+		}
+		events.upserts(nil, nil, episodicEdges)
+		events.events = append(events.events, batch.events...)
+		c.emitGraphEvents(ctx, events.events)
+	}
+
 	// Report final database statistics after bulk operations
 	if stats, err := c.GetStats(ctx); err == nil {
 		episodesInDB := int64(0)
@@ -1054,28 +1091,41 @@ func (c *Client) performFinalGraphUpdates(ctx context.Context, episodeID string,
 	return nil
 }
 
-// UpdateCommunities updates graph communities if requested in options.
-func (c *Client) UpdateCommunities(ctx context.Context, episodeID string, groupID string) ([]*types.Node, []*types.Edge, error) {
-
-	c.logger.Info("Starting community update",
-		"episode_id", episodeID,
-		"group_id", groupID)
-
-	communityResult, err := c.community.BuildCommunities(ctx, []string{groupID}, c.logger)
-	if err != nil && len(communityResult.CommunityNodes) == 0 {
-		return nil, nil, fmt.Errorf("failed to build communities: %w", err)
+// linkEpisodeSource connects the episode node to a node for its source, if it
+// has one, and records the source node and edge it creates in batch.
+func (c *Client) linkEpisodeSource(ctx context.Context, episode types.Episode, episodeNode *types.Node, batch *graphEventBatch) error {
+	if episode.Source == "" {
+		return nil
 	}
 
-	c.logger.Info("Community update completed",
-		"episode_id", episodeID,
-		"communities", len(communityResult.CommunityNodes),
-		"community_edges", len(communityResult.CommunityEdges))
+	sourceNode, isNew, err := c.getOrCreateSourceNode(ctx, episode.Source, episode.GroupID)
+	if err != nil {
+		return err
+	}
+	if sourceNode == nil {
+		return nil
+	}
+	if isNew {
+		c.logger.Info("Created new source node for episode", "source", episode.Source, "episode_id", episode.ID)
+		batch.node(types.GraphEventCreated, sourceNode)
+	} else {
+		c.logger.Debug("Using existing source node for episode", "source", episode.Source, "episode_id", episode.ID)
+	}
 
-	return communityResult.CommunityNodes, communityResult.CommunityEdges, nil
+	sourceEdge, err := c.createSourceEdge(ctx, sourceNode, episodeNode)
+	if err != nil {
+		return err
+	}
+	if sourceEdge != nil {
+		c.logger.Debug("Created source edge", "source", episode.Source, "episode_id", episode.ID, "edge_id", sourceEdge.Uuid)
+		batch.edge(types.GraphEventCreated, sourceEdge)
+	}
+	return nil
 }
 
-// createEpisodeNode creates an episode node in the graph.
-func (c *Client) createEpisodeNode(ctx context.Context, episode types.Episode, options *AddEpisodeOptions) (*types.Node, error) {
+// buildEpisodeNode builds the node for an episode, embedding its content if
+// needed. The node is persisted by performFinalGraphUpdates.
+func (c *Client) buildEpisodeNode(ctx context.Context, episode types.Episode, options *AddEpisodeOptions) (*types.Node, error) {
 	now := time.Now()
 
 	// Use existing embedding or create new one if embedder is available
@@ -1107,10 +1157,6 @@ func (c *Client) createEpisodeNode(ctx context.Context, episode types.Episode, o
 		Metadata:    episode.Metadata,
 	}
 
-	if err := c.driver.UpsertNode(ctx, episodeNode); err != nil {
-		return nil, fmt.Errorf("failed to create episode node: %w", err)
-	}
-
 	return episodeNode, nil
 }
 
@@ -1302,6 +1348,18 @@ func (c *Client) AddTriplet(ctx context.Context, sourceNode *types.Node, edge *t
 		return nil, fmt.Errorf("source node, edge, and target node must not be nil")
 	}
 
+	groupID := sourceNode.GroupID
+	if groupID == "" {
+		groupID = c.config.GroupID
+	}
+	ctx, err := c.withUsage(ctx, "", groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkEmbeddingSpace(ctx); err != nil {
+		return nil, err
+	}
+
 	// Step 1: Generate name embeddings for nodes if missing (lines 1024-1027)
 	// Equivalent to: if source_node.name_embedding is None: await source_node.generate_name_embedding(self.embedder)
 	if len(sourceNode.NameEmbedding) == 0 && c.embedder != nil {
@@ -1413,10 +1471,32 @@ func (c *Client) AddTriplet(ctx context.Context, sourceNode *types.Node, edge *t
 	}
 
 	// Step 13: Add nodes and edges in bulk (line 1084)
-	_, err = utils.AddNodesAndEdgesBulk(ctx, c.driver, []*types.Node{}, []*types.Edge{}, nodes, allEdges, c.embedder)
+	existing := c.existingGraphObjects(ctx, resolvedEdge.GroupID, nodes, []*types.Edge{resolvedEdge})
+	written, err := utils.AddNodesAndEdgesBulk(ctx, c.driver, []*types.Node{}, []*types.Edge{}, nodes, allEdges, c.embedder)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add nodes and edges to database: %w", err)
 	}
+	if len(written.Errors) > 0 {
+		c.logger.Warn("Failed to write part of the triplet", "error", errors.Join(written.Errors...))
+	}
+	if existing != nil {
+		// Report only the writes that succeeded
+		stored := make(map[string]bool, len(written.EntityEdges))
+		for _, edge := range written.EntityEdges {
+			stored[edge.Uuid] = true
+		}
+		events := &graphEventBatch{operation: types.GraphOperationAddTriplet}
+		events.upserts(existing, written.EntityNodes, nil)
+		if stored[resolvedEdge.Uuid] {
+			events.upserts(existing, nil, []*types.Edge{resolvedEdge})
+		}
+		for _, invalidated := range invalidatedEdges {
+			if stored[invalidated.Uuid] {
+				events.edge(types.GraphEventInvalidated, invalidated)
+			}
+		}
+		c.emitGraphEvents(ctx, events.events)
+	}
 
 	// Step 14: Return results (line 1085)
 	return &types.AddTripletResults{
@@ -1490,6 +1570,26 @@ func GenerateViaCsv[T any](ctx context.Context, client Predicato, messages []typ
 	return nil, nil
 }
 
+// findSourceNode returns the source node named sourceName in the group, or nil if there is none.
+func (c *Client) findSourceNode(ctx context.Context, sourceName string, groupID string) (*types.Node, error) {
+	searchResults, err := c.driver.SearchNodes(ctx, sourceName, groupID, &driver.SearchOptions{
+		Limit:       1,
+		NodeTypes:   []types.NodeType{types.SourceNodeType},
+		UseFullText: false,
+		ExactMatch:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range searchResults {
+		if node.Name == sourceName && node.Type == types.SourceNodeType {
+			return node, nil
+		}
+	}
+	return nil, nil
+}
+
 // getOrCreateSourceNode retrieves an existing source node or creates a new one if it doesn't exist.
 // Returns the source node and a boolean indicating whether a new node was created.
 func (c *Client) getOrCreateSourceNode(ctx context.Context, sourceName string, groupID string) (*types.Node, bool, error) {
@@ -1498,25 +1598,13 @@ func (c *Client) getOrCreateSourceNode(ctx context.Context, sourceName string, g
 	}
 
 	// Try to find an existing source node with this name
-	// Search for source nodes by name in the group
-	searchResults, err := c.driver.SearchNodes(ctx, sourceName, groupID, &driver.SearchOptions{
-		Limit:       1,
-		NodeTypes:   []types.NodeType{types.SourceNodeType},
-		UseFullText: false,
-		ExactMatch:  true,
-	})
+	existing, err := c.findSourceNode(ctx, sourceName, groupID)
 	if err != nil {
 		c.logger.Warn("Failed to search for existing source node", "source", sourceName, "error", err)
 	}
-
-	// If we found an existing source node with exact name match, return it
-	if len(searchResults) > 0 {
-		for _, node := range searchResults {
-			if node.Name == sourceName && node.Type == types.SourceNodeType {
-				c.logger.Debug("Found existing source node", "source", sourceName, "node_id", node.Uuid)
-				return node, false, nil
-			}
-		}
+	if existing != nil {
+		c.logger.Debug("Found existing source node", "source", sourceName, "node_id", existing.Uuid)
+		return existing, false, nil
 	}
 
 	// Create a new source node
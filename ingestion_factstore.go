@@ -3,10 +3,12 @@ package predicato
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/soundprediction/predicato/pkg/factstore"
 	"github.com/soundprediction/predicato/pkg/modeler"
+	"github.com/soundprediction/predicato/pkg/nlp"
 	"github.com/soundprediction/predicato/pkg/prompts"
 	"github.com/soundprediction/predicato/pkg/types"
 	"github.com/soundprediction/predicato/pkg/utils/maintenance"
@@ -16,8 +18,6 @@ import (
 // Returns ExtractionResults containing the raw extracted entities and relationships
 // before graph modeling/resolution.
 func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, options *AddEpisodeOptions) (*types.ExtractionResults, error) {
-	startTime := time.Now()
-
 	if c.factStore == nil {
 		return nil, fmt.Errorf("facts DB not configured")
 	}
@@ -26,20 +26,158 @@ func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, opti
 		options = &AddEpisodeOptions{}
 	}
 
+	groupID := episode.GroupID
+	if groupID == "" {
+		groupID = c.config.GroupID
+	}
+	ctx, err := c.withUsage(ctx, options.Usage, groupID)
+	if err != nil {
+		return nil, err
+	}
+	// Tag token usage with the group for the telemetry reports
+	ctx = context.WithValue(ctx, types.ContextKeyGroupID, groupID)
+	if err := c.checkFactsEmbeddingSpace(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := preprocessEpisode(ctx, &episode, options); err != nil {
+		return nil, err
+	}
+
+	results, err := c.extractFacts(ctx, &episode, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save to Facts
+	if err := c.factStore.SaveSource(ctx, sourceFromEpisode(episode, options)); err != nil {
+		return nil, err
+	}
+
+	if err := c.factStore.SaveExtractedKnowledge(ctx, episode.ID, results.ExtractedNodes, results.ExtractedEdges); err != nil {
+		return nil, err
+	}
+
+	if err := c.commitExtraction(ctx, episode, options); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// commitExtraction commits the extraction of an episode when the fact store
+// is versioned, so runs with different prompts or models can be compared and
+// rolled back.
+func (c *Client) commitExtraction(ctx context.Context, episode types.Episode, options *AddEpisodeOptions) error {
+	versioned, ok := c.factStore.(factstore.VersionedFactsDB)
+	if !ok {
+		return nil
+	}
+	_, err := versioned.CommitExtraction(ctx, c.extractionRun(episode, options), "")
+	return err
+}
+
+// extractionRun describes the extraction of an episode for the commit
+// history of a versioned fact store.
+func (c *Client) extractionRun(episode types.Episode, options *AddEpisodeOptions) *factstore.ExtractionRun {
+	var entityModel interface{} = c.nlpModels.NodeExtraction
+	if c.config.EntityExtractor != nil {
+		entityModel = c.config.EntityExtractor
+	}
+	run := &factstore.ExtractionRun{
+		PromptVersion: options.PromptVersion,
+		Model:         nlp.ModelID(entityModel),
+		Options:       make(map[string]interface{}),
+		SourceIDs:     []string{episode.ID},
+	}
+
+	var edgeModel interface{} = c.nlpModels.EdgeExtraction
+	if c.config.RelationExtractor != nil {
+		edgeModel = c.config.RelationExtractor
+	}
+	if model := nlp.ModelID(edgeModel); model != run.Model {
+		run.Options["edge_model"] = model
+	}
+	if len(options.EntityTypes) > 0 {
+		run.Options["entity_types"] = sortedKeys(options.EntityTypes)
+	}
+	if len(options.ExcludedEntityTypes) > 0 {
+		run.Options["excluded_entity_types"] = options.ExcludedEntityTypes
+	}
+	if len(options.EdgeTypes) > 0 {
+		run.Options["edge_types"] = sortedKeys(options.EdgeTypes)
+	}
+	if options.MaxCharacters > 0 {
+		run.Options["max_characters"] = options.MaxCharacters
+	}
+	if options.UseYAML {
+		run.Options["use_yaml"] = true
+	}
+	if options.SkipReflexion {
+		run.Options["skip_reflexion"] = true
+	}
+	if options.SkipAttributes {
+		run.Options["skip_attributes"] = true
+	}
+	return run
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sourceFromEpisode builds the fact store source for an episode, recording the
+// extraction provenance in its metadata.
+func sourceFromEpisode(episode types.Episode, options *AddEpisodeOptions) *factstore.Source {
+	metadata := make(map[string]interface{}, len(episode.Metadata)+3)
+	for k, v := range episode.Metadata {
+		metadata[k] = v
+	}
+	if episode.Source != "" {
+		metadata[factstore.MetadataKeyEpisodeSource] = episode.Source
+	}
+	if options != nil && options.PromptVersion != "" {
+		metadata[factstore.MetadataKeyPromptVersion] = options.PromptVersion
+	}
+	metadata[factstore.MetadataKeyExtractedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	return &factstore.Source{
+		ID:        episode.ID,
+		Name:      episode.Name,
+		Content:   episode.Content,
+		GroupID:   episode.GroupID,
+		Metadata:  metadata,
+		CreatedAt: episode.CreatedAt,
+		Reference: episode.Reference,
+	}
+}
+
+// extractFacts runs chunking, entity extraction and edge extraction for an episode
+// without persisting anything to the fact store.
+func (c *Client) extractFacts(ctx context.Context, episode *types.Episode, options *AddEpisodeOptions) (*types.ExtractionResults, error) {
+	startTime := time.Now()
+	ctx, ensembleStats := maintenance.WithEnsembleStats(ctx)
+
 	// 1. Prepare and Chunk
-	chunks, err := c.prepareAndValidateEpisode(&episode, options, options.MaxCharacters)
+	chunks, err := c.prepareAndValidateEpisode(episode, options, options.MaxCharacters)
 	if err != nil {
 		return nil, err
 	}
 
 	// 2. Get Context
-	previousEpisodes, err := c.getPreviousEpisodesForContext(ctx, episode, options)
+	previousEpisodes, err := c.getPreviousEpisodesForContext(ctx, *episode, options)
 	if err != nil {
 		return nil, err
 	}
 
 	// 3. Create Structures
-	chunkData, err := c.createChunkEpisodeStructures(ctx, episode, chunks, previousEpisodes, options)
+	chunkData, err := c.createChunkEpisodeStructures(ctx, *episode, chunks, previousEpisodes, options)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +185,7 @@ func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, opti
 	// 4. Extract Entities (Raw)
 	nodeOps := maintenance.NewNodeOperations(c.driver, c.nlpModels.NodeExtraction, c.embedder, prompts.NewLibrary())
 	nodeOps.ReflexionNLP = c.nlpModels.NodeReflexion
+	nodeOps.EntityExtractor = c.config.EntityExtractor
 	nodeOps.ResolutionNLP = c.nlpModels.NodeResolution
 	nodeOps.AttributeNLP = c.nlpModels.NodeAttribute
 	if options.UseYAML {
@@ -60,6 +199,8 @@ func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, opti
 	}
 
 	// 5. Prepare Facts Data (Nodes)
+	// Spans come back relative to each chunk; store them relative to the source content.
+	offsets := chunkOffsets(episode.Content, chunks)
 	var flattenedNodes []*types.Node
 	var factsNodes []*factstore.ExtractedNode
 
@@ -74,6 +215,7 @@ func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, opti
 				Description: n.Summary,
 				Embedding:   n.Embedding,
 				ChunkIndex:  chunkIdx,
+				Span:        anchorSpan(episode.Content, types.GetSpan(n.Metadata), chunkBase(offsets, chunkIdx)),
 			})
 		}
 	}
@@ -81,6 +223,7 @@ func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, opti
 	// 6. Extract Edges (Raw) and Prepare Facts Data
 	edgeOps := maintenance.NewEdgeOperations(c.driver, c.nlProcessor, c.embedder, prompts.NewLibrary())
 	edgeOps.ExtractionNLP = c.nlpModels.EdgeExtraction
+	edgeOps.RelationExtractor = c.config.RelationExtractor
 	edgeOps.ResolutionNLP = c.nlpModels.EdgeResolution
 	edgeOps.SkipResolution = options.SkipEdgeResolution
 	edgeOps.UseYAML = options.UseYAML
@@ -106,6 +249,7 @@ func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, opti
 			if err != nil {
 				return nil, err
 			}
+			anchorEdgeProvenance(episode.Content, extracted, chunkBase(offsets, chunkIdx))
 
 			for _, e := range extracted {
 				// Resolve Names for Facts DB
@@ -153,39 +297,28 @@ func (c *Client) ExtractToFacts(ctx context.Context, episode types.Episode, opti
 					SourceNodeName: sourceName,
 					TargetNodeName: targetName,
 					Relation:       e.Name,
-					Description:    e.Summary,  // Alias for Fact
-					Weight:         e.Strength, // Use Strength
+					Description:    e.Summary, // Alias for Fact
+					Weight:         e.Strength,
+					Confidence:     e.Confidence,
 					ChunkIndex:     chunkIdx,
+					Span:           firstProvenanceSpan(e),
 				})
 			}
 		}
 	}
 
-	// 7. Save to Facts
-	source := &factstore.Source{
-		ID:        episode.ID,
-		Name:      episode.Name,
-		Content:   episode.Content,
-		GroupID:   episode.GroupID,
-		Metadata:  episode.Metadata,
-		CreatedAt: episode.CreatedAt,
-	}
-	if err := c.factStore.SaveSource(ctx, source); err != nil {
-		return nil, err
-	}
-
-	if err := c.factStore.SaveExtractedKnowledge(ctx, episode.ID, factsNodes, factsEdges); err != nil {
-		return nil, err
-	}
-
-	// 8. Return ExtractionResults
-	return &types.ExtractionResults{
+	// 7. Return ExtractionResults
+	results := &types.ExtractionResults{
 		SourceID:       episode.ID,
 		ExtractedNodes: factsNodes,
 		ExtractedEdges: factsEdges,
 		ChunkCount:     len(chunks),
 		ExtractionTime: time.Since(startTime),
-	}, nil
+	}
+	if stats := ensembleStats.Stats(); stats != nil {
+		results.Metadata = &types.ExtractionMetadata{Ensemble: stats}
+	}
+	return results, nil
 }
 
 // getOrCreateModeler returns the GraphModeler to use, checking options, config, then creating default.
@@ -226,6 +359,19 @@ func (c *Client) getOrCreateModeler(options *AddEpisodeOptions) (modeler.GraphMo
 	return defaultModeler, nil
 }
 
+// modelerName returns the name recorded in the fact store for the GraphModeler
+// getOrCreateModeler selects, without creating it.
+func (c *Client) modelerName(options *AddEpisodeOptions) string {
+	switch {
+	case options != nil && options.GraphModeler != nil:
+		return fmt.Sprintf("%T", options.GraphModeler)
+	case c.config != nil && c.config.DefaultGraphModeler != nil:
+		return fmt.Sprintf("%T", c.config.DefaultGraphModeler)
+	default:
+		return fmt.Sprintf("%T", &modeler.DefaultModeler{})
+	}
+}
+
 // handleModelerError handles errors from GraphModeler based on ModelerErrorHandling setting.
 // Returns: (shouldContinue bool, fallbackModeler GraphModeler, error)
 func (c *Client) handleModelerError(step string, err error, options *AddEpisodeOptions) (bool, modeler.GraphModeler, error) {
@@ -276,21 +422,102 @@ func (c *Client) PromoteToGraph(ctx context.Context, sourceID string, options *A
 	}
 
 	// 1. Load from Facts
-	source, err := c.factStore.GetSource(ctx, sourceID)
+	facts, err := c.factsAt(options.FactsRef)
+	if err != nil {
+		return nil, err
+	}
+	source, err := facts.GetSource(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = c.withUsage(ctx, options.Usage, source.GroupID)
 	if err != nil {
 		return nil, err
 	}
 
-	extNodes, err := c.factStore.GetExtractedNodes(ctx, sourceID)
+	extNodes, err := facts.GetExtractedNodes(ctx, sourceID)
 	if err != nil {
 		return nil, err
 	}
 
-	extEdges, err := c.factStore.GetExtractedEdges(ctx, sourceID)
+	extEdges, err := facts.GetExtractedEdges(ctx, sourceID)
 	if err != nil {
 		return nil, err
 	}
 
+	results, promoteErr := c.promoteFacts(ctx, source, extNodes, extEdges, options)
+	if options.FactsRef != "" {
+		// The promotion status belongs to the current contents, not to the ref
+		return results, promoteErr
+	}
+
+	status := factstore.PromotionPromoted
+	if promoteErr != nil {
+		status = factstore.PromotionFailed
+	}
+	if err := c.factStore.SetPromotionStatus(ctx, sourceID, status, c.modelerName(options), promoteErr); err != nil {
+		c.logger.Warn("Failed to record promotion status",
+			"source_id", sourceID,
+			"status", status,
+			"error", err)
+	}
+
+	return results, promoteErr
+}
+
+// factsReader is the part of the fact store PromoteToGraph reads from.
+type factsReader interface {
+	GetSource(ctx context.Context, sourceID string) (*factstore.Source, error)
+	GetExtractedNodes(ctx context.Context, sourceID string) ([]*factstore.ExtractedNode, error)
+	GetExtractedEdges(ctx context.Context, sourceID string) ([]*factstore.ExtractedEdge, error)
+}
+
+// factsAtRef reads a versioned fact store as of a ref.
+type factsAtRef struct {
+	db  factstore.VersionedFactsDB
+	ref string
+}
+
+func (f factsAtRef) GetSource(ctx context.Context, sourceID string) (*factstore.Source, error) {
+	return f.db.GetSourceAt(ctx, f.ref, sourceID)
+}
+
+func (f factsAtRef) GetExtractedNodes(ctx context.Context, sourceID string) ([]*factstore.ExtractedNode, error) {
+	return f.db.GetExtractedNodesAt(ctx, f.ref, sourceID)
+}
+
+func (f factsAtRef) GetExtractedEdges(ctx context.Context, sourceID string) ([]*factstore.ExtractedEdge, error) {
+	return f.db.GetExtractedEdgesAt(ctx, f.ref, sourceID)
+}
+
+// factsAt returns the fact store as of ref, or its current contents if ref is empty.
+func (c *Client) factsAt(ref string) (factsReader, error) {
+	if ref == "" {
+		return c.factStore, nil
+	}
+	versioned, ok := c.factStore.(factstore.VersionedFactsDB)
+	if !ok {
+		return nil, fmt.Errorf("fact store %T does not support refs", c.factStore)
+	}
+	return factsAtRef{db: versioned, ref: ref}, nil
+}
+
+// WithFactStore returns a shallow copy of the client that extracts to and
+// promotes from factStore, for example a branch of a versioned fact store
+// opened with VersionedFactsDB.OnBranch for an experimental extraction run.
+// The copy shares the graph driver, models and event listeners.
+func (c *Client) WithFactStore(factStore factstore.FactsDB) *Client {
+	clone := *c
+	clone.factStore = factStore
+	clone.embeddingSpace = &embeddingSpaceState{}
+	return &clone
+}
+
+// promoteFacts promotes the given extracted nodes and edges of a source to the graph.
+// Edges whose endpoints are not among extNodes are dropped.
+func (c *Client) promoteFacts(ctx context.Context, source *factstore.Source, extNodes []*factstore.ExtractedNode, extEdges []*factstore.ExtractedEdge, options *AddEpisodeOptions) (*types.AddEpisodeResults, error) {
+	sourceID := source.ID
+
 	// 2. Reconstruct Episode and Chunks to get Tuples/Structure
 	episode := types.Episode{
 		ID:        source.ID,
@@ -316,6 +543,11 @@ func (c *Client) PromoteToGraph(ctx context.Context, sourceID string, options *A
 		return nil, err
 	}
 
+	// Stored spans are relative to the source content, while the episode node
+	// holds the re-joined chunks, so provenance is re-anchored chunk by chunk.
+	sourceOffsets := chunkOffsets(source.Content, chunks)
+	episodeOffsets := chunkOffsets(chunkData.mainEpisodeNode.Content, chunks)
+
 	// 3. Reconstruct Nodes from Facts
 	uuidToNode := make(map[string]*types.Node)
 	var allExtractedNodes []*types.Node
@@ -361,13 +593,24 @@ func (c *Client) PromoteToGraph(ctx context.Context, sourceID string, options *A
 					GroupID:      source.GroupID,
 					CreatedAt:    time.Now(),
 				},
-				Name:     e.Relation,
-				Summary:  e.Description,
-				Fact:     e.Description,
-				Strength: e.Weight,
-				Type:     types.EntityEdgeType,
-				SourceID: sUUID,
-				TargetID: tUUID,
+				Name:          e.Relation,
+				Summary:       e.Description,
+				Fact:          e.Description,
+				Strength:      e.Weight,
+				Type:          types.EntityEdgeType,
+				SourceID:      sUUID,
+				TargetID:      tUUID,
+				Confidence:    e.Confidence,
+				Corroboration: 1,
+			}
+			if e.Span.IsValid() {
+				relative := e.Span
+				if base := chunkBase(sourceOffsets, e.ChunkIndex); base >= 0 {
+					relative = e.Span.Shift(-base)
+				}
+				if span := anchorSpan(chunkData.mainEpisodeNode.Content, relative, chunkBase(episodeOffsets, e.ChunkIndex)); span != nil {
+					types.AddEdgeProvenance(te, types.Provenance{EpisodeID: sourceID, Start: span.Start, End: span.End, Text: span.Text})
+				}
 			}
 			allExtractedEdges = append(allExtractedEdges, te)
 		}
@@ -504,19 +747,57 @@ func (c *Client) PromoteToGraph(ctx context.Context, sourceID string, options *A
 			"community_edges", len(communityEdges))
 	}
 
-	return &types.AddEpisodeResults{
+	result := &types.AddEpisodeResults{
 		Episode:        chunkData.mainEpisodeNode,
 		EpisodicEdges:  relOutput.EpisodicEdges,
 		Nodes:          entityOutput.ResolvedNodes,
 		Edges:          relOutput.ResolvedEdges,
 		Communities:    communities,
 		CommunityEdges: communityEdges,
-	}, nil
+	}
+	if c.hasGraphEventListeners() {
+		c.emitGraphEvents(ctx, promotionEvents(result, entityOutput.UUIDMap, sourceID))
+	}
+	return result, nil
+}
+
+// promotionEvents describes the graph changes of a promotion. The modeler
+// writes the graph itself, so resolved nodes that extracted nodes were merged
+// into, and edges already supported by other episodes, are reported as
+// updated and everything else as created.
+func promotionEvents(result *types.AddEpisodeResults, uuidMap map[string]string, sourceID string) []types.GraphEvent {
+	merged := make(map[string]bool)
+	for extracted, resolved := range uuidMap {
+		if extracted != resolved {
+			merged[resolved] = true
+		}
+	}
+	for _, edge := range result.Edges {
+		if edge == nil {
+			continue
+		}
+		for _, episode := range edge.Episodes {
+			if episode != sourceID {
+				merged[edge.Uuid] = true
+				break
+			}
+		}
+	}
+
+	events := &graphEventBatch{operation: types.GraphOperationPromotion, episodeUUID: sourceID}
+	events.upserts(nil, []*types.Node{result.Episode}, result.EpisodicEdges)
+	events.upserts(merged, result.Nodes, result.Edges)
+	events.upserts(nil, result.Communities, result.CommunityEdges)
+	return events.events
 }
 
 // ValidateModeler tests a GraphModeler implementation with sample data to verify
 // it works correctly before using it in production.
 func (c *Client) ValidateModeler(ctx context.Context, gm modeler.GraphModeler) (*modeler.ModelerValidationResult, error) {
+	ctx, err := c.withUsage(ctx, "", c.config.GroupID)
+	if err != nil {
+		return nil, err
+	}
 	opts := &modeler.ValidateModelerOptions{
 		GroupID: c.config.GroupID,
 	}
@@ -0,0 +1,51 @@
+package predicato
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// TestFinalGraphUpdatesRetriedPublishOnce tests that a write transaction the
+// driver retries publishes the events of the committed attempt only
+func TestFinalGraphUpdatesRetriedPublishOnce(t *testing.T) {
+	d := newMemoryDriver()
+	d.retryWrites = true
+	client, err := NewClient(d, nil, &lengthEmbedder{model: "test"}, &Config{GroupID: "g", TimeZone: time.UTC}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	var events []types.GraphEvent
+	client.AddGraphEventListener(GraphEventListenerFunc(func(ctx context.Context, batch []types.GraphEvent) error {
+		events = append(events, batch...)
+		return nil
+	}))
+
+	episode := types.Episode{ID: "ep-1", Name: "Meeting", Content: "Alice met Bob", GroupID: "g", Source: "notes.txt"}
+	episodeNode := &types.Node{Uuid: "ep-1", Name: "Meeting", Type: types.EpisodicNodeType, GroupID: "g"}
+	alice := nodeWithSummary("alice", "Alice", "")
+	met := &types.Edge{
+		BaseEdge: types.BaseEdge{Uuid: "edge-1", GroupID: "g", SourceNodeID: "alice", TargetNodeID: "alice"},
+		Type:     types.EntityEdgeType,
+		Fact:     "Alice met herself",
+	}
+	err = client.performFinalGraphUpdates(context.Background(), episode, episodeNode,
+		[]*types.Node{alice}, []*types.Edge{met}, nil, nil)
+	if err != nil {
+		t.Fatalf("performFinalGraphUpdates: %v", err)
+	}
+
+	// The episode, Alice, her edge, and the source node and link
+	if len(events) != 5 {
+		t.Errorf("got %d events, want 5: %+v", len(events), events)
+	}
+	for _, event := range events {
+		_, isNode := d.nodes[event.UUID]
+		_, isEdge := d.edges[event.UUID]
+		if !isNode && !isEdge {
+			t.Errorf("%s event for %s %s, which the committed attempt did not write", event.Type, event.Kind, event.UUID)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package predicato
 import (
 	"context"
 
+	"github.com/soundprediction/predicato/pkg/embedder"
 	"github.com/soundprediction/predicato/pkg/factstore"
 	"github.com/soundprediction/predicato/pkg/modeler"
 	"github.com/soundprediction/predicato/pkg/types"
@@ -50,6 +51,10 @@ type EpisodeManager interface {
 	// RemoveEpisode removes an episode and its associated nodes and edges from the knowledge graph.
 	RemoveEpisode(ctx context.Context, episodeUUID string) error
 
+	// RemoveEpisodeWithOptions removes an episode, cleaning up edges, nodes, sources and
+	// communities it alone supported. With DryRun set it only returns the planned changes.
+	RemoveEpisodeWithOptions(ctx context.Context, episodeUUID string, opts *RemoveEpisodeOptions) (*RemoveEpisodeResult, error)
+
 	// GetEpisodes retrieves recent episodes from the knowledge graph.
 	GetEpisodes(ctx context.Context, groupID string, limit int) ([]*types.Node, error)
 
@@ -69,6 +74,9 @@ type GraphQuerier interface {
 
 	// GetEdge retrieves a specific edge from the knowledge graph.
 	GetEdge(ctx context.Context, edgeID string) (*types.Edge, error)
+
+	// Answer answers a question using retrieved facts and returns the cited UUIDs.
+	Answer(ctx context.Context, question string, opts *AnswerOptions) (*AnswerResult, error)
 }
 
 // GraphMutator provides write operations on the knowledge graph.
@@ -82,6 +90,13 @@ type GraphMutator interface {
 
 	// UpdateCommunities updates community assignments after changes to the graph.
 	UpdateCommunities(ctx context.Context, episodeUUID string, groupID string) ([]*types.Node, []*types.Edge, error)
+
+	// PurgeEntity deletes an entity and its edges, redacts or deletes the episodes that
+	// mention it, purges its fact store rows and cached embeddings, and records an audit entry.
+	PurgeEntity(ctx context.Context, uuid string, opts *PurgeOptions) (*PurgeResult, error)
+
+	// PurgeBySource removes everything ingested from a source and records an audit entry.
+	PurgeBySource(ctx context.Context, sourceName string) (*PurgeResult, error)
 }
 
 // FactsManager provides operations for the two-phase ingestion pipeline.
@@ -101,6 +116,12 @@ type FactsManager interface {
 	// PromoteToGraph takes previously extracted facts from the fact store and promotes
 	// them to the knowledge graph using the configured GraphModeler.
 	PromoteToGraph(ctx context.Context, sourceID string, options *AddEpisodeOptions) (*types.AddEpisodeResults, error)
+
+	// PromoteAll promotes the pending sources of the fact store in Reference order.
+	PromoteAll(ctx context.Context, filter *PromoteFilter, concurrency int) (*PromoteAllResults, error)
+
+	// ReprocessEpisodes re-extracts the selected sources and applies only the changes to the graph.
+	ReprocessEpisodes(ctx context.Context, selector *ReprocessSelector, options *ReprocessOptions) (*ReprocessResults, error)
 }
 
 // GraphAdmin provides administrative operations for the knowledge graph.
@@ -112,6 +133,12 @@ type GraphAdmin interface {
 	// ValidateModeler tests a GraphModeler implementation with sample data.
 	ValidateModeler(ctx context.Context, gm modeler.GraphModeler) (*modeler.ModelerValidationResult, error)
 
+	// EmbeddingSpace returns the embedding model and dimensions recorded for the graph.
+	EmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error)
+
+	// ReembedAll re-embeds every stored vector with newEmbedder and switches over atomically.
+	ReembedAll(ctx context.Context, newEmbedder embedder.Client, batchSize int) (*ReembedResult, error)
+
 	// Close closes all connections and cleans up resources.
 	Close(ctx context.Context) error
 }
@@ -0,0 +1,173 @@
+// Package audit keeps a tamper-evident, append-only log of sensitive
+// operations such as purges. Entries are written as JSON lines and chained by
+// SHA-256: each entry's hash covers its content and the previous entry's hash,
+// so editing, removing or reordering an entry breaks the chain and is detected
+// by Verify.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is one record of the audit log.
+type Entry struct {
+	Sequence int64                  `json:"sequence"`
+	Time     time.Time              `json:"time"`
+	Action   string                 `json:"action"`
+	Actor    string                 `json:"actor,omitempty"`
+	Subject  string                 `json:"subject"`
+	Reason   string                 `json:"reason,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	PrevHash string                 `json:"prev_hash"`
+	Hash     string                 `json:"hash"`
+}
+
+// ErrTampered is returned by Verify when the hash chain is broken.
+var ErrTampered = errors.New("audit log has been tampered with")
+
+// Log appends entries to an audit log file.
+type Log struct {
+	path     string
+	mu       sync.Mutex
+	sequence int64
+	lastHash string
+}
+
+// Open opens the audit log at path, creating it if needed. The existing chain
+// is verified so that new entries are never appended to a tampered log.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	entries, err := Verify(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	l := &Log{path: path}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		l.sequence = last.Sequence
+		l.lastHash = last.Hash
+	}
+	return l, nil
+}
+
+// Path returns the path of the log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append chains entry to the log and writes it. Sequence, PrevHash and Hash
+// are filled in, as is Time when unset. The written entry is returned.
+func (l *Log) Append(entry Entry) (*Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	// Details are hashed in the form they are read back in
+	if entry.Details != nil {
+		data, err := json.Marshal(entry.Details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		entry.Details = nil
+		if err := json.Unmarshal(data, &entry.Details); err != nil {
+			return nil, fmt.Errorf("failed to normalize audit details: %w", err)
+		}
+	}
+	entry.Sequence = l.sequence + 1
+	entry.PrevHash = l.lastHash
+	hash, err := entryHash(&entry)
+	if err != nil {
+		return nil, err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	l.sequence = entry.Sequence
+	l.lastHash = entry.Hash
+	return &entry, nil
+}
+
+// Verify reads the audit log at path and checks its hash chain. It returns
+// the entries read, and an error wrapping ErrTampered at the first entry
+// that does not match its hash or its predecessor.
+func Verify(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*Entry
+	var prevHash string
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var entry Entry
+			if jsonErr := json.Unmarshal(line, &entry); jsonErr != nil {
+				return entries, fmt.Errorf("%w: entry %d is not valid JSON", ErrTampered, len(entries)+1)
+			}
+			if entry.Sequence != int64(len(entries)+1) || entry.PrevHash != prevHash {
+				return entries, fmt.Errorf("%w: entry %d is out of sequence", ErrTampered, len(entries)+1)
+			}
+			hash, hashErr := entryHash(&entry)
+			if hashErr != nil {
+				return entries, hashErr
+			}
+			if hash != entry.Hash {
+				return entries, fmt.Errorf("%w: entry %d does not match its hash", ErrTampered, entry.Sequence)
+			}
+			entries = append(entries, &entry)
+			prevHash = entry.Hash
+		}
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+}
+
+// entryHash returns the hex-encoded SHA-256 of entry without its Hash field.
+func entryHash(entry *Entry) (string, error) {
+	unhashed := *entry
+	unhashed.Hash = ""
+	data, err := json.Marshal(unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
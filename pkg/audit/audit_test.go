@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppendChainsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	type counts struct {
+		Nodes int `json:"nodes"`
+		Edges int `json:"edges"`
+	}
+	first, err := log.Append(Entry{Action: "purge_entity", Subject: "n1", Details: map[string]interface{}{
+		"counts": counts{Nodes: 1, Edges: 2},
+		"uuids":  []string{"e1", "e2"},
+	}})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	log, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	second, err := log.Append(Entry{Action: "purge_source", Subject: "crm"})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if second.Sequence != 2 || second.PrevHash != first.Hash {
+		t.Errorf("entry not chained: %+v", second)
+	}
+
+	entries, err := Verify(path)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for _, subject := range []string{"n1", "n2"} {
+		if _, err := log.Append(Entry{Action: "purge_entity", Subject: subject}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), `"n1"`, `"n9"`, 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Verify(path); !errors.Is(err, ErrTampered) {
+		t.Errorf("Verify() error = %v, want ErrTampered", err)
+	}
+	if _, err := Open(path); !errors.Is(err, ErrTampered) {
+		t.Errorf("Open() error = %v, want ErrTampered", err)
+	}
+}
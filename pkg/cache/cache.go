@@ -25,6 +25,12 @@ type Cache interface {
 	Close() error
 }
 
+// KeyLister is implemented by caches that can enumerate their keys
+type KeyLister interface {
+	// Keys returns the keys that start with prefix
+	Keys(prefix string) ([]string, error)
+}
+
 // BadgerCache implements Cache using BadgerDB
 type BadgerCache struct {
 	db *badger.DB
@@ -84,6 +90,23 @@ func (c *BadgerCache) Delete(key string) error {
 	})
 }
 
+// Keys returns the keys that start with prefix
+func (c *BadgerCache) Keys(prefix string) ([]string, error) {
+	var keys []string
+	err := c.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			keys = append(keys, string(it.Item().KeyCopy(nil)))
+		}
+		return nil
+	})
+	return keys, err
+}
+
 // Close closes the cache
 func (c *BadgerCache) Close() error {
 	return c.db.Close()
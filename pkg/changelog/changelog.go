@@ -0,0 +1,417 @@
+// Package changelog records graph change events to an append-only log that
+// downstream consumers can tail by sequence number.
+//
+// A Sink is registered on a predicato Client with AddGraphEventListener. Every
+// event it receives is given the next sequence number and appended either to a
+// single JSONL file or to a series of Parquet files, one per batch. Sequence
+// numbers increase monotonically across restarts: a reopened sink continues
+// after the highest number already in its directory.
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// Format is the file format of a change log.
+type Format string
+
+const (
+	// FormatJSONL appends one JSON record per line to changes.jsonl.
+	FormatJSONL Format = "jsonl"
+	// FormatParquet writes each batch of records to its own Parquet file.
+	FormatParquet Format = "parquet"
+)
+
+const (
+	jsonlFileName      = "changes.jsonl"
+	parquetFilePrefix  = "changes_"
+	parquetFileSuffix  = ".parquet"
+	defaultParquetSize = 1000
+)
+
+// Record is one entry of the change log.
+type Record struct {
+	Sequence       int64     `json:"sequence" parquet:"sequence"`
+	Time           time.Time `json:"time" parquet:"time"`
+	Type           string    `json:"type" parquet:"type"`
+	Kind           string    `json:"kind" parquet:"kind"`
+	UUID           string    `json:"uuid" parquet:"uuid"`
+	GroupID        string    `json:"group_id" parquet:"group_id"`
+	Operation      string    `json:"operation" parquet:"operation"`
+	EpisodeUUID    string    `json:"episode_uuid,omitempty" parquet:"episode_uuid"`
+	Name           string    `json:"name,omitempty" parquet:"name"`
+	SourceNodeUUID string    `json:"source_node_uuid,omitempty" parquet:"source_node_uuid"`
+	TargetNodeUUID string    `json:"target_node_uuid,omitempty" parquet:"target_node_uuid"`
+	// Object is the JSON encoding of the node or edge, without embeddings.
+	Object json.RawMessage `json:"object,omitempty" parquet:"object"`
+}
+
+// Options configures a Sink.
+type Options struct {
+	// Format selects JSONL or Parquet output. Defaults to FormatJSONL.
+	Format Format
+	// BatchSize is the number of records buffered before a Parquet file is
+	// written. Defaults to 1000. JSONL records are written immediately.
+	BatchSize int
+}
+
+// Sink appends graph events to a change log directory. It implements the
+// predicato GraphEventListener interface and is safe for concurrent use.
+type Sink struct {
+	mu        sync.Mutex
+	dir       string
+	format    Format
+	batchSize int
+	last      int64
+	file      *os.File
+	buffer    []Record
+	closed    bool
+}
+
+// Open opens the change log in dir, creating the directory if needed, and
+// recovers the last sequence number written to it.
+func Open(dir string, opts *Options) (*Sink, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	s := &Sink{
+		dir:       dir,
+		format:    opts.Format,
+		batchSize: opts.BatchSize,
+	}
+	if s.format == "" {
+		s.format = FormatJSONL
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultParquetSize
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create change log directory: %w", err)
+	}
+
+	switch s.format {
+	case FormatJSONL:
+		if err := s.openJSONL(); err != nil {
+			return nil, err
+		}
+	case FormatParquet:
+		last, err := lastParquetSequence(dir)
+		if err != nil {
+			return nil, err
+		}
+		s.last = last
+	default:
+		return nil, fmt.Errorf("unsupported change log format: %s", s.format)
+	}
+	return s, nil
+}
+
+// openJSONL opens the JSONL file for appending. A record left incomplete by a
+// crash is truncated, and the sequence continues after the last full record.
+func (s *Sink) openJSONL() error {
+	path := filepath.Join(s.dir, jsonlFileName)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open change log: %w", err)
+	}
+
+	var last int64
+	var complete int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to read change log: %w", err)
+		}
+		complete += int64(len(line))
+		var record struct {
+			Sequence int64 `json:"sequence"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to parse change log record at offset %d: %w", complete-int64(len(line)), err)
+		}
+		last = record.Sequence
+	}
+
+	if err := file.Truncate(complete); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to truncate incomplete change log record: %w", err)
+	}
+	if _, err := file.Seek(complete, io.SeekStart); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to seek change log: %w", err)
+	}
+	s.file = file
+	s.last = last
+	return nil
+}
+
+// LastSequence returns the sequence number of the last record appended.
+func (s *Sink) LastSequence() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// OnGraphEvents appends events to the log with consecutive sequence numbers.
+func (s *Sink) OnGraphEvents(ctx context.Context, events []types.GraphEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("change log is closed")
+	}
+
+	records := make([]Record, 0, len(events))
+	for i, event := range events {
+		record, err := newRecord(s.last+int64(i)+1, event)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	switch s.format {
+	case FormatJSONL:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("failed to encode change log record: %w", err)
+			}
+		}
+		// A single write keeps a batch from being interleaved or half-applied
+		// by another writer of the same sink.
+		if _, err := s.file.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to append to change log: %w", err)
+		}
+	case FormatParquet:
+		s.buffer = append(s.buffer, records...)
+		if len(s.buffer) >= s.batchSize {
+			if err := s.flushLocked(); err != nil {
+				// The records stay buffered and their sequence numbers are
+				// used, so a later flush can still write them
+				s.last += int64(len(records))
+				return err
+			}
+		}
+	}
+
+	s.last += int64(len(records))
+	return nil
+}
+
+// Flush writes buffered Parquet records to a new file and syncs JSONL output.
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+func (s *Sink) flushLocked() error {
+	if s.file != nil {
+		if err := s.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync change log: %w", err)
+		}
+	}
+	if len(s.buffer) == 0 {
+		return nil
+	}
+
+	first, last := s.buffer[0].Sequence, s.buffer[len(s.buffer)-1].Sequence
+	path := filepath.Join(s.dir, parquetFileName(first, last))
+	tmpPath := path + ".tmp"
+	if err := parquet.WriteFile(tmpPath, s.buffer); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write change log parquet file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move change log parquet file into place: %w", err)
+	}
+	s.buffer = s.buffer[:0]
+	return nil
+}
+
+// Close flushes pending records and closes the log.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	err := s.flushLocked()
+	if s.file != nil {
+		if closeErr := s.file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close change log: %w", closeErr)
+		}
+	}
+	s.closed = true
+	return err
+}
+
+// newRecord converts event into a record with the given sequence number.
+func newRecord(sequence int64, event types.GraphEvent) (Record, error) {
+	record := Record{
+		Sequence:    sequence,
+		Time:        event.Time,
+		Type:        string(event.Type),
+		Kind:        string(event.Kind),
+		UUID:        event.UUID,
+		GroupID:     event.GroupID,
+		Operation:   event.Operation,
+		EpisodeUUID: event.EpisodeUUID,
+	}
+
+	var object interface{}
+	switch {
+	case event.Node != nil:
+		node := *event.Node
+		node.Embedding = nil
+		node.NameEmbedding = nil
+		record.Name = node.Name
+		object = node
+	case event.Edge != nil:
+		edge := *event.Edge
+		edge.Embedding = nil
+		edge.FactEmbedding = nil
+		record.Name = edge.Name
+		record.SourceNodeUUID = edge.SourceNodeID
+		record.TargetNodeUUID = edge.TargetNodeID
+		object = edge
+	}
+	if object != nil {
+		data, err := json.Marshal(object)
+		if err != nil {
+			return Record{}, fmt.Errorf("failed to encode %s %s: %w", event.Kind, event.UUID, err)
+		}
+		record.Object = data
+	}
+	return record, nil
+}
+
+// parquetFileName names the Parquet file holding sequences first to last.
+// The numbers are zero-padded so that files sort in sequence order.
+func parquetFileName(first, last int64) string {
+	return fmt.Sprintf("%s%020d_%020d%s", parquetFilePrefix, first, last, parquetFileSuffix)
+}
+
+// parquetFiles lists the Parquet files of the log in dir with the sequence
+// range each holds, in sequence order.
+func parquetFiles(dir string) ([]parquetFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change log directory: %w", err)
+	}
+	var files []parquetFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, parquetFilePrefix) || !strings.HasSuffix(name, parquetFileSuffix) {
+			continue
+		}
+		var f parquetFile
+		if _, err := fmt.Sscanf(strings.TrimSuffix(strings.TrimPrefix(name, parquetFilePrefix), parquetFileSuffix), "%d_%d", &f.first, &f.last); err != nil {
+			continue
+		}
+		f.path = filepath.Join(dir, name)
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].first < files[j].first })
+	return files, nil
+}
+
+type parquetFile struct {
+	path        string
+	first, last int64
+}
+
+// lastParquetSequence returns the highest sequence number in the Parquet
+// files of dir, or zero when there are none.
+func lastParquetSequence(dir string) (int64, error) {
+	files, err := parquetFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+	var last int64
+	for _, f := range files {
+		last = max(last, f.last)
+	}
+	return last, nil
+}
+
+// Read returns the records in the change log in dir with a sequence number
+// greater than after, in sequence order. Both JSONL and Parquet logs are read.
+func Read(dir string, after int64) ([]Record, error) {
+	var records []Record
+
+	file, err := os.Open(filepath.Join(dir, jsonlFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open change log: %w", err)
+	}
+	if err == nil {
+		defer file.Close()
+		reader := bufio.NewReader(file)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err == io.EOF {
+				// A trailing line without a newline is a record still being written
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read change log: %w", err)
+			}
+			var record Record
+			if err := json.Unmarshal(line, &record); err != nil {
+				return nil, fmt.Errorf("failed to parse change log record: %w", err)
+			}
+			if record.Sequence > after {
+				records = append(records, record)
+			}
+		}
+	}
+
+	files, err := parquetFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.last <= after {
+			continue
+		}
+		rows, err := parquet.ReadFile[Record](f.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read change log file %s: %w", f.path, err)
+		}
+		for _, record := range rows {
+			if record.Sequence > after {
+				records = append(records, record)
+			}
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Sequence < records[j].Sequence })
+	return records, nil
+}
@@ -0,0 +1,130 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+func testEvents() []types.GraphEvent {
+	node := &types.Node{Uuid: "n1", Name: "Alice", Type: types.EntityNodeType, GroupID: "g", NameEmbedding: []float32{1, 2}}
+	edge := &types.Edge{
+		BaseEdge: types.BaseEdge{Uuid: "e1", GroupID: "g", SourceNodeID: "n1", TargetNodeID: "n2"},
+		Name:     "KNOWS",
+	}
+	return []types.GraphEvent{
+		types.NewNodeEvent(types.GraphEventCreated, types.GraphOperationIngestion, node),
+		types.NewEdgeEvent(types.GraphEventUpdated, types.GraphOperationIngestion, edge),
+	}
+}
+
+func TestSinkSequencesSurviveReopen(t *testing.T) {
+	for _, format := range []Format{FormatJSONL, FormatParquet} {
+		t.Run(string(format), func(t *testing.T) {
+			dir := t.TempDir()
+			ctx := context.Background()
+
+			sink, err := Open(dir, &Options{Format: format})
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if err := sink.OnGraphEvents(ctx, testEvents()); err != nil {
+				t.Fatalf("OnGraphEvents: %v", err)
+			}
+			if err := sink.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			sink, err = Open(dir, &Options{Format: format})
+			if err != nil {
+				t.Fatalf("reopen: %v", err)
+			}
+			if got := sink.LastSequence(); got != 2 {
+				t.Fatalf("LastSequence after reopen = %d, want 2", got)
+			}
+			if err := sink.OnGraphEvents(ctx, testEvents()); err != nil {
+				t.Fatalf("OnGraphEvents: %v", err)
+			}
+			if err := sink.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			records, err := Read(dir, 0)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if len(records) != 4 {
+				t.Fatalf("got %d records, want 4", len(records))
+			}
+			for i, record := range records {
+				if record.Sequence != int64(i+1) {
+					t.Errorf("record %d has sequence %d", i, record.Sequence)
+				}
+			}
+			if records[0].Kind != string(types.GraphObjectNode) || records[0].Name != "Alice" {
+				t.Errorf("unexpected node record: %+v", records[0])
+			}
+			if records[1].SourceNodeUUID != "n1" || records[1].TargetNodeUUID != "n2" || records[1].Type != string(types.GraphEventUpdated) {
+				t.Errorf("unexpected edge record: %+v", records[1])
+			}
+
+			var node types.Node
+			if err := json.Unmarshal(records[0].Object, &node); err != nil {
+				t.Fatalf("failed to decode object: %v", err)
+			}
+			if node.Uuid != "n1" || node.NameEmbedding != nil {
+				t.Errorf("unexpected object: %+v", node)
+			}
+
+			tail, err := Read(dir, 3)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if len(tail) != 1 || tail[0].Sequence != 4 {
+				t.Errorf("Read after 3 returned %+v", tail)
+			}
+		})
+	}
+}
+
+func TestSinkTruncatesIncompleteRecord(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	sink, err := Open(dir, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := sink.OnGraphEvents(ctx, testEvents()); err != nil {
+		t.Fatalf("OnGraphEvents: %v", err)
+	}
+	sink.Close()
+
+	file, err := os.OpenFile(filepath.Join(dir, jsonlFileName), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"sequence":3,"ty`)
+	file.Close()
+
+	sink, err = Open(dir, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if err := sink.OnGraphEvents(ctx, testEvents()[:1]); err != nil {
+		t.Fatalf("OnGraphEvents: %v", err)
+	}
+	sink.Close()
+
+	records, err := Read(dir, 0)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(records) != 3 || records[2].Sequence != 3 {
+		t.Fatalf("unexpected records after recovery: %+v", records)
+	}
+}
@@ -47,6 +47,22 @@ func NewBuilder(driver driver.GraphDriver, nlProcessor nlp.Client, summarizerCli
 	}, nil
 }
 
+// ModelClients returns the NLP and embedder clients the builder calls,
+// keyed by role, omitting those that are not set.
+func (b *Builder) ModelClients() map[string]interface{} {
+	clients := make(map[string]interface{})
+	if b.nlProcessor != nil {
+		clients["nlp"] = b.nlProcessor
+	}
+	if b.summarizer != nil {
+		clients["summarizer"] = b.summarizer
+	}
+	if b.embedder != nil {
+		clients["embedder"] = b.embedder
+	}
+	return clients
+}
+
 // BuildCommunitiesResult represents the result of community building
 type BuildCommunitiesResult struct {
 	CommunityNodes []*types.Node `json:"community_nodes"`
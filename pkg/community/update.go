@@ -3,6 +3,8 @@ package community
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/soundprediction/predicato/pkg/types"
@@ -137,3 +139,228 @@ func (b *Builder) getExistingCommunity(ctx context.Context, entityUUID string) (
 func (b *Builder) findModalCommunity(ctx context.Context, entityUUID string) (*types.Node, error) {
 	return b.driver.FindModalCommunity(ctx, entityUUID)
 }
+
+// RefreshCommunityResult represents the result of refreshing a community
+type RefreshCommunityResult struct {
+	Community *types.Node
+	Deleted   bool
+}
+
+// RefreshCommunity re-summarizes a community from its current members, after
+// members have been removed or changed. A community left without members is
+// deleted.
+func (b *Builder) RefreshCommunity(ctx context.Context, communityUUID, groupID string) (*RefreshCommunityResult, error) {
+	community, err := b.driver.GetNode(ctx, communityUUID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get community: %w", err)
+	}
+
+	memberUUIDs, err := b.getCommunityMembers(ctx, communityUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get community members: %w", err)
+	}
+	members, err := b.getNodesByUUIDs(ctx, memberUUIDs, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get community members: %w", err)
+	}
+
+	if len(members) == 0 {
+		if err := b.driver.DeleteNode(ctx, communityUUID, groupID); err != nil {
+			return nil, fmt.Errorf("failed to delete empty community: %w", err)
+		}
+		return &RefreshCommunityResult{Community: community, Deleted: true}, nil
+	}
+
+	summaries := make([]string, len(members))
+	for i, member := range members {
+		summaries[i] = member.Summary
+	}
+	summary, err := b.hierarchicalSummarize(ctx, summaries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize community: %w", err)
+	}
+	name, err := b.generateCommunityName(ctx, summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate community name: %w", err)
+	}
+
+	community.Summary = summary
+	community.Name = name
+	community.UpdatedAt = time.Now().UTC()
+	if err := b.generateCommunityEmbedding(ctx, community); err != nil {
+		return nil, fmt.Errorf("failed to generate community embedding: %w", err)
+	}
+	if err := b.driver.UpsertNode(ctx, community); err != nil {
+		return nil, fmt.Errorf("failed to save refreshed community: %w", err)
+	}
+
+	return &RefreshCommunityResult{Community: community}, nil
+}
+
+// getCommunityMembers returns the UUIDs of a community's entity members
+func (b *Builder) getCommunityMembers(ctx context.Context, communityUUID string) ([]string, error) {
+	query := `
+		MATCH (c:Community {uuid: $uuid})-[:HAS_MEMBER]->(n:Entity)
+		RETURN n.uuid AS uuid
+	`
+	records, _, _, err := b.driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"uuid": communityUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var uuids []string
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			if uuid, ok := record["uuid"].(string); ok {
+				uuids = append(uuids, uuid)
+			}
+		}
+	}
+	return uuids, nil
+}
+
+// ReconcileCommunitiesResult describes how rebuilt communities map onto the
+// communities already in a group
+type ReconcileCommunitiesResult struct {
+	// Existing holds the UUIDs of rebuilt communities and edges that now
+	// name objects already in the graph
+	Existing map[string]bool
+	// Stale holds the existing communities no rebuilt community matches
+	Stale []*types.Node
+}
+
+// ReconcileCommunities matches rebuilt communities to the group's existing
+// communities with the same members. A matched community and its HAS_MEMBER
+// edges take over the existing UUIDs, so persisting them updates the
+// community in place instead of adding a duplicate.
+func (b *Builder) ReconcileCommunities(ctx context.Context, groupID string, built *BuildCommunitiesResult) (*ReconcileCommunitiesResult, error) {
+	result := &ReconcileCommunitiesResult{Existing: make(map[string]bool)}
+
+	memberEdges, err := b.getGroupCommunityMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing communities: %w", err)
+	}
+	if len(memberEdges) == 0 {
+		return result, nil
+	}
+
+	existingUUIDs := make([]string, 0, len(memberEdges))
+	byMembers := make(map[string]string, len(memberEdges))
+	for communityUUID, edges := range memberEdges {
+		existingUUIDs = append(existingUUIDs, communityUUID)
+		members := make([]string, 0, len(edges))
+		for memberUUID := range edges {
+			members = append(members, memberUUID)
+		}
+		byMembers[membershipKey(members)] = communityUUID
+	}
+	sort.Strings(existingUUIDs)
+	existing, err := b.getNodesByUUIDs(ctx, existingUUIDs, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing communities: %w", err)
+	}
+	existingByUUID := make(map[string]*types.Node, len(existing))
+	for _, community := range existing {
+		existingByUUID[community.Uuid] = community
+	}
+
+	builtEdges := make(map[string][]*types.Edge)
+	if built != nil {
+		for _, edge := range built.CommunityEdges {
+			builtEdges[edge.SourceNodeID] = append(builtEdges[edge.SourceNodeID], edge)
+		}
+	}
+
+	matched := make(map[string]bool)
+	if built != nil {
+		for _, community := range built.CommunityNodes {
+			edges := builtEdges[community.Uuid]
+			members := make([]string, len(edges))
+			for i, edge := range edges {
+				members[i] = edge.TargetNodeID
+			}
+			existingUUID, ok := byMembers[membershipKey(members)]
+			if !ok || matched[existingUUID] {
+				continue
+			}
+			matched[existingUUID] = true
+
+			community.Uuid = existingUUID
+			if previous := existingByUUID[existingUUID]; previous != nil {
+				community.CreatedAt = previous.CreatedAt
+			}
+			result.Existing[existingUUID] = true
+			for _, edge := range edges {
+				edge.Uuid = memberEdges[existingUUID][edge.TargetNodeID]
+				edge.SourceNodeID = existingUUID
+				edge.SourceIDs = []string{existingUUID}
+				result.Existing[edge.Uuid] = true
+			}
+		}
+	}
+
+	for _, community := range existing {
+		if !matched[community.Uuid] {
+			result.Stale = append(result.Stale, community)
+		}
+	}
+	return result, nil
+}
+
+// GetGroupCommunities returns the communities of a group
+func (b *Builder) GetGroupCommunities(ctx context.Context, groupID string) ([]*types.Node, error) {
+	memberEdges, err := b.getGroupCommunityMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	uuids := make([]string, 0, len(memberEdges))
+	for communityUUID := range memberEdges {
+		uuids = append(uuids, communityUUID)
+	}
+	sort.Strings(uuids)
+	return b.getNodesByUUIDs(ctx, uuids, groupID)
+}
+
+// getGroupCommunityMembers returns, for each community of a group, the UUID
+// of the HAS_MEMBER edge to each of its entity members
+func (b *Builder) getGroupCommunityMembers(ctx context.Context, groupID string) (map[string]map[string]string, error) {
+	query := `
+		MATCH (c:Community {group_id: $group_id})
+		OPTIONAL MATCH (c)-[r:HAS_MEMBER]->(n:Entity)
+		RETURN c.uuid AS community_uuid, n.uuid AS member_uuid, r.uuid AS edge_uuid
+	`
+	records, _, _, err := b.driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"group_id": groupID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	memberEdges := make(map[string]map[string]string)
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			communityUUID, ok := record["community_uuid"].(string)
+			if !ok {
+				continue
+			}
+			if memberEdges[communityUUID] == nil {
+				memberEdges[communityUUID] = make(map[string]string)
+			}
+			memberUUID, _ := record["member_uuid"].(string)
+			edgeUUID, _ := record["edge_uuid"].(string)
+			if memberUUID != "" && edgeUUID != "" {
+				memberEdges[communityUUID][memberUUID] = edgeUUID
+			}
+		}
+	}
+	return memberEdges, nil
+}
+
+// membershipKey identifies a community by its set of member UUIDs
+func membershipKey(members []string) string {
+	sorted := append([]string(nil), members...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "\x00")
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -32,6 +33,63 @@ type Config struct {
 
 	// CircuitBreaker configuration
 	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+
+	// Webhooks configuration
+	Webhooks WebhookConfig `mapstructure:"webhooks"`
+
+	// CrossEncoder configuration
+	CrossEncoder CrossEncoderConfig `mapstructure:"cross_encoder"`
+
+	// FactStore configuration
+	FactStore FactStoreConfig `mapstructure:"factstore"`
+}
+
+// CrossEncoderConfig holds configuration for the search reranker
+type CrossEncoderConfig struct {
+	Provider  string `mapstructure:"provider" json:"provider"` // openai, reranker, embedding, embedeverything, local, mock
+	Model     string `mapstructure:"model" json:"model"`
+	BaseURL   string `mapstructure:"base_url" json:"base_url"`
+	APIKey    string `mapstructure:"api_key" json:"-"` // Excluded from JSON to prevent credential exposure
+	BatchSize int    `mapstructure:"batch_size" json:"batch_size"`
+}
+
+// FactStoreConfig holds configuration for the fact store
+type FactStoreConfig struct {
+	Type                string `mapstructure:"type" json:"type"`           // postgres, doltgres, dolt
+	ConnectionString    string `mapstructure:"connection_string" json:"-"` // May contain credentials, excluded from JSON
+	DataDir             string `mapstructure:"data_dir" json:"data_dir"`
+	EmbeddingDimensions int    `mapstructure:"embedding_dimensions" json:"embedding_dimensions"`
+	MaxConnections      int    `mapstructure:"max_connections" json:"max_connections"`
+}
+
+// WebhookConfig holds configuration for the server's ingestion webhooks
+type WebhookConfig struct {
+	// Secret signs deliveries that have no subscription secret of their own,
+	// including those to a request's callback_url
+	Secret string `mapstructure:"secret" json:"-"` // Excluded from JSON to prevent credential exposure
+	// Subscriptions are notified of every ingestion outcome in their group
+	Subscriptions []WebhookSubscription `mapstructure:"subscriptions" json:"subscriptions"`
+	// AllowedCallbackHosts lists the hosts a request's callback_url may use;
+	// empty rejects every callback_url
+	AllowedCallbackHosts []string `mapstructure:"allowed_callback_hosts" json:"allowed_callback_hosts"`
+	MaxAttempts          int      `mapstructure:"max_attempts" json:"max_attempts"`
+	InitialBackoff       int      `mapstructure:"initial_backoff" json:"initial_backoff"` // in seconds
+	Timeout              int      `mapstructure:"timeout" json:"timeout"`                 // in seconds
+	// DeliveryLogPath is the JSONL file each delivery attempt is recorded in
+	DeliveryLogPath string `mapstructure:"delivery_log_path" json:"delivery_log_path"`
+}
+
+// Enabled reports whether any webhook can be delivered: a subscription is
+// configured or callback URLs are allowed
+func (c WebhookConfig) Enabled() bool {
+	return len(c.Subscriptions) > 0 || len(c.AllowedCallbackHosts) > 0
+}
+
+// WebhookSubscription subscribes a URL to the ingestion outcomes of a group
+type WebhookSubscription struct {
+	GroupID string `mapstructure:"group_id" json:"group_id"`
+	URL     string `mapstructure:"url" json:"url"`
+	Secret  string `mapstructure:"secret" json:"-"` // Excluded from JSON to prevent credential exposure
 }
 
 // AlertConfig holds configuration for alerting
@@ -71,6 +129,28 @@ type ServerConfig struct {
 	Host string `mapstructure:"host"`
 	Port int    `mapstructure:"port"`
 	Mode string `mapstructure:"mode"` // gin mode: debug, release, test
+
+	// GroupID is the group the server reads and writes by default
+	GroupID string `mapstructure:"group_id"`
+	// AllowedGroupIDs lists additional groups that every search request may include
+	AllowedGroupIDs []string `mapstructure:"allowed_group_ids"`
+	// UserGroups lists further groups that requests carrying a given
+	// X-User-ID may include, keyed by user ID
+	UserGroups map[string][]string `mapstructure:"user_groups"`
+	// ProxySecret is shared with the authenticating proxy in front of the
+	// server, which sends it as X-Proxy-Secret with the X-User-ID it
+	// verified. Without it X-User-ID is ignored.
+	ProxySecret string `mapstructure:"proxy_secret" json:"-"` // Excluded from JSON to prevent credential exposure
+}
+
+// AllowedGroupsFor returns the groups a request from userID may search in
+// addition to GroupID.
+func (c ServerConfig) AllowedGroupsFor(userID string) []string {
+	groupIDs := append([]string{}, c.AllowedGroupIDs...)
+	if userID != "" {
+		groupIDs = append(groupIDs, c.UserGroups[userID]...)
+	}
+	return groupIDs
 }
 
 // DatabaseConfig holds database configuration
@@ -87,8 +167,28 @@ type NLPConfig struct {
 	// Models is a map of model configurations (e.g. "default", "embedding", "summary")
 	Models map[string]NLPModelConfig `mapstructure:"models"`
 
-	// RouterRules defines how to route requests
+	// RouterRules defines how to route requests. Providers are named by Models keys.
 	RouterRules []RouterRule `mapstructure:"router_rules"`
+
+	// DisableRetry turns off retrying failed NLP requests
+	DisableRetry bool `mapstructure:"disable_retry"`
+
+	// GroupUsage maps group IDs to the usage tag their requests are routed by
+	GroupUsage map[string]string `mapstructure:"group_usage"`
+	// DefaultUsage is the usage tag of groups missing from GroupUsage
+	DefaultUsage string `mapstructure:"default_usage"`
+	// SensitiveUsages are usage tags that must only reach local providers
+	SensitiveUsages []string `mapstructure:"sensitive_usages"`
+	// StrictRouting fails requests whose sensitive usage would reach a non-local provider
+	StrictRouting bool `mapstructure:"strict_routing"`
+
+	// EnsembleModels names the Models whose entity extractions are merged,
+	// e.g. a GLiNER model and "node_extraction"
+	EnsembleModels []string `mapstructure:"ensemble_models"`
+	// EnsembleStrategy decides entities not every model extracted: vote or adjudicate
+	EnsembleStrategy string `mapstructure:"ensemble_strategy"`
+	// EnsembleQuorum is the share of models a disputed entity needs to be kept by vote
+	EnsembleQuorum float64 `mapstructure:"ensemble_quorum"`
 }
 
 // NLPModelConfig holds configuration for a specific model
@@ -99,6 +199,12 @@ type NLPModelConfig struct {
 	BaseURL     string  `mapstructure:"base_url" json:"base_url"`
 	Temperature float32 `mapstructure:"temperature" json:"temperature"`
 	MaxTokens   int     `mapstructure:"max_tokens" json:"max_tokens"`
+
+	// Task selects the RustBERT pipeline: text_generation, summarization, ner or qa
+	Task string `mapstructure:"task" json:"task"`
+	// DeploymentID and APIVersion are used by the azure provider
+	DeploymentID string `mapstructure:"deployment_id" json:"deployment_id"`
+	APIVersion   string `mapstructure:"api_version" json:"api_version"`
 }
 
 // RouterRule defines a rule for routing requests
@@ -114,6 +220,11 @@ type EmbeddingConfig struct {
 	Model    string `mapstructure:"model" json:"model"`
 	APIKey   string `mapstructure:"api_key" json:"-"` // Excluded from JSON to prevent credential exposure
 	BaseURL  string `mapstructure:"base_url" json:"base_url"`
+
+	Dimensions   int    `mapstructure:"dimensions" json:"dimensions"`
+	DeploymentID string `mapstructure:"deployment_id" json:"deployment_id"` // azure only
+	// CachePath enables a Badger embedding cache at this directory
+	CachePath string `mapstructure:"cache_path" json:"cache_path"`
 }
 
 // Load loads configuration from file and environment variables
@@ -142,6 +253,7 @@ func setDefaults() {
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.mode", "debug")
+	viper.SetDefault("server.group_id", "default")
 
 	// Database defaults
 	viper.SetDefault("database.driver", "ladybug")
@@ -160,11 +272,17 @@ func setDefaults() {
 	viper.SetDefault("nlp.models.embedding.base_url", "embedeverything://")
 	viper.SetDefault("nlp.models.embedding.model", "all-MiniLM-L6-v2")
 
+	// Webhook defaults
+	viper.SetDefault("webhooks.max_attempts", 5)
+	viper.SetDefault("webhooks.initial_backoff", 1)
+	viper.SetDefault("webhooks.timeout", 10)
+
 	// Telemetry defaults
 	home, err := os.UserHomeDir()
 	if err == nil {
 		defaultPath := fmt.Sprintf("%s/.predicato/telemetry", home)
 		viper.SetDefault("telemetry.parquet_path", defaultPath)
+		viper.SetDefault("webhooks.delivery_log_path", fmt.Sprintf("%s/.predicato/webhooks/deliveries.jsonl", home))
 	}
 }
 
@@ -231,9 +349,28 @@ func overrideWithEnv(config *Config) {
 	if port := os.Getenv("SERVER_PORT"); port != "" {
 		viper.Set("server.port", port)
 	}
+	if groupID := os.Getenv("GROUP_ID"); groupID != "" {
+		config.Server.GroupID = groupID
+	}
+	if secret := os.Getenv("SERVER_PROXY_SECRET"); secret != "" {
+		config.Server.ProxySecret = secret
+	}
+	if groups := os.Getenv("ALLOWED_GROUP_IDS"); groups != "" {
+		config.Server.AllowedGroupIDs = nil
+		for _, groupID := range strings.Split(groups, ",") {
+			if groupID = strings.TrimSpace(groupID); groupID != "" {
+				config.Server.AllowedGroupIDs = append(config.Server.AllowedGroupIDs, groupID)
+			}
+		}
+	}
 
 	// Telemetry settings
 	if path := os.Getenv("TELEMETRY_PARQUET_PATH"); path != "" {
 		config.Telemetry.ParquetPath = path
 	}
+
+	// Webhook settings
+	if secret := os.Getenv("WEBHOOK_SECRET"); secret != "" {
+		config.Webhooks.Secret = secret
+	}
 }
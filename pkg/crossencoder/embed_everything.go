@@ -66,3 +66,8 @@ func (e *EmbedEverythingClient) Close() error {
 	e.reranker.Close()
 	return nil
 }
+
+// IsLocalFor implements nlp.LocalityReporter; EmbedEverything models always run locally.
+func (e *EmbedEverythingClient) IsLocalFor(usage string) bool {
+	return true
+}
@@ -6,6 +6,7 @@ import (
 	"sort"
 
 	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/nlp"
 	"github.com/soundprediction/predicato/pkg/utils"
 )
 
@@ -134,3 +135,8 @@ func (c *EmbeddingRerankerClient) Close() error {
 	}
 	return nil
 }
+
+// IsLocalFor implements nlp.LocalityReporter for the wrapped embedder.
+func (c *EmbeddingRerankerClient) IsLocalFor(usage string) bool {
+	return nlp.IsLocalFor(c.embedder, usage)
+}
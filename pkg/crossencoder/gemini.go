@@ -230,3 +230,8 @@ func (c *GeminiRerankerClient) Close() error {
 	}
 	return nil
 }
+
+// IsLocalFor implements nlp.LocalityReporter for the client that scores passages.
+func (c *GeminiRerankerClient) IsLocalFor(usage string) bool {
+	return nlp.IsLocalFor(c.client, usage)
+}
@@ -136,3 +136,8 @@ func (c *LocalRerankerClient) buildTermFrequency(tokens []string) map[string]int
 func (c *LocalRerankerClient) Close() error {
 	return nil
 }
+
+// IsLocalFor implements nlp.LocalityReporter; ranking runs in-process.
+func (c *LocalRerankerClient) IsLocalFor(usage string) bool {
+	return true
+}
@@ -153,3 +153,8 @@ func (c *OpenAIRerankerClient) Close() error {
 	}
 	return nil
 }
+
+// IsLocalFor implements nlp.LocalityReporter for the client that scores passages.
+func (c *OpenAIRerankerClient) IsLocalFor(usage string) bool {
+	return nlp.IsLocalFor(c.client, usage)
+}
@@ -0,0 +1,306 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// EmbeddingScanner is implemented by drivers that can page through every
+// stored vector of the graph and overwrite vectors in place. Re-embedding
+// the graph with another model uses it instead of searches, which return
+// nothing for an empty query, and instead of full upserts, which would
+// rewrite every other property.
+type EmbeddingScanner interface {
+	// ScanNodes returns up to limit nodes of nodeType (entity, episodic or
+	// community) across all groups, in UUID order, starting after afterUUID.
+	// The nodes carry their UUID, group, texts and embeddings only.
+	ScanNodes(ctx context.Context, nodeType types.NodeType, afterUUID string, limit int) ([]*types.Node, error)
+
+	// ScanEdges returns up to limit entity edges, the only edges that carry
+	// embeddings, across all groups in UUID order, starting after afterUUID.
+	// The edges carry their UUID, group, texts and embeddings only.
+	ScanEdges(ctx context.Context, afterUUID string, limit int) ([]*types.Edge, error)
+
+	// SetNodeEmbeddings overwrites the embeddings of nodes, leaving their
+	// other properties untouched. Inside a transaction the change commits
+	// with it.
+	SetNodeEmbeddings(ctx context.Context, nodes []*types.Node) error
+
+	// SetEdgeEmbeddings overwrites the embeddings of edges likewise.
+	SetEdgeEmbeddings(ctx context.Context, edges []*types.Edge) error
+}
+
+var (
+	_ EmbeddingScanner = (*Neo4jDriver)(nil)
+	_ EmbeddingScanner = (*MemgraphDriver)(nil)
+)
+
+// scanNodeLabel returns the label of the nodes of nodeType that can carry
+// embeddings.
+func scanNodeLabel(nodeType types.NodeType) (string, error) {
+	switch nodeType {
+	case types.EntityNodeType:
+		return "Entity", nil
+	case types.EpisodicNodeType:
+		return "Episodic", nil
+	case types.CommunityNodeType:
+		return "Community", nil
+	}
+	return "", fmt.Errorf("unsupported node type for scanning: %s", nodeType)
+}
+
+// resultRows returns the rows of an ExecuteQuery result, which are either
+// Bolt records or Ladybug row maps.
+func resultRows(result any) ([]map[string]any, error) {
+	if records, ok := AsRecordSlice(result); ok {
+		rows := make([]map[string]any, len(records))
+		for i, record := range records {
+			rows[i] = record.AsMap()
+		}
+		return rows, nil
+	}
+	if rows, ok := result.([]map[string]interface{}); ok {
+		return rows, nil
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected result type: got %T", result)
+}
+
+// embeddingValue decodes a stored embedding: a JSON string on Neo4j and
+// Memgraph, or a float list on Ladybug.
+func embeddingValue(value any) []float32 {
+	switch v := value.(type) {
+	case string:
+		embedding, _ := parseEmbeddingJSON(v)
+		return embedding
+	case []float32:
+		return v
+	case []float64:
+		embedding := make([]float32, len(v))
+		for i, f := range v {
+			embedding[i] = float32(f)
+		}
+		return embedding
+	case []interface{}:
+		embedding := make([]float32, 0, len(v))
+		for _, item := range v {
+			switch f := item.(type) {
+			case float64:
+				embedding = append(embedding, float32(f))
+			case float32:
+				embedding = append(embedding, f)
+			}
+		}
+		return embedding
+	}
+	return nil
+}
+
+// embeddingJSON encodes an embedding for the JSON properties of Neo4j and
+// Memgraph, or returns nil for an empty one, which removes the property.
+func embeddingJSON(embedding []float32) (any, error) {
+	if len(embedding) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(embedding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Neo4j and Memgraph keep embeddings as JSON properties, plus the native
+// embedding_vector mirror read by the vector indexes.
+const (
+	boltScanNodesQuery = `
+		MATCH (n:%s) WHERE n.uuid > $after
+		RETURN n.uuid AS uuid, n.group_id AS group_id, n.name AS name, n.summary AS summary,
+			n.content AS content, n.name_embedding AS name_embedding, n.embedding AS embedding
+		ORDER BY n.uuid LIMIT $limit
+	`
+	boltScanEdgesQuery = `
+		MATCH ()-[e:RELATES_TO]->() WHERE e.uuid > $after
+		RETURN e.uuid AS uuid, e.group_id AS group_id, e.fact AS fact, e.summary AS summary,
+			e.fact_embedding AS fact_embedding, e.embedding AS embedding
+		ORDER BY e.uuid LIMIT $limit
+	`
+	boltSetNodeEmbeddingsQuery = `
+		UNWIND $rows AS row
+		MATCH (n:%s {uuid: row.uuid})
+		SET n.name_embedding = row.name_embedding, n.embedding = row.embedding, n.embedding_vector = row.vector
+	`
+	boltSetEdgeEmbeddingsQuery = `
+		UNWIND $rows AS row
+		MATCH ()-[e:RELATES_TO {uuid: row.uuid}]->()
+		SET e.fact_embedding = row.fact_embedding, e.embedding = row.embedding, e.embedding_vector = row.vector
+	`
+)
+
+func boltScanNodes(ctx context.Context, d GraphDriver, nodeType types.NodeType, afterUUID string, limit int) ([]*types.Node, error) {
+	label, err := scanNodeLabel(nodeType)
+	if err != nil {
+		return nil, err
+	}
+	result, _, _, err := d.ExecuteQuery(ctx, fmt.Sprintf(boltScanNodesQuery, label), map[string]interface{}{
+		"after": afterUUID,
+		"limit": int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s nodes: %w", label, err)
+	}
+	rows, err := resultRows(result)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*types.Node, 0, len(rows))
+	for _, row := range rows {
+		node := &types.Node{Type: nodeType}
+		node.Uuid, _ = AsString(row["uuid"])
+		node.GroupID, _ = AsString(row["group_id"])
+		node.Name, _ = AsString(row["name"])
+		node.Summary, _ = AsString(row["summary"])
+		node.Content, _ = AsString(row["content"])
+		node.NameEmbedding = embeddingValue(row["name_embedding"])
+		node.Embedding = embeddingValue(row["embedding"])
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func boltScanEdges(ctx context.Context, d GraphDriver, afterUUID string, limit int) ([]*types.Edge, error) {
+	result, _, _, err := d.ExecuteQuery(ctx, boltScanEdgesQuery, map[string]interface{}{
+		"after": afterUUID,
+		"limit": int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan edges: %w", err)
+	}
+	rows, err := resultRows(result)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*types.Edge, 0, len(rows))
+	for _, row := range rows {
+		edge := &types.Edge{Type: types.EntityEdgeType}
+		edge.Uuid, _ = AsString(row["uuid"])
+		edge.GroupID, _ = AsString(row["group_id"])
+		edge.Fact, _ = AsString(row["fact"])
+		edge.Summary, _ = AsString(row["summary"])
+		edge.FactEmbedding = embeddingValue(row["fact_embedding"])
+		edge.Embedding = embeddingValue(row["embedding"])
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+func boltSetNodeEmbeddings(ctx context.Context, d GraphDriver, indexes *nativeIndexState, nodes []*types.Node) error {
+	rowsByLabel := make(map[string][]map[string]interface{})
+	for _, node := range nodes {
+		label, err := scanNodeLabel(node.Type)
+		if err != nil {
+			return err
+		}
+		nameEmbedding, err := embeddingJSON(node.NameEmbedding)
+		if err != nil {
+			return err
+		}
+		embedding, err := embeddingJSON(node.Embedding)
+		if err != nil {
+			return err
+		}
+		row := map[string]interface{}{
+			"uuid":           node.Uuid,
+			"name_embedding": nameEmbedding,
+			"embedding":      embedding,
+			"vector":         nil,
+		}
+		// A vector of another size cannot enter the index; it is backfilled
+		// once the indexes are created with the new dimensions
+		if vector := indexes.vectorProperty(node.Embedding); vector != nil {
+			row["vector"] = vector
+		}
+		rowsByLabel[label] = append(rowsByLabel[label], row)
+	}
+	for label, rows := range rowsByLabel {
+		if _, _, _, err := d.ExecuteQuery(ctx, fmt.Sprintf(boltSetNodeEmbeddingsQuery, label), map[string]interface{}{"rows": rows}); err != nil {
+			return fmt.Errorf("failed to set %s embeddings: %w", label, err)
+		}
+	}
+	return nil
+}
+
+func boltSetEdgeEmbeddings(ctx context.Context, d GraphDriver, indexes *nativeIndexState, edges []*types.Edge) error {
+	if len(edges) == 0 {
+		return nil
+	}
+	rows := make([]map[string]interface{}, 0, len(edges))
+	for _, edge := range edges {
+		factEmbedding, err := embeddingJSON(edge.FactEmbedding)
+		if err != nil {
+			return err
+		}
+		embedding, err := embeddingJSON(edge.Embedding)
+		if err != nil {
+			return err
+		}
+		row := map[string]interface{}{
+			"uuid":           edge.Uuid,
+			"fact_embedding": factEmbedding,
+			"embedding":      embedding,
+			"vector":         nil,
+		}
+		if vector := indexes.vectorProperty(edge.Embedding); vector != nil {
+			row["vector"] = vector
+		}
+		rows = append(rows, row)
+	}
+	if _, _, _, err := d.ExecuteQuery(ctx, boltSetEdgeEmbeddingsQuery, map[string]interface{}{"rows": rows}); err != nil {
+		return fmt.Errorf("failed to set edge embeddings: %w", err)
+	}
+	return nil
+}
+
+// ScanNodes implements EmbeddingScanner.
+func (n *Neo4jDriver) ScanNodes(ctx context.Context, nodeType types.NodeType, afterUUID string, limit int) ([]*types.Node, error) {
+	return boltScanNodes(ctx, n, nodeType, afterUUID, limit)
+}
+
+// ScanEdges implements EmbeddingScanner.
+func (n *Neo4jDriver) ScanEdges(ctx context.Context, afterUUID string, limit int) ([]*types.Edge, error) {
+	return boltScanEdges(ctx, n, afterUUID, limit)
+}
+
+// SetNodeEmbeddings implements EmbeddingScanner.
+func (n *Neo4jDriver) SetNodeEmbeddings(ctx context.Context, nodes []*types.Node) error {
+	return boltSetNodeEmbeddings(ctx, n, &n.indexes, nodes)
+}
+
+// SetEdgeEmbeddings implements EmbeddingScanner.
+func (n *Neo4jDriver) SetEdgeEmbeddings(ctx context.Context, edges []*types.Edge) error {
+	return boltSetEdgeEmbeddings(ctx, n, &n.indexes, edges)
+}
+
+// ScanNodes implements EmbeddingScanner.
+func (m *MemgraphDriver) ScanNodes(ctx context.Context, nodeType types.NodeType, afterUUID string, limit int) ([]*types.Node, error) {
+	return boltScanNodes(ctx, m, nodeType, afterUUID, limit)
+}
+
+// ScanEdges implements EmbeddingScanner.
+func (m *MemgraphDriver) ScanEdges(ctx context.Context, afterUUID string, limit int) ([]*types.Edge, error) {
+	return boltScanEdges(ctx, m, afterUUID, limit)
+}
+
+// SetNodeEmbeddings implements EmbeddingScanner.
+func (m *MemgraphDriver) SetNodeEmbeddings(ctx context.Context, nodes []*types.Node) error {
+	return boltSetNodeEmbeddings(ctx, m, &m.indexes, nodes)
+}
+
+// SetEdgeEmbeddings implements EmbeddingScanner.
+func (m *MemgraphDriver) SetEdgeEmbeddings(ctx context.Context, edges []*types.Edge) error {
+	return boltSetEdgeEmbeddings(ctx, m, &m.indexes, edges)
+}
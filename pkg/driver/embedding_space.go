@@ -0,0 +1,124 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// EmbeddingSpaceStore is implemented by drivers that record which embedding
+// model produced the vectors stored in the graph.
+type EmbeddingSpaceStore interface {
+	// GetEmbeddingSpace returns the recorded embedding space, or nil if none
+	// has been recorded.
+	GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error)
+
+	// SetEmbeddingSpace records the embedding space of the stored vectors.
+	// Inside a transaction the change commits with it.
+	SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error
+}
+
+// The embedding space is stored on a single EmbeddingSpace node.
+const (
+	getEmbeddingSpaceQuery = `
+		MATCH (s:EmbeddingSpace {id: 'graph'})
+		RETURN s.model AS model, s.dimensions AS dimensions, s.updated_at AS updated_at
+	`
+	setEmbeddingSpaceQuery = `
+		MERGE (s:EmbeddingSpace {id: 'graph'})
+		SET s.model = $model, s.dimensions = $dimensions, s.updated_at = $updated_at
+	`
+)
+
+// getEmbeddingSpace reads the embedding space node through the driver's
+// ExecuteQuery, which returns either Bolt records or Ladybug row maps.
+func getEmbeddingSpace(ctx context.Context, d GraphDriver) (*types.EmbeddingSpace, error) {
+	result, _, _, err := d.ExecuteQuery(ctx, getEmbeddingSpaceQuery, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding space: %w", err)
+	}
+
+	var row map[string]any
+	if records, ok := AsRecordSlice(result); ok {
+		if len(records) == 0 {
+			return nil, nil
+		}
+		row = records[0].AsMap()
+	} else if rows, ok := result.([]map[string]interface{}); ok {
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		row = rows[0]
+	} else {
+		return nil, fmt.Errorf("unexpected result type: got %T", result)
+	}
+
+	space := &types.EmbeddingSpace{}
+	space.Model, _ = AsString(row["model"])
+	if dims, ok := AsInt64(row["dimensions"]); ok {
+		space.Dimensions = int(dims)
+	}
+	if updatedAt, ok := AsString(row["updated_at"]); ok {
+		space.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	}
+	return space, nil
+}
+
+// setEmbeddingSpace writes the embedding space node through the driver's
+// ExecuteQuery, so it joins an active transaction.
+func setEmbeddingSpace(ctx context.Context, d GraphDriver, space *types.EmbeddingSpace) error {
+	if space == nil {
+		return nil
+	}
+	updatedAt := space.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+	_, _, _, err := d.ExecuteQuery(ctx, setEmbeddingSpaceQuery, map[string]interface{}{
+		"model":      space.Model,
+		"dimensions": int64(space.Dimensions),
+		"updated_at": updatedAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set embedding space: %w", err)
+	}
+	return nil
+}
+
+// GetEmbeddingSpace returns the recorded embedding space of the graph.
+func (n *Neo4jDriver) GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error) {
+	return getEmbeddingSpace(ctx, n)
+}
+
+// SetEmbeddingSpace records the embedding space of the graph.
+func (n *Neo4jDriver) SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error {
+	return setEmbeddingSpace(ctx, n, space)
+}
+
+// GetEmbeddingSpace returns the recorded embedding space of the graph.
+func (m *MemgraphDriver) GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error) {
+	return getEmbeddingSpace(ctx, m)
+}
+
+// SetEmbeddingSpace records the embedding space of the graph.
+func (m *MemgraphDriver) SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error {
+	return setEmbeddingSpace(ctx, m, space)
+}
+
+// GetEmbeddingSpace returns the recorded embedding space of the graph.
+func (k *LadybugDriver) GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error) {
+	return getEmbeddingSpace(ctx, k)
+}
+
+// SetEmbeddingSpace records the embedding space of the graph.
+func (k *LadybugDriver) SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error {
+	return setEmbeddingSpace(ctx, k, space)
+}
+
+var (
+	_ EmbeddingSpaceStore = (*Neo4jDriver)(nil)
+	_ EmbeddingSpaceStore = (*MemgraphDriver)(nil)
+	_ EmbeddingSpaceStore = (*LadybugDriver)(nil)
+)
@@ -184,6 +184,13 @@ type DatabaseAdmin interface {
 	GetAllGroupIDs(ctx context.Context) ([]string, error)
 }
 
+// EmbeddingDimensionsSetter is implemented by drivers that size native vector
+// indexes from the embedder's output dimension. Call it before CreateIndices.
+type EmbeddingDimensionsSetter interface {
+	// SetEmbeddingDimensions sets the dimension used for vector indexes.
+	SetEmbeddingDimensions(dims int)
+}
+
 // Ensure GraphDriver implements all focused interfaces.
 // This compile-time check ensures backward compatibility.
 var _ interface {
@@ -18,6 +18,7 @@ import (
 
 	ladybug "github.com/LadybugDB/go-ladybug"
 
+	"github.com/soundprediction/predicato/pkg/telemetry"
 	"github.com/soundprediction/predicato/pkg/types"
 )
 
@@ -87,13 +88,26 @@ const LadybugSchemaQueries = `
         group_id STRING,
         created_at TIMESTAMP
     );
+    CREATE NODE TABLE IF NOT EXISTS EmbeddingSpace (
+        id STRING PRIMARY KEY,
+        model STRING,
+        dimensions INT64,
+        updated_at STRING
+    );
 `
 
-// writeOperation represents a queued write operation
+// writeOperation represents a queued write operation. When tx is set the
+// operation is a transaction: the worker runs tx between BEGIN TRANSACTION
+// and COMMIT, rolling back if it fails, and calls committed after a
+// successful COMMIT. When exclusive is set the worker runs it outside any
+// transaction while all other queries wait.
 type writeOperation struct {
-	query    string
-	params   map[string]interface{}
-	resultCh chan writeResult
+	query     string
+	params    map[string]interface{}
+	tx        func() (interface{}, error)
+	committed func()
+	exclusive func() (interface{}, error)
+	resultCh  chan writeResult
 }
 
 // writeResult holds the result of a write operation
@@ -117,12 +131,23 @@ type LadybugDriver struct {
 	originalPath string     // Original path before copying to temp
 	mu           sync.Mutex // Mutex to protect database operations from concurrent access
 
+	// txMu keeps queries outside a transaction from running on the shared
+	// connection while a transaction is open.
+	txMu sync.RWMutex
+
 	// Write queue for transparent concurrency handling
 	writeQueue chan writeOperation
 	writeWg    sync.WaitGroup
 	closeCh    chan struct{}
 	closed     bool
 	closeMu    sync.RWMutex
+
+	// vectors is the HNSW index over entity and edge embeddings, nil when disabled
+	vectors *ladybugVectorIndex
+
+	// readOnly rejects writes; config is kept for reopening snapshots
+	readOnly bool
+	config   *LadybugDriverConfig
 }
 
 // copyDir recursively copies a directory from src to dst
@@ -227,6 +252,32 @@ type LadybugDriverConfig struct {
 
 	// Maximum database size in bytes (defaults to 8TB)
 	MaxDbSize uint64
+
+	// Open the database read-only (defaults to false)
+	// Read-only drivers reject writes and do not create the schema. Use them to
+	// serve queries from a snapshot taken with Snapshot while another process
+	// writes to the original database, and call SwapSnapshot to move to a newer one
+	ReadOnly bool
+
+	// Disable the in-process HNSW index used by SearchNodesByEmbedding and
+	// SearchEdgesByEmbedding, so every search scans all embeddings (defaults to false)
+	DisableVectorIndex bool
+
+	// HNSW neighbors per node (defaults to 16)
+	// Higher values improve recall at the cost of memory and insert time
+	VectorIndexM int
+
+	// HNSW candidate list size when inserting (defaults to 200)
+	// Higher values improve index quality at the cost of insert time
+	VectorIndexEfConstruction int
+
+	// HNSW candidate list size when searching (defaults to 100)
+	// Higher values improve recall at the cost of search latency
+	VectorIndexEfSearch int
+
+	// Minimum number of embeddings in a group before searches use the index
+	// instead of an exact scan (defaults to 1000)
+	VectorIndexMinSize int
 }
 
 // DefaultLadybugDriverConfig returns a LadybugDriverConfig with sensible defaults
@@ -238,6 +289,11 @@ func DefaultLadybugDriverConfig() *LadybugDriverConfig {
 		BufferPoolSize:       1024 * 1024 * 1024, // 1GB
 		EnableCompression:    true,
 		MaxDbSize:            1 << 43, // 8TB
+
+		VectorIndexM:              16,
+		VectorIndexEfConstruction: 200,
+		VectorIndexEfSearch:       100,
+		VectorIndexMinSize:        1000,
 	}
 }
 
@@ -277,6 +333,34 @@ func (c *LadybugDriverConfig) WithMaxDbSize(size uint64) *LadybugDriverConfig {
 	return c
 }
 
+// WithReadOnly opens the database read-only
+func (c *LadybugDriverConfig) WithReadOnly(readOnly bool) *LadybugDriverConfig {
+	c.ReadOnly = readOnly
+	return c
+}
+
+// WithVectorIndex enables or disables the HNSW vector index
+func (c *LadybugDriverConfig) WithVectorIndex(enable bool) *LadybugDriverConfig {
+	c.DisableVectorIndex = !enable
+	return c
+}
+
+// WithVectorIndexParams sets the HNSW neighbors per node and the candidate
+// list sizes used when inserting and searching
+func (c *LadybugDriverConfig) WithVectorIndexParams(m, efConstruction, efSearch int) *LadybugDriverConfig {
+	c.VectorIndexM = m
+	c.VectorIndexEfConstruction = efConstruction
+	c.VectorIndexEfSearch = efSearch
+	return c
+}
+
+// WithVectorIndexMinSize sets the number of embeddings a group needs before
+// searches use the vector index
+func (c *LadybugDriverConfig) WithVectorIndexMinSize(size int) *LadybugDriverConfig {
+	c.VectorIndexMinSize = size
+	return c
+}
+
 // NewLadybugDriver creates a new Ladybug driver instance with exact same signature as Python
 // Parameters:
 //   - db: Database path (defaults to ":memory:" like Python)
@@ -323,6 +407,18 @@ func NewLadybugDriverWithConfig(config *LadybugDriverConfig) (*LadybugDriver, er
 	if config.MaxDbSize == 0 {
 		config.MaxDbSize = 1 << 43 // 8TB
 	}
+	if config.VectorIndexM < 2 {
+		config.VectorIndexM = 16
+	}
+	if config.VectorIndexEfConstruction <= 0 {
+		config.VectorIndexEfConstruction = 200
+	}
+	if config.VectorIndexEfSearch <= 0 {
+		config.VectorIndexEfSearch = 100
+	}
+	if config.VectorIndexMinSize < 0 {
+		config.VectorIndexMinSize = 0
+	}
 
 	originalPath := config.DBPath
 	tempDbPath := ""
@@ -330,13 +426,7 @@ func NewLadybugDriverWithConfig(config *LadybugDriverConfig) (*LadybugDriver, er
 
 	// Create a SystemConfig manually to avoid version mismatch issues with DefaultSystemConfig()
 	// These are safe, conservative defaults that work with ladybug
-	systemConfig := ladybug.SystemConfig{
-		BufferPoolSize:    config.BufferPoolSize,
-		MaxNumThreads:     uint64(config.MaxConcurrentQueries),
-		EnableCompression: config.EnableCompression,
-		ReadOnly:          false,
-		MaxDbSize:         config.MaxDbSize,
-	}
+	systemConfig := ladybugSystemConfig(config)
 
 	// Try to open the database with our custom config
 	database, err := ladybug.OpenDatabase(db, systemConfig)
@@ -369,7 +459,7 @@ func NewLadybugDriverWithConfig(config *LadybugDriverConfig) (*LadybugDriver, er
 			}
 
 			db = tempDbPath // Use temp path for the rest of initialization
-		} else if db != ":memory:" {
+		} else if db != ":memory:" && !config.ReadOnly {
 			// Not a lock error, might be WAL corruption. Try to recover.
 			log.Printf("Failed to open database: %v. Checking for WAL corruption...", err)
 
@@ -410,14 +500,26 @@ func NewLadybugDriverWithConfig(config *LadybugDriverConfig) (*LadybugDriver, er
 		originalPath: originalPath,
 		writeQueue:   make(chan writeOperation, config.WriteQueueSize),
 		closeCh:      make(chan struct{}),
+		readOnly:     config.ReadOnly,
+		config:       config,
+	}
+
+	// Persist the vector index next to on-disk databases, but not next to a
+	// temporary copy, which is removed on Close
+	vectorIndexPath := db
+	if db == ":memory:" || tempDbPath != "" {
+		vectorIndexPath = ""
 	}
+	driver.vectors = newLadybugVectorIndex(config, vectorIndexPath)
 
 	// Start the write worker goroutine
 	driver.writeWg.Add(1)
 	go driver.writeWorker()
 
-	// Setup schema exactly like Python
-	driver.setupSchema()
+	// Setup schema exactly like Python; read-only databases must already have it
+	if !driver.readOnly {
+		driver.setupSchema()
+	}
 
 	// Create connection - Go ladybug doesn't have AsyncConnection but we simulate the interface
 	client, err := ladybug.OpenConnection(database)
@@ -456,7 +558,10 @@ func NewLadybugDriverWithConfig(config *LadybugDriverConfig) (*LadybugDriver, er
 // Returns (results, summary, keys) tuple like Python, though summary and keys are unused in Ladybug.
 // Write operations are automatically queued and executed sequentially for thread safety.
 // Read operations execute directly with mutex protection for better performance.
-func (k *LadybugDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kwargs map[string]interface{}) (interface{}, interface{}, interface{}, error) {
+func (k *LadybugDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kwargs map[string]interface{}) (records interface{}, summary interface{}, keys interface{}, err error) {
+	ctx, done := traceQuery(ctx, k.Provider(), "query")
+	defer func() { done(err) }()
+
 	// Check if driver is closed
 	k.closeMu.RLock()
 	if k.closed {
@@ -465,8 +570,16 @@ func (k *LadybugDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kw
 	}
 	k.closeMu.RUnlock()
 
+	// Queries from inside a transaction already hold the write queue
+	if _, ok := transactionFrom(ctx, k); ok {
+		return k.executeQueryInternal(cypherQuery, kwargs)
+	}
+
 	// Route write operations to the queue for sequential execution
 	if k.isWriteQuery(cypherQuery) {
+		if k.readOnly {
+			return nil, nil, nil, ErrLadybugReadOnly
+		}
 		resultCh := make(chan writeResult, 1)
 		op := writeOperation{
 			query:    cypherQuery,
@@ -475,20 +588,123 @@ func (k *LadybugDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kw
 		}
 
 		// Send to write queue (non-blocking with timeout for safety)
-		select {
-		case k.writeQueue <- op:
-			// Wait for result
-			result := <-resultCh
-			return result.result, result.cols, result.meta, result.err
-		case <-time.After(5 * time.Minute):
+		if !k.enqueueWrite(op) {
 			return nil, nil, nil, fmt.Errorf("write queue timeout after 5m")
 		}
+		result := <-resultCh
+		return result.result, result.cols, result.meta, result.err
 	}
 
-	// Read operations execute directly with mutex protection
+	// Read operations execute directly with mutex protection, waiting for
+	// any open transaction to finish
+	k.txMu.RLock()
+	defer k.txMu.RUnlock()
 	return k.executeQueryInternal(cypherQuery, kwargs)
 }
 
+// executeTransaction runs work as one unit through the write queue. Queries
+// issued by work with the context it receives run inside a single database
+// transaction that is committed when work succeeds and rolled back otherwise.
+// Queries made with any other context wait for the transaction and so
+// deadlock, and slow calls such as embedding requests hold up every other
+// query; callers make them before the transaction.
+func (k *LadybugDriver) executeTransaction(ctx context.Context, work func(context.Context) (interface{}, error)) (interface{}, error) {
+	// Nested calls join the enclosing transaction
+	if _, ok := transactionFrom(ctx, k); ok {
+		return work(ctx)
+	}
+
+	k.closeMu.RLock()
+	if k.closed {
+		k.closeMu.RUnlock()
+		return nil, fmt.Errorf("driver is closed")
+	}
+	k.closeMu.RUnlock()
+
+	if k.readOnly {
+		return nil, ErrLadybugReadOnly
+	}
+
+	txCtx := withTransaction(ctx, k, nil)
+	active, _ := transactionFrom(txCtx, k)
+	resultCh := make(chan writeResult, 1)
+	op := writeOperation{
+		tx: func() (interface{}, error) {
+			return work(txCtx)
+		},
+		committed: active.committed,
+		resultCh:  resultCh,
+	}
+
+	if !k.enqueueWrite(op) {
+		return nil, fmt.Errorf("write queue timeout after 5m")
+	}
+	result := <-resultCh
+	return result.result, result.err
+}
+
+// enqueueWrite sends op to the write queue, reporting false if the queue
+// stays full for 5 minutes. The queue depth is exported as a metric.
+func (k *LadybugDriver) enqueueWrite(op writeOperation) bool {
+	telemetry.AddLadybugWriteQueueDepth(1)
+	select {
+	case k.writeQueue <- op:
+		return true
+	case <-time.After(5 * time.Minute):
+		telemetry.AddLadybugWriteQueueDepth(-1)
+		return false
+	}
+}
+
+// runWriteOperation executes a queued query, transaction or exclusive operation.
+func (k *LadybugDriver) runWriteOperation(op writeOperation) writeResult {
+	if op.exclusive != nil {
+		k.txMu.Lock()
+		defer k.txMu.Unlock()
+		result, err := op.exclusive()
+		return writeResult{result: result, err: err}
+	}
+
+	if op.tx == nil {
+		result, cols, meta, err := k.executeQueryInternal(op.query, op.params)
+		return writeResult{result, cols, meta, err}
+	}
+
+	k.txMu.Lock()
+	defer k.txMu.Unlock()
+
+	if _, _, _, err := k.executeQueryInternal("BEGIN TRANSACTION", nil); err != nil {
+		return writeResult{err: fmt.Errorf("failed to begin transaction: %w", err)}
+	}
+
+	result, err := op.tx()
+	if err != nil {
+		if _, _, _, rollbackErr := k.executeQueryInternal("ROLLBACK", nil); rollbackErr != nil {
+			log.Printf("Failed to roll back ladybug transaction: %v", rollbackErr)
+		}
+		return writeResult{err: err}
+	}
+
+	if _, _, _, err := k.executeQueryInternal("COMMIT", nil); err != nil {
+		return writeResult{err: fmt.Errorf("failed to commit transaction: %w", err)}
+	}
+	if op.committed != nil {
+		op.committed()
+	}
+	return writeResult{result: result}
+}
+
+// onCommit runs fn once the writes made with ctx are committed: when the
+// enclosing transaction commits, or immediately outside a transaction. A
+// rolled-back transaction never runs fn.
+func (k *LadybugDriver) onCommit(ctx context.Context, fn func()) {
+	if active, ok := transactionFrom(ctx, k); ok {
+		active.afterCommit(fn)
+		return
+	}
+	fn()
+}
+
 // isWriteQuery checks if a query is a write operation (CREATE, MERGE, SET, DELETE, etc.)
 func (k *LadybugDriver) isWriteQuery(query string) bool {
 	upperQuery := strings.ToUpper(strings.TrimSpace(query))
@@ -515,16 +731,16 @@ func (k *LadybugDriver) writeWorker() {
 			for {
 				select {
 				case op := <-k.writeQueue:
-					result, cols, meta, err := k.executeQueryInternal(op.query, op.params)
-					op.resultCh <- writeResult{result, cols, meta, err}
+					telemetry.AddLadybugWriteQueueDepth(-1)
+					op.resultCh <- k.runWriteOperation(op)
 					close(op.resultCh)
 				default:
 					return
 				}
 			}
 		case op := <-k.writeQueue:
-			result, cols, meta, err := k.executeQueryInternal(op.query, op.params)
-			op.resultCh <- writeResult{result, cols, meta, err}
+			telemetry.AddLadybugWriteQueueDepth(-1)
+			op.resultCh <- k.runWriteOperation(op)
 			close(op.resultCh)
 		}
 	}
@@ -646,6 +862,10 @@ func (k *LadybugDriver) Close() error {
 	close(k.closeCh)
 	k.writeWg.Wait()
 
+	if err := k.vectors.save(); err != nil {
+		log.Printf("Warning: Failed to persist vector index: %v", err)
+	}
+
 	// Clean up temporary database copy if it was created
 	if k.tempDbPath != "" {
 		tempDir := filepath.Dir(k.tempDbPath)
@@ -837,15 +1057,17 @@ func (k *LadybugDriver) UpsertNode(ctx context.Context, node *types.Node) error
 		if err != nil {
 			return fmt.Errorf("failed to create node %w", err)
 		}
-		return err
-
+	} else {
+		updateErr := k.executeNodeUpdateQuery(ctx, node, tableName)
+		if updateErr != nil {
+			return fmt.Errorf("failed to update node %w", updateErr)
+		}
 	}
 
-	updateErr := k.executeNodeUpdateQuery(ctx, node, tableName)
-	if updateErr != nil {
-		return fmt.Errorf("failed to update node %w", updateErr)
+	if tableName == "Entity" {
+		groupID, uuid, embedding := node.GroupID, node.Uuid, node.NameEmbedding
+		k.onCommit(ctx, func() { k.vectors.add(ladybugEntityVectors, groupID, uuid, embedding) })
 	}
-
 	return nil
 }
 
@@ -860,12 +1082,24 @@ func (k *LadybugDriver) DeleteNode(ctx context.Context, nodeID, groupID string)
 		"group_id": groupID,
 	}
 
+	// Entities and edges have vector index entries; only the index of the
+	// table the node is found in is updated
+	vectorKinds := map[string]ladybugVectorKind{
+		"Entity":         ladybugEntityVectors,
+		"RelatesToNode_": ladybugEdgeVectors,
+	}
+	var indexed []ladybugVectorKind
+
 	for _, table := range tables {
 		// Validate table name against allowlist to prevent label injection
 		if !allowedNodeLabels[table] {
 			continue
 		}
 
+		if kind, ok := vectorKinds[table]; ok && k.nodeInTable(ctx, table, params) {
+			indexed = append(indexed, kind)
+		}
+
 		// Delete relationships first using parameterized query
 		// Note: Table name is validated above, safe to interpolate
 		deleteRelsQuery := fmt.Sprintf(`
@@ -886,9 +1120,31 @@ func (k *LadybugDriver) DeleteNode(ctx context.Context, nodeID, groupID string)
 		k.ExecuteQuery(ctx, deleteNodeQuery, params) // Ignore errors for nodes not in this table
 	}
 
+	k.onCommit(ctx, func() {
+		for _, kind := range indexed {
+			k.vectors.remove(kind, groupID, nodeID)
+		}
+	})
 	return nil
 }
 
+// nodeInTable reports whether the node identified by params' uuid and
+// group_id exists in table.
+func (k *LadybugDriver) nodeInTable(ctx context.Context, table string, params map[string]interface{}) bool {
+	query := fmt.Sprintf(`
+		MATCH (n:%s)
+		WHERE n.uuid = $uuid AND n.group_id = $group_id
+		RETURN n.uuid
+		LIMIT 1
+	`, table)
+	result, _, _, err := k.ExecuteQuery(ctx, query, params)
+	if err != nil {
+		return false
+	}
+	rows, ok := result.([]map[string]interface{})
+	return ok && len(rows) > 0
+}
+
 // GetNodes retrieves multiple nodes by their IDs.
 func (k *LadybugDriver) GetNodes(ctx context.Context, nodeIDs []string, groupID string) ([]*types.Node, error) {
 	if len(nodeIDs) == 0 {
@@ -937,7 +1193,7 @@ func (k *LadybugDriver) GetEdge(ctx context.Context, edgeID, groupID string) (*t
 	query := `
 		MATCH (a:Entity)-[:RELATES_TO]->(rel:RelatesToNode_)-[:RELATES_TO]->(b:Entity)
 		WHERE rel.uuid = $uuid AND rel.group_id = $group_id
-		RETURN rel.uuid as uuid, rel.name as name, rel.fact as fact, rel.group_id as group_id, a.uuid AS source_id, b.uuid AS target_id
+		RETURN rel.uuid as uuid, rel.name as name, rel.fact as fact, rel.group_id as group_id, rel.attributes as attributes, a.uuid AS source_id, b.uuid AS target_id
 	`
 
 	params := map[string]interface{}{
@@ -972,14 +1228,15 @@ func (k *LadybugDriver) UpsertEdge(ctx context.Context, edge *types.Edge) error
 		if err != nil {
 			return fmt.Errorf("failed to create edge %w", err)
 		}
-		return err
-	}
-
-	updateErr := k.executeEdgeUpdateQuery(ctx, edge)
-	if updateErr != nil {
-		return fmt.Errorf("failed to update edge %w", updateErr)
+	} else {
+		updateErr := k.executeEdgeUpdateQuery(ctx, edge)
+		if updateErr != nil {
+			return fmt.Errorf("failed to update edge %w", updateErr)
+		}
 	}
 
+	groupID, uuid, embedding := edge.GroupID, edge.Uuid, edge.FactEmbedding
+	k.onCommit(ctx, func() { k.vectors.add(ladybugEdgeVectors, groupID, uuid, embedding) })
 	return nil
 }
 
@@ -1009,12 +1266,7 @@ func (k *LadybugDriver) EdgeExists(ctx context.Context, edge *types.Edge) bool {
 }
 
 func (k *LadybugDriver) executeEdgeCreateQuery(ctx context.Context, edge *types.Edge) error {
-	var metadataJSON string
-	if edge.Metadata != nil {
-		if data, err := json.Marshal(edge.Metadata); err == nil {
-			metadataJSON = string(data)
-		}
-	}
+	metadataJSON := edgeAttributesJSON(edge)
 
 	// Build query dynamically to handle empty arrays with explicit CASTs
 	var factEmbeddingValue string
@@ -1043,9 +1295,16 @@ func (k *LadybugDriver) executeEdgeCreateQuery(ctx context.Context, edge *types.
 		episodesValue = "CAST([] AS STRING[])"
 	}
 
+	// RELATES_TO only joins entities, so the SOURCED_FROM edge of a source
+	// node hangs off the source alone and names its episode in episodes
+	matchTarget, linkTarget := "MATCH (b:Entity {uuid: $target_uuid, group_id: $group_id})", "CREATE (rel)-[:RELATES_TO]->(b)"
+	if edge.Type == types.SourceEdgeType {
+		matchTarget, linkTarget = "", ""
+	}
+
 	query := fmt.Sprintf(`
 		MATCH (a:Entity {uuid: $source_uuid, group_id: $group_id})
-		MATCH (b:Entity {uuid: $target_uuid, group_id: $group_id})
+		%s
 		CREATE (rel:RelatesToNode_ {
 			uuid: $uuid,
 			group_id: $group_id,
@@ -1060,8 +1319,8 @@ func (k *LadybugDriver) executeEdgeCreateQuery(ctx context.Context, edge *types.
 			attributes: $attributes
 		})
 		CREATE (a)-[:RELATES_TO]->(rel)
-		CREATE (rel)-[:RELATES_TO]->(b)
-	`, factEmbeddingValue, episodesValue)
+		%s
+	`, matchTarget, factEmbeddingValue, episodesValue, linkTarget)
 
 	params["source_uuid"] = edge.SourceID
 	params["target_uuid"] = edge.TargetID
@@ -1086,12 +1345,7 @@ func (k *LadybugDriver) executeEdgeCreateQuery(ctx context.Context, edge *types.
 }
 
 func (k *LadybugDriver) executeEdgeUpdateQuery(ctx context.Context, edge *types.Edge) error {
-	var metadataJSON string
-	if edge.Metadata != nil {
-		if data, err := json.Marshal(edge.Metadata); err == nil {
-			metadataJSON = string(data)
-		}
-	}
+	metadataJSON := edgeAttributesJSON(edge)
 
 	// Build query dynamically to handle empty arrays with explicit CASTs
 	var factEmbeddingClause string
@@ -1242,6 +1496,7 @@ func (k *LadybugDriver) DeleteEdge(ctx context.Context, edgeID, groupID string)
 		return fmt.Errorf("failed to delete edge: %w", err)
 	}
 
+	k.onCommit(ctx, func() { k.vectors.remove(ladybugEdgeVectors, groupID, edgeID) })
 	return nil
 }
 
@@ -1255,7 +1510,7 @@ func (k *LadybugDriver) GetEdges(ctx context.Context, edgeIDs []string, groupID
 	query := `
 		MATCH (a:Entity)-[:RELATES_TO]->(rel:RelatesToNode_)-[:RELATES_TO]->(b:Entity)
 		WHERE rel.uuid IN $uuids AND rel.group_id = $group_id
-		RETURN rel.uuid as uuid, rel.name as name, rel.fact as fact, rel.group_id as group_id, a.uuid AS source_id, b.uuid AS target_id
+		RETURN rel.uuid as uuid, rel.name as name, rel.fact as fact, rel.group_id as group_id, rel.attributes as attributes, a.uuid AS source_id, b.uuid AS target_id
 	`
 
 	params := map[string]interface{}{
@@ -1335,11 +1590,27 @@ func (k *LadybugDriver) GetRelatedNodes(ctx context.Context, nodeID, groupID str
 // SearchNodesByEmbedding performs vector similarity search on node embeddings using cosine similarity.
 // This matches the Python implementation in search_utils.py:node_similarity_search()
 // For ladybug, it uses array_cosine_similarity function on name_embedding field.
+// Groups with at least VectorIndexMinSize embeddings are searched through the
+// HNSW index, whose candidates are rescored exactly by the database.
 func (k *LadybugDriver) SearchNodesByEmbedding(ctx context.Context, embedding []float32, groupID string, limit int) ([]*types.Node, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
+	if uuids, ok := k.vectors.candidates(ctx, k, ladybugEntityVectors, groupID, embedding, limit); ok {
+		nodes, err := k.searchNodesByEmbedding(ctx, embedding, groupID, limit, uuids)
+		if err == nil && len(nodes) >= limit {
+			return nodes, nil
+		}
+		// Fall back to an exact scan when candidates were stale or filtered out
+	}
+
+	return k.searchNodesByEmbedding(ctx, embedding, groupID, limit, nil)
+}
+
+// searchNodesByEmbedding scores entity embeddings in the group, restricted to
+// uuids when it is non-nil.
+func (k *LadybugDriver) searchNodesByEmbedding(ctx context.Context, embedding []float32, groupID string, limit int, uuids []string) ([]*types.Node, error) {
 	// Convert float32 embedding to float64 for ladybug parameter
 	embeddingF64 := make([]float64, len(embedding))
 	for i, v := range embedding {
@@ -1351,7 +1622,7 @@ func (k *LadybugDriver) SearchNodesByEmbedding(ctx context.Context, embedding []
 	query := `
 		MATCH (n:Entity)
 		WHERE n.group_id = $group_id
-		  AND size(n.name_embedding) > 0
+		  AND size(n.name_embedding) > 0` + candidateFilter("n", uuids) + `
 		WITH n, array_cosine_similarity(n.name_embedding, CAST($search_vector AS FLOAT[` + fmt.Sprintf("%d", len(embedding)) + `])) AS score
 		WHERE score > 0.0
 		RETURN
@@ -1373,6 +1644,9 @@ func (k *LadybugDriver) SearchNodesByEmbedding(ctx context.Context, embedding []
 		"search_vector": embeddingF64,
 		"limit":         int64(limit),
 	}
+	if uuids != nil {
+		params["uuids"] = uuids
+	}
 
 	result, _, _, err := k.ExecuteQuery(ctx, query, params)
 	if err != nil {
@@ -1440,11 +1714,27 @@ func (k *LadybugDriver) SearchNodesByEmbedding(ctx context.Context, embedding []
 // SearchEdgesByEmbedding performs vector similarity search on edge embeddings using cosine similarity.
 // This matches the Python implementation in search_utils.py:edge_similarity_search()
 // For ladybug, edges are represented as RelatesToNode_ intermediate nodes with fact_embedding field.
+// Groups with at least VectorIndexMinSize embeddings are searched through the
+// HNSW index, whose candidates are rescored exactly by the database.
 func (k *LadybugDriver) SearchEdgesByEmbedding(ctx context.Context, embedding []float32, groupID string, limit int) ([]*types.Edge, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
+	if uuids, ok := k.vectors.candidates(ctx, k, ladybugEdgeVectors, groupID, embedding, limit); ok {
+		edges, err := k.searchEdgesByEmbedding(ctx, embedding, groupID, limit, uuids)
+		if err == nil && len(edges) >= limit {
+			return edges, nil
+		}
+		// Fall back to an exact scan when candidates were stale or filtered out
+	}
+
+	return k.searchEdgesByEmbedding(ctx, embedding, groupID, limit, nil)
+}
+
+// searchEdgesByEmbedding scores edge fact embeddings in the group, restricted
+// to uuids when it is non-nil.
+func (k *LadybugDriver) searchEdgesByEmbedding(ctx context.Context, embedding []float32, groupID string, limit int, uuids []string) ([]*types.Edge, error) {
 	// Convert float32 embedding to float64 for ladybug parameter
 	embeddingF64 := make([]float64, len(embedding))
 	for i, v := range embedding {
@@ -1456,7 +1746,7 @@ func (k *LadybugDriver) SearchEdgesByEmbedding(ctx context.Context, embedding []
 	// Uses RelatesToNode_ intermediate representation
 	query := `
 		MATCH (n:Entity)-[:RELATES_TO]->(e:RelatesToNode_)-[:RELATES_TO]->(m:Entity)
-		WHERE e.group_id = $group_id
+		WHERE e.group_id = $group_id` + candidateFilter("e", uuids) + `
 		WITH DISTINCT e, n, m, array_cosine_similarity(e.fact_embedding, CAST($search_vector AS FLOAT[` + fmt.Sprintf("%d", len(embedding)) + `])) AS score
 		WHERE score > 0.0
 		RETURN
@@ -1470,6 +1760,7 @@ func (k *LadybugDriver) SearchEdgesByEmbedding(ctx context.Context, embedding []
 			e.expired_at AS expired_at,
 			e.valid_at AS valid_at,
 			e.invalid_at AS invalid_at,
+			e.attributes AS attributes,
 			n.uuid AS source_node_uuid,
 			m.uuid AS target_node_uuid,
 			score
@@ -1482,6 +1773,9 @@ func (k *LadybugDriver) SearchEdgesByEmbedding(ctx context.Context, embedding []
 		"search_vector": embeddingF64,
 		"limit":         int64(limit),
 	}
+	if uuids != nil {
+		params["uuids"] = uuids
+	}
 
 	result, _, _, err := k.ExecuteQuery(ctx, query, params)
 	if err != nil {
@@ -1558,6 +1852,7 @@ func (k *LadybugDriver) SearchEdgesByEmbedding(ctx context.Context, embedding []
 			ValidAt:       &validAt,
 			InvalidAt:     &invalidAt,
 		}
+		applyEdgeAttributes(edge, row["attributes"])
 
 		edges = append(edges, edge)
 	}
@@ -1655,6 +1950,7 @@ func (k *LadybugDriver) SearchEdges(ctx context.Context, query, groupID string,
 			e.expired_at AS expired_at,
 			e.valid_at AS valid_at,
 			e.invalid_at AS invalid_at,
+			e.attributes AS attributes,
 			n.uuid AS source_node_uuid,
 			m.uuid AS target_node_uuid,
 			score
@@ -2390,6 +2686,7 @@ func (k *LadybugDriver) mapToEdge(data map[string]interface{}) (*types.Edge, err
 	if embedding, ok := data["fact_embedding"]; ok {
 		edge.FactEmbedding = convertToFloat32Slice(embedding)
 	}
+	applyEdgeAttributes(edge, data["attributes"])
 	if sourceID, ok := data["source_id"]; ok {
 		edge.SourceID = fmt.Sprintf("%v", sourceID)
 		edge.SourceNodeID = fmt.Sprintf("%v", sourceID)
@@ -2415,6 +2712,48 @@ func (k *LadybugDriver) mapToEdge(data map[string]interface{}) (*types.Edge, err
 	return edge, nil
 }
 
+// edgeAttributesJSON encodes edge metadata, together with the confidence
+// and corroboration scores, for the JSON attributes column of RelatesToNode_.
+func edgeAttributesJSON(edge *types.Edge) string {
+	attributes := make(map[string]interface{}, len(edge.Metadata)+2)
+	for k, v := range edge.Metadata {
+		attributes[k] = v
+	}
+	if edge.Confidence > 0 {
+		attributes[types.MetadataKeyConfidence] = edge.Confidence
+	}
+	if edge.Corroboration > 0 {
+		attributes[types.MetadataKeyCorroboration] = edge.Corroboration
+	}
+	if len(attributes) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// applyEdgeAttributes decodes the JSON attributes column of a RelatesToNode_
+// back into edge metadata and scores.
+func applyEdgeAttributes(edge *types.Edge, value interface{}) {
+	attrStr, ok := value.(string)
+	if !ok || attrStr == "" {
+		return
+	}
+	var attributes map[string]interface{}
+	if err := json.Unmarshal([]byte(attrStr), &attributes); err != nil {
+		return
+	}
+	edge.Confidence, edge.Corroboration = types.EdgeScoresFromAttributes(attributes)
+	delete(attributes, types.MetadataKeyConfidence)
+	delete(attributes, types.MetadataKeyCorroboration)
+	if len(attributes) > 0 {
+		edge.Metadata = attributes
+	}
+}
+
 func (k *LadybugDriver) executeNodeCreateQuery(ctx context.Context, node *types.Node, tableName string) error {
 	// Defensive nil check for node
 	if node == nil {
@@ -2751,10 +3090,12 @@ func (s *LadybugDriverSession) Close() error {
 	return nil
 }
 
-// ExecuteWrite executes a write function exactly like Python implementation
+// ExecuteWrite runs fn as a single transaction through the driver's write queue.
+// Driver methods called by fn with the context it receives join the transaction.
 func (s *LadybugDriverSession) ExecuteWrite(ctx context.Context, fn func(context.Context, GraphDriverSession, ...interface{}) (interface{}, error), args ...interface{}) (interface{}, error) {
-	// Directly await the provided function with `self` as the transaction/session (matching Python comment)
-	return fn(ctx, s, args...)
+	return s.driver.executeTransaction(ctx, func(txCtx context.Context) (interface{}, error) {
+		return fn(txCtx, s, args...)
+	})
 }
 
 // Run executes a query or list of queries exactly like Python implementation
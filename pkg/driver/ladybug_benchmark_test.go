@@ -5,6 +5,7 @@ package driver_test
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"path/filepath"
 	"testing"
 	"time"
@@ -221,3 +222,109 @@ func BenchmarkGetEdgesBatch(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkSearchNodesByEmbedding compares the exact embedding scan with the
+// HNSW vector index and reports the index's recall@10 against the exact scan.
+func BenchmarkSearchNodesByEmbedding(b *testing.B) {
+	tempDir := b.TempDir()
+	dbPath := filepath.Join(tempDir, "bench_ladybug_vectors.db")
+
+	const (
+		numNodes   = 5000
+		dimensions = 128
+		numQueries = 50
+		limit      = 10
+	)
+	rng := rand.New(rand.NewSource(42))
+	randomEmbedding := func() []float32 {
+		embedding := make([]float32, dimensions)
+		for i := range embedding {
+			embedding[i] = rng.Float32()*2 - 1
+		}
+		return embedding
+	}
+
+	ctx := context.Background()
+	exact, err := driver.NewLadybugDriverWithConfig(driver.DefaultLadybugDriverConfig().
+		WithDBPath(dbPath).
+		WithVectorIndex(false))
+	if err != nil {
+		b.Fatalf("Failed to create driver: %v", err)
+	}
+
+	// Setup: create entity nodes with random embeddings
+	now := time.Now()
+	for i := 0; i < numNodes; i++ {
+		node := &types.Node{
+			Uuid:          fmt.Sprintf("bench-vector-node-%d", i),
+			Name:          fmt.Sprintf("Vector Node %d", i),
+			Type:          types.EntityNodeType,
+			GroupID:       "bench-group",
+			EntityType:    "BenchEntity",
+			NameEmbedding: randomEmbedding(),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := exact.UpsertNode(ctx, node); err != nil {
+			b.Fatalf("Failed to create node %d: %v", i, err)
+		}
+	}
+
+	queries := make([][]float32, numQueries)
+	expected := make([]map[string]bool, numQueries)
+	for i := range queries {
+		queries[i] = randomEmbedding()
+		nodes, err := exact.SearchNodesByEmbedding(ctx, queries[i], "bench-group", limit)
+		if err != nil {
+			b.Fatalf("SearchNodesByEmbedding failed: %v", err)
+		}
+		expected[i] = make(map[string]bool, len(nodes))
+		for _, node := range nodes {
+			expected[i][node.Uuid] = true
+		}
+	}
+
+	b.Run("Exact_5000nodes", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := exact.SearchNodesByEmbedding(ctx, queries[i%numQueries], "bench-group", limit); err != nil {
+				b.Fatalf("SearchNodesByEmbedding failed: %v", err)
+			}
+		}
+	})
+	exact.Close()
+
+	for _, efSearch := range []int{50, 100, 200} {
+		b.Run(fmt.Sprintf("HNSW_ef%d_5000nodes", efSearch), func(b *testing.B) {
+			d, err := driver.NewLadybugDriverWithConfig(driver.DefaultLadybugDriverConfig().
+				WithDBPath(dbPath).
+				WithVectorIndexParams(16, 200, efSearch))
+			if err != nil {
+				b.Fatalf("Failed to create driver: %v", err)
+			}
+			defer d.Close()
+
+			// The first search builds the index; measure recall before timing
+			hits := 0
+			for i, query := range queries {
+				nodes, err := d.SearchNodesByEmbedding(ctx, query, "bench-group", limit)
+				if err != nil {
+					b.Fatalf("SearchNodesByEmbedding failed: %v", err)
+				}
+				for _, node := range nodes {
+					if expected[i][node.Uuid] {
+						hits++
+					}
+				}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := d.SearchNodesByEmbedding(ctx, queries[i%numQueries], "bench-group", limit); err != nil {
+					b.Fatalf("SearchNodesByEmbedding failed: %v", err)
+				}
+			}
+			b.ReportMetric(float64(hits)/float64(numQueries*limit), "recall@10")
+		})
+	}
+}
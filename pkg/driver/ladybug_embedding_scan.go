@@ -0,0 +1,136 @@
+//go:build cgo
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+var _ EmbeddingScanner = (*LadybugDriver)(nil)
+
+// ScanNodes implements EmbeddingScanner. Ladybug stores the name embedding
+// of entities and communities only; episodes carry no vectors.
+func (k *LadybugDriver) ScanNodes(ctx context.Context, nodeType types.NodeType, afterUUID string, limit int) ([]*types.Node, error) {
+	label, err := scanNodeLabel(nodeType)
+	if err != nil {
+		return nil, err
+	}
+	if nodeType == types.EpisodicNodeType {
+		return nil, nil
+	}
+	query := fmt.Sprintf(`
+		MATCH (n:%s) WHERE n.uuid > $after
+		RETURN n.uuid AS uuid, n.group_id AS group_id, n.name AS name, n.summary AS summary,
+			n.name_embedding AS name_embedding
+		ORDER BY n.uuid LIMIT $limit
+	`, label)
+	result, _, _, err := k.ExecuteQuery(ctx, query, map[string]interface{}{
+		"after": afterUUID,
+		"limit": int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s nodes: %w", label, err)
+	}
+	rows, err := resultRows(result)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*types.Node, 0, len(rows))
+	for _, row := range rows {
+		node := &types.Node{Type: nodeType}
+		node.Uuid, _ = AsString(row["uuid"])
+		node.GroupID, _ = AsString(row["group_id"])
+		node.Name, _ = AsString(row["name"])
+		node.Summary, _ = AsString(row["summary"])
+		node.NameEmbedding = convertToFloat32Slice(row["name_embedding"])
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// ScanEdges implements EmbeddingScanner. Ladybug stores the fact embedding
+// of edges only.
+func (k *LadybugDriver) ScanEdges(ctx context.Context, afterUUID string, limit int) ([]*types.Edge, error) {
+	result, _, _, err := k.ExecuteQuery(ctx, `
+		MATCH (e:RelatesToNode_) WHERE e.uuid > $after
+		RETURN e.uuid AS uuid, e.group_id AS group_id, e.fact AS fact, e.fact_embedding AS fact_embedding
+		ORDER BY e.uuid LIMIT $limit
+	`, map[string]interface{}{
+		"after": afterUUID,
+		"limit": int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan edges: %w", err)
+	}
+	rows, err := resultRows(result)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*types.Edge, 0, len(rows))
+	for _, row := range rows {
+		edge := &types.Edge{Type: types.EntityEdgeType}
+		edge.Uuid, _ = AsString(row["uuid"])
+		edge.GroupID, _ = AsString(row["group_id"])
+		edge.Fact, _ = AsString(row["fact"])
+		edge.FactEmbedding = convertToFloat32Slice(row["fact_embedding"])
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+// SetNodeEmbeddings implements EmbeddingScanner. The HNSW indexes of the
+// entities are dropped and rebuilt from the new vectors when next searched.
+func (k *LadybugDriver) SetNodeEmbeddings(ctx context.Context, nodes []*types.Node) error {
+	for _, node := range nodes {
+		label, err := scanNodeLabel(node.Type)
+		if err != nil {
+			return err
+		}
+		if node.Type == types.EpisodicNodeType {
+			continue
+		}
+		query := fmt.Sprintf(`
+			MATCH (n:%s {uuid: $uuid})
+			SET n.name_embedding = $embedding
+		`, label)
+		if _, _, _, err := k.ExecuteQuery(ctx, query, map[string]interface{}{
+			"uuid":      node.Uuid,
+			"embedding": ladybugEmbedding(node.NameEmbedding),
+		}); err != nil {
+			return fmt.Errorf("failed to set embedding of %s %s: %w", label, node.Uuid, err)
+		}
+	}
+	k.onCommit(ctx, func() { k.vectors.reset(ladybugEntityVectors) })
+	return nil
+}
+
+// SetEdgeEmbeddings implements EmbeddingScanner. The HNSW indexes of the
+// edges are dropped and rebuilt from the new vectors when next searched.
+func (k *LadybugDriver) SetEdgeEmbeddings(ctx context.Context, edges []*types.Edge) error {
+	for _, edge := range edges {
+		if _, _, _, err := k.ExecuteQuery(ctx, `
+			MATCH (e:RelatesToNode_ {uuid: $uuid})
+			SET e.fact_embedding = $embedding
+		`, map[string]interface{}{
+			"uuid":      edge.Uuid,
+			"embedding": ladybugEmbedding(edge.FactEmbedding),
+		}); err != nil {
+			return fmt.Errorf("failed to set embedding of edge %s: %w", edge.Uuid, err)
+		}
+	}
+	k.onCommit(ctx, func() { k.vectors.reset(ladybugEdgeVectors) })
+	return nil
+}
+
+// ladybugEmbedding converts an embedding to the float64 list Ladybug binds
+// to a FLOAT[] column.
+func ladybugEmbedding(embedding []float32) []float64 {
+	converted := make([]float64, len(embedding))
+	for i, v := range embedding {
+		converted[i] = float64(v)
+	}
+	return converted
+}
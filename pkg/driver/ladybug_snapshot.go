@@ -0,0 +1,166 @@
+//go:build cgo
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	ladybug "github.com/LadybugDB/go-ladybug"
+)
+
+// ErrLadybugReadOnly is returned for writes to a driver opened with ReadOnly.
+var ErrLadybugReadOnly = errors.New("ladybug driver is read-only")
+
+// ladybugSystemConfig builds the database settings for config.
+// These are safe, conservative defaults that work with ladybug; the
+// SystemConfig is built manually to avoid version mismatch issues with
+// DefaultSystemConfig().
+func ladybugSystemConfig(config *LadybugDriverConfig) ladybug.SystemConfig {
+	return ladybug.SystemConfig{
+		BufferPoolSize:    config.BufferPoolSize,
+		MaxNumThreads:     uint64(config.MaxConcurrentQueries),
+		EnableCompression: config.EnableCompression,
+		ReadOnly:          config.ReadOnly,
+		MaxDbSize:         config.MaxDbSize,
+	}
+}
+
+// Snapshot writes a consistent copy of the database to destPath, which must
+// not exist yet. Writes are checkpointed into the database file first, and
+// other queries wait until the copy is complete. The copy is made under a
+// temporary name and renamed into place, so a reader never sees a partial
+// snapshot. The HNSW vector index is saved alongside it.
+//
+// Open the snapshot with a ReadOnly driver, or move an existing one to it with
+// SwapSnapshot, to serve queries while this driver keeps writing.
+func (k *LadybugDriver) Snapshot(ctx context.Context, destPath string) error {
+	if k.dbPath == ":memory:" {
+		return fmt.Errorf("cannot snapshot an in-memory database")
+	}
+	if _, ok := transactionFrom(ctx, k); ok {
+		return fmt.Errorf("cannot snapshot inside a transaction")
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("snapshot destination %s already exists", destPath)
+	}
+
+	tmpPath := fmt.Sprintf("%s.tmp-%d", destPath, time.Now().UnixNano())
+	_, err := k.executeExclusive(func() (interface{}, error) {
+		if !k.readOnly {
+			if _, _, _, err := k.executeQueryInternal("CHECKPOINT;", nil); err != nil {
+				return nil, fmt.Errorf("failed to checkpoint database: %w", err)
+			}
+		}
+		if err := copyDir(k.dbPath, tmpPath); err != nil {
+			return nil, fmt.Errorf("failed to copy database: %w", err)
+		}
+		if _, err := os.Stat(k.dbPath + ".wal"); err == nil {
+			if err := copyFile(k.dbPath+".wal", tmpPath+".wal"); err != nil {
+				return nil, fmt.Errorf("failed to copy database WAL: %w", err)
+			}
+		}
+		if err := k.vectors.saveSnapshot(tmpPath); err != nil {
+			log.Printf("Warning: Failed to save vector index with snapshot: %v", err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		removeLadybugFiles(tmpPath)
+		return err
+	}
+
+	for _, suffix := range []string{".wal", ladybugVectorIndexSuffix} {
+		if _, err := os.Stat(tmpPath + suffix); err == nil {
+			if err := os.Rename(tmpPath+suffix, destPath+suffix); err != nil {
+				removeLadybugFiles(tmpPath)
+				return fmt.Errorf("failed to move snapshot into place: %w", err)
+			}
+		}
+	}
+	// The database itself is renamed last so that it only appears complete
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		removeLadybugFiles(tmpPath)
+		removeLadybugFiles(destPath)
+		return fmt.Errorf("failed to move snapshot into place: %w", err)
+	}
+	return nil
+}
+
+// SwapSnapshot moves a ReadOnly driver to the snapshot at path. The snapshot
+// is opened before the swap, queries already running finish on the previous
+// snapshot, and later queries see the new one. The previous snapshot is closed
+// but its files are left in place.
+func (k *LadybugDriver) SwapSnapshot(path string) error {
+	if !k.readOnly {
+		return fmt.Errorf("cannot swap the database of a writable driver; open it with ReadOnly")
+	}
+	k.closeMu.RLock()
+	closed := k.closed
+	k.closeMu.RUnlock()
+	if closed {
+		return fmt.Errorf("driver is closed")
+	}
+
+	database, err := ladybug.OpenDatabase(path, ladybugSystemConfig(k.config))
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	client, err := ladybug.OpenConnection(database)
+	if err != nil {
+		database.Close()
+		return fmt.Errorf("failed to open snapshot connection: %w", err)
+	}
+	if _, err := client.Query("LOAD EXTENSION FTS;"); err != nil && !strings.Contains(err.Error(), "already loaded") {
+		log.Printf("Warning: Failed to load FTS extension on snapshot connection: %v", err)
+	}
+
+	// Wait for running queries, then swap while nothing uses the connection
+	k.txMu.Lock()
+	k.mu.Lock()
+	oldDB, oldClient := k.db, k.client
+	k.db, k.client, k.dbPath = database, client, path
+	k.vectors.retarget(path)
+	k.mu.Unlock()
+	k.txMu.Unlock()
+
+	if oldClient != nil {
+		oldClient.Close()
+	}
+	if oldDB != nil {
+		oldDB.Close()
+	}
+	return nil
+}
+
+// executeExclusive runs fn through the write queue while all other queries
+// and writes wait.
+func (k *LadybugDriver) executeExclusive(fn func() (interface{}, error)) (interface{}, error) {
+	k.closeMu.RLock()
+	if k.closed {
+		k.closeMu.RUnlock()
+		return nil, fmt.Errorf("driver is closed")
+	}
+	k.closeMu.RUnlock()
+
+	resultCh := make(chan writeResult, 1)
+	op := writeOperation{exclusive: fn, resultCh: resultCh}
+
+	if !k.enqueueWrite(op) {
+		return nil, fmt.Errorf("write queue timeout after 5m")
+	}
+	result := <-resultCh
+	return result.result, result.err
+}
+
+// removeLadybugFiles removes a database and its WAL and vector index files.
+func removeLadybugFiles(path string) {
+	for _, p := range []string{path, path + ".wal", path + ladybugVectorIndexSuffix} {
+		os.RemoveAll(p)
+	}
+}
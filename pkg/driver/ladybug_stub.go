@@ -224,3 +224,36 @@ func (k *LadybugDriver) GetEntityNodesByGroup(ctx context.Context, groupID strin
 func (k *LadybugDriver) GetAllGroupIDs(ctx context.Context) ([]string, error) {
 	return nil, ErrCGORequired
 }
+
+// ErrLadybugReadOnly is returned for writes to a driver opened with ReadOnly.
+var ErrLadybugReadOnly = errors.New("ladybug driver is read-only")
+
+// Snapshot returns ErrCGORequired
+func (k *LadybugDriver) Snapshot(ctx context.Context, destPath string) error {
+	return ErrCGORequired
+}
+
+// SwapSnapshot returns ErrCGORequired
+func (k *LadybugDriver) SwapSnapshot(path string) error {
+	return ErrCGORequired
+}
+
+// ScanNodes returns ErrCGORequired
+func (k *LadybugDriver) ScanNodes(ctx context.Context, nodeType types.NodeType, afterUUID string, limit int) ([]*types.Node, error) {
+	return nil, ErrCGORequired
+}
+
+// ScanEdges returns ErrCGORequired
+func (k *LadybugDriver) ScanEdges(ctx context.Context, afterUUID string, limit int) ([]*types.Edge, error) {
+	return nil, ErrCGORequired
+}
+
+// SetNodeEmbeddings returns ErrCGORequired
+func (k *LadybugDriver) SetNodeEmbeddings(ctx context.Context, nodes []*types.Node) error {
+	return ErrCGORequired
+}
+
+// SetEdgeEmbeddings returns ErrCGORequired
+func (k *LadybugDriver) SetEdgeEmbeddings(ctx context.Context, edges []*types.Edge) error {
+	return ErrCGORequired
+}
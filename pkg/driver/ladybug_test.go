@@ -447,3 +447,57 @@ func TestLadybugDriver_UpsertCommunityEdge(t *testing.T) {
 	err = d.UpsertCommunityEdge(ctx, communityNode.Uuid, entityNode.Uuid, edgeUUID, "test-group")
 	require.NoError(t, err, "Second UpsertCommunityEdge should succeed (idempotent)")
 }
+
+func TestLadybugDriver_SnapshotAndSwap(t *testing.T) {
+	dbPath := createTempLadybugDB(t)
+	writer, err := driver.NewLadybugDriver(dbPath, 1)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	ctx := context.Background()
+	require.NoError(t, writer.CreateIndices(ctx))
+
+	upsert := func(uuid string) {
+		now := time.Now()
+		require.NoError(t, writer.UpsertNode(ctx, &types.Node{
+			Uuid:       uuid,
+			Name:       uuid,
+			Type:       types.EntityNodeType,
+			GroupID:    "test-group",
+			EntityType: "Person",
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}))
+	}
+
+	upsert("node-1")
+	firstSnapshot := filepath.Join(t.TempDir(), "snapshot-1.db")
+	require.NoError(t, writer.Snapshot(ctx, firstSnapshot))
+	assert.Error(t, writer.Snapshot(ctx, firstSnapshot), "Snapshot should refuse an existing destination")
+
+	reader, err := driver.NewLadybugDriverWithConfig(driver.DefaultLadybugDriverConfig().
+		WithDBPath(firstSnapshot).
+		WithReadOnly(true))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	node, err := reader.GetNode(ctx, "node-1", "test-group")
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", node.Uuid)
+	assert.ErrorIs(t, reader.UpsertNode(ctx, node), driver.ErrLadybugReadOnly)
+
+	// Writes after the snapshot are only visible once the reader swaps
+	upsert("node-2")
+	_, err = reader.GetNode(ctx, "node-2", "test-group")
+	assert.Error(t, err, "node-2 should not be in the first snapshot")
+
+	secondSnapshot := filepath.Join(t.TempDir(), "snapshot-2.db")
+	require.NoError(t, writer.Snapshot(ctx, secondSnapshot))
+	require.NoError(t, reader.SwapSnapshot(secondSnapshot))
+
+	node, err = reader.GetNode(ctx, "node-2", "test-group")
+	require.NoError(t, err)
+	assert.Equal(t, "node-2", node.Uuid)
+
+	assert.Error(t, writer.SwapSnapshot(secondSnapshot), "SwapSnapshot should require a read-only driver")
+}
@@ -0,0 +1,375 @@
+//go:build cgo
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/soundprediction/predicato/pkg/hnsw"
+)
+
+// ladybugVectorKind selects the embeddings covered by a Ladybug vector index.
+type ladybugVectorKind int
+
+const (
+	ladybugEntityVectors ladybugVectorKind = iota
+	ladybugEdgeVectors
+)
+
+// ladybugVectorIndexSuffix is appended to the database path to name the file
+// the vector indexes are persisted to.
+const ladybugVectorIndexSuffix = ".hnsw"
+
+// ladybugVectorQueries returns the queries loading and counting the
+// embeddings of one group for kind.
+func ladybugVectorQueries(kind ladybugVectorKind) (load, count string) {
+	if kind == ladybugEdgeVectors {
+		return `
+			MATCH (e:RelatesToNode_)
+			WHERE e.group_id = $group_id AND size(e.fact_embedding) > 0
+			RETURN e.uuid AS uuid, e.fact_embedding AS embedding
+		`, `
+			MATCH (e:RelatesToNode_)
+			WHERE e.group_id = $group_id AND size(e.fact_embedding) > 0
+			RETURN count(e) AS count
+		`
+	}
+	return `
+		MATCH (n:Entity)
+		WHERE n.group_id = $group_id AND size(n.name_embedding) > 0
+		RETURN n.uuid AS uuid, n.name_embedding AS embedding
+	`, `
+		MATCH (n:Entity)
+		WHERE n.group_id = $group_id AND size(n.name_embedding) > 0
+		RETURN count(n) AS count
+	`
+}
+
+// candidateFilter returns the WHERE clause restricting variable to the
+// $uuids candidates, or nothing when uuids is nil.
+func candidateFilter(variable string, uuids []string) string {
+	if uuids == nil {
+		return ""
+	}
+	return "\n\t\t  AND " + variable + ".uuid IN $uuids"
+}
+
+// ladybugVectorIndex keeps per-group HNSW indexes over entity name embeddings
+// and edge fact embeddings. A group's index is checked against the database
+// the first time it is searched and rebuilt if the embedding counts differ;
+// after that it is kept in sync by upserts and deletes. Searches use it only
+// to pick candidates, which are rescored exactly by the database, so entries
+// left stale by other write paths cost recall but never return wrong data.
+type ladybugVectorIndex struct {
+	mu       sync.Mutex
+	cfg      hnsw.Config
+	minSize  int
+	path     string
+	readOnly bool
+	loaded   bool
+	gen      int
+	indexes  [2]map[string]*hnsw.Index
+	synced   [2]map[string]bool
+	dirty    bool
+}
+
+// persistedVectorIndexes is the on-disk form of a ladybugVectorIndex.
+type persistedVectorIndexes struct {
+	Config hnsw.Config
+	Groups [2]map[string][]byte
+}
+
+// newLadybugVectorIndex creates the vector index for a driver, or returns nil
+// when it is disabled. The indexes are persisted to dbPath+".hnsw" unless
+// dbPath is empty; read-only drivers load that file but never write it.
+func newLadybugVectorIndex(config *LadybugDriverConfig, dbPath string) *ladybugVectorIndex {
+	if config.DisableVectorIndex {
+		return nil
+	}
+	v := &ladybugVectorIndex{
+		cfg: hnsw.Config{
+			M:              config.VectorIndexM,
+			EfConstruction: config.VectorIndexEfConstruction,
+			EfSearch:       config.VectorIndexEfSearch,
+		},
+		minSize:  config.VectorIndexMinSize,
+		readOnly: config.ReadOnly,
+	}
+	v.retarget(dbPath)
+	return v
+}
+
+// retarget drops all indexes and points the index at the database at dbPath.
+// Group builds still running against the previous database are discarded.
+func (v *ladybugVectorIndex) retarget(dbPath string) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.path = ""
+	if dbPath != "" {
+		v.path = dbPath + ladybugVectorIndexSuffix
+	}
+	for kind := range v.indexes {
+		v.indexes[kind] = make(map[string]*hnsw.Index)
+		v.synced[kind] = make(map[string]bool)
+	}
+	v.loaded = false
+	v.dirty = false
+	v.gen++
+}
+
+// ensureLoaded reads the persisted indexes the first time they are needed.
+// The caller must hold v.mu.
+func (v *ladybugVectorIndex) ensureLoaded() {
+	if !v.loaded {
+		v.loaded = true
+		v.load()
+	}
+}
+
+// load reads the persisted indexes, if any. Indexes built with a different
+// M or EfConstruction are discarded and rebuilt.
+func (v *ladybugVectorIndex) load() {
+	if v.path == "" {
+		return
+	}
+	file, err := os.Open(v.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Warning: Failed to open vector index at %s: %v", v.path, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	var persisted persistedVectorIndexes
+	if err := gob.NewDecoder(file).Decode(&persisted); err != nil {
+		log.Printf("Warning: Failed to read vector index at %s, it will be rebuilt: %v", v.path, err)
+		return
+	}
+	if persisted.Config.M != v.cfg.M || persisted.Config.EfConstruction != v.cfg.EfConstruction {
+		return
+	}
+	for kind, groups := range persisted.Groups {
+		for groupID, data := range groups {
+			idx, err := hnsw.Load(bytes.NewReader(data))
+			if err != nil {
+				log.Printf("Warning: Failed to load vector index for group %s, it will be rebuilt: %v", groupID, err)
+				continue
+			}
+			v.indexes[kind][groupID] = idx
+		}
+	}
+}
+
+// candidates returns the uuids of the embeddings nearest to embedding in the
+// group, or false when the group is too small for the index to pay off or the
+// index cannot answer the query.
+func (v *ladybugVectorIndex) candidates(ctx context.Context, k *LadybugDriver, kind ladybugVectorKind, groupID string, embedding []float32, limit int) ([]string, bool) {
+	if v == nil || len(embedding) == 0 {
+		return nil, false
+	}
+	idx, err := v.group(ctx, k, kind, groupID)
+	if err != nil {
+		log.Printf("Warning: Failed to build vector index for group %s: %v", groupID, err)
+		return nil, false
+	}
+	if idx == nil || idx.Len() < v.minSize || idx.Dimensions() != len(embedding) {
+		return nil, false
+	}
+
+	n := vectorCandidates(limit)
+	results := idx.Search(embedding, n, max(n, v.cfg.EfSearch))
+	uuids := make([]string, len(results))
+	for i, r := range results {
+		uuids[i] = r.ID
+	}
+	return uuids, len(uuids) > 0
+}
+
+// group returns the index for a group, building it from the database the
+// first time the group is searched in this process.
+func (v *ladybugVectorIndex) group(ctx context.Context, k *LadybugDriver, kind ladybugVectorKind, groupID string) (*hnsw.Index, error) {
+	v.mu.Lock()
+	v.ensureLoaded()
+	idx, synced, gen := v.indexes[kind][groupID], v.synced[kind][groupID], v.gen
+	v.mu.Unlock()
+	if synced {
+		return idx, nil
+	}
+
+	// The database is queried without holding the lock, because writers in
+	// an open transaction update the index while holding the connection.
+	loadQuery, countQuery := ladybugVectorQueries(kind)
+	params := map[string]interface{}{"group_id": groupID}
+	if idx != nil {
+		result, _, _, err := k.ExecuteQuery(ctx, countQuery, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count embeddings: %w", err)
+		}
+		if rows, ok := result.([]map[string]interface{}); ok && len(rows) > 0 {
+			if count, ok := rows[0]["count"].(int64); ok && int(count) == idx.Len() {
+				v.markSynced(kind, groupID, idx, gen, false)
+				return idx, nil
+			}
+		}
+	}
+
+	result, _, _, err := k.ExecuteQuery(ctx, loadQuery, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+	idx = hnsw.New(v.cfg)
+	rows, _ := result.([]map[string]interface{})
+	for _, row := range rows {
+		uuid, _ := row["uuid"].(string)
+		embedding := convertToFloat32Slice(row["embedding"])
+		if uuid == "" || len(embedding) == 0 {
+			continue
+		}
+		if err := idx.Add(uuid, embedding); err != nil {
+			log.Printf("Warning: Skipping embedding of %s in vector index: %v", uuid, err)
+		}
+	}
+	v.markSynced(kind, groupID, idx, gen, true)
+	return idx, nil
+}
+
+// markSynced installs idx as the group's index unless the index has been
+// retargeted since generation gen.
+func (v *ladybugVectorIndex) markSynced(kind ladybugVectorKind, groupID string, idx *hnsw.Index, gen int, rebuilt bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if gen != v.gen {
+		return
+	}
+	v.indexes[kind][groupID] = idx
+	v.synced[kind][groupID] = true
+	if rebuilt {
+		v.dirty = true
+	}
+}
+
+// add records an upserted embedding. Groups without an index are skipped;
+// their index is built from the database when first searched.
+func (v *ladybugVectorIndex) add(kind ladybugVectorKind, groupID, uuid string, embedding []float32) {
+	if v == nil || len(embedding) == 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.ensureLoaded()
+	idx := v.indexes[kind][groupID]
+	if idx == nil {
+		return
+	}
+	if err := idx.Add(uuid, embedding); err != nil {
+		log.Printf("Warning: Failed to add %s to vector index: %v", uuid, err)
+		return
+	}
+	v.dirty = true
+}
+
+// remove drops a deleted node or edge from the index of its group.
+func (v *ladybugVectorIndex) remove(kind ladybugVectorKind, groupID, uuid string) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.ensureLoaded()
+	if idx := v.indexes[kind][groupID]; idx != nil && idx.Remove(uuid) {
+		v.dirty = true
+	}
+}
+
+// reset drops the indexes of kind in every group, for example after their
+// embeddings were replaced by another model. They are rebuilt from the
+// database when next searched.
+func (v *ladybugVectorIndex) reset(kind ladybugVectorKind) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.ensureLoaded()
+	v.indexes[kind] = make(map[string]*hnsw.Index)
+	v.synced[kind] = make(map[string]bool)
+	v.gen++
+	v.dirty = true
+}
+
+// save persists the indexes next to the database if they have changed.
+func (v *ladybugVectorIndex) save() error {
+	if v == nil || v.readOnly {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.path == "" || !v.dirty {
+		return nil
+	}
+	if err := v.writeFile(v.path); err != nil {
+		return err
+	}
+	v.dirty = false
+	return nil
+}
+
+// saveSnapshot writes the indexes next to a snapshot of the database at
+// dbPath, so readers of the snapshot do not have to rebuild them.
+func (v *ladybugVectorIndex) saveSnapshot(dbPath string) error {
+	if v == nil {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.ensureLoaded()
+	return v.writeFile(dbPath + ladybugVectorIndexSuffix)
+}
+
+// writeFile writes the indexes to path through a temporary file. The caller
+// must hold v.mu.
+func (v *ladybugVectorIndex) writeFile(path string) error {
+	persisted := persistedVectorIndexes{Config: v.cfg}
+	for kind, groups := range v.indexes {
+		persisted.Groups[kind] = make(map[string][]byte, len(groups))
+		for groupID, idx := range groups {
+			var buf bytes.Buffer
+			if err := idx.Save(&buf); err != nil {
+				return fmt.Errorf("failed to save vector index for group %s: %w", groupID, err)
+			}
+			persisted.Groups[kind][groupID] = buf.Bytes()
+		}
+	}
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create vector index file: %w", err)
+	}
+	if err := gob.NewEncoder(file).Encode(&persisted); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write vector index: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write vector index: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace vector index: %w", err)
+	}
+	return nil
+}
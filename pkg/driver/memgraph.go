@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/db"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
 	"github.com/soundprediction/predicato/pkg/types"
 )
@@ -21,6 +22,7 @@ import (
 type MemgraphDriver struct {
 	client   neo4j.DriverWithContext
 	database string
+	indexes  nativeIndexState
 }
 
 // NewMemgraphDriver creates a new Memgraph driver instance.
@@ -42,10 +44,7 @@ func NewMemgraphDriver(uri, username, password, database string) (*MemgraphDrive
 
 // GetNode retrieves a node by ID.
 func (m *MemgraphDriver) GetNode(ctx context.Context, nodeID, groupID string) (*types.Node, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {uuid: $nodeID, group_id: $groupID})
 			RETURN n
@@ -95,10 +94,7 @@ func (m *MemgraphDriver) NodeExists(ctx context.Context, node *types.Node) bool
 		return false
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {uuid: $uuid, group_id: $group_id})
 			RETURN n.uuid
@@ -152,10 +148,7 @@ func (m *MemgraphDriver) UpsertNode(ctx context.Context, node *types.Node) error
 		node.ValidFrom = node.CreatedAt
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Get base label for node type
 		baseLabel := m.getLabelForNodeType(node.Type)
 
@@ -193,10 +186,7 @@ func (m *MemgraphDriver) UpsertNode(ctx context.Context, node *types.Node) error
 
 // DeleteNode removes a node and its edges.
 func (m *MemgraphDriver) DeleteNode(ctx context.Context, nodeID, groupID string) error {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {uuid: $nodeID, group_id: $groupID})
 			DETACH DELETE n
@@ -217,10 +207,7 @@ func (m *MemgraphDriver) GetNodes(ctx context.Context, nodeIDs []string, groupID
 		return []*types.Node{}, nil
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {group_id: $groupID})
 			WHERE n.uuid IN $nodeIDs
@@ -264,10 +251,7 @@ func (m *MemgraphDriver) GetNodes(ctx context.Context, nodeIDs []string, groupID
 
 // GetEdge retrieves an edge by ID.
 func (m *MemgraphDriver) GetEdge(ctx context.Context, edgeID, groupID string) (*types.Edge, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {uuid: $edgeID, group_id: $groupID}]->(t)
 			RETURN r, s.uuid as source_id, t.uuid as target_id
@@ -329,10 +313,7 @@ func (m *MemgraphDriver) EdgeExists(ctx context.Context, edge *types.Edge) bool
 		return false
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH ()-[r {uuid: $uuid, group_id: $group_id}]-()
 			RETURN r.uuid
@@ -371,10 +352,7 @@ func (m *MemgraphDriver) UpsertEdge(ctx context.Context, edge *types.Edge) error
 		edge.ValidFrom = edge.CreatedAt
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s {uuid: $source_id, group_id: $group_id})
 			MATCH (t {uuid: $target_id, group_id: $group_id})
@@ -403,10 +381,7 @@ func (m *MemgraphDriver) UpsertEdge(ctx context.Context, edge *types.Edge) error
 // UpsertEpisodicEdge creates or updates a MENTIONS relationship between an Episodic node and an Entity node.
 // This matches Python's EpisodicEdge.save() method.
 func (m *MemgraphDriver) UpsertEpisodicEdge(ctx context.Context, episodeUUID, entityUUID, groupID string) error {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Python EPISODIC_EDGE_SAVE uses MERGE for idempotent upserts
 		query := `
 			MATCH (episode:Episodic {uuid: $episode_uuid, group_id: $group_id})
@@ -433,10 +408,7 @@ func (m *MemgraphDriver) UpsertEpisodicEdge(ctx context.Context, episodeUUID, en
 // UpsertCommunityEdge creates or updates a HAS_MEMBER relationship between a Community node and an Entity or Community node.
 // This matches Python's CommunityEdge.save() method.
 func (m *MemgraphDriver) UpsertCommunityEdge(ctx context.Context, communityUUID, nodeUUID, uuid, groupID string) error {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Python uses (node:Entity | Community) syntax to match either type
 		// Neo4j/Memgraph support this label alternative syntax
 		query := `
@@ -465,10 +437,7 @@ func (m *MemgraphDriver) UpsertCommunityEdge(ctx context.Context, communityUUID,
 
 // DeleteEdge removes an edge.
 func (m *MemgraphDriver) DeleteEdge(ctx context.Context, edgeID, groupID string) error {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH ()-[r {uuid: $edgeID, group_id: $groupID}]-()
 			DELETE r
@@ -489,10 +458,7 @@ func (m *MemgraphDriver) GetEdges(ctx context.Context, edgeIDs []string, groupID
 		return []*types.Edge{}, nil
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
 			WHERE r.uuid IN $edgeIDs
@@ -547,10 +513,7 @@ func (m *MemgraphDriver) GetEdges(ctx context.Context, edgeIDs []string, groupID
 
 // GetNeighbors retrieves neighboring nodes within a specified distance
 func (m *MemgraphDriver) GetNeighbors(ctx context.Context, nodeID, groupID string, maxDistance int) ([]*types.Node, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := fmt.Sprintf(`
 			MATCH (start {uuid: $nodeID, group_id: $groupID})
 			MATCH (start)-[*1..%d]-(neighbor)
@@ -595,10 +558,7 @@ func (m *MemgraphDriver) GetNeighbors(ctx context.Context, nodeID, groupID strin
 }
 
 func (m *MemgraphDriver) GetRelatedNodes(ctx context.Context, nodeID, groupID string, edgeTypes []types.EdgeType) ([]*types.Node, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		var query string
 		params := map[string]any{
 			"nodeID":  nodeID,
@@ -669,11 +629,20 @@ func (m *MemgraphDriver) SearchNodesByEmbedding(ctx context.Context, embedding [
 		return []*types.Node{}, nil
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
+	if m.indexes.useVectorIndex(embedding, limit) {
+		// The index ranks every group, so the group's matches may fall
+		// outside its top k; the exact scan below answers short results.
+		nodes, err := m.searchNodesByVectorIndex(ctx, embedding, groupID, limit)
+		if err == nil && len(nodes) >= limit {
+			return nodes, nil
+		}
+		if err != nil && !fallBack(err, &m.indexes.vectorMissing) {
+			return nil, err
+		}
+	}
 
 	// Get all nodes with embeddings and compute similarity in-memory
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {group_id: $groupID})
 			WHERE n.embedding IS NOT NULL
@@ -752,11 +721,20 @@ func (m *MemgraphDriver) SearchEdgesByEmbedding(ctx context.Context, embedding [
 		return []*types.Edge{}, nil
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
+	if m.indexes.useVectorIndex(embedding, limit) {
+		// The index ranks every group, so the group's matches may fall
+		// outside its top k; the exact scan below answers short results.
+		edges, err := m.searchEdgesByVectorIndex(ctx, embedding, groupID, limit)
+		if err == nil && len(edges) >= limit {
+			return edges, nil
+		}
+		if err != nil && !fallBack(err, &m.indexes.vectorMissing) {
+			return nil, err
+		}
+	}
 
 	// Get all edges with embeddings and compute similarity in-memory
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
 			WHERE r.embedding IS NOT NULL
@@ -845,13 +823,10 @@ func (m *MemgraphDriver) UpsertNodes(ctx context.Context, nodes []*types.Node) e
 		return nil
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
 	// Use UNWIND for efficient bulk operations matching Python's approach
 	// Note: Dynamic labels for Entity nodes need to be handled in individual upserts
 	// because Cypher doesn't support parameterized labels in UNWIND context without APOC
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Build node data array
 		nodeDataList := make([]map[string]any, 0, len(nodes))
 		for _, node := range nodes {
@@ -880,22 +855,25 @@ func (m *MemgraphDriver) UpsertNodes(ctx context.Context, nodes []*types.Node) e
 			return nil, fmt.Errorf("failed to bulk upsert nodes: %w", err)
 		}
 
-		// Handle dynamic labels for Entity nodes separately
+		// Handle labels separately: the base label is needed by the native
+		// indexes, and Entity nodes also get a dynamic label for their type.
 		// This is a tradeoff: bulk insert is fast, but labels need individual updates
 		for _, node := range nodes {
+			labels := m.getLabelForNodeType(node.Type)
 			if node.Type == types.EntityNodeType && node.EntityType != "" {
-				labelQuery := fmt.Sprintf(`
-					MATCH (n {uuid: $uuid, group_id: $group_id})
-					SET n:%s
-				`, node.EntityType)
-
-				_, err := tx.Run(ctx, labelQuery, map[string]any{
-					"uuid":     node.Uuid,
-					"group_id": node.GroupID,
-				})
-				if err != nil {
-					return nil, fmt.Errorf("failed to set label for node %s: %w", node.Uuid, err)
-				}
+				labels += ":" + node.EntityType
+			}
+			labelQuery := fmt.Sprintf(`
+				MATCH (n {uuid: $uuid, group_id: $group_id})
+				SET n:%s
+			`, labels)
+
+			_, err := tx.Run(ctx, labelQuery, map[string]any{
+				"uuid":     node.Uuid,
+				"group_id": node.GroupID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to set label for node %s: %w", node.Uuid, err)
 			}
 		}
 
@@ -910,11 +888,8 @@ func (m *MemgraphDriver) UpsertEdges(ctx context.Context, edges []*types.Edge) e
 		return nil
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
 	// Use UNWIND for efficient bulk operations matching Python's approach
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Build edge data array
 		edgeDataList := make([]map[string]any, 0, len(edges))
 		for _, edge := range edges {
@@ -954,10 +929,7 @@ func (m *MemgraphDriver) UpsertEdges(ctx context.Context, edges []*types.Edge) e
 }
 
 func (m *MemgraphDriver) GetNodesInTimeRange(ctx context.Context, start, end time.Time, groupID string) ([]*types.Node, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {group_id: $groupID})
 			WHERE n.created_at >= $start AND n.created_at <= $end
@@ -1001,10 +973,7 @@ func (m *MemgraphDriver) GetNodesInTimeRange(ctx context.Context, start, end tim
 }
 
 func (m *MemgraphDriver) GetEdgesInTimeRange(ctx context.Context, start, end time.Time, groupID string) ([]*types.Edge, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
 			WHERE r.created_at >= $start AND r.created_at <= $end
@@ -1071,10 +1040,7 @@ func (m *MemgraphDriver) RetrieveEpisodes(
 		limit = 10
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Build query parameters
 		queryParams := make(map[string]any)
 		// Use neo4j.LocalDateTime type which Memgraph understands natively
@@ -1146,10 +1112,7 @@ func (m *MemgraphDriver) RetrieveEpisodes(
 
 func (m *MemgraphDriver) GetCommunities(ctx context.Context, groupID string, level int) ([]*types.Node, error) {
 	// For basic implementation, return nodes grouped by a hypothetical community property
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n:Community {group_id: $groupID})
 			WHERE n.community_level = $level
@@ -1193,10 +1156,7 @@ func (m *MemgraphDriver) GetCommunities(ctx context.Context, groupID string, lev
 
 func (m *MemgraphDriver) BuildCommunities(ctx context.Context, groupID string) error {
 	// Basic implementation that assigns community IDs based on connected components
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Reset existing community assignments
 		resetQuery := `
 			MATCH (n:Community {group_id: $groupID})
@@ -1352,10 +1312,7 @@ func (m *MemgraphDriver) parseCommunityNodesFromRecords(result interface{}) ([]*
 // RemoveCommunities removes all community nodes and their relationships from the graph.
 // Memgraph-specific implementation using DETACH DELETE.
 func (m *MemgraphDriver) RemoveCommunities(ctx context.Context) error {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := m.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := "MATCH (c:Community) DETACH DELETE c"
 		_, err := tx.Run(ctx, query, nil)
 		return nil, err
@@ -1393,14 +1350,309 @@ func (m *MemgraphDriver) CreateIndices(ctx context.Context) error {
 		}
 	}
 
+	if err := backfillEmbeddingVectors(ctx, &m.indexes, m.executeRead, m.executeWrite); err != nil {
+		return err
+	}
+
+	// Text indexes need Memgraph's text search feature and vector indexes
+	// need Memgraph 3.x; without them search falls back to CONTAINS matching
+	// and in-memory cosine similarity.
+	m.indexes.reset()
+	for _, native := range m.nativeIndexQueries() {
+		if _, err := session.Run(ctx, native.query, nil); err != nil && !isIndexExistsError(err) {
+			log.Printf("Warning: native index unavailable, using fallback search: %v", err)
+			m.indexes.markMissing(native.vector)
+		}
+	}
+
 	return nil
 }
 
-func (m *MemgraphDriver) GetStats(ctx context.Context, groupID string) (*GraphStats, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
+// memgraphVectorIndexCapacity is the initial capacity of Memgraph vector indexes.
+const memgraphVectorIndexCapacity = 100000
+
+// memgraphNodeTextIndexes maps node labels to their text index names. Memgraph
+// text indexes cover a single label.
+var memgraphNodeTextIndexes = []struct {
+	label string
+	index string
+}{
+	{"Entity", "entity_text"},
+	{"Episodic", "episodic_text"},
+	{"Community", "community_text"},
+}
+
+// SetEmbeddingDimensions sets the dimension of the native vector indexes
+// created by CreateIndices. Vector indexes are skipped while it is 0.
+func (m *MemgraphDriver) SetEmbeddingDimensions(dims int) {
+	m.indexes.setEmbeddingDimensions(dims)
+}
+
+// nativeIndexQueries returns the statements creating the vector and text
+// indexes used by the search methods.
+func (m *MemgraphDriver) nativeIndexQueries() []nativeIndexQuery {
+	var queries []nativeIndexQuery
+	for _, idx := range memgraphNodeTextIndexes {
+		queries = append(queries, nativeIndexQuery{
+			query: fmt.Sprintf("CREATE TEXT INDEX %s ON :%s(name, summary, content)", idx.index, idx.label),
+		})
+	}
+	queries = append(queries, nativeIndexQuery{
+		query: fmt.Sprintf("CREATE TEXT EDGE INDEX %s ON :RELATES_TO(name, summary, fact)", edgeTextIndex),
+	})
+
+	dims := m.indexes.embeddingDimensions()
+	if dims <= 0 {
+		return queries
+	}
+
+	config := fmt.Sprintf(`WITH CONFIG {"dimension": %d, "capacity": %d, "metric": "cos"}`, dims, memgraphVectorIndexCapacity)
+	for _, idx := range nodeEmbeddingIndexes {
+		queries = append(queries, nativeIndexQuery{
+			query:  fmt.Sprintf("CREATE VECTOR INDEX %s ON :%s(%s) %s", idx.index, idx.label, embeddingVectorProperty, config),
+			vector: true,
+		})
+	}
+	queries = append(queries, nativeIndexQuery{
+		query:  fmt.Sprintf("CREATE VECTOR EDGE INDEX %s ON :RELATES_TO(%s) %s", edgeEmbeddingIndex, embeddingVectorProperty, config),
+		vector: true,
+	})
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	return queries
+}
+
+// searchNodesByVectorIndex queries the per-label vector indexes and merges
+// the hits by cosine similarity.
+func (m *MemgraphDriver) searchNodesByVectorIndex(ctx context.Context, embedding []float32, groupID string, limit int) ([]*types.Node, error) {
+	type scoredNode struct {
+		node  *types.Node
+		score float64
+	}
+	var candidates []scoredNode
+
+	for _, idx := range nodeEmbeddingIndexes {
+		result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			query := `
+				CALL vector_search.search($index, $k, $vector) YIELD node, similarity
+				WITH node, similarity
+				WHERE node.group_id = $groupID
+				RETURN node AS n, similarity AS score
+				ORDER BY score DESC
+				LIMIT $limit
+			`
+			res, err := tx.Run(ctx, query, map[string]any{
+				"index":   idx.index,
+				"k":       vectorCandidates(limit),
+				"vector":  toFloat64Slice(embedding),
+				"groupID": groupID,
+				"limit":   limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return res.Collect(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		records, ok := AsRecordSlice(result)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result type: got %T, expected []*db.Record", result)
+		}
+
+		for _, record := range records {
+			nodeValue, _ := record.Get("n")
+			dbNode, ok := AsDBNode(nodeValue)
+			if !ok {
+				continue
+			}
+			scoreValue, _ := record.Get("score")
+			score, _ := AsFloat64(scoreValue)
+			candidates = append(candidates, scoredNode{node: m.nodeFromDBNode(dbNode), score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	nodes := make([]*types.Node, len(candidates))
+	for i, candidate := range candidates {
+		nodes[i] = candidate.node
+	}
+	return nodes, nil
+}
+
+// searchEdgesByVectorIndex queries the RELATES_TO vector edge index.
+func (m *MemgraphDriver) searchEdgesByVectorIndex(ctx context.Context, embedding []float32, groupID string, limit int) ([]*types.Edge, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		query := `
+			CALL vector_search.search_edges($index, $k, $vector) YIELD edge, similarity
+			WITH edge, similarity
+			WHERE edge.group_id = $groupID
+			RETURN edge AS r, startNode(edge).uuid AS source_id, endNode(edge).uuid AS target_id
+			ORDER BY similarity DESC
+			LIMIT $limit
+		`
+		res, err := tx.Run(ctx, query, map[string]any{
+			"index":   edgeEmbeddingIndex,
+			"k":       vectorCandidates(limit),
+			"vector":  toFloat64Slice(embedding),
+			"groupID": groupID,
+			"limit":   limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Collect(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	records, ok := AsRecordSlice(result)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: got %T, expected []*db.Record", result)
+	}
+
+	return m.edgesFromRecords(records), nil
+}
+
+// searchNodesByTextIndex runs a BM25 query against the per-label text indexes
+// and merges the hits by score.
+func (m *MemgraphDriver) searchNodesByTextIndex(ctx context.Context, query, groupID string, limit int) ([]*types.Node, error) {
+	textQuery := escapeLuceneQuery(query)
+	if textQuery == "" {
+		return []*types.Node{}, nil
+	}
+
+	type scoredNode struct {
+		node  *types.Node
+		score float64
+	}
+	var candidates []scoredNode
+
+	for _, idx := range memgraphNodeTextIndexes {
+		result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			searchQuery := `
+				CALL text_search.search_all($index, $query) YIELD node, score
+				WITH node, score
+				WHERE node.group_id = $groupID
+				RETURN node AS n, score
+				ORDER BY score DESC
+				LIMIT $limit
+			`
+			res, err := tx.Run(ctx, searchQuery, map[string]any{
+				"index":   idx.index,
+				"query":   textQuery,
+				"groupID": groupID,
+				"limit":   limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return res.Collect(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		records, ok := AsRecordSlice(result)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result type: got %T, expected []*db.Record", result)
+		}
+
+		for _, record := range records {
+			nodeValue, _ := record.Get("n")
+			dbNode, ok := AsDBNode(nodeValue)
+			if !ok {
+				continue
+			}
+			scoreValue, _ := record.Get("score")
+			score, _ := AsFloat64(scoreValue)
+			candidates = append(candidates, scoredNode{node: m.nodeFromDBNode(dbNode), score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	nodes := make([]*types.Node, len(candidates))
+	for i, candidate := range candidates {
+		nodes[i] = candidate.node
+	}
+	return nodes, nil
+}
+
+// searchEdgesByTextIndex runs a BM25 query against the RELATES_TO text edge index.
+func (m *MemgraphDriver) searchEdgesByTextIndex(ctx context.Context, query, groupID string, limit int) ([]*types.Edge, error) {
+	textQuery := escapeLuceneQuery(query)
+	if textQuery == "" {
+		return []*types.Edge{}, nil
+	}
+
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		searchQuery := `
+			CALL text_search.search_all_edges($index, $query) YIELD edge, score
+			WITH edge, score
+			WHERE edge.group_id = $groupID
+			RETURN edge AS r, startNode(edge).uuid AS source_id, endNode(edge).uuid AS target_id
+			ORDER BY score DESC
+			LIMIT $limit
+		`
+		res, err := tx.Run(ctx, searchQuery, map[string]any{
+			"index":   edgeTextIndex,
+			"query":   textQuery,
+			"groupID": groupID,
+			"limit":   limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Collect(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	records, ok := AsRecordSlice(result)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: got %T, expected []*db.Record", result)
+	}
+
+	return m.edgesFromRecords(records), nil
+}
+
+// edgesFromRecords converts records holding r, source_id and target_id into edges.
+func (m *MemgraphDriver) edgesFromRecords(records []*db.Record) []*types.Edge {
+	edges := make([]*types.Edge, 0, len(records))
+	for _, record := range records {
+		relationValue, _ := record.Get("r")
+		relation, ok := AsDBRelationship(relationValue)
+		if !ok {
+			continue
+		}
+		sourceIDValue, _ := record.Get("source_id")
+		targetIDValue, _ := record.Get("target_id")
+		sourceID, ok := AsString(sourceIDValue)
+		if !ok {
+			continue
+		}
+		targetID, ok := AsString(targetIDValue)
+		if !ok {
+			continue
+		}
+		edges = append(edges, m.edgeFromDBRelation(relation, sourceID, targetID))
+	}
+	return edges
+}
+
+func (m *MemgraphDriver) GetStats(ctx context.Context, groupID string) (*GraphStats, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Get node count by label (Entity, Episodic, Community)
 		// Note: In Neo4j/Memgraph, node types are labels, not properties
 		nodeQuery := `
@@ -1535,10 +1787,17 @@ func (m *MemgraphDriver) SearchNodes(ctx context.Context, query, groupID string,
 		limit = options.Limit
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
+	if (options == nil || !options.ExactMatch) && m.indexes.useTextIndex() {
+		nodes, err := m.searchNodesByTextIndex(ctx, query, groupID, limit)
+		if err == nil {
+			return nodes, nil
+		}
+		if !fallBack(err, &m.indexes.textMissing) {
+			return nil, err
+		}
+	}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		var searchQuery string
 		queryParams := map[string]any{
 			"groupID": groupID,
@@ -1608,10 +1867,17 @@ func (m *MemgraphDriver) SearchEdges(ctx context.Context, query, groupID string,
 		limit = options.Limit
 	}
 
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
+	if m.indexes.useTextIndex() {
+		edges, err := m.searchEdgesByTextIndex(ctx, query, groupID, limit)
+		if err == nil {
+			return edges, nil
+		}
+		if !fallBack(err, &m.indexes.textMissing) {
+			return nil, err
+		}
+	}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Basic text search using CONTAINS
 		searchQuery := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
@@ -1750,30 +2016,27 @@ func (m *MemgraphDriver) SearchEdgesByVector(ctx context.Context, vector []float
 }
 
 // ExecuteQuery executes a Cypher query and returns records, summary, and keys (matching Python interface).
-func (m *MemgraphDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kwargs map[string]interface{}) (interface{}, interface{}, interface{}, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
+func (m *MemgraphDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kwargs map[string]interface{}) (records interface{}, summary interface{}, keys interface{}, err error) {
+	ctx, done := traceQuery(ctx, m.Provider(), "query")
+	defer func() { done(err) }()
 
-	result, err := session.Run(ctx, cypherQuery, kwargs)
-	if err != nil {
-		return nil, nil, nil, err
+	if active, ok := transactionFrom(ctx, m); ok {
+		result, err := active.tx.Run(ctx, cypherQuery, kwargs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return collectQueryResult(ctx, result)
 	}
 
-	records, err := result.Collect(ctx)
-	if err != nil {
-		return nil, nil, nil, err
-	}
+	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
+	defer session.Close(ctx)
 
-	summary, err := result.Consume(ctx)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	keys, err := result.Keys()
+	result, err := session.Run(ctx, cypherQuery, kwargs)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	return records, summary, keys, nil
+	return collectQueryResult(ctx, result)
 }
 
 // Session creates a new database session.
@@ -1875,18 +2138,30 @@ func (s *MemgraphDriverSession) Run(ctx context.Context, query interface{}, kwar
 		return fmt.Errorf("query must be a string")
 	}
 
+	if active, ok := transactionFrom(ctx, s.driver); ok {
+		_, err := active.tx.Run(ctx, queryStr, kwargs)
+		return err
+	}
+
 	_, err := s.session.Run(ctx, queryStr, kwargs)
 	return err
 }
 
-// ExecuteWrite executes a write transaction.
+// ExecuteWrite runs fn in a single write transaction.
 func (s *MemgraphDriverSession) ExecuteWrite(ctx context.Context, fn func(context.Context, GraphDriverSession, ...interface{}) (interface{}, error), args ...interface{}) (interface{}, error) {
 	if s.session == nil {
 		return nil, fmt.Errorf("session not entered")
 	}
 
-	return s.session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+	// Nested calls join the enclosing transaction.
+	if _, ok := transactionFrom(ctx, s.driver); ok {
 		return fn(ctx, s, args...)
+	}
+
+	// Driver methods called with the transaction context run inside tx, so
+	// everything fn writes commits or rolls back together.
+	return s.session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		return fn(withTransaction(ctx, s.driver, tx), s, args...)
 	})
 }
 
@@ -2056,6 +2331,9 @@ func (m *MemgraphDriver) nodeToProperties(node *types.Node) map[string]any {
 		if embeddingJSON, err := json.Marshal(node.Embedding); err == nil {
 			props["embedding"] = string(embeddingJSON)
 		}
+		if vector := m.indexes.vectorProperty(node.Embedding); vector != nil {
+			props[embeddingVectorProperty] = vector
+		}
 	}
 
 	// Source tracking
@@ -2150,6 +2428,12 @@ func (m *MemgraphDriver) edgeFromDBRelation(relation dbtype.Relationship, source
 	if strength, ok := props["strength"].(float64); ok {
 		result.Strength = strength
 	}
+	if confidence, ok := props["confidence"].(float64); ok {
+		result.Confidence = confidence
+	}
+	if corroboration, ok := props["corroboration"].(int64); ok {
+		result.Corroboration = int(corroboration)
+	}
 
 	// Episodes tracking
 	if episodesJSON, ok := props["episodes"].(string); ok {
@@ -2230,6 +2514,12 @@ func (m *MemgraphDriver) edgeToProperties(edge *types.Edge) map[string]any {
 	if edge.Strength > 0 {
 		props["strength"] = edge.Strength
 	}
+	if edge.Confidence > 0 {
+		props["confidence"] = edge.Confidence
+	}
+	if edge.Corroboration > 0 {
+		props["corroboration"] = edge.Corroboration
+	}
 
 	// Episodes tracking
 	if len(edge.Episodes) > 0 {
@@ -2248,6 +2538,9 @@ func (m *MemgraphDriver) edgeToProperties(edge *types.Edge) map[string]any {
 		if embeddingJSON, err := json.Marshal(edge.Embedding); err == nil {
 			props["embedding"] = string(embeddingJSON)
 		}
+		if vector := m.indexes.vectorProperty(edge.Embedding); vector != nil {
+			props[embeddingVectorProperty] = vector
+		}
 	}
 
 	// Source tracking
@@ -2464,10 +2757,7 @@ func (m *MemgraphDriver) ParseNodesFromRecords(result interface{}) ([]*types.Nod
 
 // getEntityNodesByGroupNeo4j gets entity nodes for Neo4j/Memgraph
 func (m *MemgraphDriver) GetEntityNodesByGroup(ctx context.Context, groupID string) ([]*types.Node, error) {
-	session := m.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: m.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := m.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n:Entity {group_id: $group_id})
 			RETURN n
@@ -2572,3 +2862,15 @@ func (m *MemgraphDriver) parseGroupIDsFromRecords(result interface{}) ([]string,
 
 	return []string{}, nil
 }
+
+// executeWrite runs work in the transaction carried by ctx, or in its own
+// write transaction.
+func (m *MemgraphDriver) executeWrite(ctx context.Context, work neo4j.ManagedTransactionWork) (any, error) {
+	return runManagedWork(ctx, m.client, m.database, m, true, work)
+}
+
+// executeRead runs work in the transaction carried by ctx, so reads see the
+// transaction's own writes, or in its own read transaction.
+func (m *MemgraphDriver) executeRead(ctx context.Context, work neo4j.ManagedTransactionWork) (any, error) {
+	return runManagedWork(ctx, m.client, m.database, m, false, work)
+}
@@ -0,0 +1,294 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Names of the native indexes created by the Neo4j and Memgraph drivers.
+const (
+	entityEmbeddingIndex    = "entity_embedding"
+	episodicEmbeddingIndex  = "episodic_embedding"
+	communityEmbeddingIndex = "community_embedding"
+	edgeEmbeddingIndex      = "relates_to_embedding"
+	nodeTextIndex           = "node_text"
+	edgeTextIndex           = "relates_to_text"
+
+	// embeddingVectorProperty holds a native float list mirroring the JSON
+	// "embedding" property, which vector indexes cannot read.
+	embeddingVectorProperty = "embedding_vector"
+
+	// vectorIndexOversample widens native vector queries, which run across
+	// all groups, before results are filtered down to one group.
+	vectorIndexOversample    = 10
+	minVectorIndexCandidates = 100
+
+	// embeddingBackfillBatchSize is the number of embedding_vector values
+	// written per transaction when backfilling existing data.
+	embeddingBackfillBatchSize = 500
+)
+
+// nodeEmbeddingIndexes maps node labels to their vector index names.
+var nodeEmbeddingIndexes = []struct {
+	label string
+	index string
+}{
+	{"Entity", entityEmbeddingIndex},
+	{"Episodic", episodicEmbeddingIndex},
+	{"Community", communityEmbeddingIndex},
+}
+
+// nativeIndexQuery is a statement creating a native vector or full-text index.
+type nativeIndexQuery struct {
+	query  string
+	vector bool
+}
+
+// nativeIndexState tracks the embedding dimension and whether the native
+// vector and full-text indexes are usable. When an index is found missing,
+// searches fall back to the CONTAINS and in-memory cosine paths until
+// CreateIndices runs again.
+type nativeIndexState struct {
+	dims          atomic.Int64
+	vectorMissing atomic.Bool
+	textMissing   atomic.Bool
+}
+
+// embeddingDimensions returns the configured embedding dimension, or 0 if unknown.
+func (s *nativeIndexState) embeddingDimensions() int {
+	return int(s.dims.Load())
+}
+
+// setEmbeddingDimensions records the embedding dimension.
+func (s *nativeIndexState) setEmbeddingDimensions(dims int) {
+	if dims < 0 {
+		dims = 0
+	}
+	s.dims.Store(int64(dims))
+}
+
+// reset marks the native indexes as available again.
+func (s *nativeIndexState) reset() {
+	s.vectorMissing.Store(false)
+	s.textMissing.Store(false)
+}
+
+// markMissing records that a vector or full-text index could not be created.
+func (s *nativeIndexState) markMissing(vector bool) {
+	if vector {
+		s.vectorMissing.Store(true)
+	} else {
+		s.textMissing.Store(true)
+	}
+}
+
+// useVectorIndex reports whether a vector search should try the native index.
+func (s *nativeIndexState) useVectorIndex(embedding []float32, limit int) bool {
+	if limit <= 0 || s.vectorMissing.Load() {
+		return false
+	}
+	dims := s.embeddingDimensions()
+	return dims == 0 || len(embedding) == dims
+}
+
+// useTextIndex reports whether a text search should try the native index.
+func (s *nativeIndexState) useTextIndex() bool {
+	return !s.textMissing.Load()
+}
+
+// fallBack reports whether err from a native index query should be answered
+// by the fallback path, remembering the index as missing if so.
+func fallBack(err error, missing *atomic.Bool) bool {
+	if !isMissingIndexError(err) {
+		return false
+	}
+	missing.Store(true)
+	return true
+}
+
+// vectorProperty returns embedding as a native list when it can be stored in
+// the vector index, or nil when it is empty or has the wrong dimension.
+func (s *nativeIndexState) vectorProperty(embedding []float32) []float64 {
+	if len(embedding) == 0 {
+		return nil
+	}
+	if dims := s.embeddingDimensions(); dims > 0 && len(embedding) != dims {
+		return nil
+	}
+	return toFloat64Slice(embedding)
+}
+
+// transactionRunner runs work in a managed read or write transaction.
+type transactionRunner func(ctx context.Context, work neo4j.ManagedTransactionWork) (any, error)
+
+// backfillEmbeddingVectors copies JSON embeddings written before the vector
+// indexes existed into the native embedding_vector property.
+func backfillEmbeddingVectors(ctx context.Context, state *nativeIndexState, read, write transactionRunner) error {
+	if state.embeddingDimensions() <= 0 {
+		return nil
+	}
+
+	targets := []struct {
+		match  string
+		update string
+	}{
+		{
+			match:  "MATCH (n) WHERE n.embedding IS NOT NULL AND n.embedding_vector IS NULL RETURN n.uuid AS uuid, n.embedding AS embedding",
+			update: "UNWIND $rows AS row MATCH (n {uuid: row.uuid}) SET n.embedding_vector = row.vector",
+		},
+		{
+			match:  "MATCH ()-[r:RELATES_TO]->() WHERE r.embedding IS NOT NULL AND r.embedding_vector IS NULL RETURN r.uuid AS uuid, r.embedding AS embedding",
+			update: "UNWIND $rows AS row MATCH ()-[r:RELATES_TO {uuid: row.uuid}]->() SET r.embedding_vector = row.vector",
+		},
+	}
+
+	for _, target := range targets {
+		result, err := read(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, target.match, nil)
+			if err != nil {
+				return nil, err
+			}
+			return res.Collect(ctx)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to find embeddings to backfill: %w", err)
+		}
+		records, ok := AsRecordSlice(result)
+		if !ok {
+			return fmt.Errorf("unexpected result type: got %T, expected []*db.Record", result)
+		}
+
+		var rows []map[string]any
+		for _, record := range records {
+			uuid, _ := record.Get("uuid")
+			value, _ := record.Get("embedding")
+			embedding, ok := parseEmbeddingJSON(value)
+			if !ok {
+				continue
+			}
+			if vector := state.vectorProperty(embedding); vector != nil {
+				rows = append(rows, map[string]any{"uuid": uuid, "vector": vector})
+			}
+		}
+
+		for start := 0; start < len(rows); start += embeddingBackfillBatchSize {
+			end := min(start+embeddingBackfillBatchSize, len(rows))
+			batch := rows[start:end]
+			_, err := write(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+				_, err := tx.Run(ctx, target.update, map[string]any{"rows": batch})
+				return nil, err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to backfill embedding vectors: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// vectorCandidates returns how many index hits to request for limit results.
+func vectorCandidates(limit int) int {
+	k := limit * vectorIndexOversample
+	if k < minVectorIndexCandidates {
+		k = minVectorIndexCandidates
+	}
+	return k
+}
+
+// toFloat64Slice converts an embedding to the list type stored by Bolt drivers.
+func toFloat64Slice(embedding []float32) []float64 {
+	vector := make([]float64, len(embedding))
+	for i, v := range embedding {
+		vector[i] = float64(v)
+	}
+	return vector
+}
+
+// parseEmbeddingJSON decodes a JSON-encoded embedding property.
+func parseEmbeddingJSON(value any) ([]float32, bool) {
+	embeddingStr, ok := value.(string)
+	if !ok || embeddingStr == "" {
+		return nil, false
+	}
+	var embedding []float32
+	if err := json.Unmarshal([]byte(embeddingStr), &embedding); err != nil {
+		return nil, false
+	}
+	return embedding, true
+}
+
+// isMissingIndexError reports whether err means a native index or search
+// procedure is unavailable, so the caller should use the fallback path.
+// Generic "does not exist" errors only count when they name one of the
+// native indexes.
+func isMissingIndexError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"no such vector schema index",
+		"no such fulltext schema index",
+		"there is no procedure with the name",
+		"procedurenotfound",
+		"procedure not found",
+		"not found in the procedure",
+		"text search feature",
+	} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	if !strings.Contains(msg, "does not exist") && !strings.Contains(msg, "doesn't exist") && !strings.Contains(msg, "no such index") {
+		return false
+	}
+	for _, name := range nativeIndexNames() {
+		if strings.Contains(msg, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// nativeIndexNames lists the names of every native index the drivers create.
+func nativeIndexNames() []string {
+	names := []string{edgeEmbeddingIndex, nodeTextIndex, edgeTextIndex}
+	for _, idx := range nodeEmbeddingIndexes {
+		names = append(names, idx.index)
+	}
+	for _, idx := range memgraphNodeTextIndexes {
+		names = append(names, idx.index)
+	}
+	return names
+}
+
+// isIndexExistsError reports whether an index creation error only means the
+// index is already there.
+func isIndexExistsError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "already exists") || strings.Contains(msg, "An equivalent")
+}
+
+// luceneSpecialChars lists the characters with meaning in Lucene and
+// Tantivy query syntax.
+const luceneSpecialChars = `+-&|!(){}[]^"~*?:\/`
+
+// escapeLuceneQuery escapes query syntax so user text is matched literally
+// by full-text indexes.
+func escapeLuceneQuery(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	for _, r := range query {
+		if strings.ContainsRune(luceneSpecialChars, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimSpace(b.String())
+}
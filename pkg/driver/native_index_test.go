@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEscapeLuceneQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "plain text", query: "alice bob", expected: "alice bob"},
+		{name: "operators", query: "a+b -c", expected: `a\+b \-c`},
+		{name: "field syntax", query: "name:alice", expected: `name\:alice`},
+		{name: "grouping and quotes", query: `(x) "y"`, expected: `\(x\) \"y\"`},
+		{name: "backslash", query: `a\b`, expected: `a\\b`},
+		{name: "whitespace only", query: "   ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := escapeLuceneQuery(tt.query); got != tt.expected {
+				t.Errorf("escapeLuceneQuery(%q) = %q, want %q", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsMissingIndexError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "neo4j vector index", err: errors.New("There is no such vector schema index: entity_embedding"), expected: true},
+		{name: "neo4j fulltext index", err: errors.New("There is no such fulltext schema index: node_text"), expected: true},
+		{name: "neo4j procedure", err: errors.New("There is no procedure with the name `db.index.vector.queryNodes` registered"), expected: true},
+		{name: "memgraph vector index", err: errors.New("Vector index entity_embedding does not exist."), expected: true},
+		{name: "memgraph text search disabled", err: errors.New("To use text indices and text search, start Memgraph with the experimental text search feature enabled."), expected: true},
+		{name: "memgraph text index", err: errors.New("Text index entity_text doesn't exist."), expected: true},
+		{name: "other error", err: errors.New("connection refused"), expected: false},
+		{name: "unrelated missing database", err: errors.New("Database graph.db does not exist."), expected: false},
+		{name: "unrelated missing label", err: errors.New("Label Person does not exist"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := isMissingIndexError(tt.err); got != tt.expected {
+				t.Errorf("isMissingIndexError(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNativeIndexStateVectorProperty(t *testing.T) {
+	t.Parallel()
+
+	var state nativeIndexState
+	if got := state.vectorProperty(nil); got != nil {
+		t.Errorf("vectorProperty(nil) = %v, want nil", got)
+	}
+	if got := state.vectorProperty([]float32{1, 2}); len(got) != 2 {
+		t.Errorf("vectorProperty without dimensions = %v, want 2 values", got)
+	}
+
+	state.setEmbeddingDimensions(3)
+	if got := state.vectorProperty([]float32{1, 2}); got != nil {
+		t.Errorf("vectorProperty with wrong dimension = %v, want nil", got)
+	}
+	if got := state.vectorProperty([]float32{1, 2, 3}); len(got) != 3 {
+		t.Errorf("vectorProperty with matching dimension = %v, want 3 values", got)
+	}
+	if state.useVectorIndex([]float32{1, 2}, 10) {
+		t.Error("useVectorIndex should reject a query of the wrong dimension")
+	}
+
+	state.markMissing(true)
+	if state.useVectorIndex([]float32{1, 2, 3}, 10) {
+		t.Error("useVectorIndex should be false after the index is marked missing")
+	}
+	state.reset()
+	if !state.useVectorIndex([]float32{1, 2, 3}, 10) {
+		t.Error("useVectorIndex should be true after reset")
+	}
+}
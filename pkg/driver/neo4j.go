@@ -8,7 +8,6 @@ import (
 	"math"
 	"reflect"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -21,6 +20,7 @@ import (
 type Neo4jDriver struct {
 	client   neo4j.DriverWithContext
 	database string
+	indexes  nativeIndexState
 }
 
 // NewNeo4jDriver creates a new Neo4j driver instance.
@@ -42,10 +42,7 @@ func NewNeo4jDriver(uri, username, password, database string) (*Neo4jDriver, err
 
 // GetNode retrieves a node by ID.
 func (n *Neo4jDriver) GetNode(ctx context.Context, nodeID, groupID string) (*types.Node, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {uuid: $nodeID, group_id: $groupID})
 			RETURN n
@@ -95,10 +92,7 @@ func (n *Neo4jDriver) NodeExists(ctx context.Context, node *types.Node) bool {
 		return false
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {uuid: $uuid, group_id: $group_id})
 			RETURN n.uuid
@@ -152,10 +146,7 @@ func (n *Neo4jDriver) UpsertNode(ctx context.Context, node *types.Node) error {
 		node.ValidFrom = node.CreatedAt
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Get base label for node type
 		baseLabel := n.getLabelForNodeType(node.Type)
 
@@ -193,10 +184,7 @@ func (n *Neo4jDriver) UpsertNode(ctx context.Context, node *types.Node) error {
 
 // DeleteNode removes a node and its edges.
 func (n *Neo4jDriver) DeleteNode(ctx context.Context, nodeID, groupID string) error {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {uuid: $nodeID, group_id: $groupID})
 			DETACH DELETE n
@@ -217,10 +205,7 @@ func (n *Neo4jDriver) GetNodes(ctx context.Context, nodeIDs []string, groupID st
 		return []*types.Node{}, nil
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {group_id: $groupID})
 			WHERE n.uuid IN $nodeIDs
@@ -264,10 +249,7 @@ func (n *Neo4jDriver) GetNodes(ctx context.Context, nodeIDs []string, groupID st
 
 // GetEdge retrieves an edge by ID.
 func (n *Neo4jDriver) GetEdge(ctx context.Context, edgeID, groupID string) (*types.Edge, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {uuid: $edgeID, group_id: $groupID}]->(t)
 			RETURN r, s.uuid as source_id, t.uuid as target_id
@@ -329,10 +311,7 @@ func (n *Neo4jDriver) EdgeExists(ctx context.Context, edge *types.Edge) bool {
 		return false
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH ()-[r {uuid: $uuid, group_id: $group_id}]-()
 			RETURN r.uuid
@@ -371,10 +350,7 @@ func (n *Neo4jDriver) UpsertEdge(ctx context.Context, edge *types.Edge) error {
 		edge.ValidFrom = edge.CreatedAt
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s {uuid: $source_id, group_id: $group_id})
 			MATCH (t {uuid: $target_id, group_id: $group_id})
@@ -403,10 +379,7 @@ func (n *Neo4jDriver) UpsertEdge(ctx context.Context, edge *types.Edge) error {
 // UpsertEpisodicEdge creates or updates a MENTIONS relationship between an Episodic node and an Entity node.
 // This matches Python's EpisodicEdge.save() method.
 func (n *Neo4jDriver) UpsertEpisodicEdge(ctx context.Context, episodeUUID, entityUUID, groupID string) error {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Python EPISODIC_EDGE_SAVE uses MERGE for idempotent upserts
 		query := `
 			MATCH (episode:Episodic {uuid: $episode_uuid, group_id: $group_id})
@@ -433,10 +406,7 @@ func (n *Neo4jDriver) UpsertEpisodicEdge(ctx context.Context, episodeUUID, entit
 // UpsertCommunityEdge creates or updates a HAS_MEMBER relationship between a Community node and an Entity or Community node.
 // This matches Python's CommunityEdge.save() method.
 func (n *Neo4jDriver) UpsertCommunityEdge(ctx context.Context, communityUUID, nodeUUID, uuid, groupID string) error {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Python uses (node:Entity | Community) syntax to match either type
 		// Neo4j supports this label alternative syntax
 		query := `
@@ -465,10 +435,7 @@ func (n *Neo4jDriver) UpsertCommunityEdge(ctx context.Context, communityUUID, no
 
 // DeleteEdge removes an edge.
 func (n *Neo4jDriver) DeleteEdge(ctx context.Context, edgeID, groupID string) error {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH ()-[r {uuid: $edgeID, group_id: $groupID}]-()
 			DELETE r
@@ -489,10 +456,7 @@ func (n *Neo4jDriver) GetEdges(ctx context.Context, edgeIDs []string, groupID st
 		return []*types.Edge{}, nil
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
 			WHERE r.uuid IN $edgeIDs
@@ -544,10 +508,7 @@ func (n *Neo4jDriver) GetEdges(ctx context.Context, edgeIDs []string, groupID st
 
 // GetNeighbors retrieves neighboring nodes within a specified distance
 func (n *Neo4jDriver) GetNeighbors(ctx context.Context, nodeID, groupID string, maxDistance int) ([]*types.Node, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := fmt.Sprintf(`
 			MATCH (start {uuid: $nodeID, group_id: $groupID})
 			MATCH (start)-[*1..%d]-(neighbor)
@@ -589,10 +550,7 @@ func (n *Neo4jDriver) GetNeighbors(ctx context.Context, nodeID, groupID string,
 }
 
 func (n *Neo4jDriver) GetRelatedNodes(ctx context.Context, nodeID, groupID string, edgeTypes []types.EdgeType) ([]*types.Node, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		var query string
 		params := map[string]any{
 			"nodeID":  nodeID,
@@ -660,11 +618,20 @@ func (n *Neo4jDriver) SearchNodesByEmbedding(ctx context.Context, embedding []fl
 		return []*types.Node{}, nil
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
+	if n.indexes.useVectorIndex(embedding, limit) {
+		// The index ranks every group, so the group's matches may fall
+		// outside its top k; the exact scan below answers short results.
+		nodes, err := n.searchNodesByVectorIndex(ctx, embedding, groupID, limit)
+		if err == nil && len(nodes) >= limit {
+			return nodes, nil
+		}
+		if err != nil && !fallBack(err, &n.indexes.vectorMissing) {
+			return nil, err
+		}
+	}
 
 	// Get all nodes with embeddings and compute similarity in-memory
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {group_id: $groupID})
 			WHERE n.embedding IS NOT NULL
@@ -740,11 +707,20 @@ func (n *Neo4jDriver) SearchEdgesByEmbedding(ctx context.Context, embedding []fl
 		return []*types.Edge{}, nil
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
+	if n.indexes.useVectorIndex(embedding, limit) {
+		// The index ranks every group, so the group's matches may fall
+		// outside its top k; the exact scan below answers short results.
+		edges, err := n.searchEdgesByVectorIndex(ctx, embedding, groupID, limit)
+		if err == nil && len(edges) >= limit {
+			return edges, nil
+		}
+		if err != nil && !fallBack(err, &n.indexes.vectorMissing) {
+			return nil, err
+		}
+	}
 
 	// Get all edges with embeddings and compute similarity in-memory
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
 			WHERE r.embedding IS NOT NULL
@@ -830,13 +806,10 @@ func (n *Neo4jDriver) UpsertNodes(ctx context.Context, nodes []*types.Node) erro
 		return nil
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
 	// Use UNWIND for efficient bulk operations matching Python's approach
 	// Note: Dynamic labels for Entity nodes need to be handled in individual upserts
 	// because Cypher doesn't support parameterized labels in UNWIND context without APOC
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Build node data array
 		nodeDataList := make([]map[string]any, 0, len(nodes))
 		for _, node := range nodes {
@@ -865,22 +838,25 @@ func (n *Neo4jDriver) UpsertNodes(ctx context.Context, nodes []*types.Node) erro
 			return nil, fmt.Errorf("failed to bulk upsert nodes: %w", err)
 		}
 
-		// Handle dynamic labels for Entity nodes separately
+		// Handle labels separately: the base label is needed by the native
+		// indexes, and Entity nodes also get a dynamic label for their type.
 		// This is a tradeoff: bulk insert is fast, but labels need individual updates
 		for _, node := range nodes {
+			labels := n.getLabelForNodeType(node.Type)
 			if node.Type == types.EntityNodeType && node.EntityType != "" {
-				labelQuery := fmt.Sprintf(`
-					MATCH (n {uuid: $uuid, group_id: $group_id})
-					SET n:%s
-				`, node.EntityType)
-
-				_, err := tx.Run(ctx, labelQuery, map[string]any{
-					"uuid":     node.Uuid,
-					"group_id": node.GroupID,
-				})
-				if err != nil {
-					return nil, fmt.Errorf("failed to set label for node %s: %w", node.Uuid, err)
-				}
+				labels += ":" + node.EntityType
+			}
+			labelQuery := fmt.Sprintf(`
+				MATCH (n {uuid: $uuid, group_id: $group_id})
+				SET n:%s
+			`, labels)
+
+			_, err := tx.Run(ctx, labelQuery, map[string]any{
+				"uuid":     node.Uuid,
+				"group_id": node.GroupID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to set label for node %s: %w", node.Uuid, err)
 			}
 		}
 
@@ -895,11 +871,8 @@ func (n *Neo4jDriver) UpsertEdges(ctx context.Context, edges []*types.Edge) erro
 		return nil
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
 	// Use UNWIND for efficient bulk operations matching Python's approach
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Build edge data array
 		edgeDataList := make([]map[string]any, 0, len(edges))
 		for _, edge := range edges {
@@ -939,10 +912,7 @@ func (n *Neo4jDriver) UpsertEdges(ctx context.Context, edges []*types.Edge) erro
 }
 
 func (n *Neo4jDriver) GetNodesInTimeRange(ctx context.Context, start, end time.Time, groupID string) ([]*types.Node, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n {group_id: $groupID})
 			WHERE n.created_at >= $start AND n.created_at <= $end
@@ -983,10 +953,7 @@ func (n *Neo4jDriver) GetNodesInTimeRange(ctx context.Context, start, end time.T
 }
 
 func (n *Neo4jDriver) GetEdgesInTimeRange(ctx context.Context, start, end time.Time, groupID string) ([]*types.Edge, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
 			WHERE r.created_at >= $start AND r.created_at <= $end
@@ -1050,10 +1017,7 @@ func (n *Neo4jDriver) RetrieveEpisodes(
 		limit = 10
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Build query parameters
 		queryParams := make(map[string]any)
 		// Use neo4j.LocalDateTime type which Neo4j understands natively
@@ -1122,10 +1086,7 @@ func (n *Neo4jDriver) RetrieveEpisodes(
 
 func (n *Neo4jDriver) GetCommunities(ctx context.Context, groupID string, level int) ([]*types.Node, error) {
 	// For basic implementation, return nodes grouped by a hypothetical community property
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n:Community {group_id: $groupID})
 			WHERE n.community_level = $level
@@ -1166,10 +1127,7 @@ func (n *Neo4jDriver) GetCommunities(ctx context.Context, groupID string, level
 
 func (n *Neo4jDriver) BuildCommunities(ctx context.Context, groupID string) error {
 	// Basic implementation that assigns community IDs based on connected components
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Reset existing community assignments
 		resetQuery := `
 			MATCH (n:Community {group_id: $groupID})
@@ -1325,10 +1283,7 @@ func (n *Neo4jDriver) parseCommunityNodesFromRecords(result interface{}) ([]*typ
 // RemoveCommunities removes all community nodes and their relationships from the graph.
 // Neo4j-specific implementation using DETACH DELETE.
 func (n *Neo4jDriver) RemoveCommunities(ctx context.Context) error {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	_, err := n.executeWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := "MATCH (c:Community) DETACH DELETE c"
 		_, err := tx.Run(ctx, query, nil)
 		return nil, err
@@ -1359,20 +1314,254 @@ func (n *Neo4jDriver) CreateIndices(ctx context.Context) error {
 	for _, indexQuery := range indices {
 		_, err := session.Run(ctx, indexQuery, nil)
 		if err != nil {
-			if !strings.Contains(err.Error(), "already exists") && !strings.Contains(err.Error(), "An equivalent") {
+			if !isIndexExistsError(err) {
 				return err
 			}
 		}
 	}
 
+	if err := backfillEmbeddingVectors(ctx, &n.indexes, n.executeRead, n.executeWrite); err != nil {
+		return err
+	}
+
+	// Vector and full-text indexes need Neo4j 5.x; without them search
+	// falls back to CONTAINS matching and in-memory cosine similarity.
+	n.indexes.reset()
+	for _, native := range n.nativeIndexQueries() {
+		if _, err := session.Run(ctx, native.query, nil); err != nil && !isIndexExistsError(err) {
+			log.Printf("Warning: native index unavailable, using fallback search: %v", err)
+			n.indexes.markMissing(native.vector)
+		}
+	}
+
 	return nil
 }
 
-func (n *Neo4jDriver) GetStats(ctx context.Context, groupID string) (*GraphStats, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
+// SetEmbeddingDimensions sets the dimension of the native vector indexes
+// created by CreateIndices. Vector indexes are skipped while it is 0.
+func (n *Neo4jDriver) SetEmbeddingDimensions(dims int) {
+	n.indexes.setEmbeddingDimensions(dims)
+}
+
+// nativeIndexQueries returns the statements creating the vector and
+// full-text indexes used by the search methods.
+func (n *Neo4jDriver) nativeIndexQueries() []nativeIndexQuery {
+	queries := []nativeIndexQuery{
+		{query: fmt.Sprintf("CREATE FULLTEXT INDEX %s IF NOT EXISTS FOR (n:Entity|Episodic|Community) ON EACH [n.name, n.summary, n.content]", nodeTextIndex)},
+		{query: fmt.Sprintf("CREATE FULLTEXT INDEX %s IF NOT EXISTS FOR ()-[r:RELATES_TO]-() ON EACH [r.name, r.summary, r.fact]", edgeTextIndex)},
+	}
+
+	dims := n.indexes.embeddingDimensions()
+	if dims <= 0 {
+		return queries
+	}
+
+	indexConfig := fmt.Sprintf("OPTIONS {indexConfig: {`vector.dimensions`: %d, `vector.similarity_function`: 'cosine'}}", dims)
+	for _, idx := range nodeEmbeddingIndexes {
+		queries = append(queries, nativeIndexQuery{
+			query: fmt.Sprintf("CREATE VECTOR INDEX %s IF NOT EXISTS FOR (n:%s) ON (n.%s) %s",
+				idx.index, idx.label, embeddingVectorProperty, indexConfig),
+			vector: true,
+		})
+	}
+	queries = append(queries, nativeIndexQuery{
+		query: fmt.Sprintf("CREATE VECTOR INDEX %s IF NOT EXISTS FOR ()-[r:RELATES_TO]-() ON (r.%s) %s",
+			edgeEmbeddingIndex, embeddingVectorProperty, indexConfig),
+		vector: true,
+	})
+
+	return queries
+}
+
+// searchNodesByVectorIndex queries the per-label vector indexes and merges
+// the hits by cosine score.
+func (n *Neo4jDriver) searchNodesByVectorIndex(ctx context.Context, embedding []float32, groupID string, limit int) ([]*types.Node, error) {
+	type scoredNode struct {
+		node  *types.Node
+		score float64
+	}
+	var candidates []scoredNode
+
+	for _, idx := range nodeEmbeddingIndexes {
+		result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			query := `
+				CALL db.index.vector.queryNodes($index, $k, $vector) YIELD node, score
+				WHERE node.group_id = $groupID
+				RETURN node AS n, score
+				ORDER BY score DESC
+				LIMIT $limit
+			`
+			res, err := tx.Run(ctx, query, map[string]any{
+				"index":   idx.index,
+				"k":       vectorCandidates(limit),
+				"vector":  toFloat64Slice(embedding),
+				"groupID": groupID,
+				"limit":   limit,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return res.Collect(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range result.([]*db.Record) {
+			nodeValue, _ := record.Get("n")
+			dbNode, ok := nodeValue.(dbtype.Node)
+			if !ok {
+				continue
+			}
+			scoreValue, _ := record.Get("score")
+			score, _ := scoreValue.(float64)
+			candidates = append(candidates, scoredNode{node: n.nodeFromDBNode(dbNode), score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	nodes := make([]*types.Node, len(candidates))
+	for i, candidate := range candidates {
+		nodes[i] = candidate.node
+	}
+	return nodes, nil
+}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+// searchEdgesByVectorIndex queries the RELATES_TO vector index.
+func (n *Neo4jDriver) searchEdgesByVectorIndex(ctx context.Context, embedding []float32, groupID string, limit int) ([]*types.Edge, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		query := `
+			CALL db.index.vector.queryRelationships($index, $k, $vector) YIELD relationship, score
+			WHERE relationship.group_id = $groupID
+			RETURN relationship AS r, startNode(relationship).uuid AS source_id, endNode(relationship).uuid AS target_id
+			ORDER BY score DESC
+			LIMIT $limit
+		`
+		res, err := tx.Run(ctx, query, map[string]any{
+			"index":   edgeEmbeddingIndex,
+			"k":       vectorCandidates(limit),
+			"vector":  toFloat64Slice(embedding),
+			"groupID": groupID,
+			"limit":   limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Collect(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return n.edgesFromRecords(result.([]*db.Record)), nil
+}
+
+// searchNodesByFulltextIndex runs a BM25 query against the node full-text index.
+func (n *Neo4jDriver) searchNodesByFulltextIndex(ctx context.Context, query, groupID string, limit int) ([]*types.Node, error) {
+	luceneQuery := escapeLuceneQuery(query)
+	if luceneQuery == "" {
+		return []*types.Node{}, nil
+	}
+
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		searchQuery := `
+			CALL db.index.fulltext.queryNodes($index, $query) YIELD node, score
+			WHERE node.group_id = $groupID
+			RETURN node AS n
+			ORDER BY score DESC
+			LIMIT $limit
+		`
+		res, err := tx.Run(ctx, searchQuery, map[string]any{
+			"index":   nodeTextIndex,
+			"query":   luceneQuery,
+			"groupID": groupID,
+			"limit":   limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Collect(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := result.([]*db.Record)
+	nodes := make([]*types.Node, 0, len(records))
+	for _, record := range records {
+		nodeValue, _ := record.Get("n")
+		if node, ok := nodeValue.(dbtype.Node); ok {
+			nodes = append(nodes, n.nodeFromDBNode(node))
+		}
+	}
+	return nodes, nil
+}
+
+// searchEdgesByFulltextIndex runs a BM25 query against the RELATES_TO full-text index.
+func (n *Neo4jDriver) searchEdgesByFulltextIndex(ctx context.Context, query, groupID string, limit int) ([]*types.Edge, error) {
+	luceneQuery := escapeLuceneQuery(query)
+	if luceneQuery == "" {
+		return []*types.Edge{}, nil
+	}
+
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		searchQuery := `
+			CALL db.index.fulltext.queryRelationships($index, $query) YIELD relationship, score
+			WHERE relationship.group_id = $groupID
+			RETURN relationship AS r, startNode(relationship).uuid AS source_id, endNode(relationship).uuid AS target_id
+			ORDER BY score DESC
+			LIMIT $limit
+		`
+		res, err := tx.Run(ctx, searchQuery, map[string]any{
+			"index":   edgeTextIndex,
+			"query":   luceneQuery,
+			"groupID": groupID,
+			"limit":   limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Collect(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return n.edgesFromRecords(result.([]*db.Record)), nil
+}
+
+// edgesFromRecords converts records holding r, source_id and target_id into edges.
+func (n *Neo4jDriver) edgesFromRecords(records []*db.Record) []*types.Edge {
+	edges := make([]*types.Edge, 0, len(records))
+	for _, record := range records {
+		relationValue, _ := record.Get("r")
+		relation, ok := relationValue.(dbtype.Relationship)
+		if !ok {
+			continue
+		}
+		sourceIDValue, _ := record.Get("source_id")
+		targetIDValue, _ := record.Get("target_id")
+		sourceID, ok := sourceIDValue.(string)
+		if !ok {
+			continue
+		}
+		targetID, ok := targetIDValue.(string)
+		if !ok {
+			continue
+		}
+		edges = append(edges, n.edgeFromDBRelation(relation, sourceID, targetID))
+	}
+	return edges
+}
+
+func (n *Neo4jDriver) GetStats(ctx context.Context, groupID string) (*GraphStats, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Get node count by label (Entity, Episodic, Community)
 		// Note: In Neo4j, node types are labels, not properties
 		nodeQuery := `
@@ -1507,10 +1696,17 @@ func (n *Neo4jDriver) SearchNodes(ctx context.Context, query, groupID string, op
 		limit = options.Limit
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
+	if (options == nil || !options.ExactMatch) && n.indexes.useTextIndex() {
+		nodes, err := n.searchNodesByFulltextIndex(ctx, query, groupID, limit)
+		if err == nil {
+			return nodes, nil
+		}
+		if !fallBack(err, &n.indexes.textMissing) {
+			return nil, err
+		}
+	}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		var searchQuery string
 		queryParams := map[string]any{
 			"groupID": groupID,
@@ -1577,10 +1773,17 @@ func (n *Neo4jDriver) SearchEdges(ctx context.Context, query, groupID string, op
 		limit = options.Limit
 	}
 
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
+	if n.indexes.useTextIndex() {
+		edges, err := n.searchEdgesByFulltextIndex(ctx, query, groupID, limit)
+		if err == nil {
+			return edges, nil
+		}
+		if !fallBack(err, &n.indexes.textMissing) {
+			return nil, err
+		}
+	}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		// Basic text search using CONTAINS
 		searchQuery := `
 			MATCH (s)-[r {group_id: $groupID}]->(t)
@@ -1716,30 +1919,27 @@ func (n *Neo4jDriver) SearchEdgesByVector(ctx context.Context, vector []float32,
 }
 
 // ExecuteQuery executes a Cypher query and returns records, summary, and keys (matching Python interface).
-func (n *Neo4jDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kwargs map[string]interface{}) (interface{}, interface{}, interface{}, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
+func (n *Neo4jDriver) ExecuteQuery(ctx context.Context, cypherQuery string, kwargs map[string]interface{}) (records interface{}, summary interface{}, keys interface{}, err error) {
+	ctx, done := traceQuery(ctx, n.Provider(), "query")
+	defer func() { done(err) }()
 
-	result, err := session.Run(ctx, cypherQuery, kwargs)
-	if err != nil {
-		return nil, nil, nil, err
+	if active, ok := transactionFrom(ctx, n); ok {
+		result, err := active.tx.Run(ctx, cypherQuery, kwargs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return collectQueryResult(ctx, result)
 	}
 
-	records, err := result.Collect(ctx)
-	if err != nil {
-		return nil, nil, nil, err
-	}
+	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
+	defer session.Close(ctx)
 
-	summary, err := result.Consume(ctx)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-	keys, err := result.Keys()
+	result, err := session.Run(ctx, cypherQuery, kwargs)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	return records, summary, keys, nil
+	return collectQueryResult(ctx, result)
 }
 
 // Session creates a new database session.
@@ -1841,18 +2041,30 @@ func (s *Neo4jDriverSession) Run(ctx context.Context, query interface{}, kwargs
 		return fmt.Errorf("query must be a string")
 	}
 
+	if active, ok := transactionFrom(ctx, s.driver); ok {
+		_, err := active.tx.Run(ctx, queryStr, kwargs)
+		return err
+	}
+
 	_, err := s.session.Run(ctx, queryStr, kwargs)
 	return err
 }
 
-// ExecuteWrite executes a write transaction.
+// ExecuteWrite runs fn in a single write transaction.
 func (s *Neo4jDriverSession) ExecuteWrite(ctx context.Context, fn func(context.Context, GraphDriverSession, ...interface{}) (interface{}, error), args ...interface{}) (interface{}, error) {
 	if s.session == nil {
 		return nil, fmt.Errorf("session not entered")
 	}
 
-	return s.session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+	// Nested calls join the enclosing transaction.
+	if _, ok := transactionFrom(ctx, s.driver); ok {
 		return fn(ctx, s, args...)
+	}
+
+	// Driver methods called with the transaction context run inside tx, so
+	// everything fn writes commits or rolls back together.
+	return s.session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		return fn(withTransaction(ctx, s.driver, tx), s, args...)
 	})
 }
 
@@ -2022,6 +2234,9 @@ func (n *Neo4jDriver) nodeToProperties(node *types.Node) map[string]any {
 		if embeddingJSON, err := json.Marshal(node.Embedding); err == nil {
 			props["embedding"] = string(embeddingJSON)
 		}
+		if vector := n.indexes.vectorProperty(node.Embedding); vector != nil {
+			props[embeddingVectorProperty] = vector
+		}
 	}
 
 	// Source tracking
@@ -2116,6 +2331,12 @@ func (n *Neo4jDriver) edgeFromDBRelation(relation dbtype.Relationship, sourceID,
 	if strength, ok := props["strength"].(float64); ok {
 		result.Strength = strength
 	}
+	if confidence, ok := props["confidence"].(float64); ok {
+		result.Confidence = confidence
+	}
+	if corroboration, ok := props["corroboration"].(int64); ok {
+		result.Corroboration = int(corroboration)
+	}
 
 	// Episodes tracking
 	if episodesJSON, ok := props["episodes"].(string); ok {
@@ -2196,6 +2417,12 @@ func (n *Neo4jDriver) edgeToProperties(edge *types.Edge) map[string]any {
 	if edge.Strength > 0 {
 		props["strength"] = edge.Strength
 	}
+	if edge.Confidence > 0 {
+		props["confidence"] = edge.Confidence
+	}
+	if edge.Corroboration > 0 {
+		props["corroboration"] = edge.Corroboration
+	}
 
 	// Episodes tracking
 	if len(edge.Episodes) > 0 {
@@ -2214,6 +2441,9 @@ func (n *Neo4jDriver) edgeToProperties(edge *types.Edge) map[string]any {
 		if embeddingJSON, err := json.Marshal(edge.Embedding); err == nil {
 			props["embedding"] = string(embeddingJSON)
 		}
+		if vector := n.indexes.vectorProperty(edge.Embedding); vector != nil {
+			props[embeddingVectorProperty] = vector
+		}
 	}
 
 	// Source tracking
@@ -2430,10 +2660,7 @@ func (n *Neo4jDriver) ParseNodesFromRecords(result interface{}) ([]*types.Node,
 
 // getEntityNodesByGroupNeo4j gets entity nodes for Neo4j
 func (n *Neo4jDriver) GetEntityNodesByGroup(ctx context.Context, groupID string) ([]*types.Node, error) {
-	session := n.client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: n.database})
-	defer session.Close(ctx)
-
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	result, err := n.executeRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		query := `
 			MATCH (n:Entity {group_id: $group_id})
 			RETURN n
@@ -2535,3 +2762,15 @@ func (n *Neo4jDriver) parseGroupIDsFromRecords(result interface{}) ([]string, er
 
 	return []string{}, nil
 }
+
+// executeWrite runs work in the transaction carried by ctx, or in its own
+// write transaction.
+func (n *Neo4jDriver) executeWrite(ctx context.Context, work neo4j.ManagedTransactionWork) (any, error) {
+	return runManagedWork(ctx, n.client, n.database, n, true, work)
+}
+
+// executeRead runs work in the transaction carried by ctx, so reads see the
+// transaction's own writes, or in its own read transaction.
+func (n *Neo4jDriver) executeRead(ctx context.Context, work neo4j.ManagedTransactionWork) (any, error) {
+	return runManagedWork(ctx, n.client, n.database, n, false, work)
+}
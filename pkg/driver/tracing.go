@@ -0,0 +1,28 @@
+package driver
+
+import (
+	"context"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/telemetry"
+)
+
+// traceQuery starts a span for a graph database operation, e.g. "query",
+// "read" or "write". The returned function ends the span and records the
+// operation's latency.
+func traceQuery(ctx context.Context, provider GraphProvider, operation string) (context.Context, func(error)) {
+	ctx, span := telemetry.StartSpan(ctx, "driver."+operation, telemetry.AttrDBSystem.String(string(provider)))
+	start := time.Now()
+	return ctx, func(err error) {
+		telemetry.ObserveDriverQuery(string(provider), operation, time.Since(start), err)
+		telemetry.EndSpan(span, err)
+	}
+}
+
+// providerOf returns the provider of a driver, or "" if owner is not one.
+func providerOf(owner any) GraphProvider {
+	if d, ok := owner.(interface{ Provider() GraphProvider }); ok {
+		return d.Provider()
+	}
+	return ""
+}
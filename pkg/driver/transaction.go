@@ -0,0 +1,106 @@
+package driver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// txContextKey is the context key for the transaction opened by
+// GraphDriverSession.ExecuteWrite.
+type txContextKey struct{}
+
+// activeTransaction records the driver that owns an open transaction and,
+// for Bolt drivers, the transaction itself.
+type activeTransaction struct {
+	owner any
+	tx    neo4j.ManagedTransaction
+
+	// commitHooks run after the transaction commits and are dropped on
+	// rollback, so in-memory state only follows committed writes.
+	mu          sync.Mutex
+	commitHooks []func()
+}
+
+// afterCommit registers fn to run once the transaction has committed.
+func (t *activeTransaction) afterCommit(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.commitHooks = append(t.commitHooks, fn)
+}
+
+// committed runs the registered commit hooks in order.
+func (t *activeTransaction) committed() {
+	t.mu.Lock()
+	hooks := t.commitHooks
+	t.commitHooks = nil
+	t.mu.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+// withTransaction returns a context that routes the owner's queries through tx.
+func withTransaction(ctx context.Context, owner any, tx neo4j.ManagedTransaction) context.Context {
+	return context.WithValue(ctx, txContextKey{}, &activeTransaction{owner: owner, tx: tx})
+}
+
+// transactionFrom returns the owner's open transaction carried by ctx, if any.
+func transactionFrom(ctx context.Context, owner any) (*activeTransaction, bool) {
+	active, ok := ctx.Value(txContextKey{}).(*activeTransaction)
+	if !ok || active.owner != owner {
+		return nil, false
+	}
+	return active, true
+}
+
+// InTransaction reports whether ctx carries a transaction opened by
+// GraphDriverSession.ExecuteWrite. Driver methods called with such a context
+// join the transaction instead of committing on their own.
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txContextKey{}).(*activeTransaction)
+	return ok
+}
+
+// runManagedWork runs work in the transaction carried by ctx, or in a new
+// session transaction of the given access mode when there is none.
+func runManagedWork(ctx context.Context, client neo4j.DriverWithContext, database string, owner any, write bool, work neo4j.ManagedTransactionWork) (result any, err error) {
+	operation := "read"
+	if write {
+		operation = "write"
+	}
+	ctx, done := traceQuery(ctx, providerOf(owner), operation)
+	defer func() { done(err) }()
+
+	if active, ok := transactionFrom(ctx, owner); ok {
+		return work(active.tx)
+	}
+
+	session := client.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database})
+	defer session.Close(ctx)
+
+	if write {
+		return session.ExecuteWrite(ctx, work)
+	}
+	return session.ExecuteRead(ctx, work)
+}
+
+// collectQueryResult returns the records, summary and keys of a Bolt result.
+func collectQueryResult(ctx context.Context, result neo4j.ResultWithContext) (interface{}, interface{}, interface{}, error) {
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	summary, err := result.Consume(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keys, err := result.Keys()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return records, summary, keys, nil
+}
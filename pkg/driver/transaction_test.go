@@ -0,0 +1,55 @@
+package driver
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTransactionContext(t *testing.T) {
+	t.Parallel()
+
+	owner := &Neo4jDriver{}
+	other := &MemgraphDriver{}
+
+	ctx := context.Background()
+	if InTransaction(ctx) {
+		t.Fatal("background context should not be in a transaction")
+	}
+
+	txCtx := withTransaction(ctx, owner, nil)
+	if !InTransaction(txCtx) {
+		t.Error("InTransaction should be true for a transaction context")
+	}
+	if _, ok := transactionFrom(txCtx, owner); !ok {
+		t.Error("transactionFrom should find the owner's transaction")
+	}
+	if _, ok := transactionFrom(txCtx, other); ok {
+		t.Error("transactionFrom should ignore transactions owned by another driver")
+	}
+}
+
+func TestTransactionCommitHooks(t *testing.T) {
+	t.Parallel()
+
+	owner := &Neo4jDriver{}
+	var ran []int
+
+	committed, _ := transactionFrom(withTransaction(context.Background(), owner, nil), owner)
+	committed.afterCommit(func() { ran = append(ran, 1) })
+	committed.afterCommit(func() { ran = append(ran, 2) })
+	if len(ran) != 0 {
+		t.Fatal("commit hooks should not run before the transaction commits")
+	}
+	committed.committed()
+	if len(ran) != 2 || ran[0] != 1 || ran[1] != 2 {
+		t.Errorf("hooks ran %v, want [1 2]", ran)
+	}
+	committed.committed()
+	if len(ran) != 2 {
+		t.Error("commit hooks should run only once")
+	}
+
+	// A rolled-back transaction never calls committed, so its hooks are dropped
+	rolledBack, _ := transactionFrom(withTransaction(context.Background(), owner, nil), owner)
+	rolledBack.afterCommit(func() { t.Error("hook of a rolled-back transaction ran") })
+}
@@ -191,6 +191,11 @@ func (a *AzureOpenAIEmbedder) Dimensions() int {
 	return a.config.Dimensions
 }
 
+// ModelID identifies the embedding model.
+func (a *AzureOpenAIEmbedder) ModelID() string {
+	return "azure/" + a.deploymentID
+}
+
 // Close cleans up any resources.
 func (a *AzureOpenAIEmbedder) Close() error {
 	// Nothing to clean up for HTTP client
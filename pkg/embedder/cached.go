@@ -0,0 +1,192 @@
+package embedder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/cache"
+	"github.com/soundprediction/predicato/pkg/nlp"
+)
+
+// Forgetter is implemented by embedders that keep embeddings of the texts they
+// embed, so that the embeddings of purged text can be dropped.
+type Forgetter interface {
+	// Forget removes any stored embeddings of texts.
+	Forget(ctx context.Context, texts []string) error
+}
+
+// CachedClient wraps a Client and stores embeddings in a cache keyed by a
+// hash of the text, so that identical text is embedded only once. If the
+// wrapped client implements ModelIdentifier the keys include its model ID, so
+// that a cache shared across an embedder switch never returns vectors of the
+// old model.
+type CachedClient struct {
+	client Client
+	cache  cache.Cache
+	ttl    time.Duration
+}
+
+// defaultCacheTTL is used when NewCachedClient is given no TTL.
+const defaultCacheTTL = 30 * 24 * time.Hour
+
+// NewCachedClient creates a CachedClient. Entries expire after ttl, or after
+// 30 days if ttl is not positive.
+func NewCachedClient(client Client, c cache.Cache, ttl time.Duration) *CachedClient {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &CachedClient{client: client, cache: c, ttl: ttl}
+}
+
+// Embed returns cached embeddings where available and embeds the rest.
+func (c *CachedClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	var missing []string
+	var missingIdx []int
+	for i, text := range texts {
+		if embedding, ok := c.lookup(text); ok {
+			embeddings[i] = embedding
+			continue
+		}
+		missing = append(missing, text)
+		missingIdx = append(missingIdx, i)
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	computed, err := c.client.Embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	if len(computed) != len(missing) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d texts", len(computed), len(missing))
+	}
+	for i, embedding := range computed {
+		embeddings[missingIdx[i]] = embedding
+		c.store(missing[i], embedding)
+	}
+	return embeddings, nil
+}
+
+// EmbedSingle returns the cached embedding of text, embedding it if needed.
+func (c *CachedClient) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	if embedding, ok := c.lookup(text); ok {
+		return embedding, nil
+	}
+	embedding, err := c.client.EmbedSingle(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.store(text, embedding)
+	return embedding, nil
+}
+
+// ModelID returns the model ID of the wrapped client.
+func (c *CachedClient) ModelID() string {
+	return ModelID(c.client)
+}
+
+// Dimensions returns the dimensions of the wrapped client.
+func (c *CachedClient) Dimensions() int {
+	return c.client.Dimensions()
+}
+
+// Close closes the wrapped client. The cache is owned by the caller.
+func (c *CachedClient) Close() error {
+	return c.client.Close()
+}
+
+// IsLocalFor implements nlp.LocalityReporter for the wrapped client.
+func (c *CachedClient) IsLocalFor(usage string) bool {
+	return nlp.IsLocalFor(c.client, usage)
+}
+
+// Forget removes the cached embeddings of texts: the entries of the wrapped
+// model, entries stored before keys were scoped by model and, when the cache
+// can list its keys, the entries of every other model sharing the cache.
+func (c *CachedClient) Forget(ctx context.Context, texts []string) error {
+	keys := make(map[string]bool, 2*len(texts))
+	hashes := make(map[string]bool, len(texts))
+	for _, text := range texts {
+		keys[c.key(text)] = true
+		keys[CacheKey(text)] = true
+		hashes[textHash(text)] = true
+	}
+	if lister, ok := c.cache.(cache.KeyLister); ok && len(hashes) > 0 {
+		cached, err := lister.Keys(cacheKeyPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to list cached embeddings: %w", err)
+		}
+		for _, key := range cached {
+			if hashes[key[strings.LastIndex(key, ":")+1:]] {
+				keys[key] = true
+			}
+		}
+	}
+
+	for key := range keys {
+		if err := c.cache.Delete(key); err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+			return fmt.Errorf("failed to delete cached embedding: %w", err)
+		}
+	}
+	return nil
+}
+
+// cacheKeyPrefix starts every embedding cache key.
+const cacheKeyPrefix = "embedding:"
+
+// textHash returns the hash of text that ends its cache keys.
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// CacheKey returns the cache key under which the embedding of text is stored
+// for embedders without a model ID.
+func CacheKey(text string) string {
+	return cacheKeyPrefix + textHash(text)
+}
+
+// key returns the cache key of text, scoped to the model of the wrapped
+// client when it identifies one.
+func (c *CachedClient) key(text string) string {
+	if identifier, ok := c.client.(ModelIdentifier); ok {
+		return ModelCacheKey(identifier.ModelID(), text)
+	}
+	return CacheKey(text)
+}
+
+// ModelCacheKey returns the cache key under which the embedding of text by
+// the given model is stored.
+func ModelCacheKey(modelID, text string) string {
+	return cacheKeyPrefix + modelID + ":" + textHash(text)
+}
+
+func (c *CachedClient) lookup(text string) ([]float32, bool) {
+	data, err := c.cache.Get(c.key(text))
+	if err != nil || len(data)%4 != 0 {
+		return nil, false
+	}
+	embedding := make([]float32, len(data)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return embedding, true
+}
+
+// store caches an embedding. Failures only cost a later recomputation.
+func (c *CachedClient) store(text string, embedding []float32) {
+	data := make([]byte, len(embedding)*4)
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+	_ = c.cache.Set(c.key(text), data, c.ttl)
+}
@@ -2,6 +2,7 @@ package embedder
 
 import (
 	"context"
+	"fmt"
 )
 
 // Client defines the interface for embedding operations.
@@ -19,6 +20,23 @@ type Client interface {
 	Close() error
 }
 
+// ModelIdentifier is implemented by embedders that can name their model.
+// Vectors of different models are not comparable, so the model is recorded
+// with stored vectors.
+type ModelIdentifier interface {
+	// ModelID identifies the embedding model, e.g. "openai/text-embedding-3-small".
+	ModelID() string
+}
+
+// ModelID returns the model ID of c, or its Go type if it does not implement
+// ModelIdentifier.
+func ModelID(c Client) string {
+	if identifier, ok := c.(ModelIdentifier); ok {
+		return identifier.ModelID()
+	}
+	return fmt.Sprintf("%T", c)
+}
+
 // Config holds configuration for embedding clients.
 type Config struct {
 	Model      string            `json:"model"`
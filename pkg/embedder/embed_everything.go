@@ -61,6 +61,11 @@ func (e *EmbedEverythingClient) Dimensions() int {
 	return e.config.Dimensions
 }
 
+// ModelID identifies the embedding model.
+func (e *EmbedEverythingClient) ModelID() string {
+	return "embedeverything/" + e.config.Model
+}
+
 // Close cleans up any resources.
 func (e *EmbedEverythingClient) Close() error {
 	e.client.Close()
@@ -72,3 +77,8 @@ func (e *EmbedEverythingClient) Close() error {
 func (e *EmbedEverythingClient) GetCapabilities() []nlp.TaskCapability {
 	return []nlp.TaskCapability{nlp.TaskEmbedding}
 }
+
+// IsLocalFor implements nlp.LocalityReporter; EmbedEverything models always run locally.
+func (e *EmbedEverythingClient) IsLocalFor(usage string) bool {
+	return true
+}
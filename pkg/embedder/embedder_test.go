@@ -2,8 +2,11 @@ package embedder_test
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/soundprediction/predicato/pkg/cache"
 	"github.com/soundprediction/predicato/pkg/embedder"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -173,3 +176,129 @@ func TestEmbedderConfig(t *testing.T) {
 		})
 	}
 }
+
+type memoryCache map[string][]byte
+
+func (m memoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+func (m memoryCache) Get(key string) ([]byte, error) {
+	if value, ok := m[key]; ok {
+		return value, nil
+	}
+	return nil, cache.ErrKeyNotFound
+}
+
+func (m memoryCache) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m memoryCache) Keys(prefix string) ([]string, error) {
+	var keys []string
+	for key := range m {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m memoryCache) Close() error { return nil }
+
+type countingEmbedder struct {
+	calls int
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	c.calls++
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = []float32{float32(len(text)), 0.5}
+	}
+	return embeddings, nil
+}
+
+func (c *countingEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := c.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (c *countingEmbedder) Dimensions() int { return 2 }
+
+func (c *countingEmbedder) Close() error { return nil }
+
+func TestCachedClientForget(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingEmbedder{}
+	store := memoryCache{}
+	client := embedder.NewCachedClient(inner, store, time.Hour)
+
+	first, err := client.Embed(ctx, []string{"alice", "bob"})
+	require.NoError(t, err)
+	second, err := client.Embed(ctx, []string{"bob", "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, first[0], second[1])
+	assert.Equal(t, []float32{3, 0.5}, second[0])
+
+	var forgetter embedder.Forgetter = client
+	require.NoError(t, forgetter.Forget(ctx, []string{"alice"}))
+	assert.NotContains(t, store, embedder.CacheKey("alice"))
+	assert.Contains(t, store, embedder.CacheKey("bob"))
+
+	_, err = client.EmbedSingle(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+type namedEmbedder struct {
+	countingEmbedder
+	model string
+}
+
+func (n *namedEmbedder) ModelID() string { return n.model }
+
+func TestCachedClientScopesKeysByModel(t *testing.T) {
+	ctx := context.Background()
+	store := memoryCache{}
+	small := &namedEmbedder{model: "openai/small"}
+	large := &namedEmbedder{model: "openai/large"}
+
+	_, err := embedder.NewCachedClient(small, store, time.Hour).EmbedSingle(ctx, "alice")
+	require.NoError(t, err)
+	client := embedder.NewCachedClient(large, store, time.Hour)
+	_, err = client.EmbedSingle(ctx, "alice")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, large.calls, "a cache shared with another model must not be reused")
+	assert.Contains(t, store, embedder.ModelCacheKey("openai/small", "alice"))
+	assert.Equal(t, "openai/large", embedder.ModelID(client))
+	assert.Equal(t, "*embedder_test.countingEmbedder", embedder.ModelID(&countingEmbedder{}))
+}
+
+func TestCachedClientForgetsEveryModel(t *testing.T) {
+	ctx := context.Background()
+	store := memoryCache{}
+	small := &namedEmbedder{model: "openai/small"}
+	large := &namedEmbedder{model: "openai/large"}
+
+	_, err := embedder.NewCachedClient(small, store, time.Hour).Embed(ctx, []string{"alice", "bob"})
+	require.NoError(t, err)
+	_, err = embedder.NewCachedClient(&countingEmbedder{}, store, time.Hour).EmbedSingle(ctx, "alice")
+	require.NoError(t, err)
+	client := embedder.NewCachedClient(large, store, time.Hour)
+	_, err = client.EmbedSingle(ctx, "alice")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Forget(ctx, []string{"alice"}))
+	assert.NotContains(t, store, embedder.ModelCacheKey("openai/large", "alice"))
+	assert.NotContains(t, store, embedder.ModelCacheKey("openai/small", "alice"))
+	assert.NotContains(t, store, embedder.CacheKey("alice"))
+	assert.Contains(t, store, embedder.ModelCacheKey("openai/small", "bob"))
+}
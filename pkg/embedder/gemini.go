@@ -240,6 +240,11 @@ func (g *GeminiEmbedder) Dimensions() int {
 	return g.config.Dimensions
 }
 
+// ModelID identifies the embedding model.
+func (g *GeminiEmbedder) ModelID() string {
+	return "gemini/" + g.config.Model
+}
+
 // Close cleans up any resources.
 func (g *GeminiEmbedder) Close() error {
 	// Nothing to clean up for HTTP client
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
 )
 
 // Constants for retry configuration
@@ -114,6 +116,17 @@ func (e *OpenAIEmbedder) Dimensions() int {
 	return e.config.Dimensions
 }
 
+// ModelID identifies the embedding model.
+func (e *OpenAIEmbedder) ModelID() string {
+	return "openai/" + e.config.Model
+}
+
+// IsLocalFor implements nlp.LocalityReporter; an OpenAI-compatible server is
+// local when its base URL is on this machine.
+func (e *OpenAIEmbedder) IsLocalFor(usage string) bool {
+	return nlp.IsLocalEndpoint(e.config.BaseURL)
+}
+
 // Close cleans up resources (no-op for OpenAI embedder).
 func (e *OpenAIEmbedder) Close() error {
 	return nil
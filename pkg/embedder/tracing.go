@@ -0,0 +1,65 @@
+package embedder
+
+import (
+	"context"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/telemetry"
+)
+
+// TracingClient wraps a Client to record a span and latency metrics for
+// every embedding request.
+type TracingClient struct {
+	client Client
+}
+
+// NewTracingClient creates a wrapper client.
+func NewTracingClient(client Client) *TracingClient {
+	return &TracingClient{client: client}
+}
+
+// Embed implements Client
+func (c *TracingClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := ModelID(c.client)
+	ctx, span := telemetry.StartSpan(ctx, "embedder.Embed",
+		telemetry.AttrModel.String(model),
+		telemetry.AttrCount.Int(len(texts)),
+	)
+	start := time.Now()
+	embeddings, err := c.client.Embed(ctx, texts)
+	telemetry.ObserveEmbedderRequest(model, len(texts), time.Since(start), err)
+	telemetry.EndSpan(span, err)
+	return embeddings, err
+}
+
+// EmbedSingle implements Client
+func (c *TracingClient) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	model := ModelID(c.client)
+	ctx, span := telemetry.StartSpan(ctx, "embedder.EmbedSingle", telemetry.AttrModel.String(model))
+	start := time.Now()
+	embedding, err := c.client.EmbedSingle(ctx, text)
+	telemetry.ObserveEmbedderRequest(model, 1, time.Since(start), err)
+	telemetry.EndSpan(span, err)
+	return embedding, err
+}
+
+// ModelID returns the model ID of the wrapped client.
+func (c *TracingClient) ModelID() string {
+	return ModelID(c.client)
+}
+
+// Dimensions returns the dimensions of the wrapped client.
+func (c *TracingClient) Dimensions() int {
+	return c.client.Dimensions()
+}
+
+// Close closes the wrapped client.
+func (c *TracingClient) Close() error {
+	return c.client.Close()
+}
+
+// IsLocalFor implements nlp.LocalityReporter for the wrapped client.
+func (c *TracingClient) IsLocalFor(usage string) bool {
+	return nlp.IsLocalFor(c.client, usage)
+}
@@ -176,6 +176,11 @@ func (v *VoyageEmbedder) Dimensions() int {
 	return v.config.Dimensions
 }
 
+// ModelID identifies the embedding model.
+func (v *VoyageEmbedder) ModelID() string {
+	return "voyage/" + v.config.Model
+}
+
 // Close cleans up any resources.
 func (v *VoyageEmbedder) Close() error {
 	// Nothing to clean up for HTTP client
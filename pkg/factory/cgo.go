@@ -0,0 +1,26 @@
+//go:build cgo
+
+package factory
+
+import (
+	"fmt"
+
+	"github.com/soundprediction/predicato/pkg/driver"
+	"github.com/soundprediction/predicato/pkg/embedder"
+)
+
+func newLadybugDriver(uri string) (driver.GraphDriver, error) {
+	graphDriver, err := driver.NewLadybugDriver(uri, 16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ladybug driver: %w", err)
+	}
+	return graphDriver, nil
+}
+
+func newEmbedEverythingEmbedder(config *embedder.Config) (embedder.Client, error) {
+	client, err := embedder.NewEmbedEverythingClient(&embedder.EmbedEverythingConfig{Config: config})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EmbedEverything embedder: %w", err)
+	}
+	return client, nil
+}
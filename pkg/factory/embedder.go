@@ -0,0 +1,87 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/soundprediction/predicato/pkg/cache"
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/nlp"
+)
+
+// NewEmbedder creates the embedder described by cfg, or nil if no provider is
+// configured. Hosted providers require an API key. With a CachePath, embeddings are cached in Badger so that they
+// can be reused and, on purge, forgotten. Provider requests are traced with
+// OpenTelemetry and recorded in the telemetry metrics.
+func NewEmbedder(cfg config.EmbeddingConfig) (embedder.Client, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	if cfg.APIKey == "" && requiresAPIKey(cfg.Provider, cfg.BaseURL) {
+		return nil, fmt.Errorf("embedding provider %s requires an API key", cfg.Provider)
+	}
+	base := &embedder.Config{
+		Model:      cfg.Model,
+		BaseURL:    cfg.BaseURL,
+		Dimensions: cfg.Dimensions,
+	}
+
+	var client embedder.Client
+	switch cfg.Provider {
+	case string(nlp.ProviderOpenAI), string(nlp.ProviderOpenAICompatible):
+		apiKey := cfg.APIKey
+		if apiKey == "" && cfg.BaseURL != "" {
+			// Some OpenAI-compatible services require a non-empty key
+			apiKey = "dummy"
+		}
+		client = embedder.NewOpenAIEmbedder(apiKey, *base)
+	case "voyage":
+		client = embedder.NewVoyageEmbedder(&embedder.VoyageConfig{Config: base, APIKey: cfg.APIKey})
+	case string(nlp.ProviderGoogle), "gemini":
+		client = embedder.NewGeminiEmbedder(&embedder.GeminiConfig{Config: base, APIKey: cfg.APIKey})
+	case string(nlp.ProviderAzure):
+		client = embedder.NewAzureOpenAIEmbedder(&embedder.AzureOpenAIConfig{Config: base, APIKey: cfg.APIKey, DeploymentID: cfg.DeploymentID})
+	case string(nlp.ProviderEmbedEverything):
+		var err error
+		client, err = newEmbedEverythingEmbedder(base)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s", cfg.Provider)
+	}
+
+	// Traced inside the cache, so spans show only requests to the provider
+	client = embedder.NewTracingClient(client)
+
+	if cfg.CachePath == "" {
+		return client, nil
+	}
+	embeddingCache, err := cache.NewBadgerCache(cfg.CachePath)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to open embedding cache: %w", err)
+	}
+	return embedder.NewCachedClient(client, embeddingCache, 0), nil
+}
+
+// EmbeddingConfig returns the embedding config, falling back to the
+// "embedding" entry of the NLP models when no embedding provider is set.
+func EmbeddingConfig(cfg *config.Config) config.EmbeddingConfig {
+	if cfg.Embedding.Provider != "" {
+		return cfg.Embedding
+	}
+	model, ok := cfg.NLP.Models[ModelEmbedding]
+	if !ok {
+		return cfg.Embedding
+	}
+	embeddingConfig := cfg.Embedding
+	embeddingConfig.Provider = model.Provider
+	embeddingConfig.Model = model.Model
+	embeddingConfig.APIKey = model.APIKey
+	embeddingConfig.DeploymentID = model.DeploymentID
+	if model.BaseURL != "embedeverything://" {
+		embeddingConfig.BaseURL = model.BaseURL
+	}
+	return embeddingConfig
+}
@@ -0,0 +1,110 @@
+package factory
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/config"
+)
+
+func TestNewEmbedder(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.EmbeddingConfig
+		want    string // type of the client, empty for none
+		wantErr string
+	}{
+		{"no provider", config.EmbeddingConfig{}, "", ""},
+		{"openai", config.EmbeddingConfig{Provider: "openai", Model: "text-embedding-3-small", APIKey: "key"}, "*embedder.TracingClient", ""},
+		{"openai server without key", config.EmbeddingConfig{Provider: "openai", BaseURL: "http://localhost:8080/v1"}, "*embedder.TracingClient", ""},
+		{"openai_compatible without key", config.EmbeddingConfig{Provider: "openai_compatible", BaseURL: "http://localhost:11434/v1"}, "*embedder.TracingClient", ""},
+		{"voyage", config.EmbeddingConfig{Provider: "voyage", APIKey: "key"}, "*embedder.TracingClient", ""},
+		{"google", config.EmbeddingConfig{Provider: "google", APIKey: "key"}, "*embedder.TracingClient", ""},
+		{"gemini", config.EmbeddingConfig{Provider: "gemini", APIKey: "key"}, "*embedder.TracingClient", ""},
+		{"azure", config.EmbeddingConfig{Provider: "azure", APIKey: "key", DeploymentID: "ada"}, "*embedder.TracingClient", ""},
+
+		{"openai without key", config.EmbeddingConfig{Provider: "openai"}, "", "requires an API key"},
+		{"voyage without key", config.EmbeddingConfig{Provider: "voyage"}, "", "requires an API key"},
+		{"gemini without key", config.EmbeddingConfig{Provider: "gemini"}, "", "requires an API key"},
+		{"azure without key", config.EmbeddingConfig{Provider: "azure", DeploymentID: "ada"}, "", "requires an API key"},
+		{"unknown provider", config.EmbeddingConfig{Provider: "cohere", APIKey: "key"}, "", "unsupported embedding provider: cohere"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewEmbedder(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewEmbedder = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewEmbedder: %v", err)
+			}
+			if tt.want == "" {
+				if client != nil {
+					t.Errorf("NewEmbedder = %T, want none", client)
+				}
+				return
+			}
+			defer client.Close()
+			if got := fmt.Sprintf("%T", client); got != tt.want {
+				t.Errorf("client type = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEmbedder_Cache(t *testing.T) {
+	client, err := NewEmbedder(config.EmbeddingConfig{
+		Provider:  "openai",
+		APIKey:    "key",
+		CachePath: filepath.Join(t.TempDir(), "embeddings"),
+	})
+	if err != nil {
+		t.Fatalf("NewEmbedder: %v", err)
+	}
+	defer client.Close()
+	if got := fmt.Sprintf("%T", client); got != "*embedder.CachedClient" {
+		t.Errorf("client type = %s, want the cache around the provider", got)
+	}
+}
+
+func TestEmbeddingConfig(t *testing.T) {
+	models := map[string]config.NLPModelConfig{
+		ModelEmbedding: {Provider: "openai", Model: "text-embedding-3-small", APIKey: "key", BaseURL: "http://localhost:8080/v1"},
+	}
+
+	// The embedding section wins when it names a provider
+	cfg := &config.Config{
+		Embedding: config.EmbeddingConfig{Provider: "voyage", Model: "voyage-3", APIKey: "voyage-key"},
+		NLP:       config.NLPConfig{Models: models},
+	}
+	if got := EmbeddingConfig(cfg); got.Provider != "voyage" || got.Model != "voyage-3" {
+		t.Errorf("EmbeddingConfig = %s %s, want the embedding section", got.Provider, got.Model)
+	}
+
+	// Otherwise the embedding model supplies the provider, keeping the rest
+	cfg.Embedding = config.EmbeddingConfig{Dimensions: 256}
+	got := EmbeddingConfig(cfg)
+	if got.Provider != "openai" || got.Model != "text-embedding-3-small" || got.APIKey != "key" || got.BaseURL != "http://localhost:8080/v1" {
+		t.Errorf("EmbeddingConfig = %+v, want the embedding model", got)
+	}
+	if got.Dimensions != 256 {
+		t.Errorf("Dimensions = %d, want 256 from the embedding section", got.Dimensions)
+	}
+
+	// The in-process marker URL is not passed on as a base URL
+	models[ModelEmbedding] = config.NLPModelConfig{Provider: "embedeverything", Model: "bge-small", BaseURL: "embedeverything://"}
+	if got := EmbeddingConfig(cfg); got.Provider != "embedeverything" || got.BaseURL != "" {
+		t.Errorf("EmbeddingConfig = %s at %q, want embedeverything without a base URL", got.Provider, got.BaseURL)
+	}
+
+	// Without either, nothing is configured
+	if got := EmbeddingConfig(&config.Config{}); got.Provider != "" {
+		t.Errorf("EmbeddingConfig = %s, want no provider", got.Provider)
+	}
+}
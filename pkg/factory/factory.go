@@ -0,0 +1,339 @@
+// Package factory builds a fully wired predicato Client from a config.Config:
+// the graph driver, the NLP clients for every ingestion step (with routing and
+// retry, circuit-breaker and token-tracking decorators), the embedder, the
+// search cross-encoder and the fact store. The server, the MCP command and
+// applications embedding predicato share this code path.
+package factory
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/soundprediction/predicato"
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/crossencoder"
+	"github.com/soundprediction/predicato/pkg/driver"
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/prompts"
+	"github.com/soundprediction/predicato/pkg/telemetry"
+	"github.com/soundprediction/predicato/pkg/utils/maintenance"
+)
+
+// Options adjusts how a client is built. All fields are optional.
+type Options struct {
+	// Logger defaults to a text logger on stderr.
+	Logger *slog.Logger
+	// Driver is used instead of building one from the database config.
+	Driver driver.GraphDriver
+	// GroupID defaults to the server config's group, or "default".
+	GroupID string
+	// AllowedGroupIDs defaults to the server config's allowed groups.
+	AllowedGroupIDs []string
+}
+
+// Components are the parts a Client is built from. Use Build to adjust them,
+// for example to set Config.EntityTypes, before calling NewClientFromComponents.
+type Components struct {
+	Driver       driver.GraphDriver
+	NLP          nlp.Client
+	Embedder     embedder.Client
+	CrossEncoder crossencoder.Client
+	// Config holds the NLP step models, fact store, cross-encoder and usage policy.
+	Config *predicato.Config
+	Logger *slog.Logger
+}
+
+// NewClient builds a Client from cfg.
+func NewClient(cfg *config.Config, opts *Options) (*predicato.Client, error) {
+	components, err := Build(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromComponents(components)
+}
+
+// NewClientFromComponents creates a Client from components returned by Build.
+func NewClientFromComponents(components *Components) (*predicato.Client, error) {
+	client, err := predicato.NewClient(components.Driver, components.NLP, components.Embedder, components.Config, components.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Predicato client: %w", err)
+	}
+	return client, nil
+}
+
+// Build creates the components described by cfg.
+func Build(cfg *config.Config, opts *Options) (*Components, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	}
+
+	// Errors are recorded to Parquet alongside token usage
+	if cfg.Telemetry.ParquetPath != "" {
+		parquetHandler, err := telemetry.NewParquetHandler(logger.Handler(), cfg.Telemetry.ParquetPath)
+		if err != nil {
+			logger.Warn("Failed to initialize error tracking", "error", err)
+		} else {
+			logger = slog.New(parquetHandler)
+		}
+	}
+
+	graphDriver := opts.Driver
+	if graphDriver == nil {
+		var err error
+		graphDriver, err = NewDriver(cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	components, err := buildClients(cfg, logger)
+	if err != nil {
+		if opts.Driver == nil {
+			_ = graphDriver.Close()
+		}
+		return nil, err
+	}
+	components.Driver = graphDriver
+	components.Logger = logger
+
+	groupID := opts.GroupID
+	if groupID == "" {
+		groupID = cfg.Server.GroupID
+	}
+	if groupID == "" {
+		groupID = "default"
+	}
+	allowedGroupIDs := opts.AllowedGroupIDs
+	if allowedGroupIDs == nil {
+		allowedGroupIDs = cfg.Server.AllowedGroupIDs
+	}
+	components.Config.GroupID = groupID
+	components.Config.AllowedGroupIDs = allowedGroupIDs
+	components.Config.TimeZone = time.UTC
+	return components, nil
+}
+
+// buildClients creates the NLP clients, embedder and cross-encoder and the
+// client config referring to them.
+func buildClients(cfg *config.Config, logger *slog.Logger) (*Components, error) {
+	models, err := NewNLPClients(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	embedderClient, err := NewEmbedder(EmbeddingConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	crossEncoder, err := NewCrossEncoder(cfg.CrossEncoder, models.Default, embedderClient)
+	if err != nil {
+		return nil, err
+	}
+
+	predicatoConfig := &predicato.Config{
+		NlpModels:    models.Steps,
+		CrossEncoder: crossEncoder,
+		UsagePolicy:  usagePolicy(cfg.NLP),
+	}
+	if err := applyFactStoreConfig(predicatoConfig, cfg.FactStore, embeddingDimensions(cfg, embedderClient)); err != nil {
+		return nil, err
+	}
+	if predicatoConfig.EntityExtractor, err = NewEnsembleExtractor(cfg.NLP, models, logger); err != nil {
+		return nil, err
+	}
+
+	return &Components{
+		NLP:          models.Default,
+		Embedder:     embedderClient,
+		CrossEncoder: crossEncoder,
+		Config:       predicatoConfig,
+	}, nil
+}
+
+// NewDriver creates the graph driver described by cfg.
+func NewDriver(cfg config.DatabaseConfig) (driver.GraphDriver, error) {
+	switch cfg.Driver {
+	case "ladybug", "":
+		return newLadybugDriver(cfg.URI)
+	case "neo4j":
+		graphDriver, err := driver.NewNeo4jDriver(cfg.URI, cfg.Username, cfg.Password, cfg.Database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create neo4j driver: %w", err)
+		}
+		return graphDriver, nil
+	case "memgraph":
+		graphDriver, err := driver.NewMemgraphDriver(cfg.URI, cfg.Username, cfg.Password, cfg.Database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create memgraph driver: %w", err)
+		}
+		return graphDriver, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s (supported: ladybug, neo4j, memgraph)", cfg.Driver)
+	}
+}
+
+// NewCrossEncoder creates the search reranker described by cfg, or nil if no
+// provider is configured. The openai provider ranks with nlProcessor and the
+// embedding provider with embedderClient.
+func NewCrossEncoder(cfg config.CrossEncoderConfig, nlProcessor nlp.Client, embedderClient embedder.Client) (crossencoder.Client, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	base := crossencoder.Config{Model: cfg.Model, BatchSize: cfg.BatchSize}
+	clientConfig := crossencoder.ClientConfig{
+		Provider:       crossencoder.Provider(cfg.Provider),
+		Config:         base,
+		LLMClient:      nlProcessor,
+		EmbedderClient: embedderClient,
+	}
+	if clientConfig.Provider == crossencoder.ProviderReranker {
+		clientConfig.RerankerConfig = &crossencoder.RerankerConfig{Config: base, BaseURL: cfg.BaseURL, APIKey: cfg.APIKey}
+	}
+	client, err := crossencoder.NewClient(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cross-encoder: %w", err)
+	}
+	return client, nil
+}
+
+// NewEnsembleExtractor creates the entity extractor merging the models named
+// by cfg.EnsembleModels, or returns nil if none are named. Disputed entities
+// are adjudicated by the node extraction model, or the default model.
+func NewEnsembleExtractor(cfg config.NLPConfig, models *NLPClients, logger *slog.Logger) (nlp.EntityExtractor, error) {
+	if len(cfg.EnsembleModels) == 0 {
+		return nil, nil
+	}
+	library := prompts.NewLibrary()
+	sources := make([]maintenance.EnsembleSource, 0, len(cfg.EnsembleModels))
+	for _, name := range cfg.EnsembleModels {
+		client, ok := models.Named[name]
+		if !ok {
+			return nil, fmt.Errorf("ensemble model %q is not configured", name)
+		}
+		sources = append(sources, maintenance.EnsembleSource{
+			Name:      name,
+			Extractor: maintenance.NewClientExtractor(client, library, logger),
+		})
+	}
+
+	ensemble, err := maintenance.NewEnsembleExtractor(sources, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ensemble extractor: %w", err)
+	}
+	switch maintenance.EnsembleStrategy(cfg.EnsembleStrategy) {
+	case "", maintenance.EnsembleVote:
+	case maintenance.EnsembleAdjudicate:
+		ensemble.Strategy = maintenance.EnsembleAdjudicate
+		ensemble.Adjudicator = models.Steps.NodeExtraction
+		if ensemble.Adjudicator == nil {
+			ensemble.Adjudicator = models.Default
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ensemble strategy: %s (supported: vote, adjudicate)", cfg.EnsembleStrategy)
+	}
+	if cfg.EnsembleQuorum > 0 {
+		ensemble.Quorum = cfg.EnsembleQuorum
+	}
+	ensemble.Prompts = library
+	return ensemble, nil
+}
+
+// applyFactStoreConfig sets the fact store of predicatoConfig from cfg.
+func applyFactStoreConfig(predicatoConfig *predicato.Config, cfg config.FactStoreConfig, dimensions int) error {
+	if cfg.ConnectionString == "" {
+		return nil
+	}
+	if cfg.EmbeddingDimensions > 0 {
+		dimensions = cfg.EmbeddingDimensions
+	}
+	switch cfg.Type {
+	case "dolt":
+		predicatoConfig.FactsDBURL = cfg.ConnectionString
+	case "", string(factstore.FactStoreTypeDoltGres), string(factstore.FactStoreTypePostgres):
+		predicatoConfig.FactStoreConfig = &factstore.FactStoreConfig{
+			Type:                factstore.FactStoreType(cfg.Type),
+			ConnectionString:    cfg.ConnectionString,
+			EmbeddingDimensions: dimensions,
+			DataDir:             cfg.DataDir,
+			MaxConnections:      cfg.MaxConnections,
+		}
+	default:
+		return fmt.Errorf("unsupported factstore type: %s (supported: postgres, doltgres, dolt)", cfg.Type)
+	}
+	return nil
+}
+
+// NewFactStore opens the fact store described by cfg without initializing it.
+// dimensions is the embedding size used when cfg sets none.
+func NewFactStore(cfg config.FactStoreConfig, dimensions int) (factstore.FactsDB, error) {
+	if cfg.ConnectionString == "" {
+		return nil, fmt.Errorf("factstore connection string is required")
+	}
+	if cfg.EmbeddingDimensions > 0 {
+		dimensions = cfg.EmbeddingDimensions
+	}
+	switch cfg.Type {
+	case "dolt":
+		doltConfig := factstore.DefaultDoltDBConfig()
+		doltConfig.EmbeddingDimensions = dimensions
+		if cfg.MaxConnections > 0 {
+			doltConfig.MaxOpenConns = cfg.MaxConnections
+		}
+		facts, err := factstore.NewDoltDBWithConfig(cfg.ConnectionString, doltConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open dolt factstore: %w", err)
+		}
+		return facts, nil
+	case "", string(factstore.FactStoreTypeDoltGres), string(factstore.FactStoreTypePostgres):
+		facts, err := factstore.NewFactsDB(&factstore.FactStoreConfig{
+			Type:                factstore.FactStoreType(cfg.Type),
+			ConnectionString:    cfg.ConnectionString,
+			EmbeddingDimensions: dimensions,
+			DataDir:             cfg.DataDir,
+			MaxConnections:      cfg.MaxConnections,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open factstore: %w", err)
+		}
+		return facts, nil
+	default:
+		return nil, fmt.Errorf("unsupported factstore type: %s (supported: postgres, doltgres, dolt)", cfg.Type)
+	}
+}
+
+// embeddingDimensions returns the configured embedding size, falling back to
+// the embedder's.
+func embeddingDimensions(cfg *config.Config, embedderClient embedder.Client) int {
+	if cfg.Embedding.Dimensions > 0 {
+		return cfg.Embedding.Dimensions
+	}
+	if embedderClient != nil {
+		return embedderClient.Dimensions()
+	}
+	return 0
+}
+
+// usagePolicy converts the routing policy of cfg, or returns nil if none is set.
+func usagePolicy(cfg config.NLPConfig) *predicato.UsagePolicy {
+	if len(cfg.GroupUsage) == 0 && cfg.DefaultUsage == "" {
+		return nil
+	}
+	return &predicato.UsagePolicy{
+		GroupUsage:      cfg.GroupUsage,
+		DefaultUsage:    cfg.DefaultUsage,
+		SensitiveUsages: cfg.SensitiveUsages,
+		Strict:          cfg.StrictRouting,
+	}
+}
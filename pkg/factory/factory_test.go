@@ -0,0 +1,225 @@
+package factory
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato"
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/factstore"
+)
+
+func TestNewDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.DatabaseConfig
+		want    string // type of the driver
+		wantErr string
+	}{
+		// Bolt drivers connect lazily, so no server is needed
+		{"neo4j", config.DatabaseConfig{Driver: "neo4j", URI: "bolt://localhost:7687"}, "*driver.Neo4jDriver", ""},
+		{"memgraph", config.DatabaseConfig{Driver: "memgraph", URI: "bolt://localhost:7687"}, "*driver.MemgraphDriver", ""},
+		{"neo4j with invalid URI", config.DatabaseConfig{Driver: "neo4j", URI: "ftp://localhost"}, "", "failed to create neo4j driver"},
+		{"memgraph with invalid URI", config.DatabaseConfig{Driver: "memgraph", URI: "ftp://localhost"}, "", "failed to create memgraph driver"},
+		{"unknown driver", config.DatabaseConfig{Driver: "dgraph"}, "", "unsupported database driver: dgraph"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graphDriver, err := NewDriver(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewDriver = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewDriver: %v", err)
+			}
+			defer graphDriver.Close()
+			if got := fmt.Sprintf("%T", graphDriver); got != tt.want {
+				t.Errorf("driver type = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCrossEncoder(t *testing.T) {
+	nlProcessor, err := NewNLPClient(config.NLPModelConfig{Provider: "openai", APIKey: "key"})
+	if err != nil {
+		t.Fatalf("NewNLPClient: %v", err)
+	}
+	embedderClient := embedder.NewOpenAIEmbedder("key", embedder.Config{})
+
+	tests := []struct {
+		name     string
+		cfg      config.CrossEncoderConfig
+		embedder embedder.Client
+		want     string // type of the client, empty for none
+		wantErr  string
+	}{
+		{"no provider", config.CrossEncoderConfig{}, embedderClient, "", ""},
+		{"openai", config.CrossEncoderConfig{Provider: "openai"}, embedderClient, "*crossencoder.OpenAIRerankerClient", ""},
+		{"local", config.CrossEncoderConfig{Provider: "local"}, embedderClient, "*crossencoder.LocalRerankerClient", ""},
+		{"reranker", config.CrossEncoderConfig{Provider: "reranker", BaseURL: "http://localhost:8081", APIKey: "key"}, embedderClient, "*crossencoder.RerankerClient", ""},
+		{"embedding", config.CrossEncoderConfig{Provider: "embedding"}, embedderClient, "*crossencoder.EmbeddingRerankerClient", ""},
+		{"embedding without embedder", config.CrossEncoderConfig{Provider: "embedding"}, nil, "", "embedder client is required"},
+		{"unknown provider", config.CrossEncoderConfig{Provider: "cohere"}, embedderClient, "", "unsupported cross-encoder provider: cohere"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewCrossEncoder(tt.cfg, nlProcessor, tt.embedder)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewCrossEncoder = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewCrossEncoder: %v", err)
+			}
+			got := ""
+			if client != nil {
+				got = fmt.Sprintf("%T", client)
+			}
+			if got != tt.want {
+				t.Errorf("client type = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild_Defaults(t *testing.T) {
+	local := config.NLPModelConfig{Provider: "openai_compatible", Model: "llama3", BaseURL: "http://localhost:11434/v1"}
+	newConfig := func() *config.Config {
+		return &config.Config{NLP: config.NLPConfig{Models: map[string]config.NLPModelConfig{ModelDefault: local}}}
+	}
+
+	if _, err := Build(nil, nil); err == nil {
+		t.Error("Build accepted a nil config")
+	}
+
+	components, err := Build(newConfig(), &Options{Driver: stubDriver{}, Logger: testLogger()})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if components.Config.GroupID != "default" || components.Config.AllowedGroupIDs != nil {
+		t.Errorf("groups = %q allowing %v, want default allowing any", components.Config.GroupID, components.Config.AllowedGroupIDs)
+	}
+	if components.Config.TimeZone != time.UTC {
+		t.Errorf("TimeZone = %v, want UTC", components.Config.TimeZone)
+	}
+	if components.NLP == nil || components.Embedder != nil || components.CrossEncoder != nil {
+		t.Errorf("components = NLP %T, embedder %T, cross-encoder %T; want only NLP",
+			components.NLP, components.Embedder, components.CrossEncoder)
+	}
+	if components.Config.UsagePolicy != nil || components.Config.FactStoreConfig != nil || components.Config.FactsDBURL != "" {
+		t.Error("unconfigured usage policy or fact store was set")
+	}
+	if components.Logger == nil {
+		t.Error("no logger was set")
+	}
+
+	// The server config supplies the groups, and options override it
+	cfg := newConfig()
+	cfg.Server.GroupID = "team"
+	cfg.Server.AllowedGroupIDs = []string{"team", "shared"}
+	components, err = Build(cfg, &Options{Driver: stubDriver{}, Logger: testLogger()})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if components.Config.GroupID != "team" || !slices.Equal(components.Config.AllowedGroupIDs, []string{"team", "shared"}) {
+		t.Errorf("groups = %q allowing %v, want the server config's", components.Config.GroupID, components.Config.AllowedGroupIDs)
+	}
+	components, err = Build(cfg, &Options{Driver: stubDriver{}, Logger: testLogger(), GroupID: "mine", AllowedGroupIDs: []string{"mine"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if components.Config.GroupID != "mine" || !slices.Equal(components.Config.AllowedGroupIDs, []string{"mine"}) {
+		t.Errorf("groups = %q allowing %v, want the options'", components.Config.GroupID, components.Config.AllowedGroupIDs)
+	}
+
+	// Routing policy and fact store are passed on
+	cfg = newConfig()
+	cfg.NLP.GroupUsage = map[string]string{"patients": "hipaa"}
+	cfg.NLP.SensitiveUsages = []string{"hipaa"}
+	cfg.NLP.StrictRouting = true
+	cfg.Embedding = config.EmbeddingConfig{Provider: "openai", APIKey: "key", Dimensions: 256}
+	cfg.FactStore = config.FactStoreConfig{ConnectionString: "postgres://localhost/facts"}
+	components, err = Build(cfg, &Options{Driver: stubDriver{}, Logger: testLogger()})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if policy := components.Config.UsagePolicy; policy == nil || !policy.Strict || policy.GroupUsage["patients"] != "hipaa" {
+		t.Errorf("UsagePolicy = %+v, want strict routing of patients to hipaa", policy)
+	}
+	if store := components.Config.FactStoreConfig; store == nil || store.Type != "" || store.EmbeddingDimensions != 256 {
+		t.Errorf("FactStoreConfig = %+v, want postgres with 256 dimensions", store)
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	local := config.NLPModelConfig{Provider: "openai_compatible", Model: "llama3", BaseURL: "http://localhost:11434/v1"}
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.Config)
+		driver  bool // pass a driver in the options
+		wantErr string
+	}{
+		{"unknown driver", func(cfg *config.Config) { cfg.Database.Driver = "dgraph" }, false, "unsupported database driver"},
+		{"unknown NLP provider", func(cfg *config.Config) { cfg.NLP.Models["extra"] = config.NLPModelConfig{Provider: "cohere"} }, true, "unsupported NLP provider"},
+		{"missing NLP key", func(cfg *config.Config) {
+			cfg.NLP.Models[ModelSummarization] = config.NLPModelConfig{Provider: "anthropic"}
+		}, true, "requires an API key"},
+		{"missing embedding key", func(cfg *config.Config) { cfg.Embedding.Provider = "voyage" }, true, "requires an API key"},
+		{"unknown cross-encoder", func(cfg *config.Config) { cfg.CrossEncoder.Provider = "cohere" }, true, "unsupported cross-encoder provider"},
+		{"unknown fact store", func(cfg *config.Config) {
+			cfg.FactStore = config.FactStoreConfig{Type: "sqlite", ConnectionString: "facts.db"}
+		}, true, "unsupported factstore type"},
+		{"unknown ensemble model", func(cfg *config.Config) { cfg.NLP.EnsembleModels = []string{"missing"} }, true, `ensemble model "missing"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{NLP: config.NLPConfig{Models: map[string]config.NLPModelConfig{ModelDefault: local}}}
+			tt.modify(cfg)
+			opts := &Options{Logger: testLogger()}
+			if tt.driver {
+				opts.Driver = stubDriver{}
+			}
+			if _, err := Build(cfg, opts); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyFactStoreConfig_Types(t *testing.T) {
+	tests := []struct {
+		storeType string
+		wantURL   bool // set as a Dolt URL rather than a store config
+	}{
+		{"", false},
+		{string(factstore.FactStoreTypePostgres), false},
+		{string(factstore.FactStoreTypeDoltGres), false},
+		{"dolt", true},
+	}
+	for _, tt := range tests {
+		cfg := &predicato.Config{}
+		store := config.FactStoreConfig{Type: tt.storeType, ConnectionString: "facts"}
+		if err := applyFactStoreConfig(cfg, store, 384); err != nil {
+			t.Fatalf("type %q: %v", tt.storeType, err)
+		}
+		if got := cfg.FactsDBURL != ""; got != tt.wantURL {
+			t.Errorf("type %q: FactsDBURL set = %v, want %v", tt.storeType, got, tt.wantURL)
+		}
+		if got := cfg.FactStoreConfig != nil; got == tt.wantURL {
+			t.Errorf("type %q: FactStoreConfig set = %v, want %v", tt.storeType, got, !tt.wantURL)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package factory
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/soundprediction/predicato"
+	"github.com/soundprediction/predicato/pkg/alert"
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/gliner"
+	"github.com/soundprediction/predicato/pkg/gliner2"
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/rustbert"
+)
+
+// Names of the NLPConfig.Models entries with a fixed meaning. Other entries
+// are named providers that RouterRules can route to.
+const (
+	ModelDefault   = "default"
+	ModelEmbedding = "embedding"
+
+	ModelNodeExtraction = "node_extraction"
+	ModelNodeReflexion  = "node_reflexion"
+	ModelNodeResolution = "node_resolution"
+	ModelNodeAttribute  = "node_attribute"
+	ModelEdgeExtraction = "edge_extraction"
+	ModelEdgeResolution = "edge_resolution"
+	ModelSummarization  = "summarization"
+	ModelTextGeneration = "text_generation"
+)
+
+// NLPClients are the NLP clients built from NLPConfig.
+type NLPClients struct {
+	// Default is the "default" model, or a router over all models when
+	// router rules are configured.
+	Default nlp.Client
+	// Steps holds the clients of the step models that are configured.
+	Steps predicato.NlpModels
+	// Named holds every decorated model client by name.
+	Named map[string]nlp.Client
+}
+
+// NewNLPClients creates a client for every model in cfg.NLP.Models except the
+// embedding model, wrapped in retry, circuit-breaker, tracing and
+// token-tracking decorators as configured. Each client reports the locality
+// of its configured provider and endpoint for strict local-only routing.
+// With router rules, the default and every step client are routers so that
+// usage tags are honoured at each step.
+func NewNLPClients(cfg *config.Config, logger *slog.Logger) (*NLPClients, error) {
+	var tracker *nlp.ParquetTokenTracker
+	if cfg.Telemetry.ParquetPath != "" {
+		var err error
+		tracker, err = nlp.NewTokenTracker(cfg.Telemetry.ParquetPath)
+		if err != nil {
+			logger.Warn("Failed to initialize token tracker", "error", err)
+		}
+	}
+	var alerter alert.Alerter
+	if cfg.Alert.Enabled {
+		alerter = alert.NewEmailAlerter(cfg.Alert)
+	}
+
+	// Build in a stable order so that errors are reproducible
+	names := make([]string, 0, len(cfg.NLP.Models))
+	for name := range cfg.NLP.Models {
+		if name != ModelEmbedding {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	named := make(map[string]nlp.Client, len(names))
+	for _, name := range names {
+		modelConfig := cfg.NLP.Models[name]
+		if modelConfig.Provider == "" {
+			continue
+		}
+		client, err := NewNLPClient(modelConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create NLP client %q: %w", name, err)
+		}
+		client = nlp.NewLocalityClient(client, modelIsLocal(modelConfig))
+		if !cfg.NLP.DisableRetry {
+			client, err = nlp.NewRetryClient(client, nlp.DefaultRetryConfig())
+			if err != nil {
+				return nil, fmt.Errorf("failed to create retry client: %w", err)
+			}
+		}
+		if cfg.CircuitBreaker.Enabled {
+			client = nlp.NewCircuitBreakerClient(client, cfg.CircuitBreaker, alerter, name)
+		}
+		client = nlp.NewTracingClient(client, modelConfig.Model)
+		if tracker != nil {
+			client = nlp.NewTokenTrackingClient(client, tracker)
+		}
+		named[name] = client
+	}
+
+	clients := &NLPClients{Named: named}
+	route := func(client nlp.Client) (nlp.Client, error) {
+		if client == nil || len(cfg.NLP.RouterRules) == 0 {
+			return client, nil
+		}
+		providers := make(map[string]nlp.Client, len(named)+1)
+		for name, provider := range named {
+			providers[name] = provider
+		}
+		providers[ModelDefault] = client
+		router, err := nlp.NewRouterClient(providers, cfg.NLP.RouterRules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create NLP router: %w", err)
+		}
+		return router, nil
+	}
+
+	var err error
+	if clients.Default, err = route(named[ModelDefault]); err != nil {
+		return nil, err
+	}
+	steps := []struct {
+		name   string
+		client *nlp.Client
+	}{
+		{ModelNodeExtraction, &clients.Steps.NodeExtraction},
+		{ModelNodeReflexion, &clients.Steps.NodeReflexion},
+		{ModelNodeResolution, &clients.Steps.NodeResolution},
+		{ModelNodeAttribute, &clients.Steps.NodeAttribute},
+		{ModelEdgeExtraction, &clients.Steps.EdgeExtraction},
+		{ModelEdgeResolution, &clients.Steps.EdgeResolution},
+		{ModelSummarization, &clients.Steps.Summarization},
+		{ModelTextGeneration, &clients.Steps.TextGeneration},
+	}
+	for _, step := range steps {
+		if *step.client, err = route(named[step.name]); err != nil {
+			return nil, err
+		}
+	}
+	return clients, nil
+}
+
+// NewNLPClient creates an undecorated client for one model. Hosted providers
+// require an API key.
+func NewNLPClient(cfg config.NLPModelConfig) (nlp.Client, error) {
+	if cfg.APIKey == "" && requiresAPIKey(cfg.Provider, cfg.BaseURL) {
+		return nil, fmt.Errorf("NLP provider %s requires an API key", cfg.Provider)
+	}
+	llmConfig := &nlp.LLMConfig{
+		APIKey:      cfg.APIKey,
+		Model:       cfg.Model,
+		BaseURL:     cfg.BaseURL,
+		Temperature: cfg.Temperature,
+		MaxTokens:   cfg.MaxTokens,
+	}
+
+	switch nlp.ProviderID(cfg.Provider) {
+	case nlp.ProviderOpenAI:
+		openaiConfig := nlp.Config{
+			Model:       cfg.Model,
+			Temperature: &cfg.Temperature,
+			BaseURL:     cfg.BaseURL,
+		}
+		if cfg.MaxTokens > 0 {
+			openaiConfig.MaxTokens = &cfg.MaxTokens
+		}
+		return nlp.NewOpenAIClient(cfg.APIKey, openaiConfig)
+	case nlp.ProviderOpenAICompatible:
+		if llmConfig.APIKey == "" {
+			// Some OpenAI-compatible services require a non-empty key
+			llmConfig.APIKey = "dummy"
+		}
+		return nlp.NewOpenAIGenericClient(llmConfig)
+	case nlp.ProviderAnthropic:
+		return nlp.NewAnthropicClient(llmConfig), nil
+	case nlp.ProviderGoogle, "gemini":
+		return nlp.NewGeminiClient(llmConfig), nil
+	case nlp.ProviderAzure:
+		return nlp.NewAzureOpenAIClient(&nlp.AzureOpenAIConfig{
+			LLMConfig:    llmConfig,
+			APIVersion:   cfg.APIVersion,
+			DeploymentID: cfg.DeploymentID,
+		}), nil
+	case nlp.ProviderGLiNER:
+		client, err := gliner.NewClient(cfg.Model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load GLiNER model: %w", err)
+		}
+		return gliner.NewLLMAdapter(client, nil), nil
+	case nlp.ProviderGLiNER2:
+		return newGLiNER2Client(cfg)
+	case nlp.ProviderRustBert:
+		return newRustBertClient(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported NLP provider: %s", cfg.Provider)
+	}
+}
+
+// requiresAPIKey reports whether provider is a hosted API that rejects
+// requests without a key. OpenAI with a base URL may be a compatible server
+// that needs none.
+func requiresAPIKey(provider, baseURL string) bool {
+	switch nlp.ProviderID(provider) {
+	case nlp.ProviderOpenAI:
+		return baseURL == ""
+	case nlp.ProviderAnthropic, nlp.ProviderGoogle, "gemini", nlp.ProviderAzure, "voyage":
+		return true
+	default:
+		return false
+	}
+}
+
+// modelIsLocal reports whether a model keeps requests on this machine,
+// judged from its configured provider and endpoint. GLiNER and RustBert run
+// in process; OpenAI-compatible and GLiNER2 servers are local when their
+// base URL is, and hosted APIs never are.
+func modelIsLocal(cfg config.NLPModelConfig) bool {
+	switch nlp.ProviderID(cfg.Provider) {
+	case nlp.ProviderGLiNER, nlp.ProviderRustBert:
+		return true
+	case nlp.ProviderOpenAI, nlp.ProviderOpenAICompatible:
+		return nlp.IsLocalEndpoint(cfg.BaseURL)
+	case nlp.ProviderGLiNER2:
+		return cfg.APIKey == "" && nlp.IsLocalEndpoint(cfg.BaseURL)
+	default:
+		return false
+	}
+}
+
+// newGLiNER2Client creates a GLiNER2 client: the Fastino API when an API key
+// is set, otherwise a local GLiNER2 server at BaseURL.
+func newGLiNER2Client(cfg config.NLPModelConfig) (nlp.Client, error) {
+	timeout := 30 * time.Second
+	gliner2Config := gliner2.Config{Provider: gliner2.ProviderLocal, Local: &gliner2.LocalConfig{Endpoint: cfg.BaseURL, Timeout: timeout}}
+	if cfg.APIKey != "" {
+		gliner2Config = gliner2.Config{Provider: gliner2.ProviderFastino, Fastino: &gliner2.FastinoConfig{Endpoint: cfg.BaseURL, APIKey: cfg.APIKey, Timeout: timeout}}
+	}
+	return gliner2.NewClient(gliner2Config)
+}
+
+// newRustBertClient creates a RustBERT client for the configured task and
+// loads its model. The task may also be given as the host of a
+// "rustbert://<task>" base URL.
+func newRustBertClient(cfg config.NLPModelConfig) (nlp.Client, error) {
+	task := cfg.Task
+	if task == "" {
+		task = strings.TrimPrefix(cfg.BaseURL, "rustbert://")
+	}
+	if task == "" || task == "generator" {
+		task = "text_generation"
+	}
+
+	var rustbertConfig rustbert.Config
+	switch task {
+	case "ner":
+		rustbertConfig.NERModelID = cfg.Model
+	case "summarization":
+		rustbertConfig.SummarizationModelID = cfg.Model
+	}
+	client := rustbert.NewClient(rustbertConfig)
+
+	var err error
+	switch task {
+	case "text_generation", "generation":
+		err = client.LoadTextGenerationModel()
+	case "summarization":
+		err = client.LoadSummarizationModel()
+	case "ner":
+		err = client.LoadNERModel()
+	case "qa":
+		err = client.LoadQAModel()
+	default:
+		return nil, fmt.Errorf("unsupported RustBERT task: %s", task)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load RustBERT %s model: %w", task, err)
+	}
+	return rustbert.NewLLMAdapter(client, task), nil
+}
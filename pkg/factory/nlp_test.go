@@ -0,0 +1,200 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/soundprediction/predicato"
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/driver"
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// stubDriver satisfies driver.GraphDriver for tests that must fail before
+// the graph is touched; any driver call panics.
+type stubDriver struct {
+	driver.GraphDriver
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestNewNLPClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   config.NLPModelConfig
+		want    string // type of the client
+		wantErr string
+	}{
+		{"openai", config.NLPModelConfig{Provider: "openai", Model: "gpt-4o-mini", APIKey: "key"}, "*nlp.OpenAIClient", ""},
+		{"openai server without key", config.NLPModelConfig{Provider: "openai", BaseURL: "http://localhost:8080/v1"}, "*nlp.OpenAIClient", ""},
+		{"openai_compatible without key", config.NLPModelConfig{Provider: "openai_compatible", Model: "llama3", BaseURL: "http://localhost:11434/v1"}, "*nlp.OpenAIGenericClient", ""},
+		{"anthropic", config.NLPModelConfig{Provider: "anthropic", Model: "claude", APIKey: "key"}, "*nlp.AnthropicClient", ""},
+		{"google", config.NLPModelConfig{Provider: "google", Model: "gemini-pro", APIKey: "key"}, "*nlp.GeminiClient", ""},
+		{"gemini", config.NLPModelConfig{Provider: "gemini", Model: "gemini-pro", APIKey: "key"}, "*nlp.GeminiClient", ""},
+		{"azure", config.NLPModelConfig{Provider: "azure", APIKey: "key", DeploymentID: "gpt4"}, "*nlp.AzureOpenAIClient", ""},
+		{"gliner2 server", config.NLPModelConfig{Provider: "gliner2", BaseURL: "http://localhost:8000"}, "*gliner2.Client", ""},
+		{"gliner2 fastino", config.NLPModelConfig{Provider: "gliner2", APIKey: "key", BaseURL: "https://api.fastino.ai"}, "*gliner2.Client", ""},
+
+		{"openai without key", config.NLPModelConfig{Provider: "openai", Model: "gpt-4o-mini"}, "", "requires an API key"},
+		{"anthropic without key", config.NLPModelConfig{Provider: "anthropic", Model: "claude"}, "", "requires an API key"},
+		{"google without key", config.NLPModelConfig{Provider: "google", Model: "gemini-pro"}, "", "requires an API key"},
+		{"azure without key", config.NLPModelConfig{Provider: "azure", BaseURL: "https://example.openai.azure.com"}, "", "requires an API key"},
+		{"openai with invalid base URL", config.NLPModelConfig{Provider: "openai", BaseURL: "::not a url"}, "", "invalid base URL"},
+		{"rustbert unknown task", config.NLPModelConfig{Provider: "rustbert", Task: "translation"}, "", "unsupported RustBERT task"},
+		{"unknown provider", config.NLPModelConfig{Provider: "cohere", APIKey: "key"}, "", "unsupported NLP provider: cohere"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewNLPClient(tt.model)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewNLPClient = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewNLPClient: %v", err)
+			}
+			defer client.Close()
+			if got := fmt.Sprintf("%T", client); got != tt.want {
+				t.Errorf("client type = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNLPClients(t *testing.T) {
+	cfg := &config.Config{NLP: config.NLPConfig{Models: map[string]config.NLPModelConfig{
+		ModelDefault:        {Provider: "openai_compatible", Model: "llama3", BaseURL: "http://localhost:11434/v1"},
+		ModelSummarization:  {Provider: "anthropic", Model: "claude", APIKey: "key"},
+		ModelEmbedding:      {Provider: "openai", Model: "text-embedding-3-small", APIKey: "key"},
+		ModelNodeExtraction: {}, // no provider: left unset
+	}}}
+	clients, err := NewNLPClients(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewNLPClients: %v", err)
+	}
+	if clients.Default == nil || clients.Steps.Summarization == nil {
+		t.Error("the default and summarization clients were not built")
+	}
+	if clients.Steps.NodeExtraction != nil || clients.Steps.EdgeExtraction != nil {
+		t.Error("step clients without a configured provider were built")
+	}
+	if _, ok := clients.Named[ModelEmbedding]; ok {
+		t.Error("the embedding model was built as an NLP client")
+	}
+	if len(clients.Named) != 2 {
+		t.Errorf("built %d named clients, want 2", len(clients.Named))
+	}
+
+	// A model that fails to build is reported by name
+	cfg.NLP.Models["broken"] = config.NLPModelConfig{Provider: "anthropic"}
+	if _, err := NewNLPClients(cfg, testLogger()); err == nil || !strings.Contains(err.Error(), `"broken"`) {
+		t.Errorf("NewNLPClients = %v, want an error naming the broken model", err)
+	}
+
+	// Router rules turn the default and step clients into routers
+	delete(cfg.NLP.Models, "broken")
+	cfg.NLP.RouterRules = []config.RouterRule{{Usage: "hipaa", Provider: ModelDefault}}
+	clients, err = NewNLPClients(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewNLPClients with router rules: %v", err)
+	}
+	for name, client := range map[string]nlp.Client{"default": clients.Default, "summarization": clients.Steps.Summarization} {
+		if _, ok := client.(*nlp.RouterClient); !ok {
+			t.Errorf("%s client is %T, want a router", name, client)
+		}
+	}
+}
+
+func TestNewNLPClients_Locality(t *testing.T) {
+	tests := []struct {
+		name  string
+		model config.NLPModelConfig
+		want  bool
+	}{
+		// Router names are chosen by the user and say nothing about locality
+		{"rustbert", config.NLPModelConfig{Provider: "openai", Model: "gpt-4o-mini", APIKey: "key"}, false},
+		{"gliner", config.NLPModelConfig{Provider: "anthropic", Model: "claude", APIKey: "key"}, false},
+		{"ollama", config.NLPModelConfig{Provider: "openai_compatible", Model: "llama3", BaseURL: "http://localhost:11434/v1"}, true},
+		{"vllm", config.NLPModelConfig{Provider: "openai_compatible", Model: "llama3", BaseURL: "http://127.0.0.1:8000/v1"}, true},
+		{"hosted", config.NLPModelConfig{Provider: "openai_compatible", Model: "llama3", BaseURL: "https://api.example.com/v1"}, false},
+		{"openai_proxy", config.NLPModelConfig{Provider: "openai", Model: "gpt-4o-mini", APIKey: "key", BaseURL: "http://[::1]:8080/v1"}, true},
+		{"ner", config.NLPModelConfig{Provider: "gliner2", Model: "fastino/gliner2-base-v1", BaseURL: "http://localhost:8000"}, true},
+		{"remote_ner", config.NLPModelConfig{Provider: "gliner2", Model: "fastino/gliner2-base-v1", BaseURL: "http://gliner.example.com"}, false},
+		{"fastino", config.NLPModelConfig{Provider: "gliner2", Model: "fastino/gliner2-base-v1", APIKey: "key", BaseURL: "http://localhost:8000"}, false},
+	}
+
+	models := make(map[string]config.NLPModelConfig, len(tests))
+	for _, tt := range tests {
+		models[tt.name] = tt.model
+	}
+	cfg := &config.Config{NLP: config.NLPConfig{Models: models}}
+	clients, err := NewNLPClients(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewNLPClients: %v", err)
+	}
+	for _, tt := range tests {
+		client, ok := clients.Named[tt.name]
+		if !ok {
+			t.Errorf("model %q was not built", tt.name)
+			continue
+		}
+		if got := nlp.IsLocalFor(client, "hipaa"); got != tt.want {
+			t.Errorf("model %q (%s %s): IsLocalFor = %v, want %v", tt.name, tt.model.Provider, tt.model.BaseURL, got, tt.want)
+		}
+	}
+}
+
+func TestBuild_StrictRoutingRejectsMisleadinglyNamedRemoteModel(t *testing.T) {
+	newConfig := func(hipaaProvider string) *config.Config {
+		return &config.Config{
+			NLP: config.NLPConfig{
+				Models: map[string]config.NLPModelConfig{
+					ModelDefault: {Provider: "openai_compatible", Model: "llama3", BaseURL: "http://localhost:11434/v1"},
+					// Named after a local provider, but calls OpenAI
+					"rustbert": {Provider: "openai", Model: "gpt-4o-mini", APIKey: "key"},
+					"ner":      {Provider: "gliner2", Model: "fastino/gliner2-base-v1", BaseURL: "http://localhost:8000"},
+				},
+				RouterRules:     []config.RouterRule{{Usage: "hipaa", Provider: hipaaProvider}},
+				GroupUsage:      map[string]string{"patients": "hipaa"},
+				SensitiveUsages: []string{"hipaa"},
+				StrictRouting:   true,
+			},
+		}
+	}
+
+	components, err := Build(newConfig("rustbert"), &Options{Driver: stubDriver{}, Logger: testLogger(), AllowedGroupIDs: []string{"patients"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if nlp.IsLocalFor(components.NLP, "hipaa") {
+		t.Error("hipaa requests routed to a remote model named rustbert are reported as local")
+	}
+	client, err := NewClientFromComponents(components)
+	if err != nil {
+		t.Fatalf("NewClientFromComponents: %v", err)
+	}
+	_, err = client.Search(context.Background(), "blood pressure", &types.SearchConfig{GroupIDs: []string{"patients"}})
+	if !errors.Is(err, predicato.ErrSensitiveRouting) {
+		t.Errorf("Search in a hipaa group = %v, want ErrSensitiveRouting", err)
+	}
+
+	// Routed to a GLiNER2 server on localhost, the same usage stays local
+	components, err = Build(newConfig("ner"), &Options{Driver: stubDriver{}, Logger: testLogger()})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !nlp.IsLocalFor(components.NLP, "hipaa") {
+		t.Error("hipaa requests routed to a local GLiNER2 server are reported as remote")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !cgo
+
+package factory
+
+import (
+	"github.com/soundprediction/predicato/pkg/driver"
+	"github.com/soundprediction/predicato/pkg/embedder"
+)
+
+func newLadybugDriver(uri string) (driver.GraphDriver, error) {
+	return nil, driver.ErrCGORequired
+}
+
+func newEmbedEverythingEmbedder(config *embedder.Config) (embedder.Client, error) {
+	return nil, driver.ErrCGORequired
+}
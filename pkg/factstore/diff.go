@@ -0,0 +1,109 @@
+package factstore
+
+import (
+	"strings"
+)
+
+// ExtractionDiff describes how two extractions of the same source differ.
+// Nodes are matched by normalized name and type; edges are matched by their
+// normalized source name, relation and target name.
+type ExtractionDiff struct {
+	// AddedNodes are nodes present only in the new extraction.
+	AddedNodes []*ExtractedNode `json:"added_nodes"`
+	// RemovedNodes are nodes present only in the old extraction.
+	RemovedNodes []*ExtractedNode `json:"removed_nodes"`
+	// UnchangedNodes are nodes from the old extraction that the new extraction also produced.
+	UnchangedNodes []*ExtractedNode `json:"unchanged_nodes"`
+
+	// AddedEdges are edges present only in the new extraction.
+	AddedEdges []*ExtractedEdge `json:"added_edges"`
+	// RemovedEdges are edges present only in the old extraction.
+	RemovedEdges []*ExtractedEdge `json:"removed_edges"`
+	// UnchangedEdges are edges from the old extraction that the new extraction also produced.
+	UnchangedEdges []*ExtractedEdge `json:"unchanged_edges"`
+}
+
+// IsEmpty returns true if nothing was added or removed.
+func (d *ExtractionDiff) IsEmpty() bool {
+	if d == nil {
+		return true
+	}
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// DiffExtractedKnowledge compares an old and a new extraction of the same source.
+// Duplicate keys within one side are collapsed so that repeated mentions across
+// chunks do not show up as spurious additions or removals.
+func DiffExtractedKnowledge(oldNodes []*ExtractedNode, oldEdges []*ExtractedEdge, newNodes []*ExtractedNode, newEdges []*ExtractedEdge) *ExtractionDiff {
+	diff := &ExtractionDiff{}
+
+	newNodeKeys := make(map[string]bool, len(newNodes))
+	for _, n := range newNodes {
+		newNodeKeys[NodeKey(n)] = true
+	}
+	oldNodeKeys := make(map[string]bool, len(oldNodes))
+	for _, n := range oldNodes {
+		key := NodeKey(n)
+		if oldNodeKeys[key] {
+			continue
+		}
+		oldNodeKeys[key] = true
+		if newNodeKeys[key] {
+			diff.UnchangedNodes = append(diff.UnchangedNodes, n)
+		} else {
+			diff.RemovedNodes = append(diff.RemovedNodes, n)
+		}
+	}
+	for _, n := range newNodes {
+		key := NodeKey(n)
+		if oldNodeKeys[key] {
+			continue
+		}
+		oldNodeKeys[key] = true
+		diff.AddedNodes = append(diff.AddedNodes, n)
+	}
+
+	newEdgeKeys := make(map[string]bool, len(newEdges))
+	for _, e := range newEdges {
+		newEdgeKeys[EdgeKey(e)] = true
+	}
+	oldEdgeKeys := make(map[string]bool, len(oldEdges))
+	for _, e := range oldEdges {
+		key := EdgeKey(e)
+		if oldEdgeKeys[key] {
+			continue
+		}
+		oldEdgeKeys[key] = true
+		if newEdgeKeys[key] {
+			diff.UnchangedEdges = append(diff.UnchangedEdges, e)
+		} else {
+			diff.RemovedEdges = append(diff.RemovedEdges, e)
+		}
+	}
+	for _, e := range newEdges {
+		key := EdgeKey(e)
+		if oldEdgeKeys[key] {
+			continue
+		}
+		oldEdgeKeys[key] = true
+		diff.AddedEdges = append(diff.AddedEdges, e)
+	}
+
+	return diff
+}
+
+// NodeKey returns the identity used to match extracted nodes across extraction runs.
+func NodeKey(n *ExtractedNode) string {
+	return normalizeKeyPart(n.Name) + "|" + normalizeKeyPart(n.Type)
+}
+
+// EdgeKey returns the identity used to match extracted edges across extraction runs.
+func EdgeKey(e *ExtractedEdge) string {
+	return normalizeKeyPart(e.SourceNodeName) + "|" + normalizeKeyPart(e.Relation) + "|" + normalizeKeyPart(e.TargetNodeName)
+}
+
+// normalizeKeyPart lowercases text and collapses whitespace.
+func normalizeKeyPart(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
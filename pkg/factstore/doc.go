@@ -27,6 +27,28 @@
 //	    return err
 //	}
 //
+// # Corrections
+//
+// Stored extractions can be corrected without re-extracting: UpdateNode and
+// UpdateEdge overwrite single rows, ReplaceExtractedKnowledge swaps the whole
+// extraction of a source in one transaction and DeleteSource removes a source
+// with its extracted nodes and edges.
+//
+// # Versioning
+//
+// DoltDB implements VersionedFactsDB. Extraction runs are committed with
+// CommitExtraction, which the client calls after each extraction.
+// Experimental runs are written to branches opened with OnBranch.
+// DiffExtractions compares the facts of two refs, and the *At methods read a
+// source and its facts as of a ref.
+//
+// # Migrations
+//
+// The schema of each backend is a list of Migrations tracked in the
+// schema_version table. Initialize applies pending migrations and fails with
+// ErrEmbeddingDimensionsChanged if the stored vectors do not have the
+// configured size; Migrate re-embeds or pads them (see Migrator).
+//
 // # Search Capabilities
 //
 // The package provides hybrid search combining:
@@ -18,6 +18,10 @@ import (
 // DoltDB is maintained for backward compatibility but uses in-memory vector search.
 type DoltDB struct {
 	db *sql.DB
+
+	// connectionString and config are kept to open branches with OnBranch.
+	connectionString string
+	config           *DoltDBConfig
 }
 
 // DoltDBConfig holds configuration options for DoltDB connection pool.
@@ -33,6 +37,11 @@ type DoltDBConfig struct {
 	// ConnMaxLifetime is the maximum amount of time a connection may be reused.
 	// Default: 5 minutes
 	ConnMaxLifetime time.Duration
+
+	// EmbeddingDimensions is the size of the stored vectors. Initialize fails
+	// if the stored vectors differ, until Migrate resizes them. Zero disables
+	// the check.
+	EmbeddingDimensions int
 }
 
 // DefaultDoltDBConfig returns the default DoltDB configuration.
@@ -57,6 +66,11 @@ func NewDoltDB(connectionString string) (*DoltDB, error) {
 func NewDoltDBWithConfig(connectionString string, config *DoltDBConfig) (*DoltDB, error) {
 	fmt.Println("Warning: DoltDB is deprecated. Consider using PostgresDB with DoltGres for VectorChord support.")
 
+	return openDoltDB(connectionString, config)
+}
+
+// openDoltDB opens and pings a Dolt database.
+func openDoltDB(connectionString string, config *DoltDBConfig) (*DoltDB, error) {
 	if config == nil {
 		config = DefaultDoltDBConfig()
 	}
@@ -75,7 +89,7 @@ func NewDoltDBWithConfig(connectionString string, config *DoltDBConfig) (*DoltDB
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DoltDB{db: db}, nil
+	return &DoltDB{db: db, connectionString: connectionString, config: config}, nil
 }
 
 func (d *DoltDB) Initialize(ctx context.Context) error {
@@ -87,49 +101,11 @@ func (d *DoltDB) Initialize(ctx context.Context) error {
 		return fmt.Errorf("failed to use database 'facts': %w", err)
 	}
 
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS sources (
-			id VARCHAR(255) PRIMARY KEY,
-			name TEXT,
-			content TEXT,
-			group_id VARCHAR(255),
-			metadata JSON,
-			created_at TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS extracted_nodes (
-			id VARCHAR(255) PRIMARY KEY,
-			source_id VARCHAR(255),
-			group_id VARCHAR(255),
-			name TEXT,
-			type VARCHAR(50),
-			description TEXT,
-			embedding JSON,
-			chunk_index INT,
-			created_at TIMESTAMP,
-			FOREIGN KEY (source_id) REFERENCES sources(id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS extracted_edges (
-			id VARCHAR(255) PRIMARY KEY,
-			source_id VARCHAR(255),
-			group_id VARCHAR(255),
-			source_node_name TEXT,
-			target_node_name TEXT,
-			relation TEXT,
-			description TEXT,
-			embedding JSON,
-			weight FLOAT,
-			chunk_index INT,
-			created_at TIMESTAMP,
-			FOREIGN KEY (source_id) REFERENCES sources(id)
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.db.ExecContext(ctx, query); err != nil {
-			return fmt.Errorf("failed to execute init query: %w", err)
-		}
+	s := d.schema()
+	if _, err := s.apply(ctx); err != nil {
+		return err
 	}
-	return nil
+	return s.checkDimensions(ctx, d.config.EmbeddingDimensions, d.decodeEmbedding)
 }
 
 func (d *DoltDB) SaveSource(ctx context.Context, source *Source) error {
@@ -138,14 +114,48 @@ func (d *DoltDB) SaveSource(ctx context.Context, source *Source) error {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	query := `INSERT INTO sources (id, name, content, group_id, metadata, created_at) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err = d.db.ExecContext(ctx, query, source.ID, source.Name, source.Content, source.GroupID, metadataJSON, source.CreatedAt)
+	query := `INSERT INTO sources (id, name, content, group_id, metadata, created_at, reference, promotion_status, promoted_at, promoted_by, promotion_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE name = VALUES(name), content = VALUES(content), group_id = VALUES(group_id), metadata = VALUES(metadata),
+		reference = COALESCE(VALUES(reference), reference)`
+	// Without a status the promotion state of an existing source is kept
+	if source.PromotionStatus != "" {
+		query += `, promotion_status = VALUES(promotion_status), promoted_at = VALUES(promoted_at),
+		promoted_by = VALUES(promoted_by), promotion_error = VALUES(promotion_error)`
+	}
+	reference, status, promotedAt, promotedBy, promotionErr := sourceValues(source)
+	_, err = d.db.ExecContext(ctx, query, source.ID, source.Name, source.Content, source.GroupID, metadataJSON, source.CreatedAt,
+		reference, status, promotedAt, promotedBy, promotionErr)
 	if err != nil {
 		return fmt.Errorf("failed to insert source: %w", err)
 	}
 	return nil
 }
 
+// SetPromotionStatus records the outcome of promoting a source to the graph.
+func (d *DoltDB) SetPromotionStatus(ctx context.Context, sourceID string, status PromotionStatus, modelerName string, promotionErr error) error {
+	var errMsg string
+	if promotionErr != nil {
+		errMsg = promotionErr.Error()
+	}
+	var promotedAt interface{}
+	if status != PromotionPending {
+		promotedAt = time.Now().UTC()
+	}
+
+	if err := requireRow(ctx, d.db, doltPlaceholder, "sources", "source", sourceID); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE sources SET promotion_status = ?, promoted_at = ?, promoted_by = ?, promotion_error = ? WHERE id = ?",
+		string(status), promotedAt, modelerName, errMsg, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to update promotion status: %w", err)
+	}
+	return nil
+}
+
 func (d *DoltDB) SaveExtractedKnowledge(ctx context.Context, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error {
 	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -153,14 +163,142 @@ func (d *DoltDB) SaveExtractedKnowledge(ctx context.Context, sourceID string, no
 	}
 	defer tx.Rollback()
 
+	if err := d.insertExtractedKnowledge(ctx, tx, sourceID, nodes, edges); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceExtractedKnowledge deletes the existing extracted nodes and edges of a source
+// and inserts the given ones in a single transaction.
+func (d *DoltDB) ReplaceExtractedKnowledge(ctx context.Context, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM extracted_edges WHERE source_id = ?", sourceID); err != nil {
+		return fmt.Errorf("failed to delete extracted edges: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM extracted_nodes WHERE source_id = ?", sourceID); err != nil {
+		return fmt.Errorf("failed to delete extracted nodes: %w", err)
+	}
+
+	if err := d.insertExtractedKnowledge(ctx, tx, sourceID, nodes, edges); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSource deletes a source and its extracted nodes and edges in a single transaction.
+func (d *DoltDB) DeleteSource(ctx context.Context, sourceID string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteSource(ctx, tx, doltPlaceholder, sourceID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateNode overwrites the stored fields of an extracted node in a single transaction.
+func (d *DoltDB) UpdateNode(ctx context.Context, node *ExtractedNode) error {
+	embedding, err := json.Marshal(node.Embedding)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding for node %s: %w", node.ID, err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateNode(ctx, tx, doltPlaceholder, node, embedding); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateEdge overwrites the stored fields of an extracted edge in a single transaction.
+func (d *DoltDB) UpdateEdge(ctx context.Context, edge *ExtractedEdge) error {
+	embedding, err := json.Marshal(edge.Embedding)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding for edge %s: %w", edge.ID, err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateEdge(ctx, tx, doltPlaceholder, edge, embedding); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeEntity deletes the extracted rows naming an entity and redacts its name
+// from the remaining rows in a single transaction.
+func (d *DoltDB) PurgeEntity(ctx context.Context, groupID, name string) (*PurgeStats, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats, err := purgeEntity(ctx, tx, doltPlaceholder, groupID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return stats, nil
+}
+
+// insertExtractedKnowledge inserts extracted nodes and edges for a source within tx.
+func (d *DoltDB) insertExtractedKnowledge(ctx context.Context, tx *sql.Tx, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error {
 	// Get source's group_id for nodes/edges
 	var groupID string
-	err = tx.QueryRowContext(ctx, "SELECT group_id FROM sources WHERE id = ?", sourceID).Scan(&groupID)
+	err := tx.QueryRowContext(ctx, "SELECT group_id FROM sources WHERE id = ?", sourceID).Scan(&groupID)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("failed to get source group_id: %w", err)
 	}
 
-	nodeStmt, err := tx.PrepareContext(ctx, `INSERT INTO extracted_nodes (id, source_id, group_id, name, type, description, embedding, chunk_index, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	nodeStmt, err := tx.PrepareContext(ctx, `INSERT INTO extracted_nodes (id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare node statement: %w", err)
 	}
@@ -179,12 +317,13 @@ func (d *DoltDB) SaveExtractedKnowledge(ctx context.Context, sourceID string, no
 		if createdAt.IsZero() {
 			createdAt = time.Now()
 		}
-		if _, err := nodeStmt.ExecContext(ctx, node.ID, sourceID, nodeGroupID, node.Name, node.Type, node.Description, embeddingJSON, node.ChunkIndex, createdAt); err != nil {
+		spanStart, spanEnd, evidence := spanValues(node.Span)
+		if _, err := nodeStmt.ExecContext(ctx, node.ID, sourceID, nodeGroupID, node.Name, node.Type, node.Description, embeddingJSON, node.ChunkIndex, spanStart, spanEnd, evidence, createdAt); err != nil {
 			return fmt.Errorf("failed to insert node %s: %w", node.ID, err)
 		}
 	}
 
-	edgeStmt, err := tx.PrepareContext(ctx, `INSERT INTO extracted_edges (id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	edgeStmt, err := tx.PrepareContext(ctx, `INSERT INTO extracted_edges (id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare edge statement: %w", err)
 	}
@@ -203,103 +342,47 @@ func (d *DoltDB) SaveExtractedKnowledge(ctx context.Context, sourceID string, no
 		if createdAt.IsZero() {
 			createdAt = time.Now()
 		}
-		if _, err := edgeStmt.ExecContext(ctx, edge.ID, sourceID, edgeGroupID, edge.SourceNodeName, edge.TargetNodeName, edge.Relation, edge.Description, embeddingJSON, edge.Weight, edge.ChunkIndex, createdAt); err != nil {
+		spanStart, spanEnd, evidence := spanValues(edge.Span)
+		if _, err := edgeStmt.ExecContext(ctx, edge.ID, sourceID, edgeGroupID, edge.SourceNodeName, edge.TargetNodeName, edge.Relation, edge.Description, embeddingJSON, edge.Weight, edge.Confidence, edge.ChunkIndex, spanStart, spanEnd, evidence, createdAt); err != nil {
 			return fmt.Errorf("failed to insert edge %s: %w", edge.ID, err)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 func (d *DoltDB) GetSource(ctx context.Context, sourceID string) (*Source, error) {
-	row := d.db.QueryRowContext(ctx, "SELECT id, name, content, group_id, metadata, created_at FROM sources WHERE id = ?", sourceID)
+	row := d.db.QueryRowContext(ctx, "SELECT "+sourceColumns+" FROM sources WHERE id = ?", sourceID)
 
-	var s Source
-	var metadataBytes []byte
-
-	if err := row.Scan(&s.ID, &s.Name, &s.Content, &s.GroupID, &metadataBytes, &s.CreatedAt); err != nil {
+	s, err := scanSource(row)
+	if err != nil {
 		return nil, fmt.Errorf("failed to scan source: %w", err)
 	}
-
-	if len(metadataBytes) > 0 {
-		if err := json.Unmarshal(metadataBytes, &s.Metadata); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-	}
-
-	return &s, nil
+	return s, nil
 }
 
 func (d *DoltDB) GetExtractedNodes(ctx context.Context, sourceID string) ([]*ExtractedNode, error) {
-	rows, err := d.db.QueryContext(ctx, "SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, created_at FROM extracted_nodes WHERE source_id = ?", sourceID)
+	rows, err := d.db.QueryContext(ctx, "SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at FROM extracted_nodes WHERE source_id = ?", sourceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query extracted nodes: %w", err)
 	}
 	defer rows.Close()
 
-	var nodes []*ExtractedNode
-	for rows.Next() {
-		var n ExtractedNode
-		var embeddingBytes []byte
-		var groupID sql.NullString
-		var createdAt sql.NullTime
-		if err := rows.Scan(&n.ID, &n.SourceID, &groupID, &n.Name, &n.Type, &n.Description, &embeddingBytes, &n.ChunkIndex, &createdAt); err != nil {
-			return nil, err
-		}
-		if groupID.Valid {
-			n.GroupID = groupID.String
-		}
-		if createdAt.Valid {
-			n.CreatedAt = createdAt.Time
-		}
-		if len(embeddingBytes) > 0 {
-			if err := json.Unmarshal(embeddingBytes, &n.Embedding); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
-			}
-		}
-		nodes = append(nodes, &n)
-	}
-	return nodes, nil
+	return d.scanNodes(rows)
 }
 
 func (d *DoltDB) GetExtractedEdges(ctx context.Context, sourceID string) ([]*ExtractedEdge, error) {
-	rows, err := d.db.QueryContext(ctx, "SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at FROM extracted_edges WHERE source_id = ?", sourceID)
+	rows, err := d.db.QueryContext(ctx, "SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at FROM extracted_edges WHERE source_id = ?", sourceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query extracted edges: %w", err)
 	}
 	defer rows.Close()
 
-	var edges []*ExtractedEdge
-	for rows.Next() {
-		var e ExtractedEdge
-		var embeddingBytes []byte
-		var groupID sql.NullString
-		var createdAt sql.NullTime
-		if err := rows.Scan(&e.ID, &e.SourceID, &groupID, &e.SourceNodeName, &e.TargetNodeName, &e.Relation, &e.Description, &embeddingBytes, &e.Weight, &e.ChunkIndex, &createdAt); err != nil {
-			return nil, err
-		}
-		if groupID.Valid {
-			e.GroupID = groupID.String
-		}
-		if createdAt.Valid {
-			e.CreatedAt = createdAt.Time
-		}
-		if len(embeddingBytes) > 0 {
-			if err := json.Unmarshal(embeddingBytes, &e.Embedding); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
-			}
-		}
-		edges = append(edges, &e)
-	}
-	return edges, nil
+	return d.scanEdges(rows)
 }
 
 func (d *DoltDB) GetAllSources(ctx context.Context, limit int) ([]*Source, error) {
-	query := "SELECT id, name, content, group_id, metadata, created_at FROM sources ORDER BY created_at DESC"
+	query := "SELECT " + sourceColumns + " FROM sources ORDER BY created_at DESC"
 	var rows *sql.Rows
 	var err error
 	if limit > 0 {
@@ -313,25 +396,35 @@ func (d *DoltDB) GetAllSources(ctx context.Context, limit int) ([]*Source, error
 	}
 	defer rows.Close()
 
-	var sources []*Source
-	for rows.Next() {
-		var s Source
-		var metadataBytes []byte
-		if err := rows.Scan(&s.ID, &s.Name, &s.Content, &s.GroupID, &metadataBytes, &s.CreatedAt); err != nil {
-			return nil, err
-		}
-		if len(metadataBytes) > 0 {
-			if err := json.Unmarshal(metadataBytes, &s.Metadata); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-			}
-		}
-		sources = append(sources, &s)
+	return scanSourceRows(rows)
+}
+
+// ListSources retrieves the sources matching filter, oldest reference first.
+// Sources without a reference are ordered by their creation time.
+func (d *DoltDB) ListSources(ctx context.Context, filter *SourceFilter) ([]*Source, error) {
+	conditions, args := sourceFilterClauses(filter, doltPlaceholder)
+
+	query := "SELECT " + sourceColumns + " FROM sources"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
-	return sources, nil
+	query += " ORDER BY COALESCE(reference, created_at) ASC, id ASC"
+	if filter != nil && filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sources: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSourceRows(rows)
 }
 
 func (d *DoltDB) GetAllNodes(ctx context.Context, limit int) ([]*ExtractedNode, error) {
-	query := "SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, created_at FROM extracted_nodes"
+	query := "SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at FROM extracted_nodes"
 	var rows *sql.Rows
 	var err error
 	if limit > 0 {
@@ -345,33 +438,11 @@ func (d *DoltDB) GetAllNodes(ctx context.Context, limit int) ([]*ExtractedNode,
 	}
 	defer rows.Close()
 
-	var nodes []*ExtractedNode
-	for rows.Next() {
-		var n ExtractedNode
-		var embeddingBytes []byte
-		var groupID sql.NullString
-		var createdAt sql.NullTime
-		if err := rows.Scan(&n.ID, &n.SourceID, &groupID, &n.Name, &n.Type, &n.Description, &embeddingBytes, &n.ChunkIndex, &createdAt); err != nil {
-			return nil, err
-		}
-		if groupID.Valid {
-			n.GroupID = groupID.String
-		}
-		if createdAt.Valid {
-			n.CreatedAt = createdAt.Time
-		}
-		if len(embeddingBytes) > 0 {
-			if err := json.Unmarshal(embeddingBytes, &n.Embedding); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
-			}
-		}
-		nodes = append(nodes, &n)
-	}
-	return nodes, nil
+	return d.scanNodes(rows)
 }
 
 func (d *DoltDB) GetAllEdges(ctx context.Context, limit int) ([]*ExtractedEdge, error) {
-	query := "SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at FROM extracted_edges"
+	query := "SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at FROM extracted_edges"
 	var rows *sql.Rows
 	var err error
 	if limit > 0 {
@@ -385,29 +456,7 @@ func (d *DoltDB) GetAllEdges(ctx context.Context, limit int) ([]*ExtractedEdge,
 	}
 	defer rows.Close()
 
-	var edges []*ExtractedEdge
-	for rows.Next() {
-		var e ExtractedEdge
-		var embeddingBytes []byte
-		var groupID sql.NullString
-		var createdAt sql.NullTime
-		if err := rows.Scan(&e.ID, &e.SourceID, &groupID, &e.SourceNodeName, &e.TargetNodeName, &e.Relation, &e.Description, &embeddingBytes, &e.Weight, &e.ChunkIndex, &createdAt); err != nil {
-			return nil, err
-		}
-		if groupID.Valid {
-			e.GroupID = groupID.String
-		}
-		if createdAt.Valid {
-			e.CreatedAt = createdAt.Time
-		}
-		if len(embeddingBytes) > 0 {
-			if err := json.Unmarshal(embeddingBytes, &e.Embedding); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
-			}
-		}
-		edges = append(edges, &e)
-	}
-	return edges, nil
+	return d.scanEdges(rows)
 }
 
 func (d *DoltDB) GetStats(ctx context.Context) (*Stats, error) {
@@ -698,3 +747,59 @@ func cosineSimilarity(a, b []float32) float64 {
 
 	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
 }
+
+func (d *DoltDB) scanNodes(rows *sql.Rows) ([]*ExtractedNode, error) {
+	var nodes []*ExtractedNode
+	for rows.Next() {
+		var n ExtractedNode
+		var embeddingBytes []byte
+		var groupID sql.NullString
+		var createdAt sql.NullTime
+		var span spanColumns
+		if err := rows.Scan(&n.ID, &n.SourceID, &groupID, &n.Name, &n.Type, &n.Description, &embeddingBytes, &n.ChunkIndex, &span.start, &span.end, &span.text, &createdAt); err != nil {
+			return nil, err
+		}
+		n.Span = span.span()
+		if groupID.Valid {
+			n.GroupID = groupID.String
+		}
+		if createdAt.Valid {
+			n.CreatedAt = createdAt.Time
+		}
+		if len(embeddingBytes) > 0 {
+			if err := json.Unmarshal(embeddingBytes, &n.Embedding); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
+			}
+		}
+		nodes = append(nodes, &n)
+	}
+	return nodes, nil
+}
+
+func (d *DoltDB) scanEdges(rows *sql.Rows) ([]*ExtractedEdge, error) {
+	var edges []*ExtractedEdge
+	for rows.Next() {
+		var e ExtractedEdge
+		var embeddingBytes []byte
+		var groupID sql.NullString
+		var createdAt sql.NullTime
+		var span spanColumns
+		if err := rows.Scan(&e.ID, &e.SourceID, &groupID, &e.SourceNodeName, &e.TargetNodeName, &e.Relation, &e.Description, &embeddingBytes, &e.Weight, &e.Confidence, &e.ChunkIndex, &span.start, &span.end, &span.text, &createdAt); err != nil {
+			return nil, err
+		}
+		e.Span = span.span()
+		if groupID.Valid {
+			e.GroupID = groupID.String
+		}
+		if createdAt.Valid {
+			e.CreatedAt = createdAt.Time
+		}
+		if len(embeddingBytes) > 0 {
+			if err := json.Unmarshal(embeddingBytes, &e.Embedding); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
+			}
+		}
+		edges = append(edges, &e)
+	}
+	return edges, nil
+}
@@ -0,0 +1,273 @@
+package factstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+var _ Migrator = (*DoltDB)(nil)
+
+// doltMigrations returns the schema history of DoltDB.
+func doltMigrations() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create sources, extracted_nodes and extracted_edges tables",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				tables := []string{
+					`CREATE TABLE IF NOT EXISTS sources (
+						id VARCHAR(255) PRIMARY KEY,
+						name TEXT,
+						content TEXT,
+						group_id VARCHAR(255),
+						metadata JSON,
+						created_at TIMESTAMP
+					)`,
+					`CREATE TABLE IF NOT EXISTS extracted_nodes (
+						id VARCHAR(255) PRIMARY KEY,
+						source_id VARCHAR(255),
+						group_id VARCHAR(255),
+						name TEXT,
+						type VARCHAR(50),
+						description TEXT,
+						embedding JSON,
+						chunk_index INT,
+						created_at TIMESTAMP,
+						FOREIGN KEY (source_id) REFERENCES sources(id)
+					)`,
+					`CREATE TABLE IF NOT EXISTS extracted_edges (
+						id VARCHAR(255) PRIMARY KEY,
+						source_id VARCHAR(255),
+						group_id VARCHAR(255),
+						source_node_name TEXT,
+						target_node_name TEXT,
+						relation TEXT,
+						description TEXT,
+						embedding JSON,
+						weight FLOAT,
+						chunk_index INT,
+						created_at TIMESTAMP,
+						FOREIGN KEY (source_id) REFERENCES sources(id)
+					)`,
+				}
+				for _, table := range tables {
+					if _, err := tx.ExecContext(ctx, table); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     2,
+			Description: "add provenance span columns to extracted_nodes and extracted_edges",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				for _, table := range []string{"extracted_nodes", "extracted_edges"} {
+					if err := addDoltColumns(ctx, tx, table, "span_start INT", "span_end INT", "evidence TEXT"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     3,
+			Description: "add reference and promotion status columns to sources",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				return addDoltColumns(ctx, tx, "sources", "reference TIMESTAMP NULL", "promotion_status VARCHAR(20)",
+					"promoted_at TIMESTAMP NULL", "promoted_by TEXT", "promotion_error TEXT")
+			},
+		},
+		{
+			Version:     4,
+			Description: "mark sources saved before promotion was tracked as unknown",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "UPDATE sources SET promotion_status = 'unknown' WHERE promotion_status IS NULL")
+				return err
+			},
+		},
+		{
+			Version:     5,
+			Description: "add a confidence column to extracted_edges",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				if err := addDoltColumns(ctx, tx, "extracted_edges", "confidence FLOAT DEFAULT 0"); err != nil {
+					return err
+				}
+				// Earlier versions stored the confidence in weight and read
+				// weights in (0, 1] back as confidences
+				_, err := tx.ExecContext(ctx, "UPDATE extracted_edges SET confidence = weight WHERE weight > 0 AND weight <= 1")
+				return err
+			},
+		},
+	}
+}
+
+// addDoltColumns adds columns to table, skipping those that already exist.
+// Dolt has no ADD COLUMN IF NOT EXISTS.
+func addDoltColumns(ctx context.Context, tx *sql.Tx, table string, columns ...string) error {
+	for _, column := range columns {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, column))
+		if err != nil && !strings.Contains(strings.ToLower(err.Error()), "duplicate column") &&
+			!strings.Contains(strings.ToLower(err.Error()), "already exists") {
+			return fmt.Errorf("failed to add column %s to %s: %w", column, table, err)
+		}
+	}
+	return nil
+}
+
+func (d *DoltDB) schema() *schema {
+	return &schema{db: d.db, ph: doltPlaceholder, migrations: doltMigrations()}
+}
+
+// decodeEmbedding parses an embedding stored as a JSON array.
+func (d *DoltDB) decodeEmbedding(raw []byte) ([]float32, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var embedding []float32
+	if err := json.Unmarshal(raw, &embedding); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
+	}
+	return embedding, nil
+}
+
+// encodeEmbedding stores an embedding as a JSON array.
+func (d *DoltDB) encodeEmbedding(embedding []float32) (interface{}, error) {
+	encoded, err := json.Marshal(embedding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding: %w", err)
+	}
+	return encoded, nil
+}
+
+// SchemaStatus reports the applied migrations and the embedding dimensions.
+func (d *DoltDB) SchemaStatus(ctx context.Context) (*SchemaStatus, error) {
+	s := d.schema()
+	status, err := s.status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The embedding tables exist once the first migration has been applied
+	if status.CurrentVersion > 0 {
+		if status.StoredDimensions, err = s.storedDimensions(ctx, d.decodeEmbedding); err != nil {
+			return nil, err
+		}
+	}
+	status.ConfiguredDimensions = d.config.EmbeddingDimensions
+	return status, nil
+}
+
+// Migrate applies pending migrations and, if DoltDBConfig.EmbeddingDimensions
+// differs from the stored vectors, resizes them in one transaction.
+func (d *DoltDB) Migrate(ctx context.Context, opts *MigrateOptions) (*MigrationResult, error) {
+	s := d.schema()
+	applied, err := s.apply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrationResult{Applied: applied}
+
+	dims := d.config.EmbeddingDimensions
+	if dims <= 0 {
+		return result, nil
+	}
+	stored, err := s.storedDimensions(ctx, d.decodeEmbedding)
+	if err != nil {
+		return nil, err
+	}
+	if stored > 0 && stored != dims {
+		vectors, err := d.resizeEmbeddings(ctx, dims, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.ResizedFrom, result.ResizedTo, result.Vectors = stored, dims, vectors
+		result.Reembedded = opts != nil && opts.Embedder != nil
+	}
+	if err := s.setMetadata(ctx, metadataKeyEmbeddingDimensions, strconv.Itoa(dims)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEmbeddingSpace returns the embedding model and dimensions of the stored vectors.
+func (d *DoltDB) GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error) {
+	return d.schema().embeddingSpace(ctx, d.decodeEmbedding)
+}
+
+// SetEmbeddingSpace records the embedding model and dimensions of the stored vectors.
+func (d *DoltDB) SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error {
+	return d.schema().setEmbeddingSpace(ctx, space)
+}
+
+// ScanVectorTexts returns up to limit stored vectors after the given one.
+func (d *DoltDB) ScanVectorTexts(ctx context.Context, after *VectorText, limit int) ([]VectorText, error) {
+	return scanVectorTexts(ctx, d.db, doltPlaceholder, d.decodeEmbedding, after, limit)
+}
+
+// ReplaceEmbeddings replaces every stored vector and records space in one
+// transaction.
+func (d *DoltDB) ReplaceEmbeddings(ctx context.Context, space *types.EmbeddingSpace, vector func(VectorText) ([]float32, error)) (int, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	count := 0
+	for _, table := range vectorTables {
+		n, err := replaceVectors(ctx, tx, doltPlaceholder, table.name, "embedding", table.textColumns, d.decodeEmbedding, d.encodeEmbedding, vector)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	if err := d.schema().setEmbeddingSpaceTx(ctx, tx, space); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return count, nil
+}
+
+// resizeEmbeddings rewrites every stored embedding with dims dimensions and
+// returns the number of vectors rewritten.
+func (d *DoltDB) resizeEmbeddings(ctx context.Context, dims int, opts *MigrateOptions) (int, error) {
+	resized := make(map[string][]*vectorRow)
+	count := 0
+	for _, table := range vectorTables {
+		rows, err := loadVectors(ctx, d.db, doltPlaceholder, table.name, table.textColumns, d.decodeEmbedding)
+		if err != nil {
+			return 0, err
+		}
+		if err := resizeVectors(ctx, rows, dims, opts); err != nil {
+			return 0, err
+		}
+		resized[table.name] = rows
+		count += len(rows)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range vectorTables {
+		if err := writeVectors(ctx, tx, doltPlaceholder, table.name, "embedding", resized[table.name], d.encodeEmbedding); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return count, nil
+}
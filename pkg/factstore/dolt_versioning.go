@@ -0,0 +1,243 @@
+package factstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var _ VersionedFactsDB = (*DoltDB)(nil)
+
+// doltDefaultDatabase is the database Initialize creates when the connection
+// string does not name one.
+const doltDefaultDatabase = "facts"
+
+// asOf returns the AS OF clause reading a table at ref, or "" for the working set.
+func asOf(ref string) (string, error) {
+	if ref == "" {
+		return "", nil
+	}
+	if err := validateRef(ref); err != nil {
+		return "", err
+	}
+	return " AS OF '" + ref + "'", nil
+}
+
+// CommitExtraction commits all tables of the current branch, recording run in
+// the message. A run that changed nothing is committed too so it appears in Log.
+func (d *DoltDB) CommitExtraction(ctx context.Context, run *ExtractionRun, message string) (string, error) {
+	message, err := extractionCommitMessage(run, message)
+	if err != nil {
+		return "", err
+	}
+
+	var hash string
+	if err := d.db.QueryRowContext(ctx, "CALL DOLT_COMMIT('-A', '--allow-empty', '-m', ?)", message).Scan(&hash); err != nil {
+		return "", fmt.Errorf("failed to commit extraction: %w", err)
+	}
+	return hash, nil
+}
+
+// Log returns the commits reachable from ref, newest first.
+func (d *DoltDB) Log(ctx context.Context, ref string, limit int) ([]*Commit, error) {
+	query := "SELECT commit_hash, committer, date, message FROM dolt_log"
+	if ref != "" {
+		if err := validateRef(ref); err != nil {
+			return nil, err
+		}
+		query = "SELECT commit_hash, committer, date, message FROM dolt_log('" + ref + "')"
+	}
+	query += " ORDER BY date DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commit log: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []*Commit
+	for rows.Next() {
+		var c Commit
+		if err := rows.Scan(&c.Hash, &c.Committer, &c.Date, &c.Message); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		c.Run = parseExtractionRun(c.Message)
+		commits = append(commits, &c)
+	}
+	return commits, rows.Err()
+}
+
+// CreateBranch creates a branch at startRef, or at HEAD if startRef is empty.
+func (d *DoltDB) CreateBranch(ctx context.Context, name, startRef string) error {
+	if err := validateRef(name); err != nil {
+		return err
+	}
+
+	var err error
+	if startRef == "" {
+		_, err = d.db.ExecContext(ctx, "CALL DOLT_BRANCH(?)", name)
+	} else {
+		if err := validateRef(startRef); err != nil {
+			return err
+		}
+		_, err = d.db.ExecContext(ctx, "CALL DOLT_BRANCH(?, ?)", name, startRef)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteBranch force-deletes a branch.
+func (d *DoltDB) DeleteBranch(ctx context.Context, name string) error {
+	if _, err := d.db.ExecContext(ctx, "CALL DOLT_BRANCH('-D', ?)", name); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", name, err)
+	}
+	return nil
+}
+
+// OnBranch opens a separate connection pool on the revision database of the
+// branch. Checking out the branch on the shared pool instead would leak the
+// branch into whichever pooled connection ran the checkout.
+func (d *DoltDB) OnBranch(ctx context.Context, name string) (VersionedFactsDB, error) {
+	if err := validateRef(name); err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dolt_branches WHERE name = ?", name).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to look up branch %s: %w", name, err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("branch not found: %s", name)
+	}
+
+	u, err := url.Parse(d.connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	query := u.Query()
+	database := query.Get("database")
+	if database == "" {
+		database = doltDefaultDatabase
+	}
+	// Replace the revision of a connection string that already names a branch
+	if i := strings.Index(database, "/"); i >= 0 {
+		database = database[:i]
+	}
+	query.Set("database", database+"/"+name)
+	u.RawQuery = query.Encode()
+
+	branch, err := openDoltDB(u.String(), d.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open branch %s: %w", name, err)
+	}
+	return branch, nil
+}
+
+// Merge merges branch into the current branch.
+func (d *DoltDB) Merge(ctx context.Context, branch string) error {
+	if _, err := d.db.ExecContext(ctx, "CALL DOLT_MERGE(?)", branch); err != nil {
+		return fmt.Errorf("failed to merge branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// ResetTo hard-resets the current branch to ref.
+func (d *DoltDB) ResetTo(ctx context.Context, ref string) error {
+	if err := validateRef(ref); err != nil {
+		return err
+	}
+	if _, err := d.db.ExecContext(ctx, "CALL DOLT_RESET('--hard', ?)", ref); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", ref, err)
+	}
+	return nil
+}
+
+// GetSourceAt retrieves a source as of ref.
+func (d *DoltDB) GetSourceAt(ctx context.Context, ref, sourceID string) (*Source, error) {
+	clause, err := asOf(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := scanSource(d.db.QueryRowContext(ctx, "SELECT "+sourceColumns+" FROM sources"+clause+" WHERE id = ?", sourceID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("source not found at %s: %s", ref, sourceID)
+		}
+		return nil, fmt.Errorf("failed to scan source: %w", err)
+	}
+	return s, nil
+}
+
+// GetExtractedNodesAt retrieves the extracted nodes of a source as of ref.
+func (d *DoltDB) GetExtractedNodesAt(ctx context.Context, ref, sourceID string) ([]*ExtractedNode, error) {
+	return d.extractedNodesAt(ctx, ref, " WHERE source_id = ?", sourceID)
+}
+
+// GetExtractedEdgesAt retrieves the extracted edges of a source as of ref.
+func (d *DoltDB) GetExtractedEdgesAt(ctx context.Context, ref, sourceID string) ([]*ExtractedEdge, error) {
+	return d.extractedEdgesAt(ctx, ref, " WHERE source_id = ?", sourceID)
+}
+
+// DiffExtractions compares the extracted knowledge of every source between two refs.
+func (d *DoltDB) DiffExtractions(ctx context.Context, fromRef, toRef string) (*ExtractionDiff, error) {
+	if fromRef == "" || toRef == "" {
+		return nil, fmt.Errorf("both refs are required")
+	}
+
+	oldNodes, err := d.extractedNodesAt(ctx, fromRef, "")
+	if err != nil {
+		return nil, err
+	}
+	oldEdges, err := d.extractedEdgesAt(ctx, fromRef, "")
+	if err != nil {
+		return nil, err
+	}
+	newNodes, err := d.extractedNodesAt(ctx, toRef, "")
+	if err != nil {
+		return nil, err
+	}
+	newEdges, err := d.extractedEdgesAt(ctx, toRef, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return diffExtractionsBySource(oldNodes, oldEdges, newNodes, newEdges), nil
+}
+
+func (d *DoltDB) extractedNodesAt(ctx context.Context, ref, where string, args ...interface{}) ([]*ExtractedNode, error) {
+	clause, err := asOf(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at FROM extracted_nodes"+clause+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extracted nodes: %w", err)
+	}
+	defer rows.Close()
+
+	return d.scanNodes(rows)
+}
+
+func (d *DoltDB) extractedEdgesAt(ctx context.Context, ref, where string, args ...interface{}) ([]*ExtractedEdge, error) {
+	clause, err := asOf(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at FROM extracted_edges"+clause+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extracted edges: %w", err)
+	}
+	defer rows.Close()
+
+	return d.scanEdges(rows)
+}
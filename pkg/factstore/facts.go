@@ -2,6 +2,10 @@ package factstore
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/soundprediction/predicato/pkg/types"
@@ -96,6 +100,22 @@ type FactStoreConfig struct {
 	MaxConnections int `json:"max_connections,omitempty"`
 }
 
+// PromotionStatus records whether the extracted knowledge of a source has been
+// promoted to the graph.
+type PromotionStatus string
+
+const (
+	// PromotionPending marks a source whose latest extraction is not promoted yet.
+	PromotionPending PromotionStatus = "pending"
+	// PromotionPromoted marks a source whose latest extraction is in the graph.
+	PromotionPromoted PromotionStatus = "promoted"
+	// PromotionFailed marks a source whose last promotion attempt failed.
+	PromotionFailed PromotionStatus = "failed"
+	// PromotionUnknown marks a source saved before promotion was tracked, whose
+	// extraction may or may not be in the graph.
+	PromotionUnknown PromotionStatus = "unknown"
+)
+
 // Source represents the origin of extracted information.
 type Source struct {
 	ID        string                 `json:"id"`
@@ -104,8 +124,41 @@ type Source struct {
 	GroupID   string                 `json:"group_id"`
 	Metadata  map[string]interface{} `json:"metadata"`
 	CreatedAt time.Time              `json:"created_at"`
+	// Reference is the episode reference time, used to promote sources in order.
+	Reference time.Time `json:"reference,omitempty"`
+
+	// PromotionStatus is PromotionPending until the source is promoted. SaveSource
+	// stores an empty status as pending for a new source and keeps the promotion
+	// state of an existing one.
+	PromotionStatus PromotionStatus `json:"promotion_status,omitempty"`
+	// PromotedAt is the time of the last promotion attempt.
+	PromotedAt *time.Time `json:"promoted_at,omitempty"`
+	// PromotedBy names the graph modeler of the last promotion attempt.
+	PromotedBy string `json:"promoted_by,omitempty"`
+	// PromotionError holds the error of the last promotion attempt if it failed.
+	PromotionError string `json:"promotion_error,omitempty"`
 }
 
+// SourceFilter selects sources for ListSources. Empty fields match every source.
+type SourceFilter struct {
+	GroupID string
+	// Statuses matches sources in any of the given promotion states.
+	Statuses []PromotionStatus
+	// Limit caps the number of sources returned. Zero means no limit.
+	Limit int
+}
+
+// Source metadata keys recorded by the extraction pipeline. They allow sources
+// to be selected later for re-processing.
+const (
+	// MetadataKeyPromptVersion holds the prompt/model version used for extraction.
+	MetadataKeyPromptVersion = "prompt_version"
+	// MetadataKeyEpisodeSource holds the originating Episode.Source (URL, file path, ...).
+	MetadataKeyEpisodeSource = "episode_source"
+	// MetadataKeyExtractedAt holds the RFC3339 time of the most recent extraction.
+	MetadataKeyExtractedAt = "extracted_at"
+)
+
 // ExtractedNode is an alias to types.ExtractedNode for backward compatibility.
 // The canonical definition is in pkg/types/extraction.go.
 type ExtractedNode = types.ExtractedNode
@@ -125,6 +178,28 @@ type FactsDB interface {
 	// SaveExtractedKnowledge saves the raw extraction results.
 	SaveExtractedKnowledge(ctx context.Context, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error
 
+	// ReplaceExtractedKnowledge atomically replaces all extracted nodes and edges
+	// of a source with the given ones.
+	ReplaceExtractedKnowledge(ctx context.Context, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error
+
+	// DeleteSource deletes a source together with its extracted nodes and edges.
+	DeleteSource(ctx context.Context, sourceID string) error
+
+	// UpdateNode overwrites the name, type, description, embedding, chunk index
+	// and span of the extracted node with node.ID. Renaming a node renames it in
+	// the extracted edges of the same source.
+	UpdateNode(ctx context.Context, node *ExtractedNode) error
+
+	// UpdateEdge overwrites the endpoints, relation, description, embedding,
+	// weight, confidence, chunk index and span of the extracted edge with
+	// edge.ID.
+	UpdateEdge(ctx context.Context, edge *ExtractedEdge) error
+
+	// PurgeEntity deletes the extracted nodes named name in groupID and the
+	// extracted edges that reference them, and redacts name from source content
+	// and from the descriptions and evidence of the remaining rows.
+	PurgeEntity(ctx context.Context, groupID, name string) (*PurgeStats, error)
+
 	// GetSource retrieves a source by ID.
 	GetSource(ctx context.Context, sourceID string) (*Source, error)
 
@@ -137,6 +212,13 @@ type FactsDB interface {
 	// GetAllSources retrieves all sources.
 	GetAllSources(ctx context.Context, limit int) ([]*Source, error)
 
+	// ListSources retrieves the sources matching filter, oldest Reference first.
+	ListSources(ctx context.Context, filter *SourceFilter) ([]*Source, error)
+
+	// SetPromotionStatus records the outcome of promoting a source to the graph
+	// with the named modeler. promotionErr is stored for PromotionFailed.
+	SetPromotionStatus(ctx context.Context, sourceID string, status PromotionStatus, modelerName string, promotionErr error) error
+
 	// GetAllNodes retrieves all nodes (with optional limit).
 	GetAllNodes(ctx context.Context, limit int) ([]*ExtractedNode, error)
 
@@ -171,3 +253,116 @@ type Stats struct {
 	NodeCount   int64
 	EdgeCount   int64
 }
+
+// sourceColumns lists the sources columns read by scanSource.
+const sourceColumns = "id, name, content, group_id, metadata, created_at, reference, promotion_status, promoted_at, promoted_by, promotion_error"
+
+// scanSource scans a row of sourceColumns.
+func scanSource(row interface{ Scan(...interface{}) error }) (*Source, error) {
+	var s Source
+	var metadataBytes []byte
+	var reference, promotedAt sql.NullTime
+	var status, promotedBy, promotionErr sql.NullString
+
+	if err := row.Scan(&s.ID, &s.Name, &s.Content, &s.GroupID, &metadataBytes, &s.CreatedAt,
+		&reference, &status, &promotedAt, &promotedBy, &promotionErr); err != nil {
+		return nil, err
+	}
+
+	if len(metadataBytes) > 0 {
+		if err := json.Unmarshal(metadataBytes, &s.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+	// Sources saved before references were recorded fall back to their creation time
+	s.Reference = s.CreatedAt
+	if reference.Valid {
+		s.Reference = reference.Time
+	}
+	s.PromotionStatus = PromotionStatus(status.String)
+	if s.PromotionStatus == "" {
+		s.PromotionStatus = PromotionUnknown
+	}
+	if promotedAt.Valid {
+		t := promotedAt.Time
+		s.PromotedAt = &t
+	}
+	s.PromotedBy = promotedBy.String
+	s.PromotionError = promotionErr.String
+	return &s, nil
+}
+
+// scanSourceRows scans all rows of sourceColumns.
+func scanSourceRows(rows *sql.Rows) ([]*Source, error) {
+	var sources []*Source
+	for rows.Next() {
+		s, err := scanSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, s)
+	}
+	return sources, rows.Err()
+}
+
+// sourceValues returns the reference, promotion_status, promoted_at,
+// promoted_by and promotion_error values to store for source.
+func sourceValues(source *Source) (interface{}, string, interface{}, string, string) {
+	var reference interface{}
+	if !source.Reference.IsZero() {
+		reference = source.Reference
+	}
+	status := source.PromotionStatus
+	if status == "" {
+		status = PromotionPending
+	}
+	var promotedAt interface{}
+	if source.PromotedAt != nil {
+		promotedAt = *source.PromotedAt
+	}
+	return reference, string(status), promotedAt, source.PromotedBy, source.PromotionError
+}
+
+// sourceFilterClauses returns the WHERE conditions and arguments for filter.
+func sourceFilterClauses(filter *SourceFilter, ph placeholder) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if filter == nil {
+		return conditions, args
+	}
+	if filter.GroupID != "" {
+		args = append(args, filter.GroupID)
+		conditions = append(conditions, "group_id = "+ph(len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		var statuses []string
+		for _, status := range filter.Statuses {
+			args = append(args, string(status))
+			statuses = append(statuses, ph(len(args)))
+		}
+		conditions = append(conditions, "promotion_status IN ("+strings.Join(statuses, ", ")+")")
+	}
+	return conditions, args
+}
+
+// spanColumns receives the nullable span_start, span_end and evidence columns.
+type spanColumns struct {
+	start sql.NullInt64
+	end   sql.NullInt64
+	text  sql.NullString
+}
+
+func (c *spanColumns) span() *types.Span {
+	if !c.start.Valid || !c.end.Valid {
+		return nil
+	}
+	return &types.Span{Start: int(c.start.Int64), End: int(c.end.Int64), Text: c.text.String}
+}
+
+// spanValues returns the span_start, span_end and evidence values to store for span.
+func spanValues(span *types.Span) (interface{}, interface{}, interface{}) {
+	if !span.IsValid() {
+		return nil, nil, nil
+	}
+	return span.Start, span.End, span.Text
+}
@@ -375,3 +375,110 @@ func TestStatsJSON(t *testing.T) {
 		t.Errorf("SourceCount mismatch: expected %d, got %d", stats.SourceCount, unmarshaled.SourceCount)
 	}
 }
+
+// TestSourceFilterClauses tests the WHERE conditions built for ListSources
+func TestSourceFilterClauses(t *testing.T) {
+	conditions, args := sourceFilterClauses(&SourceFilter{
+		GroupID:  "group-1",
+		Statuses: []PromotionStatus{PromotionPending, PromotionFailed},
+	}, postgresPlaceholder)
+
+	want := []string{
+		"group_id = $1",
+		"promotion_status IN ($2, $3)",
+	}
+	if len(conditions) != len(want) {
+		t.Fatalf("conditions = %v, want %v", conditions, want)
+	}
+	for i := range want {
+		if conditions[i] != want[i] {
+			t.Errorf("conditions[%d] = %q, want %q", i, conditions[i], want[i])
+		}
+	}
+	if len(args) != 3 || args[0] != "group-1" || args[1] != "pending" || args[2] != "failed" {
+		t.Errorf("args = %v, want [group-1 pending failed]", args)
+	}
+
+	conditions, _ = sourceFilterClauses(&SourceFilter{Statuses: []PromotionStatus{PromotionPromoted}}, doltPlaceholder)
+	if len(conditions) != 1 || conditions[0] != "promotion_status IN (?)" {
+		t.Errorf("conditions = %v, want only promoted sources", conditions)
+	}
+
+	if conditions, args := sourceFilterClauses(nil, doltPlaceholder); len(conditions) != 0 || len(args) != 0 {
+		t.Errorf("nil filter produced %v %v", conditions, args)
+	}
+}
+
+// TestSourceValues tests the promotion values stored for a source
+func TestSourceValues(t *testing.T) {
+	reference, status, promotedAt, _, _ := sourceValues(&Source{ID: "source-1"})
+	if reference != nil || promotedAt != nil {
+		t.Errorf("unset reference and promotion time should be stored as NULL, got %v %v", reference, promotedAt)
+	}
+	if status != string(PromotionPending) {
+		t.Errorf("status = %q, want pending", status)
+	}
+
+	now := time.Now()
+	reference, status, promotedAt, promotedBy, promotionErr := sourceValues(&Source{
+		Reference:       now,
+		PromotionStatus: PromotionFailed,
+		PromotedAt:      &now,
+		PromotedBy:      "*modeler.DefaultModeler",
+		PromotionError:  "boom",
+	})
+	if reference != now || promotedAt != now || status != "failed" || promotedBy != "*modeler.DefaultModeler" || promotionErr != "boom" {
+		t.Errorf("unexpected values %v %v %v %v %v", reference, status, promotedAt, promotedBy, promotionErr)
+	}
+}
+
+// TestDiffExtractedKnowledge tests diffing two extractions of the same source
+func TestDiffExtractedKnowledge(t *testing.T) {
+	oldNodes := []*ExtractedNode{
+		{ID: "n1", Name: "Alice", Type: "person"},
+		{ID: "n2", Name: "Acme Corp", Type: "organization"},
+		{ID: "n3", Name: "Bob", Type: "person"},
+	}
+	oldEdges := []*ExtractedEdge{
+		{ID: "e1", SourceNodeName: "Alice", TargetNodeName: "Acme Corp", Relation: "WORKS_AT"},
+		{ID: "e2", SourceNodeName: "Bob", TargetNodeName: "Acme Corp", Relation: "WORKS_AT"},
+	}
+	newNodes := []*ExtractedNode{
+		{ID: "n4", Name: "alice", Type: "person"},
+		{ID: "n5", Name: "Acme  Corp", Type: "organization"},
+		{ID: "n6", Name: "Acme Corp", Type: "organization"},
+		{ID: "n7", Name: "Carol", Type: "person"},
+	}
+	newEdges := []*ExtractedEdge{
+		{ID: "e3", SourceNodeName: "Alice", TargetNodeName: "Acme Corp", Relation: "works_at"},
+		{ID: "e4", SourceNodeName: "Carol", TargetNodeName: "Acme Corp", Relation: "WORKS_AT"},
+	}
+
+	diff := DiffExtractedKnowledge(oldNodes, oldEdges, newNodes, newEdges)
+
+	if diff.IsEmpty() {
+		t.Fatal("Expected a non-empty diff")
+	}
+	if len(diff.UnchangedNodes) != 2 || diff.UnchangedNodes[0].ID != "n1" || diff.UnchangedNodes[1].ID != "n2" {
+		t.Errorf("Expected old nodes n1 and n2 to be unchanged, got %+v", diff.UnchangedNodes)
+	}
+	if len(diff.RemovedNodes) != 1 || diff.RemovedNodes[0].ID != "n3" {
+		t.Errorf("Expected node n3 to be removed, got %+v", diff.RemovedNodes)
+	}
+	if len(diff.AddedNodes) != 1 || diff.AddedNodes[0].ID != "n7" {
+		t.Errorf("Expected node n7 to be added, got %+v", diff.AddedNodes)
+	}
+	if len(diff.UnchangedEdges) != 1 || diff.UnchangedEdges[0].ID != "e1" {
+		t.Errorf("Expected edge e1 to be unchanged, got %+v", diff.UnchangedEdges)
+	}
+	if len(diff.RemovedEdges) != 1 || diff.RemovedEdges[0].ID != "e2" {
+		t.Errorf("Expected edge e2 to be removed, got %+v", diff.RemovedEdges)
+	}
+	if len(diff.AddedEdges) != 1 || diff.AddedEdges[0].ID != "e4" {
+		t.Errorf("Expected edge e4 to be added, got %+v", diff.AddedEdges)
+	}
+
+	if !DiffExtractedKnowledge(oldNodes, oldEdges, oldNodes, oldEdges).IsEmpty() {
+		t.Error("Expected diff of identical extractions to be empty")
+	}
+}
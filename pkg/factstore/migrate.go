@@ -0,0 +1,645 @@
+package factstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// ErrEmbeddingDimensionsChanged is returned by Initialize when the stored
+// vectors do not have the configured embedding dimensions. Migrate resizes them.
+var ErrEmbeddingDimensionsChanged = errors.New("embedding dimensions changed")
+
+// Migration is a schema change of a fact store backend. The migrations of a
+// backend are applied in Version order, each at most once. Migrations are
+// idempotent so that databases created before versioning can adopt them.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, tx *sql.Tx) error
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Version     int        `json:"version"`
+	Description string     `json:"description"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
+
+// SchemaStatus describes the schema of a fact store.
+type SchemaStatus struct {
+	Migrations     []MigrationStatus `json:"migrations"`
+	CurrentVersion int               `json:"current_version"`
+	LatestVersion  int               `json:"latest_version"`
+	// StoredDimensions is the size of the stored vectors, or 0 if unknown.
+	StoredDimensions int `json:"stored_dimensions"`
+	// ConfiguredDimensions is the size the store was opened with, or 0 if the
+	// backend does not track it.
+	ConfiguredDimensions int `json:"configured_dimensions"`
+}
+
+// Pending returns the migrations that have not been applied.
+func (s *SchemaStatus) Pending() []MigrationStatus {
+	var pending []MigrationStatus
+	for _, m := range s.Migrations {
+		if m.AppliedAt == nil {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// DimensionsChanged reports whether the stored vectors must be resized.
+func (s *SchemaStatus) DimensionsChanged() bool {
+	return s.StoredDimensions > 0 && s.ConfiguredDimensions > 0 && s.StoredDimensions != s.ConfiguredDimensions
+}
+
+// MigrateOptions configures Migrate.
+type MigrateOptions struct {
+	// Embedder re-embeds the stored nodes and edges when the embedding
+	// dimensions changed. If nil, the stored vectors are zero-padded or
+	// truncated instead, which keeps them comparable only for embedding models
+	// trained for truncation (Matryoshka embeddings).
+	Embedder embedder.Client
+	// BatchSize is the number of texts re-embedded per call. Default: 100.
+	BatchSize int
+}
+
+// MigrationResult describes what Migrate changed.
+type MigrationResult struct {
+	Applied []MigrationStatus `json:"applied"`
+	// ResizedFrom and ResizedTo are the old and new embedding dimensions if
+	// the stored vectors were resized.
+	ResizedFrom int  `json:"resized_from,omitempty"`
+	ResizedTo   int  `json:"resized_to,omitempty"`
+	Reembedded  bool `json:"reembedded,omitempty"`
+	// Vectors is the number of node and edge vectors rewritten.
+	Vectors int `json:"vectors,omitempty"`
+}
+
+// Migrator is implemented by fact stores with a versioned schema. Initialize
+// applies pending migrations; Migrate also resizes the stored vectors when
+// the embedding dimensions changed.
+type Migrator interface {
+	Migrate(ctx context.Context, opts *MigrateOptions) (*MigrationResult, error)
+	SchemaStatus(ctx context.Context) (*SchemaStatus, error)
+
+	// GetEmbeddingSpace returns the embedding model and dimensions of the
+	// stored vectors, or nil if neither is known.
+	GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error)
+
+	// SetEmbeddingSpace records the embedding model and dimensions of the
+	// stored vectors.
+	SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error
+
+	// ScanVectorTexts returns up to limit stored node and edge vectors with
+	// the text each was computed from, in table and ID order, starting after
+	// the given vector; nil starts at the first.
+	ScanVectorTexts(ctx context.Context, after *VectorText, limit int) ([]VectorText, error)
+
+	// ReplaceEmbeddings replaces every stored vector with the one returned by
+	// vector and records space, in a single transaction, so readers never see
+	// a mix of both models. If vector fails, nothing is written. It returns
+	// the number of vectors written.
+	ReplaceEmbeddings(ctx context.Context, space *types.EmbeddingSpace, vector func(VectorText) ([]float32, error)) (int, error)
+}
+
+// VectorText is a stored vector of an extracted node or edge and the text
+// it was computed from.
+type VectorText struct {
+	Table string
+	ID    string
+	Text  string
+}
+
+const (
+	// metadataKeyEmbeddingDimensions holds the size of the stored vectors.
+	metadataKeyEmbeddingDimensions = "embedding_dimensions"
+	// metadataKeyEmbeddingModel holds the ID of the model of the stored vectors.
+	metadataKeyEmbeddingModel = "embedding_model"
+)
+
+// schema applies the migrations of a backend and tracks them in the
+// schema_version table. Other schema facts, such as the embedding dimensions,
+// are kept in the factstore_metadata table.
+type schema struct {
+	db         *sql.DB
+	ph         placeholder
+	migrations []Migration
+}
+
+func (s *schema) ensureTables(ctx context.Context) error {
+	tables := []string{
+		`CREATE TABLE IF NOT EXISTS schema_version (
+			version INT PRIMARY KEY,
+			description TEXT,
+			applied_at TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS factstore_metadata (
+			name VARCHAR(255) PRIMARY KEY,
+			value TEXT
+		)`,
+	}
+	for _, table := range tables {
+		if _, err := s.db.ExecContext(ctx, table); err != nil {
+			return fmt.Errorf("failed to create schema tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// status returns the applied state of every migration.
+func (s *schema) status(ctx context.Context) (*SchemaStatus, error) {
+	if err := s.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_version: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_version: %w", err)
+		}
+		applied[version] = appliedAt.Time
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_version: %w", err)
+	}
+
+	migrations := append([]Migration(nil), s.migrations...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	status := &SchemaStatus{}
+	for _, m := range migrations {
+		ms := MigrationStatus{Version: m.Version, Description: m.Description}
+		if at, ok := applied[m.Version]; ok {
+			at := at
+			ms.AppliedAt = &at
+			if m.Version > status.CurrentVersion {
+				status.CurrentVersion = m.Version
+			}
+		}
+		status.Migrations = append(status.Migrations, ms)
+		status.LatestVersion = m.Version
+	}
+	return status, nil
+}
+
+// apply runs the pending migrations in order, each in its own transaction.
+func (s *schema) apply(ctx context.Context) ([]MigrationStatus, error) {
+	status, err := s.status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]Migration, len(s.migrations))
+	for _, m := range s.migrations {
+		byVersion[m.Version] = m
+	}
+
+	var applied []MigrationStatus
+	for _, pending := range status.Pending() {
+		m := byVersion[pending.Version]
+		if err := s.applyOne(ctx, m); err != nil {
+			return applied, err
+		}
+		now := time.Now().UTC()
+		pending.AppliedAt = &now
+		applied = append(applied, pending)
+	}
+	return applied, nil
+}
+
+func (s *schema) applyOne(ctx context.Context, m Migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := m.Up(ctx, tx); err != nil {
+		return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Description, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO schema_version (version, description, applied_at) VALUES (%s, %s, %s)", s.ph(1), s.ph(2), s.ph(3)),
+		m.Version, m.Description, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+	return nil
+}
+
+// metadata returns a factstore_metadata value, or "" if it is not set.
+func (s *schema) metadata(ctx context.Context, name string) (string, error) {
+	var value sql.NullString
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM factstore_metadata WHERE name = "+s.ph(1), name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read metadata %s: %w", name, err)
+	}
+	return value.String, nil
+}
+
+// setMetadata sets a factstore_metadata value.
+func (s *schema) setMetadata(ctx context.Context, name, value string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.setMetadataTx(ctx, tx, name, value); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// setMetadataTx sets a factstore_metadata value within tx.
+func (s *schema) setMetadataTx(ctx context.Context, tx *sql.Tx, name, value string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM factstore_metadata WHERE name = "+s.ph(1), name); err != nil {
+		return fmt.Errorf("failed to write metadata %s: %w", name, err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO factstore_metadata (name, value) VALUES (%s, %s)", s.ph(1), s.ph(2)), name, value); err != nil {
+		return fmt.Errorf("failed to write metadata %s: %w", name, err)
+	}
+	return nil
+}
+
+// storedDimensions returns the recorded size of the stored vectors. Stores
+// created before it was recorded are measured from a stored vector, using
+// decode to parse the embedding column; 0 means no vector is stored.
+func (s *schema) storedDimensions(ctx context.Context, decode func([]byte) ([]float32, error)) (int, error) {
+	value, err := s.metadata(ctx, metadataKeyEmbeddingDimensions)
+	if err != nil {
+		return 0, err
+	}
+	if value != "" {
+		dims, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s metadata %q: %w", metadataKeyEmbeddingDimensions, value, err)
+		}
+		return dims, nil
+	}
+
+	for _, table := range vectorTables {
+		dims, err := s.sampleDimensions(ctx, table.name, decode)
+		if err != nil || dims > 0 {
+			return dims, err
+		}
+	}
+	return 0, nil
+}
+
+// sampleDimensions returns the size of the first non-empty embedding of table.
+// Empty embeddings may be stored as JSON null or [] rather than NULL, so rows
+// are scanned until one decodes to a vector.
+func (s *schema) sampleDimensions(ctx context.Context, table string, decode func([]byte) ([]float32, error)) (int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT embedding FROM "+table+" WHERE embedding IS NOT NULL")
+	if err != nil {
+		return 0, fmt.Errorf("failed to sample %s embeddings: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return 0, fmt.Errorf("failed to scan %s embedding: %w", table, err)
+		}
+		embedding, err := decode(raw)
+		if err != nil {
+			return 0, err
+		}
+		if len(embedding) > 0 {
+			return len(embedding), nil
+		}
+	}
+	return 0, rows.Err()
+}
+
+// checkDimensions records configured as the stored dimensions if none are
+// known yet, and returns ErrEmbeddingDimensionsChanged if they differ.
+func (s *schema) checkDimensions(ctx context.Context, configured int, decode func([]byte) ([]float32, error)) error {
+	if configured <= 0 {
+		return nil
+	}
+	stored, err := s.storedDimensions(ctx, decode)
+	if err != nil {
+		return err
+	}
+	if stored > 0 && stored != configured {
+		return fmt.Errorf("%w: stored vectors have %d dimensions, configured %d; run `predicato factstore migrate` to resize them",
+			ErrEmbeddingDimensionsChanged, stored, configured)
+	}
+	return s.setMetadata(ctx, metadataKeyEmbeddingDimensions, strconv.Itoa(configured))
+}
+
+// embeddingSpace returns the recorded embedding space, or nil if neither
+// the model nor the dimensions are known.
+func (s *schema) embeddingSpace(ctx context.Context, decode func([]byte) ([]float32, error)) (*types.EmbeddingSpace, error) {
+	model, err := s.metadata(ctx, metadataKeyEmbeddingModel)
+	if err != nil {
+		return nil, err
+	}
+	dims, err := s.storedDimensions(ctx, decode)
+	if err != nil {
+		return nil, err
+	}
+	if model == "" && dims == 0 {
+		return nil, nil
+	}
+	return &types.EmbeddingSpace{Model: model, Dimensions: dims}, nil
+}
+
+// setEmbeddingSpace records the model and dimensions of space.
+func (s *schema) setEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error {
+	if space == nil {
+		return nil
+	}
+	if space.Model != "" {
+		if err := s.setMetadata(ctx, metadataKeyEmbeddingModel, space.Model); err != nil {
+			return err
+		}
+	}
+	if space.Dimensions > 0 {
+		return s.setMetadata(ctx, metadataKeyEmbeddingDimensions, strconv.Itoa(space.Dimensions))
+	}
+	return nil
+}
+
+// setEmbeddingSpaceTx records the model and dimensions of space within tx.
+func (s *schema) setEmbeddingSpaceTx(ctx context.Context, tx *sql.Tx, space *types.EmbeddingSpace) error {
+	if space == nil {
+		return nil
+	}
+	if space.Model != "" {
+		if err := s.setMetadataTx(ctx, tx, metadataKeyEmbeddingModel, space.Model); err != nil {
+			return err
+		}
+	}
+	if space.Dimensions > 0 {
+		return s.setMetadataTx(ctx, tx, metadataKeyEmbeddingDimensions, strconv.Itoa(space.Dimensions))
+	}
+	return nil
+}
+
+// vectorRow is a stored embedding to resize together with the text it embeds.
+type vectorRow struct {
+	id        string
+	text      string
+	embedding []float32
+}
+
+// vectorText returns the text a stored embedding was computed from: the name
+// and description of a node, or the description of an edge.
+func vectorText(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// resizeVectors sets the embeddings of rows to dims dimensions, re-embedding
+// their text with opts.Embedder or padding or truncating the stored vectors.
+func resizeVectors(ctx context.Context, rows []*vectorRow, dims int, opts *MigrateOptions) error {
+	if opts == nil || opts.Embedder == nil {
+		for _, row := range rows {
+			row.embedding = resizeVector(row.embedding, dims)
+		}
+		return nil
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		texts := make([]string, end-start)
+		for i, row := range rows[start:end] {
+			texts[i] = row.text
+		}
+		embeddings, err := opts.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to re-embed: %w", err)
+		}
+		if len(embeddings) != len(texts) {
+			return fmt.Errorf("failed to re-embed: got %d embeddings for %d texts", len(embeddings), len(texts))
+		}
+		for i, embedding := range embeddings {
+			if len(embedding) != dims {
+				return fmt.Errorf("embedder returned %d dimensions, configured %d", len(embedding), dims)
+			}
+			rows[start+i].embedding = embedding
+		}
+	}
+	return nil
+}
+
+// resizeVector zero-pads or truncates v to dims dimensions.
+func resizeVector(v []float32, dims int) []float32 {
+	resized := make([]float32, dims)
+	copy(resized, v)
+	return resized
+}
+
+// vectorTables lists the tables with embeddings and the columns of the text
+// each embedding is computed from.
+var vectorTables = []struct {
+	name        string
+	textColumns []string
+}{
+	{"extracted_nodes", []string{"name", "description"}},
+	{"extracted_edges", []string{"description"}},
+}
+
+// vectorPageSize is the number of vectors read per query when paging
+// through a table.
+const vectorPageSize = 500
+
+// queryer is a *sql.DB or *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadVectors reads the stored embeddings of table, parsed with decode.
+func loadVectors(ctx context.Context, db *sql.DB, ph placeholder, table string, textColumns []string, decode func([]byte) ([]float32, error)) ([]*vectorRow, error) {
+	var vectors []*vectorRow
+	after := ""
+	for {
+		page, last, err := loadVectorPage(ctx, db, ph, table, textColumns, decode, after, vectorPageSize)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, page...)
+		if last == "" {
+			return vectors, nil
+		}
+		after = last
+	}
+}
+
+// loadVectorPage reads up to limit stored embeddings of table with IDs after
+// afterID, parsed with decode. It also returns the last ID read, which is ""
+// once the table is exhausted; rows whose embedding decodes to nothing are
+// read but not returned.
+func loadVectorPage(ctx context.Context, q queryer, ph placeholder, table string, textColumns []string, decode func([]byte) ([]float32, error), afterID string, limit int) ([]*vectorRow, string, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT id, %s, embedding FROM %s WHERE embedding IS NOT NULL AND id > %s ORDER BY id LIMIT %d",
+		strings.Join(textColumns, ", "), table, ph(1), limit), afterID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query %s embeddings: %w", table, err)
+	}
+	defer rows.Close()
+
+	var vectors []*vectorRow
+	last := ""
+	read := 0
+	for rows.Next() {
+		var id string
+		var raw []byte
+		texts := make([]sql.NullString, len(textColumns))
+		dest := []interface{}{&id}
+		for i := range texts {
+			dest = append(dest, &texts[i])
+		}
+		dest = append(dest, &raw)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, "", fmt.Errorf("failed to scan %s embedding: %w", table, err)
+		}
+		last = id
+		read++
+
+		embedding, err := decode(raw)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(embedding) == 0 {
+			continue
+		}
+		parts := make([]string, len(texts))
+		for i, text := range texts {
+			parts[i] = text.String
+		}
+		vectors = append(vectors, &vectorRow{id: id, text: vectorText(parts...), embedding: embedding})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read %s embeddings: %w", table, err)
+	}
+	if read < limit {
+		last = ""
+	}
+	return vectors, last, nil
+}
+
+// scanVectorTexts returns up to limit stored vectors of every table after
+// the given one, implementing Migrator.ScanVectorTexts.
+func scanVectorTexts(ctx context.Context, q queryer, ph placeholder, decode func([]byte) ([]float32, error), after *VectorText, limit int) ([]VectorText, error) {
+	if limit <= 0 {
+		limit = vectorPageSize
+	}
+	var texts []VectorText
+	started := after == nil
+	for _, table := range vectorTables {
+		afterID := ""
+		if !started {
+			if table.name != after.Table {
+				continue
+			}
+			started = true
+			afterID = after.ID
+		}
+		for len(texts) < limit {
+			page, last, err := loadVectorPage(ctx, q, ph, table.name, table.textColumns, decode, afterID, limit-len(texts))
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range page {
+				texts = append(texts, VectorText{Table: table.name, ID: row.id, Text: row.text})
+			}
+			if last == "" {
+				break
+			}
+			afterID = last
+		}
+		if len(texts) >= limit {
+			break
+		}
+	}
+	if !started {
+		return nil, fmt.Errorf("unknown vector table %q", after.Table)
+	}
+	return texts, nil
+}
+
+// replaceVectors pages through the stored vectors of table within tx and
+// writes the one returned by vector for each to column, returning the number
+// of vectors written.
+func replaceVectors(ctx context.Context, tx *sql.Tx, ph placeholder, table, column string, textColumns []string,
+	decode func([]byte) ([]float32, error), encode func([]float32) (interface{}, error), vector func(VectorText) ([]float32, error)) (int, error) {
+	count := 0
+	after := ""
+	for {
+		rows, last, err := loadVectorPage(ctx, tx, ph, table, textColumns, decode, after, vectorPageSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			if row.embedding, err = vector(VectorText{Table: table, ID: row.id, Text: row.text}); err != nil {
+				return 0, err
+			}
+		}
+		if err := writeVectors(ctx, tx, ph, table, column, rows, encode); err != nil {
+			return 0, err
+		}
+		count += len(rows)
+		if last == "" {
+			return count, nil
+		}
+		after = last
+	}
+}
+
+// writeVectors stores the embeddings of rows in column of table within tx,
+// encoded for the backend with encode.
+func writeVectors(ctx context.Context, tx *sql.Tx, ph placeholder, table, column string, rows []*vectorRow, encode func([]float32) (interface{}, error)) error {
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE id = %s", table, column, ph(1), ph(2))
+	for _, row := range rows {
+		embedding, err := encode(row.embedding)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, embedding, row.id); err != nil {
+			return fmt.Errorf("failed to update %s embedding %s: %w", table, row.id, err)
+		}
+	}
+	return nil
+}
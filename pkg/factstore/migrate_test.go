@@ -0,0 +1,286 @@
+package factstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// fakeEmbedder embeds every text as a vector of its length, recording the texts
+type fakeEmbedder struct {
+	dims  int
+	texts []string
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		f.texts = append(f.texts, text)
+		embeddings[i] = make([]float32, f.dims)
+		embeddings[i][0] = float32(len(text))
+	}
+	return embeddings, nil
+}
+
+func (f *fakeEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := f.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (f *fakeEmbedder) Dimensions() int { return f.dims }
+
+func (f *fakeEmbedder) Close() error { return nil }
+
+// openTestDolt opens the Dolt database in dir with the given embedding dimensions
+func openTestDolt(t *testing.T, dir string, dims int) *DoltDB {
+	t.Helper()
+	config := DefaultDoltDBConfig()
+	config.EmbeddingDimensions = dims
+	db, err := openDoltDB("file://"+dir+"?commitname=Test&commitemail=test@example.com&database=facts", config)
+	if err != nil {
+		t.Fatalf("openDoltDB: %v", err)
+	}
+	return db
+}
+
+// TestMigrateAdoptsExistingSchema tests that stores created before schema
+// versioning are brought up to date
+func TestMigrateAdoptsExistingSchema(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDolt(t, t.TempDir(), 0)
+	defer db.Close()
+
+	// A sources table as created before the promotion columns existed
+	if _, err := db.db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS facts"); err != nil {
+		t.Fatalf("create database: %v", err)
+	}
+	if _, err := db.db.ExecContext(ctx, "CREATE TABLE sources (id VARCHAR(255) PRIMARY KEY, name TEXT, content TEXT, group_id VARCHAR(255), metadata JSON, created_at TIMESTAMP)"); err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+
+	status, err := db.SchemaStatus(ctx)
+	if err != nil {
+		t.Fatalf("SchemaStatus: %v", err)
+	}
+	if status.CurrentVersion != 0 || status.LatestVersion != 5 || len(status.Pending()) != 5 {
+		t.Fatalf("status = %+v, want 5 pending migrations", status)
+	}
+	if _, err := db.db.ExecContext(ctx, "INSERT INTO sources (id, name, content, group_id, created_at) VALUES ('old', 'doc', 'text', 'g1', NOW())"); err != nil {
+		t.Fatalf("insert legacy source: %v", err)
+	}
+
+	if err := db.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	// Whether a source from before promotion tracking is in the graph is unknown
+	old, err := db.GetSource(ctx, "old")
+	if err != nil {
+		t.Fatalf("GetSource: %v", err)
+	}
+	if old.PromotionStatus != PromotionUnknown {
+		t.Errorf("legacy source status = %q, want unknown", old.PromotionStatus)
+	}
+	pending, err := db.ListSources(ctx, &SourceFilter{Statuses: []PromotionStatus{PromotionPending}})
+	if err != nil {
+		t.Fatalf("ListSources: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending = %v, want the legacy source excluded", sourceIDs(pending))
+	}
+	seedSource(t, db, "legacy")
+	if err := db.SetPromotionStatus(ctx, "legacy", PromotionPromoted, "", nil); err != nil {
+		t.Errorf("SetPromotionStatus after migrating: %v", err)
+	}
+
+	status, err = db.SchemaStatus(ctx)
+	if err != nil {
+		t.Fatalf("SchemaStatus: %v", err)
+	}
+	if status.CurrentVersion != 5 || len(status.Pending()) != 0 || status.Migrations[0].AppliedAt == nil {
+		t.Errorf("status = %+v, want all migrations applied", status)
+	}
+
+	// Running the migrations again changes nothing
+	result, err := db.Migrate(ctx, nil)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(result.Applied) != 0 || result.ResizedTo != 0 {
+		t.Errorf("result = %+v, want no changes", result)
+	}
+}
+
+// TestMigrateEmbeddingDimensions tests resizing the stored vectors when the
+// configured embedding dimensions change
+func TestMigrateEmbeddingDimensions(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db := openTestDolt(t, dir, 3)
+	if err := db.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	seedSource(t, db, "doc")
+	db.Close()
+
+	// A store opened with other dimensions refuses to start until migrated
+	db = openTestDolt(t, dir, 4)
+	err := db.Initialize(ctx)
+	if !errors.Is(err, ErrEmbeddingDimensionsChanged) {
+		t.Fatalf("Initialize = %v, want ErrEmbeddingDimensionsChanged", err)
+	}
+	status, err := db.SchemaStatus(ctx)
+	if err != nil {
+		t.Fatalf("SchemaStatus: %v", err)
+	}
+	if !status.DimensionsChanged() || status.StoredDimensions != 3 || status.ConfiguredDimensions != 4 {
+		t.Errorf("status = %+v, want 3 stored and 4 configured dimensions", status)
+	}
+
+	// Without an embedder the vectors are zero-padded
+	result, err := db.Migrate(ctx, nil)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if result.ResizedFrom != 3 || result.ResizedTo != 4 || result.Vectors != 3 || result.Reembedded {
+		t.Errorf("result = %+v, want 3 vectors padded from 3 to 4", result)
+	}
+	nodes, err := db.GetExtractedNodes(ctx, "doc")
+	if err != nil {
+		t.Fatalf("GetExtractedNodes: %v", err)
+	}
+	for _, n := range nodes {
+		if len(n.Embedding) != 4 || n.Embedding[3] != 0 {
+			t.Errorf("node %s embedding = %v, want 4 dimensions padded with 0", n.ID, n.Embedding)
+		}
+	}
+	if err := db.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize after Migrate: %v", err)
+	}
+	db.Close()
+
+	// With an embedder the vectors are re-embedded from their text
+	db = openTestDolt(t, dir, 2)
+	defer db.Close()
+	embedder := &fakeEmbedder{dims: 2}
+	result, err = db.Migrate(ctx, &MigrateOptions{Embedder: embedder, BatchSize: 2})
+	if err != nil {
+		t.Fatalf("Migrate with embedder: %v", err)
+	}
+	if !result.Reembedded || result.ResizedFrom != 4 || result.ResizedTo != 2 {
+		t.Errorf("result = %+v, want vectors re-embedded from 4 to 2", result)
+	}
+	sort.Strings(embedder.texts)
+	if len(embedder.texts) != 3 || embedder.texts[0] != "Acme" || embedder.texts[1] != "Alice" || embedder.texts[2] != "Alice works at Acme" {
+		t.Errorf("embedded texts = %q, want the node names and the edge description", embedder.texts)
+	}
+	edges, err := db.GetExtractedEdges(ctx, "doc")
+	if err != nil {
+		t.Fatalf("GetExtractedEdges: %v", err)
+	}
+	if len(edges) != 1 || len(edges[0].Embedding) != 2 || edges[0].Embedding[0] != float32(len("Alice works at Acme")) {
+		t.Errorf("edges = %+v, want the re-embedded description", edges)
+	}
+}
+
+// TestResizeVector tests padding and truncating vectors
+func TestResizeVector(t *testing.T) {
+	if got := resizeVector([]float32{1, 2}, 4); len(got) != 4 || got[1] != 2 || got[3] != 0 {
+		t.Errorf("padded = %v, want [1 2 0 0]", got)
+	}
+	if got := resizeVector([]float32{1, 2, 3}, 2); len(got) != 2 || got[1] != 2 {
+		t.Errorf("truncated = %v, want [1 2]", got)
+	}
+	if got := vectorText("Alice", "", "A person"); got != "Alice A person" {
+		t.Errorf("vectorText = %q, want empty parts skipped", got)
+	}
+}
+
+// TestReplaceEmbeddings tests re-embedding the stored vectors with another
+// model by paging through their texts
+func TestReplaceEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDolt(t, t.TempDir(), 3)
+	defer db.Close()
+	if err := db.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	seedSource(t, db, "doc")
+
+	space, err := db.GetEmbeddingSpace(ctx)
+	if err != nil {
+		t.Fatalf("GetEmbeddingSpace: %v", err)
+	}
+	if space == nil || space.Model != "" || space.Dimensions != 3 {
+		t.Errorf("space = %v, want an unknown model with 3 dimensions", space)
+	}
+
+	// Page through the vectors two at a time, across both tables
+	embedder := &fakeEmbedder{dims: 5}
+	staged := make(map[VectorText][]float32)
+	var after *VectorText
+	for {
+		texts, err := db.ScanVectorTexts(ctx, after, 2)
+		if err != nil {
+			t.Fatalf("ScanVectorTexts: %v", err)
+		}
+		if len(texts) == 0 {
+			break
+		}
+		for _, text := range texts {
+			if staged[text], err = embedder.EmbedSingle(ctx, text.Text); err != nil {
+				t.Fatalf("EmbedSingle: %v", err)
+			}
+		}
+		after = &texts[len(texts)-1]
+	}
+	if len(staged) != 3 {
+		t.Fatalf("scanned %d vectors, want 3", len(staged))
+	}
+
+	// A vector that was not staged leaves the store unchanged
+	missing := errors.New("not staged")
+	if _, err := db.ReplaceEmbeddings(ctx, &types.EmbeddingSpace{Model: "fake", Dimensions: 5}, func(text VectorText) ([]float32, error) {
+		if text.Table == "extracted_edges" {
+			return nil, missing
+		}
+		return staged[text], nil
+	}); !errors.Is(err, missing) {
+		t.Fatalf("ReplaceEmbeddings = %v, want the lookup error", err)
+	}
+	if space, err := db.GetEmbeddingSpace(ctx); err != nil || space.Model != "" || space.Dimensions != 3 {
+		t.Errorf("space after failure = %v, %v; want it unchanged", space, err)
+	}
+
+	count, err := db.ReplaceEmbeddings(ctx, &types.EmbeddingSpace{Model: "fake", Dimensions: 5}, func(text VectorText) ([]float32, error) {
+		return staged[text], nil
+	})
+	if err != nil {
+		t.Fatalf("ReplaceEmbeddings: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("ReplaceEmbeddings = %d, want 3 vectors", count)
+	}
+	space, err = db.GetEmbeddingSpace(ctx)
+	if err != nil {
+		t.Fatalf("GetEmbeddingSpace: %v", err)
+	}
+	if space.Model != "fake" || space.Dimensions != 5 {
+		t.Errorf("space = %v, want the fake model with 5 dimensions", space)
+	}
+	nodes, err := db.GetExtractedNodes(ctx, "doc")
+	if err != nil {
+		t.Fatalf("GetExtractedNodes: %v", err)
+	}
+	for _, n := range nodes {
+		if len(n.Embedding) != 5 || n.Embedding[0] != float32(len(n.Name)) {
+			t.Errorf("node %s embedding = %v, want its name re-embedded", n.Name, n.Embedding)
+		}
+	}
+}
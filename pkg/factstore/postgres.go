@@ -100,101 +100,22 @@ func NewDoltGresDBWithConfig(connectionString string, embeddingDimensions int, c
 }
 
 func (p *PostgresDB) Initialize(ctx context.Context) error {
-	// Enable VectorChord extension only for PostgreSQL (not DoltGres)
-	if p.usePgVector {
-		if _, err := p.db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS vector"); err != nil {
-			return fmt.Errorf("failed to create vector extension: %w", err)
-		}
+	if err := p.createExtensions(ctx); err != nil {
+		return err
 	}
 
-	// Create sources table
-	sourcesTable := `
-		CREATE TABLE IF NOT EXISTS sources (
-			id VARCHAR(255) PRIMARY KEY,
-			name TEXT,
-			content TEXT,
-			group_id VARCHAR(255),
-			metadata JSONB,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`
-	if _, err := p.db.ExecContext(ctx, sourcesTable); err != nil {
-		return fmt.Errorf("failed to create sources table: %w", err)
+	s := p.schema()
+	if _, err := s.apply(ctx); err != nil {
+		return err
 	}
-
-	// Create extracted_nodes table
-	// Use vector type for PostgreSQL with VectorChord, JSONB for DoltGres
-	var nodesTable string
-	if p.usePgVector {
-		nodesTable = fmt.Sprintf(`
-			CREATE TABLE IF NOT EXISTS extracted_nodes (
-				id VARCHAR(255) PRIMARY KEY,
-				source_id VARCHAR(255) REFERENCES sources(id),
-				group_id VARCHAR(255),
-				name TEXT,
-				type VARCHAR(50),
-				description TEXT,
-				embedding vector(%d),
-				chunk_index INT,
-				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-			)`, p.embeddingDimensions)
-	} else {
-		nodesTable = `
-			CREATE TABLE IF NOT EXISTS extracted_nodes (
-				id VARCHAR(255) PRIMARY KEY,
-				source_id VARCHAR(255) REFERENCES sources(id),
-				group_id VARCHAR(255),
-				name TEXT,
-				type VARCHAR(50),
-				description TEXT,
-				embedding JSONB,
-				chunk_index INT,
-				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-			)`
-	}
-	if _, err := p.db.ExecContext(ctx, nodesTable); err != nil {
-		return fmt.Errorf("failed to create extracted_nodes table: %w", err)
-	}
-
-	// Create extracted_edges table
-	var edgesTable string
-	if p.usePgVector {
-		edgesTable = fmt.Sprintf(`
-			CREATE TABLE IF NOT EXISTS extracted_edges (
-				id VARCHAR(255) PRIMARY KEY,
-				source_id VARCHAR(255) REFERENCES sources(id),
-				group_id VARCHAR(255),
-				source_node_name TEXT,
-				target_node_name TEXT,
-				relation TEXT,
-				description TEXT,
-				embedding vector(%d),
-				weight FLOAT,
-				chunk_index INT,
-				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-			)`, p.embeddingDimensions)
-	} else {
-		edgesTable = `
-			CREATE TABLE IF NOT EXISTS extracted_edges (
-				id VARCHAR(255) PRIMARY KEY,
-				source_id VARCHAR(255) REFERENCES sources(id),
-				group_id VARCHAR(255),
-				source_node_name TEXT,
-				target_node_name TEXT,
-				relation TEXT,
-				description TEXT,
-				embedding JSONB,
-				weight FLOAT,
-				chunk_index INT,
-				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-			)`
-	}
-	if _, err := p.db.ExecContext(ctx, edgesTable); err != nil {
-		return fmt.Errorf("failed to create extracted_edges table: %w", err)
+	if err := s.checkDimensions(ctx, p.embeddingDimensions, p.decodeEmbedding); err != nil {
+		return err
 	}
 
 	// Create indices for better query performance
 	indices := []string{
 		"CREATE INDEX IF NOT EXISTS idx_sources_group ON sources(group_id)",
+		"CREATE INDEX IF NOT EXISTS idx_sources_promotion ON sources(promotion_status, reference)",
 		"CREATE INDEX IF NOT EXISTS idx_nodes_source ON extracted_nodes(source_id)",
 		"CREATE INDEX IF NOT EXISTS idx_nodes_group ON extracted_nodes(group_id)",
 		"CREATE INDEX IF NOT EXISTS idx_nodes_type ON extracted_nodes(type)",
@@ -231,6 +152,17 @@ func (p *PostgresDB) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// createExtensions enables the vector extension the schema depends on. Only
+// PostgreSQL uses it (not DoltGres).
+func (p *PostgresDB) createExtensions(ctx context.Context) error {
+	if p.usePgVector {
+		if _, err := p.db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS vector"); err != nil {
+			return fmt.Errorf("failed to create vector extension: %w", err)
+		}
+	}
+	return nil
+}
+
 // CreateVectorIndices creates IVFFlat indices for vector similarity search.
 // This should be called after bulk data loading for optimal performance.
 // lists parameter determines the number of clusters (recommended: sqrt(num_rows))
@@ -265,27 +197,62 @@ func (p *PostgresDB) SaveSource(ctx context.Context, source *Source) error {
 	}
 
 	query := `
-		INSERT INTO sources (id, name, content, group_id, metadata, created_at) 
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO sources (id, name, content, group_id, metadata, created_at, reference, promotion_status, promoted_at, promoted_by, promotion_error) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
 			content = EXCLUDED.content,
 			group_id = EXCLUDED.group_id,
-			metadata = EXCLUDED.metadata`
+			metadata = EXCLUDED.metadata,
+			reference = COALESCE(EXCLUDED.reference, sources.reference)`
+	// Without a status the promotion state of an existing source is kept
+	if source.PromotionStatus != "" {
+		query += `,
+			promotion_status = EXCLUDED.promotion_status,
+			promoted_at = EXCLUDED.promoted_at,
+			promoted_by = EXCLUDED.promoted_by,
+			promotion_error = EXCLUDED.promotion_error`
+	}
 
 	createdAt := source.CreatedAt
 	if createdAt.IsZero() {
 		createdAt = time.Now()
 	}
+	reference, status, promotedAt, promotedBy, promotionErr := sourceValues(source)
 
 	_, err = p.db.ExecContext(ctx, query,
-		source.ID, source.Name, source.Content, source.GroupID, metadataJSON, createdAt)
+		source.ID, source.Name, source.Content, source.GroupID, metadataJSON, createdAt,
+		reference, status, promotedAt, promotedBy, promotionErr)
 	if err != nil {
 		return fmt.Errorf("failed to insert source: %w", err)
 	}
 	return nil
 }
 
+// SetPromotionStatus records the outcome of promoting a source to the graph.
+func (p *PostgresDB) SetPromotionStatus(ctx context.Context, sourceID string, status PromotionStatus, modelerName string, promotionErr error) error {
+	var errMsg string
+	if promotionErr != nil {
+		errMsg = promotionErr.Error()
+	}
+	var promotedAt interface{}
+	if status != PromotionPending {
+		promotedAt = time.Now().UTC()
+	}
+
+	if err := requireRow(ctx, p.db, postgresPlaceholder, "sources", "source", sourceID); err != nil {
+		return err
+	}
+
+	_, err := p.db.ExecContext(ctx,
+		"UPDATE sources SET promotion_status = $1, promoted_at = $2, promoted_by = $3, promotion_error = $4 WHERE id = $5",
+		string(status), promotedAt, modelerName, errMsg, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to update promotion status: %w", err)
+	}
+	return nil
+}
+
 func (p *PostgresDB) SaveExtractedKnowledge(ctx context.Context, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error {
 	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -293,23 +260,148 @@ func (p *PostgresDB) SaveExtractedKnowledge(ctx context.Context, sourceID string
 	}
 	defer tx.Rollback()
 
+	if err := p.insertExtractedKnowledge(ctx, tx, sourceID, nodes, edges); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceExtractedKnowledge deletes the existing extracted nodes and edges of a source
+// and inserts the given ones in a single transaction.
+func (p *PostgresDB) ReplaceExtractedKnowledge(ctx context.Context, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM extracted_edges WHERE source_id = $1", sourceID); err != nil {
+		return fmt.Errorf("failed to delete extracted edges: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM extracted_nodes WHERE source_id = $1", sourceID); err != nil {
+		return fmt.Errorf("failed to delete extracted nodes: %w", err)
+	}
+
+	if err := p.insertExtractedKnowledge(ctx, tx, sourceID, nodes, edges); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSource deletes a source and its extracted nodes and edges in a single transaction.
+func (p *PostgresDB) DeleteSource(ctx context.Context, sourceID string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteSource(ctx, tx, postgresPlaceholder, sourceID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateNode overwrites the stored fields of an extracted node in a single transaction.
+func (p *PostgresDB) UpdateNode(ctx context.Context, node *ExtractedNode) error {
+	embedding := p.embeddingToString(node.Embedding)
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateNode(ctx, tx, postgresPlaceholder, node, embedding); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateEdge overwrites the stored fields of an extracted edge in a single transaction.
+func (p *PostgresDB) UpdateEdge(ctx context.Context, edge *ExtractedEdge) error {
+	embedding := p.embeddingToString(edge.Embedding)
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateEdge(ctx, tx, postgresPlaceholder, edge, embedding); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeEntity deletes the extracted rows naming an entity and redacts its name
+// from the remaining rows in a single transaction.
+func (p *PostgresDB) PurgeEntity(ctx context.Context, groupID, name string) (*PurgeStats, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stats, err := purgeEntity(ctx, tx, postgresPlaceholder, groupID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return stats, nil
+}
+
+// insertExtractedKnowledge upserts extracted nodes and edges for a source within tx.
+func (p *PostgresDB) insertExtractedKnowledge(ctx context.Context, tx *sql.Tx, sourceID string, nodes []*ExtractedNode, edges []*ExtractedEdge) error {
 	// Get source's group_id for nodes/edges
 	var groupID string
-	err = tx.QueryRowContext(ctx, "SELECT group_id FROM sources WHERE id = $1", sourceID).Scan(&groupID)
+	err := tx.QueryRowContext(ctx, "SELECT group_id FROM sources WHERE id = $1", sourceID).Scan(&groupID)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("failed to get source group_id: %w", err)
 	}
 
 	// Insert nodes
 	nodeStmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO extracted_nodes (id, source_id, group_id, name, type, description, embedding, chunk_index, created_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO extracted_nodes (id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
 			type = EXCLUDED.type,
 			description = EXCLUDED.description,
 			embedding = EXCLUDED.embedding,
-			chunk_index = EXCLUDED.chunk_index`)
+			chunk_index = EXCLUDED.chunk_index,
+			span_start = EXCLUDED.span_start,
+			span_end = EXCLUDED.span_end,
+			evidence = EXCLUDED.evidence`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare node statement: %w", err)
 	}
@@ -326,17 +418,19 @@ func (p *PostgresDB) SaveExtractedKnowledge(ctx context.Context, sourceID string
 			createdAt = time.Now()
 		}
 
+		spanStart, spanEnd, evidence := spanValues(node.Span)
+
 		if _, err := nodeStmt.ExecContext(ctx,
 			node.ID, sourceID, nodeGroupID, node.Name, node.Type, node.Description,
-			embeddingStr, node.ChunkIndex, createdAt); err != nil {
+			embeddingStr, node.ChunkIndex, spanStart, spanEnd, evidence, createdAt); err != nil {
 			return fmt.Errorf("failed to insert node %s: %w", node.ID, err)
 		}
 	}
 
 	// Insert edges
 	edgeStmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO extracted_edges (id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO extracted_edges (id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		ON CONFLICT (id) DO UPDATE SET
 			source_node_name = EXCLUDED.source_node_name,
 			target_node_name = EXCLUDED.target_node_name,
@@ -344,7 +438,11 @@ func (p *PostgresDB) SaveExtractedKnowledge(ctx context.Context, sourceID string
 			description = EXCLUDED.description,
 			embedding = EXCLUDED.embedding,
 			weight = EXCLUDED.weight,
-			chunk_index = EXCLUDED.chunk_index`)
+			confidence = EXCLUDED.confidence,
+			chunk_index = EXCLUDED.chunk_index,
+			span_start = EXCLUDED.span_start,
+			span_end = EXCLUDED.span_end,
+			evidence = EXCLUDED.evidence`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare edge statement: %w", err)
 	}
@@ -361,46 +459,36 @@ func (p *PostgresDB) SaveExtractedKnowledge(ctx context.Context, sourceID string
 			createdAt = time.Now()
 		}
 
+		spanStart, spanEnd, evidence := spanValues(edge.Span)
+
 		if _, err := edgeStmt.ExecContext(ctx,
 			edge.ID, sourceID, edgeGroupID, edge.SourceNodeName, edge.TargetNodeName,
-			edge.Relation, edge.Description, embeddingStr, edge.Weight, edge.ChunkIndex, createdAt); err != nil {
+			edge.Relation, edge.Description, embeddingStr, edge.Weight, edge.Confidence, edge.ChunkIndex,
+			spanStart, spanEnd, evidence, createdAt); err != nil {
 			return fmt.Errorf("failed to insert edge %s: %w", edge.ID, err)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
 func (p *PostgresDB) GetSource(ctx context.Context, sourceID string) (*Source, error) {
 	row := p.db.QueryRowContext(ctx,
-		"SELECT id, name, content, group_id, metadata, created_at FROM sources WHERE id = $1", sourceID)
+		"SELECT "+sourceColumns+" FROM sources WHERE id = $1", sourceID)
 
-	var s Source
-	var metadataBytes []byte
-
-	if err := row.Scan(&s.ID, &s.Name, &s.Content, &s.GroupID, &metadataBytes, &s.CreatedAt); err != nil {
+	s, err := scanSource(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("source not found: %s", sourceID)
 		}
 		return nil, fmt.Errorf("failed to scan source: %w", err)
 	}
-
-	if len(metadataBytes) > 0 {
-		if err := json.Unmarshal(metadataBytes, &s.Metadata); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-	}
-
-	return &s, nil
+	return s, nil
 }
 
 func (p *PostgresDB) GetExtractedNodes(ctx context.Context, sourceID string) ([]*ExtractedNode, error) {
 	rows, err := p.db.QueryContext(ctx,
-		"SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, created_at FROM extracted_nodes WHERE source_id = $1",
+		"SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at FROM extracted_nodes WHERE source_id = $1",
 		sourceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query extracted nodes: %w", err)
@@ -412,7 +500,7 @@ func (p *PostgresDB) GetExtractedNodes(ctx context.Context, sourceID string) ([]
 
 func (p *PostgresDB) GetExtractedEdges(ctx context.Context, sourceID string) ([]*ExtractedEdge, error) {
 	rows, err := p.db.QueryContext(ctx,
-		"SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at FROM extracted_edges WHERE source_id = $1",
+		"SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at FROM extracted_edges WHERE source_id = $1",
 		sourceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query extracted edges: %w", err)
@@ -423,7 +511,7 @@ func (p *PostgresDB) GetExtractedEdges(ctx context.Context, sourceID string) ([]
 }
 
 func (p *PostgresDB) GetAllSources(ctx context.Context, limit int) ([]*Source, error) {
-	query := "SELECT id, name, content, group_id, metadata, created_at FROM sources ORDER BY created_at DESC"
+	query := "SELECT " + sourceColumns + " FROM sources ORDER BY created_at DESC"
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
@@ -434,25 +522,34 @@ func (p *PostgresDB) GetAllSources(ctx context.Context, limit int) ([]*Source, e
 	}
 	defer rows.Close()
 
-	var sources []*Source
-	for rows.Next() {
-		var s Source
-		var metadataBytes []byte
-		if err := rows.Scan(&s.ID, &s.Name, &s.Content, &s.GroupID, &metadataBytes, &s.CreatedAt); err != nil {
-			return nil, err
-		}
-		if len(metadataBytes) > 0 {
-			if err := json.Unmarshal(metadataBytes, &s.Metadata); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-			}
-		}
-		sources = append(sources, &s)
+	return scanSourceRows(rows)
+}
+
+// ListSources retrieves the sources matching filter, oldest reference first.
+// Sources without a reference are ordered by their creation time.
+func (p *PostgresDB) ListSources(ctx context.Context, filter *SourceFilter) ([]*Source, error) {
+	conditions, args := sourceFilterClauses(filter, postgresPlaceholder)
+
+	query := "SELECT " + sourceColumns + " FROM sources"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY COALESCE(reference, created_at) ASC, id ASC"
+	if filter != nil && filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
 	}
-	return sources, nil
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sources: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSourceRows(rows)
 }
 
 func (p *PostgresDB) GetAllNodes(ctx context.Context, limit int) ([]*ExtractedNode, error) {
-	query := "SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, created_at FROM extracted_nodes"
+	query := "SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at FROM extracted_nodes"
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
@@ -467,7 +564,7 @@ func (p *PostgresDB) GetAllNodes(ctx context.Context, limit int) ([]*ExtractedNo
 }
 
 func (p *PostgresDB) GetAllEdges(ctx context.Context, limit int) ([]*ExtractedEdge, error) {
-	query := "SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at FROM extracted_edges"
+	query := "SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at FROM extracted_edges"
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
@@ -719,7 +816,7 @@ func (p *PostgresDB) vectorSearchNodes(ctx context.Context, embedding []float32,
 
 	// Build query with filters (VectorChord mode)
 	sqlQuery := `
-		SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, created_at,
+		SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at,
 			   1 - (embedding <=> $1::vector) AS score
 		FROM extracted_nodes
 		WHERE embedding IS NOT NULL`
@@ -774,10 +871,12 @@ func (p *PostgresDB) vectorSearchNodes(ctx context.Context, embedding []float32,
 		var embeddingStr sql.NullString
 		var score float64
 
+		var span spanColumns
 		if err := rows.Scan(&n.ID, &n.SourceID, &n.GroupID, &n.Name, &n.Type, &n.Description,
-			&embeddingStr, &n.ChunkIndex, &n.CreatedAt, &score); err != nil {
+			&embeddingStr, &n.ChunkIndex, &span.start, &span.end, &span.text, &n.CreatedAt, &score); err != nil {
 			return nil, nil, err
 		}
+		n.Span = span.span()
 
 		if score < config.MinScore {
 			continue
@@ -808,7 +907,7 @@ func (p *PostgresDB) inMemoryVectorSearchNodes(ctx context.Context, embedding []
 	// Build query to fetch all nodes with embeddings
 	// Limit to MaxInMemorySearchResults to prevent excessive memory usage
 	sqlQuery := `
-		SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, created_at
+		SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at
 		FROM extracted_nodes
 		WHERE embedding IS NOT NULL`
 
@@ -861,10 +960,12 @@ func (p *PostgresDB) inMemoryVectorSearchNodes(ctx context.Context, embedding []
 		var n ExtractedNode
 		var embeddingJSON sql.NullString
 
+		var span spanColumns
 		if err := rows.Scan(&n.ID, &n.SourceID, &n.GroupID, &n.Name, &n.Type, &n.Description,
-			&embeddingJSON, &n.ChunkIndex, &n.CreatedAt); err != nil {
+			&embeddingJSON, &n.ChunkIndex, &span.start, &span.end, &span.text, &n.CreatedAt); err != nil {
 			return nil, nil, err
 		}
+		n.Span = span.span()
 
 		if !embeddingJSON.Valid || embeddingJSON.String == "" {
 			continue
@@ -907,7 +1008,7 @@ func (p *PostgresDB) inMemoryVectorSearchNodes(ctx context.Context, embedding []
 
 func (p *PostgresDB) keywordSearchNodes(ctx context.Context, query string, config *FactSearchConfig) ([]*ExtractedNode, []float64, error) {
 	sqlQuery := `
-		SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, created_at,
+		SELECT id, source_id, group_id, name, type, description, embedding, chunk_index, span_start, span_end, evidence, created_at,
 			   ts_rank(to_tsvector('english', COALESCE(name, '') || ' ' || COALESCE(description, '')), 
 			          plainto_tsquery('english', $1)) AS score
 		FROM extracted_nodes
@@ -964,10 +1065,12 @@ func (p *PostgresDB) keywordSearchNodes(ctx context.Context, query string, confi
 		var embeddingStr sql.NullString
 		var score float64
 
+		var span spanColumns
 		if err := rows.Scan(&n.ID, &n.SourceID, &n.GroupID, &n.Name, &n.Type, &n.Description,
-			&embeddingStr, &n.ChunkIndex, &n.CreatedAt, &score); err != nil {
+			&embeddingStr, &n.ChunkIndex, &span.start, &span.end, &span.text, &n.CreatedAt, &score); err != nil {
 			return nil, nil, err
 		}
+		n.Span = span.span()
 
 		if score < config.MinScore {
 			continue
@@ -997,7 +1100,7 @@ func (p *PostgresDB) vectorSearchEdges(ctx context.Context, embedding []float32,
 	embeddingStr := p.embeddingToString(embedding)
 
 	sqlQuery := `
-		SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at,
+		SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at,
 			   1 - (embedding <=> $1::vector) AS score
 		FROM extracted_edges
 		WHERE embedding IS NOT NULL`
@@ -1042,10 +1145,12 @@ func (p *PostgresDB) vectorSearchEdges(ctx context.Context, embedding []float32,
 		var embeddingStr sql.NullString
 		var score float64
 
+		var span spanColumns
 		if err := rows.Scan(&e.ID, &e.SourceID, &e.GroupID, &e.SourceNodeName, &e.TargetNodeName,
-			&e.Relation, &e.Description, &embeddingStr, &e.Weight, &e.ChunkIndex, &e.CreatedAt, &score); err != nil {
+			&e.Relation, &e.Description, &embeddingStr, &e.Weight, &e.Confidence, &e.ChunkIndex, &span.start, &span.end, &span.text, &e.CreatedAt, &score); err != nil {
 			return nil, nil, err
 		}
+		e.Span = span.span()
 
 		if score < config.MinScore {
 			continue
@@ -1070,7 +1175,7 @@ func (p *PostgresDB) vectorSearchEdges(ctx context.Context, embedding []float32,
 // and computing cosine similarity in Go. Used for DoltGres.
 func (p *PostgresDB) inMemoryVectorSearchEdges(ctx context.Context, embedding []float32, config *FactSearchConfig) ([]*ExtractedEdge, []float64, error) {
 	sqlQuery := `
-		SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at
+		SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at
 		FROM extracted_edges
 		WHERE embedding IS NOT NULL`
 
@@ -1112,10 +1217,12 @@ func (p *PostgresDB) inMemoryVectorSearchEdges(ctx context.Context, embedding []
 		var e ExtractedEdge
 		var embeddingJSON sql.NullString
 
+		var span spanColumns
 		if err := rows.Scan(&e.ID, &e.SourceID, &e.GroupID, &e.SourceNodeName, &e.TargetNodeName,
-			&e.Relation, &e.Description, &embeddingJSON, &e.Weight, &e.ChunkIndex, &e.CreatedAt); err != nil {
+			&e.Relation, &e.Description, &embeddingJSON, &e.Weight, &e.Confidence, &e.ChunkIndex, &span.start, &span.end, &span.text, &e.CreatedAt); err != nil {
 			return nil, nil, err
 		}
+		e.Span = span.span()
 
 		if !embeddingJSON.Valid || embeddingJSON.String == "" {
 			continue
@@ -1150,7 +1257,7 @@ func (p *PostgresDB) inMemoryVectorSearchEdges(ctx context.Context, embedding []
 
 func (p *PostgresDB) keywordSearchEdges(ctx context.Context, query string, config *FactSearchConfig) ([]*ExtractedEdge, []float64, error) {
 	sqlQuery := `
-		SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, chunk_index, created_at,
+		SELECT id, source_id, group_id, source_node_name, target_node_name, relation, description, embedding, weight, confidence, chunk_index, span_start, span_end, evidence, created_at,
 			   ts_rank(to_tsvector('english', COALESCE(relation, '') || ' ' || COALESCE(description, '')), 
 			          plainto_tsquery('english', $1)) AS score
 		FROM extracted_edges
@@ -1197,10 +1304,12 @@ func (p *PostgresDB) keywordSearchEdges(ctx context.Context, query string, confi
 		var embeddingStr sql.NullString
 		var score float64
 
+		var span spanColumns
 		if err := rows.Scan(&e.ID, &e.SourceID, &e.GroupID, &e.SourceNodeName, &e.TargetNodeName,
-			&e.Relation, &e.Description, &embeddingStr, &e.Weight, &e.ChunkIndex, &e.CreatedAt, &score); err != nil {
+			&e.Relation, &e.Description, &embeddingStr, &e.Weight, &e.Confidence, &e.ChunkIndex, &span.start, &span.end, &span.text, &e.CreatedAt, &score); err != nil {
 			return nil, nil, err
 		}
+		e.Span = span.span()
 
 		if score < config.MinScore {
 			continue
@@ -1382,10 +1491,12 @@ func (p *PostgresDB) scanNodes(rows *sql.Rows) ([]*ExtractedNode, error) {
 		var n ExtractedNode
 		var embeddingStr sql.NullString
 
+		var span spanColumns
 		if err := rows.Scan(&n.ID, &n.SourceID, &n.GroupID, &n.Name, &n.Type, &n.Description,
-			&embeddingStr, &n.ChunkIndex, &n.CreatedAt); err != nil {
+			&embeddingStr, &n.ChunkIndex, &span.start, &span.end, &span.text, &n.CreatedAt); err != nil {
 			return nil, err
 		}
+		n.Span = span.span()
 
 		if embeddingStr.Valid {
 			n.Embedding = p.parseEmbedding(embeddingStr.String)
@@ -1404,10 +1515,12 @@ func (p *PostgresDB) scanEdges(rows *sql.Rows) ([]*ExtractedEdge, error) {
 		var e ExtractedEdge
 		var embeddingStr sql.NullString
 
+		var span spanColumns
 		if err := rows.Scan(&e.ID, &e.SourceID, &e.GroupID, &e.SourceNodeName, &e.TargetNodeName,
-			&e.Relation, &e.Description, &embeddingStr, &e.Weight, &e.ChunkIndex, &e.CreatedAt); err != nil {
+			&e.Relation, &e.Description, &embeddingStr, &e.Weight, &e.Confidence, &e.ChunkIndex, &span.start, &span.end, &span.text, &e.CreatedAt); err != nil {
 			return nil, err
 		}
+		e.Span = span.span()
 
 		if embeddingStr.Valid {
 			e.Embedding = p.parseEmbedding(embeddingStr.String)
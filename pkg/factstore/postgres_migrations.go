@@ -0,0 +1,310 @@
+package factstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+var _ Migrator = (*PostgresDB)(nil)
+
+// migrations returns the schema history of PostgresDB. Embeddings are stored
+// as vector(N) with pgvector and as JSONB on DoltGres.
+func (p *PostgresDB) migrations() []Migration {
+	embeddingType := "JSONB"
+	if p.usePgVector {
+		embeddingType = fmt.Sprintf("vector(%d)", p.embeddingDimensions)
+	}
+
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create sources, extracted_nodes and extracted_edges tables",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				tables := []string{
+					`CREATE TABLE IF NOT EXISTS sources (
+						id VARCHAR(255) PRIMARY KEY,
+						name TEXT,
+						content TEXT,
+						group_id VARCHAR(255),
+						metadata JSONB,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					)`,
+					fmt.Sprintf(`CREATE TABLE IF NOT EXISTS extracted_nodes (
+						id VARCHAR(255) PRIMARY KEY,
+						source_id VARCHAR(255) REFERENCES sources(id),
+						group_id VARCHAR(255),
+						name TEXT,
+						type VARCHAR(50),
+						description TEXT,
+						embedding %s,
+						chunk_index INT,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					)`, embeddingType),
+					fmt.Sprintf(`CREATE TABLE IF NOT EXISTS extracted_edges (
+						id VARCHAR(255) PRIMARY KEY,
+						source_id VARCHAR(255) REFERENCES sources(id),
+						group_id VARCHAR(255),
+						source_node_name TEXT,
+						target_node_name TEXT,
+						relation TEXT,
+						description TEXT,
+						embedding %s,
+						weight FLOAT,
+						chunk_index INT,
+						created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+					)`, embeddingType),
+				}
+				for _, table := range tables {
+					if _, err := tx.ExecContext(ctx, table); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     2,
+			Description: "add provenance span columns to extracted_nodes and extracted_edges",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				for _, table := range []string{"extracted_nodes", "extracted_edges"} {
+					if err := addPostgresColumns(ctx, tx, table, "span_start INT", "span_end INT", "evidence TEXT"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version:     3,
+			Description: "add reference and promotion status columns to sources",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				return addPostgresColumns(ctx, tx, "sources", "reference TIMESTAMP", "promotion_status VARCHAR(20)",
+					"promoted_at TIMESTAMP", "promoted_by TEXT", "promotion_error TEXT")
+			},
+		},
+		{
+			Version:     4,
+			Description: "mark sources saved before promotion was tracked as unknown",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "UPDATE sources SET promotion_status = 'unknown' WHERE promotion_status IS NULL")
+				return err
+			},
+		},
+		{
+			Version:     5,
+			Description: "add a confidence column to extracted_edges",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				if err := addPostgresColumns(ctx, tx, "extracted_edges", "confidence FLOAT DEFAULT 0"); err != nil {
+					return err
+				}
+				// Earlier versions stored the confidence in weight and read
+				// weights in (0, 1] back as confidences
+				_, err := tx.ExecContext(ctx, "UPDATE extracted_edges SET confidence = weight WHERE weight > 0 AND weight <= 1")
+				return err
+			},
+		},
+	}
+}
+
+// addPostgresColumns adds columns to table unless they already exist.
+func addPostgresColumns(ctx context.Context, tx *sql.Tx, table string, columns ...string) error {
+	for _, column := range columns {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", table, column)); err != nil {
+			return fmt.Errorf("failed to add column %s to %s: %w", column, table, err)
+		}
+	}
+	return nil
+}
+
+func (p *PostgresDB) schema() *schema {
+	return &schema{db: p.db, ph: postgresPlaceholder, migrations: p.migrations()}
+}
+
+// decodeEmbedding parses an embedding stored as a vector or JSONB array.
+func (p *PostgresDB) decodeEmbedding(raw []byte) ([]float32, error) {
+	return p.parseEmbeddingJSON(string(raw)), nil
+}
+
+// SchemaStatus reports the applied migrations and the embedding dimensions.
+func (p *PostgresDB) SchemaStatus(ctx context.Context) (*SchemaStatus, error) {
+	s := p.schema()
+	status, err := s.status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The embedding tables exist once the first migration has been applied
+	if status.CurrentVersion > 0 {
+		if status.StoredDimensions, err = s.storedDimensions(ctx, p.decodeEmbedding); err != nil {
+			return nil, err
+		}
+	}
+	status.ConfiguredDimensions = p.embeddingDimensions
+	return status, nil
+}
+
+// Migrate applies pending migrations and, if the configured embedding
+// dimensions differ from the stored vectors, resizes them in one transaction.
+// With pgvector the embedding columns are recreated with the new size, which
+// drops their vector indices; recreate them with CreateVectorIndices.
+func (p *PostgresDB) Migrate(ctx context.Context, opts *MigrateOptions) (*MigrationResult, error) {
+	if err := p.createExtensions(ctx); err != nil {
+		return nil, err
+	}
+
+	s := p.schema()
+	applied, err := s.apply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrationResult{Applied: applied}
+
+	stored, err := s.storedDimensions(ctx, p.decodeEmbedding)
+	if err != nil {
+		return nil, err
+	}
+	if stored > 0 && stored != p.embeddingDimensions {
+		vectors, err := p.resizeEmbeddings(ctx, p.embeddingDimensions, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.ResizedFrom, result.ResizedTo, result.Vectors = stored, p.embeddingDimensions, vectors
+		result.Reembedded = opts != nil && opts.Embedder != nil
+	}
+	if err := s.setMetadata(ctx, metadataKeyEmbeddingDimensions, strconv.Itoa(p.embeddingDimensions)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEmbeddingSpace returns the embedding model and dimensions of the stored vectors.
+func (p *PostgresDB) GetEmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error) {
+	return p.schema().embeddingSpace(ctx, p.decodeEmbedding)
+}
+
+// SetEmbeddingSpace records the embedding model and dimensions of the stored vectors.
+func (p *PostgresDB) SetEmbeddingSpace(ctx context.Context, space *types.EmbeddingSpace) error {
+	return p.schema().setEmbeddingSpace(ctx, space)
+}
+
+// ScanVectorTexts returns up to limit stored vectors after the given one.
+func (p *PostgresDB) ScanVectorTexts(ctx context.Context, after *VectorText, limit int) ([]VectorText, error) {
+	return scanVectorTexts(ctx, p.db, postgresPlaceholder, p.decodeEmbedding, after, limit)
+}
+
+// ReplaceEmbeddings replaces every stored vector and records space in one
+// transaction. With pgvector the new vectors are written to a new column that
+// replaces the old one on commit, which drops its vector index; if the
+// dimensions change, open the store with the new dimensions afterwards and
+// recreate the indices with CreateVectorIndices.
+func (p *PostgresDB) ReplaceEmbeddings(ctx context.Context, space *types.EmbeddingSpace, vector func(VectorText) ([]float32, error)) (int, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	count := 0
+	for _, table := range vectorTables {
+		column := "embedding"
+		if p.usePgVector {
+			if space == nil || space.Dimensions <= 0 {
+				return 0, fmt.Errorf("embedding dimensions are required to replace pgvector embeddings")
+			}
+			column = "embedding_resized"
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN embedding_resized vector(%d)", table.name, space.Dimensions)); err != nil {
+				return 0, fmt.Errorf("failed to replace %s embeddings: %w", table.name, err)
+			}
+		}
+		n, err := replaceVectors(ctx, tx, postgresPlaceholder, table.name, column, table.textColumns, p.decodeEmbedding, p.encodeEmbedding, vector)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+		if p.usePgVector {
+			if err := p.swapResizedColumn(ctx, tx, table.name); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := p.schema().setEmbeddingSpaceTx(ctx, tx, space); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return count, nil
+}
+
+// encodeEmbedding formats an embedding for a vector or JSONB column.
+func (p *PostgresDB) encodeEmbedding(embedding []float32) (interface{}, error) {
+	return p.embeddingToString(embedding), nil
+}
+
+// swapResizedColumn replaces the embedding column of table with
+// embedding_resized within tx.
+func (p *PostgresDB) swapResizedColumn(ctx context.Context, tx *sql.Tx, table string) error {
+	for _, statement := range []string{
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN embedding", table),
+		fmt.Sprintf("ALTER TABLE %s RENAME COLUMN embedding_resized TO embedding", table),
+	} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to resize %s embeddings: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// resizeEmbeddings rewrites every stored embedding with dims dimensions and
+// returns the number of vectors rewritten.
+func (p *PostgresDB) resizeEmbeddings(ctx context.Context, dims int, opts *MigrateOptions) (int, error) {
+	resized := make(map[string][]*vectorRow)
+	count := 0
+	for _, table := range vectorTables {
+		rows, err := loadVectors(ctx, p.db, postgresPlaceholder, table.name, table.textColumns, p.decodeEmbedding)
+		if err != nil {
+			return 0, err
+		}
+		if err := resizeVectors(ctx, rows, dims, opts); err != nil {
+			return 0, err
+		}
+		resized[table.name] = rows
+		count += len(rows)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range vectorTables {
+		if !p.usePgVector {
+			if err := writeVectors(ctx, tx, postgresPlaceholder, table.name, "embedding", resized[table.name], p.encodeEmbedding); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		// A vector(N) column only holds vectors of size N, so the resized
+		// vectors are written to a new column that replaces the old one
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN embedding_resized vector(%d)", table.name, dims)); err != nil {
+			return 0, fmt.Errorf("failed to resize %s embeddings: %w", table.name, err)
+		}
+		if err := writeVectors(ctx, tx, postgresPlaceholder, table.name, "embedding_resized", resized[table.name], p.encodeEmbedding); err != nil {
+			return 0, err
+		}
+		if err := p.swapResizedColumn(ctx, tx, table.name); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return count, nil
+}
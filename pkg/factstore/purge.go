@@ -0,0 +1,146 @@
+package factstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// PurgeStats counts the rows changed by PurgeEntity.
+type PurgeStats struct {
+	DeletedNodes    int64 `json:"deleted_nodes"`
+	DeletedEdges    int64 `json:"deleted_edges"`
+	RedactedSources int64 `json:"redacted_sources"`
+	RedactedNodes   int64 `json:"redacted_nodes"`
+	RedactedEdges   int64 `json:"redacted_edges"`
+}
+
+// placeholder returns the bind parameter for the n-th (1-based) argument of a
+// query: "?" for Dolt, "$n" for PostgreSQL.
+type placeholder func(n int) string
+
+func doltPlaceholder(int) string { return "?" }
+
+func postgresPlaceholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+// deleteSource deletes a source and its extracted rows within tx.
+func deleteSource(ctx context.Context, tx *sql.Tx, ph placeholder, sourceID string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM extracted_edges WHERE source_id = "+ph(1), sourceID); err != nil {
+		return fmt.Errorf("failed to delete extracted edges: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM extracted_nodes WHERE source_id = "+ph(1), sourceID); err != nil {
+		return fmt.Errorf("failed to delete extracted nodes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sources WHERE id = "+ph(1), sourceID); err != nil {
+		return fmt.Errorf("failed to delete source: %w", err)
+	}
+	return nil
+}
+
+// purgeEntity deletes the extracted rows naming an entity and redacts its name
+// from the remaining text columns within tx.
+func purgeEntity(ctx context.Context, tx *sql.Tx, ph placeholder, groupID, name string) (*PurgeStats, error) {
+	stats := &PurgeStats{}
+
+	result, err := tx.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM extracted_edges WHERE group_id = %s AND (LOWER(source_node_name) = LOWER(%s) OR LOWER(target_node_name) = LOWER(%s))", ph(1), ph(2), ph(3)),
+		groupID, name, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete extracted edges: %w", err)
+	}
+	stats.DeletedEdges, _ = result.RowsAffected()
+
+	result, err = tx.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM extracted_nodes WHERE group_id = %s AND LOWER(name) = LOWER(%s)", ph(1), ph(2)),
+		groupID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete extracted nodes: %w", err)
+	}
+	stats.DeletedNodes, _ = result.RowsAffected()
+
+	pattern := "%" + strings.ToLower(name) + "%"
+	if stats.RedactedSources, err = redactColumns(ctx, tx, ph, "sources", []string{"content"}, groupID, pattern, name); err != nil {
+		return nil, err
+	}
+	if stats.RedactedNodes, err = redactColumns(ctx, tx, ph, "extracted_nodes", []string{"description", "evidence"}, groupID, pattern, name); err != nil {
+		return nil, err
+	}
+	if stats.RedactedEdges, err = redactColumns(ctx, tx, ph, "extracted_edges", []string{"description", "evidence"}, groupID, pattern, name); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// redactColumns redacts name from the given text columns of the rows of table
+// in groupID that contain it, returning the number of rows changed. The LIKE
+// pattern only preselects rows; types.RedactTerms decides what is replaced.
+func redactColumns(ctx context.Context, tx *sql.Tx, ph placeholder, table string, columns []string, groupID, pattern, name string) (int64, error) {
+	conditions := make([]string, len(columns))
+	args := []interface{}{groupID}
+	for i, column := range columns {
+		conditions[i] = fmt.Sprintf("LOWER(%s) LIKE %s", column, ph(i+2))
+		args = append(args, pattern)
+	}
+	query := fmt.Sprintf("SELECT id, %s FROM %s WHERE group_id = %s AND (%s)",
+		strings.Join(columns, ", "), table, ph(1), strings.Join(conditions, " OR "))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find %s to redact: %w", table, err)
+	}
+
+	type redaction struct {
+		id     string
+		values []sql.NullString
+	}
+	var redactions []redaction
+	for rows.Next() {
+		var id string
+		values := make([]sql.NullString, len(columns))
+		dest := []interface{}{&id}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan %s row: %w", table, err)
+		}
+
+		changed := false
+		for i := range values {
+			if redacted, ok := types.RedactTerms(values[i].String, []string{name}); ok {
+				values[i].String = redacted
+				changed = true
+			}
+		}
+		if changed {
+			redactions = append(redactions, redaction{id: id, values: values})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read %s rows: %w", table, err)
+	}
+
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = fmt.Sprintf("%s = %s", column, ph(i+1))
+	}
+	update := fmt.Sprintf("UPDATE %s SET %s WHERE id = %s", table, strings.Join(assignments, ", "), ph(len(columns)+1))
+	for _, r := range redactions {
+		args := make([]interface{}, 0, len(columns)+1)
+		for _, value := range r.values {
+			args = append(args, value)
+		}
+		args = append(args, r.id)
+		if _, err := tx.ExecContext(ctx, update, args...); err != nil {
+			return 0, fmt.Errorf("failed to redact %s row %s: %w", table, r.id, err)
+		}
+	}
+
+	return int64(len(redactions)), nil
+}
@@ -0,0 +1,284 @@
+package factstore
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// testStores returns the fact stores the store tests run against: an embedded
+// Dolt database, plus PostgreSQL if FACTSTORE_POSTGRES_URL is set.
+func testStores(t *testing.T) map[string]FactsDB {
+	t.Helper()
+	ctx := context.Background()
+	stores := make(map[string]FactsDB)
+
+	dolt, err := NewDoltDB("file://" + t.TempDir() + "?commitname=Test&commitemail=test@example.com&database=facts")
+	if err != nil {
+		t.Fatalf("NewDoltDB: %v", err)
+	}
+	t.Cleanup(func() { dolt.Close() })
+	if err := dolt.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize dolt: %v", err)
+	}
+	stores["dolt"] = dolt
+
+	if url := os.Getenv("FACTSTORE_POSTGRES_URL"); url != "" {
+		postgres, err := NewPostgresDBWithConfig(url, 3, false, nil)
+		if err != nil {
+			t.Fatalf("NewPostgresDB: %v", err)
+		}
+		t.Cleanup(func() { postgres.Close() })
+		if err := postgres.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize postgres: %v", err)
+		}
+		stores["postgres"] = postgres
+	}
+	return stores
+}
+
+// seedSource saves a source with one edge between two nodes, with IDs
+// prefixed by id so tests sharing a PostgreSQL database do not collide.
+func seedSource(t *testing.T, db FactsDB, id string) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := db.SaveSource(ctx, &Source{ID: id, Name: "doc", Content: "Alice works at Acme.", GroupID: "g1", CreatedAt: now}); err != nil {
+		t.Fatalf("SaveSource: %v", err)
+	}
+	nodes := []*ExtractedNode{
+		{ID: id + "-alice", Name: "Alice", Type: "Person", Embedding: []float32{1, 0, 0}, CreatedAt: now},
+		{ID: id + "-acme", Name: "Acme", Type: "Organization", Embedding: []float32{0, 1, 0}, CreatedAt: now},
+	}
+	edges := []*ExtractedEdge{
+		{ID: id + "-works", SourceNodeName: "Alice", TargetNodeName: "Acme", Relation: "WORKS_AT", Description: "Alice works at Acme", Embedding: []float32{0, 0, 1}, Weight: 0.9, CreatedAt: now},
+	}
+	if err := db.SaveExtractedKnowledge(ctx, id, nodes, edges); err != nil {
+		t.Fatalf("SaveExtractedKnowledge: %v", err)
+	}
+}
+
+// TestUpdateNode tests overwriting an extracted node, renaming it in edges
+func TestUpdateNode(t *testing.T) {
+	for name, db := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id := "update-node-" + name
+			seedSource(t, db, id)
+
+			updated := &ExtractedNode{
+				ID:          id + "-acme",
+				Name:        "Acme Corp",
+				Type:        "Company",
+				Description: "A company",
+				Embedding:   []float32{0, 0.5, 0.5},
+				Span:        &types.Span{Start: 15, End: 19, Text: "Acme"},
+			}
+			if err := db.UpdateNode(ctx, updated); err != nil {
+				t.Fatalf("UpdateNode: %v", err)
+			}
+
+			nodes, err := db.GetExtractedNodes(ctx, id)
+			if err != nil {
+				t.Fatalf("GetExtractedNodes: %v", err)
+			}
+			var got *ExtractedNode
+			for _, n := range nodes {
+				if n.ID == updated.ID {
+					got = n
+				}
+			}
+			if got == nil {
+				t.Fatalf("node %s missing after update", updated.ID)
+			}
+			if got.Name != "Acme Corp" || got.Type != "Company" || got.Description != "A company" {
+				t.Errorf("node = %+v, want the updated name, type and description", got)
+			}
+			if len(got.Embedding) != 3 || got.Embedding[1] != 0.5 {
+				t.Errorf("embedding = %v, want [0 0.5 0.5]", got.Embedding)
+			}
+			if got.Span == nil || got.Span.Start != 15 || got.Span.Text != "Acme" {
+				t.Errorf("span = %+v, want 15-19 Acme", got.Span)
+			}
+
+			edges, err := db.GetExtractedEdges(ctx, id)
+			if err != nil {
+				t.Fatalf("GetExtractedEdges: %v", err)
+			}
+			if len(edges) != 1 || edges[0].TargetNodeName != "Acme Corp" || edges[0].SourceNodeName != "Alice" {
+				t.Errorf("edges = %+v, want the target renamed to Acme Corp", edges)
+			}
+
+			err = db.UpdateNode(ctx, &ExtractedNode{ID: id + "-missing", Name: "Nobody"})
+			if err == nil || !strings.Contains(err.Error(), "not found") {
+				t.Errorf("UpdateNode of a missing node = %v, want not found", err)
+			}
+		})
+	}
+}
+
+// TestUpdateEdge tests overwriting an extracted edge
+func TestUpdateEdge(t *testing.T) {
+	for name, db := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id := "update-edge-" + name
+			seedSource(t, db, id)
+
+			updated := &ExtractedEdge{
+				ID:             id + "-works",
+				SourceNodeName: "Alice",
+				TargetNodeName: "Acme",
+				Relation:       "FOUNDED",
+				Description:    "Alice founded Acme",
+				Weight:         0.4,
+				Confidence:     0.7,
+			}
+			if err := db.UpdateEdge(ctx, updated); err != nil {
+				t.Fatalf("UpdateEdge: %v", err)
+			}
+			// Writing the same values again must not be mistaken for a missing row
+			if err := db.UpdateEdge(ctx, updated); err != nil {
+				t.Fatalf("UpdateEdge with unchanged values: %v", err)
+			}
+
+			edges, err := db.GetExtractedEdges(ctx, id)
+			if err != nil {
+				t.Fatalf("GetExtractedEdges: %v", err)
+			}
+			if len(edges) != 1 {
+				t.Fatalf("got %d edges, want 1", len(edges))
+			}
+			if got := edges[0]; got.Relation != "FOUNDED" || got.Description != "Alice founded Acme" || got.Weight != 0.4 || got.Confidence != 0.7 || got.Span != nil {
+				t.Errorf("edge = %+v, want the updated relation, description, weight and confidence without a span", got)
+			}
+
+			err = db.UpdateEdge(ctx, &ExtractedEdge{ID: id + "-missing"})
+			if err == nil || !strings.Contains(err.Error(), "not found") {
+				t.Errorf("UpdateEdge of a missing edge = %v, want not found", err)
+			}
+		})
+	}
+}
+
+// TestReplaceExtractedKnowledge tests swapping the extraction of one source
+func TestReplaceExtractedKnowledge(t *testing.T) {
+	for name, db := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id := "replace-" + name
+			other := "replace-other-" + name
+			seedSource(t, db, id)
+			seedSource(t, db, other)
+
+			nodes := []*ExtractedNode{{ID: id + "-bob", Name: "Bob", Type: "Person", CreatedAt: time.Now()}}
+			if err := db.ReplaceExtractedKnowledge(ctx, id, nodes, nil); err != nil {
+				t.Fatalf("ReplaceExtractedKnowledge: %v", err)
+			}
+
+			got, err := db.GetExtractedNodes(ctx, id)
+			if err != nil {
+				t.Fatalf("GetExtractedNodes: %v", err)
+			}
+			if len(got) != 1 || got[0].Name != "Bob" || got[0].GroupID != "g1" {
+				t.Errorf("nodes = %+v, want only Bob in the source's group", got)
+			}
+			if edges, _ := db.GetExtractedEdges(ctx, id); len(edges) != 0 {
+				t.Errorf("edges = %+v, want none", edges)
+			}
+			if otherNodes, _ := db.GetExtractedNodes(ctx, other); len(otherNodes) != 2 {
+				t.Errorf("other source has %d nodes, want 2 untouched", len(otherNodes))
+			}
+		})
+	}
+}
+
+// TestDeleteSource tests that deleting a source cascades to its extraction
+func TestDeleteSource(t *testing.T) {
+	for name, db := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id := "delete-" + name
+			seedSource(t, db, id)
+
+			if err := db.DeleteSource(ctx, id); err != nil {
+				t.Fatalf("DeleteSource: %v", err)
+			}
+			if _, err := db.GetSource(ctx, id); err == nil {
+				t.Error("source still exists after DeleteSource")
+			}
+			if nodes, _ := db.GetExtractedNodes(ctx, id); len(nodes) != 0 {
+				t.Errorf("nodes = %+v, want none", nodes)
+			}
+			if edges, _ := db.GetExtractedEdges(ctx, id); len(edges) != 0 {
+				t.Errorf("edges = %+v, want none", edges)
+			}
+		})
+	}
+}
+
+// TestPromotionStatus tests recording promotions and listing sources by status
+func TestPromotionStatus(t *testing.T) {
+	for name, db := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			group := "promotion-" + name
+			now := time.Now().UTC().Truncate(time.Second)
+			for i, id := range []string{"late", "early", "middle"} {
+				source := &Source{ID: group + "-" + id, GroupID: group, CreatedAt: now}
+				source.Reference = now.Add(time.Duration([]int{2, 0, 1}[i]) * time.Hour)
+				if err := db.SaveSource(ctx, source); err != nil {
+					t.Fatalf("SaveSource: %v", err)
+				}
+			}
+			if err := db.SetPromotionStatus(ctx, group+"-middle", PromotionPromoted, "*modeler.DefaultModeler", nil); err != nil {
+				t.Fatalf("SetPromotionStatus: %v", err)
+			}
+
+			pending, err := db.ListSources(ctx, &SourceFilter{GroupID: group, Statuses: []PromotionStatus{PromotionPending}})
+			if err != nil {
+				t.Fatalf("ListSources: %v", err)
+			}
+			if len(pending) != 2 || pending[0].ID != group+"-early" || pending[1].ID != group+"-late" {
+				t.Errorf("pending = %v, want early then late", sourceIDs(pending))
+			}
+
+			promoted, err := db.GetSource(ctx, group+"-middle")
+			if err != nil {
+				t.Fatalf("GetSource: %v", err)
+			}
+			if promoted.PromotionStatus != PromotionPromoted || promoted.PromotedAt == nil || promoted.PromotedBy != "*modeler.DefaultModeler" {
+				t.Errorf("source = %+v, want promoted by the default modeler", promoted)
+			}
+
+			// Saving the source again without a status keeps its promotion state
+			if err := db.SaveSource(ctx, &Source{ID: group + "-middle", Name: "re-extracted", GroupID: group, CreatedAt: now}); err != nil {
+				t.Fatalf("SaveSource: %v", err)
+			}
+			resaved, err := db.GetSource(ctx, group+"-middle")
+			if err != nil {
+				t.Fatalf("GetSource: %v", err)
+			}
+			if resaved.Name != "re-extracted" || resaved.PromotionStatus != PromotionPromoted || resaved.PromotedAt == nil || resaved.PromotedBy != "*modeler.DefaultModeler" {
+				t.Errorf("source = %+v, want the promotion state kept", resaved)
+			}
+
+			if err := db.SetPromotionStatus(ctx, group+"-missing", PromotionFailed, "", nil); err == nil {
+				t.Error("SetPromotionStatus of a missing source should fail")
+			}
+		})
+	}
+}
+
+func sourceIDs(sources []*Source) []string {
+	ids := make([]string, len(sources))
+	for i, s := range sources {
+		ids[i] = s.ID
+	}
+	return ids
+}
@@ -0,0 +1,78 @@
+package factstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// updateNode overwrites the stored fields of the extracted node with node.ID
+// within tx. embedding is the node embedding encoded for the backend. When the
+// name changes, the edges of the same source that reference the old name are
+// renamed too so they keep pointing at the node.
+func updateNode(ctx context.Context, tx *sql.Tx, ph placeholder, node *ExtractedNode, embedding interface{}) error {
+	var sourceID, oldName string
+	err := tx.QueryRowContext(ctx, "SELECT source_id, name FROM extracted_nodes WHERE id = "+ph(1), node.ID).Scan(&sourceID, &oldName)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("extracted node not found: %s", node.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get extracted node: %w", err)
+	}
+
+	spanStart, spanEnd, evidence := spanValues(node.Span)
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf("UPDATE extracted_nodes SET name = %s, type = %s, description = %s, embedding = %s, chunk_index = %s, span_start = %s, span_end = %s, evidence = %s WHERE id = %s",
+			ph(1), ph(2), ph(3), ph(4), ph(5), ph(6), ph(7), ph(8), ph(9)),
+		node.Name, node.Type, node.Description, embedding, node.ChunkIndex, spanStart, spanEnd, evidence, node.ID); err != nil {
+		return fmt.Errorf("failed to update extracted node %s: %w", node.ID, err)
+	}
+
+	if node.Name == oldName {
+		return nil
+	}
+	for _, column := range []string{"source_node_name", "target_node_name"} {
+		if _, err := tx.ExecContext(ctx,
+			fmt.Sprintf("UPDATE extracted_edges SET %s = %s WHERE source_id = %s AND %s = %s", column, ph(1), ph(2), column, ph(3)),
+			node.Name, sourceID, oldName); err != nil {
+			return fmt.Errorf("failed to rename %s of extracted edges: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// updateEdge overwrites the stored fields of the extracted edge with edge.ID
+// within tx. embedding is the edge embedding encoded for the backend.
+func updateEdge(ctx context.Context, tx *sql.Tx, ph placeholder, edge *ExtractedEdge, embedding interface{}) error {
+	if err := requireRow(ctx, tx, ph, "extracted_edges", "extracted edge", edge.ID); err != nil {
+		return err
+	}
+
+	spanStart, spanEnd, evidence := spanValues(edge.Span)
+	_, err := tx.ExecContext(ctx,
+		fmt.Sprintf("UPDATE extracted_edges SET source_node_name = %s, target_node_name = %s, relation = %s, description = %s, embedding = %s, weight = %s, confidence = %s, chunk_index = %s, span_start = %s, span_end = %s, evidence = %s WHERE id = %s",
+			ph(1), ph(2), ph(3), ph(4), ph(5), ph(6), ph(7), ph(8), ph(9), ph(10), ph(11), ph(12)),
+		edge.SourceNodeName, edge.TargetNodeName, edge.Relation, edge.Description, embedding, edge.Weight, edge.Confidence, edge.ChunkIndex,
+		spanStart, spanEnd, evidence, edge.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update extracted edge %s: %w", edge.ID, err)
+	}
+	return nil
+}
+
+// requireRow returns a "<kind> not found" error if table has no row with the given id. Updates
+// check this up front because MySQL-compatible backends such as Dolt report
+// only changed rows as affected.
+func requireRow(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, ph placeholder, table, kind, id string) error {
+	var found int
+	err := q.QueryRowContext(ctx, fmt.Sprintf("SELECT 1 FROM %s WHERE id = %s", table, ph(1)), id).Scan(&found)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%s not found: %s", kind, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up %s %s: %w", kind, id, err)
+	}
+	return nil
+}
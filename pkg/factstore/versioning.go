@@ -0,0 +1,182 @@
+package factstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExtractionRun describes an extraction batch. CommitExtraction records it in
+// the commit message so runs can be told apart in the history.
+type ExtractionRun struct {
+	// PromptVersion is the AddEpisodeOptions.PromptVersion of the run.
+	PromptVersion string `json:"prompt_version,omitempty"`
+	// Model names the extraction model.
+	Model string `json:"model,omitempty"`
+	// Options holds any other settings worth recording, such as entity types
+	// or chunk sizes.
+	Options map[string]interface{} `json:"options,omitempty"`
+	// SourceIDs are the sources extracted in the batch.
+	SourceIDs []string `json:"source_ids,omitempty"`
+}
+
+// Commit is an entry in the history of a versioned fact store.
+type Commit struct {
+	Hash      string    `json:"hash"`
+	Committer string    `json:"committer"`
+	Date      time.Time `json:"date"`
+	Message   string    `json:"message"`
+	// Run is the extraction run recorded by CommitExtraction, or nil.
+	Run *ExtractionRun `json:"run,omitempty"`
+}
+
+// VersionedFactsDB is a FactsDB with a commit history and branches, so that
+// extraction runs can be compared, promoted from a given revision and rolled
+// back. Refs are branch names, tags or commit hashes, optionally followed by
+// ~ and ^ ancestry suffixes. DoltDB implements it.
+type VersionedFactsDB interface {
+	FactsDB
+
+	// CommitExtraction commits the current contents, recording run in the
+	// message, and returns the commit hash. message is the summary line; if
+	// empty, one is derived from run.
+	CommitExtraction(ctx context.Context, run *ExtractionRun, message string) (string, error)
+
+	// Log returns up to limit commits reachable from ref (HEAD if empty),
+	// newest first. Zero means no limit.
+	Log(ctx context.Context, ref string, limit int) ([]*Commit, error)
+
+	// CreateBranch creates a branch at startRef (HEAD if empty).
+	CreateBranch(ctx context.Context, name, startRef string) error
+
+	// DeleteBranch deletes a branch, discarding the runs committed only there.
+	DeleteBranch(ctx context.Context, name string) error
+
+	// OnBranch returns a store that reads and writes the given existing branch.
+	// The caller closes it.
+	OnBranch(ctx context.Context, name string) (VersionedFactsDB, error)
+
+	// Merge merges branch into the current branch.
+	Merge(ctx context.Context, branch string) error
+
+	// ResetTo moves the current branch to ref, discarding later commits and
+	// uncommitted changes. Use it to roll back a bad run.
+	ResetTo(ctx context.Context, ref string) error
+
+	// GetSourceAt retrieves a source as of ref.
+	GetSourceAt(ctx context.Context, ref, sourceID string) (*Source, error)
+
+	// GetExtractedNodesAt retrieves the extracted nodes of a source as of ref.
+	GetExtractedNodesAt(ctx context.Context, ref, sourceID string) ([]*ExtractedNode, error)
+
+	// GetExtractedEdgesAt retrieves the extracted edges of a source as of ref.
+	GetExtractedEdgesAt(ctx context.Context, ref, sourceID string) ([]*ExtractedEdge, error)
+
+	// DiffExtractions compares the extracted knowledge of every source between
+	// two refs. Facts are matched per source as in DiffExtractedKnowledge, so
+	// re-extracted rows with new IDs but the same content are unchanged.
+	DiffExtractions(ctx context.Context, fromRef, toRef string) (*ExtractionDiff, error)
+}
+
+// extractionRunTrailer prefixes the JSON encoded ExtractionRun in a commit message.
+const extractionRunTrailer = "Extraction-Run: "
+
+// refPattern matches the refs accepted by VersionedFactsDB. Refs are inlined in
+// AS OF clauses, which do not take bind parameters, so quotes are never allowed.
+var refPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_./@~^-]*$`)
+
+// validateRef returns an error if ref is not a well-formed ref.
+func validateRef(ref string) error {
+	if !refPattern.MatchString(ref) {
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	return nil
+}
+
+// extractionCommitMessage builds the commit message recording run.
+func extractionCommitMessage(run *ExtractionRun, message string) (string, error) {
+	if run == nil {
+		run = &ExtractionRun{}
+	}
+	if message == "" {
+		message = fmt.Sprintf("Extract %d sources", len(run.SourceIDs))
+		if run.PromptVersion != "" {
+			message += " with prompt " + run.PromptVersion
+		}
+		if run.Model != "" {
+			message += " using " + run.Model
+		}
+	}
+
+	encoded, err := json.Marshal(run)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal extraction run: %w", err)
+	}
+	return message + "\n\n" + extractionRunTrailer + string(encoded), nil
+}
+
+// parseExtractionRun returns the run recorded in a commit message, or nil.
+func parseExtractionRun(message string) *ExtractionRun {
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, extractionRunTrailer) {
+			continue
+		}
+		var run ExtractionRun
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, extractionRunTrailer)), &run); err != nil {
+			return nil
+		}
+		return &run
+	}
+	return nil
+}
+
+// diffExtractionsBySource diffs two snapshots of the fact store source by
+// source and concatenates the results in source ID order.
+func diffExtractionsBySource(oldNodes []*ExtractedNode, oldEdges []*ExtractedEdge, newNodes []*ExtractedNode, newEdges []*ExtractedEdge) *ExtractionDiff {
+	type snapshot struct {
+		oldNodes, newNodes []*ExtractedNode
+		oldEdges, newEdges []*ExtractedEdge
+	}
+	bySource := make(map[string]*snapshot)
+	get := func(sourceID string) *snapshot {
+		if bySource[sourceID] == nil {
+			bySource[sourceID] = &snapshot{}
+		}
+		return bySource[sourceID]
+	}
+	for _, n := range oldNodes {
+		get(n.SourceID).oldNodes = append(get(n.SourceID).oldNodes, n)
+	}
+	for _, n := range newNodes {
+		get(n.SourceID).newNodes = append(get(n.SourceID).newNodes, n)
+	}
+	for _, e := range oldEdges {
+		get(e.SourceID).oldEdges = append(get(e.SourceID).oldEdges, e)
+	}
+	for _, e := range newEdges {
+		get(e.SourceID).newEdges = append(get(e.SourceID).newEdges, e)
+	}
+
+	sourceIDs := make([]string, 0, len(bySource))
+	for sourceID := range bySource {
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+	sort.Strings(sourceIDs)
+
+	diff := &ExtractionDiff{}
+	for _, sourceID := range sourceIDs {
+		s := bySource[sourceID]
+		d := DiffExtractedKnowledge(s.oldNodes, s.oldEdges, s.newNodes, s.newEdges)
+		diff.AddedNodes = append(diff.AddedNodes, d.AddedNodes...)
+		diff.RemovedNodes = append(diff.RemovedNodes, d.RemovedNodes...)
+		diff.UnchangedNodes = append(diff.UnchangedNodes, d.UnchangedNodes...)
+		diff.AddedEdges = append(diff.AddedEdges, d.AddedEdges...)
+		diff.RemovedEdges = append(diff.RemovedEdges, d.RemovedEdges...)
+		diff.UnchangedEdges = append(diff.UnchangedEdges, d.UnchangedEdges...)
+	}
+	return diff
+}
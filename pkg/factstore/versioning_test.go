@@ -0,0 +1,124 @@
+package factstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestDoltVersioning tests committing runs, extracting on a branch, diffing and rolling back
+func TestDoltVersioning(t *testing.T) {
+	ctx := context.Background()
+	db := testStores(t)["dolt"].(*DoltDB)
+	seedSource(t, db, "doc")
+
+	mainRun, err := db.CommitExtraction(ctx, &ExtractionRun{PromptVersion: "v1", Model: "gpt-4o-mini", SourceIDs: []string{"doc"}}, "")
+	if err != nil {
+		t.Fatalf("CommitExtraction: %v", err)
+	}
+
+	if err := db.CreateBranch(ctx, "prompt-v2", ""); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	branch, err := db.OnBranch(ctx, "prompt-v2")
+	if err != nil {
+		t.Fatalf("OnBranch: %v", err)
+	}
+	defer branch.Close()
+
+	// The experimental run renames Acme and adds Bob
+	now := time.Now().UTC().Truncate(time.Second)
+	nodes := []*ExtractedNode{
+		{ID: "v2-alice", Name: "Alice", Type: "Person", CreatedAt: now},
+		{ID: "v2-acme", Name: "Acme Corp", Type: "Organization", CreatedAt: now},
+		{ID: "v2-bob", Name: "Bob", Type: "Person", CreatedAt: now},
+	}
+	edges := []*ExtractedEdge{
+		{ID: "v2-works", SourceNodeName: "Alice", TargetNodeName: "Acme Corp", Relation: "WORKS_AT", CreatedAt: now},
+	}
+	if err := branch.ReplaceExtractedKnowledge(ctx, "doc", nodes, edges); err != nil {
+		t.Fatalf("ReplaceExtractedKnowledge on branch: %v", err)
+	}
+	if _, err := branch.CommitExtraction(ctx, &ExtractionRun{PromptVersion: "v2", Options: map[string]interface{}{"chunk_size": 500}}, "Try prompt v2"); err != nil {
+		t.Fatalf("CommitExtraction on branch: %v", err)
+	}
+
+	// The branch run leaves main untouched
+	if mainNodes, _ := db.GetExtractedNodes(ctx, "doc"); len(mainNodes) != 2 {
+		t.Errorf("main has %d nodes, want the 2 of the first run", len(mainNodes))
+	}
+	branchNodes, err := db.GetExtractedNodesAt(ctx, "prompt-v2", "doc")
+	if err != nil {
+		t.Fatalf("GetExtractedNodesAt: %v", err)
+	}
+	if len(branchNodes) != 3 {
+		t.Errorf("prompt-v2 has %d nodes, want 3", len(branchNodes))
+	}
+	if source, err := db.GetSourceAt(ctx, mainRun, "doc"); err != nil || source.Content != "Alice works at Acme." {
+		t.Errorf("GetSourceAt = %+v, %v", source, err)
+	}
+
+	diff, err := db.DiffExtractions(ctx, "main", "prompt-v2")
+	if err != nil {
+		t.Fatalf("DiffExtractions: %v", err)
+	}
+	if len(diff.AddedNodes) != 2 || len(diff.RemovedNodes) != 1 || len(diff.UnchangedNodes) != 1 {
+		t.Errorf("node diff = +%d -%d =%d, want +2 (Acme Corp, Bob) -1 (Acme) =1 (Alice)",
+			len(diff.AddedNodes), len(diff.RemovedNodes), len(diff.UnchangedNodes))
+	}
+	if len(diff.AddedEdges) != 1 || len(diff.RemovedEdges) != 1 {
+		t.Errorf("edge diff = +%d -%d, want the edge re-targeted", len(diff.AddedEdges), len(diff.RemovedEdges))
+	}
+
+	log, err := db.Log(ctx, "prompt-v2", 1)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(log) != 1 || log[0].Run == nil || log[0].Run.PromptVersion != "v2" || log[0].Run.Options["chunk_size"] != float64(500) {
+		t.Fatalf("log = %+v, want the v2 run", log)
+	}
+	if got := log[0].Message; got[:len("Try prompt v2")] != "Try prompt v2" {
+		t.Errorf("message = %q, want the given summary first", got)
+	}
+
+	// Merging the branch adopts the run; resetting to the first run rolls it back
+	if err := db.Merge(ctx, "prompt-v2"); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if merged, _ := db.GetExtractedNodes(ctx, "doc"); len(merged) != 3 {
+		t.Errorf("main has %d nodes after merge, want 3", len(merged))
+	}
+	if err := db.ResetTo(ctx, mainRun); err != nil {
+		t.Fatalf("ResetTo: %v", err)
+	}
+	if reset, _ := db.GetExtractedNodes(ctx, "doc"); len(reset) != 2 {
+		t.Errorf("main has %d nodes after reset, want 2", len(reset))
+	}
+
+	if _, err := db.OnBranch(ctx, "missing"); err == nil {
+		t.Error("OnBranch of a missing branch should fail")
+	}
+	if _, err := db.GetExtractedNodesAt(ctx, "main'; DROP TABLE sources; --", "doc"); err == nil {
+		t.Error("a ref with quotes should be rejected")
+	}
+}
+
+// TestExtractionCommitMessage tests recording runs in commit messages
+func TestExtractionCommitMessage(t *testing.T) {
+	message, err := extractionCommitMessage(&ExtractionRun{PromptVersion: "v3", Model: "qwen", SourceIDs: []string{"a", "b"}}, "")
+	if err != nil {
+		t.Fatalf("extractionCommitMessage: %v", err)
+	}
+	want := "Extract 2 sources with prompt v3 using qwen\n\n" + extractionRunTrailer + `{"prompt_version":"v3","model":"qwen","source_ids":["a","b"]}`
+	if message != want {
+		t.Errorf("message = %q, want %q", message, want)
+	}
+
+	run := parseExtractionRun(message)
+	if run == nil || run.Model != "qwen" || len(run.SourceIDs) != 2 {
+		t.Errorf("parsed run = %+v", run)
+	}
+	if parseExtractionRun("Manual fix") != nil {
+		t.Error("a message without a run should parse to nil")
+	}
+}
@@ -4,17 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/soundprediction/predicato/pkg/nlp"
 	"github.com/soundprediction/predicato/pkg/types"
 )
 
 // LLMAdapter wraps a gliner.Client to implement nlp.Client interface
-// It intercepts extraction prompts and uses GLiNER models
-// It delegates other prompts to a base LLM client
+// It implements nlp.EntityExtractor and nlp.RelationExtractor with GLiNER models
+// It delegates prompts to a base LLM client
 type LLMAdapter struct {
 	glinerClient *Client
 	baseClient   nlp.Client
@@ -69,41 +66,19 @@ func (a *LLMAdapter) GetCapabilities() []nlp.TaskCapability {
 	return caps
 }
 
-func (a *LLMAdapter) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
-	// 1. Inspect messages to detect extraction pattern
-	if len(messages) == 0 {
-		return &types.Response{Content: ""}, nil
-	}
-
-	systemMsg := ""
-	lastUserMsg := ""
-	for _, m := range messages {
-		if m.Role == "system" { // nlp.RoleSystem is constrained const, string comparison safe
-			systemMsg = m.Content
-		}
-		if m.Role == "user" {
-			lastUserMsg = m.Content
-		}
-	}
-
-	// NODE EXTRACTION DETECTION
-	if strings.Contains(systemMsg, "extracts entity nodes") || strings.Contains(systemMsg, "extracts entity nodes from") {
-		a.logger.Info("GLiNER Adapter: Detected Node Extraction request")
-		return a.handleNodeExtraction(lastUserMsg)
-	}
-
-	// EDGE EXTRACTION DETECTION
-	if strings.Contains(systemMsg, "expert fact extractor") || strings.Contains(systemMsg, "extracts fact triples") {
-		a.logger.Info("GLiNER Adapter: Detected Edge Extraction request")
-		return a.handleEdgeExtraction(lastUserMsg)
-	}
+// IsLocalFor implements nlp.LocalityReporter. GLiNER runs locally, but
+// requests it cannot serve go to the base client.
+func (a *LLMAdapter) IsLocalFor(usage string) bool {
+	return a.baseClient == nil || nlp.IsLocalFor(a.baseClient, usage)
+}
 
-	// Fallback to base client
+// Chat implements nlp.Client by delegating to the base client. Extraction
+// requests reach GLiNER through ExtractTypedEntities and ExtractTypedRelations.
+func (a *LLMAdapter) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
 	if a.baseClient != nil {
 		return a.baseClient.Chat(ctx, messages)
 	}
-
-	return nil, fmt.Errorf("no base client configured and prompt not handled by GLiNER")
+	return nil, fmt.Errorf("no base client configured for GLiNER adapter")
 }
 
 func (a *LLMAdapter) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
@@ -115,251 +90,93 @@ func (a *LLMAdapter) ChatWithStructuredOutput(ctx context.Context, messages []ty
 	return nil, fmt.Errorf("ChatWithStructuredOutput not supported by GLiNER adapter without base client")
 }
 
-// ---- Extraction Handlers ----
-
-// parseSection extracts content between <TAG> and </TAG>
-func parseSection(text, tag string) string {
-	re := regexp.MustCompile(fmt.Sprintf(`<%s>\s*([\s\S]*?)\s*</%s>`, tag, tag))
-	match := re.FindStringSubmatch(text)
-	if len(match) > 1 {
-		return match[1]
-	}
-	return ""
-}
-
-// parseTSV parses simple TSV string into slice of records
-func parseTSV(tsv string) [][]string {
-	lines := strings.Split(tsv, "\n")
-	var records [][]string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.Split(line, "\t")
-		// clean quotes?
-		for i := range parts {
-			parts[i] = strings.Trim(parts[i], "\"")
-		}
-		records = append(records, parts)
-	}
-	return records
-}
-
-func (a *LLMAdapter) handleNodeExtraction(userMsg string) (*types.Response, error) {
-	// Parse input
-	entityTypesTSV := parseSection(userMsg, "ENTITY TYPES")
-	text := parseSection(userMsg, "TEXT")
-	if text == "" {
-		text = parseSection(userMsg, "CURRENT MESSAGE")
-	}
-	if text == "" {
-		text = parseSection(userMsg, "JSON")
-	}
-
-	// Parse Entity Types to map Name -> ID
-	// Format: entity_type_id\tentity_type_name...
-	typesRecords := parseTSV(entityTypesTSV)
-
-	labelToID := make(map[string]string)
-	var labels []string
-
-	// Skip header if present (check if first row has "entity_type_id" string)
-	startIndex := 0
-	if len(typesRecords) > 0 && (strings.Contains(typesRecords[0][0], "entity_type_id") || strings.Contains(typesRecords[0][1], "entity_type_name")) {
-		startIndex = 1
+// ExtractTypedEntities implements nlp.EntityExtractor with the GLiNER span model.
+func (a *LLMAdapter) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	labels := make([]string, len(req.EntityTypes))
+	for i, entityType := range req.EntityTypes {
+		labels[i] = entityType.Name
 	}
 
-	for i := startIndex; i < len(typesRecords); i++ {
-		row := typesRecords[i]
-		if len(row) < 2 {
-			continue
-		}
-		// Assuming standard prompts: entity_type_id is col 0, entity_type_name is col 1
-		id := row[0]
-		name := row[1]
-		// Verify
-		if _, err := strconv.Atoi(id); err != nil {
-			// maybe swapped?
-			if _, err2 := strconv.Atoi(row[1]); err2 == nil {
-				id = row[1]
-				name = row[0]
-			}
-		}
-
-		labelToID[name] = id
-		labels = append(labels, name)
-	}
-
-	// Run Extraction
-	entities, err := a.glinerClient.ExtractEntities(text, labels)
+	a.logger.Debug("GLiNER Adapter: extracting entities", "labels", len(labels))
+	entities, err := a.glinerClient.ExtractEntities(req.Text, labels)
 	if err != nil {
 		return nil, fmt.Errorf("GLiNER node extraction failed: %w", err)
 	}
 
-	// Format Output as TSV
-	// entity\tentity_type_id
-	var sb strings.Builder
-	sb.WriteString("entity\tentity_type_id\n")
-
+	extracted := make([]nlp.ExtractedEntity, 0, len(entities))
 	for _, e := range entities {
-		id, ok := labelToID[e.Label]
-		if !ok {
-			// Should not happen if labels matched
-			id = "-1"
-		}
-		sb.WriteString(fmt.Sprintf("%s\t%s\n", e.Text, id))
-	}
-
-	return &types.Response{
-		Content: sb.String(),
-	}, nil
+		extracted = append(extracted, nlp.ExtractedEntity{
+			Name:  e.Text,
+			Type:  e.Label,
+			Span:  types.LocateSpan(req.Text, e.Text),
+			Score: float64(e.Score),
+		})
+	}
+	return extracted, nil
 }
 
-func (a *LLMAdapter) handleEdgeExtraction(userMsg string) (*types.Response, error) {
-	// Parse input
-	factTypesTSV := parseSection(userMsg, "FACT TYPES")
-	extractedEntitiesTSV := parseSection(userMsg, "ENTITIES")
-	text := parseSection(userMsg, "CURRENT_MESSAGE") // in edgePrompt it's CURRENT_MESSAGE
-	if text == "" {
-		text = parseSection(userMsg, "CURRENT MESSAGE")
-	}
-
-	// Parse Entities to map Text -> ID (as string)
-	entitiesRecords := parseTSV(extractedEntitiesTSV)
-	nameToID := make(map[string]string)
-
-	// Skip header
-	startIndex := 0
-	if len(entitiesRecords) > 0 && (strings.Contains(entitiesRecords[0][0], "id") || strings.Contains(entitiesRecords[0][1], "name")) {
-		startIndex = 1
+// ExtractTypedRelations implements nlp.RelationExtractor with the GLiNER
+// relation model, which must have been loaded with LoadRelationModel.
+func (a *LLMAdapter) ExtractTypedRelations(ctx context.Context, req *nlp.RelationExtractionRequest) ([]nlp.ExtractedRelation, error) {
+	if len(req.EdgeTypeMap) == 0 {
+		return nil, nil
 	}
 
-	// Need to identify columns dynamically
-	idCol := -1
-	nameCol := -1
-	if len(entitiesRecords) > 0 {
-		header := entitiesRecords[0]
-		for i, h := range header {
-			if h == "id" || h == "node_id" {
-				idCol = i
-			}
-			if h == "name" || h == "entity" {
-				nameCol = i
-			}
+	// Entity labels are the entities' types and those named in signatures
+	var labels []string
+	seen := make(map[string]bool)
+	addLabel := func(label string) {
+		if label != "" && !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
 		}
 	}
-	// Fallback default
-	if idCol == -1 {
-		idCol = 0
+	for _, entity := range req.Entities {
+		addLabel(entity.Type)
 	}
-	if nameCol == -1 {
-		nameCol = 1
-	}
-
-	// Collect labels for GLiNER Relation (Entity Labels)
-	allLabelsMap := make(map[string]bool)
 
-	// Parse Fact Types (Schema)
-	factTypesRecords := parseTSV(factTypesTSV)
-	schema := make(map[string][2][]string) // Rel -> [Heads, Tails]
-
-	startFact := 0
-	if len(factTypesRecords) > 0 && strings.Contains(factTypesRecords[0][0], "relation_type") {
-		startFact = 1
-	}
-
-	// Find columns (sorted keys)
-	sigCol := -1
-	relCol := -1
-
-	if len(factTypesRecords) > 0 {
-		header := factTypesRecords[0]
-		for i, h := range header {
-			if h == "fact_type_signature" {
-				sigCol = i
-			}
-			if h == "relation_type" {
-				relCol = i
+	// Rel -> [Heads, Tails]
+	schema := make(map[string][2][]string, len(req.EdgeTypeMap))
+	for relationType, signatures := range req.EdgeTypeMap {
+		var heads, tails []string
+		for _, signature := range signatures {
+			if len(signature) == 2 {
+				heads = append(heads, signature[0])
+				tails = append(tails, signature[1])
+				addLabel(signature[0])
+				addLabel(signature[1])
 			}
 		}
+		schema[relationType] = [2][]string{heads, tails}
 	}
-	if sigCol == -1 {
-		sigCol = 0
-	}
-	if relCol == -1 {
-		relCol = 1
-	}
-
-	for i := startFact; i < len(factTypesRecords); i++ {
-		row := factTypesRecords[i]
-		if len(row) <= max(sigCol, relCol) {
-			continue
-		}
-
-		sig := row[sigCol]
-		rel := row[relCol]
-
-		// Parse matches "Head->REL->Tail"
-		parts := strings.Split(sig, "->")
-		if len(parts) == 3 {
-			head := parts[0]
-			tail := parts[2]
-
-			// Add to schema
-			schema[rel] = [2][]string{{head}, {tail}}
-
-			allLabelsMap[head] = true
-			allLabelsMap[tail] = true
-		}
-	}
-
-	var allLabels []string
-	for l := range allLabelsMap {
-		allLabels = append(allLabels, l)
-	}
-
-	// Populate nameToID mapping
-	for i := startIndex; i < len(entitiesRecords); i++ {
-		row := entitiesRecords[i]
-		if len(row) <= max(idCol, nameCol) {
-			continue
+	// Unconstrained types may connect any entities
+	for relationType, heads := range schema {
+		if len(heads[0]) == 0 {
+			schema[relationType] = [2][]string{labels, labels}
 		}
-		id := row[idCol]
-		name := row[nameCol]
-		nameToID[name] = id
 	}
 
-	// Run Extraction
-	relations, err := a.glinerClient.ExtractRelations(text, allLabels, schema)
+	a.logger.Debug("GLiNER Adapter: extracting relations", "relation_types", len(schema))
+	relations, err := a.glinerClient.ExtractRelations(req.Text, labels, schema)
 	if err != nil {
 		return nil, fmt.Errorf("GLiNER relation extraction failed: %w", err)
 	}
 
-	// Format Output as TSV
-	var sb strings.Builder
-	sb.WriteString("source_id\trelation_type\ttarget_id\tfact\tsummary\tvalid_at\tinvalid_at\n")
-
+	var extracted []nlp.ExtractedRelation
 	for _, r := range relations {
-		srcID, okSrc := nameToID[r.Source]
-		tgtID, okTgt := nameToID[r.Target]
-
-		// Only emit if we can map back to IDs
-		if okSrc && okTgt {
-			fact := fmt.Sprintf("%s %s %s", r.Source, r.Type, r.Target)
-			sb.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-				srcID, r.Type, tgtID, fact, "", "null", "null"))
+		source := nlp.FindEntity(req.Entities, r.Source)
+		target := nlp.FindEntity(req.Entities, r.Target)
+		// Only emit relations between the given entities
+		if source < 0 || target < 0 {
+			continue
 		}
-	}
-
-	return &types.Response{
-		Content: sb.String(),
-	}, nil
-}
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+		extracted = append(extracted, nlp.ExtractedRelation{
+			Source: source,
+			Target: target,
+			Type:   r.Type,
+			Fact:   fmt.Sprintf("%s %s %s", r.Source, r.Type, r.Target),
+			Score:  float64(r.Score),
+		})
+	}
+	return extracted, nil
 }
@@ -1,12 +1,14 @@
 package gliner
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 
 	"github.com/soundprediction/go-gline-rs/pkg/gline"
+	"github.com/soundprediction/predicato/pkg/pii"
 )
 
 type Client struct {
@@ -122,6 +124,19 @@ func (c *Client) ExtractEntities(text string, labels []string) ([]Entity, error)
 	return entities, nil
 }
 
+// RecognizeEntities implements pii.Recognizer.
+func (c *Client) RecognizeEntities(ctx context.Context, text string, labels []string) ([]pii.Entity, error) {
+	entities, err := c.ExtractEntities(text, labels)
+	if err != nil {
+		return nil, err
+	}
+	recognized := make([]pii.Entity, 0, len(entities))
+	for _, e := range entities {
+		recognized = append(recognized, pii.Entity{Text: e.Text, Label: e.Label, Score: float64(e.Score)})
+	}
+	return recognized, nil
+}
+
 func (c *Client) ExtractRelations(text string, entityLabels []string, schema map[string][2][]string) ([]Relation, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -3,9 +3,11 @@ package gliner2
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/pii"
 	"github.com/soundprediction/predicato/pkg/types"
 )
 
@@ -63,6 +65,20 @@ func (c *Client) GetCapabilities() []nlp.TaskCapability {
 	}
 }
 
+// IsLocalFor implements nlp.LocalityReporter. The native provider runs in
+// process and the local provider is local when its endpoint is on this
+// machine; the Fastino API is always remote.
+func (c *Client) IsLocalFor(usage string) bool {
+	switch c.provider {
+	case ProviderNative:
+		return true
+	case ProviderLocal:
+		return c.config.Local != nil && nlp.IsLocalEndpoint(c.config.Local.Endpoint)
+	default:
+		return false
+	}
+}
+
 func (c *Client) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
 	if len(messages) == 0 {
 		return &types.Response{Content: ""}, nil
@@ -80,15 +96,8 @@ func (c *Client) Chat(ctx context.Context, messages []types.Message) (*types.Res
 		}
 	}
 
-	// ENTITY EXTRACTION DETECTION
-	if strings.Contains(systemMsg, "extracts entity nodes") || strings.Contains(systemMsg, "extracts entity nodes from") {
-		return c.handleNodeExtraction(ctx, lastUserMsg)
-	}
-
-	// EDGE/FACT EXTRACTION DETECTION
-	if strings.Contains(systemMsg, "expert fact extractor") || strings.Contains(systemMsg, "extracts fact triples") {
-		return c.handleFactExtraction(ctx, lastUserMsg)
-	}
+	// Entity and fact extraction go through ExtractTypedEntities and
+	// ExtractTypedRelations
 
 	// TEXT CLASSIFICATION DETECTION
 	if strings.Contains(systemMsg, "text classifier") || strings.Contains(systemMsg, "classify text") {
@@ -142,6 +151,40 @@ func (c *Client) ExtractEntities(ctx context.Context, text string, entityTypes [
 	}
 }
 
+// ClassifyText provides direct access to text classification
+func (c *Client) ClassifyText(ctx context.Context, text string, schema interface{}, threshold float64) (*ClassificationResult, error) {
+	switch c.provider {
+	case ProviderNative:
+		return c.nativeClient.ClassifyText(ctx, text, schema, threshold)
+	case ProviderLocal, ProviderFastino:
+		if c.httpClient == nil {
+			return nil, fmt.Errorf("HTTP client not available")
+		}
+		return c.httpClient.ClassifyText(ctx, text, schema, threshold)
+	default:
+		return nil, fmt.Errorf("unsupported provider: %v", c.provider)
+	}
+}
+
+// RecognizeEntities implements pii.Recognizer
+func (c *Client) RecognizeEntities(ctx context.Context, text string, labels []string) ([]pii.Entity, error) {
+	entities, err := c.ExtractEntities(ctx, text, labels)
+	if err != nil {
+		return nil, err
+	}
+	recognized := make([]pii.Entity, 0, len(entities))
+	for _, e := range entities {
+		recognized = append(recognized, pii.Entity{
+			Text:  e.Text,
+			Label: e.Label,
+			Score: e.Confidence,
+			Start: e.Start,
+			End:   e.End,
+		})
+	}
+	return recognized, nil
+}
+
 // ExtractFacts provides direct access to fact extraction (GLInER2 relations)
 func (c *Client) ExtractFacts(ctx context.Context, text string, relationTypes []string) ([]Fact, error) {
 	switch c.provider {
@@ -166,130 +209,80 @@ func (c *Client) ExtractFacts(ctx context.Context, text string, relationTypes []
 	}
 }
 
-func (c *Client) handleNodeExtraction(ctx context.Context, userMsg string) (*types.Response, error) {
-	entityTypesTSV := parseSection(userMsg, "ENTITY TYPES")
-	text := parseSection(userMsg, "TEXT")
-	if text == "" {
-		text = parseSection(userMsg, "CURRENT MESSAGE")
-	}
-	if text == "" {
-		text = parseSection(userMsg, "JSON")
-	}
-
-	// Parse Entity Types to map Name -> ID
-	typesRecords := parseTSV(entityTypesTSV)
-	labelToID := make(map[string]string)
-	var labels []string
-
-	// Skip header if present
-	startIndex := 0
-	if len(typesRecords) > 0 && (strings.Contains(typesRecords[0][0], "entity_type_id") || strings.Contains(typesRecords[0][1], "entity_type_name")) {
-		startIndex = 1
-	}
-
-	for i := startIndex; i < len(typesRecords); i++ {
-		row := typesRecords[i]
-		if len(row) < 2 {
-			continue
-		}
-		id := row[0]
-		name := row[1]
-		labelToID[name] = id
-		labels = append(labels, name)
+// ExtractTypedEntities implements nlp.EntityExtractor
+func (c *Client) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	labels := make([]string, len(req.EntityTypes))
+	for i, entityType := range req.EntityTypes {
+		labels[i] = entityType.Name
 	}
 
-	// Run entity extraction
-	entities, err := c.ExtractEntities(ctx, text, labels)
+	entities, err := c.ExtractEntities(ctx, req.Text, labels)
 	if err != nil {
 		return nil, fmt.Errorf("GLInER2 node extraction failed: %w", err)
 	}
 
-	// Format Output as TSV (like existing GLInER adapter)
-	var sb strings.Builder
-	sb.WriteString("entity\tentity_type_id\n")
-
+	extracted := make([]nlp.ExtractedEntity, 0, len(entities))
 	for _, e := range entities {
-		id, ok := labelToID[e.Label]
-		if !ok {
-			id = "-1"
+		span := types.NewSpan(req.Text, e.Start, e.End)
+		if span == nil {
+			span = types.LocateSpan(req.Text, e.Text)
 		}
-		sb.WriteString(fmt.Sprintf("%s\t%s\n", e.Text, id))
+		extracted = append(extracted, nlp.ExtractedEntity{
+			Name:  e.Text,
+			Type:  e.Label,
+			Span:  span,
+			Score: e.Confidence,
+		})
 	}
-
-	return &types.Response{
-		Content: sb.String(),
-	}, nil
+	return extracted, nil
 }
 
-func (c *Client) handleFactExtraction(ctx context.Context, userMsg string) (*types.Response, error) {
-	factTypesTSV := parseSection(userMsg, "FACT TYPES")
-	extractedEntitiesTSV := parseSection(userMsg, "ENTITIES")
-	text := parseSection(userMsg, "CURRENT_MESSAGE")
-	if text == "" {
-		text = parseSection(userMsg, "CURRENT MESSAGE")
+// ExtractTypedRelations implements nlp.RelationExtractor with GLInER2 relations
+func (c *Client) ExtractTypedRelations(ctx context.Context, req *nlp.RelationExtractionRequest) ([]nlp.ExtractedRelation, error) {
+	relationTypes := make([]string, 0, len(req.EdgeTypeMap))
+	for relationType := range req.EdgeTypeMap {
+		relationTypes = append(relationTypes, relationType)
 	}
+	sort.Strings(relationTypes)
 
-	// Parse Entities to map Text -> ID
-	entitiesRecords := parseTSV(extractedEntitiesTSV)
-	nameToID := make(map[string]string)
-
-	// Skip header
-	startIndex := 0
-	if len(entitiesRecords) > 0 && (strings.Contains(entitiesRecords[0][0], "id") || strings.Contains(entitiesRecords[0][1], "name")) {
-		startIndex = 1
+	facts, err := c.ExtractFacts(ctx, req.Text, relationTypes)
+	if err != nil {
+		return nil, fmt.Errorf("GLInER2 fact extraction failed: %w", err)
 	}
 
-	for i := startIndex; i < len(entitiesRecords); i++ {
-		row := entitiesRecords[i]
-		if len(row) < 2 {
+	var extracted []nlp.ExtractedRelation
+	for _, f := range facts {
+		source := nlp.FindEntity(req.Entities, f.Source)
+		target := nlp.FindEntity(req.Entities, f.Target)
+		// Only emit facts between the given entities
+		if source < 0 || target < 0 {
 			continue
 		}
-		id := row[0]
-		name := row[1]
-		nameToID[name] = id
-	}
-
-	// Parse Fact Types (Relation Types)
-	factTypesRecords := parseTSV(factTypesTSV)
-	var relationTypes []string
-
-	startFact := 0
-	if len(factTypesRecords) > 0 && strings.Contains(factTypesRecords[0][0], "relation_type") {
-		startFact = 1
+		extracted = append(extracted, nlp.ExtractedRelation{
+			Source: source,
+			Target: target,
+			Type:   f.Type,
+			Fact:   fmt.Sprintf("%s %s %s", f.Source, f.Type, f.Target),
+			// The evidence span runs from the first to the last mention of the pair
+			Span:  factSpan(req.Text, f),
+			Score: f.Confidence,
+		})
 	}
+	return extracted, nil
+}
 
-	for i := startFact; i < len(factTypesRecords); i++ {
-		row := factTypesRecords[i]
-		if len(row) > 0 {
-			relationTypes = append(relationTypes, row[0])
+// factSpan returns the span of text covering both argument mentions of a fact.
+func factSpan(text string, f Fact) *types.Span {
+	var union *types.Span
+	for _, s := range []*Span{f.SourceSpan, f.TargetSpan} {
+		if s != nil && s.End > s.Start {
+			union = union.Union(&types.Span{Start: s.Start, End: s.End})
 		}
 	}
-
-	// Run fact extraction using GLInER2 relations
-	facts, err := c.ExtractFacts(ctx, text, relationTypes)
-	if err != nil {
-		return nil, fmt.Errorf("GLInER2 fact extraction failed: %w", err)
+	if union == nil {
+		return nil
 	}
-
-	// Format Output as TSV (like existing GLInER adapter)
-	var sb strings.Builder
-	sb.WriteString("source_id\trelation_type\ttarget_id\tfact\tsummary\tvalid_at\tinvalid_at\n")
-
-	for _, f := range facts {
-		srcID, okSrc := nameToID[f.Source]
-		tgtID, okTgt := nameToID[f.Target]
-
-		// Only emit if we can map back to IDs
-		if okSrc && okTgt {
-			fact := fmt.Sprintf("%s %s %s", f.Source, f.Type, f.Target)
-			sb.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-				srcID, f.Type, tgtID, fact, "", "null", "null"))
-		}
-	}
-
-	return &types.Response{
-		Content: sb.String(),
-	}, nil
+	return types.NewSpan(text, union.Start, union.End)
 }
 
 func (c *Client) handleTextClassification(ctx context.Context, userMsg string) (*types.Response, error) {
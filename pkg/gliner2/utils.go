@@ -1,13 +1,15 @@
 package gliner2
 
 import (
+	"encoding/json"
 	"regexp"
 	"strings"
 )
 
 // parseSection extracts content between <TAG> and </TAG>
 func parseSection(text, tag string) string {
-	re := regexp.MustCompile(regexp.QuoteMeta(`<` + tag + `>\s*([\s\S]*?)\s*</` + tag + `>`))
+	quoted := regexp.QuoteMeta(tag)
+	re := regexp.MustCompile(`<` + quoted + `>\s*([\s\S]*?)\s*</` + quoted + `>`)
 	match := re.FindStringSubmatch(text)
 	if len(match) > 1 {
 		return match[1]
@@ -33,3 +35,42 @@ func parseTSV(tsv string) [][]string {
 	}
 	return records
 }
+
+// extractClassificationSchema builds a GLInER2 classification schema, mapping
+// task names to their labels, from the LABELS section of a prompt. Each row is
+// a task name followed by its labels; rows with a single column are labels of
+// a task named "classification".
+func extractClassificationSchema(userMsg string) map[string][]string {
+	schema := make(map[string][]string)
+	for _, row := range parseTSV(parseSection(userMsg, "LABELS")) {
+		if len(row) == 1 {
+			schema["classification"] = append(schema["classification"], row[0])
+			continue
+		}
+		schema[row[0]] = append(schema[row[0]], row[1:]...)
+	}
+	return schema
+}
+
+// extractTextContent returns the text to process from a prompt, falling back
+// to the whole message when it has no TEXT or CURRENT MESSAGE section.
+func extractTextContent(userMsg string) string {
+	for _, tag := range []string{"TEXT", "CURRENT MESSAGE"} {
+		if text := parseSection(userMsg, tag); text != "" {
+			return text
+		}
+	}
+	return strings.TrimSpace(userMsg)
+}
+
+// formatClassificationResult formats classifications as JSON keyed by task.
+func formatClassificationResult(result *ClassificationResult) (string, error) {
+	if result == nil || result.Classifications == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(result.Classifications)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
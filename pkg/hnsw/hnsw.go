@@ -0,0 +1,523 @@
+// Package hnsw implements an in-process Hierarchical Navigable Small World
+// graph for approximate nearest neighbor search over embeddings.
+//
+// Vectors are compared by cosine similarity. Removal marks a vector as
+// deleted; deleted vectors still route searches but are never returned, and
+// the graph is rebuilt once they make up half of the index.
+package hnsw
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Config holds the HNSW construction and search parameters.
+type Config struct {
+	// M is the number of neighbors kept per node on each layer above the
+	// base layer, which keeps 2*M. Higher values improve recall at the cost
+	// of memory and insert time.
+	M int
+	// EfConstruction is the candidate list size used when inserting.
+	EfConstruction int
+	// EfSearch is the default candidate list size used when searching.
+	// Higher values improve recall at the cost of latency.
+	EfSearch int
+}
+
+// DefaultConfig returns the default HNSW parameters.
+func DefaultConfig() Config {
+	return Config{
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       100,
+	}
+}
+
+// withDefaults fills unset parameters from DefaultConfig.
+func (c Config) withDefaults() Config {
+	defaults := DefaultConfig()
+	if c.M < 2 {
+		c.M = defaults.M
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = defaults.EfConstruction
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = defaults.EfSearch
+	}
+	return c
+}
+
+// Result is a search hit with its cosine similarity to the query.
+type Result struct {
+	ID    string
+	Score float32
+}
+
+// node is a vector in the graph with its neighbor lists per layer.
+type node struct {
+	id        string
+	vector    []float32
+	neighbors [][]int32
+	deleted   bool
+}
+
+// Index is an HNSW index safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	cfg      Config
+	dims     int
+	nodes    []*node
+	ids      map[string]int32
+	entry    int32
+	maxLevel int
+	deleted  int
+	levelMul float64
+	rng      *rand.Rand
+}
+
+// New creates an empty index.
+func New(cfg Config) *Index {
+	cfg = cfg.withDefaults()
+	return &Index{
+		cfg:      cfg,
+		ids:      make(map[string]int32),
+		entry:    -1,
+		levelMul: 1 / math.Log(float64(cfg.M)),
+		rng:      rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Config returns the parameters the index was created with.
+func (idx *Index) Config() Config {
+	return idx.cfg
+}
+
+// Len returns the number of vectors that have not been removed.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.ids)
+}
+
+// Dimensions returns the vector dimension, or 0 if the index is empty.
+func (idx *Index) Dimensions() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.dims
+}
+
+// Contains reports whether id is in the index.
+func (idx *Index) Contains(id string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	_, ok := idx.ids[id]
+	return ok
+}
+
+// Add inserts vector under id, replacing any vector already stored for id.
+func (idx *Index) Add(id string, vector []float32) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("empty vector for %s", id)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.dims != 0 && len(vector) != idx.dims {
+		return fmt.Errorf("vector for %s has dimension %d, index has %d", id, len(vector), idx.dims)
+	}
+
+	idx.removeLocked(id)
+	if idx.dims == 0 {
+		idx.dims = len(vector)
+	}
+	idx.insert(id, normalize(vector))
+	return nil
+}
+
+// Remove deletes id from the index. It reports whether id was present.
+func (idx *Index) Remove(id string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.removeLocked(id)
+}
+
+// Search returns up to k vectors most similar to query, best first. ef is the
+// candidate list size; values below k fall back to the configured EfSearch.
+func (idx *Index) Search(query []float32, k, ef int) []Result {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if k <= 0 || idx.entry < 0 || len(query) != idx.dims {
+		return nil
+	}
+	if ef < k {
+		ef = max(idx.cfg.EfSearch, k)
+	}
+
+	q := normalize(query)
+	cur := idx.greedyDescend(q, idx.entry, idx.maxLevel, 0)
+	candidates := idx.searchLayer(q, []int32{cur}, ef, 0)
+
+	results := make([]Result, 0, min(k, len(candidates)))
+	for _, c := range candidates {
+		if idx.nodes[c.id].deleted {
+			continue
+		}
+		results = append(results, Result{ID: idx.nodes[c.id].id, Score: c.score})
+		if len(results) == k {
+			break
+		}
+	}
+	return results
+}
+
+// removeLocked marks id as deleted and compacts the graph when deleted
+// vectors make up half of it. The caller must hold the write lock.
+func (idx *Index) removeLocked(id string) bool {
+	i, ok := idx.ids[id]
+	if !ok {
+		return false
+	}
+	delete(idx.ids, id)
+	idx.nodes[i].deleted = true
+	idx.deleted++
+
+	if len(idx.ids) == 0 {
+		idx.reset()
+	} else if idx.deleted*2 >= len(idx.nodes) {
+		idx.compact()
+	}
+	return true
+}
+
+// reset empties the graph, keeping its parameters.
+func (idx *Index) reset() {
+	idx.dims = 0
+	idx.nodes = nil
+	idx.ids = make(map[string]int32)
+	idx.entry = -1
+	idx.maxLevel = 0
+	idx.deleted = 0
+}
+
+// compact rebuilds the graph from the vectors that have not been removed.
+func (idx *Index) compact() {
+	live := make([]*node, 0, len(idx.ids))
+	for _, n := range idx.nodes {
+		if !n.deleted {
+			live = append(live, n)
+		}
+	}
+	dims := idx.dims
+	idx.reset()
+	idx.dims = dims
+	for _, n := range live {
+		idx.insert(n.id, n.vector)
+	}
+}
+
+// insert adds a normalized vector to the graph.
+func (idx *Index) insert(id string, vector []float32) {
+	level := idx.randomLevel()
+	i := int32(len(idx.nodes))
+	n := &node{id: id, vector: vector, neighbors: make([][]int32, level+1)}
+	idx.nodes = append(idx.nodes, n)
+	idx.ids[id] = i
+
+	if idx.entry < 0 {
+		idx.entry = i
+		idx.maxLevel = level
+		return
+	}
+
+	cur := idx.greedyDescend(vector, idx.entry, idx.maxLevel, level+1)
+	entryPoints := []int32{cur}
+	for l := min(level, idx.maxLevel); l >= 0; l-- {
+		candidates := idx.searchLayer(vector, entryPoints, idx.cfg.EfConstruction, l)
+		n.neighbors[l] = idx.selectNeighbors(candidates, idx.maxNeighbors(l))
+		for _, nb := range n.neighbors[l] {
+			idx.link(nb, i, l)
+		}
+		entryPoints = entryPoints[:0]
+		for _, c := range candidates {
+			entryPoints = append(entryPoints, c.id)
+		}
+	}
+
+	if level > idx.maxLevel {
+		idx.entry = i
+		idx.maxLevel = level
+	}
+}
+
+// link adds a connection from node `from` to node `to` on layer l, pruning
+// the neighbor list back to its maximum size if needed.
+func (idx *Index) link(from, to int32, l int) {
+	n := idx.nodes[from]
+	n.neighbors[l] = append(n.neighbors[l], to)
+	limit := idx.maxNeighbors(l)
+	if len(n.neighbors[l]) <= limit {
+		return
+	}
+
+	candidates := make([]candidate, len(n.neighbors[l]))
+	for j, nb := range n.neighbors[l] {
+		candidates[j] = candidate{id: nb, score: dot(n.vector, idx.nodes[nb].vector)}
+	}
+	sort.Slice(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+	n.neighbors[l] = idx.selectNeighbors(candidates, limit)
+}
+
+// selectNeighbors picks up to m neighbors from candidates sorted best first,
+// preferring candidates that are closer to the new node than to any neighbor
+// already selected, so that links spread in different directions.
+func (idx *Index) selectNeighbors(candidates []candidate, m int) []int32 {
+	selected := make([]int32, 0, m)
+	var skipped []int32
+	for _, c := range candidates {
+		if len(selected) == m {
+			break
+		}
+		diverse := true
+		for _, s := range selected {
+			if dot(idx.nodes[c.id].vector, idx.nodes[s].vector) > c.score {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c.id)
+		} else {
+			skipped = append(skipped, c.id)
+		}
+	}
+	for _, id := range skipped {
+		if len(selected) == m {
+			break
+		}
+		selected = append(selected, id)
+	}
+	return selected
+}
+
+// greedyDescend walks from entry down to layer stop, moving to the closest
+// neighbor on each layer, and returns the node reached.
+func (idx *Index) greedyDescend(q []float32, entry int32, from, stop int) int32 {
+	cur := entry
+	curScore := dot(q, idx.nodes[cur].vector)
+	for l := from; l >= stop; l-- {
+		for changed := true; changed; {
+			changed = false
+			for _, nb := range idx.nodes[cur].neighbors[l] {
+				if score := dot(q, idx.nodes[nb].vector); score > curScore {
+					cur, curScore = nb, score
+					changed = true
+				}
+			}
+		}
+	}
+	return cur
+}
+
+// searchLayer runs a best-first search on layer l and returns up to ef
+// candidates sorted best first.
+func (idx *Index) searchLayer(q []float32, entryPoints []int32, ef, l int) []candidate {
+	visited := make(map[int32]struct{}, ef*4)
+	frontier := &maxHeap{}
+	found := &minHeap{}
+
+	for _, ep := range entryPoints {
+		if _, ok := visited[ep]; ok {
+			continue
+		}
+		visited[ep] = struct{}{}
+		c := candidate{id: ep, score: dot(q, idx.nodes[ep].vector)}
+		heap.Push(frontier, c)
+		heap.Push(found, c)
+		if found.Len() > ef {
+			heap.Pop(found)
+		}
+	}
+
+	for frontier.Len() > 0 {
+		c := heap.Pop(frontier).(candidate)
+		if found.Len() >= ef && c.score < (*found)[0].score {
+			break
+		}
+		for _, nb := range idx.nodes[c.id].neighbors[l] {
+			if _, ok := visited[nb]; ok {
+				continue
+			}
+			visited[nb] = struct{}{}
+			score := dot(q, idx.nodes[nb].vector)
+			if found.Len() < ef || score > (*found)[0].score {
+				next := candidate{id: nb, score: score}
+				heap.Push(frontier, next)
+				heap.Push(found, next)
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	results := make([]candidate, found.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(found).(candidate)
+	}
+	return results
+}
+
+// maxNeighbors returns the neighbor list size for layer l.
+func (idx *Index) maxNeighbors(l int) int {
+	if l == 0 {
+		return idx.cfg.M * 2
+	}
+	return idx.cfg.M
+}
+
+// randomLevel draws the top layer for a new node.
+func (idx *Index) randomLevel() int {
+	return int(math.Floor(-math.Log(1-idx.rng.Float64()) * idx.levelMul))
+}
+
+// snapshot is the serialized form of an index.
+type snapshot struct {
+	Config   Config
+	Dims     int
+	Entry    int32
+	MaxLevel int
+	Nodes    []snapshotNode
+}
+
+type snapshotNode struct {
+	ID        string
+	Vector    []float32
+	Neighbors [][]int32
+	Deleted   bool
+}
+
+// Save writes the index to w.
+func (idx *Index) Save(w io.Writer) error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	s := snapshot{
+		Config:   idx.cfg,
+		Dims:     idx.dims,
+		Entry:    idx.entry,
+		MaxLevel: idx.maxLevel,
+		Nodes:    make([]snapshotNode, len(idx.nodes)),
+	}
+	for i, n := range idx.nodes {
+		s.Nodes[i] = snapshotNode{ID: n.id, Vector: n.vector, Neighbors: n.neighbors, Deleted: n.deleted}
+	}
+	if err := gob.NewEncoder(w).Encode(&s); err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	return nil
+}
+
+// Load reads an index written by Save.
+func Load(r io.Reader) (*Index, error) {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to decode index: %w", err)
+	}
+
+	idx := New(s.Config)
+	idx.dims = s.Dims
+	idx.entry = s.Entry
+	idx.maxLevel = s.MaxLevel
+	idx.nodes = make([]*node, len(s.Nodes))
+	for i, sn := range s.Nodes {
+		if len(sn.Vector) != s.Dims {
+			return nil, fmt.Errorf("vector for %s has dimension %d, index has %d", sn.ID, len(sn.Vector), s.Dims)
+		}
+		for _, layer := range sn.Neighbors {
+			for _, nb := range layer {
+				if nb < 0 || int(nb) >= len(s.Nodes) {
+					return nil, fmt.Errorf("neighbor %d of %s is out of range", nb, sn.ID)
+				}
+			}
+		}
+		idx.nodes[i] = &node{id: sn.ID, vector: sn.Vector, neighbors: sn.Neighbors, deleted: sn.Deleted}
+		if sn.Deleted {
+			idx.deleted++
+		} else {
+			idx.ids[sn.ID] = int32(i)
+		}
+	}
+	if len(idx.nodes) > 0 && (idx.entry < 0 || int(idx.entry) >= len(idx.nodes) || len(idx.nodes[idx.entry].neighbors) <= idx.maxLevel) {
+		return nil, fmt.Errorf("invalid entry point %d", idx.entry)
+	}
+	return idx, nil
+}
+
+// normalize returns vector scaled to unit length.
+func normalize(vector []float32) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	out := make([]float32, len(vector))
+	if norm == 0 {
+		return out
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i, v := range vector {
+		out[i] = v * scale
+	}
+	return out
+}
+
+// dot returns the dot product of two vectors of equal length.
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// candidate is a node and its similarity to the current query.
+type candidate struct {
+	id    int32
+	score float32
+}
+
+// maxHeap pops the most similar candidate first.
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].score > h[j].score }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// minHeap pops the least similar candidate first.
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,192 @@
+package hnsw
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomVectors(rng *rand.Rand, n, dims int) [][]float32 {
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		v := make([]float32, dims)
+		for j := range v {
+			v[j] = rng.Float32()*2 - 1
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
+
+func exactSearch(vectors [][]float32, query []float32, k int) []string {
+	q := normalize(query)
+	type scored struct {
+		id    string
+		score float32
+	}
+	all := make([]scored, len(vectors))
+	for i, v := range vectors {
+		all[i] = scored{id: fmt.Sprint(i), score: dot(q, normalize(v))}
+	}
+	sort.Slice(all, func(a, b int) bool { return all[a].score > all[b].score })
+	ids := make([]string, k)
+	for i := range ids {
+		ids[i] = all[i].id
+	}
+	return ids
+}
+
+func buildIndex(t *testing.T, vectors [][]float32) *Index {
+	t.Helper()
+	idx := New(DefaultConfig())
+	for i, v := range vectors {
+		if err := idx.Add(fmt.Sprint(i), v); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	return idx
+}
+
+func TestIndexRecall(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+	vectors := randomVectors(rng, 2000, 32)
+	idx := buildIndex(t, vectors)
+
+	const k = 10
+	queries := randomVectors(rng, 50, 32)
+	hits := 0
+	for _, q := range queries {
+		expected := make(map[string]bool, k)
+		for _, id := range exactSearch(vectors, q, k) {
+			expected[id] = true
+		}
+		for _, r := range idx.Search(q, k, 0) {
+			if expected[r.ID] {
+				hits++
+			}
+		}
+	}
+
+	if recall := float64(hits) / float64(k*len(queries)); recall < 0.9 {
+		t.Errorf("recall = %.2f, want at least 0.90", recall)
+	}
+}
+
+func TestIndexAddRemove(t *testing.T) {
+	t.Parallel()
+
+	idx := New(Config{})
+	if err := idx.Add("a", []float32{1, 0}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := idx.Add("b", []float32{0, 1}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := idx.Add("c", []float32{1, 0, 0}); err == nil {
+		t.Error("Add should reject a vector of the wrong dimension")
+	}
+
+	results := idx.Search([]float32{0.9, 0.1}, 1, 0)
+	if len(results) != 1 || results[0].ID != "a" {
+		t.Fatalf("Search = %v, want a", results)
+	}
+
+	if err := idx.Add("a", []float32{0, -1}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if idx.Len() != 2 {
+		t.Errorf("Len after replacing a vector = %d, want 2", idx.Len())
+	}
+	if results := idx.Search([]float32{0.9, 0.1}, 1, 0); results[0].ID != "b" {
+		t.Errorf("Search after replacing a = %v, want b", results)
+	}
+
+	if !idx.Remove("b") {
+		t.Error("Remove should report b as present")
+	}
+	if idx.Remove("b") {
+		t.Error("Remove should report b as absent the second time")
+	}
+	for _, r := range idx.Search([]float32{0, 1}, 2, 0) {
+		if r.ID == "b" {
+			t.Error("Search returned a removed vector")
+		}
+	}
+
+	idx.Remove("a")
+	if idx.Len() != 0 || idx.Dimensions() != 0 {
+		t.Errorf("empty index has Len %d and Dimensions %d, want 0 and 0", idx.Len(), idx.Dimensions())
+	}
+}
+
+func TestIndexCompaction(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(2))
+	vectors := randomVectors(rng, 500, 16)
+	idx := buildIndex(t, vectors)
+
+	for i := 0; i < 400; i++ {
+		idx.Remove(fmt.Sprint(i))
+	}
+	if idx.Len() != 100 {
+		t.Fatalf("Len = %d, want 100", idx.Len())
+	}
+
+	for _, r := range idx.Search(vectors[450], 5, 0) {
+		var id int
+		fmt.Sscan(r.ID, &id)
+		if id < 400 {
+			t.Errorf("Search returned removed vector %s", r.ID)
+		}
+	}
+	if results := idx.Search(vectors[450], 1, 0); len(results) != 1 || results[0].ID != "450" {
+		t.Errorf("Search for a stored vector = %v, want 450", results)
+	}
+}
+
+func TestIndexSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(3))
+	vectors := randomVectors(rng, 300, 8)
+	idx := buildIndex(t, vectors)
+	idx.Remove("7")
+
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.Len() != idx.Len() || loaded.Dimensions() != idx.Dimensions() {
+		t.Errorf("loaded index has Len %d and Dimensions %d, want %d and %d",
+			loaded.Len(), loaded.Dimensions(), idx.Len(), idx.Dimensions())
+	}
+	if loaded.Contains("7") {
+		t.Error("loaded index should not contain a removed vector")
+	}
+
+	query := vectors[42]
+	want := idx.Search(query, 5, 50)
+	got := loaded.Search(query, 5, 50)
+	if len(got) != len(want) {
+		t.Fatalf("loaded Search returned %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID {
+			t.Errorf("loaded Search result %d = %s, want %s", i, got[i].ID, want[i].ID)
+		}
+	}
+
+	if _, err := Load(bytes.NewReader([]byte("not an index"))); err == nil {
+		t.Error("Load should fail on invalid input")
+	}
+}
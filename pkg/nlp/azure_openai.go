@@ -185,3 +185,12 @@ func (a *AzureOpenAIClient) ChatWithStructuredOutput(ctx context.Context, messag
 	// AzureOpenAIClient.Chat now returns *types.Response
 	return resp, nil
 }
+
+// Close releases any resources held by the client.
+// For AzureOpenAIClient, this closes the underlying HTTP client's idle connections.
+func (a *AzureOpenAIClient) Close() error {
+	if a.httpClient != nil {
+		a.httpClient.CloseIdleConnections()
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/sony/gobreaker"
 	"github.com/soundprediction/predicato/pkg/alert"
 	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/telemetry"
 	"github.com/soundprediction/predicato/pkg/types"
 )
 
@@ -38,6 +39,7 @@ func NewCircuitBreakerClient(client Client, cfg config.CircuitBreakerConfig, ale
 			return counts.Requests >= 3 && failureRatio >= cfg.ReadyToTripRatio
 		},
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			telemetry.SetCircuitBreakerState(name, int(to))
 			if to == gobreaker.StateOpen {
 				// Trip! Alert!
 				msg := fmt.Sprintf("Circuit Breaker '%s' changed status from %s to %s. Too many failures detected.", name, from, to)
@@ -49,6 +51,8 @@ func NewCircuitBreakerClient(client Client, cfg config.CircuitBreakerConfig, ale
 		},
 	}
 
+	telemetry.SetCircuitBreakerState(name, telemetry.CircuitClosed)
+
 	return &CircuitBreakerClient{
 		client:  client,
 		cb:      gobreaker.NewCircuitBreaker(st),
@@ -90,3 +94,46 @@ func (c *CircuitBreakerClient) Close() error {
 func (c *CircuitBreakerClient) GetCapabilities() []TaskCapability {
 	return c.client.GetCapabilities()
 }
+
+// IsLocalFor implements LocalityReporter for the wrapped client.
+func (c *CircuitBreakerClient) IsLocalFor(usage string) bool {
+	return IsLocalFor(c.client, usage)
+}
+
+// ModelID implements ModelIdentifier for the wrapped client.
+func (c *CircuitBreakerClient) ModelID() string {
+	return ModelID(c.client)
+}
+
+// ExtractTypedEntities implements EntityExtractor for the wrapped client.
+// Requests count towards the breaker like Chat; clients without typed
+// extraction report ErrExtractionUnsupported without touching it.
+func (c *CircuitBreakerClient) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	extractor, ok := c.client.(EntityExtractor)
+	if !ok {
+		return nil, ErrExtractionUnsupported
+	}
+	entities, err := c.cb.Execute(func() (interface{}, error) {
+		return extractor.ExtractTypedEntities(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entities.([]ExtractedEntity), nil
+}
+
+// ExtractTypedRelations implements RelationExtractor for the wrapped client.
+// Requests count towards the breaker like Chat.
+func (c *CircuitBreakerClient) ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) ([]ExtractedRelation, error) {
+	extractor, ok := c.client.(RelationExtractor)
+	if !ok {
+		return nil, ErrExtractionUnsupported
+	}
+	relations, err := c.cb.Execute(func() (interface{}, error) {
+		return extractor.ExtractTypedRelations(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return relations.([]ExtractedRelation), nil
+}
@@ -0,0 +1,45 @@
+package nlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/soundprediction/predicato/pkg/config"
+)
+
+func TestCircuitBreakerClient_TypedExtractionTripsBreaker(t *testing.T) {
+	mock := &flakyExtractor{mockClient{failUntilCall: 100, errorToReturn: errors.New("503 service unavailable")}}
+	client := NewCircuitBreakerClient(mock, config.CircuitBreakerConfig{
+		Enabled:          true,
+		MaxRequests:      1,
+		Interval:         60,
+		Timeout:          60,
+		ReadyToTripRatio: 0.5,
+	}, nil, "test-extraction")
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := client.ExtractTypedEntities(ctx, &EntityExtractionRequest{Text: "Alice"}); err == nil {
+			t.Fatal("expected the extractor's error")
+		}
+	}
+	if _, err := client.ExtractTypedRelations(ctx, &RelationExtractionRequest{Text: "Alice"}); !errors.Is(err, gobreaker.ErrOpenState) {
+		t.Errorf("expected the open breaker to reject the request, got %v", err)
+	}
+	if mock.callCount != 3 {
+		t.Errorf("expected 3 calls to reach the extractor, got %d", mock.callCount)
+	}
+
+	// A client without typed extraction does not count as a failure
+	plain := NewCircuitBreakerClient(&mockClient{}, config.CircuitBreakerConfig{MaxRequests: 1, ReadyToTripRatio: 0.5}, nil, "test-plain")
+	for i := 0; i < 3; i++ {
+		if _, err := plain.ExtractTypedEntities(ctx, &EntityExtractionRequest{}); !errors.Is(err, ErrExtractionUnsupported) {
+			t.Fatalf("expected ErrExtractionUnsupported, got %v", err)
+		}
+	}
+	if plain.cb.State() != gobreaker.StateClosed {
+		t.Error("unsupported extraction should not trip the breaker")
+	}
+}
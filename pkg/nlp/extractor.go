@@ -0,0 +1,115 @@
+package nlp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// ErrExtractionUnsupported is returned by a typed extractor that cannot serve
+// a request, e.g. a decorator around a client that only supports Chat. Callers
+// then fall back to prompt-based extraction.
+var ErrExtractionUnsupported = errors.New("client does not support typed extraction")
+
+// EntityType is a label extracted entities are classified as.
+type EntityType struct {
+	Name        string
+	Description string
+}
+
+// EntityExtractionRequest is the input of an EntityExtractor.
+type EntityExtractionRequest struct {
+	Text        string
+	EntityTypes []EntityType
+
+	// The fields below give prompt-based extractors more context; span
+	// extractors such as GLiNER ignore them.
+	SourceType       string // "message", "text" or "json"
+	PreviousEpisodes []string
+	ReferenceTime    time.Time
+	Instructions     string
+}
+
+// ExtractedEntity is an entity mention found by an EntityExtractor.
+type ExtractedEntity struct {
+	Name string
+	// Type is the name of one of the requested entity types, or empty if the
+	// entity could not be classified.
+	Type string
+	// Span is where the entity was found in the text, or nil if unknown.
+	Span *types.Span
+	// Score is the extractor's confidence in [0, 1], or 0 if not reported.
+	Score float64
+}
+
+// EntityExtractor extracts typed entities from text.
+type EntityExtractor interface {
+	ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error)
+}
+
+// RelationExtractionRequest is the input of a RelationExtractor.
+type RelationExtractionRequest struct {
+	Text string
+	// Entities are the candidate relation arguments.
+	Entities []ExtractedEntity
+	// EdgeTypeMap maps each relation type to the [source type, target type]
+	// pairs it may connect. A type without pairs may connect any entities.
+	EdgeTypeMap map[string][][]string
+
+	// As in EntityExtractionRequest, for prompt-based extractors.
+	PreviousEpisodes []string
+	ReferenceTime    time.Time
+	Instructions     string
+}
+
+// ExtractedRelation is a relation found by a RelationExtractor.
+type ExtractedRelation struct {
+	// Source and Target index RelationExtractionRequest.Entities.
+	Source int
+	Target int
+	Type   string
+	Fact   string
+	// ValidAt and InvalidAt are set when the text dates the relation.
+	ValidAt   *time.Time
+	InvalidAt *time.Time
+	// Span is where the relation was stated in the text, or nil if unknown.
+	Span *types.Span
+	// Score is the extractor's confidence in [0, 1], or 0 if not reported.
+	Score float64
+}
+
+// RelationExtractor extracts typed relations between known entities.
+type RelationExtractor interface {
+	ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) ([]ExtractedRelation, error)
+}
+
+// ExtractTypedEntities runs client as an EntityExtractor, or returns
+// ErrExtractionUnsupported if it is not one.
+func ExtractTypedEntities(ctx context.Context, client interface{}, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	if extractor, ok := client.(EntityExtractor); ok {
+		return extractor.ExtractTypedEntities(ctx, req)
+	}
+	return nil, ErrExtractionUnsupported
+}
+
+// ExtractTypedRelations runs client as a RelationExtractor, or returns
+// ErrExtractionUnsupported if it is not one.
+func ExtractTypedRelations(ctx context.Context, client interface{}, req *RelationExtractionRequest) ([]ExtractedRelation, error) {
+	if extractor, ok := client.(RelationExtractor); ok {
+		return extractor.ExtractTypedRelations(ctx, req)
+	}
+	return nil, ErrExtractionUnsupported
+}
+
+// FindEntity returns the index of the entity named name, ignoring case, or -1.
+func FindEntity(entities []ExtractedEntity, name string) int {
+	for i, entity := range entities {
+		if strings.EqualFold(entity.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,86 @@
+package nlp
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// LocalityClient wraps a Client with a locality recorded from its
+// configuration, so that strict local-only routing does not depend on the
+// name a model was registered under.
+type LocalityClient struct {
+	client Client
+	local  bool
+}
+
+// NewLocalityClient wraps client and reports it as local when local is true.
+func NewLocalityClient(client Client, local bool) *LocalityClient {
+	return &LocalityClient{client: client, local: local}
+}
+
+// Chat implements Client
+func (c *LocalityClient) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
+	return c.client.Chat(ctx, messages)
+}
+
+// ChatWithStructuredOutput implements Client
+func (c *LocalityClient) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
+	return c.client.ChatWithStructuredOutput(ctx, messages, schema)
+}
+
+// Close implements Client
+func (c *LocalityClient) Close() error {
+	return c.client.Close()
+}
+
+// GetCapabilities returns the list of capabilities supported by this client.
+func (c *LocalityClient) GetCapabilities() []TaskCapability {
+	return c.client.GetCapabilities()
+}
+
+// IsLocalFor implements LocalityReporter with the recorded locality. The
+// usage tag does not matter because the wrapped client serves every usage.
+func (c *LocalityClient) IsLocalFor(usage string) bool {
+	return c.local
+}
+
+// ModelID implements ModelIdentifier for the wrapped client.
+func (c *LocalityClient) ModelID() string {
+	return ModelID(c.client)
+}
+
+// ExtractTypedEntities implements EntityExtractor for the wrapped client.
+func (c *LocalityClient) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	return ExtractTypedEntities(ctx, c.client, req)
+}
+
+// ExtractTypedRelations implements RelationExtractor for the wrapped client.
+func (c *LocalityClient) ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) ([]ExtractedRelation, error) {
+	return ExtractTypedRelations(ctx, c.client, req)
+}
+
+// IsLocalEndpoint reports whether baseURL points at the local machine: a
+// loopback address or the host name localhost. An empty or unparsable URL
+// is not local.
+func IsLocalEndpoint(baseURL string) bool {
+	if baseURL == "" {
+		return false
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "" {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
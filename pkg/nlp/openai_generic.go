@@ -287,3 +287,11 @@ func (c *OpenAIGenericClient) GetClient() *openai.Client {
 func (c *OpenAIGenericClient) GetConfig() *LLMConfig {
 	return c.config
 }
+
+// ModelID implements ModelIdentifier.
+func (c *OpenAIGenericClient) ModelID() string {
+	if c.config == nil {
+		return ""
+	}
+	return c.config.Model
+}
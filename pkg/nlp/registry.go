@@ -1,6 +1,9 @@
 package nlp
 
-import "slices"
+import (
+	"fmt"
+	"slices"
+)
 
 // TaskCapability represents a specific NLP task that a model can perform.
 type TaskCapability string
@@ -221,6 +224,47 @@ func GetProvider(id ProviderID) (Provider, bool) {
 	return p, ok
 }
 
+// IsLocalProvider reports whether the built-in provider with the given ID runs locally.
+func IsLocalProvider(id ProviderID) bool {
+	p, ok := BuiltInProviders[id]
+	return ok && p.IsLocal
+}
+
+// LocalityReporter is implemented by clients that know whether requests with
+// a usage tag stay on a local provider.
+type LocalityReporter interface {
+	IsLocalFor(usage string) bool
+}
+
+// IsLocalFor reports whether client, an NLP or embedder client, keeps
+// requests tagged with usage on a local provider. Clients that do not
+// implement LocalityReporter are assumed to be remote.
+func IsLocalFor(client interface{}, usage string) bool {
+	reporter, ok := client.(LocalityReporter)
+	return ok && reporter.IsLocalFor(usage)
+}
+
+// ModelIdentifier is implemented by clients that can name the model they
+// call, so that extraction runs can record it.
+type ModelIdentifier interface {
+	ModelID() string
+}
+
+// ModelID returns the model client calls, or its Go type if it does not
+// implement ModelIdentifier or does not know its model. It returns "" for a
+// nil client.
+func ModelID(client interface{}) string {
+	if client == nil {
+		return ""
+	}
+	if identifier, ok := client.(ModelIdentifier); ok {
+		if id := identifier.ModelID(); id != "" {
+			return id
+		}
+	}
+	return fmt.Sprintf("%T", client)
+}
+
 // GetModel returns the model with the given ID.
 func GetModel(id string) (Model, bool) {
 	for _, m := range BuiltInModels {
@@ -86,45 +86,21 @@ func MustNewRetryClient(client Client, config *RetryConfig) *RetryClient {
 
 // Chat implements the Client interface with retry logic
 func (r *RetryClient) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= r.config.MaxRetries; attempt++ {
-		// If this is a retry, wait with exponential backoff
-		if attempt > 0 {
-			delay := r.calculateDelay(attempt)
-			select {
-			case <-time.After(delay):
-				// Continue with retry
-			case <-ctx.Done():
-				return nil, fmt.Errorf("context cancelled during retry backoff: %w", ctx.Err())
-			}
-		}
-
-		// Make the LLM call
-		resp, err := r.client.Chat(ctx, messages)
-		if err == nil {
-			return resp, nil
-		}
-
-		// Store the error
-		lastErr = err
-
-		// Check if the error is retryable
-		if !isRetryableError(err) {
-			// Non-retryable error, fail immediately
-			return nil, err
-		}
-
-		// Log retry attempt (in production, this should use a logger)
-		// For now, we just continue to retry
-	}
-
-	// All retries exhausted
-	return nil, fmt.Errorf("failed after %d retries: %w", r.config.MaxRetries, lastErr)
+	return retryCall(ctx, r, func() (*types.Response, error) {
+		return r.client.Chat(ctx, messages)
+	})
 }
 
 // ChatWithStructuredOutput implements the Client interface with retry logic
 func (r *RetryClient) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
+	return retryCall(ctx, r, func() (*types.Response, error) {
+		return r.client.ChatWithStructuredOutput(ctx, messages, schema)
+	})
+}
+
+// retryCall makes call, retrying retryable errors with exponential backoff.
+func retryCall[T any](ctx context.Context, r *RetryClient, call func() (T, error)) (T, error) {
+	var zero T
 	var lastErr error
 
 	for attempt := 0; attempt <= r.config.MaxRetries; attempt++ {
@@ -135,12 +111,12 @@ func (r *RetryClient) ChatWithStructuredOutput(ctx context.Context, messages []t
 			case <-time.After(delay):
 				// Continue with retry
 			case <-ctx.Done():
-				return nil, fmt.Errorf("context cancelled during retry backoff: %w", ctx.Err())
+				return zero, fmt.Errorf("context cancelled during retry backoff: %w", ctx.Err())
 			}
 		}
 
 		// Make the LLM call
-		result, err := r.client.ChatWithStructuredOutput(ctx, messages, schema)
+		result, err := call()
 		if err == nil {
 			return result, nil
 		}
@@ -151,15 +127,12 @@ func (r *RetryClient) ChatWithStructuredOutput(ctx context.Context, messages []t
 		// Check if the error is retryable
 		if !isRetryableError(err) {
 			// Non-retryable error, fail immediately
-			return nil, err
+			return zero, err
 		}
-
-		// Log retry attempt (in production, this should use a logger)
-		// For now, we just continue to retry
 	}
 
 	// All retries exhausted
-	return nil, fmt.Errorf("failed after %d retries: %w", r.config.MaxRetries, lastErr)
+	return zero, fmt.Errorf("failed after %d retries: %w", r.config.MaxRetries, lastErr)
 }
 
 // Close implements the Client interface
@@ -172,6 +145,32 @@ func (r *RetryClient) GetCapabilities() []TaskCapability {
 	return r.client.GetCapabilities()
 }
 
+// IsLocalFor implements LocalityReporter for the wrapped client.
+func (r *RetryClient) IsLocalFor(usage string) bool {
+	return IsLocalFor(r.client, usage)
+}
+
+// ModelID implements ModelIdentifier for the wrapped client.
+func (r *RetryClient) ModelID() string {
+	return ModelID(r.client)
+}
+
+// ExtractTypedEntities implements EntityExtractor for the wrapped client,
+// retrying like Chat.
+func (r *RetryClient) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	return retryCall(ctx, r, func() ([]ExtractedEntity, error) {
+		return ExtractTypedEntities(ctx, r.client, req)
+	})
+}
+
+// ExtractTypedRelations implements RelationExtractor for the wrapped client,
+// retrying like Chat.
+func (r *RetryClient) ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) ([]ExtractedRelation, error) {
+	return retryCall(ctx, r, func() ([]ExtractedRelation, error) {
+		return ExtractTypedRelations(ctx, r.client, req)
+	})
+}
+
 // calculateDelay calculates the delay for a given retry attempt using exponential backoff with jitter
 func (r *RetryClient) calculateDelay(attempt int) time.Duration {
 	// Calculate exponential backoff: InitialDelay * (BackoffMultiplier ^ (attempt - 1))
@@ -403,3 +403,59 @@ func TestIsRetryableError_HTTPStatusCode(t *testing.T) {
 		})
 	}
 }
+
+// flakyExtractor is a mockClient that also extracts typed entities and relations.
+type flakyExtractor struct {
+	mockClient
+}
+
+func (m *flakyExtractor) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	m.callCount++
+	if m.callCount <= m.failUntilCall {
+		return nil, m.errorToReturn
+	}
+	return []ExtractedEntity{{Name: "Alice", Type: "Person"}}, nil
+}
+
+func (m *flakyExtractor) ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) ([]ExtractedRelation, error) {
+	m.callCount++
+	if m.callCount <= m.failUntilCall {
+		return nil, m.errorToReturn
+	}
+	return []ExtractedRelation{{Source: 0, Target: 1, Type: "KNOWS"}}, nil
+}
+
+func TestRetryClient_TypedExtraction(t *testing.T) {
+	config := &RetryConfig{
+		MaxRetries:        3,
+		InitialDelay:      time.Millisecond,
+		MaxDelay:          10 * time.Millisecond,
+		BackoffMultiplier: 2.0,
+	}
+
+	entityMock := &flakyExtractor{mockClient{failUntilCall: 2, errorToReturn: errors.New("503 service unavailable")}}
+	retryClient, _ := NewRetryClient(entityMock, config)
+	entities, err := retryClient.ExtractTypedEntities(context.Background(), &EntityExtractionRequest{Text: "Alice"})
+	if err != nil || len(entities) != 1 {
+		t.Fatalf("ExtractTypedEntities = %v, %v", entities, err)
+	}
+	if entityMock.callCount != 3 {
+		t.Errorf("expected 3 entity calls, got %d", entityMock.callCount)
+	}
+
+	relationMock := &flakyExtractor{mockClient{failUntilCall: 1, errorToReturn: errors.New("429 too many requests")}}
+	retryClient, _ = NewRetryClient(relationMock, config)
+	relations, err := retryClient.ExtractTypedRelations(context.Background(), &RelationExtractionRequest{Text: "Alice knows Bob"})
+	if err != nil || len(relations) != 1 {
+		t.Fatalf("ExtractTypedRelations = %v, %v", relations, err)
+	}
+	if relationMock.callCount != 2 {
+		t.Errorf("expected 2 relation calls, got %d", relationMock.callCount)
+	}
+
+	// Clients without typed extraction are not retried
+	retryClient, _ = NewRetryClient(&mockClient{}, config)
+	if _, err := retryClient.ExtractTypedEntities(context.Background(), &EntityExtractionRequest{}); !errors.Is(err, ErrExtractionUnsupported) {
+		t.Errorf("expected ErrExtractionUnsupported, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package nlp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -14,6 +15,7 @@ type RouterClient struct {
 	providers     map[string]Client
 	rules         []config.RouterRule
 	defaultClient Client
+	defaultName   string
 }
 
 // NewRouterClient creates a new router client
@@ -24,12 +26,13 @@ func NewRouterClient(providers map[string]Client, rules []config.RouterRule) (*R
 
 	// Determine default client (first one or specific "default" key)
 	var defaultClient Client
+	defaultName := "default"
 	if client, ok := providers["default"]; ok {
 		defaultClient = client
 	} else {
 		// Pick any
-		for _, client := range providers {
-			defaultClient = client
+		for name, client := range providers {
+			defaultClient, defaultName = client, name
 			break
 		}
 	}
@@ -38,14 +41,21 @@ func NewRouterClient(providers map[string]Client, rules []config.RouterRule) (*R
 		providers:     providers,
 		rules:         rules,
 		defaultClient: defaultClient,
+		defaultName:   defaultName,
 	}, nil
 }
 
 // getClientForContext determines which client to use based on context
 func (r *RouterClient) getClientForContext(ctx context.Context) (Client, string, Client) {
-	usage, ok := ctx.Value(types.ContextKeyUsage).(string)
-	if !ok || usage == "" {
-		return r.defaultClient, "default", nil
+	usage, _ := ctx.Value(types.ContextKeyUsage).(string)
+	primary, name, fallback, _ := r.route(usage)
+	return primary, name, fallback
+}
+
+// route returns the primary and fallback providers, and their names, for a usage tag
+func (r *RouterClient) route(usage string) (Client, string, Client, string) {
+	if usage == "" {
+		return r.defaultClient, r.defaultName, nil, ""
 	}
 
 	// Find matching rule
@@ -57,12 +67,56 @@ func (r *RouterClient) getClientForContext(ctx context.Context) (Client, string,
 				if rule.Fallback != "" {
 					fallback = r.providers[rule.Fallback]
 				}
-				return primary, rule.Provider, fallback
+				return primary, rule.Provider, fallback, rule.Fallback
 			}
 		}
 	}
 
-	return r.defaultClient, "default", nil
+	return r.defaultClient, r.defaultName, nil, ""
+}
+
+// IsLocalFor reports whether requests tagged with usage, including any
+// fallback, are routed to local providers. A provider is local only when its
+// client reports so; the name it is registered under is chosen by the user
+// and says nothing about where requests go.
+func (r *RouterClient) IsLocalFor(usage string) bool {
+	primary, _, fallback, _ := r.route(usage)
+	if !IsLocalFor(primary, usage) {
+		return false
+	}
+	return fallback == nil || IsLocalFor(fallback, usage)
+}
+
+// ModelID implements ModelIdentifier for the default provider, which serves
+// requests without a usage tag.
+func (r *RouterClient) ModelID() string {
+	return ModelID(r.defaultClient)
+}
+
+// ExtractTypedEntities implements EntityExtractor with routing and fallback.
+// It returns ErrExtractionUnsupported if the routed client is not an
+// EntityExtractor, so that the caller falls back to Chat.
+func (r *RouterClient) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	primary, _, fallback := r.getClientForContext(ctx)
+
+	entities, err := ExtractTypedEntities(ctx, primary, req)
+	if err != nil && fallback != nil && !errors.Is(err, ErrExtractionUnsupported) {
+		return ExtractTypedEntities(ctx, fallback, req)
+	}
+	return entities, err
+}
+
+// ExtractTypedRelations implements RelationExtractor with routing and fallback.
+// It returns ErrExtractionUnsupported if the routed client is not a
+// RelationExtractor, so that the caller falls back to Chat.
+func (r *RouterClient) ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) ([]ExtractedRelation, error) {
+	primary, _, fallback := r.getClientForContext(ctx)
+
+	relations, err := ExtractTypedRelations(ctx, primary, req)
+	if err != nil && fallback != nil && !errors.Is(err, ErrExtractionUnsupported) {
+		return ExtractTypedRelations(ctx, fallback, req)
+	}
+	return relations, err
 }
 
 // Chat implements Client with routing and fallback
@@ -0,0 +1,148 @@
+package nlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+func TestRouterClient_RoutesOnUsage(t *testing.T) {
+	remote := &mockClient{responseToReturn: &types.Response{Content: "remote"}}
+	local := &mockClient{responseToReturn: &types.Response{Content: "local"}}
+	router, err := NewRouterClient(map[string]Client{
+		"default":              remote,
+		string(ProviderGLiNER): local,
+	}, []config.RouterRule{{Usage: "hipaa", Provider: string(ProviderGLiNER)}})
+	if err != nil {
+		t.Fatalf("NewRouterClient: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), types.ContextKeyUsage, "HIPAA")
+	resp, err := router.Chat(ctx, nil)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "local" {
+		t.Errorf("hipaa request routed to %q, want local", resp.Content)
+	}
+	resp, err = router.Chat(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "remote" {
+		t.Errorf("untagged request routed to %q, want remote", resp.Content)
+	}
+}
+
+func TestRouterClient_IsLocalFor(t *testing.T) {
+	router, err := NewRouterClient(map[string]Client{
+		"default":   &mockClient{},
+		"ner":       NewLocalityClient(&mockClient{}, true),
+		"summaries": NewLocalityClient(&mockClient{}, true),
+		"claude":    NewLocalityClient(&mockClient{}, false),
+		// A local provider's name does not make a remote client local
+		string(ProviderRustBert): &mockClient{},
+	}, []config.RouterRule{
+		{Usage: "hipaa", Provider: "ner", Fallback: "summaries"},
+		{Usage: "pci", Provider: "ner", Fallback: "claude"},
+		{Usage: "phi", Provider: string(ProviderRustBert)},
+	})
+	if err != nil {
+		t.Fatalf("NewRouterClient: %v", err)
+	}
+
+	tests := []struct {
+		usage string
+		want  bool
+	}{
+		{"hipaa", true},
+		{"pci", false}, // the fallback is remote
+		{"phi", false}, // named like a local provider, but unrecorded
+		{"other", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := router.IsLocalFor(tt.usage); got != tt.want {
+			t.Errorf("IsLocalFor(%q) = %v, want %v", tt.usage, got, tt.want)
+		}
+	}
+
+	// Wrappers report the locality of the client they wrap
+	retry, err := NewRetryClient(router, nil)
+	if err != nil {
+		t.Fatalf("NewRetryClient: %v", err)
+	}
+	if !IsLocalFor(retry, "hipaa") || IsLocalFor(retry, "pci") {
+		t.Error("RetryClient does not report the locality of the wrapped router")
+	}
+	if IsLocalFor(&mockClient{}, "hipaa") {
+		t.Error("clients without a LocalityReporter must be treated as remote")
+	}
+}
+
+func TestIsLocalEndpoint(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    bool
+	}{
+		{"http://localhost:11434/v1", true},
+		{"http://LOCALHOST:8000", true},
+		{"http://ollama.localhost:11434", true},
+		{"http://127.0.0.1:8000", true},
+		{"http://[::1]:8000", true},
+		{"https://api.openai.com/v1", false},
+		{"http://localhost.example.com", false},
+		{"http://10.0.0.5:8000", false},
+		{"", false},
+		{"localhost:8000", false}, // no scheme, so no host
+	}
+	for _, tt := range tests {
+		if got := IsLocalEndpoint(tt.baseURL); got != tt.want {
+			t.Errorf("IsLocalEndpoint(%q) = %v, want %v", tt.baseURL, got, tt.want)
+		}
+	}
+}
+
+type mockExtractor struct {
+	mockClient
+	entities []ExtractedEntity
+}
+
+func (m *mockExtractor) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	return m.entities, nil
+}
+
+func TestRouterClient_ExtractTypedEntities(t *testing.T) {
+	local := &mockExtractor{entities: []ExtractedEntity{{Name: "Alice", Type: "Person"}}}
+	router, err := NewRouterClient(map[string]Client{
+		"default":              &mockClient{},
+		string(ProviderGLiNER): local,
+	}, []config.RouterRule{{Usage: "hipaa", Provider: string(ProviderGLiNER)}})
+	if err != nil {
+		t.Fatalf("NewRouterClient: %v", err)
+	}
+	retry, err := NewRetryClient(router, nil)
+	if err != nil {
+		t.Fatalf("NewRetryClient: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), types.ContextKeyUsage, "hipaa")
+	entities, err := ExtractTypedEntities(ctx, retry, &EntityExtractionRequest{Text: "Alice"})
+	if err != nil {
+		t.Fatalf("ExtractTypedEntities: %v", err)
+	}
+	if len(entities) != 1 || entities[0].Name != "Alice" {
+		t.Errorf("entities = %+v, want Alice from the routed extractor", entities)
+	}
+
+	// The default client only supports Chat
+	if _, err := ExtractTypedEntities(context.Background(), retry, &EntityExtractionRequest{Text: "Alice"}); !errors.Is(err, ErrExtractionUnsupported) {
+		t.Errorf("err = %v, want ErrExtractionUnsupported", err)
+	}
+	if _, err := ExtractTypedRelations(ctx, retry, &RelationExtractionRequest{Text: "Alice"}); !errors.Is(err, ErrExtractionUnsupported) {
+		t.Errorf("err = %v, want ErrExtractionUnsupported for relations", err)
+	}
+}
@@ -0,0 +1,188 @@
+package nlp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/soundprediction/predicato/pkg/telemetry"
+)
+
+// TokenUsageFilePrefix starts the names of the Parquet files written by
+// ParquetTokenTracker.
+const TokenUsageFilePrefix = "token_usage_"
+
+// Dimensions by which SummarizeTokenUsage and SummarizeLatency group records.
+const (
+	UsageByModel  = "model"
+	UsageByDay    = "day"
+	UsageByGroup  = "group"
+	UsageBySource = "source"
+)
+
+// UsageDimensions lists the supported grouping dimensions.
+var UsageDimensions = []string{UsageByModel, UsageByDay, UsageByGroup, UsageBySource}
+
+// ReadTokenUsage reads the token usage that ParquetTokenTracker wrote to dir
+// within window.
+func ReadTokenUsage(dir string, window telemetry.TimeWindow) ([]TokenUsageRecord, error) {
+	records, err := telemetry.ReadParquetFiles[TokenUsageRecord](dir, TokenUsageFilePrefix)
+	if err != nil {
+		return nil, err
+	}
+	filtered := records[:0]
+	for _, record := range records {
+		if window.Contains(record.Timestamp) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+// UsageKey identifies a group of token usage records. Only the dimensions
+// grouped by are set; Day is the UTC date.
+type UsageKey struct {
+	Model   string `json:"model,omitempty"`
+	Day     string `json:"day,omitempty"`
+	GroupID string `json:"group_id,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+// Value returns the value of the dimension dim.
+func (k UsageKey) Value(dim string) string {
+	switch dim {
+	case UsageByModel:
+		return k.Model
+	case UsageByDay:
+		return k.Day
+	case UsageByGroup:
+		return k.GroupID
+	case UsageBySource:
+		return k.Source
+	}
+	return ""
+}
+
+// TokenUsageSummary totals the token usage of a group of records.
+type TokenUsageSummary struct {
+	UsageKey
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+}
+
+// LatencySummary holds the request latency percentiles, in milliseconds, of a
+// group of records.
+type LatencySummary struct {
+	UsageKey
+	Requests int     `json:"requests"`
+	P50Ms    float64 `json:"p50_ms"`
+	P90Ms    float64 `json:"p90_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+	MaxMs    float64 `json:"max_ms"`
+}
+
+// SummarizeTokenUsage totals records grouped by the dimensions by, sorted by
+// their values in that order. With no dimensions everything is one group.
+func SummarizeTokenUsage(records []TokenUsageRecord, by ...string) ([]TokenUsageSummary, error) {
+	if err := validateUsageDimensions(by); err != nil {
+		return nil, err
+	}
+	groups := make(map[UsageKey]*TokenUsageSummary)
+	for _, record := range records {
+		key := usageKeyOf(&record, by)
+		summary, ok := groups[key]
+		if !ok {
+			summary = &TokenUsageSummary{UsageKey: key}
+			groups[key] = summary
+		}
+		summary.Requests++
+		summary.PromptTokens += record.PromptTokens
+		summary.CompletionTokens += record.CompletionTokens
+		summary.TotalTokens += record.TotalTokens
+		summary.EstimatedCost += record.EstimatedCost
+	}
+
+	summaries := make([]TokenUsageSummary, 0, len(groups))
+	for _, summary := range groups {
+		summaries = append(summaries, *summary)
+	}
+	slices.SortFunc(summaries, func(a, b TokenUsageSummary) int {
+		return compareUsageKeys(a.UsageKey, b.UsageKey, by)
+	})
+	return summaries, nil
+}
+
+// SummarizeLatency computes request latency percentiles of records grouped by
+// the dimensions by, sorted by their values in that order. Records without a
+// measured latency are skipped.
+func SummarizeLatency(records []TokenUsageRecord, by ...string) ([]LatencySummary, error) {
+	if err := validateUsageDimensions(by); err != nil {
+		return nil, err
+	}
+	groups := make(map[UsageKey][]float64)
+	for _, record := range records {
+		if record.LatencyMs <= 0 {
+			continue
+		}
+		key := usageKeyOf(&record, by)
+		groups[key] = append(groups[key], record.LatencyMs)
+	}
+
+	summaries := make([]LatencySummary, 0, len(groups))
+	for key, latencies := range groups {
+		p := telemetry.Percentiles(latencies, 50, 90, 95, 99, 100)
+		summaries = append(summaries, LatencySummary{
+			UsageKey: key,
+			Requests: len(latencies),
+			P50Ms:    p[0],
+			P90Ms:    p[1],
+			P95Ms:    p[2],
+			P99Ms:    p[3],
+			MaxMs:    p[4],
+		})
+	}
+	slices.SortFunc(summaries, func(a, b LatencySummary) int {
+		return compareUsageKeys(a.UsageKey, b.UsageKey, by)
+	})
+	return summaries, nil
+}
+
+func validateUsageDimensions(by []string) error {
+	for _, dim := range by {
+		if !slices.Contains(UsageDimensions, dim) {
+			return fmt.Errorf("unknown usage dimension %q, expected one of %v", dim, UsageDimensions)
+		}
+	}
+	return nil
+}
+
+// usageKeyOf returns the key of record for the dimensions by.
+func usageKeyOf(record *TokenUsageRecord, by []string) UsageKey {
+	var key UsageKey
+	for _, dim := range by {
+		switch dim {
+		case UsageByModel:
+			key.Model = record.Model
+		case UsageByDay:
+			key.Day = record.Timestamp.UTC().Format("2006-01-02")
+		case UsageByGroup:
+			key.GroupID = record.GroupID
+		case UsageBySource:
+			key.Source = record.RequestSource
+		}
+	}
+	return key
+}
+
+func compareUsageKeys(a, b UsageKey, by []string) int {
+	for _, dim := range by {
+		if c := strings.Compare(a.Value(dim), b.Value(dim)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package nlp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/soundprediction/predicato/pkg/telemetry"
+	"github.com/soundprediction/predicato/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTokenUsage(t *testing.T) {
+	dir := t.TempDir()
+
+	tracker, err := NewTokenTracker(dir)
+	require.NoError(t, err)
+	tracker.batchSize = 1
+
+	ctx := context.WithValue(context.Background(), types.ContextKeyGroupID, "group-a")
+	usage := &types.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	require.NoError(t, tracker.AddUsageWithLatency(ctx, usage, "model-a", 250*time.Millisecond))
+
+	// Files written before the group and latency columns existed still read
+	type legacyRecord struct {
+		ID          string    `parquet:"id"`
+		Timestamp   time.Time `parquet:"timestamp"`
+		Model       string    `parquet:"model"`
+		TotalTokens int       `parquet:"total_tokens"`
+	}
+	old := time.Now().UTC().Add(-48 * time.Hour)
+	require.NoError(t, parquet.WriteFile(filepath.Join(dir, TokenUsageFilePrefix+"legacy.parquet"), []legacyRecord{
+		{ID: "legacy", Timestamp: old, Model: "model-b", TotalTokens: 100},
+	}))
+
+	records, err := ReadTokenUsage(dir, telemetry.TimeWindow{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	records, err = ReadTokenUsage(dir, telemetry.TimeWindow{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "group-a", records[0].GroupID)
+	assert.Equal(t, 250.0, records[0].LatencyMs)
+
+	records, err = ReadTokenUsage(dir, telemetry.TimeWindow{Until: old.Add(time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "model-b", records[0].Model)
+	assert.Zero(t, records[0].LatencyMs)
+}
+
+func TestSummarizeTokenUsage(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	records := []TokenUsageRecord{
+		{Timestamp: day1, Model: "b", GroupID: "g1", TotalTokens: 10, EstimatedCost: 0.1, LatencyMs: 100},
+		{Timestamp: day1, Model: "a", GroupID: "g1", TotalTokens: 20, EstimatedCost: 0.2, LatencyMs: 300},
+		{Timestamp: day2, Model: "a", GroupID: "g2", TotalTokens: 30, EstimatedCost: 0.3, LatencyMs: 200},
+		{Timestamp: day2, Model: "a", GroupID: "g2", TotalTokens: 40, EstimatedCost: 0.4},
+	}
+
+	byModel, err := SummarizeTokenUsage(records, UsageByModel)
+	require.NoError(t, err)
+	require.Len(t, byModel, 2)
+	assert.Equal(t, "a", byModel[0].Model)
+	assert.Equal(t, 3, byModel[0].Requests)
+	assert.Equal(t, 90, byModel[0].TotalTokens)
+	assert.InDelta(t, 0.9, byModel[0].EstimatedCost, 1e-9)
+	assert.Empty(t, byModel[0].Day)
+
+	byDayGroup, err := SummarizeTokenUsage(records, UsageByDay, UsageByGroup)
+	require.NoError(t, err)
+	require.Len(t, byDayGroup, 2)
+	assert.Equal(t, UsageKey{Day: "2026-03-01", GroupID: "g1"}, byDayGroup[0].UsageKey)
+	assert.Equal(t, 30, byDayGroup[0].TotalTokens)
+
+	latency, err := SummarizeLatency(records, UsageByModel)
+	require.NoError(t, err)
+	require.Len(t, latency, 2)
+	assert.Equal(t, 2, latency[0].Requests)
+	assert.Equal(t, 200.0, latency[0].P50Ms)
+	assert.Equal(t, 300.0, latency[0].MaxMs)
+
+	_, err = SummarizeTokenUsage(records, "colour")
+	assert.Error(t, err)
+}
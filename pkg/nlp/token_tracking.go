@@ -28,6 +28,8 @@ type TokenUsageRecord struct {
 	RequestSource    string    `parquet:"request_source"`
 	IngestionSource  string    `parquet:"ingestion_source"`
 	IsSystemCall     bool      `parquet:"is_system_call"`
+	GroupID          string    `parquet:"group_id"`
+	LatencyMs        float64   `parquet:"latency_ms"` // 0 if not measured
 }
 
 // ParquetTokenTracker handles persistence of token usage stats to Parquet files
@@ -57,6 +59,11 @@ func NewTokenTracker(outputDir string) (*ParquetTokenTracker, error) {
 
 // AddUsage adds usage to the tracker
 func (t *ParquetTokenTracker) AddUsage(ctx context.Context, usage *types.TokenUsage, model string) error {
+	return t.AddUsageWithLatency(ctx, usage, model, 0)
+}
+
+// AddUsageWithLatency adds usage of a request that took latency to the tracker
+func (t *ParquetTokenTracker) AddUsageWithLatency(ctx context.Context, usage *types.TokenUsage, model string, latency time.Duration) error {
 	if usage == nil {
 		return nil
 	}
@@ -71,6 +78,7 @@ func (t *ParquetTokenTracker) AddUsage(ctx context.Context, usage *types.TokenUs
 		PromptTokens:     usage.PromptTokens,
 		CompletionTokens: usage.CompletionTokens,
 		EstimatedCost:    costUSD,
+		LatencyMs:        float64(latency) / float64(time.Millisecond),
 	}
 
 	// Extract context
@@ -89,6 +97,9 @@ func (t *ParquetTokenTracker) AddUsage(ctx context.Context, usage *types.TokenUs
 	if v, ok := ctx.Value(types.ContextKeySystemCall).(bool); ok {
 		record.IsSystemCall = v
 	}
+	if v, ok := ctx.Value(types.ContextKeyGroupID).(string); ok {
+		record.GroupID = v
+	}
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -109,7 +120,7 @@ func (t *ParquetTokenTracker) flush() error {
 		return nil
 	}
 
-	filename := fmt.Sprintf("token_usage_%s_%d.parquet", time.Now().Format("20060102_150405"), time.Now().UnixNano())
+	filename := fmt.Sprintf("%s%s_%d.parquet", TokenUsageFilePrefix, time.Now().Format("20060102_150405"), time.Now().UnixNano())
 	filepath := filepath.Join(t.outputDir, filename)
 
 	err := parquet.WriteFile(filepath, t.buffer)
@@ -139,6 +150,7 @@ func NewTokenTrackingClient(client Client, tracker *ParquetTokenTracker) *TokenT
 
 // Chat implements Client
 func (c *TokenTrackingClient) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
+	start := time.Now()
 	resp, err := c.client.Chat(ctx, messages)
 	if err != nil {
 		return nil, err
@@ -151,7 +163,7 @@ func (c *TokenTrackingClient) Chat(ctx context.Context, messages []types.Message
 			model = "unknown"
 		}
 
-		if err := c.tracker.AddUsage(ctx, resp.TokensUsed, model); err != nil {
+		if err := c.tracker.AddUsageWithLatency(ctx, resp.TokensUsed, model, time.Since(start)); err != nil {
 			fmt.Printf("Warning: Failed to log token usage: %v\n", err)
 		}
 	}
@@ -161,6 +173,7 @@ func (c *TokenTrackingClient) Chat(ctx context.Context, messages []types.Message
 
 // ChatWithStructuredOutput implements Client
 func (c *TokenTrackingClient) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
+	start := time.Now()
 	resp, err := c.client.ChatWithStructuredOutput(ctx, messages, schema)
 	if err != nil {
 		return nil, err
@@ -173,7 +186,7 @@ func (c *TokenTrackingClient) ChatWithStructuredOutput(ctx context.Context, mess
 			model = "unknown"
 		}
 
-		if err := c.tracker.AddUsage(ctx, resp.TokensUsed, model); err != nil {
+		if err := c.tracker.AddUsageWithLatency(ctx, resp.TokensUsed, model, time.Since(start)); err != nil {
 			fmt.Printf("Warning: Failed to log token usage: %v\n", err)
 		}
 	}
@@ -190,3 +203,23 @@ func (c *TokenTrackingClient) Close() error {
 func (c *TokenTrackingClient) GetCapabilities() []TaskCapability {
 	return c.client.GetCapabilities()
 }
+
+// IsLocalFor implements LocalityReporter for the wrapped client.
+func (c *TokenTrackingClient) IsLocalFor(usage string) bool {
+	return IsLocalFor(c.client, usage)
+}
+
+// ModelID implements ModelIdentifier for the wrapped client.
+func (c *TokenTrackingClient) ModelID() string {
+	return ModelID(c.client)
+}
+
+// ExtractTypedEntities implements EntityExtractor for the wrapped client.
+func (c *TokenTrackingClient) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) ([]ExtractedEntity, error) {
+	return ExtractTypedEntities(ctx, c.client, req)
+}
+
+// ExtractTypedRelations implements RelationExtractor for the wrapped client.
+func (c *TokenTrackingClient) ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) ([]ExtractedRelation, error) {
+	return ExtractTypedRelations(ctx, c.client, req)
+}
@@ -0,0 +1,126 @@
+package nlp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/telemetry"
+	"github.com/soundprediction/predicato/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingClient wraps a Client to record a span and latency and token
+// metrics for every request.
+type TracingClient struct {
+	client Client
+	model  string
+}
+
+// NewTracingClient creates a wrapper client. model labels the spans and
+// metrics until a response reports its own model.
+func NewTracingClient(client Client, model string) *TracingClient {
+	return &TracingClient{
+		client: client,
+		model:  model,
+	}
+}
+
+// Chat implements Client
+func (c *TracingClient) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
+	ctx, span := telemetry.StartSpan(ctx, "nlp.Chat", telemetry.AttrModel.String(c.model))
+	start := time.Now()
+	resp, err := c.client.Chat(ctx, messages)
+	c.observe(span, start, resp, err)
+	return resp, err
+}
+
+// ChatWithStructuredOutput implements Client
+func (c *TracingClient) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
+	ctx, span := telemetry.StartSpan(ctx, "nlp.ChatWithStructuredOutput", telemetry.AttrModel.String(c.model))
+	start := time.Now()
+	resp, err := c.client.ChatWithStructuredOutput(ctx, messages, schema)
+	c.observe(span, start, resp, err)
+	return resp, err
+}
+
+// observe ends span and records the request's latency and token usage.
+func (c *TracingClient) observe(span trace.Span, start time.Time, resp *types.Response, err error) {
+	model := c.model
+	var usage *types.TokenUsage
+	if resp != nil {
+		if resp.Model != "" {
+			model = resp.Model
+			span.SetAttributes(telemetry.AttrModel.String(model))
+		}
+		usage = resp.TokensUsed
+	}
+	if usage != nil {
+		span.SetAttributes(
+			telemetry.AttrPromptTokens.Int(usage.PromptTokens),
+			telemetry.AttrCompletionTokens.Int(usage.CompletionTokens),
+			telemetry.AttrTotalTokens.Int(usage.TotalTokens),
+		)
+	}
+	telemetry.ObserveLLMRequest(model, time.Since(start), usage, err)
+	telemetry.EndSpan(span, err)
+}
+
+// Close implements Client
+func (c *TracingClient) Close() error {
+	return c.client.Close()
+}
+
+// GetCapabilities returns the list of capabilities supported by this client.
+func (c *TracingClient) GetCapabilities() []TaskCapability {
+	return c.client.GetCapabilities()
+}
+
+// IsLocalFor implements LocalityReporter for the wrapped client.
+func (c *TracingClient) IsLocalFor(usage string) bool {
+	return IsLocalFor(c.client, usage)
+}
+
+// ModelID implements ModelIdentifier, preferring the model the wrapper was
+// created with.
+func (c *TracingClient) ModelID() string {
+	if c.model != "" {
+		return c.model
+	}
+	return ModelID(c.client)
+}
+
+// ExtractTypedEntities implements EntityExtractor for the wrapped client,
+// recording a span and the request latency like Chat.
+func (c *TracingClient) ExtractTypedEntities(ctx context.Context, req *EntityExtractionRequest) (entities []ExtractedEntity, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "nlp.ExtractTypedEntities", telemetry.AttrModel.String(c.model))
+	start := time.Now()
+	defer func() {
+		span.SetAttributes(telemetry.AttrCount.Int(len(entities)))
+		c.observeExtraction(span, start, err)
+	}()
+	return ExtractTypedEntities(ctx, c.client, req)
+}
+
+// ExtractTypedRelations implements RelationExtractor for the wrapped client,
+// recording a span and the request latency like Chat.
+func (c *TracingClient) ExtractTypedRelations(ctx context.Context, req *RelationExtractionRequest) (relations []ExtractedRelation, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "nlp.ExtractTypedRelations", telemetry.AttrModel.String(c.model))
+	start := time.Now()
+	defer func() {
+		span.SetAttributes(telemetry.AttrCount.Int(len(relations)))
+		c.observeExtraction(span, start, err)
+	}()
+	return ExtractTypedRelations(ctx, c.client, req)
+}
+
+// observeExtraction ends span and records the latency of a typed extraction.
+// Clients without typed extraction made no request, so nothing is recorded.
+func (c *TracingClient) observeExtraction(span trace.Span, start time.Time, err error) {
+	if errors.Is(err, ErrExtractionUnsupported) {
+		telemetry.EndSpan(span, nil)
+		return
+	}
+	telemetry.ObserveLLMRequest(c.model, time.Since(start), nil, err)
+	telemetry.EndSpan(span, err)
+}
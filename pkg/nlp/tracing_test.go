@@ -0,0 +1,61 @@
+package nlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/telemetry"
+	"github.com/soundprediction/predicato/pkg/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingClient_RecordsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	mock := &mockClient{
+		responseToReturn: &types.Response{
+			Content:    "ok",
+			Model:      "served-model",
+			TokensUsed: &types.TokenUsage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7},
+		},
+	}
+	client := NewTracingClient(mock, "configured-model")
+
+	if _, err := client.Chat(context.Background(), []types.Message{{Role: "user", Content: "hi"}}); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name() != "nlp.Chat" {
+		t.Errorf("expected span nlp.Chat, got %s", spans[0].Name())
+	}
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs[string(telemetry.AttrModel)] != "served-model" {
+		t.Errorf("expected model served-model, got %q", attrs[string(telemetry.AttrModel)])
+	}
+	if attrs[string(telemetry.AttrTotalTokens)] != "7" {
+		t.Errorf("expected 7 total tokens, got %q", attrs[string(telemetry.AttrTotalTokens)])
+	}
+
+	failing := NewTracingClient(&mockClient{failUntilCall: 1, errorToReturn: errors.New("boom")}, "configured-model")
+	if _, err := failing.Chat(context.Background(), nil); err == nil {
+		t.Fatal("expected error")
+	}
+	spans = recorder.Ended()
+	if len(spans) != 2 || spans[1].Status().Code != codes.Error {
+		t.Errorf("expected failed request to end with an error status")
+	}
+}
@@ -0,0 +1,87 @@
+package pii
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultGLiNERLabels are the entity labels GLiNERDetector asks for by default.
+var DefaultGLiNERLabels = []string{
+	"person",
+	"email address",
+	"phone number",
+	"street address",
+	"date of birth",
+	"medical condition",
+	"medication",
+	"health insurance number",
+	"passport number",
+}
+
+// Entity is a labelled span returned by a Recognizer.
+type Entity struct {
+	Text  string
+	Label string
+	Score float64
+	// Start and End are byte offsets of Text. When End is zero the offsets
+	// are unknown and every occurrence of Text is matched.
+	Start int
+	End   int
+}
+
+// Recognizer labels entities in text. The gliner and gliner2 clients
+// implement it.
+type Recognizer interface {
+	RecognizeEntities(ctx context.Context, text string, labels []string) ([]Entity, error)
+}
+
+// GLiNERDetector finds free-form PII, such as names and medical conditions,
+// with a GLiNER model.
+type GLiNERDetector struct {
+	recognizer Recognizer
+	labels     []string
+	threshold  float64
+}
+
+// NewGLiNERDetector creates a detector asking recognizer for labels, or
+// DefaultGLiNERLabels if none are given. Entities scoring below threshold are ignored.
+func NewGLiNERDetector(recognizer Recognizer, labels []string, threshold float64) *GLiNERDetector {
+	if len(labels) == 0 {
+		labels = DefaultGLiNERLabels
+	}
+	return &GLiNERDetector{recognizer: recognizer, labels: labels, threshold: threshold}
+}
+
+// Detect returns the entities the model labels as PII.
+func (d *GLiNERDetector) Detect(ctx context.Context, text string) ([]Match, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	entities, err := d.recognizer.RecognizeEntities(ctx, text, d.labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recognize entities: %w", err)
+	}
+
+	var matches []Match
+	for _, entity := range entities {
+		if entity.Score < d.threshold || strings.TrimSpace(entity.Text) == "" {
+			continue
+		}
+		label := labelFor(entity.Label)
+		if entity.End > entity.Start && entity.End <= len(text) && text[entity.Start:entity.End] == entity.Text {
+			matches = append(matches, Match{Start: entity.Start, End: entity.End, Label: label, Text: entity.Text})
+			continue
+		}
+		for offset := 0; ; {
+			i := strings.Index(text[offset:], entity.Text)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			matches = append(matches, Match{Start: start, End: start + len(entity.Text), Label: label, Text: entity.Text})
+			offset = start + len(entity.Text)
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,320 @@
+// Package pii detects personally identifiable information in episode text and
+// redacts it, or replaces it with stable pseudonyms, before the text reaches
+// any NLP model or store.
+//
+// Detection is pluggable: RegexDetector finds structured identifiers (emails,
+// phone numbers, card numbers, IBANs, national and health identifiers) and
+// validates them with checksums where one exists; GLiNERDetector finds
+// free-form PII such as names and medical conditions with a GLiNER model.
+//
+// Pseudonyms are derived from a secret key, so the same value always maps to
+// the same pseudonym and entity resolution keeps working across episodes. The
+// original values are kept in an encrypted Vault, from which holders of the
+// vault key can restore them.
+package pii
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// Match is a span of PII found in a text.
+type Match struct {
+	Start int
+	End   int
+	Label string
+	Text  string
+	// Normalized is the canonical form of Text used to derive its pseudonym,
+	// e.g. a phone number without separators. Empty means Text, lower-cased.
+	Normalized string
+}
+
+// Detector finds PII in text.
+type Detector interface {
+	Detect(ctx context.Context, text string) ([]Match, error)
+}
+
+// Mode decides how detected PII is replaced.
+type Mode string
+
+const (
+	// ModeRedact replaces PII with its label, e.g. "[EMAIL]". It is not reversible.
+	ModeRedact Mode = "redact"
+	// ModePseudonymize replaces PII with a stable pseudonym, e.g. "EMAIL_3f9a2c41d0",
+	// and stores the original value in the vault.
+	ModePseudonymize Mode = "pseudonymize"
+)
+
+// MetadataKeyReplacements is the episode metadata key recording how many PII
+// spans were replaced.
+const MetadataKeyReplacements = "pii_replacements"
+
+// pseudonymPattern matches the pseudonyms produced by ModePseudonymize.
+var pseudonymPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]*_[0-9a-f]{10}\b`)
+
+// Options configures a Redactor.
+type Options struct {
+	// Mode defaults to ModeRedact.
+	Mode Mode
+	// Key is the secret pseudonyms are derived from. Required for ModePseudonymize.
+	Key []byte
+	// Vault stores the original values of pseudonyms. Optional; without it
+	// pseudonyms are stable but cannot be restored.
+	Vault Vault
+}
+
+// Replacement describes one replaced span. The original value is not kept.
+type Replacement struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Label       string `json:"label"`
+	Replacement string `json:"replacement"`
+}
+
+// Redactor runs detectors over text and replaces what they find.
+type Redactor struct {
+	detectors []Detector
+	mode      Mode
+	key       []byte
+	vault     Vault
+}
+
+// NewRedactor creates a Redactor using the given detectors.
+func NewRedactor(detectors []Detector, opts *Options) (*Redactor, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeRedact
+	}
+	switch mode {
+	case ModeRedact:
+	case ModePseudonymize:
+		if len(opts.Key) == 0 {
+			return nil, fmt.Errorf("a key is required to pseudonymize")
+		}
+	default:
+		return nil, fmt.Errorf("unknown PII mode %q", mode)
+	}
+	return &Redactor{detectors: detectors, mode: mode, key: opts.Key, vault: opts.Vault}, nil
+}
+
+// Redact replaces the PII found in text.
+func (r *Redactor) Redact(ctx context.Context, text string) (string, []Replacement, error) {
+	var matches []Match
+	for _, detector := range r.detectors {
+		found, err := detector.Detect(ctx, text)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to detect PII: %w", err)
+		}
+		matches = append(matches, found...)
+	}
+	matches = resolveOverlaps(matches)
+	if len(matches) == 0 {
+		return text, nil, nil
+	}
+
+	var b strings.Builder
+	replacements := make([]Replacement, 0, len(matches))
+	last := 0
+	for _, m := range matches {
+		replacement, err := r.replacement(ctx, m)
+		if err != nil {
+			return "", nil, err
+		}
+		b.WriteString(text[last:m.Start])
+		b.WriteString(replacement)
+		last = m.End
+		replacements = append(replacements, Replacement{Start: m.Start, End: m.End, Label: m.Label, Replacement: replacement})
+	}
+	b.WriteString(text[last:])
+	return b.String(), replacements, nil
+}
+
+// PreprocessEpisode redacts the name, content and string metadata values of an
+// episode in place and records the number of replacements in its metadata.
+func (r *Redactor) PreprocessEpisode(ctx context.Context, episode *types.Episode) error {
+	content, replacements, err := r.Redact(ctx, episode.Content)
+	if err != nil {
+		return err
+	}
+	name, nameReplacements, err := r.Redact(ctx, episode.Name)
+	if err != nil {
+		return err
+	}
+	episode.Content = content
+	episode.Name = name
+	count := len(replacements) + len(nameReplacements)
+
+	// Copy the metadata so the caller's map is left untouched. Metadata is
+	// stored alongside the episode and its fact store source, so its values
+	// are redacted like the content.
+	metadata := make(map[string]interface{}, len(episode.Metadata)+1)
+	for k, v := range episode.Metadata {
+		redacted, n, err := r.redactValue(ctx, v)
+		if err != nil {
+			return fmt.Errorf("failed to redact metadata %q: %w", k, err)
+		}
+		metadata[k] = redacted
+		count += n
+	}
+	metadata[MetadataKeyReplacements] = count
+	episode.Metadata = metadata
+	return nil
+}
+
+// redactValue redacts the strings in a metadata value, descending into maps
+// and slices. Other values are returned unchanged.
+func (r *Redactor) redactValue(ctx context.Context, value interface{}) (interface{}, int, error) {
+	switch v := value.(type) {
+	case string:
+		redacted, replacements, err := r.Redact(ctx, v)
+		return redacted, len(replacements), err
+	case []string:
+		out := make([]string, len(v))
+		count := 0
+		for i, s := range v {
+			redacted, replacements, err := r.Redact(ctx, s)
+			if err != nil {
+				return nil, 0, err
+			}
+			out[i] = redacted
+			count += len(replacements)
+		}
+		return out, count, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		count := 0
+		for i, item := range v {
+			redacted, n, err := r.redactValue(ctx, item)
+			if err != nil {
+				return nil, 0, err
+			}
+			out[i] = redacted
+			count += n
+		}
+		return out, count, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		count := 0
+		for k, item := range v {
+			redacted, n, err := r.redactValue(ctx, item)
+			if err != nil {
+				return nil, 0, err
+			}
+			out[k] = redacted
+			count += n
+		}
+		return out, count, nil
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		count := 0
+		for k, s := range v {
+			redacted, replacements, err := r.Redact(ctx, s)
+			if err != nil {
+				return nil, 0, err
+			}
+			out[k] = redacted
+			count += len(replacements)
+		}
+		return out, count, nil
+	default:
+		return value, 0, nil
+	}
+}
+
+// Restore replaces the pseudonyms in text with their original values from the
+// vault. Pseudonyms missing from the vault are left in place.
+func (r *Redactor) Restore(ctx context.Context, text string) (string, error) {
+	if r.vault == nil {
+		return "", fmt.Errorf("no vault configured")
+	}
+	var restoreErr error
+	restored := pseudonymPattern.ReplaceAllStringFunc(text, func(token string) string {
+		if restoreErr != nil {
+			return token
+		}
+		value, err := r.vault.Get(ctx, token)
+		if err != nil {
+			if !errors.Is(err, ErrNotInVault) {
+				restoreErr = err
+			}
+			return token
+		}
+		return value
+	})
+	if restoreErr != nil {
+		return "", fmt.Errorf("failed to restore pseudonym: %w", restoreErr)
+	}
+	return restored, nil
+}
+
+// replacement returns the text that replaces a match.
+func (r *Redactor) replacement(ctx context.Context, m Match) (string, error) {
+	if r.mode == ModeRedact {
+		return "[" + m.Label + "]", nil
+	}
+
+	normalized := m.Normalized
+	if normalized == "" {
+		normalized = strings.Join(strings.Fields(strings.ToLower(m.Text)), " ")
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(m.Label))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalized))
+	token := m.Label + "_" + hex.EncodeToString(mac.Sum(nil))[:10]
+
+	if r.vault != nil {
+		if err := r.vault.Put(ctx, token, m.Label, m.Text); err != nil {
+			return "", fmt.Errorf("failed to store pseudonym: %w", err)
+		}
+	}
+	return token, nil
+}
+
+// resolveOverlaps sorts matches by position and drops those overlapping an
+// earlier or longer match.
+func resolveOverlaps(matches []Match) []Match {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End-matches[i].Start > matches[j].End-matches[j].Start
+	})
+	resolved := matches[:0]
+	end := -1
+	for _, m := range matches {
+		if m.Start < end || m.End <= m.Start {
+			continue
+		}
+		resolved = append(resolved, m)
+		end = m.End
+	}
+	return resolved
+}
+
+// labelFor turns a free-form label such as "phone number" into a replacement
+// label such as "PHONE_NUMBER".
+func labelFor(label string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(strings.TrimSpace(label)) {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,192 @@
+package pii
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+func TestRegexDetectorValidatesChecksums(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		label string
+		want  bool
+	}{
+		{"valid card", "card 4111 1111 1111 1111 on file", LabelCreditCard, true},
+		{"invalid card", "card 4111 1111 1111 1112 on file", LabelCreditCard, false},
+		{"valid IBAN", "pay GB82 WEST 1234 5698 7654 32 today", LabelIBAN, true},
+		{"invalid IBAN", "pay GB83 WEST 1234 5698 7654 32 today", LabelIBAN, false},
+		{"valid NHS number", "NHS 943 476 5919", LabelNHSNumber, true},
+		{"invalid NHS number", "NHS 943 476 5918", LabelNHSNumber, false},
+		{"valid SSN", "SSN 123-45-6789", LabelSSN, true},
+		{"unissued SSN", "SSN 666-45-6789", LabelSSN, false},
+		{"email", "write to jane.doe@example.org", LabelEmail, true},
+		{"medical record number", "MRN: A12345 admitted", LabelMedicalRecordNumber, true},
+	}
+
+	detector := NewRegexDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := detector.Detect(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			found := false
+			for _, m := range matches {
+				if m.Label == tt.label {
+					found = true
+				}
+			}
+			if found != tt.want {
+				t.Errorf("found %s = %v, want %v (matches %+v)", tt.label, found, tt.want, matches)
+			}
+		})
+	}
+}
+
+func TestRedactReplacesWithLabels(t *testing.T) {
+	redactor, err := NewRedactor([]Detector{NewRegexDetector()}, nil)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	got, replacements, err := redactor.Redact(context.Background(), "Email jane@example.org or call +44 20 7946 0958.")
+	if err != nil {
+		t.Fatalf("Redact: %v", err)
+	}
+	if want := "Email [EMAIL] or call [PHONE]."; got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+	if len(replacements) != 2 {
+		t.Errorf("got %d replacements, want 2", len(replacements))
+	}
+}
+
+func TestPseudonymsAreStableAndRestorable(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	vault, err := OpenFileVault(filepath.Join(t.TempDir(), "vault.jsonl"), key)
+	if err != nil {
+		t.Fatalf("OpenFileVault: %v", err)
+	}
+	redactor, err := NewRedactor([]Detector{NewRegexDetector()}, &Options{Mode: ModePseudonymize, Key: key, Vault: vault})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	first, _, err := redactor.Redact(ctx, "Contact jane@example.org")
+	if err != nil {
+		t.Fatalf("Redact: %v", err)
+	}
+	second, _, err := redactor.Redact(ctx, "Reply to JANE@example.org")
+	if err != nil {
+		t.Fatalf("Redact: %v", err)
+	}
+	token := strings.TrimPrefix(first, "Contact ")
+	if !strings.HasPrefix(token, "EMAIL_") || second != "Reply to "+token {
+		t.Fatalf("pseudonyms not stable: %q, %q", first, second)
+	}
+
+	// A reopened vault restores the value with the same key only
+	reopened, err := OpenFileVault(vault.path, key)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	restorer, err := NewRedactor(nil, &Options{Mode: ModePseudonymize, Key: key, Vault: reopened})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	restored, err := restorer.Restore(ctx, first+" and UNKNOWN_0123456789")
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if want := "Contact jane@example.org and UNKNOWN_0123456789"; restored != want {
+		t.Errorf("Restore() = %q, want %q", restored, want)
+	}
+
+	wrongKey, err := OpenFileVault(vault.path, []byte("fedcba9876543210fedcba9876543210"))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, err := wrongKey.Get(ctx, token); err == nil || errors.Is(err, ErrNotInVault) {
+		t.Errorf("Get() with wrong key error = %v, want decryption failure", err)
+	}
+}
+
+type fakeRecognizer struct {
+	entities []Entity
+}
+
+func (f *fakeRecognizer) RecognizeEntities(ctx context.Context, text string, labels []string) ([]Entity, error) {
+	return f.entities, nil
+}
+
+func TestGLiNERDetectorAndOverlaps(t *testing.T) {
+	text := "Jane Doe (jane@example.org) has diabetes. Jane Doe is 40."
+	recognizer := &fakeRecognizer{entities: []Entity{
+		{Text: "Jane Doe", Label: "person", Score: 0.9},
+		{Text: "diabetes", Label: "medical condition", Score: 0.8, Start: 32, End: 40},
+		{Text: "jane@example.org", Label: "email address", Score: 0.9},
+		{Text: "40", Label: "person", Score: 0.1},
+	}}
+	redactor, err := NewRedactor([]Detector{NewRegexDetector(), NewGLiNERDetector(recognizer, nil, 0.5)}, nil)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	episode := &types.Episode{Name: "Note on Jane Doe", Content: text, Metadata: map[string]interface{}{"k": "v"}}
+	if err := redactor.PreprocessEpisode(context.Background(), episode); err != nil {
+		t.Fatalf("PreprocessEpisode: %v", err)
+	}
+	want := "[PERSON] ([EMAIL]) has [MEDICAL_CONDITION]. [PERSON] is 40."
+	if episode.Content != want {
+		t.Errorf("Content = %q, want %q", episode.Content, want)
+	}
+	if episode.Name != "Note on [PERSON]" {
+		t.Errorf("Name = %q", episode.Name)
+	}
+	if got := episode.Metadata[MetadataKeyReplacements]; got != 5 {
+		t.Errorf("replacements = %v, want 5", got)
+	}
+}
+
+func TestPreprocessEpisodeRedactsMetadata(t *testing.T) {
+	redactor, err := NewRedactor([]Detector{NewRegexDetector()}, nil)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	original := map[string]interface{}{
+		"original_content": "mail jane@example.org",
+		"contacts":         []interface{}{"+44 20 7946 0958", 7},
+		"nested":           map[string]interface{}{"email": "jane@example.org"},
+		"count":            3,
+	}
+	episode := &types.Episode{Content: "hello", Metadata: original}
+	if err := redactor.PreprocessEpisode(context.Background(), episode); err != nil {
+		t.Fatalf("PreprocessEpisode: %v", err)
+	}
+
+	if got := episode.Metadata["original_content"]; got != "mail [EMAIL]" {
+		t.Errorf("original_content = %v", got)
+	}
+	contacts := episode.Metadata["contacts"].([]interface{})
+	if contacts[0] != "[PHONE]" || contacts[1] != 7 {
+		t.Errorf("contacts = %v", contacts)
+	}
+	if got := episode.Metadata["nested"].(map[string]interface{})["email"]; got != "[EMAIL]" {
+		t.Errorf("nested email = %v", got)
+	}
+	if got := episode.Metadata["count"]; got != 3 {
+		t.Errorf("count = %v, want 3", got)
+	}
+	if got := episode.Metadata[MetadataKeyReplacements]; got != 3 {
+		t.Errorf("replacements = %v, want 3", got)
+	}
+	if original["original_content"] != "mail jane@example.org" {
+		t.Error("caller's metadata was modified")
+	}
+}
@@ -0,0 +1,229 @@
+package pii
+
+import (
+	"context"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Labels of the identifiers found by RegexDetector
+const (
+	LabelEmail               = "EMAIL"
+	LabelPhone               = "PHONE"
+	LabelCreditCard          = "CREDIT_CARD"
+	LabelIBAN                = "IBAN"
+	LabelSSN                 = "SSN"
+	LabelNHSNumber           = "NHS_NUMBER"
+	LabelMedicalRecordNumber = "MEDICAL_RECORD_NUMBER"
+)
+
+// Rule is a pattern for one kind of identifier.
+type Rule struct {
+	Label   string
+	Pattern *regexp.Regexp
+	// Group selects the submatch holding the identifier; zero is the whole match.
+	Group int
+	// Validate rejects candidates that match the pattern but fail a checksum
+	// or structural check. Nil accepts every candidate.
+	Validate func(value string) bool
+	// Normalize returns the canonical form of a value. Nil lower-cases it.
+	Normalize func(value string) string
+}
+
+// RegexDetector finds structured identifiers with regular expressions,
+// validating them with checksums where the identifier defines one. Rules are
+// tried in order and, where matches overlap, the earlier rule wins.
+type RegexDetector struct {
+	rules []Rule
+}
+
+// NewRegexDetector creates a detector with the given rules, or DefaultRules if none are given.
+func NewRegexDetector(rules ...Rule) *RegexDetector {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &RegexDetector{rules: rules}
+}
+
+// DefaultRules returns rules for emails, card numbers (Luhn), IBANs (mod 97),
+// US social security numbers, NHS numbers (mod 11), medical record numbers
+// and phone numbers.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Label:   LabelEmail,
+			Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		},
+		{
+			Label:     LabelCreditCard,
+			Pattern:   regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+			Validate:  luhnValid,
+			Normalize: digitsOnly,
+		},
+		{
+			Label:     LabelIBAN,
+			Pattern:   regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+			Validate:  ibanValid,
+			Normalize: alphanumericOnly,
+		},
+		{
+			Label:     LabelSSN,
+			Pattern:   regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+			Validate:  ssnValid,
+			Normalize: digitsOnly,
+		},
+		{
+			Label:     LabelNHSNumber,
+			Pattern:   regexp.MustCompile(`\b\d{3}[ -]?\d{3}[ -]?\d{4}\b`),
+			Validate:  nhsNumberValid,
+			Normalize: digitsOnly,
+		},
+		{
+			Label:     LabelMedicalRecordNumber,
+			Pattern:   regexp.MustCompile(`(?i)\b(?:MRN|medical record (?:number|no\.?|#))[:#\s]*([A-Z0-9][A-Z0-9-]{4,})`),
+			Group:     1,
+			Normalize: alphanumericOnly,
+		},
+		{
+			Label:     LabelPhone,
+			Pattern:   regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{1,4}\)[\s.-]?)?\d{2,4}[\s.-]\d{3,4}(?:[\s.-]\d{3,4})?`),
+			Validate:  phoneValid,
+			Normalize: digitsOnly,
+		},
+	}
+}
+
+// Detect returns the identifiers found in text.
+func (d *RegexDetector) Detect(ctx context.Context, text string) ([]Match, error) {
+	var matches []Match
+	taken := make([]bool, len(text))
+	for _, rule := range d.rules {
+		for _, loc := range rule.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[2*rule.Group], loc[2*rule.Group+1]
+			if start < 0 || overlapsTaken(taken, start, end) {
+				continue
+			}
+			value := text[start:end]
+			if rule.Validate != nil && !rule.Validate(value) {
+				continue
+			}
+			normalized := strings.ToLower(value)
+			if rule.Normalize != nil {
+				normalized = rule.Normalize(value)
+			}
+			for i := start; i < end; i++ {
+				taken[i] = true
+			}
+			matches = append(matches, Match{Start: start, End: end, Label: rule.Label, Text: value, Normalized: normalized})
+		}
+	}
+	return matches, nil
+}
+
+func overlapsTaken(taken []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if taken[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func digitsOnly(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func alphanumericOnly(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// luhnValid checks the Luhn checksum used by payment card numbers.
+func luhnValid(value string) bool {
+	digits := digitsOnly(value)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ibanValid checks the ISO 13616 mod-97 checksum of an IBAN.
+func ibanValid(value string) bool {
+	iban := alphanumericOnly(value)
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	rearranged := iban[4:] + iban[:4]
+	var numeric strings.Builder
+	for _, r := range rearranged {
+		if r >= 'A' && r <= 'Z' {
+			numeric.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// ssnValid rejects US social security numbers that are never issued.
+func ssnValid(value string) bool {
+	digits := digitsOnly(value)
+	if len(digits) != 9 {
+		return false
+	}
+	area, group, serial := digits[:3], digits[3:5], digits[5:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// nhsNumberValid checks the mod-11 check digit of an NHS number.
+func nhsNumberValid(value string) bool {
+	digits := digitsOnly(value)
+	if len(digits) != 10 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(digits[i]-'0') * (10 - i)
+	}
+	check := 11 - sum%11
+	if check == 11 {
+		check = 0
+	}
+	return check != 10 && check == int(digits[9]-'0')
+}
+
+// phoneValid accepts candidates with a plausible number of digits.
+func phoneValid(value string) bool {
+	n := len(digitsOnly(value))
+	return n >= 7 && n <= 15
+}
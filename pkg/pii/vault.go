@@ -0,0 +1,142 @@
+package pii
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNotInVault is returned by Vault.Get for unknown pseudonyms.
+var ErrNotInVault = errors.New("pseudonym not found in vault")
+
+// Vault maps pseudonyms back to the values they replaced.
+type Vault interface {
+	// Put records the value a pseudonym replaced. Putting a known pseudonym is a no-op.
+	Put(ctx context.Context, pseudonym, label, value string) error
+	// Get returns the value a pseudonym replaced.
+	Get(ctx context.Context, pseudonym string) (string, error)
+}
+
+// vaultRecord is one line of a FileVault. Only the value is encrypted; the
+// pseudonym is already derived from a secret.
+type vaultRecord struct {
+	Pseudonym  string    `json:"pseudonym"`
+	Label      string    `json:"label"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FileVault is a Vault stored as a JSONL file with values encrypted by
+// AES-256-GCM. Only holders of the key can read the values back, so access to
+// original PII is restricted to those the key is shared with.
+type FileVault struct {
+	path    string
+	aead    cipher.AEAD
+	mu      sync.Mutex
+	records map[string]vaultRecord
+}
+
+// OpenFileVault opens the vault at path, creating it if needed. key must be 32 bytes.
+func OpenFileVault(path string, key []byte) (*FileVault, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("vault key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault cipher: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	v := &FileVault{path: path, aead: aead, records: make(map[string]vaultRecord)}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return v, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vault: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record vaultRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// A partially written last line is skipped
+			continue
+		}
+		v.records[record.Pseudonym] = record
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+	return v, nil
+}
+
+// Put encrypts and appends value unless the pseudonym is already stored.
+func (v *FileVault) Put(ctx context.Context, pseudonym, label, value string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.records[pseudonym]; ok {
+		return nil
+	}
+
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	record := vaultRecord{
+		Pseudonym: pseudonym,
+		Label:     label,
+		Nonce:     nonce,
+		// The pseudonym is authenticated so that values cannot be swapped between records
+		Ciphertext: v.aead.Seal(nil, nonce, []byte(value), []byte(pseudonym)),
+		CreatedAt:  time.Now().UTC(),
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode vault record: %w", err)
+	}
+
+	file, err := os.OpenFile(v.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open vault: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	v.records[pseudonym] = record
+	return nil
+}
+
+// Get decrypts the value a pseudonym replaced.
+func (v *FileVault) Get(ctx context.Context, pseudonym string) (string, error) {
+	v.mu.Lock()
+	record, ok := v.records[pseudonym]
+	v.mu.Unlock()
+	if !ok {
+		return "", ErrNotInVault
+	}
+	value, err := v.aead.Open(nil, record.Nonce, record.Ciphertext, []byte(pseudonym))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt vault record: %w", err)
+	}
+	return string(value), nil
+}
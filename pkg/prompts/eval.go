@@ -73,8 +73,17 @@ Note: Query data is provided in TSV (tab-separated values) format.
 
 // qaPrompt answers questions from Alice's first person perspective.
 // Uses TSV format for entity summaries and facts to reduce token usage and improve LLM parsing.
+//
+// Optional context keys used by Client.Answer: "system_prompt" replaces the
+// Alice persona, "communities" and "sources" add further TSV sections, and
+// "cite" asks the model to cite the id column of the facts and sources it uses.
 func qaPrompt(context map[string]interface{}) ([]types.Message, error) {
 	sysPrompt := `You are Alice and should respond to all questions from the first person perspective of Alice`
+	task := "Your task is to briefly answer the question in the way that you think Alice would answer the question."
+	if custom, ok := context["system_prompt"].(string); ok && custom != "" {
+		sysPrompt = custom
+		task = "Your task is to briefly answer the question."
+	}
 
 	entitySummaries := context["entity_summaries"]
 	facts := context["facts"]
@@ -97,10 +106,34 @@ func qaPrompt(context map[string]interface{}) ([]types.Message, error) {
 		return nil, fmt.Errorf("failed to marshal facts: %w", err)
 	}
 
+	var extraSections string
+	if communities, ok := context["communities"]; ok && communities != nil {
+		communitiesTSV, err := ToPromptCSV(communities, ensureASCII)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal communities: %w", err)
+		}
+		extraSections += fmt.Sprintf("<COMMUNITIES>\n%s\n</COMMUNITIES>\n", communitiesTSV)
+	}
+	if sources, ok := context["sources"]; ok && sources != nil {
+		sourcesTSV, err := ToPromptCSV(sources, ensureASCII)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal sources: %w", err)
+		}
+		extraSections += fmt.Sprintf("<SOURCES>\n%s\n</SOURCES>\n", sourcesTSV)
+	}
+
+	var citeInstruction string
+	if cite, ok := context["cite"].(bool); ok && cite {
+		citeInstruction = `
+Only use the information provided. After each statement, cite the id of every fact or source that supports it
+in square brackets, e.g. [F1] or [F2, S1]. If the information is not sufficient to answer, say so.
+`
+	}
+
 	userPrompt := fmt.Sprintf(`
-Your task is to briefly answer the question in the way that you think Alice would answer the question.
+%s
 You are given the following entity summaries and facts to help you determine the answer to your question.
-
+%s
 Note: ENTITY_SUMMARIES and FACTS are provided in TSV (tab-separated values) format.
 
 <ENTITY_SUMMARIES>
@@ -109,10 +142,10 @@ Note: ENTITY_SUMMARIES and FACTS are provided in TSV (tab-separated values) form
 <FACTS>
 %s
 </FACTS>
-<QUESTION>
+%s<QUESTION>
 %v
 </QUESTION>
-`, entitySummariesTSV, factsTSV, query)
+`, task, citeInstruction, entitySummariesTSV, factsTSV, extraSections, query)
 	logPrompts(context["logger"].(*slog.Logger), sysPrompt, userPrompt)
 	return []types.Message{
 		nlp.NewSystemMessage(sysPrompt),
@@ -139,6 +139,8 @@ You may use information from the PREVIOUS MESSAGES only to disambiguate referenc
 3. Use a SCREAMING_SNAKE_CASE string as the 'relation_type' (e.g., FOUNDED, WORKS_AT).
 4. Do not emit duplicate or semantically redundant facts.
 5. The 'fact_text' should quote or closely paraphrase the original source sentence(s).
+   Copy the exact source sentence (or the shortest exact span supporting the fact) into 'evidence', verbatim from CURRENT MESSAGE.
+   Set 'confidence' to a number between 0 and 1 reflecting how explicitly CURRENT MESSAGE states the fact.
 6. Use 'REFERENCE_TIME' to resolve vague or relative temporal expressions (e.g., "last week").
 7. Do **not** hallucinate or infer temporal bounds from unrelated events.
 8. Format your response in a TSV table, with the schema:
@@ -151,13 +153,15 @@ fact: string
 summary: string 
 valid_at: string 
 invalid_at: string 
+evidence: string 
+confidence: float 
 </SCHEMA>
 
 9. Refer to the EXAMPLE; end with a new line
 
 <EXAMPLE>
-source_id\trelation_type\ttarget_id\tfact\tsummary\tvalid_at\tinvalid_at
-0\t"CAUSES"\t2\t"If that pressure is not relieved\tpermanent facial nerve palsy can ensue"\t"Acute Facial Palsy (AFP) causes facial nerve palsy"\t"2025-09-27T00:00:00Z"\tnull
+source_id\trelation_type\ttarget_id\tfact\tsummary\tvalid_at\tinvalid_at\tevidence\tconfidence
+0\t"CAUSES"\t2\t"If that pressure is not relieved\tpermanent facial nerve palsy can ensue"\t"Acute Facial Palsy (AFP) causes facial nerve palsy"\t"2025-09-27T00:00:00Z"\tnull\t"If that pressure is not relieved, permanent facial nerve palsy can ensue."\t0.9
 
 </EXAMPLE>
 `, edgeTypesTSV, previousEpisodesTSV, episodeContent, nodesTSV, referenceTime, customPrompt)
@@ -38,6 +38,7 @@ type ExtractNodesPrompt interface {
 	ExtractAttributes() PromptVersion
 	ExtractSummary() PromptVersion
 	ExtractAttributesBatch() PromptVersion
+	Adjudicate() PromptVersion
 }
 
 // ExtractNodesVersions holds all versions of extract nodes prompts.
@@ -50,6 +51,7 @@ type ExtractNodesVersions struct {
 	extractAttributesPrompt      PromptVersion
 	extractSummaryPrompt         PromptVersion
 	extractAttributesBatchPrompt PromptVersion
+	adjudicatePrompt             PromptVersion
 }
 
 func (e *ExtractNodesVersions) ExtractMessage() PromptVersion    { return e.extractMessagePrompt }
@@ -59,6 +61,7 @@ func (e *ExtractNodesVersions) Reflexion() PromptVersion         { return e.refl
 func (e *ExtractNodesVersions) ClassifyNodes() PromptVersion     { return e.classifyNodesPrompt }
 func (e *ExtractNodesVersions) ExtractAttributes() PromptVersion { return e.extractAttributesPrompt }
 func (e *ExtractNodesVersions) ExtractSummary() PromptVersion    { return e.extractSummaryPrompt }
+func (e *ExtractNodesVersions) Adjudicate() PromptVersion        { return e.adjudicatePrompt }
 func (e *ExtractNodesVersions) ExtractAttributesBatch() PromptVersion {
 	return e.extractAttributesBatchPrompt
 }
@@ -494,6 +497,72 @@ Output ONLY the TSV data with a header row. Include one row per missed entity. I
 	}, nil
 }
 
+// adjudicateEntitiesPrompt decides the entity candidates that extractors disagree on.
+// Uses TSV format for candidates and entity types to reduce token usage and improve LLM parsing.
+func adjudicateEntitiesPrompt(context map[string]interface{}) ([]types.Message, error) {
+	sysPrompt := `You are an AI assistant that reviews entities proposed by several entity extractors and decides which of them were correctly extracted`
+
+	episodeContent := context["episode_content"]
+	candidates := context["candidates"]
+	entityTypes := context["entity_types"]
+
+	ensureASCII := true
+	if val, ok := context["ensure_ascii"]; ok {
+		if b, ok := val.(bool); ok {
+			ensureASCII = b
+		}
+	}
+
+	candidatesTSV, err := ToPromptCSV(candidates, ensureASCII)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal candidates: %w", err)
+	}
+
+	// Filter out entity_type_description to reduce redundancy
+	filteredEntityTypes := filterEntityTypes(entityTypes)
+	entityTypesTSV, err := ToPromptCSV(filteredEntityTypes, ensureASCII)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entity types: %w", err)
+	}
+
+	userPrompt := fmt.Sprintf(`
+<CURRENT MESSAGE>
+%v
+</CURRENT MESSAGE>
+
+<ENTITY TYPES>
+%s
+</ENTITY TYPES>
+
+<CANDIDATES>
+%s
+</CANDIDATES>
+
+Note: ENTITY TYPES and CANDIDATES are provided in TSV (tab-separated values) format.
+
+Some extractors proposed the CANDIDATES and others did not. For each candidate, decide whether it is a significant entity
+explicitly mentioned in the CURRENT MESSAGE that should be extracted, and if so which of the ENTITY TYPES it is.
+
+Guidelines:
+1. Reject pronouns, dates, abstract concepts, relationships and actions
+2. Reject candidates that are only part of a longer entity name
+3. Use entity_type_id 0 if the entity is none of the other listed types
+
+Return the results in TSV (tab-separated values) format with the following structure:
+
+id	keep	entity_type_id
+0	true	1
+1	false	0
+
+Output ONLY the TSV data with a header row. Include one row per candidate.
+`, episodeContent, entityTypesTSV, candidatesTSV)
+	logPrompts(context["logger"].(*slog.Logger), sysPrompt, userPrompt)
+	return []types.Message{
+		nlp.NewSystemMessage(sysPrompt),
+		nlp.NewUserMessage(userPrompt),
+	}, nil
+}
+
 // classifyNodesPrompt classifies entity nodes.
 // Uses TSV format for episodes and entity types to reduce token usage and improve LLM parsing.
 func classifyNodesPrompt(context map[string]interface{}) ([]types.Message, error) {
@@ -797,5 +866,6 @@ func NewExtractNodesVersions() *ExtractNodesVersions {
 		extractAttributesPrompt:      NewPromptVersion(extractNodesAttributesPrompt),
 		extractSummaryPrompt:         NewPromptVersion(extractSummaryPrompt),
 		extractAttributesBatchPrompt: NewPromptVersion(extractAttributesBatchPrompt),
+		adjudicatePrompt:             NewPromptVersion(adjudicateEntitiesPrompt),
 	}
 }
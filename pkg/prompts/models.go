@@ -25,6 +25,9 @@ import (
 type ExtractedEntity struct {
 	Name         string `json:"entity" mapstructure:"entity" csv:"entity" yaml:"entity"`
 	EntityTypeID int    `json:"entity_type_id" mapstructure:"entity_type_id" csv:"entity_type_id" yaml:"entity_type_id"`
+	// Start and End are optional character offsets of the mention (e.g. from GLiNER spans).
+	Start string `json:"start,omitempty" mapstructure:"start" csv:"start" yaml:"start"`
+	End   string `json:"end,omitempty" mapstructure:"end" csv:"end" yaml:"end"`
 }
 
 // ExtractedEntities represents a list of extracted entities
@@ -42,6 +45,13 @@ type MissedEntitiesTSV struct {
 	EntityName string `csv:"entity_name"`
 }
 
+// EntityVerdictTSV represents the adjudication of a disputed entity candidate in TSV format
+type EntityVerdictTSV struct {
+	ID           int    `json:"id" csv:"id"`
+	Keep         string `json:"keep" csv:"keep"`
+	EntityTypeID int    `json:"entity_type_id" csv:"entity_type_id"`
+}
+
 // EntityClassificationTriple represents an entity with classification
 type EntityClassificationTriple struct {
 	UUID       string  `json:"uuid"`
@@ -73,6 +83,13 @@ type ExtractedEdge struct {
 	Summary   string    `json:"summary,omitempty" mapstructure:"summary" csv:"summary"`
 	ValidAt   string    `json:"valid_at,omitempty" mapstructure:"valid_at" csv:"valid_at"`       // matches Python valid_at
 	InvalidAt string    `json:"invalid_at,omitempty" mapstructure:"invalid_at" csv:"invalid_at"` // matches Python invalid_at
+	// Evidence is the verbatim source text supporting the fact.
+	Evidence string `json:"evidence,omitempty" mapstructure:"evidence" csv:"evidence"`
+	// Start and End are optional character offsets of the evidence (e.g. from GLiNER spans).
+	Start string `json:"start,omitempty" mapstructure:"start" csv:"start"`
+	End   string `json:"end,omitempty" mapstructure:"end" csv:"end"`
+	// Confidence is the extractor's confidence in the fact, in [0, 1].
+	Confidence string `json:"confidence,omitempty" mapstructure:"confidence" csv:"confidence"`
 	// alias for Fact
 }
 
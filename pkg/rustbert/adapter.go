@@ -81,6 +81,57 @@ func (a *LLMAdapter) Chat(ctx context.Context, messages []types.Message) (*types
 	}, nil
 }
 
+// nerLabelTypes maps the CoNLL labels of RustBert NER models to common entity
+// type names.
+var nerLabelTypes = map[string][]string{
+	"PER":  {"Person", "People", "Human"},
+	"ORG":  {"Organization", "Organisation", "Company"},
+	"LOC":  {"Location", "Place", "City", "Country"},
+	"MISC": {"Entity"},
+}
+
+// ExtractTypedEntities implements nlp.EntityExtractor for the "ner" task. The
+// CoNLL labels of the model are mapped onto the requested entity types by
+// name; other tasks return nlp.ErrExtractionUnsupported.
+func (a *LLMAdapter) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	if a.task != "ner" {
+		return nil, nlp.ErrExtractionUnsupported
+	}
+	entities, err := a.client.ExtractEntities(req.Text)
+	if err != nil {
+		return nil, fmt.Errorf("RustBert NER failed: %w", err)
+	}
+
+	extracted := make([]nlp.ExtractedEntity, 0, len(entities))
+	for _, e := range entities {
+		extracted = append(extracted, nlp.ExtractedEntity{
+			Name:  e.Text,
+			Type:  entityTypeForLabel(e.Label, req.EntityTypes),
+			Span:  types.LocateSpan(req.Text, e.Text),
+			Score: e.Score,
+		})
+	}
+	return extracted, nil
+}
+
+// entityTypeForLabel returns the requested entity type matching a NER label
+// such as "I-PER", or "" if none does.
+func entityTypeForLabel(label string, entityTypes []nlp.EntityType) string {
+	label = strings.ToUpper(label)
+	if i := strings.IndexByte(label, '-'); i >= 0 {
+		label = label[i+1:]
+	}
+	candidates := append([]string{label}, nerLabelTypes[label]...)
+	for _, candidate := range candidates {
+		for _, entityType := range entityTypes {
+			if strings.EqualFold(entityType.Name, candidate) {
+				return entityType.Name
+			}
+		}
+	}
+	return ""
+}
+
 // ChatWithStructuredOutput implements the nlp.Client interface.
 func (a *LLMAdapter) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
 	// RustBert models don't support structured output schema enforcement natively yet.
@@ -110,3 +161,8 @@ func (a *LLMAdapter) GetCapabilities() []nlp.TaskCapability {
 		return []nlp.TaskCapability{}
 	}
 }
+
+// IsLocalFor implements nlp.LocalityReporter; RustBert models always run locally.
+func (a *LLMAdapter) IsLocalFor(usage string) bool {
+	return true
+}
@@ -0,0 +1,167 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// SearchGroups runs a hybrid search across several groups and merges the
+// results. The query is embedded once; each group is then searched
+// independently so per-group rerankers see only their own candidates.
+//
+// Scores are multiplied by config.GroupWeights before merging, which lets a
+// caller prefer e.g. a user's private group over a shared organisation group.
+// Every returned node and edge carries the group it was found in, and the
+// merged lists are truncated to config.Limit.
+func (s *Searcher) SearchGroups(ctx context.Context, query string, config *SearchConfig, filters *SearchFilters, groupIDs []string) (result *HybridSearchResult, err error) {
+	groupIDs = uniqueGroupIDs(groupIDs)
+	if len(groupIDs) <= 1 {
+		groupID := ""
+		if len(groupIDs) == 1 {
+			groupID = groupIDs[0]
+		}
+		return s.Search(ctx, query, config, filters, groupID)
+	}
+	if strings.TrimSpace(query) == "" {
+		return &HybridSearchResult{}, nil
+	}
+	ctx, done := traceBranch(ctx, "search_groups", strings.Join(groupIDs, ","))
+	defer func() {
+		count := 0
+		if result != nil {
+			count = result.Total
+		}
+		done(count, err)
+	}()
+
+	queryVector, err := s.embedQuery(ctx, query, config)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := NewGroupMerger(config.GroupWeights, func(n *types.Node) string { return n.Uuid })
+	edges := NewGroupMerger(config.GroupWeights, func(e *types.Edge) string { return e.Uuid })
+
+	for _, groupID := range groupIDs {
+		result, err := s.searchGroup(ctx, query, queryVector, config, filters, groupID)
+		if err != nil {
+			return nil, fmt.Errorf("search in group %s failed: %w", groupID, err)
+		}
+		for _, node := range result.Nodes {
+			if node.GroupID == "" {
+				node.GroupID = groupID
+			}
+		}
+		for _, edge := range result.Edges {
+			if edge.GroupID == "" {
+				edge.GroupID = groupID
+			}
+		}
+		nodes.Add(groupID, result.Nodes, result.NodeScores)
+		edges.Add(groupID, result.Edges, result.EdgeScores)
+	}
+
+	merged := &HybridSearchResult{Query: query}
+	merged.Nodes, merged.NodeScores = nodes.Results(config.Limit)
+	merged.Edges, merged.EdgeScores = edges.Results(config.Limit)
+	merged.Total = len(merged.Nodes) + len(merged.Edges)
+
+	return merged, nil
+}
+
+// uniqueGroupIDs drops empty and repeated group IDs, preserving order.
+func uniqueGroupIDs(groupIDs []string) []string {
+	seen := make(map[string]bool, len(groupIDs))
+	unique := make([]string, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// GroupMerger combines ranked results from several groups. Each group's
+// scores are multiplied by its weight; an item found in more than one group
+// keeps its best score.
+type GroupMerger[T any] struct {
+	weights map[string]float64
+	key     func(T) string
+	items   []T
+	scores  []float64
+	index   map[string]int
+}
+
+// NewGroupMerger returns a merger that identifies items by key. Groups
+// missing from weights have weight 1.
+func NewGroupMerger[T any](weights map[string]float64, key func(T) string) *GroupMerger[T] {
+	return &GroupMerger[T]{weights: weights, key: key, index: make(map[string]int)}
+}
+
+// Add merges one group's results. scores[i] belongs to items[i]; items
+// without a score count as 1.
+func (m *GroupMerger[T]) Add(groupID string, items []T, scores []float64) {
+	weight := groupWeight(m.weights, groupID)
+	for i, item := range items {
+		score := scoreAt(scores, i) * weight
+		id := m.key(item)
+		if idx, ok := m.index[id]; ok {
+			if score > m.scores[idx] {
+				m.scores[idx] = score
+			}
+			continue
+		}
+		m.index[id] = len(m.items)
+		m.items = append(m.items, item)
+		m.scores = append(m.scores, score)
+	}
+}
+
+// Results returns the merged items ordered by descending score, truncated
+// to limit when limit is positive.
+func (m *GroupMerger[T]) Results(limit int) ([]T, []float64) {
+	return sortByScore(m.items, m.scores, limit)
+}
+
+// groupWeight returns the configured weight for a group, defaulting to 1.
+func groupWeight(weights map[string]float64, groupID string) float64 {
+	if w, ok := weights[groupID]; ok && w >= 0 {
+		return w
+	}
+	return 1.0
+}
+
+// scoreAt returns scores[i], or 1 when a reranker did not report scores.
+func scoreAt(scores []float64, i int) float64 {
+	if i < len(scores) {
+		return scores[i]
+	}
+	return 1.0
+}
+
+func sortByScore[T any](items []T, scores []float64, limit int) ([]T, []float64) {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+
+	sortedItems := make([]T, len(order))
+	sortedScores := make([]float64, len(order))
+	for i, idx := range order {
+		sortedItems[i] = items[idx]
+		sortedScores[i] = scores[idx]
+	}
+	return sortedItems, sortedScores
+}
@@ -245,3 +245,18 @@ func (esf *EnhancedSearchFilters) ConvertToBasicFilters() *SearchFilters {
 		TimeRange:   esf.TimeRange,
 	}
 }
+
+// FilterEdgesByConfidence returns the edges whose effective confidence is at
+// least minConfidence. Edges without a recorded confidence are kept.
+func FilterEdgesByConfidence(edges []*types.Edge, minConfidence float64) []*types.Edge {
+	if minConfidence <= 0 {
+		return edges
+	}
+	filtered := make([]*types.Edge, 0, len(edges))
+	for _, edge := range edges {
+		if edge.EffectiveConfidence() >= minConfidence {
+			filtered = append(filtered, edge)
+		}
+	}
+	return filtered
+}
@@ -185,8 +185,88 @@ func EpisodeMentionsReranker(ctx context.Context, driver driver.GraphDriver, nod
 	return resultUUIDs, resultScores, nil
 }
 
+// ConfidenceRRF fuses ranked lists with RRF and scales each fused score by the
+// item's confidence, so low-confidence results sink below corroborated ones.
+// confidenceWeight is in [0, 1]: 0 leaves RRF unchanged, 1 multiplies scores by
+// confidence directly. Items missing from confidence count as fully confident.
+func ConfidenceRRF(results [][]string, confidence map[string]float64, confidenceWeight float64, rankConstant int, minScore float64) ([]string, []float64) {
+	uuids, scores := RRF(results, rankConstant, 0)
+
+	type uuidScore struct {
+		uuid  string
+		score float64
+	}
+
+	scored := make([]uuidScore, 0, len(uuids))
+	for i, uuid := range uuids {
+		score := scores[i] * confidenceFactor(confidence, uuid, confidenceWeight)
+		if score >= minScore {
+			scored = append(scored, uuidScore{uuid: uuid, score: score})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	resultUUIDs := make([]string, len(scored))
+	resultScores := make([]float64, len(scored))
+	for i, item := range scored {
+		resultUUIDs[i] = item.uuid
+		resultScores[i] = item.score
+	}
+
+	return resultUUIDs, resultScores
+}
+
+// ConfidenceMMR is MaximalMarginalRelevance with each candidate's query
+// relevance scaled by its confidence (see ConfidenceRRF for the weighting).
+func ConfidenceMMR(queryVector []float32, candidates map[string][]float32, confidence map[string]float64, confidenceWeight float64, mmrLambda float64, minScore float64) ([]string, []float64) {
+	return maximalMarginalRelevance(queryVector, candidates, confidence, confidenceWeight, mmrLambda, minScore)
+}
+
+// resolveConfidenceWeight applies the EdgeSearchConfig.ConfidenceWeight
+// conventions: 0 selects DefaultConfidenceWeight and negative disables it.
+func resolveConfidenceWeight(weight float64) float64 {
+	switch {
+	case weight == 0:
+		return DefaultConfidenceWeight
+	case weight < 0:
+		return 0
+	case weight > 1:
+		return 1
+	}
+	return weight
+}
+
+// edgeConfidences returns the effective confidence of each edge by UUID.
+func edgeConfidences(edges map[string]*types.Edge) map[string]float64 {
+	confidence := make(map[string]float64, len(edges))
+	for uuid, edge := range edges {
+		confidence[uuid] = edge.EffectiveConfidence()
+	}
+	return confidence
+}
+
+// confidenceFactor maps an item's confidence to a score multiplier in
+// [1-weight, 1].
+func confidenceFactor(confidence map[string]float64, uuid string, weight float64) float64 {
+	c, ok := confidence[uuid]
+	if !ok || weight <= 0 {
+		return 1.0
+	}
+	if weight > 1 {
+		weight = 1
+	}
+	return 1 - weight + weight*c
+}
+
 // MaximalMarginalRelevance (MMR) reranks results to balance relevance and diversity
 func MaximalMarginalRelevance(queryVector []float32, candidates map[string][]float32, mmrLambda float64, minScore float64) ([]string, []float64) {
+	return maximalMarginalRelevance(queryVector, candidates, nil, 0, mmrLambda, minScore)
+}
+
+func maximalMarginalRelevance(queryVector []float32, candidates map[string][]float32, confidence map[string]float64, confidenceWeight float64, mmrLambda float64, minScore float64) ([]string, []float64) {
 	if mmrLambda == 0 {
 		mmrLambda = DefaultMMRLambda
 	}
@@ -226,8 +306,9 @@ func MaximalMarginalRelevance(queryVector []float32, candidates map[string][]flo
 	// Calculate MMR scores
 	mmrScores := make(map[string]float64)
 	for _, uuid := range uuids {
-		// Query-document similarity
+		// Query-document similarity, discounted by confidence
 		queryDocSim := CalculateCosineSimilarity(normalizedQuery, candidateVectors[uuid])
+		queryDocSim *= confidenceFactor(confidence, uuid, confidenceWeight)
 
 		// Find maximum similarity to any other document
 		maxSim := 0.0
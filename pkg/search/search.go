@@ -39,6 +39,9 @@ type SearchConfig struct {
 	CommunityConfig *CommunitySearchConfig `json:"community_config,omitempty"`
 	Limit           int                    `json:"limit"`
 	MinScore        float64                `json:"min_score"`
+	// GroupWeights scales the scores of each group's results when searching
+	// several groups with SearchGroups. Groups not listed use a weight of 1.
+	GroupWeights map[string]float64 `json:"group_weights,omitempty"`
 }
 
 type NodeSearchConfig struct {
@@ -55,6 +58,9 @@ type EdgeSearchConfig struct {
 	MinScore      float64        `json:"min_score"`
 	MMRLambda     float64        `json:"mmr_lambda"`
 	MaxDepth      int            `json:"max_depth"`
+	// ConfidenceWeight controls how much edge confidence scales RRF and MMR
+	// scores: 0 uses DefaultConfidenceWeight, a negative value disables it.
+	ConfidenceWeight float64 `json:"confidence_weight"`
 }
 
 type EpisodeSearchConfig struct {
@@ -76,6 +82,9 @@ type SearchFilters struct {
 	EdgeTypes   []types.EdgeType `json:"edge_types,omitempty"`
 	EntityTypes []string         `json:"entity_types,omitempty"`
 	TimeRange   *types.TimeRange `json:"time_range,omitempty"`
+	// MinConfidence drops edges whose confidence is below the threshold.
+	// Edges without a recorded confidence are always kept.
+	MinConfidence float64 `json:"min_confidence,omitempty"`
 }
 
 type HybridSearchResult struct {
@@ -113,26 +122,47 @@ func (s *Searcher) SetCrossEncoder(crossEncoder crossencoder.Client) {
 	s.crossEncoder = crossEncoder
 }
 
-func (s *Searcher) Search(ctx context.Context, query string, config *SearchConfig, filters *SearchFilters, groupID string) (*HybridSearchResult, error) {
+func (s *Searcher) Search(ctx context.Context, query string, config *SearchConfig, filters *SearchFilters, groupID string) (result *HybridSearchResult, err error) {
 	if strings.TrimSpace(query) == "" {
 		return &HybridSearchResult{}, nil
 	}
+	ctx, done := traceBranch(ctx, "search", groupID)
+	defer func() {
+		count := 0
+		if result != nil {
+			count = result.Total
+		}
+		done(count, err)
+	}()
 
-	// Generate query embedding if needed for semantic search
-	var queryVector []float32
-	needsEmbedding := s.needsEmbedding(config)
+	queryVector, err := s.embedQuery(ctx, query, config)
+	if err != nil {
+		return nil, err
+	}
 
-	if needsEmbedding {
-		vectors, err := s.embedder.Embed(ctx, []string{strings.ReplaceAll(query, "\n", " ")})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create query embedding: %w", err)
-		}
-		if len(vectors) > 0 {
-			queryVector = vectors[0]
-		}
+	return s.searchGroup(ctx, query, queryVector, config, filters, groupID)
+}
+
+// embedQuery generates the query embedding if the config needs one for
+// semantic search or MMR reranking.
+func (s *Searcher) embedQuery(ctx context.Context, query string, config *SearchConfig) (vector []float32, err error) {
+	if !s.needsEmbedding(config) {
+		return nil, nil
+	}
+	ctx, done := traceBranch(ctx, "embed_query", "")
+	defer func() { done(len(vector), err) }()
+	vectors, err := s.embedder.Embed(ctx, []string{strings.ReplaceAll(query, "\n", " ")})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query embedding: %w", err)
+	}
+	if len(vectors) == 0 {
+		return nil, nil
 	}
+	return vectors[0], nil
+}
 
-	// Perform searches concurrently
+// searchGroup runs node and edge search within a single group.
+func (s *Searcher) searchGroup(ctx context.Context, query string, queryVector []float32, config *SearchConfig, filters *SearchFilters, groupID string) (*HybridSearchResult, error) {
 	nodeResults := make([]*types.Node, 0)
 	edgeResults := make([]*types.Edge, 0)
 	nodeScores := make([]float64, 0)
@@ -205,7 +235,10 @@ func (s *Searcher) needsEmbedding(config *SearchConfig) bool {
 	return false
 }
 
-func (s *Searcher) searchNodes(ctx context.Context, query string, queryVector []float32, config *NodeSearchConfig, filters *SearchFilters, groupID string, limit int) ([]*types.Node, []float64, error) {
+func (s *Searcher) searchNodes(ctx context.Context, query string, queryVector []float32, config *NodeSearchConfig, filters *SearchFilters, groupID string, limit int) (results []*types.Node, scores []float64, err error) {
+	ctx, done := traceBranch(ctx, "nodes", groupID)
+	defer func() { done(len(results), err) }()
+
 	// Pre-allocate with capacity for search methods (may add BFS results too)
 	searchResults := make([][]*types.Node, 0, len(config.SearchMethods)+1)
 	var bfsOriginNodes []string
@@ -268,7 +301,9 @@ func (s *Searcher) searchNodes(ctx context.Context, query string, queryVector []
 			GroupIDs:      []string{groupID},
 		}
 
-		bfsNodes, err := searchUtils.NodeBFSSearch(ctx, bfsOriginNodes, bfsOptions)
+		bfsCtx, bfsDone := traceBranch(ctx, "node_bfs", groupID)
+		bfsNodes, err := searchUtils.NodeBFSSearch(bfsCtx, bfsOriginNodes, bfsOptions)
+		bfsDone(len(bfsNodes), err)
 		if err != nil {
 			return nil, nil, fmt.Errorf("BFS node search failed: %w", err)
 		}
@@ -281,7 +316,10 @@ func (s *Searcher) searchNodes(ctx context.Context, query string, queryVector []
 	return s.rerankNodes(ctx, query, queryVector, searchResults, config, limit)
 }
 
-func (s *Searcher) searchEdges(ctx context.Context, query string, queryVector []float32, config *EdgeSearchConfig, filters *SearchFilters, groupID string, limit int) ([]*types.Edge, []float64, error) {
+func (s *Searcher) searchEdges(ctx context.Context, query string, queryVector []float32, config *EdgeSearchConfig, filters *SearchFilters, groupID string, limit int) (results []*types.Edge, scores []float64, err error) {
+	ctx, done := traceBranch(ctx, "edges", groupID)
+	defer func() { done(len(results), err) }()
+
 	// Pre-allocate with capacity for search methods (may add BFS results too)
 	searchResults := make([][]*types.Edge, 0, len(config.SearchMethods)+1)
 	var bfsOriginNodes []string
@@ -344,7 +382,9 @@ func (s *Searcher) searchEdges(ctx context.Context, query string, queryVector []
 			GroupIDs:      []string{groupID},
 		}
 
-		bfsEdges, err := searchUtils.EdgeBFSSearch(ctx, bfsOriginNodes, bfsOptions)
+		bfsCtx, bfsDone := traceBranch(ctx, "edge_bfs", groupID)
+		bfsEdges, err := searchUtils.EdgeBFSSearch(bfsCtx, bfsOriginNodes, bfsOptions)
+		bfsDone(len(bfsEdges), err)
 		if err != nil {
 			return nil, nil, fmt.Errorf("BFS edge search failed: %w", err)
 		}
@@ -353,11 +393,20 @@ func (s *Searcher) searchEdges(ctx context.Context, query string, queryVector []
 		}
 	}
 
+	if filters != nil && filters.MinConfidence > 0 {
+		for i, results := range searchResults {
+			searchResults[i] = FilterEdgesByConfidence(results, filters.MinConfidence)
+		}
+	}
+
 	// Combine and rerank results
 	return s.rerankEdges(ctx, query, queryVector, searchResults, config, limit)
 }
 
-func (s *Searcher) nodeFulltextSearch(ctx context.Context, query string, filters *SearchFilters, groupID string, limit int) ([]*types.Node, error) {
+func (s *Searcher) nodeFulltextSearch(ctx context.Context, query string, filters *SearchFilters, groupID string, limit int) (nodes []*types.Node, err error) {
+	ctx, done := traceBranch(ctx, "node_bm25", groupID)
+	defer func() { done(len(nodes), err) }()
+
 	// This would use the driver's fulltext search capabilities
 	// For now, return a basic implementation
 	return s.driver.SearchNodes(ctx, query, groupID, &driver.SearchOptions{
@@ -367,7 +416,10 @@ func (s *Searcher) nodeFulltextSearch(ctx context.Context, query string, filters
 	})
 }
 
-func (s *Searcher) nodeSimilaritySearch(ctx context.Context, queryVector []float32, filters *SearchFilters, groupID string, limit int, minScore float64) ([]*types.Node, error) {
+func (s *Searcher) nodeSimilaritySearch(ctx context.Context, queryVector []float32, filters *SearchFilters, groupID string, limit int, minScore float64) (nodes []*types.Node, err error) {
+	ctx, done := traceBranch(ctx, "node_cosine", groupID)
+	defer func() { done(len(nodes), err) }()
+
 	// This would use vector similarity search
 	return s.driver.SearchNodesByVector(ctx, queryVector, groupID, &driver.VectorSearchOptions{
 		Limit:     limit,
@@ -376,7 +428,10 @@ func (s *Searcher) nodeSimilaritySearch(ctx context.Context, queryVector []float
 	})
 }
 
-func (s *Searcher) edgeFulltextSearch(ctx context.Context, query string, filters *SearchFilters, groupID string, limit int) ([]*types.Edge, error) {
+func (s *Searcher) edgeFulltextSearch(ctx context.Context, query string, filters *SearchFilters, groupID string, limit int) (edges []*types.Edge, err error) {
+	ctx, done := traceBranch(ctx, "edge_bm25", groupID)
+	defer func() { done(len(edges), err) }()
+
 	return s.driver.SearchEdges(ctx, query, groupID, &driver.SearchOptions{
 		Limit:       limit,
 		UseFullText: true,
@@ -384,7 +439,10 @@ func (s *Searcher) edgeFulltextSearch(ctx context.Context, query string, filters
 	})
 }
 
-func (s *Searcher) edgeSimilaritySearch(ctx context.Context, queryVector []float32, filters *SearchFilters, groupID string, limit int, minScore float64) ([]*types.Edge, error) {
+func (s *Searcher) edgeSimilaritySearch(ctx context.Context, queryVector []float32, filters *SearchFilters, groupID string, limit int, minScore float64) (edges []*types.Edge, err error) {
+	ctx, done := traceBranch(ctx, "edge_cosine", groupID)
+	defer func() { done(len(edges), err) }()
+
 	return s.driver.SearchEdgesByVector(ctx, queryVector, groupID, &driver.VectorSearchOptions{
 		Limit:     limit,
 		MinScore:  minScore,
@@ -392,10 +450,12 @@ func (s *Searcher) edgeSimilaritySearch(ctx context.Context, queryVector []float
 	})
 }
 
-func (s *Searcher) rerankNodes(ctx context.Context, query string, queryVector []float32, searchResults [][]*types.Node, config *NodeSearchConfig, limit int) ([]*types.Node, []float64, error) {
+func (s *Searcher) rerankNodes(ctx context.Context, query string, queryVector []float32, searchResults [][]*types.Node, config *NodeSearchConfig, limit int) (reranked []*types.Node, scores []float64, err error) {
 	if len(searchResults) == 0 {
 		return []*types.Node{}, []float64{}, nil
 	}
+	ctx, done := traceBranch(ctx, "node_rerank", "")
+	defer func() { done(len(reranked), err) }()
 
 	// Create node map for deduplication
 	nodeMap := make(map[string]*types.Node)
@@ -427,10 +487,12 @@ func (s *Searcher) rerankNodes(ctx context.Context, query string, queryVector []
 	}
 }
 
-func (s *Searcher) rerankEdges(ctx context.Context, query string, queryVector []float32, searchResults [][]*types.Edge, config *EdgeSearchConfig, limit int) ([]*types.Edge, []float64, error) {
+func (s *Searcher) rerankEdges(ctx context.Context, query string, queryVector []float32, searchResults [][]*types.Edge, config *EdgeSearchConfig, limit int) (reranked []*types.Edge, scores []float64, err error) {
 	if len(searchResults) == 0 {
 		return []*types.Edge{}, []float64{}, nil
 	}
+	ctx, done := traceBranch(ctx, "edge_rerank", "")
+	defer func() { done(len(reranked), err) }()
 
 	// Create edge map for deduplication
 	edgeMap := make(map[string]*types.Edge)
@@ -447,9 +509,9 @@ func (s *Searcher) rerankEdges(ctx context.Context, query string, queryVector []
 
 	switch config.Reranker {
 	case RRFRerankType:
-		return s.rrfRerankEdges(searchResults, limit)
+		return s.rrfRerankEdges(searchResults, config.ConfidenceWeight, limit)
 	case MMRRerankType:
-		return s.mmrRerankEdges(ctx, queryVector, edges, config.MMRLambda, config.MinScore, limit)
+		return s.mmrRerankEdges(ctx, queryVector, edges, config.MMRLambda, config.MinScore, config.ConfidenceWeight, limit)
 	case CrossEncoderRerankType:
 		return s.crossEncoderRerankEdges(ctx, query, edges, config.MinScore, limit)
 	default:
@@ -508,46 +570,29 @@ func (s *Searcher) rrfRerankNodes(searchResults [][]*types.Node, limit int) ([]*
 	return nodes, scores, nil
 }
 
-func (s *Searcher) rrfRerankEdges(searchResults [][]*types.Edge, limit int) ([]*types.Edge, []float64, error) {
-	scoreMap := make(map[string]float64)
+func (s *Searcher) rrfRerankEdges(searchResults [][]*types.Edge, confidenceWeight float64, limit int) ([]*types.Edge, []float64, error) {
 	edgeMap := make(map[string]*types.Edge)
+	rankedUUIDs := make([][]string, 0, len(searchResults))
 
 	for _, results := range searchResults {
-		for rank, edge := range results {
-			if _, exists := scoreMap[edge.Uuid]; !exists {
-				scoreMap[edge.Uuid] = 0
-			}
-			// RRF formula: 1 / (rank + k), where k is typically 60
-			scoreMap[edge.Uuid] += 1.0 / float64(rank+60)
+		uuids := make([]string, 0, len(results))
+		for _, edge := range results {
+			uuids = append(uuids, edge.Uuid)
 			edgeMap[edge.Uuid] = edge
 		}
+		rankedUUIDs = append(rankedUUIDs, uuids)
 	}
 
-	// Sort by score
-	type edgeScore struct {
-		edge  *types.Edge
-		score float64
-	}
-
-	edgeScores := make([]edgeScore, 0, len(scoreMap))
-	for id, score := range scoreMap {
-		edgeScores = append(edgeScores, edgeScore{
-			edge:  edgeMap[id],
-			score: score,
-		})
-	}
-
-	sort.Slice(edgeScores, func(i, j int) bool {
-		return edgeScores[i].score > edgeScores[j].score
-	})
+	// RRF formula: 1 / (rank + k), scaled by edge confidence
+	uuids, rrfScores := ConfidenceRRF(rankedUUIDs, edgeConfidences(edgeMap), resolveConfidenceWeight(confidenceWeight), DefaultRankConstant, 0)
 
 	// Extract results
-	edges := make([]*types.Edge, 0, min(limit, len(edgeScores)))
-	scores := make([]float64, 0, min(limit, len(edgeScores)))
+	edges := make([]*types.Edge, 0, min(limit, len(uuids)))
+	scores := make([]float64, 0, min(limit, len(uuids)))
 
-	for i := 0; i < min(limit, len(edgeScores)); i++ {
-		edges = append(edges, edgeScores[i].edge)
-		scores = append(scores, edgeScores[i].score)
+	for i := 0; i < min(limit, len(uuids)); i++ {
+		edges = append(edges, edgeMap[uuids[i]])
+		scores = append(scores, rrfScores[i])
 	}
 
 	return edges, scores, nil
@@ -600,7 +645,7 @@ func (s *Searcher) mmrRerankNodes(ctx context.Context, queryVector []float32, no
 	return resultNodes, resultScores, nil
 }
 
-func (s *Searcher) mmrRerankEdges(ctx context.Context, queryVector []float32, edges []*types.Edge, lambda float64, minScore float64, limit int) ([]*types.Edge, []float64, error) {
+func (s *Searcher) mmrRerankEdges(ctx context.Context, queryVector []float32, edges []*types.Edge, lambda float64, minScore float64, confidenceWeight float64, limit int) ([]*types.Edge, []float64, error) {
 	if len(queryVector) == 0 {
 		return edges[:min(limit, len(edges))], make([]float64, min(limit, len(edges))), nil
 	}
@@ -621,14 +666,14 @@ func (s *Searcher) mmrRerankEdges(ctx context.Context, queryVector []float32, ed
 	}
 
 	// Apply MMR reranking
-	mmrUUIDs, mmrScores := MaximalMarginalRelevance(queryVector, embeddings, lambda, minScore)
-
 	// Create edge map for lookup
 	edgeMap := make(map[string]*types.Edge)
 	for _, edge := range edges {
 		edgeMap[edge.Uuid] = edge
 	}
 
+	mmrUUIDs, mmrScores := ConfidenceMMR(queryVector, embeddings, edgeConfidences(edgeMap), resolveConfidenceWeight(confidenceWeight), lambda, minScore)
+
 	// Build result arrays based on MMR ranking
 	var resultEdges []*types.Edge
 	var resultScores []float64
@@ -677,7 +677,7 @@ func TestRRFRerankEdges(t *testing.T) {
 
 	searchResults := [][]*types.Edge{results1, results2}
 
-	edges, scores, err := searcher.rrfRerankEdges(searchResults, 10)
+	edges, scores, err := searcher.rrfRerankEdges(searchResults, 0, 10)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -694,6 +694,88 @@ func TestRRFRerankEdges(t *testing.T) {
 	}
 }
 
+func TestRRFRerankEdgesConfidence(t *testing.T) {
+	searcher := NewSearcher(NewMockGraphDriver(), NewMockEmbedder(), nil)
+
+	// edge1 ranks first but is barely trusted; full confidence weighting
+	// should push it below the corroborated edge2.
+	results := []*types.Edge{
+		{BaseEdge: types.BaseEdge{Uuid: "edge1"}, Confidence: 0.1},
+		{BaseEdge: types.BaseEdge{Uuid: "edge2"}, Confidence: 0.95},
+	}
+
+	edges, _, err := searcher.rrfRerankEdges([][]*types.Edge{results}, 1.0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(edges) != 2 || edges[0].Uuid != "edge2" {
+		t.Errorf("expected edge2 first, got %v", edges)
+	}
+
+	edges, _, err = searcher.rrfRerankEdges([][]*types.Edge{results}, -1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if edges[0].Uuid != "edge1" {
+		t.Errorf("expected rank order with confidence disabled, got %s first", edges[0].Uuid)
+	}
+}
+
+func TestFilterEdgesByConfidence(t *testing.T) {
+	edges := []*types.Edge{
+		{BaseEdge: types.BaseEdge{Uuid: "low"}, Confidence: 0.2},
+		{BaseEdge: types.BaseEdge{Uuid: "high"}, Confidence: 0.8},
+		{BaseEdge: types.BaseEdge{Uuid: "unscored"}},
+	}
+
+	filtered := FilterEdgesByConfidence(edges, 0.5)
+	if len(filtered) != 2 {
+		t.Fatalf("expected 2 edges, got %d", len(filtered))
+	}
+	for _, edge := range filtered {
+		if edge.Uuid == "low" {
+			t.Error("low confidence edge should have been filtered")
+		}
+	}
+
+	if got := FilterEdgesByConfidence(edges, 0); len(got) != len(edges) {
+		t.Errorf("expected no filtering at 0, got %d edges", len(got))
+	}
+}
+
+func TestSortEdgesByGroupWeightedScore(t *testing.T) {
+	weights := map[string]float64{"user": 1.0, "org": 0.5}
+	edges := []*types.Edge{
+		{BaseEdge: types.BaseEdge{Uuid: "org-edge", GroupID: "org"}},
+		{BaseEdge: types.BaseEdge{Uuid: "user-edge", GroupID: "user"}},
+		{BaseEdge: types.BaseEdge{Uuid: "other-edge", GroupID: "other"}},
+	}
+	merger := NewGroupMerger(weights, func(e *types.Edge) string { return e.Uuid })
+	merger.Add("org", edges[:1], []float64{0.9})
+	merger.Add("user", edges[1:2], []float64{0.6})
+	merger.Add("other", edges[2:], []float64{0.2})
+	// An edge seen again in a lower-weighted group keeps its best score
+	merger.Add("org", edges[1:2], []float64{0.6})
+
+	sorted, sortedScores := merger.Results(2)
+	if len(sorted) != 2 {
+		t.Fatalf("expected 2 edges after limit, got %d", len(sorted))
+	}
+	if sorted[0].Uuid != "user-edge" || sorted[1].Uuid != "org-edge" {
+		t.Errorf("unexpected order: %s, %s", sorted[0].Uuid, sorted[1].Uuid)
+	}
+	if sortedScores[0] < sortedScores[1] {
+		t.Error("scores should be in descending order")
+	}
+}
+
+func TestUniqueGroupIDs(t *testing.T) {
+	got := uniqueGroupIDs([]string{"user", "", "org", "user"})
+	if len(got) != 2 || got[0] != "user" || got[1] != "org" {
+		t.Errorf("unexpected group IDs: %v", got)
+	}
+}
+
 func TestSearchWithLimit(t *testing.T) {
 	mockDriver := NewMockGraphDriver()
 	mockEmbedder := NewMockEmbedder()
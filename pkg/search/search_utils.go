@@ -20,6 +20,8 @@ const (
 	MaxSearchDepth      = 3
 	MaxQueryLength      = 128
 	DefaultRankConstant = 60
+	// DefaultConfidenceWeight is how strongly edge confidence scales reranked scores
+	DefaultConfidenceWeight = 0.5
 )
 
 // SearchUtilities provides utility functions for graph search operations
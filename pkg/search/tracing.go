@@ -0,0 +1,17 @@
+package search
+
+import (
+	"context"
+
+	"github.com/soundprediction/predicato/pkg/telemetry"
+)
+
+// traceBranch starts a span for a search branch, e.g. "node_bm25". The
+// returned function ends it with the number of results and records the
+// branch's latency.
+func traceBranch(ctx context.Context, branch, groupID string) (context.Context, func(count int, err error)) {
+	ctx, end := telemetry.StartStep(ctx, "search."+branch, telemetry.AttrGroupID.String(groupID))
+	return ctx, func(count int, err error) {
+		end(err, telemetry.AttrCount.Int(count))
+	}
+}
@@ -46,15 +46,27 @@ type Result struct {
 
 // FactResult represents a fact result from the knowledge graph
 type FactResult struct {
-	UUID         string     `json:"uuid"`
-	Fact         string     `json:"fact"`
-	SourceName   string     `json:"source_name"`
-	TargetName   string     `json:"target_name"`
-	RelationType string     `json:"relation_type"`
-	ValidAt      *time.Time `json:"valid_at,omitempty"`
-	InvalidAt    *time.Time `json:"invalid_at,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	Score        *float64   `json:"score,omitempty"`
+	UUID          string     `json:"uuid"`
+	GroupID       string     `json:"group_id,omitempty"`
+	Fact          string     `json:"fact"`
+	SourceName    string     `json:"source_name"`
+	TargetName    string     `json:"target_name"`
+	RelationType  string     `json:"relation_type"`
+	ValidAt       *time.Time `json:"valid_at,omitempty"`
+	InvalidAt     *time.Time `json:"invalid_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Score         *float64   `json:"score,omitempty"`
+	Confidence    float64    `json:"confidence,omitempty"`
+	Corroboration int        `json:"corroboration,omitempty"`
+	Citations     []Citation `json:"citations,omitempty"`
+}
+
+// Citation points to the span of an episode that supports a fact
+type Citation struct {
+	EpisodeUUID string `json:"episode_uuid"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Snippet     string `json:"snippet"`
 }
 
 // ErrorResponse represents an error response
@@ -3,6 +3,7 @@ package dto
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -16,6 +17,7 @@ var (
 	ErrNameTooLong       = errors.New("name exceeds maximum length (1024)")
 	ErrContentTooLong    = errors.New("content exceeds maximum length (1MB)")
 	ErrInvalidCharacters = errors.New("field contains invalid characters")
+	ErrInvalidCallback   = errors.New("callback_url must be an absolute http or https URL")
 )
 
 // MaxFieldLengths defines maximum lengths for fields to prevent abuse
@@ -33,6 +35,8 @@ type AddMessagesRequest struct {
 	GroupID   string     `json:"group_id" binding:"required"`
 	Messages  []Message  `json:"messages" binding:"required,dive"`
 	Reference *time.Time `json:"reference,omitempty"`
+	// CallbackURL is notified with an IngestionWebhook when processing finishes
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 
 // Validate performs validation on AddMessagesRequest
@@ -54,6 +58,18 @@ func (r *AddMessagesRequest) Validate() error {
 			return fmt.Errorf("message %d: %w", i, err)
 		}
 	}
+	if r.CallbackURL != "" {
+		return ValidateCallbackURL(r.CallbackURL)
+	}
+	return nil
+}
+
+// ValidateCallbackURL checks that a callback URL is an absolute http or https URL
+func ValidateCallbackURL(callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ErrInvalidCallback
+	}
 	return nil
 }
 
@@ -103,6 +119,25 @@ func (r *ClearDataRequest) Validate() error {
 	return nil
 }
 
+// Webhook event names
+const (
+	WebhookEventIngestionCompleted = "ingestion.completed"
+	WebhookEventIngestionFailed    = "ingestion.failed"
+)
+
+// IngestionWebhook is the payload delivered to webhooks when an asynchronous
+// ingestion finishes
+type IngestionWebhook struct {
+	Event        string    `json:"event"`
+	ProcessID    string    `json:"process_id"`
+	GroupID      string    `json:"group_id"`
+	EpisodeUUIDs []string  `json:"episode_uuids"`
+	EntityCount  int       `json:"entity_count"`
+	EdgeCount    int       `json:"edge_count"`
+	Error        string    `json:"error,omitempty"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
 // IngestResponse represents a response from ingest operations
 type IngestResponse struct {
 	Success   bool   `json:"success"`
@@ -4,9 +4,11 @@ import "time"
 
 // SearchQuery represents a search query request
 type SearchQuery struct {
-	Query    string   `json:"query" binding:"required"`
-	GroupIDs []string `json:"group_ids,omitempty"`
-	MaxFacts int      `json:"max_facts,omitempty"`
+	Query         string             `json:"query" binding:"required"`
+	GroupIDs      []string           `json:"group_ids,omitempty"`
+	GroupWeights  map[string]float64 `json:"group_weights,omitempty"`
+	MaxFacts      int                `json:"max_facts,omitempty"`
+	MinConfidence float64            `json:"min_confidence,omitempty"`
 }
 
 // SearchResults represents search results
@@ -17,9 +19,10 @@ type SearchResults struct {
 
 // GetMemoryRequest represents a request to get memory
 type GetMemoryRequest struct {
-	Messages []Message `json:"messages" binding:"required"`
-	GroupIDs []string  `json:"group_ids,omitempty"`
-	MaxFacts int       `json:"max_facts,omitempty"`
+	Messages     []Message          `json:"messages" binding:"required"`
+	GroupIDs     []string           `json:"group_ids,omitempty"`
+	GroupWeights map[string]float64 `json:"group_weights,omitempty"`
+	MaxFacts     int                `json:"max_facts,omitempty"`
 }
 
 // GetMemoryResponse represents a memory response
@@ -12,12 +12,15 @@ import (
 
 	"github.com/soundprediction/predicato"
 	"github.com/soundprediction/predicato/pkg/server/dto"
+	"github.com/soundprediction/predicato/pkg/server/webhooks"
 	"github.com/soundprediction/predicato/pkg/types"
 )
 
 // IngestHandler handles data ingestion requests
 type IngestHandler struct {
-	predicato predicato.Predicato
+	predicato     predicato.Predicato
+	webhooks      *webhooks.Dispatcher
+	preprocessors []predicato.EpisodePreprocessor
 }
 
 // NewIngestHandler creates a new ingest handler
@@ -27,6 +30,18 @@ func NewIngestHandler(g predicato.Predicato) *IngestHandler {
 	}
 }
 
+// SetWebhooks sets the dispatcher notified when asynchronous ingestion finishes
+func (h *IngestHandler) SetWebhooks(d *webhooks.Dispatcher) {
+	h.webhooks = d
+}
+
+// SetPreprocessors sets the preprocessors, e.g. a pii.Redactor, run on every
+// ingested episode. While any are set the raw message content is not kept in
+// the episode metadata.
+func (h *IngestHandler) SetPreprocessors(preprocessors ...predicato.EpisodePreprocessor) {
+	h.preprocessors = preprocessors
+}
+
 // generateProcessID generates a unique process ID for tracking async operations
 func generateProcessID() string {
 	bytes := make([]byte, 8)
@@ -66,6 +81,17 @@ func (h *IngestHandler) AddMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.CallbackURL != "" {
+		if h.webhooks == nil {
+			writeErrorJSON(w, http.StatusBadRequest, "invalid_request", "callback_url is not supported: webhooks are not enabled")
+			return
+		}
+		if err := h.webhooks.CheckCallbackURL(req.CallbackURL); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+	}
+
 	// Generate a process ID for tracking this async operation
 	processID := generateProcessID()
 
@@ -104,6 +130,16 @@ func (h *IngestHandler) AddMessages(w http.ResponseWriter, r *http.Request) {
 				episodeTime = *msg.Timestamp
 			}
 
+			metadata := map[string]interface{}{
+				"role":       msg.Role,
+				"source":     "api_ingest",
+				"process_id": processID,
+			}
+			// Keeping the raw content would bypass the preprocessors' redaction
+			if len(h.preprocessors) == 0 {
+				metadata["original_content"] = msg.Content
+			}
+
 			episode := types.Episode{
 				ID:        episodeID,
 				Name:      episodeName,
@@ -111,24 +147,28 @@ func (h *IngestHandler) AddMessages(w http.ResponseWriter, r *http.Request) {
 				Reference: episodeTime,
 				CreatedAt: time.Now(),
 				GroupID:   req.GroupID,
-				Metadata: map[string]interface{}{
-					"role":             msg.Role,
-					"original_content": msg.Content,
-					"source":           "api_ingest",
-					"process_id":       processID,
-				},
+				Metadata:  metadata,
 			}
 
 			episodes = append(episodes, episode)
 		}
 
 		// Add episodes to predicato
-		if _, err := h.predicato.Add(ctx, episodes, nil); err != nil {
+		var options *predicato.AddEpisodeOptions
+		if len(h.preprocessors) > 0 {
+			options = &predicato.AddEpisodeOptions{Preprocessors: h.preprocessors}
+		}
+		results, err := h.predicato.Add(ctx, episodes, options)
+		if err != nil {
 			// Log error but don't fail the entire request since it's async
 			log.Printf("[%s] Error adding episodes to predicato for group %s: %v\n", processID, req.GroupID, err)
 		} else {
 			log.Printf("[%s] Successfully processed %d episodes for group %s\n", processID, len(episodes), req.GroupID)
 		}
+
+		if h.webhooks != nil {
+			h.webhooks.Notify(ctx, ingestionWebhook(processID, req.GroupID, results, err), req.CallbackURL)
+		}
 	}()
 
 	writeJSON(w, http.StatusAccepted, dto.IngestResponse{
@@ -279,3 +319,28 @@ func (h *IngestHandler) ClearData(w http.ResponseWriter, r *http.Request) {
 		Message: message,
 	})
 }
+
+// ingestionWebhook builds the webhook payload for the outcome of an ingestion
+func ingestionWebhook(processID, groupID string, results *types.AddBulkEpisodeResults, err error) dto.IngestionWebhook {
+	payload := dto.IngestionWebhook{
+		Event:        dto.WebhookEventIngestionCompleted,
+		ProcessID:    processID,
+		GroupID:      groupID,
+		EpisodeUUIDs: []string{},
+		CompletedAt:  time.Now().UTC(),
+	}
+	if err != nil {
+		payload.Event = dto.WebhookEventIngestionFailed
+		payload.Error = err.Error()
+	}
+	if results != nil {
+		for _, episode := range results.Episodes {
+			if episode != nil {
+				payload.EpisodeUUIDs = append(payload.EpisodeUUIDs, episode.Uuid)
+			}
+		}
+		payload.EntityCount = len(results.Nodes)
+		payload.EdgeCount = len(results.Edges)
+	}
+	return payload
+}
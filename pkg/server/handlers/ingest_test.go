@@ -2,12 +2,16 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/soundprediction/predicato"
 	"github.com/soundprediction/predicato/pkg/server/dto"
+	"github.com/soundprediction/predicato/pkg/types"
 )
 
 func TestGenerateProcessID(t *testing.T) {
@@ -92,6 +96,16 @@ func TestAddMessagesValidation(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "invalid_request",
 		},
+		{
+			name: "callback_url without webhooks",
+			body: dto.AddMessagesRequest{
+				GroupID:     "test-group",
+				Messages:    []dto.Message{{Role: "user", Content: "test"}},
+				CallbackURL: "https://example.com/done",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
 	}
 
 	for _, tt := range tests {
@@ -267,3 +281,84 @@ func TestClearDataValidation(t *testing.T) {
 		})
 	}
 }
+
+// recordingPredicato captures the episodes passed to Add.
+type recordingPredicato struct {
+	predicato.Predicato
+	added chan addCall
+}
+
+type addCall struct {
+	episodes []types.Episode
+	options  *predicato.AddEpisodeOptions
+}
+
+func (r *recordingPredicato) Add(ctx context.Context, episodes []types.Episode, options *predicato.AddEpisodeOptions) (*types.AddBulkEpisodeResults, error) {
+	r.added <- addCall{episodes: episodes, options: options}
+	return &types.AddBulkEpisodeResults{}, nil
+}
+
+type noopPreprocessor struct{}
+
+func (noopPreprocessor) PreprocessEpisode(ctx context.Context, episode *types.Episode) error {
+	return nil
+}
+
+func TestAddMessagesOriginalContent(t *testing.T) {
+	tests := []struct {
+		name          string
+		preprocessors []predicato.EpisodePreprocessor
+		wantOriginal  bool
+	}{
+		{name: "without preprocessors", wantOriginal: true},
+		{name: "with preprocessors", preprocessors: []predicato.EpisodePreprocessor{noopPreprocessor{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &recordingPredicato{added: make(chan addCall, 1)}
+			handler := NewIngestHandler(fake)
+			handler.SetPreprocessors(tt.preprocessors...)
+
+			body, _ := json.Marshal(dto.AddMessagesRequest{
+				GroupID:  "g",
+				Messages: []dto.Message{{Role: "user", Content: "mail jane@example.org"}},
+			})
+			req := httptest.NewRequest(http.MethodPost, "/ingest/messages", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.AddMessages(w, req)
+			if w.Code != http.StatusAccepted {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusAccepted)
+			}
+
+			var call addCall
+			select {
+			case call = <-fake.added:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Add was not called")
+			}
+			_, hasOriginal := call.episodes[0].Metadata["original_content"]
+			if hasOriginal != tt.wantOriginal {
+				t.Errorf("original_content present = %v, want %v", hasOriginal, tt.wantOriginal)
+			}
+			if got := call.options != nil && len(call.options.Preprocessors) > 0; got != (len(tt.preprocessors) > 0) {
+				t.Errorf("preprocessors passed = %v", got)
+			}
+		})
+	}
+}
+
+func TestAddMessagesRejectsCallbackWithoutWebhooks(t *testing.T) {
+	handler := NewIngestHandler(nil)
+	body, _ := json.Marshal(dto.AddMessagesRequest{
+		GroupID:     "g",
+		Messages:    []dto.Message{{Role: "user", Content: "hello"}},
+		CallbackURL: "https://callbacks.example.com/done",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/ingest/messages", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.AddMessages(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -25,6 +26,16 @@ func NewRetrieveHandler(g predicato.Predicato) *RetrieveHandler {
 	}
 }
 
+// writeSearchError reports a failed search, mapping group permission errors
+// to 403 Forbidden.
+func writeSearchError(w http.ResponseWriter, errCode string, err error) {
+	if errors.Is(err, predicato.ErrGroupNotAllowed) {
+		writeErrorJSON(w, http.StatusForbidden, "group_not_allowed", err.Error())
+		return
+	}
+	writeErrorJSON(w, http.StatusInternalServerError, errCode, err.Error())
+}
+
 // Search handles POST /search
 func (h *RetrieveHandler) Search(w http.ResponseWriter, r *http.Request) {
 	var req dto.SearchQuery
@@ -48,18 +59,19 @@ func (h *RetrieveHandler) Search(w http.ResponseWriter, r *http.Request) {
 
 	// Create search configuration
 	searchConfig := &types.SearchConfig{
-		Limit:        req.MaxFacts,
-		MinScore:     0.0,
-		IncludeEdges: true,
-		Rerank:       true,
+		Limit:         req.MaxFacts,
+		MinScore:      0.0,
+		MinConfidence: req.MinConfidence,
+		IncludeEdges:  true,
+		Rerank:        true,
+		GroupIDs:      req.GroupIDs,
+		GroupWeights:  req.GroupWeights,
 	}
 
 	// Perform the search using predicato
-	// Note: Group ID filtering would be implemented in the search configuration
-	// For now, we use the global search
 	searchResults, err := h.predicato.Search(ctx, req.Query, searchConfig)
 	if err != nil {
-		writeErrorJSON(w, http.StatusInternalServerError, "search_failed", err.Error())
+		writeSearchError(w, "search_failed", err)
 		return
 	}
 
@@ -70,6 +82,7 @@ func (h *RetrieveHandler) Search(w http.ResponseWriter, r *http.Request) {
 	for _, node := range searchResults.Nodes {
 		fact := dto.FactResult{
 			UUID:         node.Uuid,
+			GroupID:      node.GroupID,
 			Fact:         h.nodeToFactDescription(node),
 			SourceName:   node.Name,
 			TargetName:   "", // Nodes don't have targets
@@ -88,19 +101,30 @@ func (h *RetrieveHandler) Search(w http.ResponseWriter, r *http.Request) {
 	// Process edges as facts
 	for _, edge := range searchResults.Edges {
 		fact := dto.FactResult{
-			UUID:         edge.Uuid,
-			Fact:         h.edgeToFactDescription(edge),
-			SourceName:   edge.SourceID, // Could be enhanced to resolve actual names
-			TargetName:   edge.TargetID,
-			RelationType: string(edge.Type),
-			CreatedAt:    edge.CreatedAt,
-			ValidAt:      &edge.ValidFrom,
+			UUID:          edge.Uuid,
+			Fact:          h.edgeToFactDescription(edge),
+			SourceName:    edge.SourceID, // Could be enhanced to resolve actual names
+			TargetName:    edge.TargetID,
+			RelationType:  string(edge.Type),
+			CreatedAt:     edge.CreatedAt,
+			ValidAt:       &edge.ValidFrom,
+			Confidence:    edge.Confidence,
+			Corroboration: edge.Corroboration,
 		}
 
 		if edge.ValidTo != nil {
 			fact.InvalidAt = edge.ValidTo
 		}
 
+		for _, p := range types.EdgeProvenance(edge) {
+			fact.Citations = append(fact.Citations, dto.Citation{
+				EpisodeUUID: p.EpisodeID,
+				Start:       p.Start,
+				End:         p.End,
+				Snippet:     p.Text,
+			})
+		}
+
 		facts = append(facts, fact)
 	}
 
@@ -136,6 +160,7 @@ func (h *RetrieveHandler) GetEntityEdge(w http.ResponseWriter, r *http.Request)
 		// Convert node to fact format
 		fact := dto.FactResult{
 			UUID:         node.Uuid,
+			GroupID:      node.GroupID,
 			Fact:         h.nodeToFactDescription(node),
 			SourceName:   node.Name,
 			TargetName:   "",
@@ -276,12 +301,14 @@ func (h *RetrieveHandler) GetMemory(w http.ResponseWriter, r *http.Request) {
 		MinScore:     0.1, // Slightly higher threshold for memory relevance
 		IncludeEdges: true,
 		Rerank:       true,
+		GroupIDs:     req.GroupIDs,
+		GroupWeights: req.GroupWeights,
 	}
 
 	// Perform search using the combined query
 	searchResults, err := h.predicato.Search(ctx, combinedQuery, searchConfig)
 	if err != nil {
-		writeErrorJSON(w, http.StatusInternalServerError, "memory_retrieval_failed", err.Error())
+		writeSearchError(w, "memory_retrieval_failed", err)
 		return
 	}
 
@@ -293,6 +320,7 @@ func (h *RetrieveHandler) GetMemory(w http.ResponseWriter, r *http.Request) {
 		// Prioritize episodic nodes for memory retrieval
 		fact := dto.FactResult{
 			UUID:         node.Uuid,
+			GroupID:      node.GroupID,
 			Fact:         h.nodeToFactDescription(node),
 			SourceName:   node.Name,
 			TargetName:   "",
@@ -312,6 +340,7 @@ func (h *RetrieveHandler) GetMemory(w http.ResponseWriter, r *http.Request) {
 	for _, edge := range searchResults.Edges {
 		fact := dto.FactResult{
 			UUID:         edge.Uuid,
+			GroupID:      edge.GroupID,
 			Fact:         h.edgeToFactDescription(edge),
 			SourceName:   edge.SourceID,
 			TargetName:   edge.TargetID,
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,7 +13,10 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/soundprediction/predicato"
 	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/server/dto"
 	"github.com/soundprediction/predicato/pkg/server/handlers"
+	"github.com/soundprediction/predicato/pkg/server/webhooks"
+	"github.com/soundprediction/predicato/pkg/telemetry"
 	"github.com/soundprediction/predicato/pkg/types"
 )
 
@@ -21,6 +26,8 @@ type Server struct {
 	router    *chi.Mux
 	predicato predicato.Predicato
 	server    *http.Server
+
+	preprocessors []predicato.EpisodePreprocessor
 }
 
 // New creates a new server instance
@@ -31,6 +38,12 @@ func New(cfg *config.Config, predicatoClient predicato.Predicato) *Server {
 	}
 }
 
+// SetPreprocessors sets the preprocessors, e.g. a pii.Redactor, run on the
+// episodes ingested through the API. Call it before Setup.
+func (s *Server) SetPreprocessors(preprocessors ...predicato.EpisodePreprocessor) {
+	s.preprocessors = preprocessors
+}
+
 // Setup sets up the server routes and middleware
 func (s *Server) Setup() {
 	// Create router
@@ -43,11 +56,15 @@ func (s *Server) Setup() {
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(middleware.Timeout(60 * time.Second))
 	s.router.Use(corsMiddleware)
-	s.router.Use(contextMiddleware)
+	s.router.Use(s.contextMiddleware)
 
 	// Setup routes
 	s.setupRoutes()
 
+	if len(s.config.Server.UserGroups) > 0 && s.config.Server.ProxySecret == "" {
+		log.Println("server.user_groups is ignored without server.proxy_secret: X-User-ID is not authenticated")
+	}
+
 	// Create HTTP server
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
 	s.server = &http.Server{
@@ -61,6 +78,10 @@ func (s *Server) setupRoutes() {
 	// Create handlers
 	healthHandler := handlers.NewHealthHandler(s.predicato)
 	ingestHandler := handlers.NewIngestHandler(s.predicato)
+	if s.config.Webhooks.Enabled() {
+		ingestHandler.SetWebhooks(webhooks.New(s.config.Webhooks))
+	}
+	ingestHandler.SetPreprocessors(s.preprocessors...)
 	retrieveHandler := handlers.NewRetrieveHandler(s.predicato)
 
 	// Health endpoints
@@ -70,6 +91,9 @@ func (s *Server) setupRoutes() {
 	s.router.Get("/live", healthHandler.LivenessCheck) // Kubernetes liveness probe
 	s.router.Get("/health/detailed", healthHandler.DetailedHealthCheck)
 
+	// Prometheus metrics
+	s.router.Handle("/metrics", telemetry.MetricsHandler())
+
 	// API v1 routes
 	s.router.Route("/api/v1", func(r chi.Router) {
 		// Ingest routes
@@ -105,6 +129,12 @@ func (s *Server) Stop(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
+// fromProxy reports whether r carries the proxy secret.
+func (s *Server) fromProxy(r *http.Request) bool {
+	secret := r.Header.Get("X-Proxy-Secret")
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(s.config.Server.ProxySecret)) == 1
+}
+
 // corsMiddleware adds CORS headers
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -122,12 +152,29 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// contextMiddleware extracts context information from headers
-func contextMiddleware(next http.Handler) http.Handler {
+// contextMiddleware extracts context information from headers and sets the
+// groups the caller may search.
+//
+// The server does not authenticate users itself. X-User-ID is trusted only
+// from an authenticating proxy that proves itself with the configured proxy
+// secret; a request that names a user without the secret is rejected, and
+// without a configured secret the header is ignored.
+func (s *Server) contextMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		userID := r.Header.Get("X-User-ID")
+		if s.config.Server.ProxySecret == "" {
+			userID = ""
+		} else if userID != "" && !s.fromProxy(r) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(dto.ErrorResponse{
+				Error:   "unauthorized",
+				Message: "X-User-ID is only accepted from the authenticating proxy",
+			})
+			return
+		}
 		if userID != "" {
 			ctx = context.WithValue(ctx, types.ContextKeyUserID, userID)
 		}
@@ -138,6 +185,7 @@ func contextMiddleware(next http.Handler) http.Handler {
 		}
 
 		ctx = context.WithValue(ctx, types.ContextKeyRequestSource, "server")
+		ctx = predicato.WithAllowedGroupIDs(ctx, s.config.Server.AllowedGroupsFor(userID))
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -3,9 +3,11 @@ package server
 import (
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/types"
 )
 
 func TestNew(t *testing.T) {
@@ -321,3 +323,106 @@ func TestCORSHeaders(t *testing.T) {
 		}
 	}
 }
+
+func TestContextMiddlewareSetsAllowedGroups(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			AllowedGroupIDs: []string{"org"},
+			UserGroups:      map[string][]string{"alice": {"team-a"}},
+			ProxySecret:     "proxy-secret",
+		},
+	}
+	server := New(cfg, nil)
+
+	var got []string
+	handler := server.contextMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = r.Context().Value(types.ContextKeyAllowedGroupIDs).([]string)
+	}))
+
+	req := httptest.NewRequest("POST", "/search", nil)
+	req.Header.Set("X-User-ID", "alice")
+	req.Header.Set("X-Proxy-Secret", "proxy-secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if len(got) != 2 || got[0] != "org" || got[1] != "team-a" {
+		t.Errorf("alice's allowed groups = %v, want [org team-a]", got)
+	}
+
+	req = httptest.NewRequest("POST", "/search", nil)
+	req.Header.Set("X-User-ID", "bob")
+	req.Header.Set("X-Proxy-Secret", "proxy-secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if len(got) != 1 || got[0] != "org" {
+		t.Errorf("bob's allowed groups = %v, want [org]", got)
+	}
+}
+
+func TestContextMiddlewareRequiresProxyForUserID(t *testing.T) {
+	newHandler := func(secret string) (http.Handler, *[]string, *interface{}) {
+		server := New(&config.Config{
+			Server: config.ServerConfig{
+				AllowedGroupIDs: []string{"org"},
+				UserGroups:      map[string][]string{"alice": {"team-a"}},
+				ProxySecret:     secret,
+			},
+		}, nil)
+		var groups []string
+		var userID interface{}
+		handler := server.contextMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			groups, _ = r.Context().Value(types.ContextKeyAllowedGroupIDs).([]string)
+			userID = r.Context().Value(types.ContextKeyUserID)
+		}))
+		return handler, &groups, &userID
+	}
+
+	tests := []struct {
+		name        string
+		secret      string // configured proxy secret
+		userID      string
+		proxySecret string // X-Proxy-Secret sent
+		wantStatus  int
+		wantGroups  []string
+	}{
+		{"anonymous", "proxy-secret", "", "", http.StatusOK, []string{"org"}},
+		{"from proxy", "proxy-secret", "alice", "proxy-secret", http.StatusOK, []string{"org", "team-a"}},
+		{"missing proxy secret", "proxy-secret", "alice", "", http.StatusUnauthorized, nil},
+		{"forged proxy secret", "proxy-secret", "alice", "guess", http.StatusUnauthorized, nil},
+		{"no proxy configured", "", "alice", "", http.StatusOK, []string{"org"}},
+		{"no proxy configured, secret sent", "", "alice", "anything", http.StatusOK, []string{"org"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, groups, userID := newHandler(tt.secret)
+			req := httptest.NewRequest("POST", "/search", nil)
+			if tt.userID != "" {
+				req.Header.Set("X-User-ID", tt.userID)
+			}
+			if tt.proxySecret != "" {
+				req.Header.Set("X-Proxy-Secret", tt.proxySecret)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if *groups != nil {
+					t.Error("a rejected request reached the handler")
+				}
+				return
+			}
+			if !slices.Equal(*groups, tt.wantGroups) {
+				t.Errorf("allowed groups = %v, want %v", *groups, tt.wantGroups)
+			}
+			// Only a user ID vouched for by the proxy reaches the handler
+			wantUserID := ""
+			if len(tt.wantGroups) > 1 {
+				wantUserID = tt.userID
+			}
+			if got, _ := (*userID).(string); got != wantUserID {
+				t.Errorf("user ID in context = %q, want %q", got, wantUserID)
+			}
+		})
+	}
+}
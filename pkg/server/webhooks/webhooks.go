@@ -0,0 +1,318 @@
+// Package webhooks delivers ingestion outcomes from the server to HTTP
+// endpoints. Deliveries are signed with HMAC-SHA256, retried with exponential
+// backoff, and recorded in a JSONL delivery log.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/server/dto"
+)
+
+// Request headers set on every delivery
+const (
+	HeaderEvent     = "X-Predicato-Event"
+	HeaderDelivery  = "X-Predicato-Delivery"
+	HeaderTimestamp = "X-Predicato-Timestamp"
+	HeaderSignature = "X-Predicato-Signature"
+)
+
+// maxBackoff caps the delay between delivery attempts
+const maxBackoff = time.Minute
+
+// Target is an endpoint a payload is delivered to
+type Target struct {
+	URL    string
+	Secret string
+	// Callback marks a request's callback_url, which is only delivered to
+	// public addresses
+	Callback bool
+}
+
+// DeliveryRecord is one entry of the delivery log
+type DeliveryRecord struct {
+	DeliveryID string    `json:"delivery_id"`
+	Time       time.Time `json:"time"`
+	URL        string    `json:"url"`
+	Event      string    `json:"event"`
+	ProcessID  string    `json:"process_id"`
+	GroupID    string    `json:"group_id"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Delivered  bool      `json:"delivered"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Dispatcher delivers webhook payloads to subscriptions and callback URLs
+type Dispatcher struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+	// callbackClient refuses to connect to private and loopback addresses
+	callbackClient *http.Client
+	maxAttempts    int
+	backoff        time.Duration
+	logMu          sync.Mutex
+}
+
+// New creates a dispatcher from the webhook configuration
+func New(cfg config.WebhookConfig) *Dispatcher {
+	d := &Dispatcher{
+		cfg:         cfg,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     time.Duration(cfg.InitialBackoff) * time.Second,
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = 5
+	}
+	if d.backoff <= 0 {
+		d.backoff = time.Second
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	d.client = &http.Client{Timeout: timeout}
+
+	// The address is checked when connecting, after DNS resolution and on
+	// every redirect. No proxy is used, so the check sees the real endpoint.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialPublicOnly,
+	}).DialContext
+	d.callbackClient = &http.Client{Timeout: timeout, Transport: transport}
+	return d
+}
+
+// CheckCallbackURL reports whether callbackURL may receive deliveries. Only
+// hosts listed in AllowedCallbackHosts are accepted, and never private or
+// loopback addresses.
+func (d *Dispatcher) CheckCallbackURL(callbackURL string) error {
+	if err := dto.ValidateCallbackURL(callbackURL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(callbackURL)
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && !isPublicIP(ip)) || strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("callback_url host %s is not a public address", host)
+	}
+	for _, allowed := range d.cfg.AllowedCallbackHosts {
+		if strings.EqualFold(host, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("callback_url host %s is not allowed", host)
+}
+
+// dialPublicOnly refuses connections to addresses that are not public
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("callback address %s is not a public address", host)
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip is routable on the public internet
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// Targets returns the endpoints to notify of an outcome in groupID: the
+// group's subscriptions plus callbackURL, if set
+func (d *Dispatcher) Targets(groupID, callbackURL string) []Target {
+	var targets []Target
+	seen := make(map[string]bool)
+	for _, sub := range d.cfg.Subscriptions {
+		if sub.URL == "" || (sub.GroupID != groupID && sub.GroupID != "*") || seen[sub.URL] {
+			continue
+		}
+		seen[sub.URL] = true
+		secret := sub.Secret
+		if secret == "" {
+			secret = d.cfg.Secret
+		}
+		targets = append(targets, Target{URL: sub.URL, Secret: secret})
+	}
+	if callbackURL != "" && !seen[callbackURL] {
+		targets = append(targets, Target{URL: callbackURL, Secret: d.cfg.Secret, Callback: true})
+	}
+	return targets
+}
+
+// Notify delivers payload to the group's subscriptions and callbackURL. It
+// blocks until every delivery has succeeded or run out of attempts.
+func (d *Dispatcher) Notify(ctx context.Context, payload dto.IngestionWebhook, callbackURL string) {
+	targets := d.Targets(payload.GroupID, callbackURL)
+	if len(targets) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("[%s] Failed to encode webhook payload: %v\n", payload.ProcessID, err)
+		return
+	}
+
+	for _, target := range targets {
+		record := d.deliver(ctx, target, payload.Event, body)
+		record.ProcessID = payload.ProcessID
+		record.GroupID = payload.GroupID
+		if !record.Delivered {
+			log.Printf("[%s] Webhook delivery to %s failed after %d attempts: %s\n", payload.ProcessID, target.URL, record.Attempts, record.Error)
+		}
+		if err := d.logDelivery(record); err != nil {
+			log.Printf("[%s] Failed to record webhook delivery: %v\n", payload.ProcessID, err)
+		}
+	}
+}
+
+// deliver posts body to target, retrying failed attempts with exponential backoff
+func (d *Dispatcher) deliver(ctx context.Context, target Target, event string, body []byte) DeliveryRecord {
+	record := DeliveryRecord{
+		DeliveryID: newDeliveryID(),
+		URL:        target.URL,
+		Event:      event,
+	}
+
+	backoff := d.backoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		record.Attempts = attempt
+		status, retry, err := d.post(ctx, target, record.DeliveryID, event, body)
+		record.StatusCode = status
+		if err == nil {
+			record.Delivered = true
+			record.Error = ""
+			break
+		}
+		record.Error = err.Error()
+		if !retry || attempt == d.maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			record.Error = ctx.Err().Error()
+			record.Time = time.Now().UTC()
+			return record
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+	record.Time = time.Now().UTC()
+	return record
+}
+
+// post makes one delivery attempt. It reports whether a failed attempt
+// should be retried: network errors, 429 and 5xx responses are retried,
+// other client errors are not.
+func (d *Dispatcher) post(ctx context.Context, target Target, deliveryID, event string, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "predicato-webhooks")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if target.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(target.Secret, timestamp, body))
+	}
+
+	client := d.client
+	if target.Callback {
+		client = d.callbackClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retry, fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+}
+
+// Sign returns the signature header value for a delivery: the hex-encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed by secret, prefixed with "sha256=".
+// Receivers recompute it from the X-Predicato-Timestamp header and the raw body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature of body
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// logDelivery appends record to the delivery log, if one is configured
+func (d *Dispatcher) logDelivery(record DeliveryRecord) error {
+	if d.cfg.DeliveryLogPath == "" {
+		return nil
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode delivery record: %w", err)
+	}
+
+	d.logMu.Lock()
+	defer d.logMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(d.cfg.DeliveryLogPath), 0755); err != nil {
+		return fmt.Errorf("failed to create delivery log directory: %w", err)
+	}
+	file, err := os.OpenFile(d.cfg.DeliveryLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open delivery log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write delivery log: %w", err)
+	}
+	return nil
+}
+
+// newDeliveryID generates a unique ID for a delivery
+func newDeliveryID() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Sprintf("dlv_%d", time.Now().UnixNano())
+	}
+	return "dlv_" + hex.EncodeToString(bytes)
+}
@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/config"
+	"github.com/soundprediction/predicato/pkg/server/dto"
+)
+
+func readDeliveryLog(t *testing.T, path string) []DeliveryRecord {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open delivery log: %v", err)
+	}
+	defer file.Close()
+
+	var records []DeliveryRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record DeliveryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("failed to parse delivery record: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestNotifySignsAndRetries(t *testing.T) {
+	var calls atomic.Int32
+	var received dto.IngestionWebhook
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify("sub-secret", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) {
+			t.Errorf("invalid signature %q", r.Header.Get(HeaderSignature))
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	logPath := filepath.Join(t.TempDir(), "deliveries.jsonl")
+	d := New(config.WebhookConfig{
+		Secret:          "global-secret",
+		Subscriptions:   []config.WebhookSubscription{{GroupID: "g1", URL: srv.URL, Secret: "sub-secret"}},
+		DeliveryLogPath: logPath,
+	})
+	d.backoff = time.Millisecond
+
+	d.Notify(context.Background(), dto.IngestionWebhook{
+		Event:        dto.WebhookEventIngestionCompleted,
+		ProcessID:    "proc_1",
+		GroupID:      "g1",
+		EpisodeUUIDs: []string{"ep-1"},
+		EntityCount:  2,
+		EdgeCount:    1,
+	}, "")
+
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+	if len(received.EpisodeUUIDs) != 1 || received.EntityCount != 2 || received.EdgeCount != 1 {
+		t.Errorf("unexpected payload: %+v", received)
+	}
+
+	records := readDeliveryLog(t, logPath)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 delivery record, got %d", len(records))
+	}
+	if !records[0].Delivered || records[0].Attempts != 3 || records[0].StatusCode != http.StatusNoContent || records[0].ProcessID != "proc_1" {
+		t.Errorf("unexpected delivery record: %+v", records[0])
+	}
+}
+
+func TestNotifyDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	logPath := filepath.Join(t.TempDir(), "deliveries.jsonl")
+	d := New(config.WebhookConfig{
+		Subscriptions:   []config.WebhookSubscription{{GroupID: "g1", URL: srv.URL}},
+		DeliveryLogPath: logPath,
+	})
+	d.backoff = time.Millisecond
+
+	d.Notify(context.Background(), dto.IngestionWebhook{Event: dto.WebhookEventIngestionFailed, GroupID: "g1", Error: "boom"}, "")
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 attempt, got %d", calls.Load())
+	}
+	records := readDeliveryLog(t, logPath)
+	if len(records) != 1 || records[0].Delivered || records[0].StatusCode != http.StatusBadRequest {
+		t.Errorf("unexpected delivery records: %+v", records)
+	}
+}
+
+func TestTargetsAndCallbackHosts(t *testing.T) {
+	d := New(config.WebhookConfig{
+		Secret: "global",
+		Subscriptions: []config.WebhookSubscription{
+			{GroupID: "g1", URL: "https://a.example.com/hook"},
+			{GroupID: "g2", URL: "https://b.example.com/hook"},
+			{GroupID: "*", URL: "https://all.example.com/hook", Secret: "all"},
+		},
+		AllowedCallbackHosts: []string{"callbacks.example.com"},
+	})
+
+	targets := d.Targets("g1", "https://callbacks.example.com/done")
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 targets, got %+v", targets)
+	}
+	if targets[0].Secret != "global" || targets[1].Secret != "all" || targets[2].URL != "https://callbacks.example.com/done" {
+		t.Errorf("unexpected targets: %+v", targets)
+	}
+
+	if err := d.CheckCallbackURL("https://callbacks.example.com/done"); err != nil {
+		t.Errorf("expected allowed host, got %v", err)
+	}
+	if err := d.CheckCallbackURL("https://evil.example.com/done"); err == nil {
+		t.Error("expected disallowed host to be rejected")
+	}
+	if err := d.CheckCallbackURL("ftp://callbacks.example.com/done"); err == nil {
+		t.Error("expected non-http scheme to be rejected")
+	}
+	if !targets[2].Callback || targets[0].Callback {
+		t.Errorf("only the callback_url target should be marked as a callback: %+v", targets)
+	}
+}
+
+func TestCallbackURLsAreDeniedByDefault(t *testing.T) {
+	d := New(config.WebhookConfig{})
+	if err := d.CheckCallbackURL("https://callbacks.example.com/done"); err == nil {
+		t.Error("expected callback_url to be rejected without allowed hosts")
+	}
+
+	d = New(config.WebhookConfig{AllowedCallbackHosts: []string{"127.0.0.1", "10.0.0.5", "localhost", "169.254.169.254", "::1"}})
+	for _, callbackURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://10.0.0.5/hook",
+		"http://localhost:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+	} {
+		if err := d.CheckCallbackURL(callbackURL); err == nil {
+			t.Errorf("expected %s to be rejected", callbackURL)
+		}
+	}
+}
+
+func TestCallbackDeliveryRefusesPrivateAddresses(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	logPath := filepath.Join(t.TempDir(), "deliveries.jsonl")
+	d := New(config.WebhookConfig{MaxAttempts: 1, DeliveryLogPath: logPath})
+
+	// The loopback address is refused when connecting, so a host name that
+	// resolves to one cannot slip past CheckCallbackURL
+	d.Notify(context.Background(), dto.IngestionWebhook{Event: dto.WebhookEventIngestionCompleted, GroupID: "g1"}, srv.URL)
+
+	if calls.Load() != 0 {
+		t.Fatalf("expected no request to reach the loopback server, got %d", calls.Load())
+	}
+	records := readDeliveryLog(t, logPath)
+	if len(records) != 1 || records[0].Delivered {
+		t.Errorf("unexpected delivery records: %+v", records)
+	}
+}
@@ -131,7 +131,7 @@ func (h *ParquetHandler) flush() error {
 		return nil
 	}
 
-	filename := fmt.Sprintf("execution_errors_%s_%d.parquet", time.Now().Format("20060102_150405"), time.Now().UnixNano())
+	filename := fmt.Sprintf("%s%s_%d.parquet", LogFilePrefix, time.Now().Format("20060102_150405"), time.Now().UnixNano())
 	filepath := filepath.Join(h.outputDir, filename)
 
 	err := parquet.WriteFile(filepath, h.buffer)
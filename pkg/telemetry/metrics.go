@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// Registry holds the Prometheus metrics recorded by predicato, together with
+// the Go runtime and process collectors. MetricsHandler serves it.
+var Registry = prometheus.NewRegistry()
+
+var (
+	llmRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "predicato_llm_request_duration_seconds",
+		Help:    "Latency of NLP model requests.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80},
+	}, []string{"model", "status"})
+
+	llmTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "predicato_llm_tokens_total",
+		Help: "Tokens used by NLP model requests.",
+	}, []string{"model", "kind"})
+
+	embedderRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "predicato_embedder_request_duration_seconds",
+		Help:    "Latency of embedding requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"model", "status"})
+
+	embeddedTexts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "predicato_embedder_texts_total",
+		Help: "Texts sent to the embedder.",
+	}, []string{"model"})
+
+	driverQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "predicato_driver_query_duration_seconds",
+		Help:    "Latency of graph database queries and transactions.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider", "operation", "status"})
+
+	ladybugWriteQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "predicato_ladybug_write_queue_depth",
+		Help: "Write operations waiting in the Ladybug write queue.",
+	})
+
+	circuitBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "predicato_circuit_breaker_state",
+		Help: "State of each NLP circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, []string{"name"})
+
+	pipelineStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "predicato_pipeline_step_duration_seconds",
+		Help:    "Latency of ingestion and search pipeline steps.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"step", "status"})
+)
+
+// Circuit breaker states reported by SetCircuitBreakerState. They match the
+// values of gobreaker.State.
+const (
+	CircuitClosed   = 0
+	CircuitHalfOpen = 1
+	CircuitOpen     = 2
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		llmRequestDuration,
+		llmTokens,
+		embedderRequestDuration,
+		embeddedTexts,
+		driverQueryDuration,
+		ladybugWriteQueueDepth,
+		circuitBreakerState,
+		pipelineStepDuration,
+	)
+}
+
+// MetricsHandler serves the metrics in Registry in the Prometheus text format.
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// status labels an observation by whether it failed.
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// ObserveLLMRequest records the latency of an NLP model request and the
+// tokens it used.
+func ObserveLLMRequest(model string, elapsed time.Duration, usage *types.TokenUsage, err error) {
+	llmRequestDuration.WithLabelValues(model, status(err)).Observe(elapsed.Seconds())
+	if usage != nil {
+		llmTokens.WithLabelValues(model, "prompt").Add(float64(usage.PromptTokens))
+		llmTokens.WithLabelValues(model, "completion").Add(float64(usage.CompletionTokens))
+	}
+}
+
+// ObserveEmbedderRequest records the latency of an embedding request for
+// texts texts.
+func ObserveEmbedderRequest(model string, texts int, elapsed time.Duration, err error) {
+	embedderRequestDuration.WithLabelValues(model, status(err)).Observe(elapsed.Seconds())
+	embeddedTexts.WithLabelValues(model).Add(float64(texts))
+}
+
+// ObserveDriverQuery records the latency of a graph database operation,
+// e.g. "query", "read" or "write".
+func ObserveDriverQuery(provider, operation string, elapsed time.Duration, err error) {
+	driverQueryDuration.WithLabelValues(provider, operation, status(err)).Observe(elapsed.Seconds())
+}
+
+// ObservePipelineStep records the latency of an ingestion or search step.
+func ObservePipelineStep(step string, elapsed time.Duration, err error) {
+	pipelineStepDuration.WithLabelValues(step, status(err)).Observe(elapsed.Seconds())
+}
+
+// AddLadybugWriteQueueDepth adjusts the Ladybug write queue depth by delta.
+func AddLadybugWriteQueueDepth(delta int) {
+	ladybugWriteQueueDepth.Add(float64(delta))
+}
+
+// SetCircuitBreakerState records the state of the named circuit breaker.
+func SetCircuitBreakerState(name string, state int) {
+	circuitBreakerState.WithLabelValues(name).Set(float64(state))
+}
@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	ObserveLLMRequest("test-model", 150*time.Millisecond, &types.TokenUsage{PromptTokens: 5, CompletionTokens: 2}, nil)
+	ObserveDriverQuery("neo4j", "query", time.Millisecond, errors.New("boom"))
+	SetCircuitBreakerState("test-breaker", CircuitOpen)
+	AddLadybugWriteQueueDepth(2)
+	AddLadybugWriteQueueDepth(-1)
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`predicato_llm_request_duration_seconds_count{model="test-model",status="ok"} 1`,
+		`predicato_llm_tokens_total{kind="prompt",model="test-model"} 5`,
+		`predicato_driver_query_duration_seconds_count{operation="query",provider="neo4j",status="error"} 1`,
+		`predicato_circuit_breaker_state{name="test-breaker"} 2`,
+		`predicato_ladybug_write_queue_depth 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package telemetry
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// LogFilePrefix starts the names of the Parquet files written by ParquetHandler.
+const LogFilePrefix = "execution_errors_"
+
+// TimeWindow selects records by timestamp. A zero Since or Until leaves that
+// side of the window open; Until is exclusive.
+type TimeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Contains reports whether t falls in the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && !t.Before(w.Until) {
+		return false
+	}
+	return true
+}
+
+// ReadParquetFiles reads the rows of every Parquet file in dir whose name
+// starts with prefix, in file name order. A missing dir holds no rows, and
+// columns missing from files written by older releases read as zero values.
+func ReadParquetFiles[T any](dir, prefix string) ([]T, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read telemetry directory: %w", err)
+	}
+
+	var rows []T
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".parquet") {
+			continue
+		}
+		fileRows, err := parquet.ReadFile[T](filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, nil
+}
+
+// ReadLogRecords reads the error logs that ParquetHandler wrote to dir within
+// window.
+func ReadLogRecords(dir string, window TimeWindow) ([]LogRecord, error) {
+	records, err := ReadParquetFiles[LogRecord](dir, LogFilePrefix)
+	if err != nil {
+		return nil, err
+	}
+	filtered := records[:0]
+	for _, record := range records {
+		if window.Contains(record.Timestamp) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+// ErrorSummary counts the error logs with the same message.
+type ErrorSummary struct {
+	Message   string    `json:"message"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Source and Attributes are those of the latest occurrence
+	Source     string `json:"source"`
+	Attributes string `json:"attributes"`
+}
+
+// TopErrors groups records by message and returns the n most frequent, most
+// recent first among equal counts. n <= 0 returns every message.
+func TopErrors(records []LogRecord, n int) []ErrorSummary {
+	byMessage := make(map[string]*ErrorSummary)
+	for _, record := range records {
+		summary, ok := byMessage[record.Message]
+		if !ok {
+			summary = &ErrorSummary{Message: record.Message, FirstSeen: record.Timestamp}
+			byMessage[record.Message] = summary
+		}
+		summary.Count++
+		if record.Timestamp.Before(summary.FirstSeen) {
+			summary.FirstSeen = record.Timestamp
+		}
+		if !record.Timestamp.Before(summary.LastSeen) {
+			summary.LastSeen = record.Timestamp
+			summary.Source = fmt.Sprintf("%s:%d", record.SourceFile, record.LineNumber)
+			summary.Attributes = record.Attributes
+		}
+	}
+
+	summaries := make([]ErrorSummary, 0, len(byMessage))
+	for _, summary := range byMessage {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		if !summaries[i].LastSeen.Equal(summaries[j].LastSeen) {
+			return summaries[i].LastSeen.After(summaries[j].LastSeen)
+		}
+		return summaries[i].Message < summaries[j].Message
+	})
+	if n > 0 && len(summaries) > n {
+		summaries = summaries[:n]
+	}
+	return summaries
+}
+
+// Percentiles returns the nearest-rank percentiles ps, from 0 to 100, of
+// values. It returns zeros when values is empty.
+func Percentiles(values []float64, ps ...float64) []float64 {
+	result := make([]float64, len(ps))
+	if len(values) == 0 {
+		return result
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	for i, p := range ps {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		rank = min(max(rank, 1), len(sorted))
+		result[i] = sorted[rank-1]
+	}
+	return result
+}
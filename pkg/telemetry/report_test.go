@@ -0,0 +1,64 @@
+package telemetry
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestReadLogRecordsAndTopErrors(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []LogRecord{
+		{ID: "1", Timestamp: base, Level: "ERROR", Message: "timeout", SourceFile: "a.go", LineNumber: 1},
+		{ID: "2", Timestamp: base.Add(time.Minute), Level: "ERROR", Message: "timeout", SourceFile: "a.go", LineNumber: 2, Attributes: `{"k":"v"}`},
+		{ID: "3", Timestamp: base.Add(2 * time.Minute), Level: "ERROR", Message: "bad json"},
+		{ID: "4", Timestamp: base.Add(-time.Hour), Level: "ERROR", Message: "old"},
+	}
+	if err := parquet.WriteFile(filepath.Join(dir, LogFilePrefix+"test.parquet"), records); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadLogRecords(dir, TimeWindow{Since: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 3 {
+		t.Fatalf("expected 3 records in window, got %d", len(read))
+	}
+
+	top := TopErrors(read, 1)
+	if len(top) != 1 {
+		t.Fatalf("expected 1 summary, got %d", len(top))
+	}
+	if top[0].Message != "timeout" || top[0].Count != 2 {
+		t.Errorf("expected timeout twice, got %q %d times", top[0].Message, top[0].Count)
+	}
+	if top[0].Source != "a.go:2" || top[0].Attributes != `{"k":"v"}` {
+		t.Errorf("expected the latest occurrence as sample, got %s %s", top[0].Source, top[0].Attributes)
+	}
+	if !top[0].FirstSeen.Equal(base) {
+		t.Errorf("expected first seen %v, got %v", base, top[0].FirstSeen)
+	}
+
+	missing, err := ReadLogRecords(filepath.Join(dir, "missing"), TimeWindow{})
+	if err != nil || len(missing) != 0 {
+		t.Errorf("expected no records from a missing directory, got %d, %v", len(missing), err)
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	got := Percentiles(values, 50, 90, 100)
+	want := []float64{5, 9, 10}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("percentile %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+	if got := Percentiles(nil, 50); got[0] != 0 {
+		t.Errorf("expected 0 for no values, got %v", got[0])
+	}
+}
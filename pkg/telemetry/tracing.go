@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans recorded by predicato.
+// Spans go to the global OpenTelemetry tracer provider, which is a no-op until
+// the application installs one with otel.SetTracerProvider.
+const TracerName = "github.com/soundprediction/predicato"
+
+// Span attribute keys shared by the instrumented packages.
+const (
+	AttrGroupID          = attribute.Key("predicato.group_id")
+	AttrEpisodeID        = attribute.Key("predicato.episode_id")
+	AttrModel            = attribute.Key("predicato.model")
+	AttrPromptTokens     = attribute.Key("predicato.tokens.prompt")
+	AttrCompletionTokens = attribute.Key("predicato.tokens.completion")
+	AttrTotalTokens      = attribute.Key("predicato.tokens.total")
+	AttrCount            = attribute.Key("predicato.count")
+	AttrDBSystem         = attribute.Key("db.system")
+)
+
+// StartSpan starts a span named name as a child of the span in ctx.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err, if any, on span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// StartStep starts a span for a pipeline step, e.g. "ingest.extraction". The
+// returned function adds any final attributes, records the step's latency in
+// the pipeline step metric and ends the span.
+func StartStep(ctx context.Context, step string, attrs ...attribute.KeyValue) (context.Context, func(err error, attrs ...attribute.KeyValue)) {
+	ctx, span := StartSpan(ctx, step, attrs...)
+	start := time.Now()
+	return ctx, func(err error, attrs ...attribute.KeyValue) {
+		span.SetAttributes(attrs...)
+		ObservePipelineStep(step, time.Since(start), err)
+		EndSpan(span, err)
+	}
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// MetadataKeyConfidence holds the edge confidence where a backend keeps it
+	// in the attributes blob rather than a dedicated property.
+	MetadataKeyConfidence = "confidence"
+
+	// MetadataKeyCorroboration holds the edge corroboration count alongside
+	// MetadataKeyConfidence.
+	MetadataKeyCorroboration = "corroboration"
+)
+
+// ParseConfidence parses a confidence score as emitted by an extractor,
+// clamping it to [0, 1]. It reports false for empty or unparsable values.
+func ParseConfidence(value string) (float64, bool) {
+	value = strings.Trim(strings.TrimSpace(value), "\"")
+	if value == "" || strings.EqualFold(value, "null") {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return clampConfidence(f), true
+}
+
+// CombineConfidence merges two independent confidence scores with a
+// noisy-OR, so agreeing evidence raises confidence without exceeding 1.
+// Unknown (zero) scores are ignored.
+func CombineConfidence(a, b float64) float64 {
+	a, b = clampConfidence(a), clampConfidence(b)
+	switch {
+	case a == 0:
+		return b
+	case b == 0:
+		return a
+	}
+	return 1 - (1-a)*(1-b)
+}
+
+// EffectiveConfidence returns the confidence used for ranking and filtering.
+// Edges without a recorded confidence are treated as fully confident so that
+// extractors which do not report scores are not penalised.
+func (e *EntityEdge) EffectiveConfidence() float64 {
+	if e == nil || e.Confidence <= 0 {
+		return 1.0
+	}
+	return clampConfidence(e.Confidence)
+}
+
+// Corroborate records that another episode supports the edge, bumping the
+// corroboration count and folding the new extraction's confidence in.
+func (e *EntityEdge) Corroborate(confidence float64) {
+	if e.Corroboration < 1 {
+		e.Corroboration = 1
+	}
+	e.Corroboration++
+	e.Confidence = CombineConfidence(e.Confidence, confidence)
+}
+
+// EdgeScoresFromAttributes reads confidence and corroboration from a decoded
+// attribute map.
+func EdgeScoresFromAttributes(attributes map[string]interface{}) (float64, int) {
+	var confidence float64
+	var corroboration int
+	if attributes == nil {
+		return confidence, corroboration
+	}
+	switch v := attributes[MetadataKeyConfidence].(type) {
+	case float64:
+		confidence = clampConfidence(v)
+	case float32:
+		confidence = clampConfidence(float64(v))
+	}
+	switch v := attributes[MetadataKeyCorroboration].(type) {
+	case float64:
+		corroboration = int(v)
+	case int64:
+		corroboration = int(v)
+	case int:
+		corroboration = v
+	}
+	return confidence, corroboration
+}
+
+func clampConfidence(f float64) float64 {
+	if f < 0 {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseConfidence(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in     string
+		want   float64
+		wantOK bool
+	}{
+		{in: "0.75", want: 0.75, wantOK: true},
+		{in: " \"0.5\" ", want: 0.5, wantOK: true},
+		{in: "1.7", want: 1, wantOK: true},
+		{in: "-0.2", want: 0, wantOK: true},
+		{in: "", wantOK: false},
+		{in: "null", wantOK: false},
+		{in: "high", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseConfidence(tt.in)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseConfidence(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCorroborate(t *testing.T) {
+	t.Parallel()
+	edge := &EntityEdge{Confidence: 0.6}
+	edge.Corroborate(0.5)
+
+	if edge.Corroboration != 2 {
+		t.Errorf("expected corroboration 2, got %d", edge.Corroboration)
+	}
+	if math.Abs(edge.Confidence-0.8) > 1e-9 {
+		t.Errorf("expected confidence 0.8, got %v", edge.Confidence)
+	}
+
+	unscored := &EntityEdge{}
+	if unscored.EffectiveConfidence() != 1.0 {
+		t.Errorf("expected unscored edge to be fully confident")
+	}
+	unscored.Corroborate(0)
+	if unscored.Confidence != 0 || unscored.Corroboration != 2 {
+		t.Errorf("unexpected unscored edge after corroboration: %+v", unscored)
+	}
+}
+
+func TestEdgeScoresFromAttributes(t *testing.T) {
+	t.Parallel()
+	confidence, corroboration := EdgeScoresFromAttributes(map[string]interface{}{
+		MetadataKeyConfidence:    0.9,
+		MetadataKeyCorroboration: float64(3),
+	})
+	if confidence != 0.9 || corroboration != 3 {
+		t.Errorf("got %v, %d", confidence, corroboration)
+	}
+}
@@ -7,6 +7,9 @@ const (
 	ContextKeySessionID       ContextKey = "session_id"
 	ContextKeyRequestSource   ContextKey = "request_source"
 	ContextKeyIngestionSource ContextKey = "ingestion_source"
+	ContextKeyGroupID         ContextKey = "group_id"
 	ContextKeySystemCall      ContextKey = "system_call"
 	ContextKeyUsage           ContextKey = "usage"
+	ContextKeyEnsembleStats   ContextKey = "ensemble_stats"
+	ContextKeyAllowedGroupIDs ContextKey = "allowed_group_ids"
 )
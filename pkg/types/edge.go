@@ -248,6 +248,11 @@ type EntityEdge struct {
 	ValidFrom time.Time  `json:"valid_from"`
 	ValidTo   *time.Time `json:"valid_to,omitempty"`
 	SourceIDs []string   `json:"source_ids,omitempty"`
+
+	// Confidence is the extraction confidence in [0, 1]; zero means it was not recorded.
+	Confidence float64 `json:"confidence,omitempty"`
+	// Corroboration is the number of distinct episodes supporting the edge.
+	Corroboration int `json:"corroboration,omitempty"`
 }
 
 // EdgeType represents the type of an edge for backward compatibility
@@ -485,6 +490,241 @@ func GetEntityEdgesBetweenNodes(ctx context.Context, driver EdgeOperations, sour
 	return edges, nil
 }
 
+// GetEntityEdgesByEpisode returns the entity edges whose supporting episodes
+// include episodeUUID, whether or not the episode created them.
+func GetEntityEdgesByEpisode(ctx context.Context, driver EdgeOperations, episodeUUID string) ([]*EntityEdge, error) {
+	var query string
+	if driver.Provider() == GraphProviderLadybug {
+		query = `
+			MATCH (n:Entity)-[:RELATES_TO]->(e:RelatesToNode_)-[:RELATES_TO]->(m:Entity)
+			WHERE list_contains(e.episodes, $episode_uuid)
+			RETURN e.uuid AS uuid, e.name AS name, e.fact AS fact, e.group_id AS group_id,
+			       e.episodes AS episodes, e.created_at AS created_at, e.expired_at AS expired_at,
+			       e.valid_at AS valid_at, e.invalid_at AS invalid_at, e.attributes AS attributes,
+			       n.uuid AS source_node_uuid, m.uuid AS target_node_uuid
+		`
+	} else {
+		query = `
+			MATCH (n:Entity)-[e:RELATES_TO]->(m:Entity)
+			WHERE $episode_uuid IN e.episodes
+			RETURN e.uuid AS uuid, e.name AS name, e.fact AS fact, e.group_id AS group_id,
+			       e.episodes AS episodes, e.created_at AS created_at, e.expired_at AS expired_at,
+			       e.valid_at AS valid_at, e.invalid_at AS invalid_at, e AS attributes,
+			       n.uuid AS source_node_uuid, m.uuid AS target_node_uuid
+		`
+	}
+
+	records, _, _, err := driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"episode_uuid": episodeUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var edges []*EntityEdge
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			edges = append(edges, buildEntityEdgeFromRecord(record, driver.Provider()))
+		}
+	}
+
+	return edges, nil
+}
+
+// GetEntityEdgesByNode returns the entity edges that have nodeUUID as their
+// source or target.
+func GetEntityEdgesByNode(ctx context.Context, driver EdgeOperations, nodeUUID string) ([]*EntityEdge, error) {
+	var query string
+	if driver.Provider() == GraphProviderLadybug {
+		query = `
+			MATCH (n:Entity)-[:RELATES_TO]->(e:RelatesToNode_)-[:RELATES_TO]->(m:Entity)
+			WHERE n.uuid = $node_uuid OR m.uuid = $node_uuid
+			RETURN e.uuid AS uuid, e.name AS name, e.fact AS fact, e.group_id AS group_id,
+			       e.episodes AS episodes, e.created_at AS created_at, e.expired_at AS expired_at,
+			       e.valid_at AS valid_at, e.invalid_at AS invalid_at, e.attributes AS attributes,
+			       n.uuid AS source_node_uuid, m.uuid AS target_node_uuid
+		`
+	} else {
+		query = `
+			MATCH (n:Entity)-[e:RELATES_TO]->(m:Entity)
+			WHERE n.uuid = $node_uuid OR m.uuid = $node_uuid
+			RETURN e.uuid AS uuid, e.name AS name, e.fact AS fact, e.group_id AS group_id,
+			       e.episodes AS episodes, e.created_at AS created_at, e.expired_at AS expired_at,
+			       e.valid_at AS valid_at, e.invalid_at AS invalid_at, e AS attributes,
+			       n.uuid AS source_node_uuid, m.uuid AS target_node_uuid
+		`
+	}
+
+	records, _, _, err := driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"node_uuid": nodeUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var edges []*EntityEdge
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			edges = append(edges, buildEntityEdgeFromRecord(record, driver.Provider()))
+		}
+	}
+
+	return edges, nil
+}
+
+// EpisodeSourceLink is a SOURCED_FROM edge from a source node to an episode.
+type EpisodeSourceLink struct {
+	SourceNodeUUID string
+	EdgeUUID       string
+	// OtherEpisodes is the number of other episodes sourced from the same node
+	OtherEpisodes int
+}
+
+// GetEpisodeSourceLinks returns the source nodes linked to an episode. On
+// Ladybug, where RELATES_TO only joins entities, a link is an edge node off
+// the source that names the episode in its episodes.
+func GetEpisodeSourceLinks(ctx context.Context, driver EdgeOperations, episodeUUID string) ([]EpisodeSourceLink, error) {
+	var query string
+	if driver.Provider() == GraphProviderLadybug {
+		query = `
+			MATCH (s:Entity)-[:RELATES_TO]->(r:RelatesToNode_)
+			WHERE r.name = 'SOURCED_FROM' AND list_contains(r.episodes, $episode_uuid)
+			OPTIONAL MATCH (s)-[:RELATES_TO]->(o:RelatesToNode_)
+			WHERE o.name = 'SOURCED_FROM' AND NOT list_contains(o.episodes, $episode_uuid)
+			RETURN s.uuid AS uuid, r.uuid AS edge_uuid, count(o) AS other_episodes
+		`
+	} else {
+		query = `
+			MATCH (s)-[r:RELATES_TO]->(e:Episodic {uuid: $episode_uuid})
+			WHERE r.name = 'SOURCED_FROM'
+			OPTIONAL MATCH (s)-[o:RELATES_TO]->(other:Episodic)
+			WHERE other.uuid <> $episode_uuid AND o.name = 'SOURCED_FROM'
+			RETURN s.uuid AS uuid, r.uuid AS edge_uuid, count(o) AS other_episodes
+		`
+	}
+
+	records, _, _, err := driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"episode_uuid": episodeUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	links := []EpisodeSourceLink{}
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			link := EpisodeSourceLink{}
+			link.SourceNodeUUID, _ = record["uuid"].(string)
+			link.EdgeUUID, _ = record["edge_uuid"].(string)
+			switch count := record["other_episodes"].(type) {
+			case int64:
+				link.OtherEpisodes = int(count)
+			case int:
+				link.OtherEpisodes = count
+			}
+			links = append(links, link)
+		}
+	}
+
+	return links, nil
+}
+
+// GetSourcedEpisodeUUIDs returns the UUIDs of the episodes linked to a source
+// node by SOURCED_FROM edges.
+func GetSourcedEpisodeUUIDs(ctx context.Context, driver EdgeOperations, sourceNodeUUID string) ([]string, error) {
+	var query string
+	if driver.Provider() == GraphProviderLadybug {
+		query = `
+			MATCH (s:Entity {uuid: $source_uuid})-[:RELATES_TO]->(r:RelatesToNode_)
+			WHERE r.name = 'SOURCED_FROM'
+			UNWIND r.episodes AS uuid
+			RETURN uuid
+		`
+	} else {
+		query = `
+			MATCH (s {uuid: $source_uuid})-[r:RELATES_TO]->(e:Episodic)
+			WHERE r.name = 'SOURCED_FROM'
+			RETURN e.uuid AS uuid
+		`
+	}
+
+	records, _, _, err := driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"source_uuid": sourceNodeUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uuids := []string{}
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			if uuid, ok := record["uuid"].(string); ok && uuid != "" {
+				uuids = append(uuids, uuid)
+			}
+		}
+	}
+
+	return uuids, nil
+}
+
+// RetractEntityEdgeEpisode removes an episode from the episodes supporting an entity edge.
+// If no supporting episodes remain, the edge is expired and invalidated at the given time
+// instead of being deleted, so its history stays queryable. Returns true if the edge was expired.
+func RetractEntityEdgeEpisode(ctx context.Context, driver EdgeOperations, edge *EntityEdge, episodeUUID string, at time.Time) (bool, error) {
+	remaining := make([]string, 0, len(edge.Episodes))
+	for _, ep := range edge.Episodes {
+		if ep != episodeUUID {
+			remaining = append(remaining, ep)
+		}
+	}
+	expire := len(remaining) == 0
+
+	params := map[string]interface{}{
+		"uuid": edge.Uuid,
+	}
+
+	var episodesClause string
+	if driver.Provider() == GraphProviderLadybug && len(remaining) == 0 {
+		episodesClause = "e.episodes = CAST([] AS STRING[])"
+	} else {
+		episodesClause = "e.episodes = $episodes"
+		params["episodes"] = remaining
+	}
+
+	expiryClause := ""
+	if expire {
+		expiryClause = ", e.expired_at = $expired_at, e.invalid_at = $invalid_at"
+		params["expired_at"] = at
+		params["invalid_at"] = at
+	}
+
+	var query string
+	if driver.Provider() == GraphProviderLadybug {
+		query = fmt.Sprintf(`
+			MATCH (e:RelatesToNode_ {uuid: $uuid})
+			SET %s%s
+		`, episodesClause, expiryClause)
+	} else {
+		query = fmt.Sprintf(`
+			MATCH (n:Entity)-[e:RELATES_TO {uuid: $uuid}]->(m:Entity)
+			SET %s%s
+		`, episodesClause, expiryClause)
+	}
+
+	if _, _, _, err := driver.ExecuteQuery(ctx, query, params); err != nil {
+		return false, err
+	}
+
+	edge.Episodes = remaining
+	if expire {
+		edge.ExpiredAt = &at
+		edge.InvalidAt = &at
+		edge.ValidTo = &at
+	}
+
+	return expire, nil
+}
+
 // CommunityEdge represents edges between communities and their members (equivalent to Python CommunityEdge)
 type CommunityEdge struct {
 	BaseEdge
@@ -618,6 +858,8 @@ func buildEntityEdgeFromRecord(record map[string]interface{}, provider GraphProv
 		invalidAt = &t
 	}
 
+	confidence, corroboration := EdgeScoresFromAttributes(attributes)
+
 	return &EntityEdge{
 		BaseEdge: BaseEdge{
 			Uuid:         record["uuid"].(string),
@@ -634,6 +876,8 @@ func buildEntityEdgeFromRecord(record map[string]interface{}, provider GraphProv
 		ValidAt:       validAt,
 		InvalidAt:     invalidAt,
 		Attributes:    attributes,
+		Confidence:    confidence,
+		Corroboration: corroboration,
 	}
 }
 
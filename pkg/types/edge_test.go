@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -220,3 +221,75 @@ func TestCommunityEdge(t *testing.T) {
 		t.Errorf("GetSourceNodeUUID() = %s, want community-uuid", edge.GetSourceNodeUUID())
 	}
 }
+
+// mockEdgeOperations implements EdgeOperations for testing
+type mockEdgeOperations struct {
+	provider GraphProvider
+	results  interface{}
+	queries  []string
+}
+
+func (m *mockEdgeOperations) ExecuteQuery(ctx context.Context, query string, params map[string]interface{}) (interface{}, interface{}, interface{}, error) {
+	m.queries = append(m.queries, query)
+	return m.results, nil, nil, nil
+}
+
+func (m *mockEdgeOperations) Provider() GraphProvider    { return m.provider }
+func (m *mockEdgeOperations) GetAossClient() interface{} { return nil }
+
+func TestGetEntityEdgesByEpisode(t *testing.T) {
+	for _, provider := range []GraphProvider{GraphProviderLadybug, GraphProviderNeo4j} {
+		mock := &mockEdgeOperations{
+			provider: provider,
+			results: []map[string]interface{}{
+				{
+					"uuid":             "edge-1",
+					"name":             "KNOWS",
+					"fact":             "n1 knows n2",
+					"created_at":       time.Now(),
+					"group_id":         "g",
+					"episodes":         []interface{}{"ep-1", "ep-2"},
+					"source_node_uuid": "n1",
+					"target_node_uuid": "n2",
+				},
+			},
+		}
+
+		edges, err := GetEntityEdgesByEpisode(context.Background(), mock, "ep-2")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", provider, err)
+		}
+		if len(edges) != 1 || edges[0].Uuid != "edge-1" || len(edges[0].Episodes) != 2 {
+			t.Errorf("%s: unexpected edges: %+v", provider, edges)
+		}
+	}
+}
+
+func TestGetEpisodeSourceLinks(t *testing.T) {
+	for _, provider := range []GraphProvider{GraphProviderLadybug, GraphProviderNeo4j} {
+		mock := &mockEdgeOperations{
+			provider: provider,
+			results:  []map[string]interface{}{{"uuid": "src-1", "edge_uuid": "edge-1", "other_episodes": int64(2)}},
+		}
+		links, err := GetEpisodeSourceLinks(context.Background(), mock, "ep-1")
+		if err != nil || len(links) != 1 || links[0].SourceNodeUUID != "src-1" || links[0].EdgeUUID != "edge-1" || links[0].OtherEpisodes != 2 {
+			t.Errorf("%s: unexpected links: %+v, %v", provider, links, err)
+		}
+		if len(mock.queries) != 1 {
+			t.Errorf("%s: got %d queries, want 1", provider, len(mock.queries))
+		}
+	}
+}
+
+func TestGetSourcedEpisodeUUIDs(t *testing.T) {
+	for _, provider := range []GraphProvider{GraphProviderLadybug, GraphProviderNeo4j} {
+		mock := &mockEdgeOperations{
+			provider: provider,
+			results:  []map[string]interface{}{{"uuid": "ep-1"}, {"uuid": "ep-2"}},
+		}
+		uuids, err := GetSourcedEpisodeUUIDs(context.Background(), mock, "src-1")
+		if err != nil || len(uuids) != 2 || uuids[0] != "ep-1" {
+			t.Errorf("%s: unexpected episodes: %v, %v", provider, uuids, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmbeddingSpace identifies the embedding model that produced the stored
+// vectors of a graph or fact store. Vectors from different spaces cannot be
+// compared, even when they have the same number of dimensions.
+type EmbeddingSpace struct {
+	// Model identifies the embedding model, e.g. "openai/text-embedding-3-small".
+	Model string `json:"model"`
+	// Dimensions is the size of the vectors.
+	Dimensions int       `json:"dimensions"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+}
+
+// Matches reports whether vectors of s and other can be compared. Unknown
+// fields (an empty model or zero dimensions) match anything.
+func (s *EmbeddingSpace) Matches(other *EmbeddingSpace) bool {
+	if s == nil || other == nil {
+		return true
+	}
+	if s.Model != "" && other.Model != "" && s.Model != other.Model {
+		return false
+	}
+	if s.Dimensions > 0 && other.Dimensions > 0 && s.Dimensions != other.Dimensions {
+		return false
+	}
+	return true
+}
+
+// String returns the model and dimensions of the space.
+func (s *EmbeddingSpace) String() string {
+	if s == nil {
+		return "<none>"
+	}
+	model := s.Model
+	if model == "" {
+		model = "unknown model"
+	}
+	return fmt.Sprintf("%s (%d dimensions)", model, s.Dimensions)
+}
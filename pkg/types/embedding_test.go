@@ -0,0 +1,31 @@
+package types
+
+import "testing"
+
+func TestEmbeddingSpaceMatches(t *testing.T) {
+	t.Parallel()
+	openai := &EmbeddingSpace{Model: "openai/text-embedding-3-small", Dimensions: 1536}
+
+	tests := []struct {
+		name  string
+		other *EmbeddingSpace
+		want  bool
+	}{
+		{name: "same space", other: &EmbeddingSpace{Model: "openai/text-embedding-3-small", Dimensions: 1536}, want: true},
+		{name: "other model", other: &EmbeddingSpace{Model: "embedeverything/qwen3-embedding", Dimensions: 1536}, want: false},
+		{name: "other dimensions", other: &EmbeddingSpace{Model: "openai/text-embedding-3-small", Dimensions: 768}, want: false},
+		{name: "unknown model", other: &EmbeddingSpace{Dimensions: 1536}, want: true},
+		{name: "unknown model other dimensions", other: &EmbeddingSpace{Dimensions: 768}, want: false},
+		{name: "nil", other: nil, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := openai.Matches(tt.other); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.other, got, tt.want)
+			}
+			if got := tt.other.Matches(openai); got != tt.want {
+				t.Errorf("reverse Matches(%v) = %v, want %v", tt.other, got, tt.want)
+			}
+		})
+	}
+}
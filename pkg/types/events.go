@@ -0,0 +1,99 @@
+package types
+
+import "time"
+
+// GraphEventType is the kind of change recorded by a GraphEvent.
+type GraphEventType string
+
+const (
+	GraphEventCreated     GraphEventType = "created"
+	GraphEventUpdated     GraphEventType = "updated"
+	GraphEventInvalidated GraphEventType = "invalidated"
+	GraphEventDeleted     GraphEventType = "deleted"
+	// GraphEventCleared reports that every node, edge and community of
+	// GroupID was deleted, including any no other event named.
+	GraphEventCleared GraphEventType = "cleared"
+)
+
+// GraphObjectKind is the kind of graph object a GraphEvent is about.
+type GraphObjectKind string
+
+const (
+	GraphObjectNode      GraphObjectKind = "node"
+	GraphObjectEdge      GraphObjectKind = "edge"
+	GraphObjectCommunity GraphObjectKind = "community"
+	GraphObjectGroup     GraphObjectKind = "group"
+)
+
+// Operations that emit graph events, recorded in GraphEvent.Operation.
+const (
+	GraphOperationIngestion     = "ingestion"
+	GraphOperationAddTriplet    = "add_triplet"
+	GraphOperationRemoveEpisode = "remove_episode"
+	GraphOperationCommunities   = "communities"
+	GraphOperationClearGraph    = "clear_graph"
+	GraphOperationReprocess     = "reprocess"
+	GraphOperationPromotion     = "promotion"
+	GraphOperationPurge         = "purge"
+)
+
+// GraphEvent describes one change to a node, edge or community after it has
+// been written to the graph.
+type GraphEvent struct {
+	Type      GraphEventType  `json:"type"`
+	Kind      GraphObjectKind `json:"kind"`
+	UUID      string          `json:"uuid"`
+	GroupID   string          `json:"group_id"`
+	Operation string          `json:"operation"`
+	// EpisodeUUID is the episode whose processing caused the change, if any.
+	EpisodeUUID string    `json:"episode_uuid,omitempty"`
+	Time        time.Time `json:"time"`
+
+	// Node or Edge holds the object as written, or as it was before deletion.
+	// Either may be nil for deletions where only the UUID is known.
+	Node *Node `json:"node,omitempty"`
+	Edge *Edge `json:"edge,omitempty"`
+}
+
+// NewNodeEvent returns an event for a change to node. Community nodes are
+// reported with kind GraphObjectCommunity.
+func NewNodeEvent(eventType GraphEventType, operation string, node *Node) GraphEvent {
+	kind := GraphObjectNode
+	if node.Type == CommunityNodeType {
+		kind = GraphObjectCommunity
+	}
+	return GraphEvent{
+		Type:      eventType,
+		Kind:      kind,
+		UUID:      node.Uuid,
+		GroupID:   node.GroupID,
+		Operation: operation,
+		Time:      time.Now().UTC(),
+		Node:      node,
+	}
+}
+
+// NewGroupClearedEvent returns the event for clearing every object of groupID.
+func NewGroupClearedEvent(operation, groupID string) GraphEvent {
+	return GraphEvent{
+		Type:      GraphEventCleared,
+		Kind:      GraphObjectGroup,
+		UUID:      groupID,
+		GroupID:   groupID,
+		Operation: operation,
+		Time:      time.Now().UTC(),
+	}
+}
+
+// NewEdgeEvent returns an event for a change to edge.
+func NewEdgeEvent(eventType GraphEventType, operation string, edge *Edge) GraphEvent {
+	return GraphEvent{
+		Type:      eventType,
+		Kind:      GraphObjectEdge,
+		UUID:      edge.Uuid,
+		GroupID:   edge.GroupID,
+		Operation: operation,
+		Time:      time.Now().UTC(),
+		Edge:      edge,
+	}
+}
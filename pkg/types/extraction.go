@@ -17,6 +17,9 @@ type ExtractedNode struct {
 	Embedding   []float32 `json:"embedding"`
 	ChunkIndex  int       `json:"chunk_index"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	// Span locates the entity mention in the source content, if known.
+	Span *Span `json:"span,omitempty"`
 }
 
 // ExtractedEdge represents a raw relationship extracted from a source.
@@ -34,6 +37,12 @@ type ExtractedEdge struct {
 	Weight         float64   `json:"weight"`
 	ChunkIndex     int       `json:"chunk_index"`
 	CreatedAt      time.Time `json:"created_at"`
+
+	// Confidence is the extraction confidence in [0, 1]; zero means it was not recorded.
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// Span locates the evidence for the relationship in the source content, if known.
+	Span *Span `json:"span,omitempty"`
 }
 
 // ExtractionResults is returned when ExtractOnly=true in AddEpisodeOptions.
@@ -72,6 +81,80 @@ type ExtractionMetadata struct {
 
 	// TotalTokens is the estimated token count processed
 	TotalTokens int `json:"total_tokens,omitempty"`
+
+	// Ensemble reports how the extractors of an ensemble agreed, if one was used
+	Ensemble *EnsembleStats `json:"ensemble,omitempty"`
+}
+
+// EnsembleStats summarizes the entity extraction of an ensemble of extractors.
+type EnsembleStats struct {
+	// Sources holds the agreement of each extractor with the others
+	Sources []ExtractorAgreement `json:"sources"`
+
+	// Candidates is the number of distinct entities proposed
+	Candidates int `json:"candidates"`
+
+	// Unanimous is the number of candidates proposed by every extractor
+	Unanimous int `json:"unanimous"`
+
+	// Adjudicated is the number of disputed candidates decided by an LLM
+	Adjudicated int `json:"adjudicated"`
+
+	// Accepted is the number of candidates kept
+	Accepted int `json:"accepted"`
+}
+
+// ExtractorAgreement reports how one extractor of an ensemble agreed with the others.
+type ExtractorAgreement struct {
+	Source string `json:"source"`
+
+	// Extracted is the number of entities the extractor proposed
+	Extracted int `json:"extracted"`
+
+	// Agreed is the number of those that another extractor also proposed
+	Agreed int `json:"agreed"`
+
+	// Accepted is the number of those that were kept
+	Accepted int `json:"accepted"`
+
+	// Failures is the number of calls that returned an error
+	Failures int `json:"failures,omitempty"`
+}
+
+// AgreementRate returns the fraction of the extractor's entities that
+// another extractor also proposed.
+func (a ExtractorAgreement) AgreementRate() float64 {
+	if a.Extracted == 0 {
+		return 0
+	}
+	return float64(a.Agreed) / float64(a.Extracted)
+}
+
+// Merge adds the counts of other to s, matching sources by name.
+func (s *EnsembleStats) Merge(other *EnsembleStats) {
+	if other == nil {
+		return
+	}
+	s.Candidates += other.Candidates
+	s.Unanimous += other.Unanimous
+	s.Adjudicated += other.Adjudicated
+	s.Accepted += other.Accepted
+	for _, source := range other.Sources {
+		merged := false
+		for i := range s.Sources {
+			if s.Sources[i].Source == source.Source {
+				s.Sources[i].Extracted += source.Extracted
+				s.Sources[i].Agreed += source.Agreed
+				s.Sources[i].Accepted += source.Accepted
+				s.Sources[i].Failures += source.Failures
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			s.Sources = append(s.Sources, source)
+		}
+	}
 }
 
 // NodeCount returns the number of extracted nodes.
@@ -172,6 +172,123 @@ func GetMentionedNodes(ctx context.Context, driver NodeOperations, episodes []*N
 	return nodes, nil
 }
 
+// CountOtherEpisodeMentions returns, for each of nodeUUIDs, the number of
+// episodes other than episodeUUID that mention it. Nodes mentioned by no other
+// episode are present with a count of zero.
+func CountOtherEpisodeMentions(ctx context.Context, driver NodeOperations, nodeUUIDs []string, episodeUUID string) (map[string]int, error) {
+	counts := make(map[string]int, len(nodeUUIDs))
+	if len(nodeUUIDs) == 0 {
+		return counts, nil
+	}
+	for _, uuid := range nodeUUIDs {
+		counts[uuid] = 0
+	}
+
+	query := `
+		MATCH (e:Episodic)-[:MENTIONS]->(n:Entity)
+		WHERE n.uuid IN $uuids AND e.uuid <> $episode_uuid
+		RETURN n.uuid AS uuid, count(e) AS episode_count
+	`
+
+	records, _, _, err := driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"uuids":        nodeUUIDs,
+		"episode_uuid": episodeUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			uuid, _ := record["uuid"].(string)
+			if _, ok := counts[uuid]; !ok {
+				continue
+			}
+			switch count := record["episode_count"].(type) {
+			case int64:
+				counts[uuid] = int(count)
+			case int:
+				counts[uuid] = count
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// GetMentioningEpisodes returns up to limit of the most recent episodes other
+// than excludeEpisodeUUID that mention the node. A limit of zero returns all of them.
+func GetMentioningEpisodes(ctx context.Context, driver NodeOperations, nodeUUID, excludeEpisodeUUID string, limit int) ([]*Node, error) {
+	query := `
+		MATCH (e:Episodic)-[:MENTIONS]->(n:Entity {uuid: $uuid})
+		WHERE e.uuid <> $episode_uuid
+		RETURN e.uuid AS uuid, e.name AS name, e.content AS content, e.group_id AS group_id, e.valid_at AS valid_at
+		ORDER BY e.valid_at DESC
+	`
+	params := map[string]interface{}{
+		"uuid":         nodeUUID,
+		"episode_uuid": excludeEpisodeUUID,
+	}
+	if limit > 0 {
+		query += "LIMIT $limit"
+		params["limit"] = int64(limit)
+	}
+
+	records, _, _, err := driver.ExecuteQuery(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var episodes []*Node
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			episode := &Node{Type: EpisodicNodeType}
+			episode.Uuid, _ = record["uuid"].(string)
+			episode.Name, _ = record["name"].(string)
+			episode.Content, _ = record["content"].(string)
+			episode.GroupID, _ = record["group_id"].(string)
+			if validAt, ok := record["valid_at"].(time.Time); ok {
+				episode.ValidFrom = validAt
+			}
+			episodes = append(episodes, episode)
+		}
+	}
+
+	return episodes, nil
+}
+
+// GetNodeCommunities returns the UUIDs of the communities that have any of
+// nodeUUIDs as a member.
+func GetNodeCommunities(ctx context.Context, driver NodeOperations, nodeUUIDs []string) ([]string, error) {
+	if len(nodeUUIDs) == 0 {
+		return []string{}, nil
+	}
+
+	query := `
+		MATCH (c:Community)-[:HAS_MEMBER]->(n:Entity)
+		WHERE n.uuid IN $uuids
+		RETURN DISTINCT c.uuid AS uuid
+	`
+
+	records, _, _, err := driver.ExecuteQuery(ctx, query, map[string]interface{}{
+		"uuids": nodeUUIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	communities := []string{}
+	if recordList, ok := records.([]map[string]interface{}); ok {
+		for _, record := range recordList {
+			if uuid, ok := record["uuid"].(string); ok {
+				communities = append(communities, uuid)
+			}
+		}
+	}
+
+	return communities, nil
+}
+
 // parseNodeFromMap converts a map to a Node
 func ParseNodeFromMap(data map[string]interface{}) (*Node, error) {
 	node := &Node{
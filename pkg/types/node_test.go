@@ -514,3 +514,31 @@ func TestConvertToFloat32Array(t *testing.T) {
 		}
 	})
 }
+
+func TestCountOtherEpisodeMentions(t *testing.T) {
+	t.Parallel()
+	mock := &mockNodeOperations{
+		results: []map[string]interface{}{
+			{"uuid": "node-1", "episode_count": int64(2)},
+		},
+	}
+
+	counts, err := CountOtherEpisodeMentions(context.Background(), mock, []string{"node-1", "node-2"}, "ep-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts["node-1"] != 2 {
+		t.Errorf("expected node-1 to have 2 other mentions, got %d", counts["node-1"])
+	}
+	if count, ok := counts["node-2"]; !ok || count != 0 {
+		t.Errorf("expected node-2 to have 0 other mentions, got %d (present=%v)", count, ok)
+	}
+	if mock.params[0]["episode_uuid"] != "ep-1" {
+		t.Errorf("expected episode_uuid param ep-1, got %v", mock.params[0]["episode_uuid"])
+	}
+
+	mock.execError = errors.New("query failed")
+	if _, err := CountOtherEpisodeMentions(context.Background(), mock, []string{"node-1"}, "ep-1"); err == nil {
+		t.Error("expected query error to be returned")
+	}
+}
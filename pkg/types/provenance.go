@@ -0,0 +1,252 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MetadataKeySpan holds the *Span an extracted node or edge was found at,
+	// relative to the text that was handed to the extractor.
+	MetadataKeySpan = "span"
+
+	// MetadataKeyProvenance holds the []Provenance entries supporting a graph edge.
+	MetadataKeyProvenance = "provenance"
+)
+
+// Span is a character range in a piece of source text. Offsets count Unicode
+// code points rather than bytes so they line up with GLiNER spans; End is exclusive.
+type Span struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text,omitempty"`
+}
+
+// IsValid reports whether the span covers at least one character.
+func (s *Span) IsValid() bool {
+	return s != nil && s.Start >= 0 && s.End > s.Start
+}
+
+// Shift returns a copy of the span moved by offset characters.
+func (s *Span) Shift(offset int) *Span {
+	if s == nil {
+		return nil
+	}
+	return &Span{Start: s.Start + offset, End: s.End + offset, Text: s.Text}
+}
+
+// Union returns the smallest span covering both s and other. The text of the
+// union is left empty since it cannot be derived without the source content.
+func (s *Span) Union(other *Span) *Span {
+	if !s.IsValid() {
+		return other
+	}
+	if !other.IsValid() {
+		return s
+	}
+	union := &Span{Start: s.Start, End: s.End}
+	if other.Start < union.Start {
+		union.Start = other.Start
+	}
+	if other.End > union.End {
+		union.End = other.End
+	}
+	return union
+}
+
+// Provenance ties a graph fact to the span of an episode that supports it.
+type Provenance struct {
+	EpisodeID string `json:"episode_id"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	Text      string `json:"text,omitempty"`
+}
+
+// Span returns the provenance range as a Span.
+func (p Provenance) Span() *Span {
+	return &Span{Start: p.Start, End: p.End, Text: p.Text}
+}
+
+// Citation is a provenance entry resolved for display alongside a search result.
+type Citation struct {
+	EdgeUUID  string `json:"edge_uuid"`
+	EpisodeID string `json:"episode_id"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	Snippet   string `json:"snippet"`
+}
+
+// NewSpan builds the span content[start:end], in characters, clamped to the
+// bounds of content. It returns nil when the resulting range is empty.
+func NewSpan(content string, start, end int) *Span {
+	runes := []rune(content)
+	if start < 0 {
+		start = 0
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+	if end <= start {
+		return nil
+	}
+	return &Span{Start: start, End: end, Text: string(runes[start:end])}
+}
+
+// SpanText returns the text covered by span in content, or "" if the span
+// does not fit.
+func SpanText(content string, span *Span) string {
+	if !span.IsValid() {
+		return ""
+	}
+	runes := []rune(content)
+	if span.End > len(runes) {
+		return ""
+	}
+	return string(runes[span.Start:span.End])
+}
+
+// LocateSpan finds quote in content and returns its span. An exact match is
+// preferred; otherwise a case-insensitive match is attempted. It returns nil
+// when the quote does not occur in content.
+func LocateSpan(content, quote string) *Span {
+	quote = strings.TrimSpace(quote)
+	if content == "" || quote == "" {
+		return nil
+	}
+
+	if idx := strings.Index(content, quote); idx >= 0 {
+		start := utf8.RuneCountInString(content[:idx])
+		return &Span{Start: start, End: start + utf8.RuneCountInString(quote), Text: quote}
+	}
+
+	// Case folding can change byte lengths, so compare rune by rune to keep
+	// the offsets aligned with the original content.
+	haystack := []rune(content)
+	needle := []rune(quote)
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		matched := true
+		for j, r := range needle {
+			if unicode.ToLower(haystack[i+j]) != unicode.ToLower(r) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return &Span{Start: i, End: i + len(needle), Text: string(haystack[i : i+len(needle)])}
+		}
+	}
+	return nil
+}
+
+// GetSpan reads the span stored under MetadataKeySpan. It accepts both the
+// in-memory form and the map produced by a JSON round trip.
+func GetSpan(metadata map[string]interface{}) *Span {
+	if metadata == nil {
+		return nil
+	}
+	switch v := metadata[MetadataKeySpan].(type) {
+	case *Span:
+		return v
+	case Span:
+		return &v
+	case nil:
+		return nil
+	default:
+		var span Span
+		if !decodeLoose(v, &span) || !span.IsValid() {
+			return nil
+		}
+		return &span
+	}
+}
+
+// EdgeProvenance returns the provenance entries recorded on an edge. Entries
+// may live in Metadata (Neo4j/Memgraph) or Attributes (Ladybug), either as
+// typed values or in their decoded JSON form.
+func EdgeProvenance(edge *EntityEdge) []Provenance {
+	if edge == nil {
+		return nil
+	}
+	for _, source := range []map[string]interface{}{edge.Metadata, edge.Attributes} {
+		if source == nil {
+			continue
+		}
+		switch v := source[MetadataKeyProvenance].(type) {
+		case nil:
+			continue
+		case []Provenance:
+			return v
+		default:
+			var entries []Provenance
+			if decodeLoose(v, &entries) {
+				return entries
+			}
+		}
+	}
+	return nil
+}
+
+// AddEdgeProvenance records entries on the edge metadata, skipping invalid
+// ranges and entries that are already present.
+func AddEdgeProvenance(edge *EntityEdge, entries ...Provenance) {
+	if edge == nil || len(entries) == 0 {
+		return
+	}
+	existing := EdgeProvenance(edge)
+	for _, entry := range entries {
+		if !entry.Span().IsValid() {
+			continue
+		}
+		duplicate := false
+		for _, e := range existing {
+			if e.EpisodeID == entry.EpisodeID && e.Start == entry.Start && e.End == entry.End {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			existing = append(existing, entry)
+		}
+	}
+	if len(existing) == 0 {
+		return
+	}
+	if edge.Metadata == nil {
+		edge.Metadata = make(map[string]interface{})
+	}
+	edge.Metadata[MetadataKeyProvenance] = existing
+}
+
+// EdgeCitations converts the provenance of each edge into citations.
+func EdgeCitations(edges []*EntityEdge) []*Citation {
+	var citations []*Citation
+	for _, edge := range edges {
+		for _, p := range EdgeProvenance(edge) {
+			citations = append(citations, &Citation{
+				EdgeUUID:  edge.Uuid,
+				EpisodeID: p.EpisodeID,
+				Start:     p.Start,
+				End:       p.End,
+				Snippet:   p.Text,
+			})
+		}
+	}
+	return citations
+}
+
+// decodeLoose decodes v into out via JSON, accepting either a JSON string or
+// an already-decoded value such as map[string]interface{}.
+func decodeLoose(v interface{}, out interface{}) bool {
+	var data []byte
+	if s, ok := v.(string); ok {
+		data = []byte(s)
+	} else {
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return false
+		}
+	}
+	return json.Unmarshal(data, out) == nil
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLocateSpan(t *testing.T) {
+	t.Parallel()
+	content := "Café owner Alice met Bob in Paris."
+
+	tests := []struct {
+		name      string
+		quote     string
+		wantStart int
+		wantEnd   int
+		wantText  string
+		wantNil   bool
+	}{
+		{name: "exact match after multibyte rune", quote: "Alice met Bob", wantStart: 11, wantEnd: 24, wantText: "Alice met Bob"},
+		{name: "case insensitive", quote: "alice MET bob", wantStart: 11, wantEnd: 24, wantText: "Alice met Bob"},
+		{name: "trims whitespace", quote: "  Paris ", wantStart: 28, wantEnd: 33, wantText: "Paris"},
+		{name: "missing", quote: "Berlin", wantNil: true},
+		{name: "empty", quote: "", wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := LocateSpan(content, tt.quote)
+			if tt.wantNil {
+				if span != nil {
+					t.Fatalf("expected nil span, got %+v", span)
+				}
+				return
+			}
+			if span == nil {
+				t.Fatal("expected span, got nil")
+			}
+			if span.Start != tt.wantStart || span.End != tt.wantEnd || span.Text != tt.wantText {
+				t.Errorf("got %+v, want {%d %d %q}", span, tt.wantStart, tt.wantEnd, tt.wantText)
+			}
+			if got := SpanText(content, span); got != tt.wantText {
+				t.Errorf("SpanText = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestNewSpanClamps(t *testing.T) {
+	t.Parallel()
+	span := NewSpan("héllo", -2, 10)
+	if span == nil || span.Start != 0 || span.End != 5 || span.Text != "héllo" {
+		t.Errorf("unexpected span %+v", span)
+	}
+	if NewSpan("hello", 3, 3) != nil {
+		t.Error("expected nil for empty range")
+	}
+}
+
+func TestSpanUnion(t *testing.T) {
+	t.Parallel()
+	a := &Span{Start: 10, End: 15}
+	b := &Span{Start: 2, End: 12}
+	u := a.Union(b)
+	if u.Start != 2 || u.End != 15 {
+		t.Errorf("unexpected union %+v", u)
+	}
+	if got := (*Span)(nil).Union(a); got != a {
+		t.Errorf("expected union with nil to return other span")
+	}
+}
+
+func TestGetSpanFromJSONMetadata(t *testing.T) {
+	t.Parallel()
+	original := map[string]interface{}{MetadataKeySpan: &Span{Start: 3, End: 8, Text: "hello"}}
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	span := GetSpan(decoded)
+	if span == nil || span.Start != 3 || span.End != 8 || span.Text != "hello" {
+		t.Errorf("unexpected span %+v", span)
+	}
+	if GetSpan(map[string]interface{}{}) != nil {
+		t.Error("expected nil span for empty metadata")
+	}
+}
+
+func TestAddEdgeProvenance(t *testing.T) {
+	t.Parallel()
+	edge := &EntityEdge{BaseEdge: BaseEdge{Uuid: "edge-1"}}
+
+	AddEdgeProvenance(edge,
+		Provenance{EpisodeID: "ep-1", Start: 0, End: 5, Text: "Alice"},
+		Provenance{EpisodeID: "ep-1", Start: 0, End: 5, Text: "Alice"},
+		Provenance{EpisodeID: "ep-2", Start: 4, End: 4},
+	)
+	AddEdgeProvenance(edge, Provenance{EpisodeID: "ep-2", Start: 7, End: 10, Text: "Bob"})
+
+	entries := EdgeProvenance(edge)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 provenance entries, got %d: %+v", len(entries), entries)
+	}
+
+	// Ladybug returns attributes as a JSON string.
+	data, err := json.Marshal(edge.Metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := &EntityEdge{BaseEdge: BaseEdge{Uuid: "edge-1"}, Attributes: attributes}
+
+	citations := EdgeCitations([]*EntityEdge{reloaded})
+	if len(citations) != 2 {
+		t.Fatalf("expected 2 citations, got %d", len(citations))
+	}
+	if citations[1].EpisodeID != "ep-2" || citations[1].Snippet != "Bob" || citations[1].EdgeUUID != "edge-1" {
+		t.Errorf("unexpected citation %+v", citations[1])
+	}
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedPlaceholder replaces redacted text.
+const RedactedPlaceholder = "[REDACTED]"
+
+// RedactTerms replaces every case-insensitive, whole-word occurrence of terms
+// in text with RedactedPlaceholder. It reports whether anything was replaced.
+func RedactTerms(text string, terms []string) (string, bool) {
+	var patterns []string
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term != "" {
+			patterns = append(patterns, regexp.QuoteMeta(term))
+		}
+	}
+	if text == "" || len(patterns) == 0 {
+		return text, false
+	}
+
+	// Longer terms first so that "Jane Doe" wins over "Jane"
+	sort.SliceStable(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+	re := regexp.MustCompile(`(?i)(^|\W)(` + strings.Join(patterns, "|") + `)(\W|$)`)
+
+	// Matches consume their trailing boundary, so a second pass catches
+	// occurrences separated by a single character
+	redacted := re.ReplaceAllString(text, "${1}"+RedactedPlaceholder+"${3}")
+	redacted = re.ReplaceAllString(redacted, "${1}"+RedactedPlaceholder+"${3}")
+	return redacted, redacted != text
+}
@@ -141,6 +141,9 @@ type SearchConfig struct {
 	CenterNodeDistance int
 	// MinScore is the minimum relevance score for results.
 	MinScore float64
+	// MinConfidence drops edges whose extraction confidence is below this
+	// threshold. Edges without a recorded confidence are always kept.
+	MinConfidence float64
 	// IncludeEdges determines if edges should be included in results.
 	IncludeEdges bool
 	// Rerank determines if results should be reranked.
@@ -151,6 +154,12 @@ type SearchConfig struct {
 	NodeConfig *NodeSearchConfig
 	// EdgeConfig holds configuration for edge search.
 	EdgeConfig *EdgeSearchConfig
+	// GroupIDs searches several groups at once and merges the results.
+	// Empty means the client's own group.
+	GroupIDs []string
+	// GroupWeights scales the scores of each group's results when merging.
+	// Groups not listed use a weight of 1.
+	GroupWeights map[string]float64
 }
 
 // Validate checks if the SearchConfig has valid values.
@@ -199,6 +208,9 @@ type EdgeSearchConfig struct {
 	Reranker string
 	// MinScore is the minimum score for results.
 	MinScore float64
+	// ConfidenceWeight controls how much edge confidence scales reranked
+	// scores. Zero uses the default weight; a negative value disables it.
+	ConfidenceWeight float64
 }
 
 // SearchFilters holds filters for search operations.
@@ -231,6 +243,8 @@ type SearchResults struct {
 	Query string
 	// Total number of results found (before limit).
 	Total int
+	// Citations link the returned edges to the episode text supporting them.
+	Citations []*Citation
 }
 
 // ExtractedEntity represents an entity extracted from content.
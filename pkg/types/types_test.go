@@ -387,3 +387,19 @@ func TestEpisodeTypes(t *testing.T) {
 		t.Errorf("EventEpisodeType = %s, want event", EventEpisodeType)
 	}
 }
+
+func TestRedactTerms(t *testing.T) {
+	text := "Jane Doe met Jane, not Janet. JANE DOE called jane."
+	redacted, changed := RedactTerms(text, []string{"Jane", "Jane Doe"})
+	if !changed {
+		t.Fatal("expected text to change")
+	}
+	want := "[REDACTED] met [REDACTED], not Janet. [REDACTED] called [REDACTED]."
+	if redacted != want {
+		t.Errorf("RedactTerms() = %q, want %q", redacted, want)
+	}
+
+	if _, changed := RedactTerms("nothing here", []string{"Jane"}); changed {
+		t.Error("expected unchanged text")
+	}
+}
@@ -107,27 +107,8 @@ func AddNodesAndEdgesBulk(
 
 	// Add entity nodes with embeddings
 	if len(entityNodes) > 0 {
-		// Generate embeddings for entity nodes if needed
-		var textsToEmbed []string
-		var nodeIndices []int
-		for i, node := range entityNodes {
-			if len(node.Embedding) == 0 && node.Name != "" {
-				textsToEmbed = append(textsToEmbed, node.Name)
-				nodeIndices = append(nodeIndices, i)
-			}
-		}
-
-		if len(textsToEmbed) > 0 && embedder != nil {
-			embeddings, err := embedder.Embed(ctx, textsToEmbed)
-			if err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("failed to generate embeddings: %w", err))
-			} else {
-				for i, embedding := range embeddings {
-					if i < len(nodeIndices) {
-						entityNodes[nodeIndices[i]].Embedding = embedding
-					}
-				}
-			}
+		if err := embedNodes(ctx, entityNodes, embedder); err != nil {
+			result.Errors = append(result.Errors, err)
 		}
 
 		// Upsert entity nodes
@@ -153,27 +134,8 @@ func AddNodesAndEdgesBulk(
 
 	// Add entity edges with embeddings
 	if len(entityEdges) > 0 {
-		// Generate embeddings for entity edges if needed
-		var textsToEmbed []string
-		var edgeIndices []int
-		for i, edge := range entityEdges {
-			if len(edge.Embedding) == 0 && edge.Summary != "" {
-				textsToEmbed = append(textsToEmbed, edge.Summary)
-				edgeIndices = append(edgeIndices, i)
-			}
-		}
-
-		if len(textsToEmbed) > 0 && embedder != nil {
-			embeddings, err := embedder.Embed(ctx, textsToEmbed)
-			if err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("failed to generate edge embeddings: %w", err))
-			} else {
-				for i, embedding := range embeddings {
-					if i < len(edgeIndices) {
-						entityEdges[edgeIndices[i]].Embedding = embedding
-					}
-				}
-			}
+		if err := embedEdges(ctx, entityEdges, embedder); err != nil {
+			result.Errors = append(result.Errors, err)
 		}
 
 		// Upsert entity edges
@@ -256,6 +218,69 @@ func AddNodesAndEdgesBulk(
 	return result, nil
 }
 
+// EmbedNodesAndEdges generates the missing embeddings of entity nodes and
+// edges. Callers writing inside a driver transaction run it first and pass a
+// nil embedder to AddNodesAndEdgesBulk, so no embedding request is made
+// while the transaction holds the database.
+func EmbedNodesAndEdges(ctx context.Context, entityNodes []*types.Node, entityEdges []*types.Edge, embedder embedder.Client) error {
+	if err := embedNodes(ctx, entityNodes, embedder); err != nil {
+		return err
+	}
+	return embedEdges(ctx, entityEdges, embedder)
+}
+
+// embedNodes embeds the names of nodes without an embedding.
+func embedNodes(ctx context.Context, nodes []*types.Node, embedder embedder.Client) error {
+	var textsToEmbed []string
+	var nodeIndices []int
+	for i, node := range nodes {
+		if len(node.Embedding) == 0 && node.Name != "" {
+			textsToEmbed = append(textsToEmbed, node.Name)
+			nodeIndices = append(nodeIndices, i)
+		}
+	}
+	if len(textsToEmbed) == 0 || embedder == nil {
+		return nil
+	}
+
+	embeddings, err := embedder.Embed(ctx, textsToEmbed)
+	if err != nil {
+		return fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	for i, embedding := range embeddings {
+		if i < len(nodeIndices) {
+			nodes[nodeIndices[i]].Embedding = embedding
+		}
+	}
+	return nil
+}
+
+// embedEdges embeds the summaries of edges without an embedding.
+func embedEdges(ctx context.Context, edges []*types.Edge, embedder embedder.Client) error {
+	var textsToEmbed []string
+	var edgeIndices []int
+	for i, edge := range edges {
+		if len(edge.Embedding) == 0 && edge.Summary != "" {
+			textsToEmbed = append(textsToEmbed, edge.Summary)
+			edgeIndices = append(edgeIndices, i)
+		}
+	}
+	if len(textsToEmbed) == 0 || embedder == nil {
+		return nil
+	}
+
+	embeddings, err := embedder.Embed(ctx, textsToEmbed)
+	if err != nil {
+		return fmt.Errorf("failed to generate edge embeddings: %w", err)
+	}
+	for i, embedding := range embeddings {
+		if i < len(edgeIndices) {
+			edges[edgeIndices[i]].Embedding = embedding
+		}
+	}
+	return nil
+}
+
 // ExtractNodesAndEdgesBulk extracts nodes and edges from episodes in bulk
 // This matches the Python function signature: extract_nodes_and_edges_bulk(clients, episode_tuples, edge_type_map, ...)
 func ExtractNodesAndEdgesBulk(
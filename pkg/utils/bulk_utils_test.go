@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -178,3 +179,52 @@ func TestMinFunction(t *testing.T) {
 		t.Errorf("Expected 5, got %d", result)
 	}
 }
+
+// lengthEmbedder embeds each text as its length and counts the texts embedded.
+type lengthEmbedder struct {
+	embedded int
+}
+
+func (e *lengthEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.embedded += len(texts)
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = []float32{float32(len(text))}
+	}
+	return embeddings, nil
+}
+
+func (e *lengthEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.Embed(ctx, []string{text})
+	return embeddings[0], err
+}
+
+func (e *lengthEmbedder) Dimensions() int { return 1 }
+func (e *lengthEmbedder) Close() error    { return nil }
+
+func TestEmbedNodesAndEdges(t *testing.T) {
+	nodes := []*types.Node{
+		{Uuid: "alice", Name: "Alice"},
+		{Uuid: "bob", Name: "Bob", Embedding: []float32{42}},
+	}
+	edges := []*types.Edge{
+		{BaseEdge: types.BaseEdge{Uuid: "knows"}, Summary: "Alice knows Bob"},
+	}
+
+	emb := &lengthEmbedder{}
+	if err := EmbedNodesAndEdges(context.Background(), nodes, edges, emb); err != nil {
+		t.Fatalf("EmbedNodesAndEdges: %v", err)
+	}
+	if emb.embedded != 2 {
+		t.Errorf("embedded %d texts, want 2", emb.embedded)
+	}
+	if len(nodes[0].Embedding) != 1 || nodes[0].Embedding[0] != 5 {
+		t.Errorf("alice embedding = %v", nodes[0].Embedding)
+	}
+	if nodes[1].Embedding[0] != 42 {
+		t.Error("existing embedding was replaced")
+	}
+	if len(edges[0].Embedding) != 1 || edges[0].Embedding[0] != float32(len("Alice knows Bob")) {
+		t.Errorf("edge embedding = %v", edges[0].Embedding)
+	}
+}
@@ -2,6 +2,7 @@ package maintenance
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -29,6 +30,9 @@ type EdgeOperations struct {
 	ExtractionNLP nlp.Client
 	ResolutionNLP nlp.Client
 
+	// RelationExtractor, if set, is used by ExtractEdges instead of ExtractionNLP
+	RelationExtractor nlp.RelationExtractor
+
 	// Skip flags
 	SkipResolution bool
 
@@ -180,26 +184,21 @@ func (eo *EdgeOperations) extractEdgesBatch(ctx context.Context, episode *types.
 		return []*types.Edge{}, nil
 	}
 
-	// Prepare edge types context as a slice for TSV formatting
-	edgeTypesContext := []map[string]interface{}{}
+	// Prepare edge types with the signatures of their source/target entity types
+	requestedTypes := map[string][][]string{}
 	if edgeTypeMap != nil {
 		for typeName := range edgeTypes {
-			edgeTypesContext = append(edgeTypesContext, map[string]interface{}{
-				"fact_type_name":        typeName,
-				"fact_type_description": fmt.Sprintf("custom type: %s", typeName),
-				"fact_type_signature":   edgeTypeMap[typeName], // Include the signature for source/target entity types
-			})
+			requestedTypes[typeName] = edgeTypeMap[typeName]
 		}
 	}
 
-	// Prepare context for LLM using batch nodes
-	// Note: Data is passed as slices for TSV formatting in prompts
-	nodeContexts := make([]map[string]interface{}, len(batchNodes))
+	// Relation arguments are the batch nodes
+	entities := make([]nlp.ExtractedEntity, len(batchNodes))
 	for i, node := range batchNodes {
-		nodeContexts[i] = map[string]interface{}{
-			"id":           i,
-			"name":         node.Name,
-			"entity_types": []string{string(node.EntityType)}, // Simplified for now
+		entities[i] = nlp.ExtractedEntity{
+			Name: node.Name,
+			Type: node.EntityType,
+			Span: types.GetSpan(node.Metadata),
 		}
 	}
 
@@ -208,105 +207,42 @@ func (eo *EdgeOperations) extractEdgesBatch(ctx context.Context, episode *types.
 		previousEpisodeContents[i] = ep.Summary
 	}
 
-	promptContext := map[string]interface{}{
-		"episode_content":   episode.Content,
-		"nodes":             nodeContexts,
-		"previous_episodes": previousEpisodeContents,
-		"reference_time":    episode.ValidFrom,
-		"edge_types":        edgeTypesContext,
-		"custom_prompt":     "",
-		"ensure_ascii":      true,
-		"logger":            eo.logger,
-	}
-
-	// Extract edges using LLM
-	messages, err := eo.prompts.ExtractEdges().Edge().Call(promptContext)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create prompt: %w", err)
+	req := &nlp.RelationExtractionRequest{
+		Text:             episode.Content,
+		Entities:         entities,
+		EdgeTypeMap:      requestedTypes,
+		PreviousEpisodes: previousEpisodeContents,
+		ReferenceTime:    episode.ValidFrom,
 	}
 
-	// Create CSV parser function for ExtractedEdge
-	csvParser := func(csvContent string) ([]*prompts.ExtractedEdge, error) {
-		return utils.UnmarshalCSV[prompts.ExtractedEdge](csvContent, '\t')
-	}
-
-	// Use GenerateCSVResponse for robust CSV parsing with retries
-	extractedEdgeSlice, badResp, err := nlp.GenerateCSVResponse[prompts.ExtractedEdge](
-		ctx,
-		eo.getExtractionNLP(),
-		eo.logger,
-		messages,
-		csvParser,
-		0, // maxRetries (use default of 8)
-	)
-
+	relations, err := eo.extractRelations(ctx, req)
 	if err != nil {
-		// Log detailed error information
-		if badResp != nil {
-			eo.logger.Error("Failed to extract edges from CSV",
-				"error", badResp.Error,
-				"response_length", len(badResp.Response),
-				"num_messages", len(badResp.Messages))
-			if badResp.Response != "" {
-				fmt.Printf("\nFailed LLM edge extraction response:\n%v\n\n", badResp.Response)
-			}
-		}
-		return []*types.Edge{}, fmt.Errorf("failed to unmarshal extracted edges: %w", err)
+		return []*types.Edge{}, err
 	}
 
-	// Convert to ExtractedEdges struct
-	var extractedEdges prompts.ExtractedEdges
-	extractedEdges.Edges = extractedEdgeSlice
-
-	log.Printf("Extracted %d edges in %v", len(extractedEdges.Edges), time.Since(start))
+	log.Printf("Extracted %d edges in %v", len(relations), time.Since(start))
 
-	if len(extractedEdges.Edges) == 0 {
+	if len(relations) == 0 {
 		return []*types.Edge{}, nil
 	}
 
 	// Convert to Edge objects
-	edges := make([]*types.Edge, 0, len(extractedEdges.Edges))
-	for _, edgeData := range extractedEdges.Edges {
+	edges := make([]*types.Edge, 0, len(relations))
+	for _, relation := range relations {
 		// Validate node indices (relative to batch)
-		if edgeData.SourceID < 0 || edgeData.SourceID >= len(batchNodes) ||
-			edgeData.TargetID < 0 || edgeData.TargetID >= len(batchNodes) {
-			log.Printf("Warning: invalid node indices for edge %s (batch has %d nodes)", edgeData.Name, len(batchNodes))
+		if relation.Source < 0 || relation.Source >= len(batchNodes) ||
+			relation.Target < 0 || relation.Target >= len(batchNodes) {
+			log.Printf("Warning: invalid node indices for edge %s (batch has %d nodes)", relation.Type, len(batchNodes))
 			continue
 		}
 
-		sourceNode := batchNodes[edgeData.SourceID]
-		targetNode := batchNodes[edgeData.TargetID]
-
-		// Parse temporal information
-		var validAt time.Time
-		var validTo *time.Time
+		sourceNode := batchNodes[relation.Source]
+		targetNode := batchNodes[relation.Target]
 
-		if edgeData.ValidAt != "" {
-			// Strip any surrounding quotes (can happen with double JSON encoding)
-			cleanValidAt := strings.Trim(edgeData.ValidAt, "\"")
-			if parsed, err := time.Parse(time.RFC3339, strings.ReplaceAll(cleanValidAt, "Z", "+00:00")); err == nil {
-				validAt = parsed.UTC()
-			} else if cleanValidAt == "null" {
-				validAt = episode.ValidFrom
-			} else {
-				log.Printf("Warning: failed to parse valid_at date '%s': %v", cleanValidAt, err)
-				validAt = episode.ValidFrom
-			}
-		} else {
-			validAt = episode.ValidFrom
-		}
-
-		if edgeData.InvalidAt != "" {
-			// Strip any surrounding quotes (can happen with double JSON encoding)
-			cleanInvalidAt := strings.Trim(edgeData.InvalidAt, "\"")
-			if parsed, err := time.Parse(time.RFC3339, strings.ReplaceAll(cleanInvalidAt, "Z", "+00:00")); err == nil {
-				parsedUTC := parsed.UTC()
-				validTo = &parsedUTC
-			} else if cleanInvalidAt == "null" {
-				validTo = nil
-			} else {
-				log.Printf("Warning: failed to parse invalid_at date '%s': %v", cleanInvalidAt, err)
-			}
+		// Undated facts are valid from the episode
+		validAt := episode.ValidFrom
+		if relation.ValidAt != nil {
+			validAt = *relation.ValidAt
 		}
 
 		edge := types.NewEntityEdge(
@@ -314,15 +250,34 @@ func (eo *EdgeOperations) extractEdgesBatch(ctx context.Context, episode *types.
 			sourceNode.Uuid,
 			targetNode.Uuid,
 			groupID,
-			edgeData.Name,
+			relation.Type,
 			types.EntityEdgeType,
 		)
-		edge.Summary = edgeData.Summary
-		edge.Fact = edgeData.Fact
+		edge.Summary = relation.Fact
+		edge.Fact = relation.Fact
 		edge.UpdatedAt = time.Now().UTC()
 		edge.ValidFrom = validAt
-		edge.ValidTo = validTo
+		edge.ValidTo = relation.InvalidAt
 		edge.SourceIDs = []string{episode.Uuid}
+		edge.Corroboration = 1
+		if relation.Score > 0 {
+			edge.Confidence = relation.Score
+		}
+
+		// Record where in the episode the fact was stated, falling back to the
+		// stretch of text spanning both entity mentions.
+		span := relation.Span
+		if span == nil {
+			sourceSpan := types.LocateSpan(episode.Content, sourceNode.Name)
+			targetSpan := types.LocateSpan(episode.Content, targetNode.Name)
+			if sourceSpan != nil && targetSpan != nil {
+				union := sourceSpan.Union(targetSpan)
+				span = types.NewSpan(episode.Content, union.Start, union.End)
+			}
+		}
+		if span != nil {
+			types.AddEdgeProvenance(edge, types.Provenance{EpisodeID: episode.Uuid, Start: span.Start, End: span.End, Text: span.Text})
+		}
 
 		edges = append(edges, edge)
 		log.Printf("Created edge: %s from %s to %s", edge.Name, sourceNode.Name, targetNode.Name)
@@ -331,6 +286,27 @@ func (eo *EdgeOperations) extractEdgesBatch(ctx context.Context, episode *types.
 	return edges, nil
 }
 
+// extractRelations runs RelationExtractor if set, then the extraction client
+// if it is an nlp.RelationExtractor, and prompts the extraction client otherwise.
+func (eo *EdgeOperations) extractRelations(ctx context.Context, req *nlp.RelationExtractionRequest) ([]nlp.ExtractedRelation, error) {
+	var extractor nlp.RelationExtractor = eo.RelationExtractor
+	if extractor == nil {
+		if typed, ok := eo.getExtractionNLP().(nlp.RelationExtractor); ok {
+			extractor = typed
+		}
+	}
+	if extractor != nil {
+		relations, err := extractor.ExtractTypedRelations(ctx, req)
+		if !errors.Is(err, nlp.ErrExtractionUnsupported) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract relations: %w", err)
+			}
+			return relations, nil
+		}
+	}
+	return NewLLMExtractor(eo.getExtractionNLP(), eo.prompts, eo.logger).ExtractTypedRelations(ctx, req)
+}
+
 // GetBetweenNodes retrieves edges between two specific nodes using the proper Ladybug query pattern
 func (eo *EdgeOperations) GetBetweenNodes(ctx context.Context, sourceNodeID, targetNodeID string) ([]*types.Edge, error) {
 	query := `
@@ -505,7 +481,10 @@ func (eo *EdgeOperations) ResolveExtractedEdges(ctx context.Context, extractedEd
 			if !found {
 				resolvedEdge.SourceIDs = append(resolvedEdge.SourceIDs, episode.Uuid)
 				resolvedEdge.UpdatedAt = time.Now().UTC()
+				resolvedEdge.Corroborate(extractedEdge.Confidence)
 			}
+			// Keep the evidence of the new mention alongside the existing fact
+			types.AddEdgeProvenance(resolvedEdge, types.EdgeProvenance(extractedEdge)...)
 		}
 
 		resolvedEdges = append(resolvedEdges, resolvedEdge)
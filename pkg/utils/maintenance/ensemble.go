@@ -0,0 +1,481 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/prompts"
+	"github.com/soundprediction/predicato/pkg/types"
+	"github.com/soundprediction/predicato/pkg/utils"
+)
+
+// EnsembleStrategy decides the entities that not every extractor proposed.
+type EnsembleStrategy string
+
+const (
+	// EnsembleVote keeps a disputed entity if the weight of the extractors
+	// proposing it exceeds the quorum.
+	EnsembleVote EnsembleStrategy = "vote"
+	// EnsembleAdjudicate asks an LLM about each disputed entity, falling back
+	// to a vote if adjudication fails.
+	EnsembleAdjudicate EnsembleStrategy = "adjudicate"
+)
+
+// DefaultEnsembleQuorum is the share of the total weight a disputed entity
+// needs to be kept by vote.
+const DefaultEnsembleQuorum = 0.5
+
+// EnsembleSource is one extractor of an EnsembleExtractor.
+type EnsembleSource struct {
+	Name      string
+	Extractor nlp.EntityExtractor
+	// Weight of the source's votes. Zero counts as 1.
+	Weight float64
+}
+
+func (s EnsembleSource) weight() float64 {
+	if s.Weight <= 0 {
+		return 1
+	}
+	return s.Weight
+}
+
+// EnsembleExtractor implements nlp.EntityExtractor by running several
+// extractors concurrently, e.g. GLiNER for recall and an LLM for precision,
+// and aligning their entities by normalized name, MinHash similarity and
+// span overlap. Entities proposed by every extractor are kept; the others are
+// decided by Strategy. Agreement statistics are recorded in the collector
+// returned by WithEnsembleStats.
+type EnsembleExtractor struct {
+	sources []EnsembleSource
+	logger  *slog.Logger
+
+	// Strategy defaults to EnsembleVote.
+	Strategy EnsembleStrategy
+	// Quorum defaults to DefaultEnsembleQuorum.
+	Quorum float64
+	// Adjudicator is the LLM asked about disputed entities with EnsembleAdjudicate.
+	Adjudicator nlp.Client
+	// Prompts defaults to prompts.DefaultLibrary.
+	Prompts prompts.Library
+}
+
+// NewEnsembleExtractor creates a new EnsembleExtractor
+func NewEnsembleExtractor(sources []EnsembleSource, logger *slog.Logger) (*EnsembleExtractor, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("ensemble requires at least one extractor")
+	}
+	for _, source := range sources {
+		if source.Extractor == nil {
+			return nil, fmt.Errorf("ensemble extractor %q is nil", source.Name)
+		}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &EnsembleExtractor{
+		sources:  sources,
+		logger:   logger,
+		Strategy: EnsembleVote,
+		Quorum:   DefaultEnsembleQuorum,
+	}, nil
+}
+
+// ensembleCandidate is an entity aligned across extractors.
+type ensembleCandidate struct {
+	members   []nlp.ExtractedEntity
+	sources   []int // index into EnsembleExtractor.sources, one per member
+	normNames map[string]bool
+	minHashes [][]uint64
+	voters    map[int]bool
+	weight    float64
+}
+
+// ExtractTypedEntities implements nlp.EntityExtractor
+func (e *EnsembleExtractor) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	results := make([][]nlp.ExtractedEntity, len(e.sources))
+	errs := make([]error, len(e.sources))
+	var wg sync.WaitGroup
+	for i, source := range e.sources {
+		wg.Add(1)
+		go func(i int, source EnsembleSource) {
+			defer wg.Done()
+			results[i], errs[i] = source.Extractor.ExtractTypedEntities(ctx, req)
+		}(i, source)
+	}
+	wg.Wait()
+
+	stats := &types.EnsembleStats{Sources: make([]types.ExtractorAgreement, len(e.sources))}
+	totalWeight := 0.0
+	var failures []error
+	for i, source := range e.sources {
+		stats.Sources[i].Source = source.Name
+		if errs[i] != nil {
+			stats.Sources[i].Failures++
+			failures = append(failures, fmt.Errorf("%s: %w", source.Name, errs[i]))
+			e.logger.Warn("Ensemble extractor failed", "extractor", source.Name, "error", errs[i])
+			continue
+		}
+		totalWeight += source.weight()
+	}
+	if len(failures) == len(e.sources) {
+		return nil, fmt.Errorf("all ensemble extractors failed: %w", errors.Join(failures...))
+	}
+
+	candidates := e.align(results, errs)
+	stats.Candidates = len(candidates)
+
+	// Unanimous candidates are kept; the others are disputed
+	keep := make([]bool, len(candidates))
+	var disputed []int
+	for i, candidate := range candidates {
+		if candidate.weight >= totalWeight {
+			keep[i] = true
+			stats.Unanimous++
+		} else {
+			disputed = append(disputed, i)
+		}
+	}
+
+	entities := make([]nlp.ExtractedEntity, len(candidates))
+	for i, candidate := range candidates {
+		entities[i] = candidate.merge(e.sources, totalWeight)
+	}
+
+	if len(disputed) > 0 {
+		decided := map[int]bool{}
+		if e.Strategy == EnsembleAdjudicate && e.Adjudicator != nil {
+			var err error
+			decided, err = e.adjudicate(ctx, req, entities, candidates, disputed)
+			if err != nil {
+				e.logger.Warn("Ensemble adjudication failed, falling back to vote", "error", err)
+			}
+			stats.Adjudicated = len(decided)
+		}
+		quorum := e.Quorum
+		if quorum <= 0 {
+			quorum = DefaultEnsembleQuorum
+		}
+		for _, i := range disputed {
+			if verdict, ok := decided[i]; ok {
+				keep[i] = verdict
+			} else {
+				keep[i] = candidates[i].weight/totalWeight > quorum
+			}
+		}
+	}
+
+	var accepted []nlp.ExtractedEntity
+	for i, candidate := range candidates {
+		agreed := len(candidate.voters) > 1
+		for voter := range candidate.voters {
+			stats.Sources[voter].Extracted++
+			if agreed {
+				stats.Sources[voter].Agreed++
+			}
+			if keep[i] {
+				stats.Sources[voter].Accepted++
+			}
+		}
+		if keep[i] {
+			accepted = append(accepted, entities[i])
+		}
+	}
+	stats.Accepted = len(accepted)
+
+	if collector, ok := ctx.Value(types.ContextKeyEnsembleStats).(*EnsembleStatsCollector); ok {
+		collector.record(stats)
+	}
+	e.logger.Debug("Ensemble extraction completed",
+		"candidates", stats.Candidates,
+		"unanimous", stats.Unanimous,
+		"adjudicated", stats.Adjudicated,
+		"accepted", stats.Accepted)
+
+	return accepted, nil
+}
+
+// align groups the entities of all extractors into candidates. Entities are
+// the same candidate if their normalized names are equal, their MinHash
+// similarity reaches utils.FuzzyJaccardThreshold, or their spans overlap.
+func (e *EnsembleExtractor) align(results [][]nlp.ExtractedEntity, errs []error) []*ensembleCandidate {
+	var candidates []*ensembleCandidate
+	for source, entities := range results {
+		if errs[source] != nil {
+			continue
+		}
+		for _, entity := range entities {
+			normName := utils.NormalizeStringExact(entity.Name)
+			if normName == "" {
+				continue
+			}
+			var minHash []uint64
+			if utils.HasHighEntropy(normName) {
+				minHash = utils.MinHashSignature(utils.CachedShingles(normName))
+			}
+
+			// An entity matching several candidates joins them into one
+			var match *ensembleCandidate
+			remaining := candidates[:0]
+			for _, candidate := range candidates {
+				switch {
+				case !candidate.matches(normName, minHash, entity.Span):
+					remaining = append(remaining, candidate)
+				case match == nil:
+					match = candidate
+					remaining = append(remaining, candidate)
+				default:
+					for i, member := range candidate.members {
+						e.addMember(match, member, candidate.sources[i])
+					}
+				}
+			}
+			candidates = remaining
+			if match == nil {
+				match = &ensembleCandidate{normNames: map[string]bool{}, voters: map[int]bool{}}
+				candidates = append(candidates, match)
+			}
+			e.addMember(match, entity, source)
+		}
+	}
+	return candidates
+}
+
+func (e *EnsembleExtractor) addMember(candidate *ensembleCandidate, entity nlp.ExtractedEntity, source int) {
+	normName := utils.NormalizeStringExact(entity.Name)
+	candidate.members = append(candidate.members, entity)
+	candidate.sources = append(candidate.sources, source)
+	if !candidate.normNames[normName] {
+		candidate.normNames[normName] = true
+		if utils.HasHighEntropy(normName) {
+			candidate.minHashes = append(candidate.minHashes, utils.MinHashSignature(utils.CachedShingles(normName)))
+		}
+	}
+	if !candidate.voters[source] {
+		candidate.voters[source] = true
+		candidate.weight += e.sources[source].weight()
+	}
+}
+
+func (c *ensembleCandidate) matches(normName string, minHash []uint64, span *types.Span) bool {
+	if c.normNames[normName] {
+		return true
+	}
+	if minHash != nil {
+		for _, other := range c.minHashes {
+			if minHashSimilarity(minHash, other) >= utils.FuzzyJaccardThreshold {
+				return true
+			}
+		}
+	}
+	if span.IsValid() {
+		for _, member := range c.members {
+			if member.Span.IsValid() && member.Span.Start < span.End && span.Start < member.Span.End {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// minHashSimilarity estimates the Jaccard similarity of two MinHash signatures.
+func minHashSimilarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// merge returns the candidate as one entity: the mention of its
+// highest-weighted source, the type with the most weight, the first known
+// span, and the share of the total weight that proposed it as score.
+func (c *ensembleCandidate) merge(sources []EnsembleSource, totalWeight float64) nlp.ExtractedEntity {
+	best := 0
+	for i := range c.members {
+		if sources[c.sources[i]].weight() > sources[c.sources[best]].weight() {
+			best = i
+		}
+	}
+	merged := c.members[best]
+
+	typeWeights := map[string]float64{}
+	for i, member := range c.members {
+		if member.Type != "" {
+			typeWeights[member.Type] += sources[c.sources[i]].weight()
+		}
+	}
+	typeNames := make([]string, 0, len(typeWeights))
+	for typeName := range typeWeights {
+		typeNames = append(typeNames, typeName)
+	}
+	sort.Strings(typeNames)
+	for _, typeName := range typeNames {
+		if typeWeights[typeName] > typeWeights[merged.Type] {
+			merged.Type = typeName
+		}
+	}
+
+	if !merged.Span.IsValid() {
+		for _, member := range c.members {
+			if member.Span.IsValid() {
+				merged.Span = member.Span
+				break
+			}
+		}
+	}
+	if totalWeight > 0 {
+		merged.Score = c.weight / totalWeight
+	}
+	return merged
+}
+
+// adjudicate asks the Adjudicator about the disputed candidates and returns
+// the verdicts it gave, setting the entity type it chose.
+func (e *EnsembleExtractor) adjudicate(ctx context.Context, req *nlp.EntityExtractionRequest, entities []nlp.ExtractedEntity, candidates []*ensembleCandidate, disputed []int) (map[int]bool, error) {
+	library := e.Prompts
+	if library == nil {
+		library = prompts.DefaultLibrary
+	}
+
+	entityTypesContext := make([]map[string]interface{}, len(req.EntityTypes))
+	typeIDs := make(map[string]int, len(req.EntityTypes))
+	for i, entityType := range req.EntityTypes {
+		entityTypesContext[i] = map[string]interface{}{
+			"entity_type_id":          i,
+			"entity_type_name":        entityType.Name,
+			"entity_type_description": entityType.Description,
+		}
+		typeIDs[entityType.Name] = i
+	}
+
+	candidatesContext := make([]map[string]interface{}, len(disputed))
+	for id, i := range disputed {
+		var proposedBy []string
+		for voter := range candidates[i].voters {
+			proposedBy = append(proposedBy, e.sources[voter].Name)
+		}
+		sort.Strings(proposedBy)
+		typeID, ok := typeIDs[entities[i].Type]
+		if !ok {
+			typeID = 0
+		}
+		candidatesContext[id] = map[string]interface{}{
+			"id":             id,
+			"entity":         entities[i].Name,
+			"entity_type_id": typeID,
+			"proposed_by":    strings.Join(proposedBy, ","),
+		}
+	}
+
+	messages, err := library.ExtractNodes().Adjudicate().Call(map[string]interface{}{
+		"episode_content": req.Text,
+		"candidates":      candidatesContext,
+		"entity_types":    entityTypesContext,
+		"ensure_ascii":    true,
+		"logger":          e.logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create adjudication prompt: %w", err)
+	}
+
+	csvParser := func(csvContent string) ([]*prompts.EntityVerdictTSV, error) {
+		return utils.UnmarshalCSV[prompts.EntityVerdictTSV](csvContent, '\t')
+	}
+	verdicts, _, err := nlp.GenerateCSVResponse[prompts.EntityVerdictTSV](ctx, e.Adjudicator, e.logger, messages, csvParser, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjudicate entities: %w", err)
+	}
+
+	decided := make(map[int]bool, len(verdicts))
+	for _, verdict := range verdicts {
+		if verdict.ID < 0 || verdict.ID >= len(disputed) {
+			continue
+		}
+		i := disputed[verdict.ID]
+		keep := isTrue(verdict.Keep)
+		decided[i] = keep
+		if keep && verdict.EntityTypeID >= 0 && verdict.EntityTypeID < len(req.EntityTypes) {
+			entities[i].Type = req.EntityTypes[verdict.EntityTypeID].Name
+		}
+	}
+	return decided, nil
+}
+
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.Trim(strings.TrimSpace(value), "\"")) {
+	case "true", "yes", "1", "y":
+		return true
+	}
+	return false
+}
+
+// EnsembleStatsCollector accumulates the statistics of the ensemble
+// extractions run with its context.
+type EnsembleStatsCollector struct {
+	mu    sync.Mutex
+	stats *types.EnsembleStats
+}
+
+// WithEnsembleStats returns a context in which EnsembleExtractor records its
+// agreement statistics into the returned collector.
+func WithEnsembleStats(ctx context.Context) (context.Context, *EnsembleStatsCollector) {
+	collector := &EnsembleStatsCollector{}
+	return context.WithValue(ctx, types.ContextKeyEnsembleStats, collector), collector
+}
+
+func (c *EnsembleStatsCollector) record(stats *types.EnsembleStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats == nil {
+		c.stats = &types.EnsembleStats{}
+	}
+	c.stats.Merge(stats)
+}
+
+// Stats returns the accumulated statistics, or nil if no ensemble ran.
+func (c *EnsembleStatsCollector) Stats() *types.EnsembleStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats == nil {
+		return nil
+	}
+	stats := *c.stats
+	stats.Sources = append([]types.ExtractorAgreement(nil), c.stats.Sources...)
+	return &stats
+}
+
+// ClientExtractor implements nlp.EntityExtractor for an NLP client: clients
+// that are typed extractors are called directly, others are prompted.
+type ClientExtractor struct {
+	client nlp.Client
+	llm    *LLMExtractor
+}
+
+// NewClientExtractor creates a new ClientExtractor
+func NewClientExtractor(client nlp.Client, prompts prompts.Library, logger *slog.Logger) *ClientExtractor {
+	return &ClientExtractor{
+		client: client,
+		llm:    NewLLMExtractor(client, prompts, logger),
+	}
+}
+
+// ExtractTypedEntities implements nlp.EntityExtractor
+func (c *ClientExtractor) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	entities, err := nlp.ExtractTypedEntities(ctx, c.client, req)
+	if errors.Is(err, nlp.ErrExtractionUnsupported) {
+		return c.llm.ExtractTypedEntities(ctx, req)
+	}
+	return entities, err
+}
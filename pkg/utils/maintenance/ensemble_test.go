@@ -0,0 +1,98 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+type staticExtractor struct {
+	entities []nlp.ExtractedEntity
+	err      error
+}
+
+func (s *staticExtractor) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	return s.entities, s.err
+}
+
+func TestEnsembleExtractor_VotesAndReportsAgreement(t *testing.T) {
+	text := "Alice Johnson met Bob at Acme Corporation."
+	gliner := &staticExtractor{entities: []nlp.ExtractedEntity{
+		{Name: "Alice Johnson", Type: "Person", Span: types.NewSpan(text, 0, 13)},
+		{Name: "Bob", Type: "Person"},
+		{Name: "Acme Corporation", Type: "Organization"},
+	}}
+	llm := &staticExtractor{entities: []nlp.ExtractedEntity{
+		{Name: "alice  johnson", Type: "Person"}, // same name after normalization
+		{Name: "Acme", Type: "Organization", Span: types.NewSpan(text, 25, 29)},
+	}}
+	llm2 := &staticExtractor{entities: []nlp.ExtractedEntity{
+		{Name: "Alice Johnson", Type: "Entity"},
+		{Name: "Acme Corporation", Type: "Organization", Span: types.NewSpan(text, 25, 41)},
+	}}
+
+	ensemble, err := NewEnsembleExtractor([]EnsembleSource{
+		{Name: "gliner", Extractor: gliner},
+		{Name: "llm", Extractor: llm},
+		{Name: "llm2", Extractor: llm2},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewEnsembleExtractor: %v", err)
+	}
+
+	ctx, collector := WithEnsembleStats(context.Background())
+	entities, err := ensemble.ExtractTypedEntities(ctx, &nlp.EntityExtractionRequest{Text: text})
+	if err != nil {
+		t.Fatalf("ExtractTypedEntities: %v", err)
+	}
+
+	// Alice and Acme (aligned by span) are unanimous; Bob has one vote of three
+	if len(entities) != 2 {
+		t.Fatalf("got %d entities, want 2: %+v", len(entities), entities)
+	}
+	if entities[0].Name != "Alice Johnson" || entities[0].Type != "Person" || entities[0].Score != 1 {
+		t.Errorf("entities[0] = %+v, want Alice Johnson as Person with score 1", entities[0])
+	}
+	if entities[1].Name != "Acme Corporation" {
+		t.Errorf("entities[1] = %+v, want Acme Corporation", entities[1])
+	}
+
+	stats := collector.Stats()
+	if stats == nil {
+		t.Fatal("no ensemble stats recorded")
+	}
+	if stats.Candidates != 3 || stats.Unanimous != 2 || stats.Accepted != 2 {
+		t.Errorf("stats = %+v, want 3 candidates, 2 unanimous, 2 accepted", stats)
+	}
+	if got := stats.Sources[0]; got.Extracted != 3 || got.Agreed != 2 || got.Accepted != 2 {
+		t.Errorf("gliner agreement = %+v, want 3 extracted, 2 agreed, 2 accepted", got)
+	}
+	if got := stats.Sources[1].AgreementRate(); got != 1 {
+		t.Errorf("llm agreement rate = %v, want 1", got)
+	}
+}
+
+func TestEnsembleExtractor_ToleratesFailingSource(t *testing.T) {
+	ensemble, err := NewEnsembleExtractor([]EnsembleSource{
+		{Name: "gliner", Extractor: &staticExtractor{entities: []nlp.ExtractedEntity{{Name: "Bob", Type: "Person"}}}},
+		{Name: "llm", Extractor: &staticExtractor{err: errors.New("timeout")}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewEnsembleExtractor: %v", err)
+	}
+
+	ctx, collector := WithEnsembleStats(context.Background())
+	entities, err := ensemble.ExtractTypedEntities(ctx, &nlp.EntityExtractionRequest{Text: "Bob"})
+	if err != nil {
+		t.Fatalf("ExtractTypedEntities: %v", err)
+	}
+	if len(entities) != 1 {
+		t.Errorf("got %+v, want Bob from the remaining source", entities)
+	}
+	if failures := collector.Stats().Sources[1].Failures; failures != 1 {
+		t.Errorf("llm failures = %d, want 1", failures)
+	}
+}
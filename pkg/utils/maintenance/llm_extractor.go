@@ -0,0 +1,234 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/prompts"
+	"github.com/soundprediction/predicato/pkg/types"
+	"github.com/soundprediction/predicato/pkg/utils"
+)
+
+// LLMExtractor implements nlp.EntityExtractor and nlp.RelationExtractor by
+// prompting an LLM with the extract_nodes and extract_edges prompts.
+type LLMExtractor struct {
+	nlProcessor nlp.Client
+	prompts     prompts.Library
+	logger      *slog.Logger
+
+	// UseYAML requests entities as YAML instead of TSV.
+	UseYAML bool
+}
+
+// NewLLMExtractor creates a new LLMExtractor
+func NewLLMExtractor(nlProcessor nlp.Client, prompts prompts.Library, logger *slog.Logger) *LLMExtractor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LLMExtractor{
+		nlProcessor: nlProcessor,
+		prompts:     prompts,
+		logger:      logger,
+	}
+}
+
+// ExtractTypedEntities implements nlp.EntityExtractor
+func (e *LLMExtractor) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	// Note: entity_types is passed as a slice for TSV formatting in prompts
+	entityTypesContext := make([]map[string]interface{}, len(req.EntityTypes))
+	for i, entityType := range req.EntityTypes {
+		entityTypesContext[i] = map[string]interface{}{
+			"entity_type_id":          i,
+			"entity_type_name":        entityType.Name,
+			"entity_type_description": entityType.Description,
+		}
+	}
+
+	promptContext := map[string]interface{}{
+		"episode_content":    req.Text,
+		"episode_timestamp":  req.ReferenceTime.Format(time.RFC3339),
+		"previous_episodes":  req.PreviousEpisodes,
+		"custom_prompt":      req.Instructions,
+		"entity_types":       entityTypesContext,
+		"source_description": req.SourceType,
+		"ensure_ascii":       true,
+		"logger":             e.logger,
+		"use_yaml":           e.UseYAML,
+	}
+
+	// Choose the appropriate extraction method based on episode source
+	var messages []types.Message
+	var err error
+	switch strings.ToLower(req.SourceType) {
+	case "message":
+		messages, err = e.prompts.ExtractNodes().ExtractMessage().Call(promptContext)
+	case "json":
+		messages, err = e.prompts.ExtractNodes().ExtractJSON().Call(promptContext)
+	default:
+		messages, err = e.prompts.ExtractNodes().ExtractText().Call(promptContext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extraction prompt: %w", err)
+	}
+
+	var extractedEntitySlice []prompts.ExtractedEntity
+	var badResp *types.BadLlmCsvResponse
+	if e.UseYAML {
+		yamlParser := func(yamlContent string) ([]*prompts.ExtractedEntity, error) {
+			return utils.UnmarshalYAML[prompts.ExtractedEntity](yamlContent)
+		}
+		extractedEntitySlice, badResp, err = nlp.GenerateYAMLResponse[prompts.ExtractedEntity](
+			ctx,
+			e.nlProcessor,
+			e.logger,
+			messages,
+			yamlParser,
+			3, // maxRetries
+		)
+	} else {
+		// Use GenerateCSVResponse for robust CSV parsing with retries
+		csvParser := func(csvContent string) ([]*prompts.ExtractedEntity, error) {
+			return utils.UnmarshalCSV[prompts.ExtractedEntity](csvContent, '\t')
+		}
+		extractedEntitySlice, badResp, err = nlp.GenerateCSVResponse[prompts.ExtractedEntity](
+			ctx,
+			e.nlProcessor,
+			e.logger,
+			messages,
+			csvParser,
+			3, // maxRetries
+		)
+	}
+	if err != nil {
+		// Log detailed error information
+		if badResp != nil {
+			e.logger.Error("Failed to extract entities from CSV",
+				"error", badResp.Error,
+				"response_length", len(badResp.Response),
+				"num_messages", len(badResp.Messages))
+			if badResp.Response != "" {
+				fmt.Printf("\nFailed LLM response:\n%v\n\n", badResp.Response)
+			}
+		}
+		return nil, fmt.Errorf("failed to extract entities from csv: %w", err)
+	}
+
+	entities := make([]nlp.ExtractedEntity, 0, len(extractedEntitySlice))
+	for _, extracted := range extractedEntitySlice {
+		entity := nlp.ExtractedEntity{
+			Name: extracted.Name,
+			Span: extractionSpan(req.Text, extracted.Start, extracted.End, extracted.Name),
+		}
+		if extracted.EntityTypeID >= 0 && extracted.EntityTypeID < len(req.EntityTypes) {
+			entity.Type = req.EntityTypes[extracted.EntityTypeID].Name
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// ExtractTypedRelations implements nlp.RelationExtractor
+func (e *LLMExtractor) ExtractTypedRelations(ctx context.Context, req *nlp.RelationExtractionRequest) ([]nlp.ExtractedRelation, error) {
+	// Prepare edge types context as a slice for TSV formatting
+	edgeTypesContext := []map[string]interface{}{}
+	for typeName, signature := range req.EdgeTypeMap {
+		edgeTypesContext = append(edgeTypesContext, map[string]interface{}{
+			"fact_type_name":        typeName,
+			"fact_type_description": fmt.Sprintf("custom type: %s", typeName),
+			"fact_type_signature":   signature, // Include the signature for source/target entity types
+		})
+	}
+
+	// Note: Data is passed as slices for TSV formatting in prompts
+	nodeContexts := make([]map[string]interface{}, len(req.Entities))
+	for i, entity := range req.Entities {
+		nodeContexts[i] = map[string]interface{}{
+			"id":           i,
+			"name":         entity.Name,
+			"entity_types": []string{entity.Type},
+		}
+	}
+
+	promptContext := map[string]interface{}{
+		"episode_content":   req.Text,
+		"nodes":             nodeContexts,
+		"previous_episodes": req.PreviousEpisodes,
+		"reference_time":    req.ReferenceTime,
+		"edge_types":        edgeTypesContext,
+		"custom_prompt":     req.Instructions,
+		"ensure_ascii":      true,
+		"logger":            e.logger,
+	}
+
+	messages, err := e.prompts.ExtractEdges().Edge().Call(promptContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prompt: %w", err)
+	}
+
+	csvParser := func(csvContent string) ([]*prompts.ExtractedEdge, error) {
+		return utils.UnmarshalCSV[prompts.ExtractedEdge](csvContent, '\t')
+	}
+
+	// Use GenerateCSVResponse for robust CSV parsing with retries
+	extractedEdgeSlice, badResp, err := nlp.GenerateCSVResponse[prompts.ExtractedEdge](
+		ctx,
+		e.nlProcessor,
+		e.logger,
+		messages,
+		csvParser,
+		0, // maxRetries (use default of 8)
+	)
+	if err != nil {
+		// Log detailed error information
+		if badResp != nil {
+			e.logger.Error("Failed to extract edges from CSV",
+				"error", badResp.Error,
+				"response_length", len(badResp.Response),
+				"num_messages", len(badResp.Messages))
+			if badResp.Response != "" {
+				fmt.Printf("\nFailed LLM edge extraction response:\n%v\n\n", badResp.Response)
+			}
+		}
+		return nil, fmt.Errorf("failed to unmarshal extracted edges: %w", err)
+	}
+
+	relations := make([]nlp.ExtractedRelation, 0, len(extractedEdgeSlice))
+	for _, edgeData := range extractedEdgeSlice {
+		relation := nlp.ExtractedRelation{
+			Source:    edgeData.SourceID,
+			Target:    edgeData.TargetID,
+			Type:      edgeData.Name,
+			Fact:      edgeData.Fact,
+			ValidAt:   parseExtractedTime(edgeData.ValidAt, "valid_at"),
+			InvalidAt: parseExtractedTime(edgeData.InvalidAt, "invalid_at"),
+			Span:      extractionSpan(req.Text, edgeData.Start, edgeData.End, edgeData.Evidence, edgeData.Fact),
+		}
+		if confidence, ok := types.ParseConfidence(edgeData.Confidence); ok {
+			relation.Score = confidence
+		}
+		relations = append(relations, relation)
+	}
+	return relations, nil
+}
+
+// parseExtractedTime parses an RFC 3339 date from an extraction response,
+// returning nil if it is empty, "null" or malformed.
+func parseExtractedTime(value, field string) *time.Time {
+	// Strip any surrounding quotes (can happen with double JSON encoding)
+	value = strings.Trim(value, "\"")
+	if value == "" || value == "null" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, strings.ReplaceAll(value, "Z", "+00:00"))
+	if err != nil {
+		log.Printf("Warning: failed to parse %s date '%s': %v", field, value, err)
+		return nil
+	}
+	parsed = parsed.UTC()
+	return &parsed
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/soundprediction/predicato/pkg/driver"
@@ -228,3 +230,21 @@ func (mu *MaintenanceUtils) ValidateGraphIntegrity(ctx context.Context, groupID
 	log.Printf("Found %d integrity issues", len(issues))
 	return issues, nil
 }
+
+// extractionSpan locates an extraction in content. Explicit character offsets
+// (as reported by GLiNER) win; otherwise each quote is searched for in turn.
+func extractionSpan(content, start, end string, quotes ...string) *types.Span {
+	if s, err := strconv.Atoi(strings.TrimSpace(start)); err == nil {
+		if e, err := strconv.Atoi(strings.TrimSpace(end)); err == nil {
+			if span := types.NewSpan(content, s, e); span != nil {
+				return span
+			}
+		}
+	}
+	for _, quote := range quotes {
+		if span := types.LocateSpan(content, strings.Trim(quote, "\"")); span != nil {
+			return span
+		}
+	}
+	return nil
+}
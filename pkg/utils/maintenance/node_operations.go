@@ -2,6 +2,7 @@ package maintenance
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -12,6 +13,7 @@ import (
 	"github.com/soundprediction/predicato/pkg/embedder"
 	"github.com/soundprediction/predicato/pkg/nlp"
 	"github.com/soundprediction/predicato/pkg/prompts"
+	"github.com/soundprediction/predicato/pkg/telemetry"
 	"github.com/soundprediction/predicato/pkg/types"
 	"github.com/soundprediction/predicato/pkg/utils"
 )
@@ -35,6 +37,9 @@ type NodeOperations struct {
 	ResolutionNLP nlp.Client
 	AttributeNLP  nlp.Client
 
+	// EntityExtractor, if set, is used by ExtractNodes instead of ExtractionNLP
+	EntityExtractor nlp.EntityExtractor
+
 	// Skip flags
 	SkipReflexion  bool
 	SkipResolution bool
@@ -89,29 +94,24 @@ func (no *NodeOperations) getAttributeNLP() nlp.Client {
 	return no.nlProcessor
 }
 
-// ExtractNodes extracts entity nodes from episode content using LLM
+// ExtractNodes extracts entity nodes from episode content. It uses
+// EntityExtractor if set, then the extraction client if it is an
+// nlp.EntityExtractor (e.g. GLiNER), and prompts the extraction client otherwise.
 func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node, previousEpisodes []*types.Node, entityTypes map[string]interface{}, excludedEntityTypes []string) ([]*types.Node, error) {
 	start := time.Now()
 
-	// Prepare entity types context
-	entityTypesContext := []map[string]interface{}{
+	// Prepare entity types
+	requestedTypes := []nlp.EntityType{
 		{
-			"entity_type_id":          0,
-			"entity_type_name":        "Entity",
-			"entity_type_description": "Default classification. Use this entity type if the entity is not one of the other listed types.",
+			Name:        "Entity",
+			Description: "Default classification. Use this entity type if the entity is not one of the other listed types.",
 		},
 	}
-
-	if entityTypes != nil {
-		id := 1
-		for typeName := range entityTypes {
-			entityTypesContext = append(entityTypesContext, map[string]interface{}{
-				"entity_type_id":          id,
-				"entity_type_name":        typeName,
-				"entity_type_description": fmt.Sprintf("custom type: %s", typeName),
-			})
-			id++
-		}
+	for typeName := range entityTypes {
+		requestedTypes = append(requestedTypes, nlp.EntityType{
+			Name:        typeName,
+			Description: fmt.Sprintf("custom type: %s", typeName),
+		})
 	}
 
 	// Prepare previous episodes content
@@ -120,18 +120,12 @@ func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node,
 		previousEpisodeContents[i] = ep.Summary
 	}
 
-	// Prepare context for LLM
-	// Note: entity_types is passed as a slice for TSV formatting in prompts
-	promptContext := map[string]interface{}{
-		"episode_content":    episode.Content,
-		"episode_timestamp":  episode.ValidFrom.Format(time.RFC3339),
-		"previous_episodes":  previousEpisodeContents,
-		"custom_prompt":      "",
-		"entity_types":       entityTypesContext,
-		"source_description": string(episode.EpisodeType),
-		"ensure_ascii":       true,
-		"logger":             no.logger,
-		"use_yaml":           no.UseYAML,
+	req := &nlp.EntityExtractionRequest{
+		Text:             episode.Content,
+		EntityTypes:      requestedTypes,
+		SourceType:       string(episode.EpisodeType),
+		PreviousEpisodes: previousEpisodeContents,
+		ReferenceTime:    episode.ValidFrom,
 	}
 
 	// Extract entities with reflexion
@@ -139,84 +133,23 @@ func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node,
 	reflexionIterations := 0
 	maxReflexionIterations := utils.GetMaxReflexionIterations()
 
-	var extractedEntities prompts.ExtractedEntities
+	var extractedEntities []nlp.ExtractedEntity
 
 	for entitiesMissed && reflexionIterations <= maxReflexionIterations {
-		// Choose the appropriate extraction method based on episode source
-		var messages []types.Message
+		var prompted bool
 		var err error
-
-		switch strings.ToLower(string(episode.EpisodeType)) {
-		case "message":
-			messages, err = no.prompts.ExtractNodes().ExtractMessage().Call(promptContext)
-		case "text":
-			messages, err = no.prompts.ExtractNodes().ExtractText().Call(promptContext)
-		case "json":
-			messages, err = no.prompts.ExtractNodes().ExtractJSON().Call(promptContext)
-		default:
-			messages, err = no.prompts.ExtractNodes().ExtractText().Call(promptContext)
-		}
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to create extraction prompt: %w", err)
-		}
-
-		var extractedEntitySlice []prompts.ExtractedEntity
-		var badResp *types.BadLlmCsvResponse
-
-		if no.UseYAML {
-			// Create YAML parser function for ExtractedEntity
-			yamlParser := func(yamlContent string) ([]*prompts.ExtractedEntity, error) {
-				return utils.UnmarshalYAML[prompts.ExtractedEntity](yamlContent)
-			}
-
-			// Use GenerateYAMLResponse
-			extractedEntitySlice, badResp, err = nlp.GenerateYAMLResponse[prompts.ExtractedEntity](
-				ctx,
-				no.getExtractionNLP(),
-				no.logger,
-				messages,
-				yamlParser,
-				3, // maxRetries
-			)
-		} else {
-			// Create CSV parser function for ExtractedEntity
-			csvParser := func(csvContent string) ([]*prompts.ExtractedEntity, error) {
-				return utils.UnmarshalCSV[prompts.ExtractedEntity](csvContent, '\t')
-			}
-
-			// Use GenerateCSVResponse for robust CSV parsing with retries
-			extractedEntitySlice, badResp, err = nlp.GenerateCSVResponse[prompts.ExtractedEntity](
-				ctx,
-				no.getExtractionNLP(),
-				no.logger,
-				messages,
-				csvParser,
-				3, // maxRetries
-			)
-		}
-
+		extractedEntities, prompted, err = no.extractEntities(ctx, req)
 		if err != nil {
-			// Log detailed error information
-			if badResp != nil {
-				no.logger.Error("Failed to extract entities from CSV",
-					"error", badResp.Error,
-					"response_length", len(badResp.Response),
-					"num_messages", len(badResp.Messages))
-				if badResp.Response != "" {
-					fmt.Printf("\nFailed LLM response:\n%v\n\n", badResp.Response)
-				}
-			}
-			return nil, fmt.Errorf("failed to extract entities from csv: %w", err)
+			return nil, err
 		}
 
-		// Convert to ExtractedEntities struct
-		extractedEntities.ExtractedEntities = extractedEntitySlice
-
 		reflexionIterations++
-		if !no.SkipReflexion && reflexionIterations < maxReflexionIterations {
+		// Only prompt-based extraction can be told about missed entities
+		if prompted && !no.SkipReflexion && reflexionIterations < maxReflexionIterations {
 			// Run reflexion to check for missed entities
-			missedEntities, err := no.extractNodesReflexion(ctx, episode, previousEpisodes, extractedEntities)
+			reflexionCtx, endReflexion := telemetry.StartStep(ctx, "ingest.reflexion", telemetry.AttrEpisodeID.String(episode.Uuid))
+			missedEntities, err := no.extractNodesReflexion(reflexionCtx, episode, previousEpisodes, extractedEntities)
+			endReflexion(err, telemetry.AttrCount.Int(len(missedEntities)))
 			if err != nil {
 				log.Printf("Warning: reflexion failed: %v", err)
 				break
@@ -228,7 +161,7 @@ func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node,
 				for _, entity := range missedEntities {
 					customPrompt += fmt.Sprintf("\n%s,", entity)
 				}
-				promptContext["custom_prompt"] = customPrompt
+				req.Instructions = customPrompt
 			}
 		} else {
 			entitiesMissed = false
@@ -236,8 +169,8 @@ func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node,
 	}
 
 	// Filter out empty entity names
-	var filteredEntities []prompts.ExtractedEntity
-	for _, entity := range extractedEntities.ExtractedEntities {
+	var filteredEntities []nlp.ExtractedEntity
+	for _, entity := range extractedEntities {
 		if strings.TrimSpace(entity.Name) != "" {
 			filteredEntities = append(filteredEntities, entity)
 		}
@@ -249,11 +182,12 @@ func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node,
 	var extractedNodes []*types.Node
 	for _, extractedEntity := range filteredEntities {
 		// Determine entity type
-		var entityTypeName string
-		if extractedEntity.EntityTypeID >= 0 && extractedEntity.EntityTypeID < len(entityTypesContext) {
-			entityTypeName = entityTypesContext[extractedEntity.EntityTypeID]["entity_type_name"].(string)
-		} else {
-			entityTypeName = "Entity"
+		entityTypeName := "Entity"
+		for _, requested := range requestedTypes {
+			if requested.Name == extractedEntity.Type {
+				entityTypeName = requested.Name
+				break
+			}
 		}
 
 		// Check if this entity type should be excluded
@@ -283,6 +217,13 @@ func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node,
 			EntityType: entityTypeName,
 			Metadata:   make(map[string]interface{}),
 		}
+		span := extractedEntity.Span
+		if span == nil {
+			span = types.LocateSpan(episode.Content, extractedEntity.Name)
+		}
+		if span != nil {
+			node.Metadata[types.MetadataKeySpan] = span
+		}
 
 		extractedNodes = append(extractedNodes, node)
 		// log.Printf("Created entity node: %s of type: %s (UUID: %s)", node.Name, node.EntityType, node.ID)
@@ -291,11 +232,37 @@ func (no *NodeOperations) ExtractNodes(ctx context.Context, episode *types.Node,
 	return extractedNodes, nil
 }
 
+// extractEntities runs the first extractor that supports the request and
+// reports whether it was the prompt-based LLMExtractor.
+func (no *NodeOperations) extractEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, bool, error) {
+	var extractor nlp.EntityExtractor = no.EntityExtractor
+	if extractor == nil {
+		if typed, ok := no.getExtractionNLP().(nlp.EntityExtractor); ok {
+			extractor = typed
+		}
+	}
+	if extractor != nil {
+		entities, err := extractor.ExtractTypedEntities(ctx, req)
+		if !errors.Is(err, nlp.ErrExtractionUnsupported) {
+			_, prompted := extractor.(*LLMExtractor)
+			if err != nil {
+				return nil, prompted, fmt.Errorf("failed to extract entities: %w", err)
+			}
+			return entities, prompted, nil
+		}
+	}
+
+	llmExtractor := NewLLMExtractor(no.getExtractionNLP(), no.prompts, no.logger)
+	llmExtractor.UseYAML = no.UseYAML
+	entities, err := llmExtractor.ExtractTypedEntities(ctx, req)
+	return entities, true, err
+}
+
 // extractNodesReflexion performs reflexion to identify missed entities
-func (no *NodeOperations) extractNodesReflexion(ctx context.Context, episode *types.Node, previousEpisodes []*types.Node, extractedEntities prompts.ExtractedEntities) ([]string, error) {
+func (no *NodeOperations) extractNodesReflexion(ctx context.Context, episode *types.Node, previousEpisodes []*types.Node, extractedEntities []nlp.ExtractedEntity) ([]string, error) {
 	// Get entity names
 	var entityNames []string
-	for _, entity := range extractedEntities.ExtractedEntities {
+	for _, entity := range extractedEntities {
 		entityNames = append(entityNames, entity.Name)
 	}
 
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/soundprediction/predicato/pkg/community"
+	"github.com/soundprediction/predicato/pkg/crossencoder"
 	"github.com/soundprediction/predicato/pkg/driver"
 	"github.com/soundprediction/predicato/pkg/embedder"
 	"github.com/soundprediction/predicato/pkg/factstore"
@@ -65,6 +66,11 @@ type Predicato interface {
 	// GetEdge retrieves a specific edge from the knowledge graph.
 	GetEdge(ctx context.Context, edgeID string) (*types.Edge, error)
 
+	// Answer answers a question from the knowledge graph: it runs hybrid search,
+	// builds a token-budgeted context and returns the model's answer together
+	// with the fact and episode UUIDs it cites.
+	Answer(ctx context.Context, question string, opts *AnswerOptions) (*AnswerResult, error)
+
 	// GetEpisodes retrieves recent episodes from the knowledge graph.
 	GetEpisodes(ctx context.Context, groupID string, limit int) ([]*types.Node, error)
 
@@ -80,6 +86,17 @@ type Predicato interface {
 	// RemoveEpisode removes an episode and its associated nodes and edges from the knowledge graph.
 	RemoveEpisode(ctx context.Context, episodeUUID string) error
 
+	// RemoveEpisodeWithOptions removes an episode, cleaning up edges, nodes, sources and
+	// communities it alone supported. With DryRun set it only returns the planned changes.
+	RemoveEpisodeWithOptions(ctx context.Context, episodeUUID string, opts *RemoveEpisodeOptions) (*RemoveEpisodeResult, error)
+
+	// PurgeEntity deletes an entity and its edges, redacts or deletes the episodes that
+	// mention it, purges its fact store rows and cached embeddings, and records an audit entry.
+	PurgeEntity(ctx context.Context, uuid string, opts *PurgeOptions) (*PurgeResult, error)
+
+	// PurgeBySource removes everything ingested from a source and records an audit entry.
+	PurgeBySource(ctx context.Context, sourceName string) (*PurgeResult, error)
+
 	// GetNodesAndEdgesByEpisode retrieves all nodes and edges associated with a specific episode.
 	GetNodesAndEdgesByEpisode(ctx context.Context, episodeUUID string) ([]*types.Node, []*types.Edge, error)
 
@@ -114,14 +131,40 @@ type Predicato interface {
 	// relationship resolution, and community detection according to the GraphModeler.
 	//
 	// The sourceID parameter is the episode UUID returned from ExtractToFacts.
+	// The outcome is recorded as the source's promotion status in the fact store.
+	// Set options.FactsRef to promote the facts of a versioned fact store revision.
 	PromoteToGraph(ctx context.Context, sourceID string, options *AddEpisodeOptions) (*types.AddEpisodeResults, error)
 
+	// PromoteAll promotes the fact store sources that are pending promotion, oldest
+	// Reference first, with up to concurrency promotions at once but only one
+	// per group. Progress is reported through PromoteFilter.OnProgress.
+	//
+	// Requires FactStoreConfig to be set in Config.
+	PromoteAll(ctx context.Context, filter *PromoteFilter, concurrency int) (*PromoteAllResults, error)
+
+	// ReprocessEpisodes re-runs extraction on stored sources selected by group, time range,
+	// source or prompt version, diffs the result against the fact store, promotes the added
+	// facts and retracts the ones that are no longer supported.
+	//
+	// Requires FactStoreConfig to be set in Config.
+	ReprocessEpisodes(ctx context.Context, selector *ReprocessSelector, options *ReprocessOptions) (*ReprocessResults, error)
+
 	// ValidateModeler tests a GraphModeler implementation with sample data to verify
 	// it works correctly before using it in production.
 	//
 	// Returns validation results including pass/fail status for each method,
 	// latency measurements, and any warnings.
 	ValidateModeler(ctx context.Context, gm modeler.GraphModeler) (*modeler.ModelerValidationResult, error)
+
+	// EmbeddingSpace returns the embedding model and dimensions recorded for the graph.
+	// Searches and ingestion fail with ErrEmbeddingSpaceMismatch when the configured
+	// embedder differs from it.
+	EmbeddingSpace(ctx context.Context) (*types.EmbeddingSpace, error)
+
+	// ReembedAll re-embeds every stored vector of the graph and fact store with
+	// newEmbedder. New vectors are staged first and replace the old ones in one
+	// transaction once all are computed; an interrupted run resumes where it stopped.
+	ReembedAll(ctx context.Context, newEmbedder embedder.Client, batchSize int) (*ReembedResult, error)
 }
 
 // Client is the main implementation of the Predicato interface.
@@ -137,6 +180,13 @@ type Client struct {
 
 	// Specialized NLP clients for different steps
 	nlpModels NlpModels
+
+	// events delivers graph changes to registered listeners
+	events *graphEventListeners
+
+	// embeddingSpace tracks whether the embedder has been checked against
+	// the recorded embedding spaces, and holds vectors staged by ReembedAll
+	embeddingSpace *embeddingSpaceState
 }
 
 // NlpModels holds specialized NLP clients for different pipeline steps.
@@ -159,6 +209,11 @@ type NlpModels struct {
 type Config struct {
 	// GroupID is used to isolate data for multi-tenant scenarios
 	GroupID string
+	// AllowedGroupIDs lists additional groups this client may read when a
+	// search spans several groups (e.g. a shared organisation group). The
+	// client's own GroupID is always allowed. WithAllowedGroupIDs overrides
+	// this list for a single request.
+	AllowedGroupIDs []string
 	// TimeZone for temporal operations
 	TimeZone *time.Location
 	// Search configuration
@@ -182,6 +237,35 @@ type Config struct {
 	// DefaultGraphModeler is the default GraphModeler used for graph promotion.
 	// If nil, a DefaultModeler is created using the client's NLP/embedder config.
 	DefaultGraphModeler modeler.GraphModeler
+
+	// AuditLogPath is the tamper-evident audit log that purges are recorded in.
+	// Defaults to ~/.predicato/audit/purge.jsonl.
+	AuditLogPath string
+
+	// AuditHMACKey keys the HMAC-SHA256 of purged names that the audit log
+	// records, so that whoever holds the key can prove what was purged while
+	// the log alone reveals nothing. Without a key no digest is recorded.
+	AuditHMACKey []byte
+
+	// CrossEncoder reranks search results when a search config asks for the
+	// cross-encoder reranker. Optional.
+	CrossEncoder crossencoder.Client
+
+	// UsagePolicy tags NLP and embedder calls with a usage per group so that
+	// sensitive groups can be routed to local models. Nil attaches no tag.
+	UsagePolicy *UsagePolicy
+
+	// EntityExtractor and RelationExtractor, if set, extract entities and
+	// facts instead of the NodeExtraction and EdgeExtraction models. When
+	// nil, models that implement the interfaces (e.g. GLiNER) are called
+	// directly and others are prompted.
+	EntityExtractor   nlp.EntityExtractor
+	RelationExtractor nlp.RelationExtractor
+
+	// ReembedStagingPath is a Badger directory where ReembedAll stages new
+	// vectors, so an interrupted run resumes after a restart. When empty the
+	// vectors are staged in memory and only a retry on the same client resumes.
+	ReembedStagingPath string
 }
 
 // AddEpisodeOptions holds options for adding a single episode.
@@ -217,6 +301,16 @@ type AddEpisodeOptions struct {
 	// Requires FactStoreConfig to be configured.
 	ExtractOnly bool
 
+	// PromptVersion identifies the prompts/models used for extraction. It is recorded
+	// in the fact store source metadata so sources can later be selected for
+	// re-processing with ReprocessEpisodes.
+	PromptVersion string
+
+	// FactsRef makes PromoteToGraph read the extracted facts as of a fact store
+	// revision (a Dolt branch, tag or commit) instead of the current contents, to
+	// A/B test extraction runs on the same corpus. Requires a versioned fact store.
+	FactsRef string
+
 	// GraphModeler overrides the default graph modeler for this episode.
 	// If nil, uses Config.DefaultGraphModeler or creates a DefaultModeler.
 	GraphModeler modeler.GraphModeler
@@ -224,6 +318,20 @@ type AddEpisodeOptions struct {
 	// ModelerErrorHandling controls how errors from GraphModeler are handled.
 	// Default is FailOnError.
 	ModelerErrorHandling modeler.ModelerErrorHandling
+
+	// Preprocessors run in order on the episode before any NLP call, e.g. a
+	// pii.Redactor to redact or pseudonymize personal data. The processed
+	// episode is what gets stored.
+	Preprocessors []EpisodePreprocessor
+
+	// Usage tags the NLP and embedder calls for this episode (see UsagePolicy).
+	// It cannot downgrade a group whose usage is sensitive.
+	Usage string
+}
+
+// EpisodePreprocessor rewrites an episode before extraction.
+type EpisodePreprocessor interface {
+	PreprocessEpisode(ctx context.Context, episode *types.Episode) error
 }
 
 // NewClient creates a new Predicato client with the provided configuration.
@@ -242,6 +350,9 @@ func NewClient(driver driver.GraphDriver, nlProcessor nlp.Client, embedderClient
 	}
 
 	searcher := search.NewSearcher(driver, embedderClient, nlProcessor)
+	if config.CrossEncoder != nil {
+		searcher.SetCrossEncoder(config.CrossEncoder)
+	}
 	communityBuilder, err := community.NewBuilder(driver, nlProcessor, config.NlpModels.Summarization, embedderClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create community builder: %w", err)
@@ -270,7 +381,7 @@ func NewClient(driver driver.GraphDriver, nlProcessor nlp.Client, embedderClient
 		factStore = fdb
 	}
 
-	return &Client{
+	client := &Client{
 		driver:      driver,
 		nlProcessor: nlProcessor,
 		embedder:    embedderClient,
@@ -280,7 +391,13 @@ func NewClient(driver driver.GraphDriver, nlProcessor nlp.Client, embedderClient
 		logger:      logger,
 		factStore:   factStore,
 		nlpModels:   config.NlpModels,
-	}, nil
+		events:      &graphEventListeners{},
+
+		embeddingSpace: &embeddingSpaceState{},
+	}
+	client.setEmbeddingDimensions()
+
+	return client, nil
 }
 
 // GetDriver returns the underlying graph driver
@@ -298,7 +415,9 @@ func (c *Client) GetEmbedder() embedder.Client {
 	return c.embedder
 }
 
-// GetCommunityBuilder returns the community builder
+// GetCommunityBuilder returns the community builder. Changes made through
+// the builder directly are not reported to graph event listeners; the
+// Client's community methods report them.
 func (c *Client) GetCommunityBuilder() *community.Builder {
 	return c.community
 }
@@ -315,4 +434,10 @@ var (
 	ErrEdgeNotFound = errors.New("edge not found")
 	// ErrInvalidEpisode is returned when an episode is invalid.
 	ErrInvalidEpisode = errors.New("invalid episode")
+	// ErrGroupNotAllowed is returned when a search names a group the client
+	// is not permitted to read.
+	ErrGroupNotAllowed = errors.New("group not allowed")
+	// ErrEmbeddingSpaceMismatch is returned when the client's embedder differs
+	// from the model that produced the stored vectors.
+	ErrEmbeddingSpaceMismatch = errors.New("embedder does not match the stored embeddings")
 )
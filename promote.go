@@ -0,0 +1,178 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// PromoteFilter selects the fact store sources PromoteAll promotes and
+// configures their promotion. Empty fields match every pending source.
+type PromoteFilter struct {
+	// GroupID matches sources of a single group.
+	GroupID string
+	// IncludeFailed also retries sources whose previous promotion failed.
+	IncludeFailed bool
+	// IncludeUnknown also promotes sources saved before promotion was tracked.
+	// Their extraction may already be in the graph.
+	IncludeUnknown bool
+	// Limit caps the number of sources promoted. Zero means no limit.
+	Limit int
+	// Options are passed to PromoteToGraph for every source.
+	Options *AddEpisodeOptions
+	// OnProgress, if set, is called after each source is promoted or fails.
+	// Calls are serialized.
+	OnProgress func(progress PromoteProgress)
+}
+
+// PromoteProgress reports the progress of a PromoteAll run.
+type PromoteProgress struct {
+	// SourceID is the source that was just processed.
+	SourceID string
+	// Err is the promotion error of SourceID, if any.
+	Err error
+
+	Total    int
+	Done     int
+	Promoted int
+	Failed   int
+}
+
+// PromoteResult describes the outcome of promoting a single source.
+type PromoteResult struct {
+	SourceID  string                   `json:"source_id"`
+	Promotion *types.AddEpisodeResults `json:"promotion,omitempty"`
+	Error     error                    `json:"-"`
+}
+
+// PromoteAllResults summarizes a PromoteAll run. Results are in Reference order.
+type PromoteAllResults struct {
+	Results  []*PromoteResult `json:"results"`
+	Promoted int              `json:"promoted"`
+	Failed   int              `json:"failed"`
+}
+
+// PromoteAll promotes the pending sources of the fact store to the graph, oldest
+// Reference first, recording each outcome as the source's promotion status.
+// Up to concurrency sources (default 1) are promoted at once, started in
+// Reference order, but the sources of a group are always promoted one at a
+// time: each promotion resolves entities against the group's graph, so two
+// at once could both create the same entity. With a concurrency of 1 every
+// source is promoted strictly in Reference order.
+//
+// A failure on one source is recorded in its result and does not stop the run.
+func (c *Client) PromoteAll(ctx context.Context, filter *PromoteFilter, concurrency int) (*PromoteAllResults, error) {
+	if c.factStore == nil {
+		return nil, fmt.Errorf("facts DB not configured")
+	}
+	if filter == nil {
+		filter = &PromoteFilter{}
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	statuses := []factstore.PromotionStatus{factstore.PromotionPending}
+	if filter.IncludeFailed {
+		statuses = append(statuses, factstore.PromotionFailed)
+	}
+	if filter.IncludeUnknown {
+		statuses = append(statuses, factstore.PromotionUnknown)
+	}
+	sources, err := c.factStore.ListSources(ctx, &factstore.SourceFilter{
+		GroupID:  filter.GroupID,
+		Statuses: statuses,
+		Limit:    filter.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sources: %w", err)
+	}
+
+	results := &PromoteAllResults{Results: make([]*PromoteResult, len(sources))}
+	progress := PromoteProgress{Total: len(sources)}
+	var mu sync.Mutex
+
+	// Each source waits for the previous source of its group to finish, so a
+	// group is promoted one source at a time in Reference order
+	previous := make([]chan struct{}, len(sources))
+	done := make([]chan struct{}, len(sources))
+	last := make(map[string]chan struct{})
+	for i, source := range sources {
+		done[i] = make(chan struct{})
+		previous[i] = last[source.GroupID]
+		last[source.GroupID] = done[i]
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(sources); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if previous[i] != nil {
+					<-previous[i]
+				}
+				if ctx.Err() != nil {
+					close(done[i])
+					continue
+				}
+				sourceID := sources[i].ID
+				promotion, err := c.PromoteToGraph(ctx, sourceID, filter.Options)
+				result := &PromoteResult{SourceID: sourceID, Promotion: promotion, Error: err}
+
+				mu.Lock()
+				results.Results[i] = result
+				progress.SourceID = sourceID
+				progress.Err = err
+				progress.Done++
+				if err != nil {
+					progress.Failed++
+					c.logger.Warn("Failed to promote source",
+						"source_id", sourceID,
+						"error", err)
+				} else {
+					progress.Promoted++
+				}
+				if filter.OnProgress != nil {
+					filter.OnProgress(progress)
+				}
+				mu.Unlock()
+				close(done[i])
+			}
+		}()
+	}
+
+	for i := range sources {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Sources not started because the context was cancelled stay pending
+	promoted := results.Results[:0]
+	for _, result := range results.Results {
+		if result != nil {
+			promoted = append(promoted, result)
+		}
+	}
+	results.Results = promoted
+	results.Promoted = progress.Promoted
+	results.Failed = progress.Failed
+
+	c.logger.Info("Promotion complete",
+		"sources", len(sources),
+		"promoted", results.Promoted,
+		"failed", results.Failed)
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
@@ -0,0 +1,228 @@
+package predicato
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/modeler"
+)
+
+// recordingModeler records the episodes it resolves, failing those in fail,
+// and notes whether two episodes of a group were ever resolved at once.
+type recordingModeler struct {
+	fail map[string]bool
+
+	mu       sync.Mutex
+	resolved []string
+	active   map[string]int
+	overlap  bool
+}
+
+func (m *recordingModeler) ResolveEntities(ctx context.Context, input *modeler.EntityResolutionInput) (*modeler.EntityResolutionOutput, error) {
+	m.mu.Lock()
+	m.resolved = append(m.resolved, input.Episode.Uuid)
+	m.active[input.GroupID]++
+	if m.active[input.GroupID] > 1 {
+		m.overlap = true
+	}
+	m.mu.Unlock()
+
+	// Give a concurrent promotion of the same group time to start
+	time.Sleep(20 * time.Millisecond)
+
+	m.mu.Lock()
+	m.active[input.GroupID]--
+	m.mu.Unlock()
+	if m.fail[input.Episode.Uuid] {
+		return nil, errors.New("resolution failed")
+	}
+	return &modeler.EntityResolutionOutput{UUIDMap: map[string]string{}}, nil
+}
+
+func (m *recordingModeler) ResolveRelationships(ctx context.Context, input *modeler.RelationshipResolutionInput) (*modeler.RelationshipResolutionOutput, error) {
+	return &modeler.RelationshipResolutionOutput{}, nil
+}
+
+func (m *recordingModeler) BuildCommunities(ctx context.Context, input *modeler.CommunityInput) (*modeler.CommunityOutput, error) {
+	return nil, nil
+}
+
+// newPromoteClient returns a client over a fact store holding sources of
+// groups g1 and g2 with the given promotion statuses, saved out of Reference
+// order, and a modeler recording the promotions.
+func newPromoteClient(t *testing.T) (*Client, factstore.FactsDB, *recordingModeler) {
+	t.Helper()
+	ctx := context.Background()
+	store, err := factstore.NewDoltDB("file://" + t.TempDir() + "?commitname=Test&commitemail=test@example.com&database=facts")
+	if err != nil {
+		t.Fatalf("NewDoltDB: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sources := []struct {
+		id, group string
+		hours     int
+		status    factstore.PromotionStatus
+	}{
+		{"a-late", "g1", 4, factstore.PromotionPending},
+		{"a-early", "g1", 1, factstore.PromotionPending},
+		{"a-failed", "g1", 3, factstore.PromotionFailed},
+		{"a-unknown", "g1", 0, factstore.PromotionUnknown},
+		{"b-pending", "g2", 2, factstore.PromotionPending},
+		{"b-promoted", "g2", 5, factstore.PromotionPromoted},
+	}
+	for _, s := range sources {
+		reference := start.Add(time.Duration(s.hours) * time.Hour)
+		err := store.SaveSource(ctx, &factstore.Source{
+			ID: s.id, Name: s.id, Content: "Alice met Bob", GroupID: s.group,
+			CreatedAt: reference, Reference: reference, PromotionStatus: s.status,
+		})
+		if err != nil {
+			t.Fatalf("SaveSource: %v", err)
+		}
+	}
+
+	client, err := NewClient(newMemoryDriver(), nil, nil, &Config{GroupID: "g1", TimeZone: time.UTC}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client.WithFactStore(store), store, &recordingModeler{fail: map[string]bool{}, active: map[string]int{}}
+}
+
+func resultSourceIDs(results *PromoteAllResults) []string {
+	ids := make([]string, len(results.Results))
+	for i, result := range results.Results {
+		ids[i] = result.SourceID
+	}
+	return ids
+}
+
+// TestPromoteAllInReferenceOrder tests that pending sources are promoted
+// oldest first, that the status filters select failed and unknown sources,
+// and that progress is reported after each source
+func TestPromoteAllInReferenceOrder(t *testing.T) {
+	client, store, recorder := newPromoteClient(t)
+	var progress []PromoteProgress
+	results, err := client.PromoteAll(context.Background(), &PromoteFilter{
+		Options:    &AddEpisodeOptions{GraphModeler: recorder},
+		OnProgress: func(p PromoteProgress) { progress = append(progress, p) },
+	}, 1)
+	if err != nil {
+		t.Fatalf("PromoteAll: %v", err)
+	}
+
+	want := []string{"a-early", "b-pending", "a-late"}
+	if !slices.Equal(recorder.resolved, want) || !slices.Equal(resultSourceIDs(results), want) {
+		t.Errorf("promoted %v with results %v, want %v", recorder.resolved, resultSourceIDs(results), want)
+	}
+	if results.Promoted != 3 || results.Failed != 0 {
+		t.Errorf("promoted %d, failed %d; want 3 promoted", results.Promoted, results.Failed)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("got %d progress reports, want 3", len(progress))
+	}
+	for i, p := range progress {
+		if p.SourceID != want[i] || p.Done != i+1 || p.Promoted != i+1 || p.Total != 3 {
+			t.Errorf("progress %d = %+v, want %s done %d of 3", i, p, want[i], i+1)
+		}
+	}
+	source, err := store.GetSource(context.Background(), "a-late")
+	if err != nil {
+		t.Fatalf("GetSource: %v", err)
+	}
+	if source.PromotionStatus != factstore.PromotionPromoted {
+		t.Errorf("status = %s, want promoted", source.PromotionStatus)
+	}
+
+	// Only the failed and unknown sources are left to promote
+	client, store, recorder = newPromoteClient(t)
+	recorder.fail["a-early"] = true
+	results, err = client.PromoteAll(context.Background(), &PromoteFilter{
+		GroupID:        "g1",
+		IncludeFailed:  true,
+		IncludeUnknown: true,
+		Options:        &AddEpisodeOptions{GraphModeler: recorder},
+	}, 1)
+	if err != nil {
+		t.Fatalf("PromoteAll: %v", err)
+	}
+	want = []string{"a-unknown", "a-early", "a-failed", "a-late"}
+	if !slices.Equal(resultSourceIDs(results), want) {
+		t.Errorf("results = %v, want %v", resultSourceIDs(results), want)
+	}
+	if results.Promoted != 3 || results.Failed != 1 || results.Results[1].Error == nil {
+		t.Errorf("promoted %d, failed %d; want a-early alone failed", results.Promoted, results.Failed)
+	}
+	source, err = store.GetSource(context.Background(), "a-early")
+	if err != nil {
+		t.Fatalf("GetSource: %v", err)
+	}
+	if source.PromotionStatus != factstore.PromotionFailed || source.PromotionError == "" {
+		t.Errorf("status = %s (%q), want failed with the error", source.PromotionStatus, source.PromotionError)
+	}
+}
+
+// TestPromoteAllPromotesGroupsOneSourceAtATime tests that concurrency spreads
+// promotions across groups but never runs two sources of a group at once
+func TestPromoteAllPromotesGroupsOneSourceAtATime(t *testing.T) {
+	client, _, recorder := newPromoteClient(t)
+	results, err := client.PromoteAll(context.Background(), &PromoteFilter{
+		IncludeFailed:  true,
+		IncludeUnknown: true,
+		Options:        &AddEpisodeOptions{GraphModeler: recorder},
+	}, 4)
+	if err != nil {
+		t.Fatalf("PromoteAll: %v", err)
+	}
+	if recorder.overlap {
+		t.Error("two sources of a group were promoted at once")
+	}
+	var g1 []string
+	for _, id := range recorder.resolved {
+		if id != "b-pending" {
+			g1 = append(g1, id)
+		}
+	}
+	if want := []string{"a-unknown", "a-early", "a-failed", "a-late"}; !slices.Equal(g1, want) {
+		t.Errorf("g1 promoted in order %v, want %v", g1, want)
+	}
+	if want := []string{"a-unknown", "a-early", "b-pending", "a-failed", "a-late"}; !slices.Equal(resultSourceIDs(results), want) {
+		t.Errorf("results = %v, want Reference order %v", resultSourceIDs(results), want)
+	}
+}
+
+// TestPromoteAllStopsWhenCancelled tests that sources not started before
+// cancellation are left pending
+func TestPromoteAllStopsWhenCancelled(t *testing.T) {
+	client, store, recorder := newPromoteClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := client.PromoteAll(ctx, &PromoteFilter{
+		Options:    &AddEpisodeOptions{GraphModeler: recorder},
+		OnProgress: func(PromoteProgress) { cancel() },
+	}, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("PromoteAll = %v, want context.Canceled", err)
+	}
+	if !slices.Equal(resultSourceIDs(results), []string{"a-early"}) || results.Promoted != 1 {
+		t.Errorf("results = %v, promoted %d; want a-early alone", resultSourceIDs(results), results.Promoted)
+	}
+	for _, id := range []string{"b-pending", "a-late"} {
+		source, err := store.GetSource(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetSource: %v", err)
+		}
+		if source.PromotionStatus != factstore.PromotionPending {
+			t.Errorf("%s status = %s, want pending", id, source.PromotionStatus)
+		}
+	}
+}
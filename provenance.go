@@ -0,0 +1,117 @@
+package predicato
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// chunkOffsets returns the character offset of each chunk within content, or
+// -1 for chunks that are not a verbatim substring of it. Chunks are searched
+// for in order so repeated passages map to successive occurrences.
+func chunkOffsets(content string, chunks []string) []int {
+	offsets := make([]int, len(chunks))
+	cursor := 0
+	for i, chunk := range chunks {
+		idx := strings.Index(content[cursor:], chunk)
+		if idx < 0 {
+			offsets[i] = -1
+			continue
+		}
+		byteOffset := cursor + idx
+		offsets[i] = utf8.RuneCountInString(content[:byteOffset])
+		cursor = byteOffset + len(chunk)
+	}
+	return offsets
+}
+
+// anchorSpan maps span, relative to text that begins at character base of
+// content, onto content. When the shifted offsets no longer cover the span
+// text (or base is unknown), the text is searched for in content instead.
+func anchorSpan(content string, span *types.Span, base int) *types.Span {
+	if !span.IsValid() {
+		return nil
+	}
+	if base >= 0 {
+		shifted := span.Shift(base)
+		if text := types.SpanText(content, shifted); text != "" && (span.Text == "" || text == span.Text) {
+			shifted.Text = text
+			return shifted
+		}
+	}
+	return types.LocateSpan(content, span.Text)
+}
+
+// chunkBase returns the offset of chunk index i, or -1 when unknown.
+func chunkBase(offsets []int, i int) int {
+	if i < 0 || i >= len(offsets) {
+		return -1
+	}
+	return offsets[i]
+}
+
+// firstProvenanceSpan returns the first provenance span recorded on an edge.
+func firstProvenanceSpan(edge *types.Edge) *types.Span {
+	for _, p := range types.EdgeProvenance(edge) {
+		if span := p.Span(); span.IsValid() {
+			return span
+		}
+	}
+	return nil
+}
+
+// anchorNodeSpans rewrites the extraction spans of nodes, which are relative
+// to the chunk each node was extracted from, so they are relative to content.
+// Spans that cannot be placed in content are dropped.
+func anchorNodeSpans(content string, nodesByChunk [][]*types.Node, offsets []int) {
+	for i, nodes := range nodesByChunk {
+		for _, n := range nodes {
+			span := types.GetSpan(n.Metadata)
+			if span == nil {
+				continue
+			}
+			if anchored := anchorSpan(content, span, chunkBase(offsets, i)); anchored != nil {
+				n.Metadata[types.MetadataKeySpan] = anchored
+			} else {
+				delete(n.Metadata, types.MetadataKeySpan)
+			}
+		}
+	}
+}
+
+// anchorEdgeProvenance rewrites the provenance spans of edges extracted from
+// the chunk starting at character base of content so they are relative to
+// content. Entries that cannot be placed in content are dropped.
+func anchorEdgeProvenance(content string, edges []*types.Edge, base int) {
+	for _, edge := range edges {
+		entries := types.EdgeProvenance(edge)
+		if len(entries) == 0 {
+			continue
+		}
+		anchored := make([]types.Provenance, 0, len(entries))
+		for _, p := range entries {
+			if span := anchorSpan(content, p.Span(), base); span != nil {
+				p.Start, p.End, p.Text = span.Start, span.End, span.Text
+				anchored = append(anchored, p)
+			}
+		}
+		if edge.Metadata == nil {
+			edge.Metadata = make(map[string]interface{})
+		}
+		edge.Metadata[types.MetadataKeyProvenance] = anchored
+	}
+}
+
+// clearNodeSpans removes extraction spans from node metadata. They only
+// locate a mention within one episode and must not be persisted on graph
+// nodes.
+func clearNodeSpans(nodesByChunk [][]*types.Node) {
+	for _, nodes := range nodesByChunk {
+		for _, n := range nodes {
+			if n.Metadata != nil {
+				delete(n.Metadata, types.MetadataKeySpan)
+			}
+		}
+	}
+}
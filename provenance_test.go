@@ -0,0 +1,78 @@
+package predicato
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/prompts"
+	"github.com/soundprediction/predicato/pkg/types"
+	"github.com/soundprediction/predicato/pkg/utils/maintenance"
+)
+
+// spanRelationExtractor returns one relation between the first two entities,
+// located at the given text within the request text.
+type spanRelationExtractor struct {
+	fact string
+}
+
+func (s *spanRelationExtractor) ExtractTypedRelations(ctx context.Context, req *nlp.RelationExtractionRequest) ([]nlp.ExtractedRelation, error) {
+	return []nlp.ExtractedRelation{{
+		Source: 0,
+		Target: 1,
+		Type:   "HIRED",
+		Fact:   s.fact,
+		Span:   types.LocateSpan(req.Text, s.fact),
+	}}, nil
+}
+
+func TestChunkSpansAreAnchoredToEpisode(t *testing.T) {
+	chunks := []string{"Alice met Bob in Paris.", "Later, Carol hired Dave at Acme."}
+	content := strings.Join(chunks, "\n")
+	offsets := chunkOffsets(content, chunks)
+	if offsets[1] != len(chunks[0])+1 {
+		t.Fatalf("chunk offsets = %v", offsets)
+	}
+
+	nodes := [][]*types.Node{
+		{{Uuid: "alice", Name: "Alice", Metadata: map[string]interface{}{types.MetadataKeySpan: types.LocateSpan(chunks[0], "Alice")}}},
+		{
+			{Uuid: "carol", Name: "Carol", Metadata: map[string]interface{}{types.MetadataKeySpan: types.LocateSpan(chunks[1], "Carol")}},
+			{Uuid: "dave", Name: "Dave", Metadata: map[string]interface{}{types.MetadataKeySpan: types.LocateSpan(chunks[1], "Dave")}},
+		},
+	}
+	anchorNodeSpans(content, nodes, offsets)
+	for _, chunkNodes := range nodes {
+		for _, n := range chunkNodes {
+			if got := types.SpanText(content, types.GetSpan(n.Metadata)); got != n.Name {
+				t.Errorf("span of %s covers %q in the episode", n.Name, got)
+			}
+		}
+	}
+
+	// Edges extracted from the second chunk record spans within that chunk
+	edgeOps := maintenance.NewEdgeOperations(nil, nil, nil, prompts.NewLibrary())
+	edgeOps.RelationExtractor = &spanRelationExtractor{fact: "Carol hired Dave"}
+	chunkNode := &types.Node{Uuid: "ep-1", Type: types.EpisodicNodeType, Content: chunks[1]}
+	edges, err := edgeOps.ExtractEdges(context.Background(), chunkNode, nodes[1], nil, nil, nil, "g")
+	if err != nil {
+		t.Fatalf("ExtractEdges: %v", err)
+	}
+	if len(edges) != 1 {
+		t.Fatalf("got %d edges, want 1", len(edges))
+	}
+
+	anchorEdgeProvenance(content, edges, chunkBase(offsets, 1))
+	provenance := types.EdgeProvenance(edges[0])
+	if len(provenance) != 1 {
+		t.Fatalf("got %d provenance entries, want 1", len(provenance))
+	}
+	p := provenance[0]
+	if p.Start != offsets[1]+7 || p.Text != "Carol hired Dave" || types.SpanText(content, p.Span()) != "Carol hired Dave" {
+		t.Errorf("provenance = %+v, want the fact located in the episode", p)
+	}
+	if span := firstProvenanceSpan(edges[0]); span == nil || span.Start != p.Start {
+		t.Errorf("firstProvenanceSpan = %+v", span)
+	}
+}
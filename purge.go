@@ -0,0 +1,584 @@
+package predicato
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/soundprediction/predicato/pkg/audit"
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// EpisodePurgePolicy decides what happens to the episodes that mention a purged entity.
+type EpisodePurgePolicy string
+
+const (
+	// EpisodePurgeRedact replaces the entity's name in episode content with
+	// types.RedactedPlaceholder and keeps the episodes.
+	EpisodePurgeRedact EpisodePurgePolicy = "redact"
+	// EpisodePurgeDelete removes the episodes as RemoveEpisodeWithOptions does.
+	EpisodePurgeDelete EpisodePurgePolicy = "delete"
+)
+
+// Audit log actions recorded by purges
+const (
+	AuditActionPurgeEntity = "purge_entity"
+	AuditActionPurgeSource = "purge_source"
+)
+
+// PurgeOptions configures PurgeEntity.
+type PurgeOptions struct {
+	// GroupID is the group of the entity. Default: Config.GroupID.
+	GroupID string
+	// EpisodePolicy decides whether episodes mentioning the entity are redacted
+	// (the default) or deleted.
+	EpisodePolicy EpisodePurgePolicy
+	// Terms are redacted in addition to the entity's name, e.g. aliases or
+	// email addresses.
+	Terms []string
+	// Actor identifies who requested the purge in the audit log.
+	Actor string
+	// Reason is recorded in the audit log, e.g. a request reference.
+	Reason string
+}
+
+// PurgeResult lists what a purge removed and redacted.
+type PurgeResult struct {
+	// Subject is the purged entity UUID or source name.
+	Subject string `json:"subject"`
+
+	DeletedNodes    []string `json:"deleted_nodes"`
+	DeletedEdges    []string `json:"deleted_edges"`
+	DeletedEpisodes []string `json:"deleted_episodes"`
+	// RedactedNodes are entities, episodes and communities whose text named
+	// the subject and was redacted.
+	RedactedNodes []string `json:"redacted_nodes"`
+	// RedactedEdges are surviving edges whose fact named the subject.
+	RedactedEdges []string `json:"redacted_edges"`
+	// ResummarizedNodes are surviving entities whose summaries were rebuilt
+	// from their remaining episodes.
+	ResummarizedNodes []string `json:"resummarized_nodes"`
+	// DeletedFactSources are the fact store sources that were deleted.
+	DeletedFactSources []string `json:"deleted_fact_sources"`
+	// FactStore counts the fact store rows deleted and redacted by name.
+	FactStore *factstore.PurgeStats `json:"fact_store,omitempty"`
+	// ForgottenEmbeddings is the number of texts whose cached embeddings were dropped.
+	ForgottenEmbeddings int `json:"forgotten_embeddings"`
+	// Audit is the audit log entry recording the purge.
+	Audit *audit.Entry `json:"audit"`
+}
+
+// purgeAuditMu serializes appends to the audit log across clients.
+var purgeAuditMu sync.Mutex
+
+// PurgeEntity removes an entity to honour an erasure request. The entity, its
+// edges and its fact store rows are deleted; episodes mentioning it are
+// redacted or deleted according to opts.EpisodePolicy; its name is redacted
+// from the facts, summaries and communities of those episodes and from the
+// fact store; and the cached embeddings of the removed text are dropped.
+// The purge is recorded in the tamper-evident audit log.
+//
+// Entities are not re-summarized, so the purged text is never sent to a model.
+func (c *Client) PurgeEntity(ctx context.Context, uuid string, opts *PurgeOptions) (*PurgeResult, error) {
+	if opts == nil {
+		opts = &PurgeOptions{}
+	}
+	policy := opts.EpisodePolicy
+	if policy == "" {
+		policy = EpisodePurgeRedact
+	}
+	if policy != EpisodePurgeRedact && policy != EpisodePurgeDelete {
+		return nil, fmt.Errorf("unknown episode purge policy %q", policy)
+	}
+	groupID := opts.GroupID
+	if groupID == "" {
+		groupID = c.config.GroupID
+	}
+
+	purgeAuditMu.Lock()
+	defer purgeAuditMu.Unlock()
+	// Open the audit log first so that a broken log stops the purge
+	auditLog, err := c.openAuditLog()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = c.withUsage(ctx, "", groupID)
+	if err != nil {
+		return nil, err
+	}
+	node, err := c.driver.GetNode(ctx, uuid, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+	if node.Type != types.EntityNodeType {
+		return nil, fmt.Errorf("node %s is not an entity", uuid)
+	}
+	terms := append([]string{node.Name}, opts.Terms...)
+
+	wrapper := &driverWrapper{c.driver}
+	edges, err := types.GetEntityEdgesByNode(ctx, wrapper, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity edges: %w", err)
+	}
+	episodes, err := types.GetMentioningEpisodes(ctx, c.driver, uuid, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentioning episodes: %w", err)
+	}
+	communities, err := types.GetNodeCommunities(ctx, c.driver, []string{uuid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity communities: %w", err)
+	}
+	// Facts and entities extracted from the same episodes may name the entity too
+	mentioned, err := types.GetMentionedNodes(ctx, c.driver, episodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentioned nodes: %w", err)
+	}
+	var episodeEdges []*types.Edge
+	for _, episode := range episodes {
+		supported, err := types.GetEntityEdgesByEpisode(ctx, wrapper, episode.Uuid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get edges of episode %s: %w", episode.Uuid, err)
+		}
+		episodeEdges = append(episodeEdges, supported...)
+	}
+
+	result := newPurgeResult(uuid)
+	events := &graphEventBatch{operation: types.GraphOperationPurge}
+	forget := []string{node.Name, node.Summary}
+	gone := map[string]bool{uuid: true}
+	for _, edge := range edges {
+		gone[edge.Uuid] = true
+		forget = append(forget, edge.Fact)
+	}
+
+	if policy == EpisodePurgeDelete {
+		for _, episode := range episodes {
+			forget = append(forget, episode.Content)
+			// Skipping re-summarization keeps the purged text away from the model
+			removal, err := c.RemoveEpisodeWithOptions(ctx, episode.Uuid, &RemoveEpisodeOptions{SkipResummarize: true})
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete episode %s: %w", episode.Uuid, err)
+			}
+			result.DeletedEpisodes = append(result.DeletedEpisodes, episode.Uuid)
+			for _, id := range append(removal.DeletedEdges, removal.DeletedNodes...) {
+				gone[id] = true
+			}
+			result.DeletedEdges = append(result.DeletedEdges, removal.DeletedEdges...)
+			result.DeletedNodes = append(result.DeletedNodes, removal.DeletedNodes...)
+		}
+	}
+
+	var updatedNodes []*types.Node
+	var updatedEdges []*types.Edge
+	if policy == EpisodePurgeRedact {
+		for _, mention := range episodes {
+			episode, err := types.GetEpisodicNodeByUUID(ctx, c.driver, mention.Uuid)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get episode %s: %w", mention.Uuid, err)
+			}
+			redacted, changed := types.RedactTerms(episode.Content, terms)
+			if !changed {
+				continue
+			}
+			forget = append(forget, episode.Content)
+			episode.Content = redacted
+			if episode.Embedding, err = c.reembed(ctx, redacted); err != nil {
+				return nil, err
+			}
+			updatedNodes = append(updatedNodes, episode)
+		}
+	}
+	for _, entity := range mentioned {
+		if gone[entity.Uuid] {
+			continue
+		}
+		if redacted, changed := types.RedactTerms(entity.Summary, terms); changed {
+			forget = append(forget, entity.Summary)
+			entity.Summary = redacted
+			updatedNodes = append(updatedNodes, entity)
+		}
+	}
+	for _, id := range communities {
+		community, err := c.driver.GetNode(ctx, id, node.GroupID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get community %s: %w", id, err)
+		}
+		name, nameChanged := types.RedactTerms(community.Name, terms)
+		summary, summaryChanged := types.RedactTerms(community.Summary, terms)
+		if !nameChanged && !summaryChanged {
+			continue
+		}
+		forget = append(forget, community.Name, community.Summary)
+		community.Name, community.Summary = name, summary
+		if nameChanged {
+			if community.NameEmbedding, err = c.reembed(ctx, name); err != nil {
+				return nil, err
+			}
+		}
+		updatedNodes = append(updatedNodes, community)
+	}
+	seenEdges := make(map[string]bool)
+	for _, edge := range episodeEdges {
+		if gone[edge.Uuid] || seenEdges[edge.Uuid] {
+			continue
+		}
+		seenEdges[edge.Uuid] = true
+		redacted, changed := types.RedactTerms(edge.Fact, terms)
+		if !changed {
+			continue
+		}
+		forget = append(forget, edge.Fact)
+		edge.Fact, edge.Summary = redacted, redacted
+		if edge.FactEmbedding, err = c.reembed(ctx, redacted); err != nil {
+			return nil, err
+		}
+		edge.Embedding = edge.FactEmbedding
+		updatedEdges = append(updatedEdges, edge)
+	}
+
+	err = c.withWriteTransaction(ctx, func(txCtx context.Context) error {
+		for _, updated := range updatedNodes {
+			if err := c.driver.UpsertNode(txCtx, updated); err != nil {
+				return fmt.Errorf("failed to redact node %s: %w", updated.Uuid, err)
+			}
+		}
+		for _, updated := range updatedEdges {
+			if err := c.driver.UpsertEdge(txCtx, updated); err != nil {
+				return fmt.Errorf("failed to redact edge %s: %w", updated.Uuid, err)
+			}
+		}
+		if err := types.DeleteEdgesByUUIDs(txCtx, wrapper, edgeUUIDs(edges)); err != nil {
+			return fmt.Errorf("failed to delete entity edges: %w", err)
+		}
+		if err := types.DeleteNodesByUUIDs(txCtx, c.driver, []string{uuid}); err != nil {
+			return fmt.Errorf("failed to delete entity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, edge := range edges {
+		if !containsString(result.DeletedEdges, edge.Uuid) {
+			result.DeletedEdges = append(result.DeletedEdges, edge.Uuid)
+		}
+		events.edge(types.GraphEventDeleted, edge)
+	}
+	if !containsString(result.DeletedNodes, uuid) {
+		result.DeletedNodes = append(result.DeletedNodes, uuid)
+	}
+	events.node(types.GraphEventDeleted, node)
+	for _, updated := range updatedNodes {
+		result.RedactedNodes = append(result.RedactedNodes, updated.Uuid)
+		events.node(types.GraphEventUpdated, updated)
+	}
+	for _, updated := range updatedEdges {
+		result.RedactedEdges = append(result.RedactedEdges, updated.Uuid)
+		events.edge(types.GraphEventUpdated, updated)
+	}
+	c.emitGraphEvents(ctx, events.events)
+
+	if c.factStore != nil {
+		result.FactStore = &factstore.PurgeStats{}
+		for _, term := range terms {
+			stats, err := c.factStore.PurgeEntity(ctx, node.GroupID, term)
+			if err != nil {
+				return nil, fmt.Errorf("failed to purge fact store: %w", err)
+			}
+			result.FactStore.DeletedNodes += stats.DeletedNodes
+			result.FactStore.DeletedEdges += stats.DeletedEdges
+			result.FactStore.RedactedSources += stats.RedactedSources
+			result.FactStore.RedactedNodes += stats.RedactedNodes
+			result.FactStore.RedactedEdges += stats.RedactedEdges
+		}
+		// Fact store sources share their ID with the episode
+		for _, id := range result.DeletedEpisodes {
+			if err := c.factStore.DeleteSource(ctx, id); err != nil {
+				return nil, fmt.Errorf("failed to delete fact store source %s: %w", id, err)
+			}
+			result.DeletedFactSources = append(result.DeletedFactSources, id)
+		}
+	}
+
+	if result.ForgottenEmbeddings, err = c.forgetEmbeddings(ctx, append(forget, terms...)); err != nil {
+		return nil, err
+	}
+
+	details := result.auditDetails()
+	details["episode_policy"] = string(policy)
+	if digest := c.hashTerm(node.Name); digest != "" {
+		details["name_hmac_sha256"] = digest
+	}
+	result.Audit, err = auditLog.Append(audit.Entry{
+		Action:  AuditActionPurgeEntity,
+		Actor:   opts.Actor,
+		Subject: uuid,
+		Reason:  opts.Reason,
+		Details: details,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge completed but failed to record audit entry: %w", err)
+	}
+
+	c.logger.Info("Purged entity",
+		"entity_uuid", uuid,
+		"deleted_edges", len(result.DeletedEdges),
+		"deleted_episodes", len(result.DeletedEpisodes),
+		"redacted_nodes", len(result.RedactedNodes),
+		"redacted_edges", len(result.RedactedEdges))
+
+	return result, nil
+}
+
+// PurgeBySource removes everything ingested from a source (the Episode.Source
+// URL, file path or identifier): its episodes with the entities and edges
+// only they supported, the source node, the fact store sources and the cached
+// embeddings of the removed text. Once every episode of the source is gone,
+// entities that survive are re-summarized from their remaining episodes, so
+// the purged text is never sent to a model. The purge is recorded in the
+// audit log.
+func (c *Client) PurgeBySource(ctx context.Context, sourceName string) (*PurgeResult, error) {
+	if sourceName == "" {
+		return nil, fmt.Errorf("source name is required")
+	}
+
+	purgeAuditMu.Lock()
+	defer purgeAuditMu.Unlock()
+	auditLog, err := c.openAuditLog()
+	if err != nil {
+		return nil, err
+	}
+
+	groupID := c.config.GroupID
+	ctx, err = c.withUsage(ctx, "", groupID)
+	if err != nil {
+		return nil, err
+	}
+	sourceNode, err := c.findSourceNode(ctx, sourceName, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find source node: %w", err)
+	}
+
+	// Episodes are linked to the source node and recorded as fact store
+	// sources with the episode source in their metadata
+	episodeUUIDs := []string{}
+	if sourceNode != nil {
+		if episodeUUIDs, err = types.GetSourcedEpisodeUUIDs(ctx, &driverWrapper{c.driver}, sourceNode.Uuid); err != nil {
+			return nil, fmt.Errorf("failed to get source episodes: %w", err)
+		}
+	}
+	var factSources []*factstore.Source
+	if c.factStore != nil {
+		if factSources, err = c.selectSources(ctx, &ReprocessSelector{GroupID: groupID, Source: sourceName}); err != nil {
+			return nil, err
+		}
+		for _, source := range factSources {
+			if !containsString(episodeUUIDs, source.ID) {
+				episodeUUIDs = append(episodeUUIDs, source.ID)
+			}
+		}
+	}
+
+	result := newPurgeResult(sourceName)
+	var forget, resummarize, communities []string
+	for _, episodeUUID := range episodeUUIDs {
+		episode, err := types.GetEpisodicNodeByUUID(ctx, c.driver, episodeUUID)
+		if err != nil {
+			// Extract-only sources never reached the graph
+			continue
+		}
+		forget = append(forget, episode.Content)
+		supported, err := types.GetEntityEdgesByEpisode(ctx, &driverWrapper{c.driver}, episodeUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get edges of episode %s: %w", episodeUUID, err)
+		}
+		for _, edge := range supported {
+			forget = append(forget, edge.Fact)
+		}
+
+		// Summaries are rebuilt once every episode of the source is gone, so
+		// the purged text is never sent to the model
+		removal, err := c.RemoveEpisodeWithOptions(ctx, episodeUUID, &RemoveEpisodeOptions{SkipResummarize: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete episode %s: %w", episodeUUID, err)
+		}
+		result.DeletedEpisodes = append(result.DeletedEpisodes, episodeUUID)
+		result.DeletedEdges = append(result.DeletedEdges, removal.DeletedEdges...)
+		result.DeletedNodes = append(result.DeletedNodes, removal.DeletedNodes...)
+		result.DeletedNodes = append(result.DeletedNodes, removal.DeletedSourceNodes...)
+		resummarize = appendMissing(resummarize, removal.ResummarizedNodes...)
+		communities = appendMissing(communities, removal.AffectedCommunities...)
+	}
+
+	// Nodes deleted by a later removal no longer need a summary
+	var survivors []string
+	for _, uuid := range resummarize {
+		if !containsString(result.DeletedNodes, uuid) {
+			survivors = append(survivors, uuid)
+		}
+	}
+	if len(survivors) > 0 || len(communities) > 0 {
+		events := &graphEventBatch{operation: types.GraphOperationPurge}
+		resummarized, deletedCommunities := c.resummarizeAfterRemoval(ctx, groupID, survivors, communities, events)
+		result.ResummarizedNodes = resummarized
+		result.DeletedNodes = append(result.DeletedNodes, deletedCommunities...)
+		c.emitGraphEvents(ctx, events.events)
+	}
+
+	// The source node is not linked to its episodes on every driver
+	if sourceNode != nil && !containsString(result.DeletedNodes, sourceNode.Uuid) {
+		if err := c.driver.DeleteNode(ctx, sourceNode.Uuid, groupID); err != nil {
+			return nil, fmt.Errorf("failed to delete source node: %w", err)
+		}
+		result.DeletedNodes = append(result.DeletedNodes, sourceNode.Uuid)
+		c.emitGraphEvents(ctx, []types.GraphEvent{types.NewNodeEvent(types.GraphEventDeleted, types.GraphOperationPurge, sourceNode)})
+	}
+
+	for _, source := range factSources {
+		forget = append(forget, source.Content)
+		if err := c.factStore.DeleteSource(ctx, source.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete fact store source %s: %w", source.ID, err)
+		}
+		result.DeletedFactSources = append(result.DeletedFactSources, source.ID)
+	}
+
+	if result.ForgottenEmbeddings, err = c.forgetEmbeddings(ctx, forget); err != nil {
+		return nil, err
+	}
+
+	// The source name may itself identify a person, so it is never logged
+	subject := types.RedactedPlaceholder
+	if digest := c.hashTerm(sourceName); digest != "" {
+		subject = "hmac-sha256:" + digest
+	}
+	result.Audit, err = auditLog.Append(audit.Entry{
+		Action:  AuditActionPurgeSource,
+		Subject: subject,
+		Details: result.auditDetails(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("purge completed but failed to record audit entry: %w", err)
+	}
+
+	c.logger.Info("Purged source",
+		"deleted_episodes", len(result.DeletedEpisodes),
+		"deleted_nodes", len(result.DeletedNodes),
+		"deleted_fact_sources", len(result.DeletedFactSources))
+
+	return result, nil
+}
+
+// openAuditLog opens the configured audit log, verifying its hash chain.
+func (c *Client) openAuditLog() (*audit.Log, error) {
+	path := c.config.AuditLogPath
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve audit log path: %w", err)
+		}
+		path = filepath.Join(home, ".predicato", "audit", "purge.jsonl")
+	}
+	log, err := audit.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return log, nil
+}
+
+// reembed embeds redacted text. Without an embedder the embedding is dropped,
+// since the old one was computed from the unredacted text.
+func (c *Client) reembed(ctx context.Context, text string) ([]float32, error) {
+	if c.embedder == nil || text == "" {
+		return nil, nil
+	}
+	embedding, err := c.embedder.EmbedSingle(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed redacted text: %w", err)
+	}
+	return embedding, nil
+}
+
+// forgetEmbeddings drops the cached embeddings of texts if the embedder keeps
+// any, returning the number of texts forgotten.
+func (c *Client) forgetEmbeddings(ctx context.Context, texts []string) (int, error) {
+	forgetter, ok := c.embedder.(embedder.Forgetter)
+	if !ok {
+		return 0, nil
+	}
+	var unique []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		if text != "" && !seen[text] {
+			seen[text] = true
+			unique = append(unique, text)
+		}
+	}
+	if err := forgetter.Forget(ctx, unique); err != nil {
+		return 0, fmt.Errorf("failed to forget cached embeddings: %w", err)
+	}
+	return len(unique), nil
+}
+
+func newPurgeResult(subject string) *PurgeResult {
+	return &PurgeResult{
+		Subject:            subject,
+		DeletedNodes:       []string{},
+		DeletedEdges:       []string{},
+		DeletedEpisodes:    []string{},
+		RedactedNodes:      []string{},
+		RedactedEdges:      []string{},
+		ResummarizedNodes:  []string{},
+		DeletedFactSources: []string{},
+	}
+}
+
+// auditDetails summarizes the result for the audit log. Only UUIDs and
+// counts are recorded, never the purged text.
+func (r *PurgeResult) auditDetails() map[string]interface{} {
+	details := map[string]interface{}{
+		"deleted_nodes":        r.DeletedNodes,
+		"deleted_edges":        r.DeletedEdges,
+		"deleted_episodes":     r.DeletedEpisodes,
+		"redacted_nodes":       r.RedactedNodes,
+		"redacted_edges":       r.RedactedEdges,
+		"resummarized_nodes":   r.ResummarizedNodes,
+		"deleted_fact_sources": r.DeletedFactSources,
+		"forgotten_embeddings": r.ForgottenEmbeddings,
+	}
+	if r.FactStore != nil {
+		details["fact_store"] = r.FactStore
+	}
+	return details
+}
+
+// appendMissing appends the values not yet in values.
+func appendMissing(values []string, more ...string) []string {
+	for _, v := range more {
+		if !containsString(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// hashTerm returns the hex HMAC-SHA256 of a purged term under
+// Config.AuditHMACKey, or "" without a key. A plain hash of a name is
+// reversed by hashing candidate names, so it would keep the purged name in
+// the audit log.
+func (c *Client) hashTerm(term string) string {
+	if len(c.config.AuditHMACKey) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.config.AuditHMACKey)
+	mac.Write([]byte(term))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,335 @@
+package predicato
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/audit"
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// forgettingEmbedder records the texts whose cached embeddings are forgotten.
+type forgettingEmbedder struct {
+	lengthEmbedder
+	forgotten []string
+}
+
+func (e *forgettingEmbedder) Forget(ctx context.Context, texts []string) error {
+	e.forgotten = append(e.forgotten, texts...)
+	return nil
+}
+
+// newPurgeDriver returns a driver holding Bob Smith, mentioned only by ep-1,
+// which also mentions Alice. Edge edge-met links Alice to Bob; edge-referral
+// names Bob but links Alice to Acme and is also supported by ep-2. Bob is a
+// member of comm-1, whose name mentions him.
+func newPurgeDriver() *memoryDriver {
+	d := newMemoryDriver()
+	d.nodes["bob"] = nodeWithSummary("bob", "Bob Smith", "Bob Smith is a patient at the clinic")
+	d.nodes["alice"] = nodeWithSummary("alice", "Alice", "Alice met Bob Smith at the clinic")
+	d.nodes["comm-1"] = &types.Node{Uuid: "comm-1", Name: "Bob Smith and Alice", Summary: "Patients of the clinic", GroupID: "g", Type: types.CommunityNodeType}
+
+	edgeMet := map[string]interface{}{"uuid": "edge-met", "name": "MET", "fact": "Alice met Bob Smith", "group_id": "g",
+		"episodes": []interface{}{"ep-1"}, "source_node_uuid": "alice", "target_node_uuid": "bob", "created_at": time.Now()}
+	edgeReferral := map[string]interface{}{"uuid": "edge-referral", "name": "REFERRED", "fact": "Bob Smith referred Alice to Acme", "group_id": "g",
+		"episodes": []interface{}{"ep-1", "ep-2"}, "source_node_uuid": "alice", "target_node_uuid": "acme", "created_at": time.Now()}
+
+	d.respond("WHERE n.uuid = $node_uuid OR m.uuid = $node_uuid", edgeMet)
+	d.respond("MATCH (e:Episodic)-[:MENTIONS]->(n:Entity {uuid: $uuid})",
+		map[string]interface{}{"uuid": "ep-1", "name": "Visit", "content": "Alice met Bob Smith at the clinic", "group_id": "g"})
+	d.respond("MATCH (c:Community)-[:HAS_MEMBER]->(n:Entity)", map[string]interface{}{"uuid": "comm-1"})
+	d.respond("MATCH (episode:Episodic)-[:MENTIONS]->(n:Entity)",
+		map[string]interface{}{"uuid": "alice", "name": "Alice", "summary": "Alice met Bob Smith at the clinic", "group_id": "g"},
+		map[string]interface{}{"uuid": "bob", "name": "Bob Smith", "summary": "Bob Smith is a patient at the clinic", "group_id": "g"})
+	d.respond("WHERE $episode_uuid IN e.episodes", edgeMet, edgeReferral)
+	d.respond("MATCH (e:Episodic {uuid: $uuid})",
+		map[string]interface{}{"uuid": "ep-1", "name": "Visit", "content": "Alice met Bob Smith at the clinic", "group_id": "g"})
+	// Alice is also mentioned by ep-2, Bob by no other episode
+	d.respond("count(e) AS episode_count", map[string]interface{}{"uuid": "alice", "episode_count": int64(1)})
+	return d
+}
+
+// newPurgeStore returns a fact store holding the extraction of ep-1, which
+// names Bob Smith, and of ep-2, which does not.
+func newPurgeStore(t *testing.T) factstore.FactsDB {
+	t.Helper()
+	ctx := context.Background()
+	store, err := factstore.NewDoltDB("file://" + t.TempDir() + "?commitname=Test&commitemail=test@example.com&database=facts")
+	if err != nil {
+		t.Fatalf("NewDoltDB: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	sources := []struct {
+		source *factstore.Source
+		nodes  []*factstore.ExtractedNode
+		edges  []*factstore.ExtractedEdge
+	}{
+		{
+			&factstore.Source{ID: "ep-1", Name: "Visit", Content: "Alice met Bob Smith at the clinic", GroupID: "g",
+				Metadata: map[string]interface{}{factstore.MetadataKeyEpisodeSource: "clinic-notes.txt"}},
+			[]*factstore.ExtractedNode{{ID: "n-alice-1", Name: "Alice", Type: "Person"}, {ID: "n-bob", Name: "Bob Smith", Type: "Person"}},
+			[]*factstore.ExtractedEdge{{ID: "x-met", SourceNodeName: "Alice", TargetNodeName: "Bob Smith", Relation: "MET", Description: "Alice met Bob Smith"}},
+		},
+		{
+			&factstore.Source{ID: "ep-2", Name: "Hiring", Content: "Alice joined Acme", GroupID: "g"},
+			[]*factstore.ExtractedNode{{ID: "n-alice-2", Name: "Alice", Type: "Person"}, {ID: "n-acme", Name: "Acme", Type: "Organization"}},
+			[]*factstore.ExtractedEdge{{ID: "x-joined", SourceNodeName: "Alice", TargetNodeName: "Acme", Relation: "JOINED", Description: "Alice joined Acme"}},
+		},
+	}
+	for _, s := range sources {
+		s.source.CreatedAt, s.source.Reference = time.Now(), time.Now()
+		if err := store.SaveSource(ctx, s.source); err != nil {
+			t.Fatalf("SaveSource: %v", err)
+		}
+		if err := store.SaveExtractedKnowledge(ctx, s.source.ID, s.nodes, s.edges); err != nil {
+			t.Fatalf("SaveExtractedKnowledge: %v", err)
+		}
+	}
+	return store
+}
+
+// newPurgeClient returns a client of group g over d and store, auditing to a
+// temporary log.
+func newPurgeClient(t *testing.T, d *memoryDriver, store factstore.FactsDB, key []byte) (*Client, *forgettingEmbedder) {
+	t.Helper()
+	embedder := &forgettingEmbedder{lengthEmbedder: lengthEmbedder{model: "test"}}
+	config := &Config{
+		GroupID:      "g",
+		TimeZone:     time.UTC,
+		AuditLogPath: filepath.Join(t.TempDir(), "audit.jsonl"),
+		AuditHMACKey: key,
+	}
+	client, err := NewClient(d, nil, embedder, config, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if store != nil {
+		client = client.WithFactStore(store)
+	}
+	return client, embedder
+}
+
+// assertAuditLogOmits checks that the audit log is intact and that neither
+// the text nor its plain SHA-256 appear in it.
+func assertAuditLogOmits(t *testing.T, path, text string) []*audit.Entry {
+	t.Helper()
+	entries, err := audit.Verify(path)
+	if err != nil {
+		t.Fatalf("audit.Verify: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	sum := sha256.Sum256([]byte(text))
+	if strings.Contains(string(raw), text) || strings.Contains(string(raw), hex.EncodeToString(sum[:])) {
+		t.Errorf("audit log reveals %q: %s", text, raw)
+	}
+	return entries
+}
+
+func hmacHex(key []byte, text string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(text))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TestPurgeEntityRedactsEpisodes tests the default policy: the entity and its
+// edges are deleted and its name is redacted everywhere else
+func TestPurgeEntityRedactsEpisodes(t *testing.T) {
+	ctx := context.Background()
+	d := newPurgeDriver()
+	store := newPurgeStore(t)
+	key := []byte("audit-key")
+	client, embedder := newPurgeClient(t, d, store, key)
+
+	result, err := client.PurgeEntity(ctx, "bob", &PurgeOptions{
+		Terms:  []string{"bob@example.com"},
+		Actor:  "privacy-team",
+		Reason: "DSR-1042",
+	})
+	if err != nil {
+		t.Fatalf("PurgeEntity: %v", err)
+	}
+
+	if !equalStrings(result.DeletedNodes, "bob") || !equalStrings(result.DeletedEdges, "edge-met") || len(result.DeletedEpisodes) != 0 {
+		t.Errorf("deleted nodes %v, edges %v, episodes %v; want bob and edge-met only",
+			result.DeletedNodes, result.DeletedEdges, result.DeletedEpisodes)
+	}
+	if !equalStrings(result.RedactedNodes, "ep-1", "alice", "comm-1") || !equalStrings(result.RedactedEdges, "edge-referral") {
+		t.Errorf("redacted nodes %v, edges %v; want ep-1, alice, comm-1 and edge-referral",
+			result.RedactedNodes, result.RedactedEdges)
+	}
+	for _, fragment := range []string{"DETACH DELETE n", "WHERE e.uuid IN $uuids"} {
+		if len(d.executedContaining(fragment)) == 0 {
+			t.Errorf("no query containing %q was executed", fragment)
+		}
+	}
+
+	// Only the deleted entity itself, removed by query, still names Bob
+	redacted := map[string]string{
+		"episode content":   d.nodes["ep-1"].Content,
+		"entity summary":    d.nodes["alice"].Summary,
+		"community name":    d.nodes["comm-1"].Name,
+		"surviving fact":    d.edges["edge-referral"].Fact,
+		"surviving summary": d.edges["edge-referral"].Summary,
+	}
+	for what, text := range redacted {
+		if strings.Contains(text, "Bob Smith") || !strings.Contains(text, types.RedactedPlaceholder) {
+			t.Errorf("%s = %q, want Bob Smith redacted", what, text)
+		}
+	}
+	if d.nodes["ep-1"].Embedding == nil || d.edges["edge-referral"].FactEmbedding == nil {
+		t.Error("redacted text was not re-embedded")
+	}
+
+	// Fact store rows naming Bob are deleted and his name is redacted from the rest
+	if result.FactStore == nil || result.FactStore.DeletedNodes != 1 || result.FactStore.DeletedEdges != 1 {
+		t.Errorf("fact store stats = %+v, want Bob's node and edge deleted", result.FactStore)
+	}
+	nodes, err := store.GetExtractedNodes(ctx, "ep-1")
+	if err != nil {
+		t.Fatalf("GetExtractedNodes: %v", err)
+	}
+	for _, node := range nodes {
+		if node.Name == "Bob Smith" {
+			t.Error("the fact store still holds Bob's extracted node")
+		}
+	}
+	source, err := store.GetSource(ctx, "ep-1")
+	if err != nil {
+		t.Fatalf("GetSource: %v", err)
+	}
+	if strings.Contains(source.Content, "Bob Smith") {
+		t.Errorf("fact store source content = %q, want Bob Smith redacted", source.Content)
+	}
+	if len(result.DeletedFactSources) != 0 {
+		t.Errorf("redaction deleted fact store sources %v", result.DeletedFactSources)
+	}
+
+	// Cached embeddings of the name, extra terms and every replaced text are dropped
+	for _, text := range []string{"Bob Smith", "bob@example.com", "Alice met Bob Smith at the clinic", "Alice met Bob Smith", "Bob Smith referred Alice to Acme", "Bob Smith and Alice"} {
+		if !containsString(embedder.forgotten, text) {
+			t.Errorf("cached embedding of %q was not forgotten", text)
+		}
+	}
+	if result.ForgottenEmbeddings != len(embedder.forgotten) {
+		t.Errorf("ForgottenEmbeddings = %d, want %d", result.ForgottenEmbeddings, len(embedder.forgotten))
+	}
+
+	entries := assertAuditLogOmits(t, client.config.AuditLogPath, "Bob Smith")
+	if len(entries) != 1 {
+		t.Fatalf("audit log has %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.Action != AuditActionPurgeEntity || entry.Subject != "bob" || entry.Actor != "privacy-team" || entry.Reason != "DSR-1042" {
+		t.Errorf("audit entry = %+v, want the purge of bob by privacy-team", entry)
+	}
+	if entry.Details["episode_policy"] != string(EpisodePurgeRedact) {
+		t.Errorf("episode_policy = %v, want redact", entry.Details["episode_policy"])
+	}
+	if got := entry.Details["name_hmac_sha256"]; got != hmacHex(key, "Bob Smith") {
+		t.Errorf("name_hmac_sha256 = %v, want the HMAC of the name under the audit key", got)
+	}
+	if result.Audit == nil || result.Audit.Hash != entry.Hash {
+		t.Error("the result does not carry the appended audit entry")
+	}
+}
+
+// TestPurgeEntityDeletesEpisodes tests the delete policy: episodes mentioning
+// the entity are removed along with their fact store sources
+func TestPurgeEntityDeletesEpisodes(t *testing.T) {
+	ctx := context.Background()
+	d := newPurgeDriver()
+	store := newPurgeStore(t)
+	client, embedder := newPurgeClient(t, d, store, nil)
+
+	result, err := client.PurgeEntity(ctx, "bob", &PurgeOptions{EpisodePolicy: EpisodePurgeDelete})
+	if err != nil {
+		t.Fatalf("PurgeEntity: %v", err)
+	}
+
+	if !equalStrings(result.DeletedEpisodes, "ep-1") {
+		t.Errorf("DeletedEpisodes = %v, want ep-1", result.DeletedEpisodes)
+	}
+	// The episode removal already deleted Bob and his edge; they are listed once
+	if !equalStrings(result.DeletedNodes, "bob") || !equalStrings(result.DeletedEdges, "edge-met") {
+		t.Errorf("deleted nodes %v, edges %v; want bob and edge-met once", result.DeletedNodes, result.DeletedEdges)
+	}
+	// Surviving facts and summaries are still redacted; the episode is not
+	if containsString(result.RedactedNodes, "ep-1") || !equalStrings(result.RedactedEdges, "edge-referral") {
+		t.Errorf("redacted nodes %v, edges %v; want the surviving edge but not the deleted episode",
+			result.RedactedNodes, result.RedactedEdges)
+	}
+	if len(d.executedContaining("SET e.episodes")) == 0 {
+		t.Error("the deleted episode was not retracted from the shared edge")
+	}
+
+	if !equalStrings(result.DeletedFactSources, "ep-1") {
+		t.Errorf("DeletedFactSources = %v, want ep-1", result.DeletedFactSources)
+	}
+	if source, err := store.GetSource(ctx, "ep-1"); err == nil && source != nil {
+		t.Error("the fact store source of the deleted episode was kept")
+	}
+	if _, err := store.GetSource(ctx, "ep-2"); err != nil {
+		t.Errorf("the fact store source of an unrelated episode was deleted: %v", err)
+	}
+	if !containsString(embedder.forgotten, "Alice met Bob Smith at the clinic") {
+		t.Error("the cached embedding of the deleted episode was not forgotten")
+	}
+
+	// Without an audit key no digest of the name is recorded
+	entries := assertAuditLogOmits(t, client.config.AuditLogPath, "Bob Smith")
+	if len(entries) != 1 || entries[0].Details["episode_policy"] != string(EpisodePurgeDelete) {
+		t.Fatalf("audit entries = %+v, want one delete purge", entries)
+	}
+	if _, ok := entries[0].Details["name_hmac_sha256"]; ok {
+		t.Error("a name digest was recorded without an audit key")
+	}
+}
+
+// TestPurgeBySourceAuditsWithoutTheSourceName tests that the source name,
+// which may identify a person, is kept out of the audit log
+func TestPurgeBySourceAuditsWithoutTheSourceName(t *testing.T) {
+	ctx := context.Background()
+	for _, key := range [][]byte{nil, []byte("audit-key")} {
+		// ep-1 never reached the graph, so only its fact store source is purged
+		store := newPurgeStore(t)
+		client, embedder := newPurgeClient(t, newMemoryDriver(), store, key)
+
+		result, err := client.PurgeBySource(ctx, "clinic-notes.txt")
+		if err != nil {
+			t.Fatalf("PurgeBySource: %v", err)
+		}
+		if !equalStrings(result.DeletedFactSources, "ep-1") {
+			t.Errorf("DeletedFactSources = %v, want ep-1", result.DeletedFactSources)
+		}
+		if !containsString(embedder.forgotten, "Alice met Bob Smith at the clinic") {
+			t.Error("the cached embedding of the source content was not forgotten")
+		}
+
+		entries := assertAuditLogOmits(t, client.config.AuditLogPath, "clinic-notes.txt")
+		if len(entries) != 1 || entries[0].Action != AuditActionPurgeSource {
+			t.Fatalf("audit entries = %+v, want one source purge", entries)
+		}
+		want := types.RedactedPlaceholder
+		if key != nil {
+			want = "hmac-sha256:" + hmacHex(key, "clinic-notes.txt")
+		}
+		if entries[0].Subject != want {
+			t.Errorf("audit subject = %q, want %q", entries[0].Subject, want)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package predicato
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/cache"
+	"github.com/soundprediction/predicato/pkg/driver"
+	"github.com/soundprediction/predicato/pkg/embedder"
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// ReembedResult reports the outcome of ReembedAll.
+type ReembedResult struct {
+	// Space is the embedding space the graph was switched to.
+	Space *types.EmbeddingSpace
+	// Nodes and Edges count the graph objects whose vectors were replaced.
+	Nodes int
+	Edges int
+	// Vectors is the number of graph vectors replaced.
+	Vectors int
+	// Resumed is the number of vectors staged by an earlier, interrupted run.
+	Resumed int
+	// FactVectors is the number of fact store vectors replaced.
+	FactVectors int
+}
+
+// reembedStageTTL bounds how long staged vectors of an abandoned run are kept.
+const reembedStageTTL = 30 * 24 * time.Hour
+
+// reembedPageSize is the number of stored objects read per scan.
+const reembedPageSize = 500
+
+// reembedSwitchAttempts bounds how often the switch is retried when objects
+// changed while their vectors were staged.
+const reembedSwitchAttempts = 3
+
+// errReembedStale reports a stored vector whose text has no staged vector,
+// because the object was added or changed after staging.
+var errReembedStale = errors.New("stored vectors changed while staging")
+
+// reembedScanner is a driver that can re-embed the graph.
+type reembedScanner interface {
+	driver.GraphDriver
+	driver.EmbeddingScanner
+}
+
+// reembedTarget is a stored vector to recompute from text.
+type reembedTarget struct {
+	key     string
+	text    string
+	current []float32
+	assign  func([]float32)
+}
+
+// reembedSet holds a page of graph objects and their vectors.
+type reembedSet struct {
+	nodes   []*types.Node
+	edges   []*types.Edge
+	targets []*reembedTarget
+}
+
+// ReembedAll re-embeds every stored vector with newEmbedder, batchSize texts
+// per call, and records its embedding space on the graph and fact store.
+// The driver must implement driver.EmbeddingScanner.
+//
+// The new vectors of the graph and the fact store are first staged alongside
+// the old ones, which stay in use. Once every vector is staged they replace
+// the old ones in one write transaction per store, so searches see either the
+// old or the new model, never a mix; the graph only records the new space
+// once every stored vector has been verified to hold its new value. If the
+// run is interrupted, calling ReembedAll again only embeds what was not
+// staged yet; set Config.ReembedStagingPath to resume across restarts.
+//
+// After the switch this client's embedder no longer matches the graph and
+// its searches fail with ErrEmbeddingSpaceMismatch; create a client with
+// newEmbedder to query the re-embedded graph.
+func (c *Client) ReembedAll(ctx context.Context, newEmbedder embedder.Client, batchSize int) (*ReembedResult, error) {
+	if newEmbedder == nil {
+		return nil, fmt.Errorf("embedder is required for ReembedAll")
+	}
+	scanner, ok := c.driver.(reembedScanner)
+	if !ok {
+		return nil, fmt.Errorf("driver %T cannot scan its stored vectors for re-embedding", c.driver)
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	stage, err := c.reembedStage()
+	if err != nil {
+		return nil, err
+	}
+	migrator, _ := c.factStore.(factstore.Migrator)
+	modelID := embedder.ModelID(newEmbedder)
+	space := &types.EmbeddingSpace{Model: modelID, Dimensions: newEmbedder.Dimensions()}
+
+	var result *ReembedResult
+	for attempt := 1; ; attempt++ {
+		// Stage the new vectors while the old ones keep serving searches
+		resumed, err := c.stageReembedVectors(ctx, scanner, migrator, stage, modelID, newEmbedder, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		// Switch over atomically. Objects are scanned again so that changes
+		// made while staging are kept; if any has no staged vector the
+		// switch is abandoned and the changes are staged first.
+		space.UpdatedAt = time.Now()
+		err = c.withWriteTransaction(ctx, func(ctx context.Context) error {
+			// Count afresh if the driver retries the transaction
+			result = &ReembedResult{Resumed: resumed}
+			return c.switchReembedVectors(ctx, scanner, migrator, stage, space, result)
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errReembedStale) || attempt == reembedSwitchAttempts {
+			return nil, fmt.Errorf("failed to switch embeddings: %w", err)
+		}
+	}
+	result.Space = space
+
+	c.embeddingSpace.mu.Lock()
+	c.embeddingSpace.graphChecked = false
+	c.embeddingSpace.factsChecked = false
+	c.embeddingSpace.mu.Unlock()
+
+	// The staged vectors are now stored in the graph
+	c.clearReembedStage()
+	return result, nil
+}
+
+// stageReembedVectors embeds every stored vector of the graph and fact store
+// that is not staged yet and returns the number already staged.
+func (c *Client) stageReembedVectors(ctx context.Context, scanner reembedScanner, migrator factstore.Migrator, stage cache.Cache,
+	modelID string, emb embedder.Client, batchSize int) (int, error) {
+	resumed := 0
+	stagePage := func(set *reembedSet) error {
+		n, err := stageVectors(ctx, stage, emb, set.targets, batchSize)
+		resumed += n
+		return err
+	}
+	if err := scanReembedGraph(ctx, scanner, modelID, stagePage); err != nil {
+		return 0, err
+	}
+	if migrator != nil {
+		if err := scanReembedFacts(ctx, migrator, modelID, stagePage); err != nil {
+			return 0, err
+		}
+	}
+	return resumed, nil
+}
+
+// switchReembedVectors replaces every stored vector with its staged one,
+// verifies them and records space, within the graph write transaction of
+// ctx. The fact store is switched last, so a failure there rolls the graph
+// back too.
+func (c *Client) switchReembedVectors(ctx context.Context, scanner reembedScanner, migrator factstore.Migrator, stage cache.Cache,
+	space *types.EmbeddingSpace, result *ReembedResult) error {
+	err := scanReembedGraph(ctx, scanner, space.Model, func(set *reembedSet) error {
+		for _, target := range set.targets {
+			vector, err := loadReembedVector(stage, target.key)
+			if err != nil {
+				return err
+			}
+			if space.Dimensions == 0 {
+				space.Dimensions = len(vector)
+			}
+			target.assign(vector)
+		}
+		if len(set.nodes) > 0 {
+			if err := scanner.SetNodeEmbeddings(ctx, set.nodes); err != nil {
+				return fmt.Errorf("failed to write re-embedded nodes: %w", err)
+			}
+		}
+		if len(set.edges) > 0 {
+			if err := scanner.SetEdgeEmbeddings(ctx, set.edges); err != nil {
+				return fmt.Errorf("failed to write re-embedded edges: %w", err)
+			}
+		}
+		result.Nodes += len(set.nodes)
+		result.Edges += len(set.edges)
+		result.Vectors += len(set.targets)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Record the new space only if every stored vector now holds its staged value
+	verified := 0
+	err = scanReembedGraph(ctx, scanner, space.Model, func(set *reembedSet) error {
+		for _, target := range set.targets {
+			vector, err := loadReembedVector(stage, target.key)
+			if err != nil {
+				return err
+			}
+			if !equalVectors(target.current, vector) {
+				return fmt.Errorf("vector %s was not rewritten", target.key)
+			}
+			verified++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if verified != result.Vectors {
+		return fmt.Errorf("verified %d of %d re-embedded vectors", verified, result.Vectors)
+	}
+	if store, ok := c.driver.(driver.EmbeddingSpaceStore); ok {
+		if err := store.SetEmbeddingSpace(ctx, space); err != nil {
+			return err
+		}
+	}
+
+	if migrator != nil {
+		result.FactVectors, err = migrator.ReplaceEmbeddings(ctx, space, func(text factstore.VectorText) ([]float32, error) {
+			return loadReembedVector(stage, reembedKey(space.Model, "fact:"+text.Table, text.ID, text.Text))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to re-embed fact store: %w", err)
+		}
+	}
+	return nil
+}
+
+// scanReembedGraph pages through every node and edge of every group, calling
+// fn with the vectors of each page that should be recomputed.
+func scanReembedGraph(ctx context.Context, scanner driver.EmbeddingScanner, modelID string, fn func(*reembedSet) error) error {
+	for _, nodeType := range []types.NodeType{types.EntityNodeType, types.EpisodicNodeType, types.CommunityNodeType} {
+		after := ""
+		for {
+			nodes, err := scanner.ScanNodes(ctx, nodeType, after, reembedPageSize)
+			if err != nil {
+				return err
+			}
+			set := &reembedSet{}
+			for _, node := range nodes {
+				added, err := set.addNode(modelID, node)
+				if err != nil {
+					return err
+				}
+				if added {
+					set.nodes = append(set.nodes, node)
+				}
+			}
+			if err := fn(set); err != nil {
+				return err
+			}
+			if len(nodes) < reembedPageSize {
+				break
+			}
+			after = nodes[len(nodes)-1].Uuid
+		}
+	}
+
+	after := ""
+	for {
+		edges, err := scanner.ScanEdges(ctx, after, reembedPageSize)
+		if err != nil {
+			return err
+		}
+		set := &reembedSet{}
+		for _, edge := range edges {
+			added, err := set.addEdge(modelID, edge)
+			if err != nil {
+				return err
+			}
+			if added {
+				set.edges = append(set.edges, edge)
+			}
+		}
+		if err := fn(set); err != nil {
+			return err
+		}
+		if len(edges) < reembedPageSize {
+			return nil
+		}
+		after = edges[len(edges)-1].Uuid
+	}
+}
+
+// scanReembedFacts pages through every vector of the fact store, calling fn
+// with each page.
+func scanReembedFacts(ctx context.Context, migrator factstore.Migrator, modelID string, fn func(*reembedSet) error) error {
+	var after *factstore.VectorText
+	for {
+		texts, err := migrator.ScanVectorTexts(ctx, after, reembedPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to scan fact store vectors: %w", err)
+		}
+		set := &reembedSet{}
+		for _, text := range texts {
+			if text.Text == "" {
+				return fmt.Errorf("cannot re-embed %s %s: its vector has no source text", text.Table, text.ID)
+			}
+			set.add(modelID, "fact:"+text.Table, text.ID, text.Text, nil, nil)
+		}
+		if err := fn(set); err != nil {
+			return err
+		}
+		if len(texts) < reembedPageSize {
+			return nil
+		}
+		after = &texts[len(texts)-1]
+	}
+}
+
+// reembedStage returns the store of staged vectors, keeping it open across
+// runs so an interrupted run can resume.
+func (c *Client) reembedStage() (cache.Cache, error) {
+	c.embeddingSpace.mu.Lock()
+	defer c.embeddingSpace.mu.Unlock()
+	if c.embeddingSpace.stage != nil {
+		return c.embeddingSpace.stage, nil
+	}
+	if c.config.ReembedStagingPath == "" {
+		c.embeddingSpace.stage = newMemoryStage()
+		return c.embeddingSpace.stage, nil
+	}
+	stage, err := cache.NewBadgerCache(c.config.ReembedStagingPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open re-embedding stage: %w", err)
+	}
+	c.embeddingSpace.stage = stage
+	return stage, nil
+}
+
+// clearReembedStage discards the staged vectors of a finished run. A Badger
+// stage is closed; its directory can be deleted.
+func (c *Client) clearReembedStage() {
+	c.embeddingSpace.mu.Lock()
+	defer c.embeddingSpace.mu.Unlock()
+	if c.embeddingSpace.stage != nil {
+		c.embeddingSpace.stage.Close()
+		c.embeddingSpace.stage = nil
+	}
+}
+
+// addNode adds the vectors of node, reporting whether it has any. A vector
+// without the text it was computed from cannot be re-embedded.
+func (s *reembedSet) addNode(modelID string, node *types.Node) (bool, error) {
+	added := false
+	if len(node.NameEmbedding) > 0 {
+		if node.Name == "" {
+			return false, fmt.Errorf("cannot re-embed node %s: its name vector has no name", node.Uuid)
+		}
+		s.add(modelID, "node_name", node.Uuid, node.Name, node.NameEmbedding, func(v []float32) { node.NameEmbedding = v })
+		added = true
+	}
+	if len(node.Embedding) > 0 {
+		// The texts match those embedded at ingestion
+		text := node.Name
+		switch node.Type {
+		case types.EntityNodeType:
+			if node.Summary != "" {
+				text += " " + node.Summary
+			}
+		case types.EpisodicNodeType:
+			text = node.Content
+		}
+		if text == "" {
+			return false, fmt.Errorf("cannot re-embed node %s: its vector has no source text", node.Uuid)
+		}
+		s.add(modelID, "node", node.Uuid, text, node.Embedding, func(v []float32) { node.Embedding = v })
+		added = true
+	}
+	return added, nil
+}
+
+// addEdge adds the vectors of edge, reporting whether it has any.
+func (s *reembedSet) addEdge(modelID string, edge *types.Edge) (bool, error) {
+	added := false
+	if len(edge.FactEmbedding) > 0 {
+		if edge.Fact == "" {
+			return false, fmt.Errorf("cannot re-embed edge %s: its fact vector has no fact", edge.Uuid)
+		}
+		s.add(modelID, "edge_fact", edge.Uuid, edge.Fact, edge.FactEmbedding, func(v []float32) { edge.FactEmbedding = v })
+		added = true
+	}
+	if len(edge.Embedding) > 0 {
+		if edge.Summary == "" {
+			return false, fmt.Errorf("cannot re-embed edge %s: its vector has no summary", edge.Uuid)
+		}
+		s.add(modelID, "edge", edge.Uuid, edge.Summary, edge.Embedding, func(v []float32) { edge.Embedding = v })
+		added = true
+	}
+	return added, nil
+}
+
+// add records a vector to recompute, with its current value.
+func (s *reembedSet) add(modelID, kind, uuid, text string, current []float32, assign func([]float32)) {
+	s.targets = append(s.targets, &reembedTarget{key: reembedKey(modelID, kind, uuid, text), text: text, current: current, assign: assign})
+}
+
+// reembedKey returns the staging key of a vector. It covers the model and
+// the text, so a vector is embedded again if its text changed since staging.
+func reembedKey(modelID, kind, uuid, text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return fmt.Sprintf("reembed:%s:%s:%s:%s", modelID, kind, uuid, hex.EncodeToString(sum[:]))
+}
+
+// stageVectors embeds the targets that are not staged yet, batchSize texts
+// per call, and returns the number of targets that were already staged.
+func stageVectors(ctx context.Context, stage cache.Cache, emb embedder.Client, targets []*reembedTarget, batchSize int) (int, error) {
+	var pending []*reembedTarget
+	for _, target := range targets {
+		if _, err := stage.Get(target.key); err == nil {
+			continue
+		} else if !errors.Is(err, cache.ErrKeyNotFound) {
+			return 0, fmt.Errorf("failed to read staged vector: %w", err)
+		}
+		pending = append(pending, target)
+	}
+
+	for start := 0; start < len(pending); start += batchSize {
+		end := min(start+batchSize, len(pending))
+		texts := make([]string, end-start)
+		for i, target := range pending[start:end] {
+			texts[i] = target.text
+		}
+		vectors, err := emb.Embed(ctx, texts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed texts: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return 0, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+		}
+		for i, target := range pending[start:end] {
+			data, err := json.Marshal(vectors[i])
+			if err != nil {
+				return 0, fmt.Errorf("failed to encode vector: %w", err)
+			}
+			if err := stage.Set(target.key, data, reembedStageTTL); err != nil {
+				return 0, fmt.Errorf("failed to stage vector: %w", err)
+			}
+		}
+	}
+	return len(targets) - len(pending), nil
+}
+
+// loadReembedVector returns the staged vector of key, or errReembedStale if
+// it was not staged.
+func loadReembedVector(stage cache.Cache, key string) ([]float32, error) {
+	data, err := stage.Get(key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, fmt.Errorf("%w: %s", errReembedStale, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged vector: %w", err)
+	}
+	var vector []float32
+	if err := json.Unmarshal(data, &vector); err != nil {
+		return nil, fmt.Errorf("failed to decode staged vector: %w", err)
+	}
+	return vector, nil
+}
+
+// equalVectors reports whether a and b hold the same values.
+func equalVectors(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryStage is a cache.Cache kept in memory for the lifetime of a client.
+type memoryStage struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryStage() *memoryStage {
+	return &memoryStage{values: make(map[string][]byte)}
+}
+
+func (m *memoryStage) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryStage) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return nil, cache.ErrKeyNotFound
+	}
+	return value, nil
+}
+
+func (m *memoryStage) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memoryStage) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values = nil
+	return nil
+}
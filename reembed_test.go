@@ -0,0 +1,99 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// TestReembedAll tests that every stored vector is re-embedded, across scan
+// pages, before the new embedding space is recorded
+func TestReembedAll(t *testing.T) {
+	ctx := context.Background()
+	d := newMemoryDriver()
+	old := []float32{0, 0, 0}
+	for i := 0; i < reembedPageSize+10; i++ {
+		uuid := fmt.Sprintf("entity-%04d", i)
+		d.nodes[uuid] = &types.Node{Uuid: uuid, GroupID: fmt.Sprintf("group-%d", i%2), Type: types.EntityNodeType,
+			Name: fmt.Sprintf("Entity %d", i), Summary: "A thing", NameEmbedding: old, Embedding: old}
+	}
+	d.nodes["episode"] = &types.Node{Uuid: "episode", GroupID: "group-0", Type: types.EpisodicNodeType,
+		Name: "Episode", Content: "Alice met Bob", Embedding: old}
+	d.nodes["community"] = &types.Node{Uuid: "community", GroupID: "group-1", Type: types.CommunityNodeType,
+		Name: "People", NameEmbedding: old}
+	d.nodes["plain"] = &types.Node{Uuid: "plain", GroupID: "group-0", Type: types.EntityNodeType, Name: "No vectors"}
+	d.edges["edge"] = &types.Edge{BaseEdge: types.BaseEdge{Uuid: "edge", GroupID: "group-0"}, Type: types.EntityEdgeType,
+		Fact: "Alice knows Bob", FactEmbedding: old}
+
+	client, err := NewClient(d, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	emb := &lengthEmbedder{model: "new-model"}
+	result, err := client.ReembedAll(ctx, emb, 100)
+	if err != nil {
+		t.Fatalf("ReembedAll: %v", err)
+	}
+
+	wantNodes := reembedPageSize + 12
+	wantVectors := 2*(reembedPageSize+10) + 3
+	if result.Nodes != wantNodes || result.Edges != 1 || result.Vectors != wantVectors {
+		t.Errorf("result = %+v, want %d nodes, 1 edge and %d vectors", result, wantNodes, wantVectors)
+	}
+	if d.space == nil || d.space.Model != "new-model" || d.space.Dimensions != 2 {
+		t.Errorf("space = %+v, want new-model with 2 dimensions", d.space)
+	}
+	for uuid, node := range d.nodes {
+		if uuid == "plain" {
+			if node.NameEmbedding != nil || node.Embedding != nil {
+				t.Errorf("node without vectors was given %v, %v", node.NameEmbedding, node.Embedding)
+			}
+			continue
+		}
+		if node.NameEmbedding != nil && node.NameEmbedding[0] != float32(len(node.Name)) {
+			t.Errorf("node %s name vector = %v, want its name re-embedded", uuid, node.NameEmbedding)
+		}
+		if node.Embedding != nil && len(node.Embedding) != 2 {
+			t.Errorf("node %s vector = %v, want it re-embedded", uuid, node.Embedding)
+		}
+	}
+	if v := d.nodes["episode"].Embedding; v[0] != float32(len("Alice met Bob")) {
+		t.Errorf("episode vector = %v, want its content re-embedded", v)
+	}
+	if v := d.nodes["entity-0000"].Embedding; v[0] != float32(len("Entity 0 A thing")) {
+		t.Errorf("entity vector = %v, want its name and summary re-embedded", v)
+	}
+	if v := d.edges["edge"].FactEmbedding; v[0] != float32(len("Alice knows Bob")) {
+		t.Errorf("edge vector = %v, want its fact re-embedded", v)
+	}
+
+	// A second run finds every vector already in the new space
+	if _, err := client.ReembedAll(ctx, emb, 100); err != nil {
+		t.Fatalf("second ReembedAll: %v", err)
+	}
+}
+
+// TestReembedAllRefusesVectorsWithoutText tests that the new space is not
+// recorded when a stored vector cannot be recomputed
+func TestReembedAllRefusesVectorsWithoutText(t *testing.T) {
+	ctx := context.Background()
+	d := newMemoryDriver()
+	d.nodes["named"] = &types.Node{Uuid: "named", Type: types.EntityNodeType, Name: "Alice", NameEmbedding: []float32{0, 0, 0}}
+	d.nodes["unnamed"] = &types.Node{Uuid: "unnamed", Type: types.EntityNodeType, NameEmbedding: []float32{0, 0, 0}}
+
+	client, err := NewClient(d, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.ReembedAll(ctx, &lengthEmbedder{model: "new-model"}, 10); err == nil {
+		t.Fatal("ReembedAll succeeded, want an error for the vector without text")
+	}
+	if d.space != nil {
+		t.Errorf("space = %+v, want none recorded", d.space)
+	}
+	if v := d.nodes["named"].NameEmbedding; len(v) != 3 {
+		t.Errorf("named vector = %v, want it unchanged", v)
+	}
+}
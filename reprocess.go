@@ -0,0 +1,400 @@
+package predicato
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/types"
+	"github.com/soundprediction/predicato/pkg/utils"
+)
+
+// ReprocessSelector selects the fact store sources to re-process.
+// Empty fields match every source; set fields are combined with AND.
+type ReprocessSelector struct {
+	// SourceIDs restricts re-processing to specific sources (episode UUIDs).
+	SourceIDs []string
+	// GroupID matches sources of a single group.
+	GroupID string
+	// TimeRange matches sources created within the range.
+	TimeRange *types.TimeRange
+	// Source matches the source name or the recorded episode source (URL, file path, ...).
+	Source string
+	// PromptVersion matches sources extracted with the given prompt version.
+	// Sources extracted before prompt versions were recorded have an empty version.
+	PromptVersion string
+	// Limit caps the number of sources re-processed. Zero means no limit.
+	Limit int
+}
+
+// ReprocessOptions configures ReprocessEpisodes.
+type ReprocessOptions struct {
+	// EpisodeOptions are used for extraction and promotion. Set PromptVersion to
+	// record the version the sources are re-processed with.
+	EpisodeOptions *AddEpisodeOptions
+	// NlpModels overrides the client's models for re-extraction. Nil fields fall
+	// back to the client's models.
+	NlpModels *NlpModels
+	// DryRun computes the diffs without touching the fact store or the graph.
+	DryRun bool
+}
+
+// ReprocessResult describes the outcome of re-processing a single source.
+type ReprocessResult struct {
+	SourceID string                    `json:"source_id"`
+	Diff     *factstore.ExtractionDiff `json:"diff,omitempty"`
+	// RetractedEdges are the UUIDs of graph edges that lost the support of this source.
+	RetractedEdges []string `json:"retracted_edges,omitempty"`
+	// ExpiredEdges are the retracted edges that had no other supporting episode and were expired.
+	ExpiredEdges []string `json:"expired_edges,omitempty"`
+	// Promotion holds the graph changes made for added facts. Nil if nothing was promoted.
+	Promotion *types.AddEpisodeResults `json:"promotion,omitempty"`
+	Error     error                    `json:"-"`
+}
+
+// ReprocessResults summarizes a ReprocessEpisodes run.
+type ReprocessResults struct {
+	Results   []*ReprocessResult `json:"results"`
+	Changed   int                `json:"changed"`
+	Unchanged int                `json:"unchanged"`
+	Failed    int                `json:"failed"`
+	DryRun    bool               `json:"dry_run"`
+}
+
+// ReprocessEpisodes re-runs extraction on stored sources, for example with a newer model
+// or prompt version, and applies only the differences to the graph. The new extraction is
+// diffed against the one stored in the fact store: added facts are promoted, and edges that
+// are no longer supported lose this episode's support and are expired once unsupported.
+// Entities are never deleted since they may be mentioned by other episodes.
+//
+// A failure on one source is recorded in its result and does not stop the run.
+func (c *Client) ReprocessEpisodes(ctx context.Context, selector *ReprocessSelector, options *ReprocessOptions) (*ReprocessResults, error) {
+	if c.factStore == nil {
+		return nil, fmt.Errorf("facts DB not configured")
+	}
+	if selector == nil {
+		selector = &ReprocessSelector{}
+	}
+	if options == nil {
+		options = &ReprocessOptions{}
+	}
+	episodeOptions := options.EpisodeOptions
+	if episodeOptions == nil {
+		episodeOptions = &AddEpisodeOptions{}
+	}
+
+	sources, err := c.selectSources(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	extractor := c
+	if options.NlpModels != nil {
+		extractor = c.withNlpModels(options.NlpModels)
+	}
+
+	results := &ReprocessResults{DryRun: options.DryRun}
+	for _, source := range sources {
+		result := extractor.reprocessSource(ctx, source, episodeOptions, options.DryRun)
+		results.Results = append(results.Results, result)
+
+		switch {
+		case result.Error != nil:
+			results.Failed++
+			c.logger.Warn("Failed to reprocess source",
+				"source_id", source.ID,
+				"error", result.Error)
+		case result.Diff.IsEmpty():
+			results.Unchanged++
+		default:
+			results.Changed++
+		}
+	}
+
+	c.logger.Info("Reprocessing complete",
+		"sources", len(sources),
+		"changed", results.Changed,
+		"unchanged", results.Unchanged,
+		"failed", results.Failed,
+		"dry_run", options.DryRun)
+
+	return results, nil
+}
+
+// selectSources returns the sources matching the selector, oldest first so that
+// episode context is rebuilt in ingestion order.
+func (c *Client) selectSources(ctx context.Context, selector *ReprocessSelector) ([]*factstore.Source, error) {
+	var candidates []*factstore.Source
+	if len(selector.SourceIDs) > 0 {
+		for _, id := range selector.SourceIDs {
+			source, err := c.factStore.GetSource(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get source %s: %w", id, err)
+			}
+			candidates = append(candidates, source)
+		}
+	} else {
+		all, err := c.factStore.GetAllSources(ctx, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sources: %w", err)
+		}
+		// GetAllSources returns the newest first
+		for i := len(all) - 1; i >= 0; i-- {
+			candidates = append(candidates, all[i])
+		}
+	}
+
+	var selected []*factstore.Source
+	for _, source := range candidates {
+		if !selector.matches(source) {
+			continue
+		}
+		selected = append(selected, source)
+		if selector.Limit > 0 && len(selected) >= selector.Limit {
+			break
+		}
+	}
+	return selected, nil
+}
+
+// matches reports whether a source satisfies the selector.
+func (s *ReprocessSelector) matches(source *factstore.Source) bool {
+	if s.GroupID != "" && source.GroupID != s.GroupID {
+		return false
+	}
+	if s.TimeRange != nil {
+		if !s.TimeRange.Start.IsZero() && source.CreatedAt.Before(s.TimeRange.Start) {
+			return false
+		}
+		if !s.TimeRange.End.IsZero() && source.CreatedAt.After(s.TimeRange.End) {
+			return false
+		}
+	}
+	if s.Source != "" && source.Name != s.Source && metadataString(source.Metadata, factstore.MetadataKeyEpisodeSource) != s.Source {
+		return false
+	}
+	if s.PromptVersion != "" && metadataString(source.Metadata, factstore.MetadataKeyPromptVersion) != s.PromptVersion {
+		return false
+	}
+	return true
+}
+
+// metadataString returns a string metadata value, or "" if missing.
+func metadataString(metadata map[string]interface{}, key string) string {
+	if v, ok := metadata[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// withNlpModels returns a shallow copy of the client using the given models.
+// Nil models fall back to the client's own.
+func (c *Client) withNlpModels(models *NlpModels) *Client {
+	clone := *c
+	if models.NodeExtraction != nil {
+		clone.nlpModels.NodeExtraction = models.NodeExtraction
+	}
+	if models.NodeReflexion != nil {
+		clone.nlpModels.NodeReflexion = models.NodeReflexion
+	}
+	if models.NodeResolution != nil {
+		clone.nlpModels.NodeResolution = models.NodeResolution
+	}
+	if models.NodeAttribute != nil {
+		clone.nlpModels.NodeAttribute = models.NodeAttribute
+	}
+	if models.EdgeExtraction != nil {
+		clone.nlpModels.EdgeExtraction = models.EdgeExtraction
+	}
+	if models.EdgeResolution != nil {
+		clone.nlpModels.EdgeResolution = models.EdgeResolution
+	}
+	if models.Summarization != nil {
+		clone.nlpModels.Summarization = models.Summarization
+	}
+	if models.TextGeneration != nil {
+		clone.nlpModels.TextGeneration = models.TextGeneration
+	}
+	return &clone
+}
+
+// reprocessSource re-extracts a single source and applies the diff to the fact store and graph.
+func (c *Client) reprocessSource(ctx context.Context, source *factstore.Source, options *AddEpisodeOptions, dryRun bool) *ReprocessResult {
+	result := &ReprocessResult{SourceID: source.ID}
+
+	ctx, err := c.withUsage(ctx, options.Usage, source.GroupID)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	oldNodes, err := c.factStore.GetExtractedNodes(ctx, source.ID)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to get extracted nodes: %w", err)
+		return result
+	}
+	oldEdges, err := c.factStore.GetExtractedEdges(ctx, source.ID)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to get extracted edges: %w", err)
+		return result
+	}
+
+	episode := types.Episode{
+		ID:        source.ID,
+		Name:      source.Name,
+		Content:   source.Content,
+		Source:    metadataString(source.Metadata, factstore.MetadataKeyEpisodeSource),
+		GroupID:   source.GroupID,
+		Metadata:  source.Metadata,
+		CreatedAt: source.CreatedAt,
+		Reference: source.Reference,
+	}
+
+	extraction, err := c.extractFacts(ctx, &episode, options)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to re-extract: %w", err)
+		return result
+	}
+
+	diff := factstore.DiffExtractedKnowledge(oldNodes, oldEdges, extraction.ExtractedNodes, extraction.ExtractedEdges)
+	result.Diff = diff
+	if dryRun {
+		return result
+	}
+
+	// The graph is updated first and the fact store last, so a failure leaves
+	// the stored extraction untouched and the next run applies the same diff.
+	// Both graph steps are idempotent: retraction skips edges that no longer
+	// cite the episode, and promotion resolves against existing entities.
+	if len(diff.RemovedEdges) > 0 {
+		retracted, expired, err := c.retractEdges(ctx, episode, diff.RemovedEdges, time.Now().UTC())
+		result.RetractedEdges = retracted
+		result.ExpiredEdges = expired
+		if err != nil {
+			result.Error = fmt.Errorf("failed to retract edges: %w", err)
+			return result
+		}
+	}
+
+	if len(diff.AddedNodes) > 0 || len(diff.AddedEdges) > 0 {
+		promotion, err := c.promoteFacts(ctx, source, promotableNodes(diff, extraction.ExtractedNodes), diff.AddedEdges, options)
+		if err != nil {
+			result.Error = fmt.Errorf("failed to promote changes: %w", err)
+			return result
+		}
+		result.Promotion = promotion
+	}
+
+	// Keep the rows of unchanged facts so their IDs stay stable, and add the new ones
+	if !diff.IsEmpty() {
+		keptNodes := append(append([]*factstore.ExtractedNode{}, diff.UnchangedNodes...), diff.AddedNodes...)
+		keptEdges := append(append([]*factstore.ExtractedEdge{}, diff.UnchangedEdges...), diff.AddedEdges...)
+		if err := c.factStore.ReplaceExtractedKnowledge(ctx, source.ID, keptNodes, keptEdges); err != nil {
+			result.Error = fmt.Errorf("failed to replace extracted knowledge: %w", err)
+			return result
+		}
+	}
+
+	// Record the new prompt version even if nothing changed so the source is not selected
+	// again. The diff was promoted above, so SaveSource keeps the promotion state.
+	if err := c.factStore.SaveSource(ctx, sourceFromEpisode(episode, options)); err != nil {
+		result.Error = fmt.Errorf("failed to save source: %w", err)
+		return result
+	}
+	if err := c.commitExtraction(ctx, episode, options); err != nil {
+		result.Error = err
+		return result
+	}
+
+	return result
+}
+
+// promotableNodes returns the added nodes plus the nodes of the new extraction that
+// added edges need as endpoints. Already promoted endpoints are resolved against the
+// existing graph entities during promotion.
+func promotableNodes(diff *factstore.ExtractionDiff, extracted []*factstore.ExtractedNode) []*factstore.ExtractedNode {
+	endpoints := make(map[string]bool)
+	for _, e := range diff.AddedEdges {
+		endpoints[e.SourceNodeName] = true
+		endpoints[e.TargetNodeName] = true
+	}
+
+	nodes := append([]*factstore.ExtractedNode{}, diff.AddedNodes...)
+	seen := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		seen[n.Name] = true
+	}
+	for _, n := range extracted {
+		if endpoints[n.Name] && !seen[n.Name] {
+			seen[n.Name] = true
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// retractEdges removes the episode's support from graph edges matching the removed
+// extracted edges. Edges are located through the entities the episode mentions.
+// Returns the UUIDs of the retracted edges and of those that were expired.
+func (c *Client) retractEdges(ctx context.Context, episode types.Episode, removed []*factstore.ExtractedEdge, at time.Time) ([]string, []string, error) {
+	mentioned, err := types.GetMentionedNodes(ctx, c.driver, []*types.Node{{Uuid: episode.ID}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get mentioned nodes: %w", err)
+	}
+
+	uuidsByName := make(map[string][]string)
+	for _, n := range mentioned {
+		key := utils.NormalizeStringExact(n.Name)
+		uuidsByName[key] = append(uuidsByName[key], n.Uuid)
+	}
+
+	wrapper := &driverWrapper{c.driver}
+	var retracted, expired []string
+	visited := make(map[string]bool)
+	events := &graphEventBatch{operation: types.GraphOperationReprocess, episodeUUID: episode.ID}
+	defer func() { c.emitGraphEvents(ctx, events.events) }()
+
+	for _, e := range removed {
+		relation := utils.NormalizeStringExact(e.Relation)
+		for _, sourceUUID := range uuidsByName[utils.NormalizeStringExact(e.SourceNodeName)] {
+			for _, targetUUID := range uuidsByName[utils.NormalizeStringExact(e.TargetNodeName)] {
+				edges, err := types.GetEntityEdgesBetweenNodes(ctx, wrapper, sourceUUID, targetUUID)
+				if err != nil {
+					return retracted, expired, fmt.Errorf("failed to get edges between %s and %s: %w", sourceUUID, targetUUID, err)
+				}
+
+				for _, edge := range edges {
+					if visited[edge.Uuid] || utils.NormalizeStringExact(edge.Name) != relation || !containsString(edge.Episodes, episode.ID) {
+						continue
+					}
+					visited[edge.Uuid] = true
+
+					wasExpired, err := types.RetractEntityEdgeEpisode(ctx, wrapper, edge, episode.ID, at)
+					if err != nil {
+						return retracted, expired, fmt.Errorf("failed to retract edge %s: %w", edge.Uuid, err)
+					}
+					retracted = append(retracted, edge.Uuid)
+					if wasExpired {
+						expired = append(expired, edge.Uuid)
+						events.edge(types.GraphEventInvalidated, edge)
+					} else {
+						events.edge(types.GraphEventUpdated, edge)
+					}
+				}
+			}
+		}
+	}
+
+	return retracted, expired, nil
+}
+
+// containsString reports whether s is in values.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package predicato
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/factstore"
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// fixedExtractor extracts the same entities every time and a relation for
+// each entry of relations between the named entities.
+type fixedExtractor struct {
+	entities  []string
+	relations [][3]string
+}
+
+func (f *fixedExtractor) ExtractTypedEntities(ctx context.Context, req *nlp.EntityExtractionRequest) ([]nlp.ExtractedEntity, error) {
+	entities := make([]nlp.ExtractedEntity, len(f.entities))
+	for i, name := range f.entities {
+		entities[i] = nlp.ExtractedEntity{Name: name, Span: types.LocateSpan(req.Text, name)}
+	}
+	return entities, nil
+}
+
+func (f *fixedExtractor) ExtractTypedRelations(ctx context.Context, req *nlp.RelationExtractionRequest) ([]nlp.ExtractedRelation, error) {
+	index := make(map[string]int, len(req.Entities))
+	for i, entity := range req.Entities {
+		index[entity.Name] = i
+	}
+	var relations []nlp.ExtractedRelation
+	for _, r := range f.relations {
+		source, okSource := index[r[0]]
+		target, okTarget := index[r[2]]
+		if okSource && okTarget {
+			relations = append(relations, nlp.ExtractedRelation{Source: source, Target: target, Type: r[1], Fact: r[0] + " " + r[1] + " " + r[2]})
+		}
+	}
+	return relations, nil
+}
+
+// TestReprocessEpisodesUpdatesFactStoreLast tests that a failed graph update
+// leaves the stored extraction in place, so a later run applies the diff again
+func TestReprocessEpisodesUpdatesFactStoreLast(t *testing.T) {
+	ctx := context.Background()
+	store, err := factstore.NewDoltDB("file://" + t.TempDir() + "?commitname=Test&commitemail=test@example.com&database=facts")
+	if err != nil {
+		t.Fatalf("NewDoltDB: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	extractor := &fixedExtractor{
+		entities:  []string{"Alice", "Bob"},
+		relations: [][3]string{{"Alice", "MET", "Bob"}, {"Alice", "LIKES", "Bob"}},
+	}
+	d := newMemoryDriver()
+	base, err := NewClient(d, nil, nil, &Config{GroupID: "g", TimeZone: time.UTC, EntityExtractor: extractor, RelationExtractor: extractor}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	client := base.WithFactStore(store)
+
+	// Ingest the source with the original extraction
+	episode := types.Episode{ID: "ep-1", Name: "Meeting", Content: "Alice met Bob. Alice likes Bob.", GroupID: "g", CreatedAt: time.Now()}
+	if _, err := client.ExtractToFacts(ctx, episode, &AddEpisodeOptions{PromptVersion: "v1"}); err != nil {
+		t.Fatalf("ExtractToFacts: %v", err)
+	}
+	commits, err := store.Log(ctx, "", 1)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Run == nil || commits[0].Run.PromptVersion != "v1" ||
+		commits[0].Run.Model != "*predicato.fixedExtractor" || !equalStrings(commits[0].Run.SourceIDs, "ep-1") {
+		t.Errorf("latest commit = %+v, want the extraction run of ep-1", commits)
+	}
+
+	// The graph edge supporting MET cites ep-1 alone
+	d.respond("MATCH (episode:Episodic)-[:MENTIONS]->(n:Entity)",
+		map[string]interface{}{"uuid": "alice", "name": "Alice", "group_id": "g"},
+		map[string]interface{}{"uuid": "bob", "name": "Bob", "group_id": "g"})
+	d.respond("[e:RELATES_TO]->(m:Entity {uuid: $target_node_uuid})",
+		map[string]interface{}{"uuid": "edge-met", "name": "MET", "fact": "Alice met Bob", "group_id": "g",
+			"episodes": []interface{}{"ep-1"}, "source_node_uuid": "alice", "target_node_uuid": "bob", "created_at": time.Now()})
+
+	// The new extraction no longer finds MET, and the graph rejects writes
+	extractor.relations = [][3]string{{"Alice", "LIKES", "Bob"}}
+	d.fail("SET ", errors.New("graph unavailable"))
+
+	results, err := client.ReprocessEpisodes(ctx, &ReprocessSelector{SourceIDs: []string{"ep-1"}}, nil)
+	if err != nil {
+		t.Fatalf("ReprocessEpisodes: %v", err)
+	}
+	if results.Failed != 1 {
+		t.Fatalf("results = %+v, want one failure", results)
+	}
+	edges, err := store.GetExtractedEdges(ctx, "ep-1")
+	if err != nil {
+		t.Fatalf("GetExtractedEdges: %v", err)
+	}
+	if len(edges) != 2 {
+		t.Errorf("fact store holds %d edges after a failed run, want the original 2", len(edges))
+	}
+
+	// Once the graph is reachable the same diff is applied
+	d.fail("SET ", nil)
+	results, err = client.ReprocessEpisodes(ctx, &ReprocessSelector{SourceIDs: []string{"ep-1"}}, nil)
+	if err != nil {
+		t.Fatalf("ReprocessEpisodes: %v", err)
+	}
+	if results.Changed != 1 || len(results.Results[0].Diff.RemovedEdges) != 1 {
+		t.Fatalf("results = %+v, want the MET edge removed", results.Results[0])
+	}
+	if !equalStrings(results.Results[0].RetractedEdges, "edge-met") {
+		t.Errorf("retracted = %v, want edge-met", results.Results[0].RetractedEdges)
+	}
+	edges, err = store.GetExtractedEdges(ctx, "ep-1")
+	if err != nil {
+		t.Fatalf("GetExtractedEdges: %v", err)
+	}
+	if len(edges) != 1 || edges[0].Relation != "LIKES" {
+		t.Errorf("fact store edges = %+v, want only LIKES", edges)
+	}
+}
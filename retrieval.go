@@ -46,11 +46,12 @@ func (c *Client) Search(ctx context.Context, query string, config *types.SearchC
 	// Convert edge config if present
 	if config.EdgeConfig != nil {
 		searchConfig.EdgeConfig = &search.EdgeSearchConfig{
-			SearchMethods: convertSearchMethods(config.EdgeConfig.SearchMethods),
-			Reranker:      convertReranker(config.EdgeConfig.Reranker),
-			MinScore:      config.EdgeConfig.MinScore,
-			MMRLambda:     0.5, // Default MMR lambda
-			MaxDepth:      config.CenterNodeDistance,
+			SearchMethods:    convertSearchMethods(config.EdgeConfig.SearchMethods),
+			Reranker:         convertReranker(config.EdgeConfig.Reranker),
+			MinScore:         config.EdgeConfig.MinScore,
+			MMRLambda:        0.5, // Default MMR lambda
+			MaxDepth:         config.CenterNodeDistance,
+			ConfidenceWeight: config.EdgeConfig.ConfidenceWeight,
 		}
 	} else {
 		searchConfig.EdgeConfig = &search.EdgeSearchConfig{
@@ -63,25 +64,82 @@ func (c *Client) Search(ctx context.Context, query string, config *types.SearchC
 	}
 
 	// Create search filters
-	filters := &search.SearchFilters{}
+	filters := &search.SearchFilters{
+		MinConfidence: config.MinConfidence,
+	}
+
+	groupIDs, err := c.searchGroupIDs(ctx, config.GroupIDs)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = c.withUsage(ctx, "", groupIDs...)
+	if err != nil {
+		return nil, err
+	}
+	searchConfig.GroupWeights = config.GroupWeights
+
+	if err := c.checkEmbeddingSpace(ctx); err != nil {
+		return nil, err
+	}
 
 	// Perform the search
-	result, err := c.searcher.Search(ctx, query, searchConfig, filters, c.config.GroupID)
+	result, err := c.searcher.SearchGroups(ctx, query, searchConfig, filters, groupIDs)
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert back to types.SearchResults
 	searchResults := &types.SearchResults{
-		Nodes: result.Nodes,
-		Edges: result.Edges,
-		Query: result.Query,
-		Total: result.Total,
+		Nodes:     result.Nodes,
+		Edges:     result.Edges,
+		Query:     result.Query,
+		Total:     result.Total,
+		Citations: types.EdgeCitations(result.Edges),
 	}
 
 	return searchResults, nil
 }
 
+// WithAllowedGroupIDs returns a context whose searches may read groupIDs in
+// addition to the client's own group. It replaces Config.AllowedGroupIDs for
+// that request, so a server can grant each caller its own groups.
+func WithAllowedGroupIDs(ctx context.Context, groupIDs []string) context.Context {
+	return context.WithValue(ctx, types.ContextKeyAllowedGroupIDs, groupIDs)
+}
+
+// searchGroupIDs resolves the groups a search should cover, defaulting to the
+// client's own group. Every requested group must be the client's GroupID or
+// one of the groups allowed for the request.
+func (c *Client) searchGroupIDs(ctx context.Context, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return []string{c.config.GroupID}, nil
+	}
+	for _, groupID := range requested {
+		if !c.canReadGroup(ctx, groupID) {
+			return nil, fmt.Errorf("%w: %s", ErrGroupNotAllowed, groupID)
+		}
+	}
+	return requested, nil
+}
+
+// canReadGroup reports whether the request in ctx may read groupID. Groups
+// set with WithAllowedGroupIDs take precedence over Config.AllowedGroupIDs.
+func (c *Client) canReadGroup(ctx context.Context, groupID string) bool {
+	if groupID == c.config.GroupID {
+		return true
+	}
+	allowedGroupIDs := c.config.AllowedGroupIDs
+	if groupIDs, ok := ctx.Value(types.ContextKeyAllowedGroupIDs).([]string); ok {
+		allowedGroupIDs = groupIDs
+	}
+	for _, allowed := range allowedGroupIDs {
+		if allowed == groupID {
+			return true
+		}
+	}
+	return false
+}
+
 // GetNode retrieves a node by ID.
 func (c *Client) GetNode(ctx context.Context, nodeID string) (*types.Node, error) {
 	return c.driver.GetNode(ctx, nodeID, c.config.GroupID)
@@ -224,11 +282,8 @@ func (c *Client) SearchFacts(ctx context.Context, query string, config *types.Se
 	if c.embedder == nil {
 		return nil, fmt.Errorf("embedder not configured: required for SearchFacts")
 	}
-
-	// Generate embedding from query using EmbedSingle
-	embedding, err := c.embedder.EmbedSingle(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to embed query: %w", err)
+	if err := c.checkFactsEmbeddingSpace(ctx); err != nil {
+		return nil, err
 	}
 
 	// Convert types.SearchConfig to factstore.FactSearchConfig
@@ -252,13 +307,70 @@ func (c *Client) SearchFacts(ctx context.Context, query string, config *types.Se
 		}
 	}
 
-	// Perform hybrid search on factstore
-	results, err := c.factStore.HybridSearch(ctx, query, embedding, factConfig)
+	var groupIDs []string
+	var groupWeights map[string]float64
+	if config != nil {
+		groupIDs = config.GroupIDs
+		groupWeights = config.GroupWeights
+	}
+	groupIDs, err := c.searchGroupIDs(ctx, groupIDs)
 	if err != nil {
-		return nil, fmt.Errorf("factstore search failed: %w", err)
+		return nil, err
+	}
+	ctx, err = c.withUsage(ctx, "", groupIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate embedding from query using EmbedSingle
+	embedding, err := c.embedder.EmbedSingle(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	// Perform hybrid search on factstore, once per group
+	var groupResults []*factstore.FactSearchResults
+	for _, groupID := range groupIDs {
+		groupConfig := *factConfig
+		groupConfig.GroupID = groupID
+		results, err := c.factStore.HybridSearch(ctx, query, embedding, &groupConfig)
+		if err != nil {
+			return nil, fmt.Errorf("factstore search failed: %w", err)
+		}
+		groupResults = append(groupResults, results)
+	}
+	if len(groupResults) == 1 {
+		return groupResults[0], nil
+	}
+
+	return mergeFactSearchResults(query, groupIDs, groupResults, groupWeights, factConfig.Limit), nil
+}
+
+// mergeFactSearchResults combines per-group factstore results, scaling each
+// group's scores by its weight and keeping the best-scoring limit results.
+func mergeFactSearchResults(query string, groupIDs []string, groupResults []*factstore.FactSearchResults, weights map[string]float64, limit int) *factstore.FactSearchResults {
+	nodes := search.NewGroupMerger(weights, func(n *factstore.ExtractedNode) string { return n.ID })
+	edges := search.NewGroupMerger(weights, func(e *factstore.ExtractedEdge) string { return e.ID })
+	for i, results := range groupResults {
+		for _, node := range results.Nodes {
+			if node.GroupID == "" {
+				node.GroupID = groupIDs[i]
+			}
+		}
+		for _, edge := range results.Edges {
+			if edge.GroupID == "" {
+				edge.GroupID = groupIDs[i]
+			}
+		}
+		nodes.Add(groupIDs[i], results.Nodes, results.NodeScores)
+		edges.Add(groupIDs[i], results.Edges, results.EdgeScores)
 	}
 
-	return results, nil
+	merged := &factstore.FactSearchResults{Query: query}
+	merged.Nodes, merged.NodeScores = nodes.Results(limit)
+	merged.Edges, merged.EdgeScores = edges.Results(limit)
+	merged.Total = len(merged.Nodes) + len(merged.Edges)
+	return merged
 }
 
 // convertToFactstoreSearchMethods converts types.SearchConfig search method strings
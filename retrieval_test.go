@@ -0,0 +1,37 @@
+package predicato
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSearchGroupIDsUseRequestContext tests that groups allowed on the request
+// context replace the client-wide allowed groups
+func TestSearchGroupIDsUseRequestContext(t *testing.T) {
+	client, err := NewClient(newMemoryDriver(), nil, nil, &Config{
+		GroupID:         "user-1",
+		AllowedGroupIDs: []string{"org"},
+		TimeZone:        time.UTC,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.searchGroupIDs(ctx, []string{"user-1", "org"}); err != nil {
+		t.Errorf("configured group rejected: %v", err)
+	}
+
+	ctx = WithAllowedGroupIDs(ctx, []string{"team"})
+	if groups, err := client.searchGroupIDs(ctx, []string{"user-1", "team"}); err != nil || len(groups) != 2 {
+		t.Errorf("searchGroupIDs = %v, %v; want the request's groups", groups, err)
+	}
+	if _, err := client.searchGroupIDs(ctx, []string{"org"}); !errors.Is(err, ErrGroupNotAllowed) {
+		t.Errorf("group outside the request's list: err = %v, want ErrGroupNotAllowed", err)
+	}
+	if groups, err := client.searchGroupIDs(ctx, nil); err != nil || len(groups) != 1 || groups[0] != "user-1" {
+		t.Errorf("default groups = %v, %v; want the client's own group", groups, err)
+	}
+}
@@ -0,0 +1,14 @@
+package predicato
+
+import (
+	"context"
+
+	"github.com/soundprediction/predicato/pkg/telemetry"
+)
+
+// traceStep starts a span for an ingestion step of an episode, e.g.
+// "extraction". The returned function ends it and records the step's latency.
+func traceStep(ctx context.Context, step, episodeID string) (context.Context, func(error)) {
+	ctx, end := telemetry.StartStep(ctx, "ingest."+step, telemetry.AttrEpisodeID.String(episodeID))
+	return ctx, func(err error) { end(err) }
+}
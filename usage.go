@@ -0,0 +1,141 @@
+package predicato
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// ErrSensitiveRouting is returned in strict mode when a sensitive usage tag
+// would be sent to a provider that is not known to be local.
+var ErrSensitiveRouting = errors.New("sensitive usage would be routed to a non-local provider")
+
+// UsagePolicy decides the usage tag (types.ContextKeyUsage) attached to every
+// NLP and embedder call, so that an nlp.RouterClient can send sensitive groups
+// to local models.
+type UsagePolicy struct {
+	// GroupUsage maps group IDs to usage tags, e.g. "patients" to "hipaa".
+	GroupUsage map[string]string
+	// DefaultUsage tags groups without a mapping. Empty leaves them untagged.
+	DefaultUsage string
+	// SensitiveUsages are tags that must stay on local providers. A sensitive
+	// group tag is never replaced by a less sensitive per-episode or context tag.
+	SensitiveUsages []string
+	// Strict fails calls with a sensitive tag unless every configured model
+	// client (NLP clients, embedder, typed extractors, cross-encoder and the
+	// community builder's clients) reports via nlp.LocalityReporter that the
+	// tag is handled locally.
+	Strict bool
+}
+
+// IsSensitive reports whether usage is one of the sensitive tags.
+func (p *UsagePolicy) IsSensitive(usage string) bool {
+	if p == nil || usage == "" {
+		return false
+	}
+	for _, sensitive := range p.SensitiveUsages {
+		if strings.EqualFold(sensitive, usage) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsageFor returns the usage tag of the given groups. When groups differ, a
+// sensitive tag takes precedence over the others.
+func (p *UsagePolicy) UsageFor(groupIDs ...string) string {
+	if p == nil {
+		return ""
+	}
+	usage := ""
+	for _, groupID := range groupIDs {
+		tag, ok := p.GroupUsage[groupID]
+		if !ok {
+			tag = p.DefaultUsage
+		}
+		if p.IsSensitive(tag) {
+			return tag
+		}
+		if usage == "" {
+			usage = tag
+		}
+	}
+	return usage
+}
+
+// withUsage attaches the usage tag for groupIDs to ctx. override, e.g.
+// AddEpisodeOptions.Usage, and a tag already in ctx take precedence over the
+// group mapping unless that would downgrade a sensitive group. In strict mode
+// an error is returned if a sensitive tag would reach a non-local provider.
+func (c *Client) withUsage(ctx context.Context, override string, groupIDs ...string) (context.Context, error) {
+	policy := c.config.UsagePolicy
+	usage := override
+	if usage == "" {
+		usage, _ = ctx.Value(types.ContextKeyUsage).(string)
+	}
+	if policy != nil {
+		if groupUsage := policy.UsageFor(groupIDs...); usage == "" || (policy.IsSensitive(groupUsage) && !policy.IsSensitive(usage)) {
+			usage = groupUsage
+		}
+	}
+	if usage == "" {
+		return ctx, nil
+	}
+
+	if policy != nil && policy.Strict && policy.IsSensitive(usage) {
+		if name := c.firstNonLocalClient(usage); name != "" {
+			return nil, fmt.Errorf("%w: usage %q, client %s, groups %v", ErrSensitiveRouting, usage, name, groupIDs)
+		}
+	}
+	return context.WithValue(ctx, types.ContextKeyUsage, usage), nil
+}
+
+// firstNonLocalClient returns the name of the first configured model client,
+// NLP, embedder, extractor, cross-encoder or community builder client, that
+// does not keep usage on a local provider, or "" if all do.
+func (c *Client) firstNonLocalClient(usage string) string {
+	type namedClient struct {
+		name   string
+		client interface{}
+	}
+	clients := []namedClient{
+		{"nlp", c.nlProcessor},
+		{"NodeExtraction", c.nlpModels.NodeExtraction},
+		{"NodeReflexion", c.nlpModels.NodeReflexion},
+		{"NodeResolution", c.nlpModels.NodeResolution},
+		{"NodeAttribute", c.nlpModels.NodeAttribute},
+		{"EdgeExtraction", c.nlpModels.EdgeExtraction},
+		{"EdgeResolution", c.nlpModels.EdgeResolution},
+		{"Summarization", c.nlpModels.Summarization},
+		{"TextGeneration", c.nlpModels.TextGeneration},
+		{"embedder", c.embedder},
+		{"EntityExtractor", c.config.EntityExtractor},
+		{"RelationExtractor", c.config.RelationExtractor},
+		{"CrossEncoder", c.config.CrossEncoder},
+	}
+	if c.community != nil {
+		builderClients := c.community.ModelClients()
+		roles := make([]string, 0, len(builderClients))
+		for role := range builderClients {
+			roles = append(roles, role)
+		}
+		sort.Strings(roles)
+		for _, role := range roles {
+			clients = append(clients, namedClient{"community " + role, builderClients[role]})
+		}
+	}
+	for _, candidate := range clients {
+		if candidate.client == nil {
+			continue
+		}
+		if !nlp.IsLocalFor(candidate.client, usage) {
+			return candidate.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package predicato
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soundprediction/predicato/pkg/crossencoder"
+	"github.com/soundprediction/predicato/pkg/nlp"
+	"github.com/soundprediction/predicato/pkg/types"
+)
+
+// localNLP is an NLP client that reports every usage as local.
+type localNLP struct{}
+
+func (localNLP) Chat(ctx context.Context, messages []types.Message) (*types.Response, error) {
+	return &types.Response{}, nil
+}
+func (localNLP) ChatWithStructuredOutput(ctx context.Context, messages []types.Message, schema any) (*types.Response, error) {
+	return &types.Response{}, nil
+}
+func (localNLP) Close() error                          { return nil }
+func (localNLP) GetCapabilities() []nlp.TaskCapability { return nil }
+func (localNLP) IsLocalFor(usage string) bool          { return true }
+
+// remoteReranker is a cross-encoder that does not report its locality.
+type remoteReranker struct{}
+
+func (remoteReranker) Rank(ctx context.Context, query string, passages []string) ([]crossencoder.RankedPassage, error) {
+	return nil, nil
+}
+func (remoteReranker) Close() error { return nil }
+
+// TestStrictUsageChecksEveryModelClient tests that strict mode rejects a
+// sensitive call when any configured model client may be remote
+func TestStrictUsageChecksEveryModelClient(t *testing.T) {
+	newClient := func(config *Config) *Client {
+		config.GroupID = "patients"
+		config.TimeZone = time.UTC
+		config.UsagePolicy = &UsagePolicy{
+			GroupUsage:      map[string]string{"patients": "hipaa"},
+			SensitiveUsages: []string{"hipaa"},
+			Strict:          true,
+		}
+		client, err := NewClient(newMemoryDriver(), localNLP{}, nil, config, nil)
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		return client
+	}
+
+	if _, err := newClient(&Config{CrossEncoder: crossencoder.NewLocalRerankerClient(crossencoder.Config{})}).
+		withUsage(context.Background(), "", "patients"); err != nil {
+		t.Errorf("all clients local: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		config *Config
+		client string
+	}{
+		{"cross-encoder", &Config{CrossEncoder: remoteReranker{}}, "CrossEncoder"},
+		{"relation extractor", &Config{RelationExtractor: &spanRelationExtractor{}}, "RelationExtractor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newClient(tt.config).withUsage(context.Background(), "", "patients")
+			if !errors.Is(err, ErrSensitiveRouting) || !strings.Contains(err.Error(), tt.client) {
+				t.Errorf("err = %v, want ErrSensitiveRouting naming %s", err, tt.client)
+			}
+		})
+	}
+}